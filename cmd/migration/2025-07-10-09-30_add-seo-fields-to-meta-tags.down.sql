@@ -0,0 +1,12 @@
+ALTER TABLE meta_tags
+DROP COLUMN IF EXISTS robots,
+DROP COLUMN IF EXISTS canonical_url,
+DROP COLUMN IF EXISTS og_title,
+DROP COLUMN IF EXISTS og_description,
+DROP COLUMN IF EXISTS og_image,
+DROP COLUMN IF EXISTS og_type,
+DROP COLUMN IF EXISTS twitter_card,
+DROP COLUMN IF EXISTS twitter_title,
+DROP COLUMN IF EXISTS twitter_description,
+DROP COLUMN IF EXISTS twitter_image,
+DROP COLUMN IF EXISTS custom_meta;
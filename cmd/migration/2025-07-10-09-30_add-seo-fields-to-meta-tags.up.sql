@@ -0,0 +1,12 @@
+ALTER TABLE meta_tags
+ADD COLUMN IF NOT EXISTS robots VARCHAR(100),
+ADD COLUMN IF NOT EXISTS canonical_url VARCHAR(2048),
+ADD COLUMN IF NOT EXISTS og_title VARCHAR(255),
+ADD COLUMN IF NOT EXISTS og_description TEXT,
+ADD COLUMN IF NOT EXISTS og_image VARCHAR(2048),
+ADD COLUMN IF NOT EXISTS og_type VARCHAR(50),
+ADD COLUMN IF NOT EXISTS twitter_card VARCHAR(50),
+ADD COLUMN IF NOT EXISTS twitter_title VARCHAR(255),
+ADD COLUMN IF NOT EXISTS twitter_description TEXT,
+ADD COLUMN IF NOT EXISTS twitter_image VARCHAR(2048),
+ADD COLUMN IF NOT EXISTS custom_meta JSONB;
@@ -0,0 +1,2 @@
+ALTER TABLE meta_tags
+DROP COLUMN IF EXISTS structured_data;
@@ -0,0 +1,2 @@
+ALTER TABLE meta_tags
+ADD COLUMN IF NOT EXISTS structured_data JSONB;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS link_checks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    page_type VARCHAR(20) NOT NULL,
+    page_id UUID NOT NULL,
+    content_id UUID NOT NULL,
+    language VARCHAR(10),
+    field VARCHAR(100) NOT NULL,
+    url TEXT NOT NULL,
+    is_internal BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL,
+    status_code INT,
+    error_message TEXT,
+    last_checked_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_link_checks_content_field_url ON link_checks(content_id, field, url);
+CREATE INDEX IF NOT EXISTS idx_link_checks_page_id ON link_checks(page_id);
+CREATE INDEX IF NOT EXISTS idx_link_checks_status ON link_checks(status);
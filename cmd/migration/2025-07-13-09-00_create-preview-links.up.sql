@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS preview_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    page_type VARCHAR(20) NOT NULL,
+    page_id UUID NOT NULL,
+    content_id UUID NOT NULL,
+    language VARCHAR(10),
+    password_hash TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_preview_links_page_id ON preview_links(page_id);
+CREATE INDEX IF NOT EXISTS idx_preview_links_content_id ON preview_links(content_id);
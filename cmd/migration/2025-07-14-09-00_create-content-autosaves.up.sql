@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS content_autosaves (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    page_type VARCHAR(20) NOT NULL,
+    page_id UUID NOT NULL,
+    language VARCHAR(10) NOT NULL,
+    user_id UUID NOT NULL,
+    base_content_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_content_autosaves_slot ON content_autosaves(page_type, page_id, language, user_id);
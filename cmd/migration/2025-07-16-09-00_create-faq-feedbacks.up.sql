@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS faq_feedbacks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    page_id UUID NOT NULL,
+    language VARCHAR(10) NOT NULL,
+    client_hash VARCHAR(64) NOT NULL,
+    faq_content_id UUID NOT NULL,
+    helpful BOOLEAN NOT NULL,
+    feedback TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (page_id) REFERENCES faq_pages(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_faq_feedbacks_client ON faq_feedbacks(page_id, language, client_hash);
+CREATE INDEX IF NOT EXISTS idx_faq_feedbacks_faq_content_id ON faq_feedbacks(faq_content_id);
+CREATE INDEX IF NOT EXISTS idx_faq_feedbacks_updated_at ON faq_feedbacks(updated_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS page_visitor_dailies;
+DROP TABLE IF EXISTS page_view_dailies;
+DROP TABLE IF EXISTS page_views;
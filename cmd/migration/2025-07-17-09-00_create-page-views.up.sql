@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS page_views (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    page_type VARCHAR(20) NOT NULL,
+    page_id UUID NOT NULL,
+    language VARCHAR(10) NOT NULL,
+    visitor_hash VARCHAR(64) NOT NULL,
+    referrer_host VARCHAR(255) NOT NULL DEFAULT '',
+    utm_source VARCHAR(100) NOT NULL DEFAULT '',
+    utm_medium VARCHAR(100) NOT NULL DEFAULT '',
+    utm_campaign VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_views_created_at ON page_views(created_at);
+
+CREATE TABLE IF NOT EXISTS page_view_dailies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    day DATE NOT NULL,
+    page_type VARCHAR(20) NOT NULL,
+    page_id UUID NOT NULL,
+    language VARCHAR(10) NOT NULL,
+    referrer_host VARCHAR(255) NOT NULL DEFAULT '',
+    utm_source VARCHAR(100) NOT NULL DEFAULT '',
+    utm_medium VARCHAR(100) NOT NULL DEFAULT '',
+    utm_campaign VARCHAR(100) NOT NULL DEFAULT '',
+    views BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_view_dailies_day ON page_view_dailies(day);
+CREATE INDEX IF NOT EXISTS idx_page_view_dailies_page_id ON page_view_dailies(page_id, day);
+
+CREATE TABLE IF NOT EXISTS page_visitor_dailies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    day DATE NOT NULL,
+    page_type VARCHAR(20) NOT NULL,
+    page_id UUID NOT NULL,
+    language VARCHAR(10) NOT NULL,
+    utm_source VARCHAR(100) NOT NULL DEFAULT '',
+    utm_medium VARCHAR(100) NOT NULL DEFAULT '',
+    utm_campaign VARCHAR(100) NOT NULL DEFAULT '',
+    visitor_hash VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_visitor_dailies_day ON page_visitor_dailies(day);
+CREATE INDEX IF NOT EXISTS idx_page_visitor_dailies_page_id ON page_visitor_dailies(page_id, day);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS landing_experiment_events;
+DROP TABLE IF EXISTS landing_experiment_variants;
+DROP TABLE IF EXISTS landing_experiments;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS landing_experiments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    page_id UUID NOT NULL REFERENCES landing_pages(id) ON DELETE CASCADE,
+    language VARCHAR(10) NOT NULL,
+    name TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    winner_variant_id UUID,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_landing_experiments_page_id ON landing_experiments(page_id);
+-- Only one experiment at a time decides what a page language serves
+CREATE UNIQUE INDEX IF NOT EXISTS idx_landing_experiments_running ON landing_experiments(page_id, language) WHERE status = 'running';
+
+CREATE TABLE IF NOT EXISTS landing_experiment_variants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    experiment_id UUID NOT NULL REFERENCES landing_experiments(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    weight INTEGER NOT NULL CHECK (weight > 0),
+    is_control BOOLEAN NOT NULL DEFAULT FALSE,
+    content JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_landing_experiment_variants_experiment_id ON landing_experiment_variants(experiment_id);
+
+CREATE TABLE IF NOT EXISTS landing_experiment_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    experiment_id UUID NOT NULL REFERENCES landing_experiments(id) ON DELETE CASCADE,
+    variant_id UUID NOT NULL REFERENCES landing_experiment_variants(id) ON DELETE CASCADE,
+    assignment_hash VARCHAR(64) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_landing_experiment_events_visitor ON landing_experiment_events(experiment_id, assignment_hash, type);
+CREATE INDEX IF NOT EXISTS idx_landing_experiment_events_variant_id ON landing_experiment_events(variant_id);
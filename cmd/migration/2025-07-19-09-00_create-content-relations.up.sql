@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS content_relations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_page_type VARCHAR(20) NOT NULL,
+    source_page_id UUID NOT NULL,
+    source_content_id UUID NOT NULL,
+    language VARCHAR(10) NOT NULL,
+    relation_type VARCHAR(30) NOT NULL,
+    target_page_type VARCHAR(20) NOT NULL,
+    target_page_id UUID NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_content_relations_pair ON content_relations(source_content_id, relation_type, target_page_type, target_page_id);
+-- Reverse lookup of the pages pointing to a page
+CREATE INDEX IF NOT EXISTS idx_content_relations_target ON content_relations(target_page_type, target_page_id);
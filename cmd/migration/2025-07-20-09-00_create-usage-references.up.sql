@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS usage_references (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    item_type VARCHAR(20) NOT NULL,
+    item_id UUID NOT NULL,
+    referrer_type VARCHAR(20) NOT NULL,
+    referrer_id UUID NOT NULL,
+    page_type VARCHAR(20),
+    page_id UUID,
+    language VARCHAR(10),
+    label TEXT,
+    field TEXT NOT NULL,
+    published BOOLEAN NOT NULL DEFAULT FALSE,
+    indexed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Where used lookups of one item
+CREATE INDEX IF NOT EXISTS idx_usage_references_item ON usage_references(item_type, item_id);
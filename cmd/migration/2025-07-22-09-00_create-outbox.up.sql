@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    dispatched_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_idempotency_key ON outbox_events(idempotency_key);
+-- The dispatcher polls for the events it has not fanned out yet
+CREATE INDEX IF NOT EXISTS idx_outbox_events_undispatched ON outbox_events(created_at) WHERE dispatched_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_id UUID NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    subscriber VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    processed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One delivery per event and subscriber, fanning an event out twice adds nothing
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_deliveries_event_subscriber ON outbox_deliveries(event_id, subscriber);
+CREATE INDEX IF NOT EXISTS idx_outbox_deliveries_due ON outbox_deliveries(status, next_attempt_at);
+
+-- A webhook event handled again from the outbox must not be queued twice for an endpoint, replays are rows of their own
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_event ON webhook_deliveries(endpoint_id, event_id) WHERE replay_of_id IS NULL;
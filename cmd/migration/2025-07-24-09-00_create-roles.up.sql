@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles(name);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    resource VARCHAR(50) NOT NULL,
+    action VARCHAR(20) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_permissions_role_resource_action ON role_permissions(role_id, resource, action);
+
+-- Users without a role are allowed nothing in the CMS until an admin assigns one
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role_id UUID REFERENCES roles(id) ON DELETE SET NULL;
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Everything, including users, roles, webhooks and the audit log'),
+    ('editor', 'Writes pages, categories and media files'),
+    ('approver', 'Reviews and publishes pages'),
+    ('viewer', 'Reads the CMS'),
+    ('form-manager', 'Builds forms, reads their submissions and manages their emails')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, resource, action)
+SELECT roles.id, permissions.resource, permissions.action
+FROM roles
+JOIN (VALUES
+    ('admin', '*', '*'),
+    ('editor', 'pages', 'read'), ('editor', 'pages', 'create'), ('editor', 'pages', 'update'), ('editor', 'pages', 'delete'),
+    ('editor', 'categories', 'read'), ('editor', 'categories', 'create'), ('editor', 'categories', 'update'), ('editor', 'categories', 'delete'),
+    ('editor', 'media', 'read'), ('editor', 'media', 'create'), ('editor', 'media', 'update'), ('editor', 'media', 'delete'),
+    ('editor', 'forms', 'read'), ('editor', 'emails', 'read'), ('editor', 'analytics', 'read'),
+    ('approver', 'pages', 'read'), ('approver', 'pages', 'update'), ('approver', 'pages', 'publish'),
+    ('approver', 'categories', 'read'), ('approver', 'media', 'read'), ('approver', 'analytics', 'read'),
+    ('viewer', 'pages', 'read'), ('viewer', 'categories', 'read'), ('viewer', 'media', 'read'),
+    ('viewer', 'forms', 'read'), ('viewer', 'emails', 'read'), ('viewer', 'analytics', 'read'),
+    ('form-manager', 'forms', 'read'), ('form-manager', 'forms', 'create'), ('form-manager', 'forms', 'update'), ('form-manager', 'forms', 'delete'),
+    ('form-manager', 'form_submissions', 'read'), ('form-manager', 'form_submissions', 'create'),
+    ('form-manager', 'emails', 'read'), ('form-manager', 'emails', 'create'), ('form-manager', 'emails', 'update'), ('form-manager', 'emails', 'delete'), ('form-manager', 'emails', 'send'),
+    ('form-manager', 'media', 'read'), ('form-manager', 'media', 'create')
+) AS permissions(role, resource, action) ON permissions.role = roles.name
+ON CONFLICT (role_id, resource, action) DO NOTHING;
@@ -0,0 +1,13 @@
+-- Users with grants only reach the pages one of them matches, the others every page their role allows
+CREATE TABLE IF NOT EXISTS page_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    page_type VARCHAR(20) NOT NULL,
+    language VARCHAR(10),
+    -- Deleting the last grant of a user would open every page to them, so a category in a grant cannot be deleted
+    category_id UUID REFERENCES categories(id),
+    action VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_grants_user_id ON page_grants(user_id);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_categories_weight_created_at_id;
+DROP INDEX IF EXISTS idx_media_files_name_id;
+DROP INDEX IF EXISTS idx_media_files_created_at_id;
+DROP INDEX IF EXISTS idx_form_submissions_form_id_updated_at_id;
+DROP INDEX IF EXISTS idx_form_submissions_form_id_created_at_id;
+DROP INDEX IF EXISTS idx_forms_name_id;
+DROP INDEX IF EXISTS idx_forms_updated_at_id;
+DROP INDEX IF EXISTS idx_faq_pages_updated_at_id;
+DROP INDEX IF EXISTS idx_faq_pages_created_at_id;
+DROP INDEX IF EXISTS idx_partner_pages_updated_at_id;
+DROP INDEX IF EXISTS idx_partner_pages_created_at_id;
+DROP INDEX IF EXISTS idx_landing_pages_updated_at_id;
+DROP INDEX IF EXISTS idx_landing_pages_created_at_id;
@@ -0,0 +1,19 @@
+-- Keyset pagination orders by the sort column then id, these indexes serve both directions without sorting the table
+CREATE INDEX IF NOT EXISTS idx_landing_pages_created_at_id ON landing_pages(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_landing_pages_updated_at_id ON landing_pages(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_partner_pages_created_at_id ON partner_pages(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_partner_pages_updated_at_id ON partner_pages(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_faq_pages_created_at_id ON faq_pages(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_faq_pages_updated_at_id ON faq_pages(updated_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_forms_updated_at_id ON forms(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_forms_name_id ON forms(name, id);
+
+-- Submissions are always listed for one form
+CREATE INDEX IF NOT EXISTS idx_form_submissions_form_id_created_at_id ON form_submissions(form_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_form_submissions_form_id_updated_at_id ON form_submissions(form_id, updated_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_media_files_created_at_id ON media_files(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_media_files_name_id ON media_files(name, id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_weight_created_at_id ON categories(weight, created_at, id);
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,21 +14,37 @@ type SendGridConfig struct {
 }
 
 type Config struct {
-	Server    ServerConfig
-	App       AppConfig
-	Database  DatabaseConfig
-	SendGrid  SendGridConfig
-	SecretKey SecretKeyConfig
-	Line      LineConfig
+	Server      ServerConfig
+	App         AppConfig
+	Database    DatabaseConfig
+	SendGrid    SendGridConfig
+	SecretKey   SecretKeyConfig
+	Line        LineConfig
+	Audit       AuditConfig
+	LinkCheck   LinkCheckConfig
+	Preview     PreviewConfig
+	Maintenance MaintenanceConfig
+	Render      RenderConfig
+	Feedback    FeedbackConfig
+	Analytics   AnalyticsConfig
+	Usage       UsageConfig
+	GraphQL     GraphQLConfig
+	AppCache    AppCacheConfig
+	Webhook     WebhookConfig
+	Outbox      OutboxConfig
+	Permission  PermissionConfig
 }
 
 // ServerConfig holds all the server-related config
 type ServerConfig struct {
-	Port           string
-	ReadTimeout    time.Duration
-	WriteTimeout   time.Duration
-	AppName        string
-	AllowedOrigins string
+	Port            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	AppName         string
+	AllowedOrigins  string
+	ProxyHeader     string        // Header the reverse proxy sets to the client ip, e.g. X-Real-IP, empty uses the connection address
+	TrustedProxies  []string      // IPs or CIDR ranges ProxyHeader is read from, the header of any other client is ignored
+	ShutdownTimeout time.Duration // How long in-flight requests get to finish on SIGTERM
 }
 
 // AppConfig holds all the application-related config
@@ -53,6 +71,7 @@ type DatabaseConfig struct {
 type SecretKeyConfig struct {
 	LineKey   string
 	NormalKey string
+	HashSalt  string // Keys the visitor, feedback and experiment hashes, required and never one of the signing keys
 }
 
 type LineConfig struct {
@@ -63,11 +82,122 @@ type LineConfig struct {
 	AuthorizeUrl string
 }
 
+// AuditConfig holds the content audit settings
+type AuditConfig struct {
+	BlockPublishOnCritical bool // Refuse Published transitions while critical findings exist
+}
+
+// LinkCheckConfig holds the broken link checker settings
+type LinkCheckConfig struct {
+	Interval          time.Duration // 0 disables the scheduled re-check
+	Timeout           time.Duration // Per request to external links
+	Concurrency       int           // Max external requests in flight
+	UserAgent         string
+	AllowPrivateHosts bool // Lets the checker reach loopback, private and link-local addresses, for local setups only
+}
+
+// PreviewConfig holds the preview link settings
+type PreviewConfig struct {
+	SecretKey           string        // Signs the preview tokens, required and never one of the login keys
+	FrontendURL         string        // Site rendering the previews, falls back to FRONTEND_URLS
+	DefaultTTL          time.Duration // Used when the request does not ask for one
+	MaxTTL              time.Duration // Longest lifetime a preview link may ask for
+	MaxPasswordAttempts int           // Wrong passwords a link accepts before it locks, 0 disables the lockout
+	PasswordLockout     time.Duration // How long a locked link refuses every password
+}
+
+// MaintenanceConfig holds the cleanup job settings
+type MaintenanceConfig struct {
+	Interval         time.Duration // 0 disables the scheduled cleanup
+	HistoryRetention int           // History versions kept per page and language, 0 keeps them all
+	OrphanGrace      time.Duration // Orphans younger than this are left alone, they may belong to a running write
+	DryRun           bool          // Scheduled runs only report what they would remove
+}
+
+// RenderConfig holds the server-side component renderer settings
+type RenderConfig struct {
+	TemplatesDir string // *.tmpl files here redefine the built-in component templates
+	CacheSize    int    // Rendered contents kept in memory, 0 disables the cache
+}
+
+// FeedbackConfig holds the FAQ helpfulness voting settings
+type FeedbackConfig struct {
+	RateLimit  int           // Votes accepted per client within RateWindow, 0 disables the limit
+	RateWindow time.Duration // Window the rate limit is counted over
+	MaxLength  int           // Characters of free-text feedback
+}
+
+// AnalyticsConfig holds the page view analytics settings
+type AnalyticsConfig struct {
+	BufferSize     int           // Page views queued in memory, views arriving while it is full are dropped
+	BatchSize      int           // Page views written per insert
+	FlushInterval  time.Duration // Longest a queued page view waits to be written
+	RollupInterval time.Duration // 0 disables the scheduled daily rollup
+	RawRetention   time.Duration // Raw page views kept after the rollup, 0 keeps them all
+	RateLimit      int           // Page views accepted per client within RateWindow, 0 disables the limit
+	RateWindow     time.Duration // Window the rate limit is counted over
+}
+
+// UsageConfig holds the usage index settings
+type UsageConfig struct {
+	Interval time.Duration // 0 disables the scheduled rebuild
+	MaxAge   time.Duration // Impact reports and delete checks rebuild an index older than this
+}
+
+// GraphQLConfig holds the app GraphQL endpoint settings
+type GraphQLConfig struct {
+	MaxDepth              int // Deepest selection set nesting a query may have, 0 disables the limit
+	MaxComplexity         int // Highest estimated field count a query may have, 0 disables the limit
+	MaxIntrospectionDepth int // Deepest nesting below __schema or __type, counted apart from MaxDepth, 0 disables the limit
+	DefaultListSize       int // Items a list field without a limit argument is estimated to return
+	PersistedQuerySize    int // Persisted queries kept in memory, 0 disables persisted queries
+}
+
+// AppCacheConfig holds the app response cache and HTTP caching settings
+type AppCacheConfig struct {
+	Size   int           // Responses kept in memory, 0 disables the cache
+	TTL    time.Duration // Longest a cached response is served without reloading it, 0 keeps it until invalidated or evicted
+	MaxAge time.Duration // Cache-Control max-age sent to clients, 0 makes them revalidate every time
+}
+
+// WebhookConfig holds the outgoing webhook delivery settings
+type WebhookConfig struct {
+	PollInterval      time.Duration // How often due deliveries are sent, 0 disables the dispatcher
+	BatchSize         int           // Deliveries sent per poll
+	Concurrency       int           // Max requests in flight
+	Timeout           time.Duration // Per request, a slower endpoint counts as a failed attempt
+	MaxAttempts       int           // Attempts before a delivery is marked failed
+	BaseBackoff       time.Duration // Wait before the first retry, doubled after every failed attempt
+	MaxBackoff        time.Duration // Longest wait between two attempts
+	UserAgent         string
+	AllowPrivateHosts bool // Lets deliveries reach loopback, private and link-local addresses, for local setups only
+}
+
+// OutboxConfig holds the domain event dispatcher settings
+type OutboxConfig struct {
+	PollInterval time.Duration // How often new events are fanned out and due deliveries handled, 0 disables the dispatcher
+	BatchSize    int           // Events fanned out and deliveries handled per round
+	Concurrency  int           // Max deliveries handled at once
+	Lease        time.Duration // How long a claimed delivery is hidden from other instances, a handler still running after it may run twice
+	MaxAttempts  int           // Attempts before a delivery is dead-lettered
+	BaseBackoff  time.Duration // Wait before the first retry, doubled after every failed attempt
+	MaxBackoff   time.Duration // Longest wait between two attempts
+	Retention    time.Duration // How long handled events are kept, 0 keeps them forever
+}
+
+// PermissionConfig holds the role permission checks settings
+type PermissionConfig struct {
+	CacheTTL time.Duration // How long the role permissions are kept in memory, a changed role applies after at most this long
+}
+
 func New() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:    getEnv("PORT", "8080"),
-			AppName: getEnv("APP_NAME", "CMS API"),
+			Port:            getEnv("PORT", "8080"),
+			AppName:         getEnv("APP_NAME", "CMS API"),
+			ProxyHeader:     getEnv("PROXY_HEADER", ""),
+			TrustedProxies:  getEnvList("TRUSTED_PROXIES"),
+			ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
 		},
 		App: AppConfig{
 			Environment:      getEnv("ENVIRONMENT", "development"),
@@ -94,6 +224,7 @@ func New() *Config {
 		SecretKey: SecretKeyConfig{
 			LineKey:   getEnv("OAUTH_CLIENT_SECRET", ""),
 			NormalKey: getEnv("JWT_SECRET_KEY", ""),
+			HashSalt:  getEnv("HASH_SALT", ""),
 		},
 		Line: LineConfig{
 			ClientId:     getEnv("OAUTH_CLIENT_ID", ""),
@@ -102,6 +233,88 @@ func New() *Config {
 			TokenUrl:     getEnv("TOKEN_URL", "https://api.line.biz/oauth2/v2.1/token"),
 			AuthorizeUrl: getEnv("AUTHORIZE_URL", "https://access.line.me/oauth2/v2.1/authorize"),
 		},
+		Audit: AuditConfig{
+			BlockPublishOnCritical: getEnv("AUDIT_BLOCK_PUBLISH_ON_CRITICAL", "false") == "true",
+		},
+		LinkCheck: LinkCheckConfig{
+			Interval:          getEnvDuration("LINK_CHECK_INTERVAL", 24*time.Hour),
+			Timeout:           getEnvDuration("LINK_CHECK_TIMEOUT", 10*time.Second),
+			Concurrency:       getEnvInt("LINK_CHECK_CONCURRENCY", 8),
+			UserAgent:         getEnv("LINK_CHECK_USER_AGENT", "cms-api-link-checker/1.0"),
+			AllowPrivateHosts: getEnv("LINK_CHECK_ALLOW_PRIVATE_HOSTS", "false") == "true",
+		},
+		Preview: PreviewConfig{
+			SecretKey:           getEnv("PREVIEW_SECRET_KEY", ""),
+			FrontendURL:         getEnv("PREVIEW_FRONTEND_URL", ""),
+			DefaultTTL:          getEnvDuration("PREVIEW_DEFAULT_TTL", 2*time.Hour),
+			MaxTTL:              getEnvDuration("PREVIEW_MAX_TTL", 7*24*time.Hour),
+			MaxPasswordAttempts: getEnvInt("PREVIEW_MAX_PASSWORD_ATTEMPTS", 5),
+			PasswordLockout:     getEnvDuration("PREVIEW_PASSWORD_LOCKOUT", 15*time.Minute),
+		},
+		Maintenance: MaintenanceConfig{
+			Interval:         getEnvDuration("MAINTENANCE_INTERVAL", 6*time.Hour),
+			HistoryRetention: getEnvInt("MAINTENANCE_HISTORY_RETENTION", 0),
+			OrphanGrace:      getEnvDuration("MAINTENANCE_ORPHAN_GRACE", time.Hour),
+			DryRun:           getEnv("MAINTENANCE_DRY_RUN", "false") == "true",
+		},
+		Render: RenderConfig{
+			TemplatesDir: getEnv("RENDER_TEMPLATES_DIR", ""),
+			CacheSize:    getEnvInt("RENDER_CACHE_SIZE", 500),
+		},
+		Feedback: FeedbackConfig{
+			RateLimit:  getEnvInt("FEEDBACK_RATE_LIMIT", 10),
+			RateWindow: getEnvDuration("FEEDBACK_RATE_WINDOW", time.Minute),
+			MaxLength:  getEnvInt("FEEDBACK_MAX_LENGTH", 1000),
+		},
+		Analytics: AnalyticsConfig{
+			BufferSize:     getEnvInt("ANALYTICS_BUFFER_SIZE", 10000),
+			BatchSize:      getEnvInt("ANALYTICS_BATCH_SIZE", 500),
+			FlushInterval:  getEnvDuration("ANALYTICS_FLUSH_INTERVAL", 5*time.Second),
+			RollupInterval: getEnvDuration("ANALYTICS_ROLLUP_INTERVAL", time.Hour),
+			RawRetention:   getEnvDuration("ANALYTICS_RAW_RETENTION", 30*24*time.Hour),
+			RateLimit:      getEnvInt("ANALYTICS_RATE_LIMIT", 120),
+			RateWindow:     getEnvDuration("ANALYTICS_RATE_WINDOW", time.Minute),
+		},
+		Usage: UsageConfig{
+			Interval: getEnvDuration("USAGE_INDEX_INTERVAL", time.Hour),
+			MaxAge:   getEnvDuration("USAGE_INDEX_MAX_AGE", time.Minute),
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:              getEnvInt("GRAPHQL_MAX_DEPTH", 8),
+			MaxComplexity:         getEnvInt("GRAPHQL_MAX_COMPLEXITY", 1000),
+			MaxIntrospectionDepth: getEnvInt("GRAPHQL_MAX_INTROSPECTION_DEPTH", 13),
+			DefaultListSize:       getEnvInt("GRAPHQL_DEFAULT_LIST_SIZE", 10),
+			PersistedQuerySize:    getEnvInt("GRAPHQL_PERSISTED_QUERY_SIZE", 1000),
+		},
+		AppCache: AppCacheConfig{
+			Size:   getEnvInt("APP_CACHE_SIZE", 1000),
+			TTL:    getEnvDuration("APP_CACHE_TTL", 10*time.Minute),
+			MaxAge: getEnvDuration("APP_CACHE_MAX_AGE", time.Minute),
+		},
+		Webhook: WebhookConfig{
+			PollInterval:      getEnvDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+			BatchSize:         getEnvInt("WEBHOOK_BATCH_SIZE", 50),
+			Concurrency:       getEnvInt("WEBHOOK_CONCURRENCY", 4),
+			Timeout:           getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:       getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			BaseBackoff:       getEnvDuration("WEBHOOK_BASE_BACKOFF", 30*time.Second),
+			MaxBackoff:        getEnvDuration("WEBHOOK_MAX_BACKOFF", 6*time.Hour),
+			UserAgent:         getEnv("WEBHOOK_USER_AGENT", "cms-api-webhooks/1.0"),
+			AllowPrivateHosts: getEnv("WEBHOOK_ALLOW_PRIVATE_HOSTS", "false") == "true",
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", 100),
+			Concurrency:  getEnvInt("OUTBOX_CONCURRENCY", 4),
+			Lease:        getEnvDuration("OUTBOX_LEASE", 5*time.Minute),
+			MaxAttempts:  getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+			BaseBackoff:  getEnvDuration("OUTBOX_BASE_BACKOFF", 10*time.Second),
+			MaxBackoff:   getEnvDuration("OUTBOX_MAX_BACKOFF", time.Hour),
+			Retention:    getEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+		},
+		Permission: PermissionConfig{
+			CacheTTL: getEnvDuration("PERMISSION_CACHE_TTL", time.Minute),
+		},
 	}
 }
 
@@ -112,3 +325,34 @@ func getEnv(key string, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+// getEnvInt reads an integer environment variable, falling back to the default when missing or invalid
+func getEnvInt(key string, defaultVal int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultVal
+}
+
+// getEnvDuration reads a duration such as "30s" or "24h", falling back to the default when missing or invalid
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultVal
+}
+
+// getEnvList reads a comma separated environment variable, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
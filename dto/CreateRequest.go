@@ -8,9 +8,21 @@ import (
 
 // --- MetaTag ---
 type CreateMetaTagRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	CoverImage  string `json:"cover_image"`
+	Title              string            `json:"title"`
+	Description        string            `json:"description"`
+	CoverImage         string            `json:"cover_image"`
+	Robots             string            `json:"robots" example:"noindex,nofollow"`
+	CanonicalURL       string            `json:"canonical_url"`
+	OGTitle            string            `json:"og_title"`
+	OGDescription      string            `json:"og_description"`
+	OGImage            string            `json:"og_image"`
+	OGType             string            `json:"og_type" example:"article"`
+	TwitterCard        string            `json:"twitter_card" example:"summary_large_image"`
+	TwitterTitle       string            `json:"twitter_title"`
+	TwitterDescription string            `json:"twitter_description"`
+	TwitterImage       string            `json:"twitter_image"`
+	CustomMeta         map[string]string `json:"custom_meta"`
+	StructuredData     interface{}       `json:"structured_data" swaggertype:"object"`
 }
 
 // --- Component ---
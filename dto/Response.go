@@ -3,10 +3,28 @@ package dto
 import "time"
 
 type MetaTagResponse struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	CoverImage  string `json:"cover_image"`
+	ID                 string                      `json:"id"`
+	Title              string                      `json:"title"`
+	Description        string                      `json:"description"`
+	CoverImage         string                      `json:"cover_image"`
+	Robots             string                      `json:"robots"`
+	CanonicalURL       string                      `json:"canonical_url"`
+	OGTitle            string                      `json:"og_title"`
+	OGDescription      string                      `json:"og_description"`
+	OGImage            string                      `json:"og_image"`
+	OGType             string                      `json:"og_type"`
+	TwitterCard        string                      `json:"twitter_card"`
+	TwitterTitle       string                      `json:"twitter_title"`
+	TwitterDescription string                      `json:"twitter_description"`
+	TwitterImage       string                      `json:"twitter_image"`
+	CustomMeta         map[string]string           `json:"custom_meta"`
+	StructuredData     interface{}                 `json:"structured_data,omitempty" swaggertype:"object"`
+	Alternates         []HrefLangAlternateResponse `json:"alternates,omitempty"`
+}
+
+type HrefLangAlternateResponse struct {
+	HrefLang string `json:"hreflang" example:"th"`
+	Href     string `json:"href" example:"https://example.com/th/faq/about-us"`
 }
 
 type ComponentResponse struct {
@@ -56,6 +74,7 @@ type LandingContentResponse struct {
 	Revision       RevisionResponse             `json:"revision"`
 	Categories     []CategoryResponse           `json:"categories"`
 	Components     []ComponentResponse          `json:"components"`
+	JSONLD         interface{}                  `json:"json_ld,omitempty" swaggertype:"object"`
 	URLAlias       string                       `json:"url_alias"`
 	PublishOn      time.Time                    `json:"publish_on"`
 	UnpublishOn    time.Time                    `json:"unpublish_on"`
@@ -96,6 +115,7 @@ type PartnerContentResponse struct {
 	Revision         RevisionResponse    `json:"revision"`
 	Categories       []CategoryResponse  `json:"categories"`
 	Components       []ComponentResponse `json:"components"`
+	JSONLD           interface{}         `json:"json_ld,omitempty" swaggertype:"object"`
 	PublishOn        time.Time           `json:"publish_on"`
 	UnpublishOn      time.Time           `json:"unpublish_on"`
 	AuthoredOn       time.Time           `json:"authored_on"`
@@ -127,6 +147,7 @@ type FaqContentResponse struct {
 	Revision       RevisionResponse    `json:"revision"`
 	Categories     []CategoryResponse  `json:"categories"`
 	Components     []ComponentResponse `json:"components"`
+	JSONLD         interface{}         `json:"json_ld,omitempty" swaggertype:"object"`
 	PublishOn      time.Time           `json:"publish_on"`
 	UnpublishOn    time.Time           `json:"unpublish_on"`
 	AuthoredOn     time.Time           `json:"authored_on"`
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// CachedResponse is an app response body kept by the response cache with the validators sent along with it
+type CachedResponse struct {
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+	LoadedAt     time.Time // When the content was read, a response read before an invalidation is not stored
+}
+
+// CacheTags names what a cached response was built from, changing any of them invalidates the response
+type CacheTags struct {
+	Pages       []CachedPage
+	CategoryIDs []uuid.UUID
+	FormIDs     []uuid.UUID
+	MediaURLs   []string // Links to media files, by full url or path
+}
+
+type CachedPage struct {
+	PageType enums.PageType
+	PageID   uuid.UUID
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FaqGroupDefaultLimit    = 5 // Questions per category in the grouped listing
+	FaqGroupMaxLimit        = 50
+	FaqCategoryDefaultLimit = 20 // Questions per page of a single category
+	FaqCategoryMaxLimit     = 100
+)
+
+// FaqSummary is the question only projection of a faq content
+type FaqSummary struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title" example:"How do I reset my password?"`
+	URL       string    `json:"url" example:"/faq/reset-password"`
+	URLAlias  string    `json:"url_alias" example:"faq/reset-password"`
+	PublishOn time.Time `json:"publish_on"`
+}
+
+// FaqCategoryCount is one published category with the number of published faqs in it
+type FaqCategoryCount struct {
+	CategoryTypeID   uuid.UUID `json:"category_type_id"`
+	TypeCode         string    `json:"type_code"`
+	CategoryTypeName string    `json:"category_type_name"`
+	CategoryID       uuid.UUID `json:"category_id"`
+	Name             string    `json:"name"`
+	Description      *string   `json:"description,omitempty"`
+	Weight           int       `json:"weight"`
+	Count            int64     `json:"count"`
+}
+
+// FaqCategorySummary is a faq summary ranked inside one of its categories
+type FaqCategorySummary struct {
+	CategoryID uuid.UUID
+	FaqSummary
+}
+
+type FaqCategoryNode struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name" example:"Account"`
+	Description *string   `json:"description,omitempty"`
+	Weight      int       `json:"weight" example:"1"`
+	Count       int64     `json:"count" example:"12"`
+}
+
+type FaqCategoryTypeNode struct {
+	ID         uuid.UUID         `json:"id"`
+	TypeCode   string            `json:"type_code" example:"category-faq"`
+	Name       string            `json:"name" example:"FAQ"`
+	Categories []FaqCategoryNode `json:"categories"`
+}
+
+type FaqCategoryGroup struct {
+	CategoryID uuid.UUID    `json:"category_id"`
+	Name       string       `json:"name" example:"Account"`
+	Weight     int          `json:"weight" example:"1"`
+	TotalCount int64        `json:"totalCount" example:"12"`
+	Items      []FaqSummary `json:"items"`
+}
+
+type FaqCategoryTreeSuccessResponse200 struct {
+	Message string                `json:"message" example:"Faq categories retrieved successfully"`
+	Items   []FaqCategoryTypeNode `json:"items"`
+}
+
+type FaqGroupedSuccessResponse200 struct {
+	Message string             `json:"message" example:"Faq pages retrieved successfully"`
+	Items   []FaqCategoryGroup `json:"items"`
+}
+
+type FaqByCategorySuccessResponse200 struct {
+	Message    string          `json:"message" example:"Faq pages retrieved successfully"`
+	Category   FaqCategoryNode `json:"category"`
+	TotalCount int64           `json:"totalCount" example:"12"`
+	Page       int             `json:"page" example:"1"`
+	Limit      int             `json:"limit" example:"20"`
+	Items      []FaqSummary    `json:"items"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+// FieldSelection is the fields and include params of an app request, both left out means the full response.
+// fields=title,components.props keeps the title of the content and the props of its components,
+// include=components,categories expands those relations with all their fields.
+type FieldSelection struct {
+	Fields   []string            // Top-level fields, all when empty
+	Includes map[string][]string // Relations to expand with their fields, all fields when the list is empty
+}
+
+func (s FieldSelection) IsEmpty() bool {
+	return len(s.Fields) == 0 && len(s.Includes) == 0
+}
+
+// Wants reports whether a top-level field ends up in the response
+func (s FieldSelection) Wants(field string) bool {
+	if len(s.Fields) == 0 {
+		return true
+	}
+	for _, f := range s.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Expands reports whether a relation ends up in the response
+func (s FieldSelection) Expands(relation string) bool {
+	if s.IsEmpty() {
+		return true
+	}
+	_, ok := s.Includes[relation]
+	return ok
+}
+
+// ContentProjection is what a content query loads, the zero value loads the columns and relations of the full response
+type ContentProjection struct {
+	Columns  []string            // Content columns, all when empty
+	Preloads map[string][]string // Relations with their columns, all columns when the list is empty. Nil loads every relation.
+}
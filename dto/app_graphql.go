@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"github.com/MadManJJ/cms-api/models"
+
+	"github.com/google/uuid"
+)
+
+// GraphQLRequest is the body of a GraphQL POST, GET requests carry the same fields as query params
+type GraphQLRequest struct {
+	Query         string                 `json:"query" example:"{ landingPage(alias: \"summer-sale\", language: \"en\") { title } }"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    *GraphQLExtensions     `json:"extensions,omitempty"`
+}
+
+type GraphQLExtensions struct {
+	PersistedQuery *GraphQLPersistedQuery `json:"persistedQuery,omitempty"`
+}
+
+// GraphQLPersistedQuery follows the automatic persisted query protocol: a request may send only the hash of a
+// query it sent before, the full query is needed once after a miss
+type GraphQLPersistedQuery struct {
+	Version    int    `json:"version" example:"1"`
+	Sha256Hash string `json:"sha256Hash" example:"ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38"`
+}
+
+type GraphQLResponse struct {
+	Data   interface{}   `json:"data,omitempty" swaggertype:"object"`
+	Errors []interface{} `json:"errors,omitempty" swaggertype:"array,object"`
+}
+
+// ContentCategory is a category together with the content it is attached to
+type ContentCategory struct {
+	models.Category
+	ContentID uuid.UUID `json:"-"`
+}
@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PartnerSortRecommended     = "recommended" // Recommended first, then newest
+	PartnerSortPublishDesc     = "publish_on:desc"
+	PartnerSortPublishAsc      = "publish_on:asc"
+	PartnerSortTitleAsc        = "title:asc"
+	PartnerSortTitleDesc       = "title:desc"
+	CategoryMatchAll           = "all" // A content must match a selected category of every category type
+	CategoryMatchAny           = "any" // A content must match any selected category
+	PartnerListingMaxLimit     = 100
+	PartnerListingDefaultLimit = 12
+)
+
+type PartnerListingQuery struct {
+	Language      enums.PageLanguage
+	CategoryIDs   []uuid.UUID // Categories of the same type are always ORed
+	CategoryMatch string      // How category types are combined, all or any
+	IsRecommended *bool
+	Q             string // Matches part of the title, company name or lead body
+	Sort          string
+	Page          int
+	Limit         int
+	Fields        []string // Card fields to load, all when empty
+}
+
+// PartnerCard is the lightweight projection of a partner content used by listing pages
+type PartnerCard struct {
+	ID               uuid.UUID `json:"id"`
+	Title            string    `json:"title" example:"Acme Corporation"`
+	ThumbnailImage   string    `json:"thumbnail_image"`
+	ThumbnailAltText string    `json:"thumbnail_alt_text"`
+	CompanyLogo      string    `json:"company_logo"`
+	CompanyAltText   string    `json:"company_alt_text"`
+	CompanyName      string    `json:"company_name" example:"Acme"`
+	LeadBody         string    `json:"lead_body"`
+	URL              string    `json:"url" example:"/partners/acme"`
+	URLAlias         string    `json:"url_alias" example:"partners/acme"`
+	IsRecommended    bool      `json:"is_recommended"`
+	PublishOn        time.Time `json:"publish_on"`
+}
+
+type PartnerCategoryFacet struct {
+	CategoryID     uuid.UUID `json:"category_id"`
+	Name           string    `json:"name" example:"Retail"`
+	CategoryTypeID uuid.UUID `json:"category_type_id"`
+	TypeCode       string    `json:"type_code" example:"Partner"`
+	Count          int64     `json:"count" example:"4"`
+}
+
+// PartnerListingFacets counts ignore the filter they describe, so every option stays selectable
+type PartnerListingFacets struct {
+	Categories  []PartnerCategoryFacet `json:"categories"`
+	Recommended int64                  `json:"recommended" example:"3"`
+}
+
+type PartnerListingSuccessResponse200 struct {
+	Message    string               `json:"message" example:"Partner pages retrieved successfully"`
+	TotalCount int64                `json:"totalCount" example:"24"`
+	Page       int                  `json:"page" example:"1"`
+	Limit      int                  `json:"limit" example:"12"`
+	Items      []PartnerCard        `json:"items"`
+	Facets     PartnerListingFacets `json:"facets"`
+}
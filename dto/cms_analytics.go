@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+type PageViewRequest struct {
+	PageType    enums.PageType     `json:"page_type" example:"landing"`
+	PageID      string             `json:"page_id" example:"3f2b8c1e-8a4d-4f7e-9c2a-1b5d6e7f8a9b"`
+	Language    enums.PageLanguage `json:"language" example:"en"`
+	Referrer    string             `json:"referrer,omitempty" example:"https://www.google.com/search?q=cms"` // Only the host is kept
+	UTMSource   string             `json:"utm_source,omitempty" example:"newsletter"`
+	UTMMedium   string             `json:"utm_medium,omitempty" example:"email"`
+	UTMCampaign string             `json:"utm_campaign,omitempty" example:"summer-sale"`
+}
+
+type AnalyticsQuery struct {
+	From     time.Time
+	To       time.Time
+	PageType enums.PageType     `form:"pageType" json:"page_type"`
+	Language enums.PageLanguage `form:"language" json:"language"`
+	PageID   *uuid.UUID         `form:"pageId" json:"page_id"`
+}
+
+type PageViewPoint struct {
+	Day      time.Time `json:"day"`
+	Views    int64     `json:"views" example:"120"`
+	Visitors int64     `json:"visitors" example:"95"`
+}
+
+type ReferrerTraffic struct {
+	ReferrerHost string `json:"referrer_host" example:"www.google.com"`
+	Views        int64  `json:"views" example:"40"`
+}
+
+type PageAnalytics struct {
+	PageType  enums.PageType    `json:"page_type" example:"landing"`
+	PageID    uuid.UUID         `json:"page_id"`
+	Views     int64             `json:"views" example:"1200"`
+	Visitors  int64             `json:"visitors" example:"950"` // Distinct visitors of the range, a visitor returning on another day counts again
+	Series    []PageViewPoint   `json:"series"`
+	Referrers []ReferrerTraffic `json:"referrers"`
+}
+
+type TopPage struct {
+	PageType enums.PageType     `json:"page_type" example:"landing"`
+	PageID   uuid.UUID          `json:"page_id"`
+	Language enums.PageLanguage `json:"language" example:"en"`
+	Title    string             `json:"title" example:"Summer Sale"`
+	Views    int64              `json:"views" example:"1200"`
+	Visitors int64              `json:"visitors" example:"950"`
+}
+
+type CampaignTraffic struct {
+	UTMSource   string `json:"utm_source" example:"newsletter"`
+	UTMMedium   string `json:"utm_medium" example:"email"`
+	UTMCampaign string `json:"utm_campaign" example:"summer-sale"`
+	Views       int64  `json:"views" example:"300"`
+	Visitors    int64  `json:"visitors" example:"250"`
+	Pages       int64  `json:"pages" example:"3"` // Distinct pages the campaign brought views to
+}
+
+type PageViewSuccessResponse202 struct {
+	Message string `json:"message" example:"page view recorded"`
+}
+
+type PageAnalyticsSuccessResponse200 struct {
+	Message string        `json:"message" example:"successfully get page analytics"`
+	Item    PageAnalytics `json:"item"`
+}
+
+type TopPagesSuccessResponse200 struct {
+	Message string    `json:"message" example:"successfully get top pages"`
+	Items   []TopPage `json:"items"`
+}
+
+type CampaignTrafficSuccessResponse200 struct {
+	Message string            `json:"message" example:"successfully get campaign traffic"`
+	Items   []CampaignTraffic `json:"items"`
+}
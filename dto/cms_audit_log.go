@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogQuery struct {
+	UserID     *uuid.UUID `json:"user_id"`
+	Action     string     `json:"action"`
+	EntityType string     `json:"entity_type"`
+	EntityID   *uuid.UUID `json:"entity_id"`
+	From       time.Time  `json:"from"`
+	To         time.Time  `json:"to"`
+}
+
+type AuditLogSuccessResponse200 struct {
+	Message string          `json:"message" example:"successfully get audit log"`
+	Item    models.AuditLog `json:"item"`
+}
+
+type AuditLogsSuccessResponse200 struct {
+	Message    string            `json:"message" example:"successfully get audit logs"`
+	TotalCount int               `json:"totalCount" example:"100"`
+	Page       int               `json:"page" example:"1"`
+	Limit      int               `json:"limit" example:"10"`
+	Items      []models.AuditLog `json:"items"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+)
+
+type AutosaveResponse struct {
+	ID            string             `json:"id"`
+	PageType      enums.PageType     `json:"page_type" example:"landing"`
+	PageID        string             `json:"page_id"`
+	Language      enums.PageLanguage `json:"language" example:"en"`
+	BaseContentID string             `json:"base_content_id"`
+	Payload       json.RawMessage    `json:"payload" swaggertype:"object"` // Same body as the update content endpoint
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// NewerAutosave is another user's autosave of the same page and language, saved after the caller's one
+type NewerAutosave struct {
+	UserID        string    `json:"user_id"`
+	UserEmail     string    `json:"user_email,omitempty" example:"editor@example.com"`
+	BaseContentID string    `json:"base_content_id"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type AutosaveState struct {
+	Autosave       *AutosaveResponse `json:"autosave"` // Null when the user has no autosave
+	NewerAutosaves []NewerAutosave   `json:"newer_autosaves"`
+	BaseOutdated   bool              `json:"base_outdated"` // The content was saved again since the autosave was started
+}
+
+type CMSAutosaveSuccessResponse200 struct {
+	Message string        `json:"message" example:"successfully get autosave"`
+	Data    AutosaveState `json:"data"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+)
+
+type CalendarQuery struct {
+	From       time.Time
+	To         time.Time
+	PageType   enums.PageType       `form:"pageType" json:"page_type"`
+	Language   enums.PageLanguage   `form:"language" json:"language"`
+	Status     enums.WorkflowStatus `form:"status" json:"status"`
+	CategoryID string               `form:"categoryId" json:"category_id"`
+	Author     string               `form:"author" json:"author"` // Matches part of the revision author
+	Access     *PageAccess          `form:"-" json:"-"`           // Set by the service, the calendar only has pages the user may read
+}
+
+type CalendarEvent struct {
+	UID            string                  `json:"uid"`
+	Type           enums.CalendarEventType `json:"type" example:"publish"`
+	At             time.Time               `json:"at"`
+	PageType       enums.PageType          `json:"page_type" example:"landing"`
+	PageID         string                  `json:"page_id"`
+	ContentID      string                  `json:"content_id"`
+	Language       enums.PageLanguage      `json:"language" example:"en"`
+	Title          string                  `json:"title" example:"Summer campaign"`
+	WorkflowStatus enums.WorkflowStatus    `json:"workflow_status" example:"Schedule"`
+	Author         string                  `json:"author,omitempty"`
+	EditURL        string                  `json:"edit_url" example:"https://cms.example.com/landing-pages/.../content/.../edit?lang=en"`
+}
+
+type CalendarFeedTokenResponse struct {
+	URL       string    `json:"url" example:"https://api.example.com/api/v1/calendar/feeds/5f2b..."`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CMSCalendarSuccessResponse200 struct {
+	Message string          `json:"message" example:"successfully get calendar events"`
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Items   []CalendarEvent `json:"items"`
+}
+
+type CMSCalendarFeedTokenSuccessResponse201 struct {
+	Message string                    `json:"message" example:"successfully issue calendar feed"`
+	Item    CalendarFeedTokenResponse `json:"item"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "github.com/MadManJJ/cms-api/models/enums"
+
+type ContentAuditFinding struct {
+	Code     string              `json:"code" example:"meta_title_missing"`
+	Severity enums.AuditSeverity `json:"severity" example:"critical"`
+	Field    string              `json:"field" example:"meta_tag.title"`
+	Message  string              `json:"message" example:"Meta title is missing"`
+}
+
+type ContentAuditReport struct {
+	ContentID   string                `json:"content_id"`
+	PageType    enums.PageType        `json:"page_type" example:"landing"`
+	Score       int                   `json:"score" example:"80"`
+	HasCritical bool                  `json:"has_critical"`
+	Findings    []ContentAuditFinding `json:"findings"`
+}
+
+type CMSContentAuditSuccessResponse200 struct {
+	Message string             `json:"message" example:"successfully audit the content"`
+	Item    ContentAuditReport `json:"item"`
+}
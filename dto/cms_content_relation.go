@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// ReplaceContentRelationsRequest lists every related page of a content, in display order
+type ReplaceContentRelationsRequest struct {
+	Relations []ContentRelationRequest `json:"relations"`
+}
+
+type ContentRelationRequest struct {
+	RelationType   enums.RelationType `json:"relation_type" example:"related_article"`
+	TargetPageType enums.PageType     `json:"target_page_type" example:"partner"`
+	TargetPageID   string             `json:"target_page_id" example:"3f8b5a57-1f4e-4c47-9a53-7d2b1e6f0a11"`
+}
+
+// RelationSource is the content the relations belong to
+type RelationSource struct {
+	PageID         uuid.UUID            `json:"page_id"`
+	Language       enums.PageLanguage   `json:"language"`
+	Mode           enums.PageMode       `json:"mode"`
+	WorkflowStatus enums.WorkflowStatus `json:"workflow_status"`
+}
+
+// RelationTarget is the published content of a related page, Path is relative to the language root
+type RelationTarget struct {
+	PageID    uuid.UUID `json:"page_id"`
+	Title     string    `json:"title"`
+	Path      string    `json:"path"`
+	Thumbnail string    `json:"thumbnail"`
+}
+
+// IncomingRelation is a relation pointing to the looked up page, with the current title of its source
+type IncomingRelation struct {
+	models.ContentRelation
+	SourceTitle string `json:"source_title" example:"Summer Sale"`
+}
+
+type ContentRelationsSuccessResponse200 struct {
+	Message string                   `json:"message" example:"successfully get relations"`
+	Items   []models.ContentRelation `json:"items"`
+}
+
+type IncomingRelationsSuccessResponse200 struct {
+	Message string             `json:"message" example:"successfully get incoming relations"`
+	Items   []IncomingRelation `json:"items"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+// CursorQuery asks for one keyset page of a list, an empty Cursor asks for the first one
+type CursorQuery struct {
+	Cursor    string `json:"cursor"`
+	Limit     int    `json:"limit" example:"20"`
+	WithTotal bool   `json:"with_total"` // Also count every matching row, costs a second query
+}
+
+// CursorPage links the neighbouring pages of a keyset page, a cursor is empty when there is no such page
+type CursorPage struct {
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+	Limit      int    `json:"limit" example:"20"`
+	TotalCount *int64 `json:"totalCount,omitempty" example:"1250"`
+}
@@ -8,4 +8,5 @@ type SendEmailRequest struct {
 	Language               enums.PageLanguage     `json:"language" validate:"required,oneof=th en"` // Language of the email content
 	ToRecipientEmails      []string               `json:"to_recipient_emails" validate:"omitempty,dive,email"`
 	Data                   map[string]interface{} `json:"data" validate:"required"` // Placeholders and their values
+	IdempotencyKey         string                 `json:"-"`                        // Set when the email is queued from an event, a second email with the same key is dropped
 }
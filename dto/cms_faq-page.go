@@ -11,6 +11,8 @@ type FaqPageQuery struct {
 	Status           string `form:"status" json:"status"`
 	UrlAlias         string `form:"url_alias" json:"url_alias"`
 	URL              string `form:"url" json:"url"`
+
+	Access *PageAccess `form:"-" json:"-"` // Set by the service, the list only has pages the user may read
 }
 
 type CMSFaqPagesSuccessResponse200 struct {
@@ -42,10 +44,22 @@ type CMSFaqRevsionsSuccessResponse200 struct {
 }
 
 type UpdatedMetaTag struct {
-	ID          string `json:"id" example:"uuid" swaggerignore:"true"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	CoverImage  string `json:"cover_image"`
+	ID                 string            `json:"id" example:"uuid" swaggerignore:"true"`
+	Title              string            `json:"title"`
+	Description        string            `json:"description"`
+	CoverImage         string            `json:"cover_image"`
+	Robots             string            `json:"robots"`
+	CanonicalURL       string            `json:"canonical_url"`
+	OGTitle            string            `json:"og_title"`
+	OGDescription      string            `json:"og_description"`
+	OGImage            string            `json:"og_image"`
+	OGType             string            `json:"og_type"`
+	TwitterCard        string            `json:"twitter_card"`
+	TwitterTitle       string            `json:"twitter_title"`
+	TwitterDescription string            `json:"twitter_description"`
+	TwitterImage       string            `json:"twitter_image"`
+	CustomMeta         map[string]string `json:"custom_meta"`
+	StructuredData     interface{}       `json:"structured_data" swaggertype:"object"`
 }
 
 type UpdatedFaqContent struct {
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FeedbackIntervalDay   = "day"
+	FeedbackIntervalWeek  = "week"
+	FeedbackIntervalMonth = "month"
+	FeedbackExportMaxRows = 10000 // Rows written by a CSV export
+)
+
+type FaqFeedbackRequest struct {
+	Helpful  *bool  `json:"helpful" example:"true"`
+	Feedback string `json:"feedback,omitempty" example:"The steps did not match the app"`
+}
+
+type FaqFeedbackQuery struct {
+	From     time.Time
+	To       time.Time
+	Language enums.PageLanguage `form:"language" json:"language"`
+	PageID   *uuid.UUID         `form:"pageId" json:"page_id"`
+}
+
+type HelpfulnessPoint struct {
+	Period     time.Time `json:"period"`
+	Helpful    int64     `json:"helpful" example:"42"`
+	NotHelpful int64     `json:"not_helpful" example:"8"`
+	Ratio      float64   `json:"ratio" example:"0.84"` // Helpful share of the votes
+}
+
+type FaqHelpfulnessRank struct {
+	PageID     uuid.UUID          `json:"page_id"`
+	Language   enums.PageLanguage `json:"language" example:"en"`
+	Title      string             `json:"title" example:"How do I reset my password?"`
+	Helpful    int64              `json:"helpful" example:"2"`
+	NotHelpful int64              `json:"not_helpful" example:"9"`
+	Ratio      float64            `json:"ratio" example:"0.18"`
+}
+
+type FaqFeedbackComment struct {
+	ID           uuid.UUID          `json:"id"`
+	PageID       uuid.UUID          `json:"page_id"`
+	FaqContentID uuid.UUID          `json:"faq_content_id"`
+	Language     enums.PageLanguage `json:"language" example:"en"`
+	Title        string             `json:"title" example:"How do I reset my password?"`
+	Helpful      bool               `json:"helpful"`
+	Feedback     string             `json:"feedback" example:"The steps did not match the app"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+type FaqFeedbackSuccessResponse201 struct {
+	Message string `json:"message" example:"feedback recorded"`
+}
+
+type FaqHelpfulnessSeriesSuccessResponse200 struct {
+	Message string             `json:"message" example:"successfully get helpfulness"`
+	Items   []HelpfulnessPoint `json:"items"`
+}
+
+type FaqLowestRatedSuccessResponse200 struct {
+	Message string               `json:"message" example:"successfully get lowest rated faqs"`
+	Items   []FaqHelpfulnessRank `json:"items"`
+}
+
+type FaqFeedbackCommentsSuccessResponse200 struct {
+	Message    string               `json:"message" example:"successfully get feedback"`
+	TotalCount int64                `json:"totalCount" example:"120"`
+	Page       int                  `json:"page" example:"1"`
+	Limit      int                  `json:"limit" example:"20"`
+	Items      []FaqFeedbackComment `json:"items"`
+}
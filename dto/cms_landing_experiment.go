@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+type CreateLandingExperimentRequest struct {
+	PageID   string                           `json:"page_id" example:"3f8b5a57-1f4e-4c47-9a53-7d2b1e6f0a11"`
+	Language string                           `json:"language" example:"en"`
+	Name     string                           `json:"name" example:"Hero layout"`
+	Variants []CreateExperimentVariantRequest `json:"variants"`
+}
+
+type CreateExperimentVariantRequest struct {
+	Name      string                 `json:"name" example:"Split hero"`
+	Weight    int                    `json:"weight" example:"50"`
+	IsControl bool                   `json:"is_control" example:"false"`
+	Content   *LandingVariantContent `json:"content,omitempty"` // Required unless is_control
+}
+
+// LandingVariantContent replaces the matching parts of the published content, blank fields keep the published ones
+type LandingVariantContent struct {
+	Title      string              `json:"title,omitempty" example:"Summer Sale"`
+	HTMLInput  string              `json:"html_input,omitempty"`
+	Components []*models.Component `json:"components,omitempty"`
+}
+
+type PromoteExperimentRequest struct {
+	VariantID string                `json:"variant_id" example:"9c2e7d4b-5a61-4b0f-8e3d-2f1a6c7b8d90"`
+	Revision  CreateRevisionRequest `json:"revision"`
+}
+
+// ExperimentAssignment tells the app which variant a visitor was served
+type ExperimentAssignment struct {
+	ExperimentID uuid.UUID `json:"experiment_id"`
+	VariantID    uuid.UUID `json:"variant_id"`
+	VariantName  string    `json:"variant_name" example:"Split hero"`
+}
+
+type ExperimentVariantResult struct {
+	VariantID      uuid.UUID `json:"variant_id"`
+	Name           string    `json:"name" example:"Split hero"`
+	IsControl      bool      `json:"is_control" example:"false"`
+	Weight         int       `json:"weight" example:"50"`
+	Exposures      int64     `json:"exposures" example:"1200"`
+	Conversions    int64     `json:"conversions" example:"96"`
+	ConversionRate float64   `json:"conversion_rate" example:"0.08"`
+	Lift           *float64  `json:"lift,omitempty" example:"0.2"`     // Relative to the control rate, nil for the control or when it has no conversions
+	PValue         *float64  `json:"p_value,omitempty" example:"0.03"` // Two-proportion z-test against the control
+	Significant    bool      `json:"significant" example:"true"`
+}
+
+type ExperimentResults struct {
+	ExperimentID      uuid.UUID                 `json:"experiment_id"`
+	Name              string                    `json:"name" example:"Hero layout"`
+	Status            enums.ExperimentStatus    `json:"status" example:"running"`
+	WinnerVariantID   *uuid.UUID                `json:"winner_variant_id,omitempty"`
+	SignificanceLevel float64                   `json:"significance_level" example:"0.05"`
+	Variants          []ExperimentVariantResult `json:"variants"`
+}
+
+// ExperimentEventCount is the number of visitors with one event type on one variant
+type ExperimentEventCount struct {
+	VariantID uuid.UUID
+	Type      enums.ExperimentEventType
+	Visitors  int64
+}
+
+type LandingExperimentSuccessResponse201 struct {
+	Message string                   `json:"message" example:"successfully create experiment"`
+	Item    models.LandingExperiment `json:"item"`
+}
+
+type LandingExperimentsSuccessResponse200 struct {
+	Message string                     `json:"message" example:"successfully get experiments"`
+	Items   []models.LandingExperiment `json:"items"`
+}
+
+type ExperimentResultsSuccessResponse200 struct {
+	Message string            `json:"message" example:"successfully get experiment results"`
+	Item    ExperimentResults `json:"item"`
+}
+
+type ConversionSuccessResponse202 struct {
+	Message string `json:"message" example:"conversion recorded"`
+}
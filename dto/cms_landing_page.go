@@ -13,6 +13,8 @@ type LandingPageQuery struct {
 	CategoryKeywords string `form:"category_keywords" json:"category_keywords"`
 	Status           string `form:"status" json:"status"`
 	UrlAlias         string `form:"url_alias" json:"url_alias"`
+
+	Access *PageAccess `form:"-" json:"-"` // Set by the service, the list only has pages the user may read
 }
 
 type CMSLandingPageSuccessResponse200 struct {
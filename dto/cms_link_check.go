@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+)
+
+type LinkCheckQuery struct {
+	PageID     string           `form:"pageId" json:"page_id"`
+	PageType   enums.PageType   `form:"pageType" json:"page_type"`
+	Status     enums.LinkStatus `form:"status" json:"status"`
+	IsInternal *bool            `form:"isInternal" json:"is_internal"`
+	Access     *PageAccess      `form:"-" json:"-"` // Set by the service, the list only has links of pages the user may read
+}
+
+type LinkCheckRunSummary struct {
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	ContentsScanned int       `json:"contents_scanned" example:"42"`
+	LinksChecked    int       `json:"links_checked" example:"310"`
+	Broken          int       `json:"broken" example:"3"`
+	Errors          int       `json:"errors" example:"1"`
+}
+
+type LinkCheckResponse struct {
+	ID            string             `json:"id"`
+	PageType      enums.PageType     `json:"page_type" example:"partner"`
+	PageID        string             `json:"page_id"`
+	ContentID     string             `json:"content_id"`
+	Language      enums.PageLanguage `json:"language" example:"en"`
+	Field         string             `json:"field" example:"html_input"`
+	URL           string             `json:"url" example:"https://example.com/old-campaign"`
+	IsInternal    bool               `json:"is_internal"`
+	Status        enums.LinkStatus   `json:"status" example:"broken"`
+	StatusCode    int                `json:"status_code,omitempty" example:"404"`
+	ErrorMessage  string             `json:"error_message,omitempty"`
+	LastCheckedAt time.Time          `json:"last_checked_at"`
+}
+
+type CMSLinkChecksSuccessResponse200 struct {
+	Message    string              `json:"message" example:"successfully get link checks"`
+	TotalCount int                 `json:"totalCount" example:"100"`
+	Page       int                 `json:"page" example:"1"`
+	Limit      int                 `json:"limit" example:"10"`
+	Items      []LinkCheckResponse `json:"items"`
+}
+
+type CMSLinkCheckRunSuccessResponse202 struct {
+	Message string `json:"message" example:"link check started"`
+}
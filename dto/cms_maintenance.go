@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type MaintenanceReport struct {
+	DryRun           bool             `json:"dry_run"`
+	StartedAt        time.Time        `json:"started_at"`
+	FinishedAt       time.Time        `json:"finished_at"`
+	HistoryRetention int              `json:"history_retention" example:"20"`
+	ExpiredPreviews  map[string]int   `json:"expired_previews"` // Contents per page type
+	PrunedHistories  map[string]int   `json:"pruned_histories"` // Contents per page type
+	Removed          map[string]int64 `json:"removed"`          // Rows per table, would be removed in a dry run
+}
+
+type MaintenanceMetrics struct {
+	Runs         int                `json:"runs" example:"12"`
+	DryRuns      int                `json:"dry_runs" example:"2"`
+	TotalRemoved map[string]int64   `json:"total_removed"` // Rows per table since the server started
+	LastRun      *MaintenanceReport `json:"last_run,omitempty"`
+}
+
+type CMSMaintenanceReportSuccessResponse200 struct {
+	Message string            `json:"message" example:"successfully run maintenance cleanup"`
+	Data    MaintenanceReport `json:"data"`
+}
+
+type CMSMaintenanceMetricsSuccessResponse200 struct {
+	Message string             `json:"message" example:"successfully get maintenance metrics"`
+	Data    MaintenanceMetrics `json:"data"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// ContentEventData is the payload of the content.* domain events, the content fields are empty when a whole page was deleted
+type ContentEventData struct {
+	PageType       enums.PageType       `json:"page_type"`
+	PageID         uuid.UUID            `json:"page_id"`
+	ContentID      *uuid.UUID           `json:"content_id,omitempty"`
+	Language       enums.PageLanguage   `json:"language,omitempty"`
+	Mode           enums.PageMode       `json:"mode,omitempty"`
+	Title          string               `json:"title,omitempty"`
+	Path           string               `json:"path,omitempty"` // Url alias of landing contents, url of the others
+	WorkflowStatus enums.WorkflowStatus `json:"workflow_status,omitempty"`
+	Author         string               `json:"author,omitempty"` // Author of the revision
+	ApprovalEmail  []string             `json:"approval_email,omitempty"`
+}
+
+type FormSubmissionEventData struct {
+	FormID        uuid.UUID       `json:"form_id"`
+	SubmissionID  uuid.UUID       `json:"submission_id"`
+	SubmittedAt   time.Time       `json:"submitted_at"`
+	SubmittedData json.RawMessage `json:"submitted_data"`
+}
+
+type MediaFileEventData struct {
+	MediaFileID uuid.UUID  `json:"media_file_id"`
+	Name        string     `json:"name"`
+	DownloadURL string     `json:"download_url"`
+	Replaced    bool       `json:"replaced,omitempty"` // The upload took the place, and the url, of an existing file
+	ReplacedID  *uuid.UUID `json:"replaced_id,omitempty"`
+}
+
+type OutboxDeliveryQuery struct {
+	Subscriber string                     `form:"subscriber" json:"subscriber"`
+	EventType  enums.DomainEventType      `form:"eventType" json:"event_type"`
+	Status     enums.OutboxDeliveryStatus `form:"status" json:"status"`
+}
+
+type OutboxDeliverySuccessResponse200 struct {
+	Message string                `json:"message" example:"successfully get outbox delivery"`
+	Item    models.OutboxDelivery `json:"item"`
+}
+
+type OutboxDeliveryRetrySuccessResponse202 struct {
+	Message string                `json:"message" example:"outbox delivery queued"`
+	Item    models.OutboxDelivery `json:"item"`
+}
+
+type OutboxDeliveriesSuccessResponse200 struct {
+	Message    string                  `json:"message" example:"successfully get outbox deliveries"`
+	TotalCount int                     `json:"totalCount" example:"100"`
+	Page       int                     `json:"page" example:"1"`
+	Limit      int                     `json:"limit" example:"10"`
+	Items      []models.OutboxDelivery `json:"items"`
+}
@@ -16,6 +16,8 @@ type PartnerPageQuery struct {
 	URL               string `form:"url" json:"url"`
 
 	Status string `form:"status" json:"status"`
+
+	Access *PageAccess `form:"-" json:"-"` // Set by the service, the list only has pages the user may read
 }
 
 type CMSPartnerPageSuccessResponse200 struct {
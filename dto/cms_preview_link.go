@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/google/uuid"
+)
+
+type IssuePreviewLinkRequest struct {
+	PageType  enums.PageType
+	PageID    uuid.UUID
+	ContentID uuid.UUID
+	Language  enums.PageLanguage
+	TTL       time.Duration // 0 uses the configured default
+	Password  string        // Optional, required again when opening the link
+}
+
+type PreviewLinkQuery struct {
+	PageID         string         `form:"pageId" json:"page_id"`
+	PageType       enums.PageType `form:"pageType" json:"page_type"`
+	IncludeInvalid bool           `form:"includeInvalid" json:"include_invalid"` // Also list expired and revoked links
+	Access         *PageAccess    `form:"-" json:"-"`                            // Set by the service, the list only has links to pages the user may read
+}
+
+type PreviewLinkResponse struct {
+	ID                string             `json:"id"`
+	PageType          enums.PageType     `json:"page_type" example:"landing"`
+	PageID            string             `json:"page_id"`
+	ContentID         string             `json:"content_id"`
+	Language          enums.PageLanguage `json:"language" example:"en"`
+	URL               string             `json:"url,omitempty" example:"https://www.example.com/preview/en/landing?token=eyJhbGciOi..."`
+	PasswordProtected bool               `json:"password_protected"`
+	ExpiresAt         time.Time          `json:"expires_at"`
+	RevokedAt         *time.Time         `json:"revoked_at,omitempty"`
+	CreatedAt         time.Time          `json:"created_at"`
+}
+
+type CMSPreviewLinkSuccessResponse200 struct {
+	Message string              `json:"message" example:"successfully preview landing content"`
+	URL     string              `json:"url" example:"https://www.example.com/preview/en/landing?token=eyJhbGciOi..."`
+	Item    PreviewLinkResponse `json:"item"`
+}
+
+type CMSPreviewLinksSuccessResponse200 struct {
+	Message    string                `json:"message" example:"successfully get preview links"`
+	TotalCount int                   `json:"totalCount" example:"100"`
+	Page       int                   `json:"page" example:"1"`
+	Limit      int                   `json:"limit" example:"10"`
+	Items      []PreviewLinkResponse `json:"items"`
+}
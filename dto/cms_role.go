@@ -0,0 +1,104 @@
+package dto
+
+import (
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+type AssignUserRoleRequest struct {
+	Role string `json:"role" example:"editor"` // Empty takes the role away
+}
+
+type RolesSuccessResponse200 struct {
+	Message string        `json:"message" example:"successfully get roles"`
+	Items   []models.Role `json:"items"`
+}
+
+type UserRoleSuccessResponse200 struct {
+	Message string      `json:"message" example:"successfully assign role"`
+	Item    models.User `json:"item"`
+}
+
+type CreatePageGrantRequest struct {
+	PageType   string  `json:"page_type" example:"partner"`                                // landing, partner or faq
+	Language   string  `json:"language" example:"en"`                                      // Any language when empty
+	CategoryID *string `json:"category_id" example:"7c1d1f5e-7d1f-4c1a-9a40-0e7f5c3b9a11"` // Any category when empty
+	Action     string  `json:"action" example:"update"`                                    // create, read, update, publish, delete or *
+}
+
+type PageGrantSuccessResponse200 struct {
+	Message string           `json:"message" example:"successfully create page grant"`
+	Item    models.PageGrant `json:"item"`
+}
+
+type PageGrantsSuccessResponse200 struct {
+	Message string             `json:"message" example:"successfully get page grants"`
+	Items   []models.PageGrant `json:"items"`
+}
+
+// PageContentScope is what page grants are matched on for one content of a page
+type PageContentScope struct {
+	ContentID   uuid.UUID
+	Language    enums.PageLanguage
+	Mode        enums.PageMode
+	CategoryIDs []uuid.UUID
+}
+
+// PageAccess is what the user of a request may do to pages beyond the action of the route.
+// A nil PageAccess allows everything, services use it for work that does not come from a user.
+type PageAccess struct {
+	CanPublish bool               // Whether the role of the user allows publishing pages
+	Grants     []models.PageGrant // Without grants the user reaches every page, else only those one of them matches
+
+	denied bool
+}
+
+// NoPageAccess is the access of a request the page access middleware did not run for, it allows nothing
+func NoPageAccess() *PageAccess {
+	return &PageAccess{denied: true}
+}
+
+// AllowsAll reports whether the action is allowed on every page of the type, so no page needs to be looked at
+func (a *PageAccess) AllowsAll(pageType enums.PageType, action enums.PermissionAction) bool {
+	if a == nil {
+		return true
+	}
+	if a.denied {
+		return false
+	}
+	if action == enums.PermissionActionPublish && !a.CanPublish {
+		return false
+	}
+	return len(a.Grants) == 0
+}
+
+// Allows reports whether the action is allowed on the content of a page of the type
+func (a *PageAccess) Allows(pageType enums.PageType, action enums.PermissionAction, content PageContentScope) bool {
+	if a.AllowsAll(pageType, action) {
+		return true
+	}
+	if action == enums.PermissionActionPublish && !a.CanPublish {
+		return false
+	}
+	for i := range a.Grants {
+		if a.Grants[i].Matches(pageType, action, content.Language, content.CategoryIDs) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadGrants returns the grants a list of pages of the type is limited to, scoped is false when every page can be read
+func (a *PageAccess) ReadGrants(pageType enums.PageType) (grants []models.PageGrant, scoped bool) {
+	if a.AllowsAll(pageType, enums.PermissionActionRead) {
+		return nil, false
+	}
+	for _, grant := range a.Grants {
+		if grant.PageType == pageType && (grant.Action == enums.PermissionActionRead || grant.Action == enums.PermissionActionAll) {
+			grants = append(grants, grant)
+		}
+	}
+	return grants, true
+}
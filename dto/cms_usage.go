@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+type UsageIndexSummary struct {
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	ReferrersScanned int       `json:"referrers_scanned" example:"120"`
+	References       int       `json:"references" example:"340"`
+}
+
+// UsagePageScope selects the referrers of one page to index again, every language of it when Language is empty
+type UsagePageScope struct {
+	PageType enums.PageType
+	PageID   uuid.UUID
+	Language enums.PageLanguage
+}
+
+// UsagePagePath is a url a current content of a page is served under, links are resolved to pages with them
+type UsagePagePath struct {
+	PageType enums.PageType
+	PageID   uuid.UUID
+	Path     string
+}
+
+// UsageImpact tells what a delete of the item would leave pointing to nothing
+type UsageImpact struct {
+	ItemType            enums.UsageItemType     `json:"item_type" example:"media_file"`
+	ItemID              uuid.UUID               `json:"item_id"`
+	IndexedAt           time.Time               `json:"indexed_at"`
+	References          int                     `json:"references" example:"3"`
+	PublishedReferences int                     `json:"published_references" example:"1"`
+	Blocked             bool                    `json:"blocked"` // The delete needs force=true
+	Usages              []models.UsageReference `json:"usages"`
+}
+
+type CMSUsagesSuccessResponse200 struct {
+	Message   string                  `json:"message" example:"successfully get usages"`
+	IndexedAt time.Time               `json:"indexed_at"`
+	Items     []models.UsageReference `json:"items"`
+}
+
+type CMSUsageImpactSuccessResponse200 struct {
+	Message string      `json:"message" example:"successfully get delete impact"`
+	Data    UsageImpact `json:"data"`
+}
+
+type CMSUsageIndexSuccessResponse200 struct {
+	Message string            `json:"message" example:"successfully rebuild usage index"`
+	Data    UsageIndexSummary `json:"data"`
+}
+
+type UsageConflictResponse409 struct {
+	Message string      `json:"message" example:"item is still in use, pass force=true to delete it anyway"`
+	Error   string      `json:"error" example:"item is referenced by published content"`
+	Data    UsageImpact `json:"data"`
+}
@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEndpointRequest struct {
+	Name       string                   `json:"name" example:"Search indexer"`
+	URL        string                   `json:"url" example:"https://search.example.com/hooks/cms"`
+	EventTypes []enums.WebhookEventType `json:"event_types" example:"content.published,content.unpublished"`
+	IsActive   *bool                    `json:"is_active,omitempty" example:"true"` // Defaults to true
+}
+
+// WebhookEndpointSecretResponse is only sent when the secret is created or rotated, it is never shown again
+type WebhookEndpointSecretResponse struct {
+	models.WebhookEndpoint
+	Secret string `json:"secret" example:"whsec_6f1c0e0b9d7a4e58a1f3c2b7d9e4a6c1"`
+}
+
+// WebhookEvent is the body posted to the endpoints
+type WebhookEvent struct {
+	ID         uuid.UUID              `json:"id"`
+	Type       enums.WebhookEventType `json:"type" example:"content.published"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       interface{}            `json:"data"`
+}
+
+// WebhookContentData is the data of the content.* events, ContentID and the content fields are empty when a whole page was deleted
+type WebhookContentData struct {
+	PageType       enums.PageType       `json:"page_type" example:"landing"`
+	PageID         uuid.UUID            `json:"page_id"`
+	ContentID      *uuid.UUID           `json:"content_id,omitempty"`
+	Language       enums.PageLanguage   `json:"language,omitempty" example:"en"`
+	Title          string               `json:"title,omitempty" example:"Summer Sale"`
+	Path           string               `json:"path,omitempty" example:"summer-sale"` // Url alias of landing contents, url of the others
+	URL            string               `json:"url,omitempty" example:"https://www.example.com/en/summer-sale"`
+	WorkflowStatus enums.WorkflowStatus `json:"workflow_status,omitempty" example:"Published"`
+}
+
+type WebhookFormSubmittedData struct {
+	FormID        uuid.UUID       `json:"form_id"`
+	SubmissionID  uuid.UUID       `json:"submission_id"`
+	SubmittedAt   time.Time       `json:"submitted_at"`
+	SubmittedData json.RawMessage `json:"submitted_data" swaggertype:"object"`
+}
+
+type WebhookMediaUploadedData struct {
+	MediaFileID string `json:"media_file_id"`
+	Name        string `json:"name" example:"banner.png"`
+	DownloadURL string `json:"download_url" example:"https://api.example.com/files/banner.png"`
+	Replaced    bool   `json:"replaced" example:"false"` // An existing file kept its url with new bytes
+}
+
+type WebhookDeliveryQuery struct {
+	EndpointID string                      `form:"webhookId" json:"webhook_id"`
+	EventType  enums.WebhookEventType      `form:"eventType" json:"event_type"`
+	Status     enums.WebhookDeliveryStatus `form:"status" json:"status"`
+}
+
+type WebhookEndpointSuccessResponse200 struct {
+	Message string                 `json:"message" example:"successfully get webhook"`
+	Item    models.WebhookEndpoint `json:"item"`
+}
+
+type WebhookEndpointSecretSuccessResponse201 struct {
+	Message string                        `json:"message" example:"successfully create webhook"`
+	Item    WebhookEndpointSecretResponse `json:"item"`
+}
+
+type WebhookEndpointSecretSuccessResponse200 struct {
+	Message string                        `json:"message" example:"successfully rotate webhook secret"`
+	Item    WebhookEndpointSecretResponse `json:"item"`
+}
+
+type WebhookEndpointsSuccessResponse200 struct {
+	Message string                   `json:"message" example:"successfully get webhooks"`
+	Items   []models.WebhookEndpoint `json:"items"`
+}
+
+type WebhookDeliverySuccessResponse200 struct {
+	Message string                 `json:"message" example:"successfully get webhook delivery"`
+	Item    models.WebhookDelivery `json:"item"`
+}
+
+type WebhookDeliveryReplaySuccessResponse202 struct {
+	Message string                 `json:"message" example:"webhook delivery queued"`
+	Item    models.WebhookDelivery `json:"item"`
+}
+
+type WebhookDeliveriesSuccessResponse200 struct {
+	Message    string                   `json:"message" example:"successfully get webhook deliveries"`
+	TotalCount int                      `json:"totalCount" example:"100"`
+	Page       int                      `json:"page" example:"1"`
+	Limit      int                      `json:"limit" example:"10"`
+	Items      []models.WebhookDelivery `json:"items"`
+}
@@ -27,4 +27,64 @@ var (
 	ErrNoRevisionFound               = errors.New("no revision found")
 	ErrDuplicateURL                  = errors.New("duplicate URL")
 	ErrInvalidUrlAlias               = errors.New("invalid URL alias")
+	ErrInvalidPageType               = errors.New("invalid page type")
+	ErrCriticalAuditFindings         = errors.New("content has critical audit findings")
+	ErrLinkCheckInProgress           = errors.New("link check is already in progress")
+	ErrInvalidLinkStatus             = errors.New("invalid link status")
+	ErrInvalidPreviewTTL             = errors.New("invalid preview ttl")
+	ErrInvalidPreviewToken           = errors.New("invalid preview token")
+	ErrPreviewLinkExpired            = errors.New("preview link has expired")
+	ErrPreviewLinkRevoked            = errors.New("preview link has been revoked")
+	ErrPreviewPasswordRequired       = errors.New("preview password is required")
+	ErrInvalidPreviewPassword        = errors.New("invalid preview password")
+	ErrPreviewLinkLocked             = errors.New("too many wrong preview passwords, try again later")
+	ErrPreviewFrontendURLMissing     = errors.New("preview frontend url is not configured")
+	ErrMaintenanceInProgress         = errors.New("maintenance cleanup is already in progress")
+	ErrAutosaveNotFound              = errors.New("autosave not found")
+	ErrAutosaveOutdated              = errors.New("autosave is based on an outdated content version")
+	ErrInvalidAutosavePayload        = errors.New("autosave payload must be a JSON object")
+	ErrInvalidCalendarRange          = errors.New("invalid calendar date range")
+	ErrInvalidCalendarFeedToken      = errors.New("invalid calendar feed token")
+	ErrInvalidRenderMode             = errors.New("invalid render mode")
+	ErrInvalidListingSort            = errors.New("invalid listing sort")
+	ErrInvalidCategoryMatch          = errors.New("category match must be all or any")
+	ErrCategoryTypeRequired          = errors.New("category type is required")
+	ErrInvalidFeedback               = errors.New("helpful must be true or false")
+	ErrFeedbackTooLong               = errors.New("feedback is too long")
+	ErrInvalidDateRange              = errors.New("invalid date range")
+	ErrInvalidInterval               = errors.New("interval must be day, week or month")
+	ErrInvalidPageView               = errors.New("page type, page id and language are required")
+	ErrInvalidExperiment             = errors.New("an experiment needs a name, one control and another variant with positive weights")
+	ErrExperimentRunning             = errors.New("an experiment is already running for this page and language")
+	ErrExperimentCompleted           = errors.New("experiment already has a winner")
+	ErrExperimentNotRunning          = errors.New("experiment is not running")
+	ErrExperimentNotExposed          = errors.New("no variant was served to this visitor")
+	ErrInvalidExperimentVariant      = errors.New("variant does not belong to the experiment")
+	ErrInvalidExperimentStatus       = errors.New("experiment status must be running or completed")
+	ErrAssignmentKeyRequired         = errors.New("assignment key is required")
+	ErrInvalidRelation               = errors.New("a relation needs a relation type and a target page")
+	ErrSelfRelation                  = errors.New("a page cannot be related to itself")
+	ErrDuplicateRelation             = errors.New("a related page is listed twice")
+	ErrTooManyRelations              = errors.New("too many related pages")
+	ErrRelationTargetNotFound        = errors.New("related page does not exist")
+	ErrRelationsLocked               = errors.New("relations of a published, scheduled or history content cannot change, save a new version first")
+	ErrInvalidUsageItemType          = errors.New("item type must be category, media_file, landing_page, partner_page or faq_page")
+	ErrItemInUse                     = errors.New("item is referenced by published content")
+	ErrInvalidCursor                 = errors.New("invalid cursor")
+	ErrUnsupportedCursorSort         = errors.New("sort is not supported with cursor pagination")
+	ErrMissingGraphQLQuery           = errors.New("query is required")
+	ErrQueryTooDeep                  = errors.New("query is nested too deep")
+	ErrQueryTooComplex               = errors.New("query is too complex")
+	ErrPersistedQueryNotFound        = errors.New("PersistedQueryNotFound") // Exact message clients look for to resend the full query
+	ErrPersistedQueryHashMismatch    = errors.New("provided sha does not match query")
+	ErrUnknownField                  = errors.New("unknown field")
+	ErrInvalidWebhookEndpoint        = errors.New("a webhook needs a name, an http or https url and at least one event type")
+	ErrInvalidWebhookEventType       = errors.New("event type must be content.published, content.unpublished, content.deleted, form.submitted or media.uploaded")
+	ErrInvalidWebhookDeliveryStatus  = errors.New("delivery status must be pending, succeeded or failed")
+	ErrInvalidDomainEventType        = errors.New("event type must be content.saved, content.deleted, form_submission.created, media_file.uploaded, media_file.deleted or email.requested")
+	ErrInvalidOutboxDeliveryStatus   = errors.New("delivery status must be pending, succeeded or dead")
+	ErrOutboxDeliverySucceeded       = errors.New("delivery already succeeded")
+	ErrInvalidRole                   = errors.New("role does not exist")
+	ErrInvalidPageGrant              = errors.New("a page grant needs a page type of landing, partner or faq, an action of create, read, update, publish, delete or * and a valid language and category")
+	ErrPageAccessDenied              = errors.New("page is outside the page grants of the user")
 )
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AppAnalyticsHandler struct {
+	Service services.CMSAnalyticsServiceInterface
+}
+
+func NewAppAnalyticsHandler(service services.CMSAnalyticsServiceInterface) *AppAnalyticsHandler {
+	return &AppAnalyticsHandler{Service: service}
+}
+
+// HandleTrackPageView handles POST requests to record a view of a published page
+// @Summary      Track Page View
+// @Description  Records a view of a landing, partner or faq page. Only the referrer host is kept and the client ip is never stored.
+// @Description  The body is read as JSON whatever the content type, so it can be sent with navigator.sendBeacon. Clients sending DNT or Sec-GPC are not tracked.
+// @Tags         App - Analytics
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.PageViewRequest  true  "Page view"
+// @Success      202  {object} dto.PageViewSuccessResponse202
+// @Failure      400  {object} dto.ErrorResponse400
+// @Failure      429  {object} dto.ErrorResponse "Too many requests"
+// @Router       /app/analytics/views [post]
+func (h *AppAnalyticsHandler) HandleTrackPageView(c *fiber.Ctx) error {
+	if c.Get("DNT") == "1" || c.Get("Sec-GPC") == "1" {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "page view not tracked",
+		})
+	}
+
+	var request dto.PageViewRequest
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := h.Service.TrackPageView(request, c.IP(), c.Get(fiber.HeaderUserAgent)); err != nil {
+		if errors.Is(err, errs.ErrInvalidPageView) ||
+			errors.Is(err, errs.ErrInvalidPageType) ||
+			errors.Is(err, errs.ErrInvalidUUIDFormat) ||
+			errors.Is(err, errs.ErrInvalidLanguageCode) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid page view",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to record page view",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "page view recorded",
+	})
+}
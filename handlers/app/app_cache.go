@@ -0,0 +1,143 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// pageCacheKey identifies an app page response: the page type, whether it was looked up by alias or url,
+// the alias or url, the language, the field selection and the render mode
+func pageCacheKey(pageType enums.PageType, lookup, slug, language string, selection dto.FieldSelection, mode enums.RenderMode) string {
+	return strings.Join([]string{string(pageType), lookup, slug, language, canonicalSelection(selection), string(mode)}, "|")
+}
+
+// canonicalSelection writes the selection in a fixed order, fields=b,a and fields=a,b share a cache entry
+func canonicalSelection(selection dto.FieldSelection) string {
+	fields := append([]string{}, selection.Fields...)
+	sort.Strings(fields)
+
+	includes := make([]string, 0, len(selection.Includes))
+	for relation, relationFields := range selection.Includes {
+		relationFields = append([]string{}, relationFields...)
+		sort.Strings(relationFields)
+		includes = append(includes, relation+"("+strings.Join(relationFields, ",")+")")
+	}
+	sort.Strings(includes)
+
+	return strings.Join(fields, ",") + ";" + strings.Join(includes, ",")
+}
+
+// sendCachedPage answers from the cache, reporting false when the response is not cached
+func sendCachedPage(c *fiber.Ctx, cache services.AppResponseCacheInterface, key string) (bool, error) {
+	response, ok := cache.Get(key)
+	if !ok {
+		return false, nil
+	}
+	return true, helpers.SendCachedResponse(c, response, cache.MaxAge())
+}
+
+// sendAndCachePage writes the page response with its caching headers and keeps it for the next requests
+func sendAndCachePage(c *fiber.Ctx, cache services.AppResponseCacheInterface, key string, body fiber.Map, page pageCacheInfo) error {
+	response, err := helpers.NewCachedResponse(c, body, page.lastModified, page.loadedAt)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to encode the response",
+			"error":   err.Error(),
+		})
+	}
+
+	cache.Set(key, response, page.tags)
+	return helpers.SendCachedResponse(c, response, cache.MaxAge())
+}
+
+// pageCacheInfo collects what a page response was built from while its contents are walked
+type pageCacheInfo struct {
+	tags         dto.CacheTags
+	lastModified time.Time
+	loadedAt     time.Time
+}
+
+func newPageCacheInfo(pageType enums.PageType, pageId uuid.UUID, updatedAt, loadedAt time.Time) pageCacheInfo {
+	return pageCacheInfo{
+		tags:         dto.CacheTags{Pages: []dto.CachedPage{{PageType: pageType, PageID: pageId}}},
+		lastModified: updatedAt,
+		loadedAt:     loadedAt,
+	}
+}
+
+// addContent tags the response with the related pages and categories of a content, a change to any of them shows in it
+func (p *pageCacheInfo) addContent(updatedAt time.Time, related []models.RelatedPage, categories []*models.Category) {
+	p.touch(updatedAt)
+	for _, page := range related {
+		p.tags.Pages = append(p.tags.Pages, dto.CachedPage{PageType: page.PageType, PageID: page.PageID})
+	}
+	for _, category := range categories {
+		p.tags.CategoryIDs = append(p.tags.CategoryIDs, category.ID)
+		p.touch(category.UpdatedAt)
+	}
+}
+
+// addLandingMedia tags the response with the media a landing content links to, a replaced or deleted file shows in it
+func (p *pageCacheInfo) addLandingMedia(content *models.LandingContent) {
+	for _, file := range content.Files {
+		if file != nil {
+			p.addMedia(file.DownloadURL)
+		}
+	}
+	p.addMedia(helpers.ExtractHTMLLinks(content.HTMLInput)...)
+	p.addMetaTagMedia(content.MetaTag)
+	p.addComponentMedia(content.Components)
+}
+
+func (p *pageCacheInfo) addPartnerMedia(content *models.PartnerContent) {
+	p.addMedia(content.ThumbnailImage, content.CompanyLogo)
+	for _, html := range []string{content.HTMLInput, content.CompanyDetail, content.LeadBody, content.Challenges, content.Solutions, content.Results} {
+		p.addMedia(helpers.ExtractHTMLLinks(html)...)
+	}
+	p.addMetaTagMedia(content.MetaTag)
+	p.addComponentMedia(content.Components)
+}
+
+func (p *pageCacheInfo) addFaqMedia(content *models.FaqContent) {
+	p.addMedia(helpers.ExtractHTMLLinks(content.HTMLInput)...)
+	p.addMetaTagMedia(content.MetaTag)
+	p.addComponentMedia(content.Components)
+}
+
+func (p *pageCacheInfo) addMetaTagMedia(metaTag *models.MetaTag) {
+	if metaTag != nil {
+		p.addMedia(metaTag.CoverImage, metaTag.OGImage, metaTag.TwitterImage)
+	}
+}
+
+func (p *pageCacheInfo) addComponentMedia(components []*models.Component) {
+	for _, component := range components {
+		if component != nil {
+			p.addMedia(helpers.ExtractComponentLinks(component.Props)...)
+		}
+	}
+}
+
+func (p *pageCacheInfo) addMedia(links ...string) {
+	for _, link := range links {
+		if helpers.IsCheckableLink(strings.TrimSpace(link)) {
+			p.tags.MediaURLs = append(p.tags.MediaURLs, link)
+		}
+	}
+}
+
+func (p *pageCacheInfo) touch(updatedAt time.Time) {
+	if updatedAt.After(p.lastModified) {
+		p.lastModified = updatedAt
+	}
+}
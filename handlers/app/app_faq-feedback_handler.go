@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AppFaqFeedbackHandler struct {
+	Service services.CMSFaqFeedbackServiceInterface
+}
+
+func NewAppFaqFeedbackHandler(service services.CMSFaqFeedbackServiceInterface) *AppFaqFeedbackHandler {
+	return &AppFaqFeedbackHandler{Service: service}
+}
+
+// HandleCreateFaqFeedback handles POST requests to vote whether a FAQ answer was helpful
+// @Summary      Vote on a Faq answer
+// @Description  Records a helpful or not helpful vote with optional feedback text for a published FAQ content.
+// @Description  A client has one vote per FAQ page and language, voting again replaces it. Votes are rate limited per client.
+// @Tags         App - Faq Pages
+// @Accept       json
+// @Produce      json
+// @Param        contentId  path  string  true  "Faq Content ID"
+// @Param        request  body  dto.FaqFeedbackRequest  true  "Vote"
+// @Success      201  {object} dto.FaqFeedbackSuccessResponse201
+// @Failure      400  {object} dto.ErrorResponse400
+// @Failure      404  {object} dto.ErrorResponse404
+// @Failure      429  {object} dto.ErrorResponse "Too many requests"
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/faqpages/feedback/{contentId} [post]
+func (h *AppFaqFeedbackHandler) HandleCreateFaqFeedback(c *fiber.Ctx) error {
+	contentId, err := uuid.Parse(c.Params("contentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the contentId",
+			"error":   errs.ErrInvalidUUIDFormat.Error(),
+		})
+	}
+
+	var request dto.FaqFeedbackRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := h.Service.RecordFeedback(contentId, request, c.IP(), c.Get(fiber.HeaderUserAgent)); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidFeedback), errors.Is(err, errs.ErrFeedbackTooLong):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid feedback",
+				"error":   err.Error(),
+			})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Faq content not found",
+				"error":   err.Error(),
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "failed to record feedback",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "feedback recorded",
+	})
+}
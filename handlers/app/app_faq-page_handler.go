@@ -1,19 +1,29 @@
 package app
 
 import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AppFaqPageHandler struct {
-	Service services.AppFaqPageServiceInterface
+	Service            services.AppFaqPageServiceInterface
+	PreviewLinkService services.CMSPreviewLinkServiceInterface
+	Renderer           services.ComponentRendererInterface
+	Cache              services.AppResponseCacheInterface
 }
 
-func NewAppFaqPageHandler(service services.AppFaqPageServiceInterface) *AppFaqPageHandler {
-	return &AppFaqPageHandler{Service: service}
+func NewAppFaqPageHandler(service services.AppFaqPageServiceInterface, previewLinkService services.CMSPreviewLinkServiceInterface, renderer services.ComponentRendererInterface, cache services.AppResponseCacheInterface) *AppFaqPageHandler {
+	return &AppFaqPageHandler{Service: service, PreviewLinkService: previewLinkService, Renderer: renderer, Cache: cache}
 }
 
 // HandleGetFaqPage handles GET requests to retrieve a Faq Page by its UrlAlias
@@ -23,8 +33,15 @@ func NewAppFaqPageHandler(service services.AppFaqPageServiceInterface) *AppFaqPa
 // @Produce      json
 // @Param        languageCode  path  string  true  "Language"
 // @Param        url_alias  query  string  true  "Faq Page UrlAlias"
-// @Param        select  query     string  false  "Comma-separated list of fields to preload (e.g.revisions, categories, components, metatag). If blank, all fields will be preloaded."
+// @Param        fields  query     string  false  "Comma-separated content fields to return, a dotted field selects a field of a relation (e.g. title,url_alias,components.props). Unknown fields are rejected."
+// @Param        include  query     string  false  "Comma-separated relations to return with all their fields (e.g. revision, categories, components, meta_tag). Left out with fields, all relations are returned."
+// @Param        select  query     string  false  "Older name of include, still accepted"
+// @Param        render  query     string  false  "html adds rendered_html to each content, html-only also drops the component JSON"
+// @Param        If-None-Match  header  string  false  "ETag of the response the client has"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified of the response the client has"
 // @Success      200  {object} dto.FaqPageSuccessResponse200
+// @Success      304  "The response the client has is still current"
+// @Failure      400  {object} dto.ErrorResponse "Invalid render mode, fields or include"
 // @Failure 		 404  {object} dto.ErrorResponse404
 // @Failure      500  {object} dto.ErrorResponse500 
 // @Router       /app/faqpages/{languageCode}/by-alias [get]
@@ -33,11 +50,27 @@ func (h *AppFaqPageHandler) HandleGetFaqPageByAlias(c *fiber.Ctx) error {
 	language := c.Params("languageCode")
 	isAlias := true	
 
-	selectParam := c.Query("select")
+	selection, err := fieldSelectionFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+	renderMode, err := parseRenderMode(c)
+	if err != nil {
+		return renderModeErrorResponse(c, err)
+	}
+
+	cacheKey := pageCacheKey(enums.PageTypeFaq, "alias", slug, language, selection, renderMode)
+	if cached, err := sendCachedPage(c, h.Cache, cacheKey); cached {
+		return err
+	}
+	loadedAt := time.Now()
 
-	faqPage, err := h.Service.GetFaqPage(slug, isAlias, selectParam, language)	
+	faqPage, err := h.Service.GetFaqPage(slug, isAlias, withComponentsIncluded(selection, renderMode), language)	
 
 	if err != nil {
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
 		switch err {
 			case errs.ErrNotFound:
 				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -52,10 +85,27 @@ func (h *AppFaqPageHandler) HandleGetFaqPageByAlias(c *fiber.Ctx) error {
 		}
 	}	
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	cacheInfo := newPageCacheInfo(enums.PageTypeFaq, faqPage.ID, faqPage.UpdatedAt, loadedAt)
+	for _, content := range faqPage.Contents {
+		cacheInfo.addContent(content.UpdatedAt, content.Related, content.Categories)
+		cacheInfo.addFaqMedia(content)
+		if err := renderFaqContent(h.Renderer, renderMode, content); err != nil {
+			return renderErrorResponse(c, err)
+		}
+	}
+
+	data, err := projectPageContents(faqPage, selection)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Faq page",
+			"error":   err.Error(),
+		})
+	}
+
+	return sendAndCachePage(c, h.Cache, cacheKey, fiber.Map{
 		"message": "Faq page retrieved successfully",
-		"data": faqPage,	
-	})	
+		"data":    data,
+	}, cacheInfo)
 }
 
 // HandleGetFaqPage handles GET requests to retrieve a Faq Page by its Url
@@ -65,8 +115,15 @@ func (h *AppFaqPageHandler) HandleGetFaqPageByAlias(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        languageCode  path  string  true  "Language"
 // @Param        url  query  string  true  "Faq Page Url"
-// @Param        select  query     string  false  "Comma-separated list of fields to preload (e.g.revisions, categories, components, metatag). If blank, all fields will be preloaded."
+// @Param        fields  query     string  false  "Comma-separated content fields to return, a dotted field selects a field of a relation (e.g. title,url_alias,components.props). Unknown fields are rejected."
+// @Param        include  query     string  false  "Comma-separated relations to return with all their fields (e.g. revision, categories, components, meta_tag). Left out with fields, all relations are returned."
+// @Param        select  query     string  false  "Older name of include, still accepted"
+// @Param        render  query     string  false  "html adds rendered_html to each content, html-only also drops the component JSON"
+// @Param        If-None-Match  header  string  false  "ETag of the response the client has"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified of the response the client has"
 // @Success      200  {object} dto.FaqPageSuccessResponse200
+// @Success      304  "The response the client has is still current"
+// @Failure      400  {object} dto.ErrorResponse "Invalid render mode, fields or include"
 // @Failure 		 404  {object} dto.ErrorResponse404
 // @Failure      500  {object} dto.ErrorResponse500 
 // @Router       /app/faqpages/{languageCode}/by-url [get]
@@ -75,11 +132,27 @@ func (h *AppFaqPageHandler) HandleGetFaqPageByUrl(c *fiber.Ctx) error {
 	language := c.Params("languageCode")
 	isAlias := false	
 
-	selectParam := c.Query("select")
+	selection, err := fieldSelectionFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+	renderMode, err := parseRenderMode(c)
+	if err != nil {
+		return renderModeErrorResponse(c, err)
+	}
+
+	cacheKey := pageCacheKey(enums.PageTypeFaq, "url", slug, language, selection, renderMode)
+	if cached, err := sendCachedPage(c, h.Cache, cacheKey); cached {
+		return err
+	}
+	loadedAt := time.Now()
 
-	faqPage, err := h.Service.GetFaqPage(slug, isAlias, selectParam, language)	
+	faqPage, err := h.Service.GetFaqPage(slug, isAlias, withComponentsIncluded(selection, renderMode), language)	
 
 	if err != nil {
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
 		switch err {
 			case errs.ErrNotFound:
 				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -94,42 +167,221 @@ func (h *AppFaqPageHandler) HandleGetFaqPageByUrl(c *fiber.Ctx) error {
 		}
 	}	
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	cacheInfo := newPageCacheInfo(enums.PageTypeFaq, faqPage.ID, faqPage.UpdatedAt, loadedAt)
+	for _, content := range faqPage.Contents {
+		cacheInfo.addContent(content.UpdatedAt, content.Related, content.Categories)
+		cacheInfo.addFaqMedia(content)
+		if err := renderFaqContent(h.Renderer, renderMode, content); err != nil {
+			return renderErrorResponse(c, err)
+		}
+	}
+
+	data, err := projectPageContents(faqPage, selection)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Faq page",
+			"error":   err.Error(),
+		})
+	}
+
+	return sendAndCachePage(c, h.Cache, cacheKey, fiber.Map{
 		"message": "Faq page retrieved successfully",
-		"data": faqPage,	
-	})	
+		"data":    data,
+	}, cacheInfo)
 }
 
 // HandleGetFaqContentPreview handles GET requests to retrieve a Faq Content Preview
-// @Summary      Get Faq Content by content id
-// @Description  Retrieves a Faq Content by its id
+// @Summary      Get Faq Content by preview token
+// @Description  Retrieves the preview copy of a Faq Content from a signed preview link token
 // @Tags         App - Faq Pages
 // @Produce      json
-// @Param        id  path  string  true  "Content Id"
+// @Param        token  path  string  true  "Preview token"
+// @Param        X-Preview-Password  header  string  false  "Password of a protected preview link"
+// @Param        fields  query     string  false  "Comma-separated content fields to return, a dotted field selects a field of a relation. Unknown fields are rejected."
+// @Param        include  query     string  false  "Comma-separated relations to return with all their fields (e.g. components, meta_tag)"
+// @Param        render  query     string  false  "html adds rendered_html to the content, html-only also drops the component JSON"
 // @Success      200  {object} dto.FaqContentSuccessResponse200
+// @Failure      400  {object} dto.ErrorResponse "Invalid render mode, fields or include"
+// @Failure 		 401  {object} dto.ErrorResponse "Preview password missing or invalid"
 // @Failure 		 404  {object} dto.ErrorResponse404
-// @Failure      500  {object} dto.ErrorResponse500 
-// @Router       /app/faqpages/previews/{id} [get]
+// @Failure 		 410  {object} dto.ErrorResponse "Preview link expired or revoked"
+// @Failure 		 429  {object} dto.ErrorResponse "Preview link locked after too many wrong passwords"
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/faqpages/previews/{token} [get]
 func (h *AppFaqPageHandler) HandleGetFaqContentPreview(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
+	selection, err := fieldSelectionFromRequest(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "failed to parse the id",
+		return fieldSelectionErrorResponse(c, err)
+	}
+	renderMode, err := parseRenderMode(c)
+	if err != nil {
+		return renderModeErrorResponse(c, err)
+	}
+
+	previewLink, err := h.PreviewLinkService.ResolvePreviewToken(c.Params("token"), c.Get("X-Preview-Password"), enums.PageTypeFaq)
+	if err != nil {
+		return previewLinkErrorResponse(c, err)
+	}
+
+	faqContent, err := h.Service.GetFaqContentPreview(previewLink.ContentID, withComponentsIncluded(selection, renderMode))
+	if err != nil {
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "preview content not found",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to preview the content",
 			"error":   err.Error(),
 		})
-	}	
+	}
 
-	faqContent, err := h.Service.GetFaqContentPreview(id)
+	if err := renderFaqContent(h.Renderer, renderMode, faqContent); err != nil {
+		return renderErrorResponse(c, err)
+	}
+
+	data, err := projectFields(faqContent, selection)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed to preview the content",
 			"error":   err.Error(),
-		})		
+		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "successfully get preview content",
-		"data": faqContent,
-	})	
-}
\ No newline at end of file
+		"data":    data,
+	})
+}
+
+// HandleGetFaqCategoryTree handles GET requests to retrieve the FAQ category tree
+// @Summary      Get Faq category tree
+// @Description  Lists the active category types with their published categories and the number of published FAQs in each
+// @Tags         App - Faq Pages
+// @Produce      json
+// @Param        languageCode  path  string  true  "Language"
+// @Param        type  query  string  false  "Only this category type code"
+// @Success      200  {object} dto.FaqCategoryTreeSuccessResponse200
+// @Failure      400  {object} dto.ErrorResponse400
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/faqpages/{languageCode}/categories [get]
+func (h *AppFaqPageHandler) HandleGetFaqCategoryTree(c *fiber.Ctx) error {
+	tree, err := h.Service.GetFaqCategoryTree(c.Params("languageCode"), c.Query("type"))
+	if err != nil {
+		return faqBrowseErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Faq categories retrieved successfully",
+		"items":   tree,
+	})
+}
+
+// HandleGetFaqsGroupedByCategory handles GET requests to list FAQ questions grouped by category
+// @Summary      List Faq questions grouped by category
+// @Description  Lists the most helpful published FAQ questions of every category of a type by their feedback votes, groups follow the category weight
+// @Tags         App - Faq Pages
+// @Produce      json
+// @Param        languageCode  path  string  true  "Language"
+// @Param        type  query  string  true  "Category type code to group by"
+// @Param        limit  query  int  false  "Questions per category (default is 5, max 50)"
+// @Success      200  {object} dto.FaqGroupedSuccessResponse200
+// @Failure      400  {object} dto.ErrorResponse400
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/faqpages/{languageCode}/grouped [get]
+func (h *AppFaqPageHandler) HandleGetFaqsGroupedByCategory(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(dto.FaqGroupDefaultLimit)))
+	limit = clampLimit(limit, dto.FaqGroupDefaultLimit, dto.FaqGroupMaxLimit)
+
+	groups, err := h.Service.GetFaqsGroupedByCategory(c.Params("languageCode"), c.Query("type"), limit)
+	if err != nil {
+		return faqBrowseErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Faq pages retrieved successfully",
+		"items":   groups,
+	})
+}
+
+// HandleGetFaqsByCategory handles GET requests to list the FAQ questions of one category
+// @Summary      List Faq questions of a category
+// @Description  Lists the published FAQ questions of a category, newest first
+// @Tags         App - Faq Pages
+// @Produce      json
+// @Param        languageCode  path  string  true  "Language"
+// @Param        categoryId  path  string  true  "Category ID"
+// @Param        page  query  int  false  "Page number (default is 1)"
+// @Param        limit  query  int  false  "Questions per page (default is 20, max 100)"
+// @Param        fields  query  string  false  "Comma-separated question fields to return (e.g. title,url). Unknown fields are rejected."
+// @Success      200  {object} dto.FaqByCategorySuccessResponse200
+// @Failure      400  {object} dto.ErrorResponse400
+// @Failure      404  {object} dto.ErrorResponse404
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/faqpages/{languageCode}/categories/{categoryId} [get]
+func (h *AppFaqPageHandler) HandleGetFaqsByCategory(c *fiber.Ctx) error {
+	categoryId, err := uuid.Parse(c.Params("categoryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the categoryId",
+			"error":   errs.ErrInvalidUUIDFormat.Error(),
+		})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(dto.FaqCategoryDefaultLimit)))
+	limit = clampLimit(limit, dto.FaqCategoryDefaultLimit, dto.FaqCategoryMaxLimit)
+
+	fields, err := itemFieldsFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+
+	category, summaries, totalCount, err := h.Service.GetFaqsByCategory(c.Params("languageCode"), categoryId, page, limit, fields)
+	if err != nil {
+		return faqBrowseErrorResponse(c, err)
+	}
+
+	items, err := projectFields(summaries, dto.FieldSelection{Fields: fields})
+	if err != nil {
+		return faqBrowseErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Faq pages retrieved successfully",
+		"category":   category,
+		"totalCount": totalCount,
+		"page":       page,
+		"limit":      limit,
+		"items":      items,
+	})
+}
+
+func faqBrowseErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errs.ErrInvalidLanguageCode), errors.Is(err, errs.ErrCategoryTypeRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid faq browsing query",
+			"error":   err.Error(),
+		})
+	case isFieldSelectionError(err):
+		return fieldSelectionErrorResponse(c, err)
+	case errors.Is(err, errs.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Faq category not found",
+			"error":   err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Faq pages",
+			"error":   err.Error(),
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// includeAliases are the include names select used before the names followed the JSON fields
+var includeAliases = map[string]string{"metatag": "meta_tag"}
+
+// fieldSelectionFromRequest reads the fields and include params, the older select param is read as include.
+// A dotted field selects a field of an included relation, so fields=components.props includes the components.
+func fieldSelectionFromRequest(c *fiber.Ctx) (dto.FieldSelection, error) {
+	var selection dto.FieldSelection
+
+	for _, field := range splitFieldList(c.Query("fields")) {
+		relation, nested, isNested := strings.Cut(field, ".")
+		if alias, ok := includeAliases[relation]; ok {
+			relation = alias
+		}
+		if !isNested {
+			selection.Fields = append(selection.Fields, relation)
+			continue
+		}
+		if relation == "" || nested == "" || strings.Contains(nested, ".") {
+			return dto.FieldSelection{}, fmt.Errorf("%w: %s", errs.ErrUnknownField, field)
+		}
+		if selection.Includes == nil {
+			selection.Includes = map[string][]string{}
+		}
+		selection.Includes[relation] = append(selection.Includes[relation], nested)
+	}
+
+	for _, relation := range append(splitFieldList(c.Query("include")), splitFieldList(c.Query("select"))...) {
+		if alias, ok := includeAliases[relation]; ok {
+			relation = alias
+		}
+		if selection.Includes == nil {
+			selection.Includes = map[string][]string{}
+		}
+		if _, ok := selection.Includes[relation]; !ok {
+			selection.Includes[relation] = nil
+		}
+	}
+
+	return selection, nil
+}
+
+// itemFieldsFromRequest reads the fields param of a listing, its items have no relations to include
+func itemFieldsFromRequest(c *fiber.Ctx) ([]string, error) {
+	selection, err := fieldSelectionFromRequest(c)
+	if err != nil {
+		return nil, err
+	}
+	for relation := range selection.Includes {
+		return nil, fmt.Errorf("%w: %s", errs.ErrUnknownField, relation)
+	}
+	return selection.Fields, nil
+}
+
+func splitFieldList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isFieldSelectionError(err error) bool {
+	return errors.Is(err, errs.ErrUnknownField)
+}
+
+func fieldSelectionErrorResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"message": "invalid fields or include",
+		"error":   err.Error(),
+	})
+}
+
+// withComponentsIncluded makes sure whole components are loaded when they are going to be rendered, the renders are cached
+// per content so they must never come from trimmed components. The response is still trimmed to the request's selection.
+// An empty selection already loads everything.
+func withComponentsIncluded(selection dto.FieldSelection, mode enums.RenderMode) dto.FieldSelection {
+	if mode == enums.RenderModeJSON || selection.IsEmpty() {
+		return selection
+	}
+
+	includes := map[string][]string{}
+	for relation, fields := range selection.Includes {
+		includes[relation] = fields
+	}
+	includes["components"] = nil
+	return dto.FieldSelection{Fields: selection.Fields, Includes: includes}
+}
+
+// projectFields trims a response value to the selection: with fields only those top-level fields, always with the id,
+// and the included relations are trimmed to their own fields the same way. Slices are trimmed item by item.
+func projectFields(value interface{}, selection dto.FieldSelection) (interface{}, error) {
+	if selection.IsEmpty() {
+		return value, nil
+	}
+
+	decoded, err := decodeResponseValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return trimFields(decoded, selection.Fields, selection.Includes), nil
+}
+
+// projectPageContents trims the contents of a page response, the page itself keeps its fields
+func projectPageContents(page interface{}, selection dto.FieldSelection) (interface{}, error) {
+	if selection.IsEmpty() {
+		return page, nil
+	}
+
+	decoded, err := decodeResponseValue(page)
+	if err != nil {
+		return nil, err
+	}
+	if fields, ok := decoded.(map[string]interface{}); ok {
+		if contents, ok := fields["contents"]; ok {
+			fields["contents"] = trimFields(contents, selection.Fields, selection.Includes)
+		}
+	}
+	return decoded, nil
+}
+
+// decodeResponseValue turns a response value into the maps and slices it is written as, numbers kept as written
+func decodeResponseValue(value interface{}) (interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func trimFields(value interface{}, fields []string, includes map[string][]string) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i, item := range v {
+			v[i] = trimFields(item, fields, includes)
+		}
+		return v
+	case map[string]interface{}:
+		if len(fields) > 0 {
+			keep := map[string]bool{"id": true}
+			for _, field := range fields {
+				keep[field] = true
+			}
+			for relation := range includes {
+				keep[relation] = true
+			}
+			// rendered_html is asked for with the render param, not with fields
+			keep["rendered_html"] = true
+			for key := range v {
+				if !keep[key] {
+					delete(v, key)
+				}
+			}
+		}
+		for relation, relationFields := range includes {
+			if nested, ok := v[relation]; ok && len(relationFields) > 0 {
+				v[relation] = trimFields(nested, relationFields, nil)
+			}
+		}
+		return v
+	default:
+		return value
+	}
+}
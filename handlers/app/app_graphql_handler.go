@@ -0,0 +1,85 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AppGraphQLHandler struct {
+	Service services.AppGraphQLServiceInterface
+}
+
+func NewAppGraphQLHandler(service services.AppGraphQLServiceInterface) *AppGraphQLHandler {
+	return &AppGraphQLHandler{Service: service}
+}
+
+// graphQLErrorResponse answers in the GraphQL error shape rather than message and error, GraphQL clients only read errors.
+// A persisted query miss is not a failure, the client retries with the full query.
+func graphQLErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrPersistedQueryNotFound):
+		status = fiber.StatusOK
+	case errors.Is(err, errs.ErrMissingGraphQLQuery),
+		errors.Is(err, errs.ErrQueryTooDeep),
+		errors.Is(err, errs.ErrQueryTooComplex),
+		errors.Is(err, errs.ErrPersistedQueryHashMismatch),
+		errors.Is(err, errs.ErrBadRequest):
+		status = fiber.StatusBadRequest
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"errors": []fiber.Map{{"message": err.Error()}},
+	})
+}
+
+// HandleGraphQL handles GET and POST requests to query published content with GraphQL
+// @Summary      Query Published Content
+// @Description  Read-only GraphQL over published landing, partner and FAQ pages with their components, categories and meta tags, and form structures.
+// @Description  Queries deeper than GRAPHQL_MAX_DEPTH or estimated above GRAPHQL_MAX_COMPLEXITY fields are refused.
+// @Description  Supports automatic persisted queries, GET requests then carry only extensions={"persistedQuery":{"version":1,"sha256Hash":"..."}}.
+// @Tags         App - GraphQL
+// @Accept       json
+// @Produce      json
+// @Param        request        body   dto.GraphQLRequest  false  "Query, for POST requests"
+// @Param        query          query  string  false  "Query, for GET requests"
+// @Param        operationName  query  string  false  "Operation to run, for GET requests"
+// @Param        variables      query  string  false  "Variables as JSON, for GET requests"
+// @Param        extensions     query  string  false  "Extensions as JSON, for GET requests"
+// @Success      200  {object}  dto.GraphQLResponse
+// @Failure      400  {object}  dto.GraphQLResponse
+// @Failure      500  {object}  dto.GraphQLResponse
+// @Router       /app/graphql [get]
+// @Router       /app/graphql [post]
+func (h *AppGraphQLHandler) HandleGraphQL(c *fiber.Ctx) error {
+	var request dto.GraphQLRequest
+	if c.Method() == fiber.MethodGet {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if raw := c.Query("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &request.Variables); err != nil {
+				return graphQLErrorResponse(c, errs.ErrBadRequest)
+			}
+		}
+		if raw := c.Query("extensions"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &request.Extensions); err != nil {
+				return graphQLErrorResponse(c, errs.ErrBadRequest)
+			}
+		}
+	} else if err := c.BodyParser(&request); err != nil {
+		return graphQLErrorResponse(c, errs.ErrBadRequest)
+	}
+
+	result, err := h.Service.Execute(c.UserContext(), request)
+	if err != nil {
+		return graphQLErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
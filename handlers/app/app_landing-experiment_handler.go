@@ -0,0 +1,108 @@
+package app
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	experimentCookieName   = "ab_key"
+	experimentCookieMaxAge = 90 * 24 * time.Hour
+	lineUserIdHeader       = "X-Line-User-Id"
+	experimentHeader       = "X-Experiment-Variant"
+)
+
+type AppLandingExperimentHandler struct {
+	Service services.CMSLandingExperimentServiceInterface
+}
+
+func NewAppLandingExperimentHandler(service services.CMSLandingExperimentServiceInterface) *AppLandingExperimentHandler {
+	return &AppLandingExperimentHandler{Service: service}
+}
+
+// experimentAssignmentKey identifies the visitor by LINE user id, so they keep their variant across devices,
+// otherwise by the assignment cookie. A visitor without either gets a new cookie value, which the caller
+// sets with setExperimentCookie once a variant was actually served.
+func experimentAssignmentKey(c *fiber.Ctx) (key string, newCookie string) {
+	if lineUserId := c.Get(lineUserIdHeader); lineUserId != "" {
+		return "line:" + lineUserId, ""
+	}
+
+	if cookie := c.Cookies(experimentCookieName); cookie != "" {
+		if _, err := uuid.Parse(cookie); err == nil {
+			return "cookie:" + cookie, ""
+		}
+	}
+
+	newCookie = uuid.NewString()
+	return "cookie:" + newCookie, newCookie
+}
+
+func setExperimentCookie(c *fiber.Ctx, value string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     experimentCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(experimentCookieMaxAge.Seconds()),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// HandleRecordConversion handles POST requests to record a conversion of the visitor on a running experiment
+// @Summary      Record Experiment Conversion
+// @Description  Credits the conversion to the variant the visitor was served, the visitor is identified by the X-Line-User-Id header or the ab_key cookie.
+// @Description  Converting more than once counts once.
+// @Tags         App - Landing Pages
+// @Produce      json
+// @Param        experimentId    path    string  true   "Experiment ID (UUID)"
+// @Param        X-Line-User-Id  header  string  false  "LINE user id of the visitor"
+// @Success      202  {object} dto.ConversionSuccessResponse202
+// @Failure      400  {object} dto.ErrorResponse400
+// @Failure      404  {object} dto.ErrorResponse404
+// @Failure      409  {object} dto.ErrorResponse "Experiment is not running"
+// @Failure      429  {object} dto.ErrorResponse "Too many requests"
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/landingpages/experiments/{experimentId}/conversions [post]
+func (h *AppLandingExperimentHandler) HandleRecordConversion(c *fiber.Ctx) error {
+	experimentId, err := uuid.Parse(c.Params("experimentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the experimentId",
+			"error":   err.Error(),
+		})
+	}
+
+	// A visitor who was never served a variant has nothing to convert on
+	assignmentKey, newCookie := experimentAssignmentKey(c)
+	if newCookie != "" {
+		assignmentKey = ""
+	}
+
+	if err := h.Service.RecordConversion(experimentId, assignmentKey); err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrAssignmentKeyRequired):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, errs.ErrExperimentNotExposed):
+			status = fiber.StatusNotFound
+		case errors.Is(err, errs.ErrExperimentNotRunning):
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "failed to record conversion",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "conversion recorded",
+	})
+}
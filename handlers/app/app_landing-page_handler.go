@@ -1,41 +1,78 @@
 package app
 
 import (
+	"errors"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AppLandingPageHandler struct {
-	Service services.AppLandingPageServiceInterface
+	Service            services.AppLandingPageServiceInterface
+	PreviewLinkService services.CMSPreviewLinkServiceInterface
+	Renderer           services.ComponentRendererInterface
+	ExperimentService  services.CMSLandingExperimentServiceInterface
+	Cache              services.AppResponseCacheInterface
 }
 
-func NewAppLandingPageHandler(service services.AppLandingPageServiceInterface) *AppLandingPageHandler {
-	return &AppLandingPageHandler{Service: service}
+func NewAppLandingPageHandler(service services.AppLandingPageServiceInterface, previewLinkService services.CMSPreviewLinkServiceInterface, renderer services.ComponentRendererInterface, experimentService services.CMSLandingExperimentServiceInterface, cache services.AppResponseCacheInterface) *AppLandingPageHandler {
+	return &AppLandingPageHandler{Service: service, PreviewLinkService: previewLinkService, Renderer: renderer, ExperimentService: experimentService, Cache: cache}
 }
 
 // HandleGetLandingPageByUrlAlias handles GET requests to retrieve a Landing Page by its UrlAlias
 // @Summary      Get Landing Page by UrlAlias
 // @Description  Retrieves a Landing Page by its UrlAlias
+// @Description  When an experiment is running on the page language, the content is the variant assigned to the visitor by the X-Line-User-Id header or the ab_key cookie,
+// @Description  the served variant is reported in experiment and the X-Experiment-Variant header.
+// @Description  Responses without an experiment carry ETag, Last-Modified and Cache-Control headers, a matching If-None-Match or If-Modified-Since gets a 304.
 // @Tags         App - Landing Pages
 // @Produce      json
 // @Param        languageCode  path  string  true  "Language"
+// @Param        X-Line-User-Id  header  string  false  "LINE user id of the visitor, keeps the visitor on the same experiment variant"
 // @Param        url_alias  query  string  true  "Landing Page UrlAlias"
-// @Param        select  query     string  false  "Comma-separated list of fields to preload (e.g. files, revisions, categories, components, metatag). If blank, all fields will be preloaded."
+// @Param        fields  query     string  false  "Comma-separated content fields to return, a dotted field selects a field of a relation (e.g. title,url_alias,components.props). Unknown fields are rejected."
+// @Param        include  query     string  false  "Comma-separated relations to return with all their fields (e.g. files, revision, categories, components, meta_tag). Left out with fields, all relations are returned."
+// @Param        select  query     string  false  "Older name of include, still accepted"
+// @Param        render  query     string  false  "html adds rendered_html to each content, html-only also drops the component JSON"
+// @Param        If-None-Match  header  string  false  "ETag of the response the client has"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified of the response the client has"
 // @Success      200  {object} dto.LandingPageSuccessResponse200
+// @Success      304  "The response the client has is still current"
+// @Failure      400  {object} dto.ErrorResponse "Invalid render mode, fields or include"
 // @Failure 		 404  {object} dto.ErrorResponse404
 // @Failure      500  {object} dto.ErrorResponse500 
 // @Router       /app/landingpages/{languageCode}/by-alias [get]
 func(h *AppLandingPageHandler) HandleGetLandingPageByUrlAlias(c *fiber.Ctx) error {
 	urlAlias := c.Query("url_alias")
 	language := c.Params("languageCode")
-	selectParam := c.Query("select")
+	selection, err := fieldSelectionFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+	renderMode, err := parseRenderMode(c)
+	if err != nil {
+		return renderModeErrorResponse(c, err)
+	}
 
-	landingPage, err := h.Service.GetLandingPageByUrlAlias(urlAlias, selectParam, language)
+	// Pages under an experiment are never stored, a cached page has none running
+	cacheKey := pageCacheKey(enums.PageTypeLanding, "alias", urlAlias, language, selection, renderMode)
+	if cached, err := sendCachedPage(c, h.Cache, cacheKey); cached {
+		return err
+	}
+	loadedAt := time.Now()
+
+	landingPage, err := h.Service.GetLandingPageByUrlAlias(urlAlias, withComponentsIncluded(selection, renderMode), language)
 
 	if err != nil {
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
 		switch err {
 			case errs.ErrNotFound:
 				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -50,42 +87,120 @@ func(h *AppLandingPageHandler) HandleGetLandingPageByUrlAlias(c *fiber.Ctx) erro
 		}
 	}
 
+	var assignment *dto.ExperimentAssignment
+	assignmentKey, newCookie := experimentAssignmentKey(c)
+	cacheInfo := newPageCacheInfo(enums.PageTypeLanding, landingPage.ID, landingPage.UpdatedAt, loadedAt)
+	for _, content := range landingPage.Contents {
+		cacheInfo.addContent(content.UpdatedAt, content.Related, content.Categories)
+		cacheInfo.addLandingMedia(content)
+		var variant *dto.ExperimentAssignment
+		if assignment == nil {
+			variant, err = h.ExperimentService.AssignVariant(content, assignmentKey)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"message": "Failed to assign the experiment variant",
+					"error":   err.Error(),
+				})
+			}
+			assignment = variant
+		}
+		if err := renderLandingContent(h.Renderer, renderMode, content, variant); err != nil {
+			return renderErrorResponse(c, err)
+		}
+	}
+
+	data, err := projectPageContents(landingPage, selection)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Landing page",
+			"error":   err.Error(),
+		})
+	}
+
+	if assignment == nil {
+		return sendAndCachePage(c, h.Cache, cacheKey, fiber.Map{
+			"message": "Landing page retrieved successfully",
+			"data":    data,
+		}, cacheInfo)
+	}
+
+	// The variant depends on the visitor, so shared caches must not keep it
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set(experimentHeader, assignment.VariantName)
+	if newCookie != "" {
+		setExperimentCookie(c, newCookie)
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Landing page retrieved successfully",
-		"data":    landingPage,	
+		"message":    "Landing page retrieved successfully",
+		"data":       data,
+		"experiment": assignment,
 	})
 }
 
 // HandleGetLandingContentPreview handles GET requests to retrieve a Landing Content Preview
-// @Summary      Get Landing Content by content id
-// @Description  Retrieves a Landing Content by its id
+// @Summary      Get Landing Content by preview token
+// @Description  Retrieves the preview copy of a Landing Content from a signed preview link token
 // @Tags         App - Landing Pages
 // @Produce      json
-// @Param        id  path  string  true  "Content Id"
+// @Param        token  path  string  true  "Preview token"
+// @Param        X-Preview-Password  header  string  false  "Password of a protected preview link"
+// @Param        fields  query     string  false  "Comma-separated content fields to return, a dotted field selects a field of a relation. Unknown fields are rejected."
+// @Param        include  query     string  false  "Comma-separated relations to return with all their fields (e.g. components, meta_tag)"
+// @Param        render  query     string  false  "html adds rendered_html to the content, html-only also drops the component JSON"
 // @Success      200  {object} dto.LandingContentSuccessResponse200
+// @Failure      400  {object} dto.ErrorResponse "Invalid render mode, fields or include"
+// @Failure 		 401  {object} dto.ErrorResponse "Preview password missing or invalid"
 // @Failure 		 404  {object} dto.ErrorResponse404
-// @Failure      500  {object} dto.ErrorResponse500 
-// @Router       /app/landingpages/previews/{id} [get]
+// @Failure 		 410  {object} dto.ErrorResponse "Preview link expired or revoked"
+// @Failure 		 429  {object} dto.ErrorResponse "Preview link locked after too many wrong passwords"
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/landingpages/previews/{token} [get]
 func (h *AppLandingPageHandler) HandleGetLandingContentPreview(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
+	selection, err := fieldSelectionFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+	renderMode, err := parseRenderMode(c)
+	if err != nil {
+		return renderModeErrorResponse(c, err)
+	}
+
+	previewLink, err := h.PreviewLinkService.ResolvePreviewToken(c.Params("token"), c.Get("X-Preview-Password"), enums.PageTypeLanding)
+	if err != nil {
+		return previewLinkErrorResponse(c, err)
+	}
+
+	landingContent, err := h.Service.GetLandingContentPreview(previewLink.ContentID, withComponentsIncluded(selection, renderMode))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "failed to parse the id",
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "preview content not found",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to preview the content",
 			"error":   err.Error(),
 		})
-	}	
+	}
+
+	if err := renderLandingContent(h.Renderer, renderMode, landingContent, nil); err != nil {
+		return renderErrorResponse(c, err)
+	}
 
-	landingContent, err := h.Service.GetLandingContentPreview(id)
+	data, err := projectFields(landingContent, selection)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed to preview the content",
 			"error":   err.Error(),
-		})		
+		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "successfully get preview content",
-		"data": landingContent,
-	})	
+		"data":    data,
+	})
 }
\ No newline at end of file
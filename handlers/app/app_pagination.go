@@ -0,0 +1,12 @@
+package app
+
+// clampLimit falls back to the default for a missing limit and caps it at max
+func clampLimit(limit, defaultLimit, max int) int {
+	if limit < 1 {
+		return defaultLimit
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
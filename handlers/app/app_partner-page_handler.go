@@ -1,19 +1,30 @@
 package app
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AppPartnerPageHandler struct {
-	Service services.AppPartnerPageServiceInterface
+	Service            services.AppPartnerPageServiceInterface
+	PreviewLinkService services.CMSPreviewLinkServiceInterface
+	Renderer           services.ComponentRendererInterface
+	Cache              services.AppResponseCacheInterface
 }
 
-func NewAppPartnerPageHandler(service services.AppPartnerPageServiceInterface) *AppPartnerPageHandler {
-	return &AppPartnerPageHandler{Service: service}
+func NewAppPartnerPageHandler(service services.AppPartnerPageServiceInterface, previewLinkService services.CMSPreviewLinkServiceInterface, renderer services.ComponentRendererInterface, cache services.AppResponseCacheInterface) *AppPartnerPageHandler {
+	return &AppPartnerPageHandler{Service: service, PreviewLinkService: previewLinkService, Renderer: renderer, Cache: cache}
 }
 
 // HandleGetPartnerPageByAlias handles GET requests to retrieve a Partner Page by its UrlAlias
@@ -23,8 +34,15 @@ func NewAppPartnerPageHandler(service services.AppPartnerPageServiceInterface) *
 // @Produce      json
 // @Param        languageCode  path  string  true  "Language"
 // @Param        url_alias  query  string  true  "Partner Page UrlAlias"
-// @Param        select  query     string  false  "Comma-separated list of fields to preload (e.g. page, files, components, revisions, metatag)"
+// @Param        fields  query     string  false  "Comma-separated content fields to return, a dotted field selects a field of a relation (e.g. title,url_alias,components.props). Unknown fields are rejected."
+// @Param        include  query     string  false  "Comma-separated relations to return with all their fields (e.g. revision, categories, components, meta_tag). Left out with fields, all relations are returned."
+// @Param        select  query     string  false  "Older name of include, still accepted"
+// @Param        render  query     string  false  "html adds rendered_html to each content, html-only also drops the component JSON"
+// @Param        If-None-Match  header  string  false  "ETag of the response the client has"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified of the response the client has"
 // @Success      200  {object} dto.PartnerPageSuccessResponse200
+// @Success      304  "The response the client has is still current"
+// @Failure      400  {object} dto.ErrorResponse "Invalid render mode, fields or include"
 // @Failure 		 404  {object} dto.ErrorResponse404
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /app/partnerpages/{languageCode}/by-alias [get]
@@ -33,11 +51,27 @@ func (h *AppPartnerPageHandler) HandleGetPartnerPageByAlias(c *fiber.Ctx) error
 	language := c.Params("languageCode")
 	isAlias := true	
 
-	selectParam := c.Query("select")
+	selection, err := fieldSelectionFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+	renderMode, err := parseRenderMode(c)
+	if err != nil {
+		return renderModeErrorResponse(c, err)
+	}
+
+	cacheKey := pageCacheKey(enums.PageTypePartner, "alias", slug, language, selection, renderMode)
+	if cached, err := sendCachedPage(c, h.Cache, cacheKey); cached {
+		return err
+	}
+	loadedAt := time.Now()
 
-	partnerPage, err := h.Service.GetPartnerPage(slug, isAlias, selectParam, language)
+	partnerPage, err := h.Service.GetPartnerPage(slug, isAlias, withComponentsIncluded(selection, renderMode), language)
 
 	if err != nil {
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
 		switch err {
 		case errs.ErrNotFound:
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -52,10 +86,27 @@ func (h *AppPartnerPageHandler) HandleGetPartnerPageByAlias(c *fiber.Ctx) error
 		}
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	cacheInfo := newPageCacheInfo(enums.PageTypePartner, partnerPage.ID, partnerPage.UpdatedAt, loadedAt)
+	for _, content := range partnerPage.Contents {
+		cacheInfo.addContent(content.UpdatedAt, content.Related, content.Categories)
+		cacheInfo.addPartnerMedia(content)
+		if err := renderPartnerContent(h.Renderer, renderMode, content); err != nil {
+			return renderErrorResponse(c, err)
+		}
+	}
+
+	data, err := projectPageContents(partnerPage, selection)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Partner page",
+			"error":   err.Error(),
+		})
+	}
+
+	return sendAndCachePage(c, h.Cache, cacheKey, fiber.Map{
 		"message": "Partner page retrieved successfully",
-		"data":    partnerPage,
-	})
+		"data":    data,
+	}, cacheInfo)
 }
 
 // HandleGetPartnerPageByUrl handles GET requests to retrieve a Partner Page by its UrlAlias
@@ -65,8 +116,15 @@ func (h *AppPartnerPageHandler) HandleGetPartnerPageByAlias(c *fiber.Ctx) error
 // @Produce      json
 // @Param        languageCode  path  string  true  "Language"
 // @Param        url  query  string  true  "Partner Page Url"
-// @Param        select  query     string  false  "Comma-separated list of fields to preload (e.g. page, files, components, revisions, metatag)"
+// @Param        fields  query     string  false  "Comma-separated content fields to return, a dotted field selects a field of a relation (e.g. title,url_alias,components.props). Unknown fields are rejected."
+// @Param        include  query     string  false  "Comma-separated relations to return with all their fields (e.g. revision, categories, components, meta_tag). Left out with fields, all relations are returned."
+// @Param        select  query     string  false  "Older name of include, still accepted"
+// @Param        render  query     string  false  "html adds rendered_html to each content, html-only also drops the component JSON"
+// @Param        If-None-Match  header  string  false  "ETag of the response the client has"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified of the response the client has"
 // @Success      200  {object} dto.PartnerPageSuccessResponse200
+// @Success      304  "The response the client has is still current"
+// @Failure      400  {object} dto.ErrorResponse "Invalid render mode, fields or include"
 // @Failure 		 404  {object} dto.ErrorResponse404
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /app/partnerpages/{languageCode}/by-url [get]
@@ -75,11 +133,27 @@ func (h *AppPartnerPageHandler) HandleGetPartnerPageByUrl(c *fiber.Ctx) error {
 	language := c.Params("languageCode")
 	isAlias := false	
 
-	selectParam := c.Query("select")
+	selection, err := fieldSelectionFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+	renderMode, err := parseRenderMode(c)
+	if err != nil {
+		return renderModeErrorResponse(c, err)
+	}
+
+	cacheKey := pageCacheKey(enums.PageTypePartner, "url", slug, language, selection, renderMode)
+	if cached, err := sendCachedPage(c, h.Cache, cacheKey); cached {
+		return err
+	}
+	loadedAt := time.Now()
 
-	partnerPage, err := h.Service.GetPartnerPage(slug, isAlias, selectParam, language)
+	partnerPage, err := h.Service.GetPartnerPage(slug, isAlias, withComponentsIncluded(selection, renderMode), language)
 
 	if err != nil {
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
 		switch err {
 		case errs.ErrNotFound:
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -94,42 +168,185 @@ func (h *AppPartnerPageHandler) HandleGetPartnerPageByUrl(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	cacheInfo := newPageCacheInfo(enums.PageTypePartner, partnerPage.ID, partnerPage.UpdatedAt, loadedAt)
+	for _, content := range partnerPage.Contents {
+		cacheInfo.addContent(content.UpdatedAt, content.Related, content.Categories)
+		cacheInfo.addPartnerMedia(content)
+		if err := renderPartnerContent(h.Renderer, renderMode, content); err != nil {
+			return renderErrorResponse(c, err)
+		}
+	}
+
+	data, err := projectPageContents(partnerPage, selection)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Partner page",
+			"error":   err.Error(),
+		})
+	}
+
+	return sendAndCachePage(c, h.Cache, cacheKey, fiber.Map{
 		"message": "Partner page retrieved successfully",
-		"data":    partnerPage,
-	})
+		"data":    data,
+	}, cacheInfo)
 }
 
 // HandleGetPartnerContentPreview handles GET requests to retrieve a Partner Content Preview
-// @Summary      Get Partner Content by content id
-// @Description  Retrieves a Partner Content by its id
+// @Summary      Get Partner Content by preview token
+// @Description  Retrieves the preview copy of a Partner Content from a signed preview link token
 // @Tags         App - Partner Pages
 // @Produce      json
-// @Param        id  path  string  true  "Content Id"
+// @Param        token  path  string  true  "Preview token"
+// @Param        X-Preview-Password  header  string  false  "Password of a protected preview link"
+// @Param        fields  query     string  false  "Comma-separated content fields to return, a dotted field selects a field of a relation. Unknown fields are rejected."
+// @Param        include  query     string  false  "Comma-separated relations to return with all their fields (e.g. components, meta_tag)"
+// @Param        render  query     string  false  "html adds rendered_html to the content, html-only also drops the component JSON"
 // @Success      200  {object} dto.PartnerContentSuccessResponse200
+// @Failure      400  {object} dto.ErrorResponse "Invalid render mode, fields or include"
+// @Failure 		 401  {object} dto.ErrorResponse "Preview password missing or invalid"
 // @Failure 		 404  {object} dto.ErrorResponse404
-// @Failure      500  {object} dto.ErrorResponse500 
-// @Router       /app/partnerpages/previews/{id} [get]
+// @Failure 		 410  {object} dto.ErrorResponse "Preview link expired or revoked"
+// @Failure 		 429  {object} dto.ErrorResponse "Preview link locked after too many wrong passwords"
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/partnerpages/previews/{token} [get]
 func (h *AppPartnerPageHandler) HandleGetPartnerContentPreview(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := uuid.Parse(idStr)
+	selection, err := fieldSelectionFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+	renderMode, err := parseRenderMode(c)
+	if err != nil {
+		return renderModeErrorResponse(c, err)
+	}
+
+	previewLink, err := h.PreviewLinkService.ResolvePreviewToken(c.Params("token"), c.Get("X-Preview-Password"), enums.PageTypePartner)
+	if err != nil {
+		return previewLinkErrorResponse(c, err)
+	}
+
+	partnerContent, err := h.Service.GetPartnerContentPreview(previewLink.ContentID, withComponentsIncluded(selection, renderMode))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "failed to parse the id",
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "preview content not found",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to preview the content",
 			"error":   err.Error(),
 		})
-	}	
+	}
 
-	partnerContent, err := h.Service.GetPartnerContentPreview(id)
+	if err := renderPartnerContent(h.Renderer, renderMode, partnerContent); err != nil {
+		return renderErrorResponse(c, err)
+	}
+
+	data, err := projectFields(partnerContent, selection)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed to preview the content",
 			"error":   err.Error(),
-		})		
+		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "successfully get preview content",
-		"data": partnerContent,
-	})	
-}
\ No newline at end of file
+		"data":    data,
+	})
+}
+
+// HandleGetPartnerListing handles GET requests to list the published Partner contents of a language
+// @Summary      List published Partner Pages
+// @Description  Lists published partner contents as cards with category and recommended facet counts
+// @Tags         App - Partner Pages
+// @Produce      json
+// @Param        languageCode  path  string  true  "Language"
+// @Param        categories  query  string  false  "Comma-separated category ids, categories of the same type are ORed"
+// @Param        match  query  string  false  "How category types are combined: all (default) or any"
+// @Param        recommended  query  bool  false  "Only recommended (true) or not recommended (false) partners"
+// @Param        q  query  string  false  "Matches part of the title, company name or lead body"
+// @Param        sort  query  string  false  "recommended (default), publish_on:desc, publish_on:asc, title:asc or title:desc"
+// @Param        page  query  int  false  "Page number (default is 1)"
+// @Param        limit  query  int  false  "Items per page (default is 12, max 100)"
+// @Param        fields  query  string  false  "Comma-separated card fields to return (e.g. title,url_alias,company_logo). Unknown fields are rejected."
+// @Success      200  {object} dto.PartnerListingSuccessResponse200
+// @Failure      400  {object} dto.ErrorResponse400
+// @Failure      500  {object} dto.ErrorResponse500
+// @Router       /app/partnerpages/{languageCode} [get]
+func (h *AppPartnerPageHandler) HandleGetPartnerListing(c *fiber.Ctx) error {
+	query := dto.PartnerListingQuery{
+		Language:      enums.PageLanguage(c.Params("languageCode")),
+		CategoryMatch: c.Query("match"),
+		Q:             c.Query("q"),
+		Sort:          c.Query("sort"),
+	}
+	query.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	query.Limit, _ = strconv.Atoi(c.Query("limit", strconv.Itoa(dto.PartnerListingDefaultLimit)))
+
+	fields, err := itemFieldsFromRequest(c)
+	if err != nil {
+		return fieldSelectionErrorResponse(c, err)
+	}
+	query.Fields = fields
+
+	if raw := c.Query("categories"); raw != "" {
+		for _, item := range strings.Split(raw, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(item))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"message": "failed to parse the categories",
+					"error":   errs.ErrInvalidUUIDFormat.Error(),
+				})
+			}
+			query.CategoryIDs = append(query.CategoryIDs, id)
+		}
+	}
+	if raw := c.Query("recommended"); raw != "" {
+		isRecommended, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "recommended must be true or false",
+				"error":   err.Error(),
+			})
+		}
+		query.IsRecommended = &isRecommended
+	}
+
+	cards, totalCount, facets, err := h.Service.FindPartnerListing(&query)
+	if err != nil {
+		if isFieldSelectionError(err) {
+			return fieldSelectionErrorResponse(c, err)
+		}
+		if errors.Is(err, errs.ErrInvalidLanguageCode) || errors.Is(err, errs.ErrInvalidListingSort) || errors.Is(err, errs.ErrInvalidCategoryMatch) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid partner listing query",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Partner pages",
+			"error":   err.Error(),
+		})
+	}
+
+	items, err := projectFields(cards, dto.FieldSelection{Fields: query.Fields})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Partner pages",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Partner pages retrieved successfully",
+		"totalCount": totalCount,
+		"page":       query.Page,
+		"limit":      query.Limit,
+		"items":      items,
+		"facets":     facets,
+	})
+}
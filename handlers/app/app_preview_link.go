@@ -0,0 +1,29 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// previewLinkErrorResponse maps a rejected preview token to its status code
+func previewLinkErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidPreviewToken):
+		status = fiber.StatusNotFound
+	case errors.Is(err, errs.ErrPreviewLinkExpired), errors.Is(err, errs.ErrPreviewLinkRevoked):
+		status = fiber.StatusGone
+	case errors.Is(err, errs.ErrPreviewPasswordRequired), errors.Is(err, errs.ErrInvalidPreviewPassword):
+		status = fiber.StatusUnauthorized
+	case errors.Is(err, errs.ErrPreviewLinkLocked):
+		status = fiber.StatusTooManyRequests
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": "failed to open the preview link",
+		"error":   err.Error(),
+	})
+}
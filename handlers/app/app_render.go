@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// parseRenderMode reads ?render, blank keeps the JSON only response
+func parseRenderMode(c *fiber.Ctx) (enums.RenderMode, error) {
+	mode := enums.RenderMode(strings.ToLower(strings.TrimSpace(c.Query("render"))))
+	switch mode {
+	case enums.RenderModeJSON, enums.RenderModeHTML, enums.RenderModeHTMLOnly:
+		return mode, nil
+	default:
+		return "", errs.ErrInvalidRenderMode
+	}
+}
+
+func renderModeErrorResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"message": "render must be html or html-only",
+		"error":   err.Error(),
+	})
+}
+
+// renderCacheKey changes whenever the content is saved or one of its related pages changes, so a stale render is never served
+func renderCacheKey(contentID uuid.UUID, updatedAt time.Time, related []models.RelatedPage) string {
+	if len(related) == 0 {
+		return fmt.Sprintf("%s:%d", contentID, updatedAt.UnixNano())
+	}
+
+	hash := fnv.New64a()
+	for _, page := range related {
+		fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\x00%s\x00", page.RelationType, page.PageID, page.Title, page.URL, page.Thumbnail)
+	}
+	return fmt.Sprintf("%s:%d:%x", contentID, updatedAt.UnixNano(), hash.Sum64())
+}
+
+// renderComponents returns the rendered html, and the components to keep in the response
+func renderComponents(renderer services.ComponentRendererInterface, mode enums.RenderMode, cacheKey string, components []*models.Component) (string, []*models.Component, error) {
+	if mode == enums.RenderModeJSON {
+		return "", components, nil
+	}
+
+	rendered, err := renderer.RenderComponents(cacheKey, components)
+	if err != nil {
+		return "", components, err
+	}
+	if mode == enums.RenderModeHTMLOnly {
+		return rendered, nil, nil
+	}
+	return rendered, components, nil
+}
+
+// renderLandingContent renders the content as served, a variant replaces its components without saving it so the variant is part of the key
+func renderLandingContent(renderer services.ComponentRendererInterface, mode enums.RenderMode, content *models.LandingContent, variant *dto.ExperimentAssignment) error {
+	cacheKey := renderCacheKey(content.ID, content.UpdatedAt, content.Related)
+	if variant != nil {
+		cacheKey += ":" + variant.VariantID.String()
+	}
+
+	rendered, components, err := renderComponents(renderer, mode, cacheKey, content.Components)
+	if err != nil {
+		return err
+	}
+	content.RenderedHTML, content.Components = rendered, components
+	return nil
+}
+
+func renderPartnerContent(renderer services.ComponentRendererInterface, mode enums.RenderMode, content *models.PartnerContent) error {
+	rendered, components, err := renderComponents(renderer, mode, renderCacheKey(content.ID, content.UpdatedAt, content.Related), content.Components)
+	if err != nil {
+		return err
+	}
+	content.RenderedHTML, content.Components = rendered, components
+	return nil
+}
+
+func renderFaqContent(renderer services.ComponentRendererInterface, mode enums.RenderMode, content *models.FaqContent) error {
+	rendered, components, err := renderComponents(renderer, mode, renderCacheKey(content.ID, content.UpdatedAt, content.Related), content.Components)
+	if err != nil {
+		return err
+	}
+	content.RenderedHTML, content.Components = rendered, components
+	return nil
+}
+
+func renderErrorResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Failed to render components",
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,178 @@
+package cms
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CMSAnalyticsHandler struct {
+	Service services.CMSAnalyticsServiceInterface
+}
+
+func NewCMSAnalyticsHandler(service services.CMSAnalyticsServiceInterface) *CMSAnalyticsHandler {
+	return &CMSAnalyticsHandler{Service: service}
+}
+
+// parseAnalyticsQuery reads from, to, pageType and language, the range defaults to the last 30 days
+func parseAnalyticsQuery(c *fiber.Ctx) (dto.AnalyticsQuery, error) {
+	query := dto.AnalyticsQuery{
+		PageType: enums.PageType(c.Query("pageType")),
+		Language: enums.PageLanguage(c.Query("language")),
+	}
+
+	var err error
+	if query.From, err = parseCalendarTime(c.Query("from"), false); err != nil {
+		return query, errs.ErrInvalidDateRange
+	}
+	if query.To, err = parseCalendarTime(c.Query("to"), true); err != nil {
+		return query, errs.ErrInvalidDateRange
+	}
+	if query.To.IsZero() {
+		query.To = time.Now().UTC()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-30 * 24 * time.Hour)
+	}
+
+	return query, nil
+}
+
+func parseAnalyticsLimit(c *fiber.Ctx) int {
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		return 20
+	}
+	return limit
+}
+
+func analyticsErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errs.ErrInvalidDateRange) ||
+		errors.Is(err, errs.ErrInvalidPageType) ||
+		errors.Is(err, errs.ErrInvalidLanguageCode) ||
+		errors.Is(err, errs.ErrInvalidUUIDFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid analytics filter",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "failed to get analytics",
+		"error":   err.Error(),
+	})
+}
+
+// HandleGetPageAnalytics handles GET requests to read the views of one page
+// @Summary      Get Page Analytics
+// @Description  Daily views and visitors of a page with its totals and top referrer hosts. Views are rolled up per UTC day, so the current day fills in as the rollup runs.
+// @Description  from defaults to 30 days before to and to defaults to today, the range can be at most 366 days.
+// @Tags         CMS - Analytics
+// @Produce      json
+// @Param        pageType  path   string  true   "Page type"  Enums(landing, partner, faq)
+// @Param        pageId    path   string  true   "Page ID (UUID)"
+// @Param        from      query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to        query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        language  query  string  false  "Filter by language"  Enums(en, th)
+// @Success      200  {object}  dto.PageAnalyticsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/analytics/pages/{pageType}/{pageId} [get]
+func (h *CMSAnalyticsHandler) HandleGetPageAnalytics(c *fiber.Ctx) error {
+	pageId, err := uuid.Parse(c.Params("pageId"))
+	if err != nil {
+		return analyticsErrorResponse(c, errs.ErrInvalidUUIDFormat)
+	}
+
+	query, err := parseAnalyticsQuery(c)
+	if err != nil {
+		return analyticsErrorResponse(c, err)
+	}
+
+	analytics, err := h.Service.GetPageAnalytics(enums.PageType(c.Params("pageType")), pageId, query)
+	if err != nil {
+		return analyticsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get page analytics",
+		"item":    analytics,
+	})
+}
+
+// HandleGetTopPages handles GET requests to list the most viewed pages
+// @Summary      Get Top Pages
+// @Description  Rank the landing, partner and faq page languages by views in the range, with their current title.
+// @Tags         CMS - Analytics
+// @Produce      json
+// @Param        pageType  query  string  false  "Filter by page type"  Enums(landing, partner, faq)
+// @Param        language  query  string  false  "Filter by language"  Enums(en, th)
+// @Param        from      query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to        query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        limit     query  int     false  "Number of pages (default is 20, max 100)"
+// @Success      200  {object}  dto.TopPagesSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/analytics/top [get]
+func (h *CMSAnalyticsHandler) HandleGetTopPages(c *fiber.Ctx) error {
+	query, err := parseAnalyticsQuery(c)
+	if err != nil {
+		return analyticsErrorResponse(c, err)
+	}
+
+	pages, err := h.Service.GetTopPages(query, parseAnalyticsLimit(c))
+	if err != nil {
+		return analyticsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get top pages",
+		"items":   pages,
+	})
+}
+
+// HandleGetCampaignTraffic handles GET requests to list the traffic of each UTM campaign
+// @Summary      Get Campaign Traffic
+// @Description  Views, visitors and distinct pages per UTM source, medium and campaign in the range, most viewed first.
+// @Tags         CMS - Analytics
+// @Produce      json
+// @Param        pageType  query  string  false  "Filter by page type"  Enums(landing, partner, faq)
+// @Param        pageId    query  string  false  "Filter by page ID (UUID)"
+// @Param        language  query  string  false  "Filter by language"  Enums(en, th)
+// @Param        from      query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to        query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        limit     query  int     false  "Number of campaigns (default is 20, max 100)"
+// @Success      200  {object}  dto.CampaignTrafficSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/analytics/campaigns [get]
+func (h *CMSAnalyticsHandler) HandleGetCampaignTraffic(c *fiber.Ctx) error {
+	query, err := parseAnalyticsQuery(c)
+	if err != nil {
+		return analyticsErrorResponse(c, err)
+	}
+	if pageId := c.Query("pageId"); pageId != "" {
+		parsed, err := uuid.Parse(pageId)
+		if err != nil {
+			return analyticsErrorResponse(c, errs.ErrInvalidUUIDFormat)
+		}
+		query.PageID = &parsed
+	}
+
+	campaigns, err := h.Service.GetCampaignTraffic(query, parseAnalyticsLimit(c))
+	if err != nil {
+		return analyticsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get campaign traffic",
+		"items":   campaigns,
+	})
+}
@@ -0,0 +1,219 @@
+package cms
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSAuditLogHandler struct {
+	Service services.CMSAuditLogServiceInterface
+}
+
+func NewCMSAuditLogHandler(service services.CMSAuditLogServiceInterface) *CMSAuditLogHandler {
+	return &CMSAuditLogHandler{Service: service}
+}
+
+func auditLogErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidDateRange), errors.Is(err, errs.ErrInvalidUUIDFormat):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = fiber.StatusNotFound
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func parseOptionalUUID(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, errs.ErrInvalidUUIDFormat
+	}
+	return &id, nil
+}
+
+func parseAuditLogQuery(c *fiber.Ctx) (dto.AuditLogQuery, error) {
+	query := dto.AuditLogQuery{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entityType"),
+	}
+
+	var err error
+	if query.UserID, err = parseOptionalUUID(c.Query("userId")); err != nil {
+		return query, err
+	}
+	if query.EntityID, err = parseOptionalUUID(c.Query("entityId")); err != nil {
+		return query, err
+	}
+	if query.From, err = parseCalendarTime(c.Query("from"), false); err != nil {
+		return query, errs.ErrInvalidDateRange
+	}
+	if query.To, err = parseCalendarTime(c.Query("to"), true); err != nil {
+		return query, errs.ErrInvalidDateRange
+	}
+
+	return query, nil
+}
+
+func auditLogCSVRow(auditLog models.AuditLog) []string {
+	optionalID := func(id *uuid.UUID) string {
+		if id == nil {
+			return ""
+		}
+		return id.String()
+	}
+
+	return []string{
+		auditLog.ID.String(),
+		auditLog.CreatedAt.UTC().Format(time.RFC3339),
+		optionalID(auditLog.UserID),
+		auditLog.Action,
+		auditLog.Method,
+		auditLog.Path,
+		auditLog.EntityType,
+		optionalID(auditLog.EntityID),
+		strconv.Itoa(auditLog.StatusCode),
+		auditLog.IP,
+		csvText(auditLog.UserAgent),
+		csvText(string(auditLog.Before)),
+		csvText(string(auditLog.After)),
+	}
+}
+
+// HandleGetAuditLogs handles GET requests to list the audit log, newest first
+// @Summary      List Audit Logs
+// @Description  Every mutating CMS request is recorded with the user from its token, the entity it changed,
+// @Description  the stored row before and the returned item after the change (secrets redacted), its status code, IP and user agent.
+// @Tags         CMS - Audit Log
+// @Produce      json
+// @Param        userId      query  string  false  "Filter by user ID (UUID)"
+// @Param        action      query  string  false  "Filter by action, e.g. create, update, delete or duplicate.pages"
+// @Param        entityType  query  string  false  "Filter by entity type, the CMS route group, e.g. categories or email-contents"
+// @Param        entityId    query  string  false  "Filter by entity ID (UUID)"
+// @Param        from        query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to          query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        page        query  int     false  "Page number for pagination (default is 1)"
+// @Param        limit       query  int     false  "Number of items per page (default is 10)"
+// @Success      200  {object}  dto.AuditLogsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/audit-logs [get]
+func (h *CMSAuditLogHandler) HandleGetAuditLogs(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	query, err := parseAuditLogQuery(c)
+	if err != nil {
+		return auditLogErrorResponse(c, "failed to get audit logs", err)
+	}
+
+	auditLogs, totalCount, err := h.Service.FindAuditLogs(query, page, limit)
+	if err != nil {
+		return auditLogErrorResponse(c, "failed to get audit logs", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "successfully get audit logs",
+		"totalCount": totalCount,
+		"page":       page,
+		"limit":      limit,
+		"items":      auditLogs,
+	})
+}
+
+// HandleExportAuditLogs handles GET requests to download the audit log as CSV, oldest first
+// @Summary      Export Audit Logs
+// @Tags         CMS - Audit Log
+// @Produce      text/csv
+// @Param        userId      query  string  false  "Filter by user ID (UUID)"
+// @Param        action      query  string  false  "Filter by action"
+// @Param        entityType  query  string  false  "Filter by entity type"
+// @Param        entityId    query  string  false  "Filter by entity ID (UUID)"
+// @Param        from        query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to          query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Success      200  {string}  string  "CSV file"
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/audit-logs/export [get]
+func (h *CMSAuditLogHandler) HandleExportAuditLogs(c *fiber.Ctx) error {
+	query, err := parseAuditLogQuery(c)
+	if err != nil {
+		return auditLogErrorResponse(c, "failed to export audit logs", err)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"id", "created_at", "user_id", "action", "method", "path", "entity_type", "entity_id", "status_code", "ip", "user_agent", "before", "after"}); err != nil {
+		return auditLogErrorResponse(c, "failed to export audit logs", err)
+	}
+
+	err = h.Service.ExportAuditLogs(query, func(auditLogs []models.AuditLog) error {
+		for _, auditLog := range auditLogs {
+			if err := writer.Write(auditLogCSVRow(auditLog)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		writer.Flush()
+		err = writer.Error()
+	}
+	if err != nil {
+		return auditLogErrorResponse(c, "failed to export audit logs", err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="audit-logs-%s.csv"`, time.Now().UTC().Format("2006-01-02")))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// HandleGetAuditLog handles GET requests to retrieve one audit log entry
+// @Summary      Get Audit Log
+// @Tags         CMS - Audit Log
+// @Produce      json
+// @Param        auditLogId  path  string  true  "Audit log ID (UUID)"
+// @Success      200  {object}  dto.AuditLogSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/audit-logs/{auditLogId} [get]
+func (h *CMSAuditLogHandler) HandleGetAuditLog(c *fiber.Ctx) error {
+	auditLogId, err := uuid.Parse(c.Params("auditLogId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the auditLogId",
+			"error":   err.Error(),
+		})
+	}
+
+	auditLog, err := h.Service.FindAuditLogByID(auditLogId)
+	if err != nil {
+		return auditLogErrorResponse(c, "failed to get audit log", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get audit log",
+		"item":    auditLog,
+	})
+}
@@ -0,0 +1,228 @@
+package cms
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSAutosaveHandler struct {
+	Service services.CMSAutosaveServiceInterface
+}
+
+func NewCMSAutosaveHandler(service services.CMSAutosaveServiceInterface) *CMSAutosaveHandler {
+	return &CMSAutosaveHandler{Service: service}
+}
+
+// service limits the autosave service to the page access of the user of the request
+func (h *CMSAutosaveHandler) service(c *fiber.Ctx) services.CMSAutosaveServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
+}
+
+func autosaveErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidPageType), errors.Is(err, errs.ErrInvalidAutosavePayload), errors.Is(err, errs.ErrNoRevisionFound):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, errs.ErrAutosaveNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, errs.ErrAutosaveOutdated), errors.Is(err, errs.ErrDuplicateURL), errors.Is(err, errs.ErrDuplicateUrlAlias):
+		status = fiber.StatusConflict
+	case errors.Is(err, errs.ErrCriticalAuditFindings):
+		status = fiber.StatusUnprocessableEntity
+	case errors.Is(err, errs.ErrPageAccessDenied):
+		status = fiber.StatusForbidden
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// HandleGetAutosave handles GET requests to load the autosave of the current user
+// @Summary      Get Autosave
+// @Description  Load the autosave of the current user for the page and language of the content, when the editor is opened.
+// @Description  newer_autosaves lists the other users who autosaved the same page and language after the current user, base_outdated tells the content was saved again since.
+// @Tags         CMS - Autosaves
+// @Produce      json
+// @Security     BearerAuth
+// @Param        pageType   path  string  true  "Page type"  Enums(landing, partner, faq)
+// @Param        contentId  path  string  true  "Content ID (UUID) open in the editor"
+// @Success      200  {object}  dto.CMSAutosaveSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/autosaves/{pageType}/{contentId} [get]
+func (h *CMSAutosaveHandler) HandleGetAutosave(c *fiber.Ctx) error {
+	userId, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to identify the user",
+			"error":   err.Error(),
+		})
+	}
+
+	contentId, err := uuid.Parse(c.Params("contentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the contentId",
+			"error":   err.Error(),
+		})
+	}
+	pageType := enums.PageType(c.Params("pageType"))
+
+	state, err := h.service(c).GetAutosave(userId, pageType, contentId)
+	if err != nil {
+		return autosaveErrorResponse(c, "failed to get autosave", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get autosave",
+		"data":    state,
+	})
+}
+
+// HandleSaveAutosave handles PUT requests to overwrite the autosave of the current user
+// @Summary      Save Autosave
+// @Description  Overwrite the autosave slot of the current user with the editor state, without creating a content version or revision. The body is the same as the update content endpoint.
+// @Tags         CMS - Autosaves
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        pageType   path  string  true  "Page type"  Enums(landing, partner, faq)
+// @Param        contentId  path  string  true  "Content ID (UUID) open in the editor"
+// @Param        payload    body  object  true  "Editor state"
+// @Success      200  {object}  dto.CMSAutosaveSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/autosaves/{pageType}/{contentId} [put]
+func (h *CMSAutosaveHandler) HandleSaveAutosave(c *fiber.Ctx) error {
+	userId, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to identify the user",
+			"error":   err.Error(),
+		})
+	}
+
+	contentId, err := uuid.Parse(c.Params("contentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the contentId",
+			"error":   err.Error(),
+		})
+	}
+	pageType := enums.PageType(c.Params("pageType"))
+
+	state, err := h.service(c).SaveAutosave(userId, pageType, contentId, c.Body())
+	if err != nil {
+		return autosaveErrorResponse(c, "failed to save autosave", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully save autosave",
+		"data":    state,
+	})
+}
+
+// HandleDiscardAutosave handles DELETE requests to drop the autosave of the current user
+// @Summary      Discard Autosave
+// @Description  Drop the autosave of the current user for the page and language of the content.
+// @Tags         CMS - Autosaves
+// @Produce      json
+// @Security     BearerAuth
+// @Param        pageType   path  string  true  "Page type"  Enums(landing, partner, faq)
+// @Param        contentId  path  string  true  "Content ID (UUID) open in the editor"
+// @Success      200  {object}  dto.SuccessResponse
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/autosaves/{pageType}/{contentId} [delete]
+func (h *CMSAutosaveHandler) HandleDiscardAutosave(c *fiber.Ctx) error {
+	userId, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to identify the user",
+			"error":   err.Error(),
+		})
+	}
+
+	contentId, err := uuid.Parse(c.Params("contentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the contentId",
+			"error":   err.Error(),
+		})
+	}
+	pageType := enums.PageType(c.Params("pageType"))
+
+	if err := h.service(c).DiscardAutosave(userId, pageType, contentId); err != nil {
+		return autosaveErrorResponse(c, "failed to discard autosave", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully discard autosave",
+	})
+}
+
+// HandlePromoteAutosave handles POST requests to save the autosave as a real content version
+// @Summary      Promote Autosave
+// @Description  Save the autosave of the current user through the update content flow, which archives the content and creates a new version with its revision, then clear the autosave.
+// @Description  Fails with 409 when the content was saved again since the autosave was started.
+// @Tags         CMS - Autosaves
+// @Produce      json
+// @Security     BearerAuth
+// @Param        pageType   path  string  true  "Page type"  Enums(landing, partner, faq)
+// @Param        contentId  path  string  true  "Content ID (UUID) open in the editor"
+// @Success      200  {object}  dto.SuccessResponse
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      409  {object}  dto.ErrorResponse "The autosave is based on an outdated content version"
+// @Failure      422  {object}  dto.ErrorResponse "Critical audit findings block publishing"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/autosaves/{pageType}/{contentId}/promote [post]
+func (h *CMSAutosaveHandler) HandlePromoteAutosave(c *fiber.Ctx) error {
+	userId, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to identify the user",
+			"error":   err.Error(),
+		})
+	}
+
+	contentId, err := uuid.Parse(c.Params("contentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the contentId",
+			"error":   err.Error(),
+		})
+	}
+	pageType := enums.PageType(c.Params("pageType"))
+
+	saved, err := h.service(c).PromoteAutosave(userId, pageType, contentId)
+	if err != nil {
+		return autosaveErrorResponse(c, "failed to promote autosave", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully promote autosave",
+		"item":    saved,
+	})
+}
@@ -0,0 +1,251 @@
+package cms
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CMSCalendarHandler struct {
+	Service services.CMSCalendarServiceInterface
+}
+
+func NewCMSCalendarHandler(service services.CMSCalendarServiceInterface) *CMSCalendarHandler {
+	return &CMSCalendarHandler{Service: service}
+}
+
+// service limits the calendar service to the page access of the user of the request
+func (h *CMSCalendarHandler) service(c *fiber.Ctx) services.CMSCalendarServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
+}
+
+// parseCalendarTime accepts RFC 3339 or a plain date, a plain "to" date covers the whole day
+func parseCalendarTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errs.ErrInvalidCalendarRange
+	}
+	if endOfDay {
+		parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+	}
+	return parsed, nil
+}
+
+func parseCalendarQuery(c *fiber.Ctx) (dto.CalendarQuery, error) {
+	query := dto.CalendarQuery{
+		PageType:   enums.PageType(c.Query("pageType")),
+		Language:   enums.PageLanguage(c.Query("language")),
+		Status:     enums.WorkflowStatus(c.Query("status")),
+		CategoryID: c.Query("categoryId"),
+		Author:     c.Query("author"),
+	}
+
+	var err error
+	if query.From, err = parseCalendarTime(c.Query("from"), false); err != nil {
+		return query, err
+	}
+	if query.To, err = parseCalendarTime(c.Query("to"), true); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+func isCalendarQueryError(err error) bool {
+	return errors.Is(err, errs.ErrInvalidCalendarRange) ||
+		errors.Is(err, errs.ErrInvalidPageType) ||
+		errors.Is(err, errs.ErrInvalidLanguageCode) ||
+		errors.Is(err, errs.ErrInvalidWorkflowStatus) ||
+		errors.Is(err, errs.ErrInvalidUUIDFormat)
+}
+
+// HandleGetCalendarEvents handles GET requests to list the scheduled dates of every page type
+// @Summary      Get Editorial Calendar
+// @Description  List the publish, unpublish and expiry dates of the current landing, partner and faq contents in a date range, with each page's title and CMS edit link.
+// @Description  from defaults to today and to defaults to 31 days after from, the range can be at most 366 days.
+// @Tags         CMS - Calendar
+// @Produce      json
+// @Param        from        query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to          query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        pageType    query  string  false  "Filter by page type"  Enums(landing, partner, faq)
+// @Param        language    query  string  false  "Filter by language"  Enums(en, th)
+// @Param        status      query  string  false  "Filter by workflow status"
+// @Param        categoryId  query  string  false  "Filter by category ID (UUID)"
+// @Param        author      query  string  false  "Filter by revision author, partial match"
+// @Success      200  {object}  dto.CMSCalendarSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/calendar [get]
+func (h *CMSCalendarHandler) HandleGetCalendarEvents(c *fiber.Ctx) error {
+	query, err := parseCalendarQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid calendar filter",
+			"error":   err.Error(),
+		})
+	}
+	if query.From.IsZero() {
+		query.From = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	if query.To.IsZero() {
+		query.To = query.From.Add(31 * 24 * time.Hour)
+	}
+
+	events, err := h.service(c).FindCalendarEvents(query)
+	if err != nil {
+		if isCalendarQueryError(err) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid calendar filter",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to get calendar events",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get calendar events",
+		"from":    query.From,
+		"to":      query.To,
+		"items":   events,
+	})
+}
+
+// HandleIssueFeedToken handles POST requests to create the iCalendar feed url of the current user
+// @Summary      Issue Calendar Feed
+// @Description  Create a secret iCalendar (.ics) feed url for the current user to subscribe from a calendar app. Issuing again replaces the previous url.
+// @Tags         CMS - Calendar
+// @Produce      json
+// @Security     BearerAuth
+// @Success      201  {object}  dto.CMSCalendarFeedTokenSuccessResponse201
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/calendar/feed [post]
+func (h *CMSCalendarHandler) HandleIssueFeedToken(c *fiber.Ctx) error {
+	userId, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to identify the user",
+			"error":   err.Error(),
+		})
+	}
+
+	feed, err := h.Service.IssueFeedToken(userId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to issue calendar feed",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "successfully issue calendar feed",
+		"item":    feed,
+	})
+}
+
+// HandleRevokeFeedToken handles DELETE requests to stop the iCalendar feed of the current user
+// @Summary      Revoke Calendar Feed
+// @Description  Stop the iCalendar feed url of the current user.
+// @Tags         CMS - Calendar
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  dto.SuccessResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/calendar/feed [delete]
+func (h *CMSCalendarHandler) HandleRevokeFeedToken(c *fiber.Ctx) error {
+	userId, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to identify the user",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := h.Service.RevokeFeedToken(userId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "calendar feed not found",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to revoke calendar feed",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully revoke calendar feed",
+	})
+}
+
+// HandleGetCalendarFeed handles GET requests from calendar apps for the iCalendar feed
+// @Summary      Get Calendar Feed
+// @Description  iCalendar (.ics) feed of the editorial calendar, authenticated by the secret token in the url. Defaults to the last 30 days and the next 180 days, and takes the same filters as the calendar endpoint.
+// @Tags         CMS - Calendar
+// @Produce      text/calendar
+// @Param        token       path   string  true   "Feed token"
+// @Param        from        query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to          query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        pageType    query  string  false  "Filter by page type"  Enums(landing, partner, faq)
+// @Param        language    query  string  false  "Filter by language"  Enums(en, th)
+// @Param        status      query  string  false  "Filter by workflow status"
+// @Param        categoryId  query  string  false  "Filter by category ID (UUID)"
+// @Param        author      query  string  false  "Filter by revision author, partial match"
+// @Success      200  {string}  string  "iCalendar document"
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /calendar/feeds/{token} [get]
+func (h *CMSCalendarHandler) HandleGetCalendarFeed(c *fiber.Ctx) error {
+	query, err := parseCalendarQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid calendar filter",
+			"error":   err.Error(),
+		})
+	}
+
+	feed, err := h.Service.BuildFeed(c.Params("token"), query)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidCalendarFeedToken) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "calendar feed not found",
+				"error":   err.Error(),
+			})
+		}
+		if isCalendarQueryError(err) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid calendar filter",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to build calendar feed",
+			"error":   err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="editorial-calendar.ics"`)
+	return c.Status(fiber.StatusOK).SendString(feed)
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/go-playground/validator/v10"
@@ -16,14 +18,18 @@ import (
 )
 
 type CMSCategoryHandler struct {
-	Service  services.CMSCategoryServiceInterface
-	validate *validator.Validate
+	Service      services.CMSCategoryServiceInterface
+	UsageService services.CMSUsageServiceInterface
+	Cache        services.AppResponseCacheInterface
+	validate     *validator.Validate
 }
 
-func NewCMSCategoryHandler(service services.CMSCategoryServiceInterface) *CMSCategoryHandler {
+func NewCMSCategoryHandler(service services.CMSCategoryServiceInterface, usageService services.CMSUsageServiceInterface, cache services.AppResponseCacheInterface) *CMSCategoryHandler {
 	return &CMSCategoryHandler{
-		Service:  service,
-		validate: validator.New(),
+		Service:      service,
+		UsageService: usageService,
+		Cache:        cache,
+		validate:     validator.New(),
 	}
 }
 
@@ -84,6 +90,9 @@ func (h *CMSCategoryHandler) HandleCreateCategory(c *fiber.Ctx) error {
 // @Param lang query string false "Filter by language (th, en)" Enums(th, en)
 // @Param name query string false "Filter by name (partial match)"
 // @Param publish_status query string false "Filter by publish status (Published, Unpublished)" Enums(Published, Unpublished)
+// @Param cursor query string false "Switches to cursor pagination by weight then creation, empty for the first page, then the next or prev cursor of the previous response"
+// @Param limit query int false "Items per page in cursor pagination (default: 10, max: 100)"
+// @Param withTotal query bool false "Also return totalCount in cursor pagination"
 // @Success 200 {array} dto.CategoryResponse
 // @Failure 400 {object} dto.ErrorResponse "Invalid filter parameters"
 // @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
@@ -99,6 +108,14 @@ func (h *CMSCategoryHandler) HandleListAllCategories(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "Validation failed for filter", Message: err.Error()})
 	}
 
+	if cursorQuery, ok := cursorQueryFromRequest(c); ok {
+		categories, cursorPage, err := h.Service.ListCategoriesByCursor(filter, cursorQuery)
+		if err != nil {
+			return cursorErrorResponse(c, "Failed to list categories", err)
+		}
+		return cursorPageResponse(c, "successfully list categories", categories, cursorPage)
+	}
+
 	categories, err := h.Service.ListAllCategories(filter)
 	if err != nil {
 		if strings.Contains(err.Error(), "invalid category_type_id") { // ควรมาจาก service layer ถ้ามีการ parse ที่นั่น
@@ -154,7 +171,8 @@ func (h *CMSCategoryHandler) HandleGetCategoryByUUID(c *fiber.Ctx) error {
 // @Router /cms/categories/{categoryUuid} [patch]
 func (h *CMSCategoryHandler) HandleUpdateCategory(c *fiber.Ctx) error {
 	uuidStr := c.Params("categoryUuid")
-	if _, err := uuid.Parse(uuidStr); err != nil {
+	categoryId, err := uuid.Parse(uuidStr)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "Invalid category UUID format", Message: err.Error()})
 	}
 
@@ -178,27 +196,38 @@ func (h *CMSCategoryHandler) HandleUpdateCategory(c *fiber.Ctx) error {
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "Failed to update category", Message: err.Error()})
 	}
+	h.Cache.InvalidateCategory(categoryId)
 	return c.Status(fiber.StatusOK).JSON(categoryResponse)
 }
 
 // DELETE /api/v1/cms/categories/{categoryUuid}
 // @Summary Delete Category (Detail)
-// @Description Deletes a specific category detail by its UUID.
+// @Description Deletes a specific category detail by its UUID. Refused while published content is tagged with it, unless forced.
 // @Tags CMS - Categories
 // @Produce json
 // @Param categoryUuid path string true "Category (Detail) UUID"
+// @Param force query bool false "Delete even when published content is still tagged with it"
 // @Success 204 "No Content"
 // @Failure 400 {object} dto.ErrorResponse "Invalid UUID format"
 // @Failure 404 {object} dto.ErrorResponse "Category not found"
+// @Failure 409 {object} dto.UsageConflictResponse409 "Category in use"
 // @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
 // @Router /cms/categories/{categoryUuid} [delete]
 func (h *CMSCategoryHandler) HandleDeleteCategory(c *fiber.Ctx) error {
 	uuidStr := c.Params("categoryUuid")
-	if _, err := uuid.Parse(uuidStr); err != nil {
+	categoryId, err := uuid.Parse(uuidStr)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "Invalid category UUID format", Message: err.Error()})
 	}
 
-	err := h.Service.DeleteCategoryByUUID(uuidStr)
+	if stop, err := checkDeleteUsage(c, h.UsageService, enums.UsageItemCategory, categoryId); stop {
+		return err
+	}
+
+	err = h.Service.DeleteCategoryByUUID(uuidStr, c.QueryBool("force"))
+	if errors.Is(err, errs.ErrItemInUse) {
+		return deleteConflictResponse(c, h.UsageService, enums.UsageItemCategory, categoryId, err)
+	}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) || strings.Contains(strings.ToLower(err.Error()), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: "Not Found", Message: fmt.Sprintf("Category with ID '%s' not found for delete", uuidStr)})
@@ -206,6 +235,7 @@ func (h *CMSCategoryHandler) HandleDeleteCategory(c *fiber.Ctx) error {
 		// "cannot delete category with children" logic is likely in CategoryType deletion now.
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "Failed to delete category", Message: err.Error()})
 	}
+	h.Cache.InvalidateCategory(categoryId)
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -0,0 +1,76 @@
+package cms
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSContentAuditHandler struct {
+	Service services.CMSContentAuditServiceInterface
+}
+
+func NewCMSContentAuditHandler(service services.CMSContentAuditServiceInterface) *CMSContentAuditHandler {
+	return &CMSContentAuditHandler{Service: service}
+}
+
+// service limits the audit service to the page access of the user of the request
+func (h *CMSContentAuditHandler) service(c *fiber.Ctx) services.CMSContentAuditServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
+}
+
+// HandleAuditContent handles GET requests to run the SEO and content quality audit on a content
+// @Summary      Audit Content
+// @Description  Check a landing, partner or faq content for SEO and quality issues such as missing meta tags, images without alt text, heading order, duplicate titles, short content and url alias format.
+// @Description  Findings are graded critical, warning or info and the score starts at 100.
+// @Tags         CMS - Content Audits
+// @Produce      json
+// @Param        pageType   path  string  true  "Page type"  Enums(landing, partner, faq)
+// @Param        contentId  path  string  true  "Content ID (UUID)"
+// @Success      200  {object}  dto.CMSContentAuditSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/audits/{pageType}/{contentId} [get]
+func (h *CMSContentAuditHandler) HandleAuditContent(c *fiber.Ctx) error {
+	contentIdStr := c.Params("contentId")
+	contentId, err := uuid.Parse(contentIdStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the contentId",
+			"error":   err.Error(),
+		})
+	}
+
+	report, err := h.service(c).AuditContent(c.Params("pageType"), contentId)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidPageType) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "page type must be one of landing, partner or faq",
+				"error":   err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "content not found",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"message": "failed to audit content",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully audit content",
+		"item":    report,
+	})
+}
@@ -0,0 +1,160 @@
+package cms
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSContentRelationHandler struct {
+	Service services.CMSContentRelationServiceInterface
+}
+
+func NewCMSContentRelationHandler(service services.CMSContentRelationServiceInterface) *CMSContentRelationHandler {
+	return &CMSContentRelationHandler{Service: service}
+}
+
+// service limits the relation service to the page access of the user of the request
+func (h *CMSContentRelationHandler) service(c *fiber.Ctx) services.CMSContentRelationServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
+}
+
+func relationErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidPageType), errors.Is(err, errs.ErrInvalidLanguageCode),
+		errors.Is(err, errs.ErrInvalidRelation), errors.Is(err, errs.ErrSelfRelation),
+		errors.Is(err, errs.ErrDuplicateRelation), errors.Is(err, errs.ErrTooManyRelations),
+		errors.Is(err, errs.ErrRelationTargetNotFound):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, errs.ErrRelationsLocked):
+		status = fiber.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, errs.ErrPageAccessDenied):
+		status = fiber.StatusForbidden
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// HandleGetRelations handles GET requests to list the related pages of a content
+// @Summary      List Content Relations
+// @Tags         CMS - Content Relations
+// @Produce      json
+// @Param        pageType   path  string  true  "Page type"  Enums(landing, partner, faq)
+// @Param        contentId  path  string  true  "Content ID (UUID)"
+// @Success      200  {object}  dto.ContentRelationsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/relations/{pageType}/contents/{contentId} [get]
+func (h *CMSContentRelationHandler) HandleGetRelations(c *fiber.Ctx) error {
+	contentId, err := uuid.Parse(c.Params("contentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the contentId",
+			"error":   err.Error(),
+		})
+	}
+
+	relations, err := h.service(c).GetRelations(enums.PageType(c.Params("pageType")), contentId)
+	if err != nil {
+		return relationErrorResponse(c, "failed to get relations", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get relations",
+		"items":   relations,
+	})
+}
+
+// HandleReplaceRelations handles PUT requests to set the related pages of a content
+// @Summary      Replace Content Relations
+// @Description  Replaces every relation of a content that is not published or scheduled yet, new versions of the content copy them.
+// @Description  Pages are referenced by ID and listed in display order within each relation type,
+// @Description  the app resolves them to the current title, url and thumbnail of their published content and leaves out the unpublished ones.
+// @Tags         CMS - Content Relations
+// @Accept       json
+// @Produce      json
+// @Param        pageType   path  string  true  "Page type"  Enums(landing, partner, faq)
+// @Param        contentId  path  string  true  "Content ID (UUID)"
+// @Param        request  body  dto.ReplaceContentRelationsRequest  true  "Related pages"
+// @Success      200  {object}  dto.ContentRelationsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      409  {object}  dto.ErrorResponse  "Content is published, scheduled or a history version"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/relations/{pageType}/contents/{contentId} [put]
+func (h *CMSContentRelationHandler) HandleReplaceRelations(c *fiber.Ctx) error {
+	contentId, err := uuid.Parse(c.Params("contentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the contentId",
+			"error":   err.Error(),
+		})
+	}
+
+	var request dto.ReplaceContentRelationsRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the body",
+			"error":   err.Error(),
+		})
+	}
+
+	relations, err := h.service(c).ReplaceRelations(enums.PageType(c.Params("pageType")), contentId, request)
+	if err != nil {
+		return relationErrorResponse(c, "failed to replace relations", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully replace relations",
+		"items":   relations,
+	})
+}
+
+// HandleGetIncomingRelations handles GET requests to list the pages relating to a page
+// @Summary      List Incoming Content Relations
+// @Description  Reverse lookup of the current contents listing the page as related, with the current title of each.
+// @Tags         CMS - Content Relations
+// @Produce      json
+// @Param        pageType  path  string  true  "Page type"  Enums(landing, partner, faq)
+// @Param        pageId    path  string  true  "Page ID (UUID)"
+// @Success      200  {object}  dto.IncomingRelationsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/relations/incoming/{pageType}/{pageId} [get]
+func (h *CMSContentRelationHandler) HandleGetIncomingRelations(c *fiber.Ctx) error {
+	pageId, err := uuid.Parse(c.Params("pageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the pageId",
+			"error":   err.Error(),
+		})
+	}
+
+	relations, err := h.service(c).GetIncomingRelations(enums.PageType(c.Params("pageType")), pageId)
+	if err != nil {
+		return relationErrorResponse(c, "failed to get incoming relations", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get incoming relations",
+		"items":   relations,
+	})
+}
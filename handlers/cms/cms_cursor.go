@@ -0,0 +1,57 @@
+package cms
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// cursorQueryFromRequest reads the keyset pagination params of a list request.
+// A list switches to cursor pagination when the cursor param is present, empty for the first page,
+// without it the list keeps its page offsets.
+func cursorQueryFromRequest(c *fiber.Ctx) (dto.CursorQuery, bool) {
+	if !c.Context().QueryArgs().Has("cursor") {
+		return dto.CursorQuery{}, false
+	}
+
+	return dto.CursorQuery{
+		Cursor:    c.Query("cursor"),
+		Limit:     c.QueryInt("limit"),
+		WithTotal: c.QueryBool("withTotal"),
+	}, true
+}
+
+// cursorPageResponse writes a keyset page, next and prev take the place of the page number of the offset lists
+func cursorPageResponse(c *fiber.Ctx, message string, items interface{}, page *dto.CursorPage) error {
+	response := fiber.Map{
+		"message": message,
+		"items":   items,
+		"next":    page.Next,
+		"prev":    page.Prev,
+		"limit":   page.Limit,
+	}
+	if page.TotalCount != nil {
+		response["totalCount"] = *page.TotalCount
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func cursorErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidCursor),
+		errors.Is(err, errs.ErrUnsupportedCursorSort),
+		errors.Is(err, errs.ErrInvalidQuery),
+		errors.Is(err, errs.ErrBadRequest):
+		status = fiber.StatusBadRequest
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"strconv"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,11 +16,18 @@ import (
 )
 
 type CMSFaqPageHandler struct {
-	Service services.CMSFaqPageServiceInterface
+	Service            services.CMSFaqPageServiceInterface
+	PreviewLinkService services.CMSPreviewLinkServiceInterface
+	UsageService       services.CMSUsageServiceInterface
 }
 
-func NewCMSFaqPageHandler(service services.CMSFaqPageServiceInterface) *CMSFaqPageHandler {
-	return &CMSFaqPageHandler{Service: service}
+func NewCMSFaqPageHandler(service services.CMSFaqPageServiceInterface, previewLinkService services.CMSPreviewLinkServiceInterface, usageService services.CMSUsageServiceInterface) *CMSFaqPageHandler {
+	return &CMSFaqPageHandler{Service: service, PreviewLinkService: previewLinkService, UsageService: usageService}
+}
+
+// service limits the page service to the page access of the user of the request
+func (h *CMSFaqPageHandler) service(c *fiber.Ctx) services.CMSFaqPageServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
 }
 
 // HandleCreateFaqPage handles POST requests to create a new FAQ page
@@ -32,6 +41,8 @@ func NewCMSFaqPageHandler(service services.CMSFaqPageServiceInterface) *CMSFaqPa
 // @Param        faq_page_data  body  dto.CreateFaqPageRequest  true  "FAQ Page payload (optional: categories, components)"
 // @Success      200  {object} dto.CMSFaqPageSuccessResponse200
 // @Failure 		 400  {object} dto.ErrorResponse400
+// @Failure 		 403  {object} dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      422  {object} dto.ErrorResponse "Critical audit findings block publishing"
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /cms/faqpages [post]
 func (h *CMSFaqPageHandler) HandleCreateFaqPage(c *fiber.Ctx) error {
@@ -45,9 +56,15 @@ func (h *CMSFaqPageHandler) HandleCreateFaqPage(c *fiber.Ctx) error {
 
 	helpers.SanitizeFaqPage(&faqPage)
 
-	createdFaqPage, err := h.Service.CreateFaqPage(&faqPage)
+	createdFaqPage, err := h.service(c).CreateFaqPage(&faqPage)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, errs.ErrCriticalAuditFindings) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "content cannot be published until critical audit findings are fixed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "Failed to create faq page",
 			"error":   err.Error(),
 		})
@@ -69,6 +86,8 @@ func (h *CMSFaqPageHandler) HandleCreateFaqPage(c *fiber.Ctx) error {
 // @Param        page     query  int     false  "Page number for pagination (default is 1)"
 // @Param        limit    query  int     false  "Number of items per page (default is 10)"
 // @Param        language  query  string  false  "Language code for localized content (e.g., en, th)"
+// @Param        cursor     query  string  false  "Switches to cursor pagination, empty for the first page, then the next or prev cursor of the previous response. Sorts on created_at or updated_at only"
+// @Param        withTotal  query  bool    false  "Also return totalCount in cursor pagination"
 // @Success      200  {object} dto.CMSFaqPagesSuccessResponse200
 // @Failure 		 400  {object} dto.ErrorResponse400
 // @Failure      500  {object} dto.ErrorResponse500
@@ -80,7 +99,15 @@ func (h *CMSFaqPageHandler) HandleGetFaqPages(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	language := c.Query("language", "")
 
-	results, totalCount, err := h.Service.FindFaqPages(rawQuery, sort, page, limit, language)
+	if cursorQuery, ok := cursorQueryFromRequest(c); ok {
+		results, cursorPage, err := h.service(c).FindFaqPagesByCursor(rawQuery, sort, cursorQuery, language)
+		if err != nil {
+			return cursorErrorResponse(c, "failed to find faq pages", err)
+		}
+		return cursorPageResponse(c, "successfully get all faq pages", results, cursorPage)
+	}
+
+	results, totalCount, err := h.service(c).FindFaqPages(rawQuery, sort, page, limit, language)
 	if err != nil {
 		if errors.Is(err, errs.ErrInvalidQuery) {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -111,6 +138,7 @@ func (h *CMSFaqPageHandler) HandleGetFaqPages(c *fiber.Ctx) error {
 // @Param        pageid  path  string  true  "FAQ Page ID"
 // @Success      200  {object} dto.CMSFaqPageSuccessResponse200
 // @Failure      400  {object} dto.ErrorResponse400
+// @Failure      403  {object} dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /cms/faqpages/{pageid} [get]
 func (h *CMSFaqPageHandler) HandleGetFaqPageById(c *fiber.Ctx) error {
@@ -123,9 +151,9 @@ func (h *CMSFaqPageHandler) HandleGetFaqPageById(c *fiber.Ctx) error {
 		})
 	}
 
-	faqPage, err := h.Service.FindFaqPageById(id)
+	faqPage, err := h.service(c).FindFaqPageById(id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to find faq page",
 			"error":   err.Error(),
 		})
@@ -142,9 +170,12 @@ func (h *CMSFaqPageHandler) HandleGetFaqPageById(c *fiber.Ctx) error {
 // @Description  Delete an existing FAQ page using its unique identifier.
 // @Tags         CMS - Faq Pages
 // @Produce      json
-// @Param        pageId  path  string  true  "FAQ Page ID"
+// @Param        pageId  path   string  true   "FAQ Page ID"
+// @Param        force   query  bool    false  "Delete even when published content still refers to the page"
 // @Success      200  {object}  dto.CMSSuccessResponse
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      409  {object}  dto.UsageConflictResponse409
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/{pageId} [delete]
 func (h *CMSFaqPageHandler) HandleDeleteFaqPage(c *fiber.Ctx) error {
@@ -157,9 +188,16 @@ func (h *CMSFaqPageHandler) HandleDeleteFaqPage(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.Service.DeleteFaqPage(id)
+	if stop, err := checkDeleteUsage(c, h.UsageService, enums.UsageItemFaqPage, id); stop {
+		return err
+	}
+
+	err = h.service(c).DeleteFaqPage(id, c.QueryBool("force"))
+	if errors.Is(err, errs.ErrItemInUse) {
+		return deleteConflictResponse(c, h.UsageService, enums.UsageItemFaqPage, id, err)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to delete faq page",
 			"error":   err.Error(),
 		})
@@ -180,6 +218,7 @@ func (h *CMSFaqPageHandler) HandleDeleteFaqPage(c *fiber.Ctx) error {
 // @Param        mode          query     string  false   "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSFaqContentSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/{pageId}/contents/{languageCode} [get]
 func (h *CMSFaqPageHandler) HandleGetContentByFaqPageId(c *fiber.Ctx) error {
@@ -194,9 +233,9 @@ func (h *CMSFaqPageHandler) HandleGetContentByFaqPageId(c *fiber.Ctx) error {
 		})
 	}
 
-	faqContent, err := h.Service.FindContentByFaqPageId(id, language, mode)
+	faqContent, err := h.service(c).FindContentByFaqPageId(id, language, mode)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "internal server error",
 			"error":   err.Error(),
 		})
@@ -217,6 +256,7 @@ func (h *CMSFaqPageHandler) HandleGetContentByFaqPageId(c *fiber.Ctx) error {
 // @Param        languageCode  path      string  true   "Language Code (e.g., en, th)"
 // @Success      200  {object}  dto.CMSFaqContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/{pageId}/latestcontent/{languageCode} [get]
 func (h *CMSFaqPageHandler) HandleGetLatestContentByFaqPageId(c *fiber.Ctx) error {
@@ -230,9 +270,9 @@ func (h *CMSFaqPageHandler) HandleGetLatestContentByFaqPageId(c *fiber.Ctx) erro
 		})
 	}
 
-	faqContent, err := h.Service.FindLatestContentByPageId(pageId, language)
+	faqContent, err := h.service(c).FindLatestContentByPageId(pageId, language)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get the latest content",
 			"error":   err.Error(),
 		})
@@ -254,6 +294,7 @@ func (h *CMSFaqPageHandler) HandleGetLatestContentByFaqPageId(c *fiber.Ctx) erro
 // @Param        mode          query     string  false  "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSSuccessResponse
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/{pageId}/contents/{languageCode} [delete]
 func (h *CMSFaqPageHandler) HandleDeleteFaqContentByPageId(c *fiber.Ctx) error {
@@ -269,8 +310,8 @@ func (h *CMSFaqPageHandler) HandleDeleteFaqContentByPageId(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.Service.DeleteContentByFaqPageId(pageId, language, mode); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	if err := h.service(c).DeleteContentByFaqPageId(pageId, language, mode); err != nil {
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to Delete faq content",
 			"error":   err.Error(),
 		})
@@ -289,6 +330,7 @@ func (h *CMSFaqPageHandler) HandleDeleteFaqContentByPageId(c *fiber.Ctx) error {
 // @Param        pageId        path      string  true  "FAQ Page ID (UUID)"
 // @Success      200           {object}  dto.CMSFaqPageSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/duplicate/{pageId}/pages [post]
 func (h *CMSFaqPageHandler) HandleDuplicateFaqPage(c *fiber.Ctx) error {
@@ -301,9 +343,9 @@ func (h *CMSFaqPageHandler) HandleDuplicateFaqPage(c *fiber.Ctx) error {
 		})
 	}
 
-	faqPage, err := h.Service.DuplicateFaqPage(pageId)
+	faqPage, err := h.service(c).DuplicateFaqPage(pageId)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to duplicate page",
 			"error":   err.Error(),
 		})
@@ -325,6 +367,7 @@ func (h *CMSFaqPageHandler) HandleDuplicateFaqPage(c *fiber.Ctx) error {
 // @Param        revision  body  dto.CreateRevisionRequest  true  "Revision payload"
 // @Success      200           {object}  dto.CMSFaqContentSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/duplicate/{contentId}/contents [post]
 func (h *CMSFaqPageHandler) HandleDuplicateFaqContentToAnotherLanguage(c *fiber.Ctx) error {
@@ -347,9 +390,9 @@ func (h *CMSFaqPageHandler) HandleDuplicateFaqContentToAnotherLanguage(c *fiber.
 
 	helpers.SanitizeRevision(&revision)
 
-	faqContent, err := h.Service.DuplicateFaqContentToAnotherLanguage(contentId, &revision)
+	faqContent, err := h.service(c).DuplicateFaqContentToAnotherLanguage(contentId, &revision)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to duplicate content to another language",
 			"error":   err.Error(),
 		})
@@ -371,6 +414,7 @@ func (h *CMSFaqPageHandler) HandleDuplicateFaqContentToAnotherLanguage(c *fiber.
 // @Param        revision    body  dto.CreateRevisionRequest  true  "Revision payload"
 // @Success      200  {object}  dto.CMSFaqContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/{revisionId}/revisions [post]
 func (h *CMSFaqPageHandler) HandleRevertFaqContent(c *fiber.Ctx) error {
@@ -393,9 +437,9 @@ func (h *CMSFaqPageHandler) HandleRevertFaqContent(c *fiber.Ctx) error {
 
 	helpers.SanitizeRevision(&revision)
 
-	faqContent, err := h.Service.RevertFaqContent(revisionId, &revision)
+	faqContent, err := h.service(c).RevertFaqContent(revisionId, &revision)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to revert faq content",
 			"error":   err.Error(),
 		})
@@ -419,6 +463,8 @@ func (h *CMSFaqPageHandler) HandleRevertFaqContent(c *fiber.Ctx) error {
 // @Param        faqContent     body  dto.CreateFaqContentRequest  true  "Updated FAQ Content"
 // @Success      200  {object}  dto.CMSFaqContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      422  {object}  dto.ErrorResponse "Critical audit findings block publishing"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/{contentId}/contents [put]
 func (h *CMSFaqPageHandler) HandleUpdateFaqContent(c *fiber.Ctx) error {
@@ -441,9 +487,15 @@ func (h *CMSFaqPageHandler) HandleUpdateFaqContent(c *fiber.Ctx) error {
 
 	helpers.SanitizeFaqContent(&updatedContent)
 
-	faqContent, err := h.Service.UpdateFaqContent(&updatedContent, contentId)
+	faqContent, err := h.service(c).UpdateFaqContent(&updatedContent, contentId)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, errs.ErrCriticalAuditFindings) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "content cannot be published until critical audit findings are fixed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to update faq content",
 			"error":   err.Error(),
 		})
@@ -466,6 +518,7 @@ func (h *CMSFaqPageHandler) HandleUpdateFaqContent(c *fiber.Ctx) error {
 // @Param        mode              query     string  false "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSFaqCategoriesSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/category/{categoryTypeCode}/{pageId}/{languageCode} [get]
 func (h *CMSFaqPageHandler) HandleGetCategory(c *fiber.Ctx) error {
@@ -480,9 +533,9 @@ func (h *CMSFaqPageHandler) HandleGetCategory(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
-	categories, err := h.Service.FindCategories(pageId, categoryTypeCode, language, mode)
+	categories, err := h.service(c).FindCategories(pageId, categoryTypeCode, language, mode)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get categories",
 			"error":   err.Error(),
 		})
@@ -503,6 +556,7 @@ func (h *CMSFaqPageHandler) HandleGetCategory(c *fiber.Ctx) error {
 // @Param        languageCode      path      string  true  "Language Code (e.g., en, th)"
 // @Success      200           {object}  dto.CMSFaqRevsionsSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/revisions/{languageCode}/{pageId} [get]
 func (h *CMSFaqPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
@@ -516,9 +570,9 @@ func (h *CMSFaqPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
 		})
 	}
 
-	revisions, err := h.Service.FindRevisions(pageId, language)
+	revisions, err := h.service(c).FindRevisions(pageId, language)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get revisions",
 			"error":   err.Error(),
 		})
@@ -532,15 +586,19 @@ func (h *CMSFaqPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
 
 // HandlePreviewFaqContent handles POST request to preview FAQ content.
 // @Summary      Preview FAQ Content
-// @Description  Preview an existing FAQ content by its content ID.
+// @Description  Save the preview copy of a FAQ content and issue a signed preview link for it.
 // @Description  This operation does not merge with previous content — it overwrites everything.
+// @Description  The link expires after the ttl and can be revoked from /cms/previews.
 // @Tags         CMS - Faq Pages
 // @Accept       json
 // @Produce      json
 // @Param        pageId      path  string           true  "FAQ Page ID (UUID)"
+// @Param        ttl         query  string  false  "Link lifetime such as 30m or 48h (default from PREVIEW_DEFAULT_TTL)"
+// @Param        X-Preview-Password  header  string  false  "Optional password required to open the link"
 // @Param        faqContent     body  dto.CreateFaqContentPreviewRequest  true  "Preview FAQ Content"
-// @Success      200  {object}  dto.CMSFaqContentSuccessResponse200
+// @Success      200  {object}  dto.CMSPreviewLinkSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/faqpages/previews/{pageId} [post]
 func (h *CMSFaqPageHandler) HandlePreviewFaqContent(c *fiber.Ctx) error {
@@ -553,26 +611,50 @@ func (h *CMSFaqPageHandler) HandlePreviewFaqContent(c *fiber.Ctx) error {
 		})
 	}
 
+	ttl, err := h.PreviewLinkService.ParsePreviewTTL(c.Query("ttl"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid preview ttl",
+			"error":   err.Error(),
+		})
+	}
+
 	var faqContentPreview models.FaqContent
 	if err := c.BodyParser(&faqContentPreview); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "Invalid faq content JSON",
 			"error":   err.Error(),
 		})
-	}	
+	}
 
 	helpers.SanitizeFaqContent(&faqContentPreview)
 
-	url, err := h.Service.PreviewFaqContent(pageId, &faqContentPreview)
+	savedContent, err := h.service(c).PreviewFaqContent(pageId, &faqContentPreview)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to preview content",
 			"error":   err.Error(),
 		})
-	}	
+	}
+
+	previewLink, err := h.PreviewLinkService.IssuePreviewLink(dto.IssuePreviewLinkRequest{
+		PageType:  enums.PageTypeFaq,
+		PageID:    pageId,
+		ContentID: savedContent.ID,
+		Language:  savedContent.Language,
+		TTL:       ttl,
+		Password:  c.Get("X-Preview-Password"),
+	})
+	if err != nil {
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"message": "failed to issue preview link",
+			"error":   err.Error(),
+		})
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "successfully preview faq content",
-		"url":    url,
+		"url":     previewLink.URL,
+		"item":    previewLink,
 	})
 }
\ No newline at end of file
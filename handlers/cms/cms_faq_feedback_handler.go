@@ -0,0 +1,271 @@
+package cms
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CMSFaqFeedbackHandler struct {
+	Service services.CMSFaqFeedbackServiceInterface
+}
+
+func NewCMSFaqFeedbackHandler(service services.CMSFaqFeedbackServiceInterface) *CMSFaqFeedbackHandler {
+	return &CMSFaqFeedbackHandler{Service: service}
+}
+
+// parseFaqFeedbackQuery reads from, to, language and pageId, the range defaults to the last 30 days
+func parseFaqFeedbackQuery(c *fiber.Ctx) (dto.FaqFeedbackQuery, error) {
+	query := dto.FaqFeedbackQuery{Language: enums.PageLanguage(c.Query("language"))}
+
+	var err error
+	if query.From, err = parseCalendarTime(c.Query("from"), false); err != nil {
+		return query, errs.ErrInvalidDateRange
+	}
+	if query.To, err = parseCalendarTime(c.Query("to"), true); err != nil {
+		return query, errs.ErrInvalidDateRange
+	}
+	if query.To.IsZero() {
+		query.To = time.Now().UTC()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-30 * 24 * time.Hour)
+	}
+
+	if pageId := c.Query("pageId"); pageId != "" {
+		parsed, err := uuid.Parse(pageId)
+		if err != nil {
+			return query, errs.ErrInvalidUUIDFormat
+		}
+		query.PageID = &parsed
+	}
+
+	return query, nil
+}
+
+func faqFeedbackErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errs.ErrInvalidDateRange) ||
+		errors.Is(err, errs.ErrInvalidInterval) ||
+		errors.Is(err, errs.ErrInvalidLanguageCode) ||
+		errors.Is(err, errs.ErrInvalidUUIDFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid feedback filter",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "failed to get feedback",
+		"error":   err.Error(),
+	})
+}
+
+// sendCSV writes the rows as a downloadable CSV file
+func sendCSV(c *fiber.Ctx, filename string, rows [][]string) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to export feedback",
+			"error":   err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// csvText keeps spreadsheet apps from running user written text as a formula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatRatio(ratio float64) string {
+	return strconv.FormatFloat(ratio, 'f', 4, 64)
+}
+
+// HandleGetHelpfulnessSeries handles GET requests to chart the FAQ helpfulness over time
+// @Summary      Get Faq Helpfulness Over Time
+// @Description  Count the helpful and not helpful votes with the helpful ratio per day, week or month.
+// @Description  from defaults to 30 days before to and to defaults to now, the range can be at most 366 days.
+// @Tags         CMS - Faq Feedback
+// @Produce      json
+// @Produce      text/csv
+// @Param        interval  query  string  false  "Bucket size"  Enums(day, week, month)
+// @Param        from      query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to        query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        language  query  string  false  "Filter by language"  Enums(en, th)
+// @Param        pageId    query  string  false  "Filter by faq page ID (UUID)"
+// @Param        format    query  string  false  "csv downloads the result as a CSV file"
+// @Success      200  {object}  dto.FaqHelpfulnessSeriesSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/faqfeedback/stats [get]
+func (h *CMSFaqFeedbackHandler) HandleGetHelpfulnessSeries(c *fiber.Ctx) error {
+	query, err := parseFaqFeedbackQuery(c)
+	if err != nil {
+		return faqFeedbackErrorResponse(c, err)
+	}
+
+	points, err := h.Service.GetHelpfulnessSeries(query, c.Query("interval"))
+	if err != nil {
+		return faqFeedbackErrorResponse(c, err)
+	}
+
+	if c.Query("format") == "csv" {
+		rows := [][]string{{"period", "helpful", "not_helpful", "ratio"}}
+		for _, point := range points {
+			rows = append(rows, []string{
+				point.Period.Format(time.RFC3339),
+				strconv.FormatInt(point.Helpful, 10),
+				strconv.FormatInt(point.NotHelpful, 10),
+				formatRatio(point.Ratio),
+			})
+		}
+		return sendCSV(c, "faq-helpfulness.csv", rows)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get helpfulness",
+		"items":   points,
+	})
+}
+
+// HandleGetLowestRated handles GET requests to list the least helpful FAQs
+// @Summary      Get Lowest Rated Faqs
+// @Description  Rank the faq pages per language by their helpful ratio, lowest first, among those with at least minVotes votes in the range.
+// @Tags         CMS - Faq Feedback
+// @Produce      json
+// @Produce      text/csv
+// @Param        minVotes  query  int     false  "Minimum votes to be ranked (default is 5)"
+// @Param        limit     query  int     false  "Number of faqs (default is 20, max 100)"
+// @Param        from      query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to        query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        language  query  string  false  "Filter by language"  Enums(en, th)
+// @Param        format    query  string  false  "csv downloads the result as a CSV file"
+// @Success      200  {object}  dto.FaqLowestRatedSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/faqfeedback/lowest [get]
+func (h *CMSFaqFeedbackHandler) HandleGetLowestRated(c *fiber.Ctx) error {
+	query, err := parseFaqFeedbackQuery(c)
+	if err != nil {
+		return faqFeedbackErrorResponse(c, err)
+	}
+
+	minVotes, _ := strconv.Atoi(c.Query("minVotes", "5"))
+	if minVotes < 1 {
+		minVotes = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ranks, err := h.Service.GetLowestRated(query, minVotes, limit)
+	if err != nil {
+		return faqFeedbackErrorResponse(c, err)
+	}
+
+	if c.Query("format") == "csv" {
+		rows := [][]string{{"page_id", "language", "title", "helpful", "not_helpful", "ratio"}}
+		for _, rank := range ranks {
+			rows = append(rows, []string{
+				rank.PageID.String(),
+				string(rank.Language),
+				csvText(rank.Title),
+				strconv.FormatInt(rank.Helpful, 10),
+				strconv.FormatInt(rank.NotHelpful, 10),
+				formatRatio(rank.Ratio),
+			})
+		}
+		return sendCSV(c, "faq-lowest-rated.csv", rows)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get lowest rated faqs",
+		"items":   ranks,
+	})
+}
+
+// HandleGetFeedbackComments handles GET requests to read the feedback text left with the votes
+// @Summary      Get Faq Feedback Text
+// @Description  List the votes that came with feedback text, newest first. The CSV export ignores page and limit and returns up to 10000 rows.
+// @Tags         CMS - Faq Feedback
+// @Produce      json
+// @Produce      text/csv
+// @Param        page      query  int     false  "Page number (default is 1)"
+// @Param        limit     query  int     false  "Items per page (default is 20, max 100)"
+// @Param        from      query  string  false  "Range start, RFC 3339 or YYYY-MM-DD"
+// @Param        to        query  string  false  "Range end, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param        language  query  string  false  "Filter by language"  Enums(en, th)
+// @Param        pageId    query  string  false  "Filter by faq page ID (UUID)"
+// @Param        format    query  string  false  "csv downloads the result as a CSV file"
+// @Success      200  {object}  dto.FaqFeedbackCommentsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/faqfeedback/comments [get]
+func (h *CMSFaqFeedbackHandler) HandleGetFeedbackComments(c *fiber.Ctx) error {
+	query, err := parseFaqFeedbackQuery(c)
+	if err != nil {
+		return faqFeedbackErrorResponse(c, err)
+	}
+
+	export := c.Query("format") == "csv"
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if export {
+		page, limit = 1, dto.FeedbackExportMaxRows
+	}
+
+	comments, totalCount, err := h.Service.GetFeedbackComments(query, page, limit)
+	if err != nil {
+		return faqFeedbackErrorResponse(c, err)
+	}
+
+	if export {
+		rows := [][]string{{"id", "page_id", "faq_content_id", "language", "title", "helpful", "feedback", "updated_at"}}
+		for _, comment := range comments {
+			rows = append(rows, []string{
+				comment.ID.String(),
+				comment.PageID.String(),
+				comment.FaqContentID.String(),
+				string(comment.Language),
+				csvText(comment.Title),
+				strconv.FormatBool(comment.Helpful),
+				csvText(comment.Feedback),
+				comment.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+		return sendCSV(c, "faq-feedback.csv", rows)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "successfully get feedback",
+		"totalCount": totalCount,
+		"page":       page,
+		"limit":      limit,
+		"items":      comments,
+	})
+}
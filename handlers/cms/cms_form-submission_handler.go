@@ -31,6 +31,7 @@ func NewCMSFormSubmissionHandler(service services.CMSFormSubmissionServiceInterf
 // @Failure 		 400  {object} dto.ErrorResponse400
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /cms/forms/{formId}/submissions [post]
+// @Router       /app/forms/{formId}/submissions [post]
 func (h *CMSFormSubmissionHandler) HandleCreateFormSubmission(c *fiber.Ctx) error {
 	formIdStr := c.Params("formId")
 	formId, err := uuid.Parse(formIdStr)
@@ -71,6 +72,8 @@ func (h *CMSFormSubmissionHandler) HandleCreateFormSubmission(c *fiber.Ctx) erro
 // @Param        sort   query  string  false  "Sorting fields, e.g., 'title:asc,updated_at:desc'"
 // @Param        page     query  int     false  "Page number for pagination (default is 1)"
 // @Param        limit    query  int     false  "Number of items per page (default is 10)"
+// @Param        cursor     query  string  false  "Switches to cursor pagination, empty for the first page, then the next or prev cursor of the previous response. Sorts on created_at or updated_at only"
+// @Param        withTotal  query  bool    false  "Also return totalCount in cursor pagination"
 // @Success      200  {object} dto.CMSFormSubmissionsSuccessResponse200
 // @Failure 		 400  {object} dto.ErrorResponse400
 // @Failure      500  {object} dto.ErrorResponse500
@@ -88,6 +91,14 @@ func (h *CMSFormSubmissionHandler) HandleGetFormSubmissions(c *fiber.Ctx) error
 		})
 	}
 
+	if cursorQuery, ok := cursorQueryFromRequest(c); ok {
+		formSubmissions, cursorPage, err := h.Service.GetFormSubmissionsByCursor(formId, sort, cursorQuery)
+		if err != nil {
+			return cursorErrorResponse(c, "failed to get the formSubmissions", err)
+		}
+		return cursorPageResponse(c, "successfully got the formSubmissions", formSubmissions, cursorPage)
+	}
+
 	formSubmissions, totalCount, err := h.Service.GetFormSubmissions(formId, sort, page, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/services"
 
 	// สำหรับ ParseInt
@@ -19,12 +21,14 @@ import (
 
 type CMSFormHandler struct {
 	service  services.CMSFormServiceInterface
+	cache    services.AppResponseCacheInterface // Serves the app form structures, dropped when a form changes
 	validate *validator.Validate
 }
 
-func NewCMSFormHandler(service services.CMSFormServiceInterface) *CMSFormHandler {
+func NewCMSFormHandler(service services.CMSFormServiceInterface, cache services.AppResponseCacheInterface) *CMSFormHandler {
 	return &CMSFormHandler{
 		service:  service,
+		cache:    cache,
 		validate: validator.New(),
 	}
 }
@@ -86,6 +90,9 @@ func (h *CMSFormHandler) HandleCreateForm(c *fiber.Ctx) error {
 // @Param items_per_page query int false "Number of items per page (default: 10, max: 100)"
 // @Param sort_by query string false "Sort by field (e.g., name, created_at, updated_at)" Enums(name, created_at, updated_at)
 // @Param sort_order query string false "Sort order (asc, desc)" Enums(asc, desc)
+// @Param cursor query string false "Switches to cursor pagination, empty for the first page, then the next or prev cursor of the previous response"
+// @Param limit query int false "Number of items per page in cursor pagination (default: 10, max: 100)"
+// @Param withTotal query bool false "Also return totalCount in cursor pagination"
 // @Success 200 {object} dto.PaginatedFormListResponse "List of form templates"
 // @Failure 400 {object} dto.ErrorResponse "Bad Request - Invalid query parameters"
 // @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
@@ -104,6 +111,14 @@ func (h *CMSFormHandler) HandleListForms(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Validation failed for query parameters", Error: err.Error()})
 	}
 
+	if cursorQuery, ok := cursorQueryFromRequest(c); ok {
+		forms, cursorPage, err := h.service.GetFormsByCursor(filter, cursorQuery)
+		if err != nil {
+			return cursorErrorResponse(c, "failed to list forms", err)
+		}
+		return cursorPageResponse(c, "successfully list forms", forms, cursorPage)
+	}
+
 	// Set default pagination and sorting if not provided or handle in service
 	if filter.Page == nil {
 		defaultPage := 1
@@ -162,10 +177,14 @@ func (h *CMSFormHandler) HandleGetForm(c *fiber.Ctx) error {
 // HandleGetForm ดึงข้อมูล Form Structure เดียวตาม ID
 // @Summary Get Form Structure by ID
 // @Description Retrieves a specific form structure by its UUID, including sections and fields.
+// @Description Responses carry ETag, Last-Modified and Cache-Control headers, a matching If-None-Match or If-Modified-Since gets a 304.
 // @Tags App - Forms
 // @Produce json
 // @Param formId path string true "Form Structure ID (UUID)"
+// @Param If-None-Match header string false "ETag of the response the client has"
+// @Param If-Modified-Since header string false "Last-Modified of the response the client has"
 // @Success 200 {object} dto.FormResponse "Details of the form structure"
+// @Success 304 "The response the client has is still current"
 // @Failure 400 {object} dto.ErrorResponse "Bad Request - Invalid Form ID format"
 // @Failure 404 {object} dto.ErrorResponse "Not Found - Form structure not found"
 // @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
@@ -178,6 +197,12 @@ func (h *CMSFormHandler) HandleGetFormStructure(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid form ID format", Error: err.Error()})
 	}
 
+	cacheKey := "form|" + formID.String()
+	if cached, ok := h.cache.Get(cacheKey); ok {
+		return helpers.SendCachedResponse(c, cached, h.cache.MaxAge())
+	}
+	loadedAt := time.Now()
+
 	formResponse, err := h.service.GetFormStructure(formID)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
@@ -187,7 +212,13 @@ func (h *CMSFormHandler) HandleGetFormStructure(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Message: "Internal server error while getting form details"})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(formResponse)
+	response, err := helpers.NewCachedResponse(c, formResponse, formResponse.UpdatedAt, loadedAt)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Message: "Internal server error while getting form details", Error: err.Error()})
+	}
+	h.cache.Set(cacheKey, response, dto.CacheTags{FormIDs: []uuid.UUID{formID}})
+
+	return helpers.SendCachedResponse(c, response, h.cache.MaxAge())
 }
 
 // HandleUpdateForm อัปเดต Form Template ที่มีอยู่
@@ -242,6 +273,7 @@ func (h *CMSFormHandler) HandleUpdateForm(c *fiber.Ctx) error {
 		fmt.Printf("Error updating form %s: %v\n", formIDStr, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Message: "Internal server error while updating form"})
 	}
+	h.cache.InvalidateForm(formID)
 
 	return c.Status(fiber.StatusOK).JSON(updatedFormResponse)
 }
@@ -278,6 +310,7 @@ func (h *CMSFormHandler) HandleDeleteForm(c *fiber.Ctx) error {
 		fmt.Printf("Error deleting form %s: %v\n", formIDStr, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Message: "Internal server error while deleting form"})
 	}
+	h.cache.InvalidateForm(formID)
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
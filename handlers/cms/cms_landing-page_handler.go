@@ -4,9 +4,11 @@ import (
 	"errors"
 	"strconv"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,11 +16,18 @@ import (
 )
 
 type CMSLandingPageHandler struct {
-	Service services.CMSLandingPageServiceInterface
+	Service            services.CMSLandingPageServiceInterface
+	PreviewLinkService services.CMSPreviewLinkServiceInterface
+	UsageService       services.CMSUsageServiceInterface
 }
 
-func NewCMSLandingPageHandler(service services.CMSLandingPageServiceInterface) *CMSLandingPageHandler {
-	return &CMSLandingPageHandler{Service: service}
+func NewCMSLandingPageHandler(service services.CMSLandingPageServiceInterface, previewLinkService services.CMSPreviewLinkServiceInterface, usageService services.CMSUsageServiceInterface) *CMSLandingPageHandler {
+	return &CMSLandingPageHandler{Service: service, PreviewLinkService: previewLinkService, UsageService: usageService}
+}
+
+// service limits the page service to the page access of the user of the request
+func (h *CMSLandingPageHandler) service(c *fiber.Ctx) services.CMSLandingPageServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
 }
 
 // HandleCreateLandingPage handles POST requests to create a new Landing page
@@ -30,6 +39,8 @@ func NewCMSLandingPageHandler(service services.CMSLandingPageServiceInterface) *
 // @Param        Landing_page_data  body  dto.CreateLandingPageRequest  true  "Landing Page payload (optional: categories, components)"
 // @Success      200  {object} dto.CMSLandingPageSuccessResponse200
 // @Failure 		 400  {object} dto.ErrorResponse400
+// @Failure 		 403  {object} dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      422  {object} dto.ErrorResponse "Critical audit findings block publishing"
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /cms/landingpages [post]
 func (h *CMSLandingPageHandler) HandleCreateLandingPage(c *fiber.Ctx) error {
@@ -43,9 +54,15 @@ func (h *CMSLandingPageHandler) HandleCreateLandingPage(c *fiber.Ctx) error {
 
 	helpers.SanitizeLandingPage(&landingPage)
 
-	createdLandingPage, err := h.Service.CreateLandingPage(&landingPage)
+	createdLandingPage, err := h.service(c).CreateLandingPage(&landingPage)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, errs.ErrCriticalAuditFindings) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "content cannot be published until critical audit findings are fixed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "Failed to create Landing page",
 			"error":   err.Error(),
 		})
@@ -67,6 +84,8 @@ func (h *CMSLandingPageHandler) HandleCreateLandingPage(c *fiber.Ctx) error {
 // @Param        page     query  int     false  "Page number for pagination (default is 1)"
 // @Param        limit    query  int     false  "Number of items per page (default is 10)"
 // @Param        language  query  string  false  "Language code for localized content (e.g., en, th)"
+// @Param        cursor     query  string  false  "Switches to cursor pagination, empty for the first page, then the next or prev cursor of the previous response. Sorts on created_at or updated_at only"
+// @Param        withTotal  query  bool    false  "Also return totalCount in cursor pagination"
 // @Success      200  {object} dto.CMSLandingPageSuccessResponse200
 // @Failure 		 400  {object} dto.ErrorResponse400
 // @Failure      500  {object} dto.ErrorResponse500
@@ -78,7 +97,15 @@ func (h *CMSLandingPageHandler) HandleGetLandingPages(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	language := c.Query("language", "")
 
-	results, totalCount, err := h.Service.FindLandingPages(rawQuery, sort, page, limit, language)
+	if cursorQuery, ok := cursorQueryFromRequest(c); ok {
+		results, cursorPage, err := h.service(c).FindLandingPagesByCursor(rawQuery, sort, cursorQuery, language)
+		if err != nil {
+			return cursorErrorResponse(c, "failed to find Landing pages", err)
+		}
+		return cursorPageResponse(c, "successfully get all Landing pages", results, cursorPage)
+	}
+
+	results, totalCount, err := h.service(c).FindLandingPages(rawQuery, sort, page, limit, language)
 	if err != nil {
 		if errors.Is(err, errs.ErrInvalidQuery) {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -109,6 +136,7 @@ func (h *CMSLandingPageHandler) HandleGetLandingPages(c *fiber.Ctx) error {
 // @Param        pageid  path  string  true  "Landing Page ID"
 // @Success      200  {object} dto.CMSLandingPageSuccessResponse200
 // @Failure      400  {object} dto.ErrorResponse400
+// @Failure      403  {object} dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /cms/landingpages/{pageid} [get]
 func (h *CMSLandingPageHandler) HandleGetLandingPageById(c *fiber.Ctx) error {
@@ -121,9 +149,9 @@ func (h *CMSLandingPageHandler) HandleGetLandingPageById(c *fiber.Ctx) error {
 		})
 	}
 
-	LandingPage, err := h.Service.FindLandingPageById(id)
+	LandingPage, err := h.service(c).FindLandingPageById(id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to find Landing page",
 			"error":   err.Error(),
 		})
@@ -140,9 +168,12 @@ func (h *CMSLandingPageHandler) HandleGetLandingPageById(c *fiber.Ctx) error {
 // @Description  Delete an existing Landing page using its unique identifier.
 // @Tags         CMS - Landing Pages
 // @Produce      json
-// @Param        pageId  path  string  true  "Landing Page ID"
+// @Param        pageId  path   string  true   "Landing Page ID"
+// @Param        force   query  bool    false  "Delete even when published content still refers to the page"
 // @Success      200  {object}  dto.CMSSuccessResponse
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      409  {object}  dto.UsageConflictResponse409
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/{pageId} [delete]
 func (h *CMSLandingPageHandler) HandleDeleteLandingPage(c *fiber.Ctx) error {
@@ -155,9 +186,16 @@ func (h *CMSLandingPageHandler) HandleDeleteLandingPage(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.Service.DeleteLandingPage(id)
+	if stop, err := checkDeleteUsage(c, h.UsageService, enums.UsageItemLandingPage, id); stop {
+		return err
+	}
+
+	err = h.service(c).DeleteLandingPage(id, c.QueryBool("force"))
+	if errors.Is(err, errs.ErrItemInUse) {
+		return deleteConflictResponse(c, h.UsageService, enums.UsageItemLandingPage, id, err)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to delete Landing page",
 			"error":   err.Error(),
 		})
@@ -178,6 +216,7 @@ func (h *CMSLandingPageHandler) HandleDeleteLandingPage(c *fiber.Ctx) error {
 // @Param        mode          query     string  false   "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSLandingContentSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/{pageId}/contents/{languageCode} [get]
 func (h *CMSLandingPageHandler) HandleGetContentByLandingPageId(c *fiber.Ctx) error {
@@ -192,9 +231,9 @@ func (h *CMSLandingPageHandler) HandleGetContentByLandingPageId(c *fiber.Ctx) er
 		})
 	}
 
-	LandingContent, err := h.Service.FindContentByLandingPageId(id, language, mode)
+	LandingContent, err := h.service(c).FindContentByLandingPageId(id, language, mode)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "internal server error",
 			"error":   err.Error(),
 		})
@@ -215,6 +254,7 @@ func (h *CMSLandingPageHandler) HandleGetContentByLandingPageId(c *fiber.Ctx) er
 // @Param        languageCode  path      string  true   "Language Code (e.g., en, th)"
 // @Success      200  {object}  dto.CMSLandingContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/{pageId}/latestcontents/{languageCode} [get]
 func (h *CMSLandingPageHandler) HandleGetLatestContentByLandingPageId(c *fiber.Ctx) error {
@@ -228,9 +268,9 @@ func (h *CMSLandingPageHandler) HandleGetLatestContentByLandingPageId(c *fiber.C
 		})
 	}
 
-	LandingContent, err := h.Service.FindLatestContentByPageId(pageId, language)
+	LandingContent, err := h.service(c).FindLatestContentByPageId(pageId, language)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get the latest content",
 			"error":   err.Error(),
 		})
@@ -252,6 +292,7 @@ func (h *CMSLandingPageHandler) HandleGetLatestContentByLandingPageId(c *fiber.C
 // @Param        mode          query     string  false  "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSSuccessResponse
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/{pageId}/contents/{languageCode} [delete]
 func (h *CMSLandingPageHandler) HandleDeleteLandingContentByPageId(c *fiber.Ctx) error {
@@ -267,8 +308,8 @@ func (h *CMSLandingPageHandler) HandleDeleteLandingContentByPageId(c *fiber.Ctx)
 		})
 	}
 
-	if err := h.Service.DeleteContentByLandingPageId(pageId, language, mode); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	if err := h.service(c).DeleteContentByLandingPageId(pageId, language, mode); err != nil {
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to Delete Landing content",
 			"error":   err.Error(),
 		})
@@ -287,6 +328,7 @@ func (h *CMSLandingPageHandler) HandleDeleteLandingContentByPageId(c *fiber.Ctx)
 // @Param        pageId        path      string  true  "Landing Page ID (UUID)"
 // @Success      200           {object}  dto.CMSSuccessResponse
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/duplicate/{pageId} [post]
 func (h *CMSLandingPageHandler) HandleDuplicateLandingPage(c *fiber.Ctx) error {
@@ -299,9 +341,9 @@ func (h *CMSLandingPageHandler) HandleDuplicateLandingPage(c *fiber.Ctx) error {
 		})
 	}
 
-	LandingPage, err := h.Service.DuplicateLandingPage(pageId)
+	LandingPage, err := h.service(c).DuplicateLandingPage(pageId)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to duplicate page",
 			"error":   err.Error(),
 		})
@@ -323,6 +365,7 @@ func (h *CMSLandingPageHandler) HandleDuplicateLandingPage(c *fiber.Ctx) error {
 // @Param        revision  body  dto.CreateRevisionRequest  true  "Revision payload"
 // @Success      200           {object}  dto.CMSLandingContentSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/duplicate/{contentId}/contents [post]
 func (h *CMSLandingPageHandler) HandleDuplicateLandingContentToAnotherLanguage(c *fiber.Ctx) error {
@@ -345,9 +388,9 @@ func (h *CMSLandingPageHandler) HandleDuplicateLandingContentToAnotherLanguage(c
 
 	helpers.SanitizeRevision(&revision)
 
-	landingContent, err := h.Service.DuplicateLandingContentToAnotherLanguage(contentId, &revision)
+	landingContent, err := h.service(c).DuplicateLandingContentToAnotherLanguage(contentId, &revision)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to duplicate content to another language",
 			"error":   err.Error(),
 		})
@@ -369,6 +412,7 @@ func (h *CMSLandingPageHandler) HandleDuplicateLandingContentToAnotherLanguage(c
 // @Param        revision    body  dto.CreateRevisionRequest  true  "Revision payload"
 // @Success      200  {object}  dto.CMSLandingContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/{revisionId}/revisions [post]
 func (h *CMSLandingPageHandler) HandleRevertLandingContent(c *fiber.Ctx) error {
@@ -391,9 +435,9 @@ func (h *CMSLandingPageHandler) HandleRevertLandingContent(c *fiber.Ctx) error {
 
 	helpers.SanitizeRevision(&revision)
 
-	LandingContent, err := h.Service.RevertLandingContent(revisionId, &revision)
+	LandingContent, err := h.service(c).RevertLandingContent(revisionId, &revision)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to revert Landing content",
 			"error":   err.Error(),
 		})
@@ -417,6 +461,8 @@ func (h *CMSLandingPageHandler) HandleRevertLandingContent(c *fiber.Ctx) error {
 // @Param        landingContent     body  dto.CreateLandingContentRequest  true  "Updated Landing Content"
 // @Success      200  {object}  dto.CMSLandingContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      422  {object}  dto.ErrorResponse "Critical audit findings block publishing"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/{contentId}/contents [put]
 func (h *CMSLandingPageHandler) HandleUpdateLandingContent(c *fiber.Ctx) error {
@@ -439,9 +485,15 @@ func (h *CMSLandingPageHandler) HandleUpdateLandingContent(c *fiber.Ctx) error {
 
 	helpers.SanitizeLandingContent(&updatedContent)
 
-	LandingContent, err := h.Service.UpdateLandingContent(&updatedContent, contentId)
+	LandingContent, err := h.service(c).UpdateLandingContent(&updatedContent, contentId)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, errs.ErrCriticalAuditFindings) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "content cannot be published until critical audit findings are fixed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to update Landing content",
 			"error":   err.Error(),
 		})
@@ -464,6 +516,7 @@ func (h *CMSLandingPageHandler) HandleUpdateLandingContent(c *fiber.Ctx) error {
 // @Param        mode              query     string  false "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSLandingCategoriesSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/category/{categoryTypeCode}/{pageId}/{languageCode} [get]
 func (h *CMSLandingPageHandler) HandleGetCategory(c *fiber.Ctx) error {
@@ -478,9 +531,9 @@ func (h *CMSLandingPageHandler) HandleGetCategory(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
-	categories, err := h.Service.GetCategory(pageId, categoryTypeCode, language, mode)
+	categories, err := h.service(c).GetCategory(pageId, categoryTypeCode, language, mode)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get categories",
 			"error":   err.Error(),
 		})
@@ -503,6 +556,7 @@ func (h *CMSLandingPageHandler) HandleGetCategory(c *fiber.Ctx) error {
 // @Param        languageCode      path      string  true  "Language Code (e.g., en, th)"
 // @Success      200           {object}  dto.RevsionsSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/revisions/{languageCode}/{pageId} [get]
 func (h *CMSLandingPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
@@ -516,9 +570,9 @@ func (h *CMSLandingPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
 		})
 	}
 
-	revisions, err := h.Service.FindRevisions(pageId, language)
+	revisions, err := h.service(c).FindRevisions(pageId, language)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get revisions",
 			"error":   err.Error(),
 		})
@@ -532,15 +586,19 @@ func (h *CMSLandingPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
 
 // HandlePreviewLandingContent handles POST request to preview Landing content.
 // @Summary      Preview Landing Content
-// @Description  Preview an existing Landing content by its content ID.
+// @Description  Save the preview copy of a Landing content and issue a signed preview link for it.
 // @Description  This operation does not merge with previous content — it overwrites everything.
+// @Description  The link expires after the ttl and can be revoked from /cms/previews.
 // @Tags         CMS - Landing Pages
 // @Accept       json
 // @Produce      json
 // @Param        pageId      path  string           true  "Landing Page ID (UUID)"
+// @Param        ttl         query  string  false  "Link lifetime such as 30m or 48h (default from PREVIEW_DEFAULT_TTL)"
+// @Param        X-Preview-Password  header  string  false  "Optional password required to open the link"
 // @Param        landingContent     body  dto.CreateLandingContentPreviewRequest  true  "Preview Landing Content"
-// @Success      200  {object}  dto.CMSLandingContentSuccessResponse200
+// @Success      200  {object}  dto.CMSPreviewLinkSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/landingpages/previews/{pageId} [post]
 func (h *CMSLandingPageHandler) HandlePreviewLandingContent(c *fiber.Ctx) error {
@@ -553,26 +611,50 @@ func (h *CMSLandingPageHandler) HandlePreviewLandingContent(c *fiber.Ctx) error
 		})
 	}
 
+	ttl, err := h.PreviewLinkService.ParsePreviewTTL(c.Query("ttl"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid preview ttl",
+			"error":   err.Error(),
+		})
+	}
+
 	var landingContentPreview models.LandingContent
 	if err := c.BodyParser(&landingContentPreview); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "Invalid query JSON",
 			"error":   err.Error(),
 		})
-	}	
+	}
 
 	helpers.SanitizeLandingContent(&landingContentPreview)
 
-	url, err := h.Service.PreviewLandingContent(pageId, &landingContentPreview)
+	savedContent, err := h.service(c).PreviewLandingContent(pageId, &landingContentPreview)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to preview content",
 			"error":   err.Error(),
 		})
-	}	
+	}
+
+	previewLink, err := h.PreviewLinkService.IssuePreviewLink(dto.IssuePreviewLinkRequest{
+		PageType:  enums.PageTypeLanding,
+		PageID:    pageId,
+		ContentID: savedContent.ID,
+		Language:  savedContent.Language,
+		TTL:       ttl,
+		Password:  c.Get("X-Preview-Password"),
+	})
+	if err != nil {
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"message": "failed to issue preview link",
+			"error":   err.Error(),
+		})
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "successfully preview landing content",
-		"url":    url,
+		"url":     previewLink.URL,
+		"item":    previewLink,
 	})
 }
\ No newline at end of file
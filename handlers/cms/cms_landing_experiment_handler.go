@@ -0,0 +1,233 @@
+package cms
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSLandingExperimentHandler struct {
+	Service services.CMSLandingExperimentServiceInterface
+	Cache   services.AppResponseCacheInterface
+}
+
+func NewCMSLandingExperimentHandler(service services.CMSLandingExperimentServiceInterface, cache services.AppResponseCacheInterface) *CMSLandingExperimentHandler {
+	return &CMSLandingExperimentHandler{Service: service, Cache: cache}
+}
+
+// service limits the experiment service to the page access of the user of the request
+func (h *CMSLandingExperimentHandler) service(c *fiber.Ctx) services.CMSLandingExperimentServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
+}
+
+func experimentErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidExperiment), errors.Is(err, errs.ErrInvalidExperimentStatus),
+		errors.Is(err, errs.ErrInvalidExperimentVariant), errors.Is(err, errs.ErrInvalidLanguageCode),
+		errors.Is(err, errs.ErrNoRevisionFound):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, errs.ErrExperimentRunning), errors.Is(err, errs.ErrExperimentNotRunning),
+		errors.Is(err, errs.ErrExperimentCompleted), errors.Is(err, errs.ErrDuplicateURL):
+		status = fiber.StatusConflict
+	case errors.Is(err, errs.ErrCriticalAuditFindings):
+		status = fiber.StatusUnprocessableEntity
+	case errors.Is(err, errs.ErrPageAccessDenied):
+		status = fiber.StatusForbidden
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// HandleCreateExperiment handles POST requests to start an A/B experiment on a landing page language
+// @Summary      Create Landing Experiment
+// @Description  Splits the visitors of the published content between the variants by weight. Exactly one variant is the control, which serves the published content unchanged,
+// @Description  the others replace its title, html input or components. Only one experiment can run per page and language.
+// @Tags         CMS - Landing Experiments
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.CreateLandingExperimentRequest  true  "Experiment"
+// @Success      201  {object}  dto.LandingExperimentSuccessResponse201
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404 "No published content for the page and language"
+// @Failure      409  {object}  dto.ErrorResponse "An experiment is already running"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/experiments [post]
+func (h *CMSLandingExperimentHandler) HandleCreateExperiment(c *fiber.Ctx) error {
+	var request dto.CreateLandingExperimentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the body",
+			"error":   err.Error(),
+		})
+	}
+
+	experiment, err := h.service(c).CreateExperiment(request)
+	if err != nil {
+		return experimentErrorResponse(c, "failed to create experiment", err)
+	}
+	// A cached page would keep every visitor on the control content
+	h.Cache.InvalidatePage(enums.PageTypeLanding, experiment.PageID)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "successfully create experiment",
+		"item":    experiment,
+	})
+}
+
+// HandleGetExperiments handles GET requests to list the experiments, newest first
+// @Summary      List Landing Experiments
+// @Tags         CMS - Landing Experiments
+// @Produce      json
+// @Param        pageId  query  string  false  "Landing Page ID (UUID)"
+// @Param        status  query  string  false  "Experiment status"  Enums(running, completed)
+// @Success      200  {object}  dto.LandingExperimentsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/experiments [get]
+func (h *CMSLandingExperimentHandler) HandleGetExperiments(c *fiber.Ctx) error {
+	var pageId *uuid.UUID
+	if value := c.Query("pageId"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "failed to parse the pageId",
+				"error":   err.Error(),
+			})
+		}
+		pageId = &parsed
+	}
+
+	experiments, err := h.service(c).FindExperiments(pageId, enums.ExperimentStatus(c.Query("status")))
+	if err != nil {
+		return experimentErrorResponse(c, "failed to get experiments", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get experiments",
+		"items":   experiments,
+	})
+}
+
+// HandleGetExperimentResults handles GET requests to compare the conversion rates of the variants
+// @Summary      Get Landing Experiment Results
+// @Description  Exposures and conversions are counted once per visitor. Each variant is compared with the control by a two-proportion z-test,
+// @Description  significant is set when the p-value is below significance_level.
+// @Tags         CMS - Landing Experiments
+// @Produce      json
+// @Param        experimentId  path  string  true  "Experiment ID (UUID)"
+// @Success      200  {object}  dto.ExperimentResultsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/experiments/{experimentId}/results [get]
+func (h *CMSLandingExperimentHandler) HandleGetExperimentResults(c *fiber.Ctx) error {
+	experimentId, err := uuid.Parse(c.Params("experimentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the experimentId",
+			"error":   err.Error(),
+		})
+	}
+
+	results, err := h.service(c).GetExperimentResults(experimentId)
+	if err != nil {
+		return experimentErrorResponse(c, "failed to get experiment results", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get experiment results",
+		"item":    results,
+	})
+}
+
+// HandleStopExperiment handles POST requests to stop an experiment without a winner
+// @Summary      Stop Landing Experiment
+// @Description  Every visitor is served the published content again. A winner can still be promoted afterwards.
+// @Tags         CMS - Landing Experiments
+// @Produce      json
+// @Param        experimentId  path  string  true  "Experiment ID (UUID)"
+// @Success      200  {object}  dto.SuccessResponse
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      409  {object}  dto.ErrorResponse "Experiment is not running"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/experiments/{experimentId}/stop [post]
+func (h *CMSLandingExperimentHandler) HandleStopExperiment(c *fiber.Ctx) error {
+	experimentId, err := uuid.Parse(c.Params("experimentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the experimentId",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := h.service(c).StopExperiment(experimentId); err != nil {
+		return experimentErrorResponse(c, "failed to stop experiment", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully stop experiment",
+	})
+}
+
+// HandlePromoteWinner handles POST requests to make a variant the published content
+// @Summary      Promote Landing Experiment Winner
+// @Description  Saves the variant as a new version of the published content with a revision and ends the experiment.
+// @Description  Promoting the control keeps the published content. The revision message defaults to the experiment and variant names.
+// @Tags         CMS - Landing Experiments
+// @Accept       json
+// @Produce      json
+// @Param        experimentId  path  string  true  "Experiment ID (UUID)"
+// @Param        request  body  dto.PromoteExperimentRequest  true  "Winning variant"
+// @Success      200  {object}  dto.CMSLandingContentSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Role does not allow publishing pages, or the page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      409  {object}  dto.ErrorResponse "Experiment already has a winner"
+// @Failure      422  {object}  dto.ErrorResponse "Variant has critical audit findings"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/experiments/{experimentId}/promote [post]
+func (h *CMSLandingExperimentHandler) HandlePromoteWinner(c *fiber.Ctx) error {
+	experimentId, err := uuid.Parse(c.Params("experimentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the experimentId",
+			"error":   err.Error(),
+		})
+	}
+
+	var request dto.PromoteExperimentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the body",
+			"error":   err.Error(),
+		})
+	}
+
+	content, err := h.service(c).PromoteWinner(experimentId, request)
+	if err != nil {
+		return experimentErrorResponse(c, "failed to promote the winner", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully promote the winner",
+		"item":    content,
+	})
+}
@@ -0,0 +1,119 @@
+package cms
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CMSLinkCheckHandler struct {
+	Service services.CMSLinkCheckServiceInterface
+}
+
+func NewCMSLinkCheckHandler(service services.CMSLinkCheckServiceInterface) *CMSLinkCheckHandler {
+	return &CMSLinkCheckHandler{Service: service}
+}
+
+// service limits the link check service to the page access of the user of the request
+func (h *CMSLinkCheckHandler) service(c *fiber.Ctx) services.CMSLinkCheckServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
+}
+
+// HandleGetLinkChecks handles GET requests to retrieve the broken link report
+// @Summary      List Link Checks
+// @Description  Retrieve the last known status of every link found in published landing, partner and faq contents, limited to the pages the user may read.
+// @Tags         CMS - Link Checks
+// @Produce      json
+// @Param        pageId      query  string  false  "Filter by page ID (UUID)"
+// @Param        pageType    query  string  false  "Filter by page type"  Enums(landing, partner, faq)
+// @Param        status      query  string  false  "Filter by status"  Enums(ok, broken, error)
+// @Param        isInternal  query  bool    false  "Filter internal or external links"
+// @Param        page        query  int     false  "Page number for pagination (default is 1)"
+// @Param        limit       query  int     false  "Number of items per page (default is 10)"
+// @Success      200  {object}  dto.CMSLinkChecksSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/link-checks [get]
+func (h *CMSLinkCheckHandler) HandleGetLinkChecks(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	query := dto.LinkCheckQuery{
+		PageID:   c.Query("pageId"),
+		PageType: enums.PageType(c.Query("pageType")),
+		Status:   enums.LinkStatus(c.Query("status")),
+	}
+	if isInternal, err := strconv.ParseBool(c.Query("isInternal")); err == nil {
+		query.IsInternal = &isInternal
+	}
+
+	results, totalCount, err := h.service(c).FindLinkChecks(query, page, limit)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidUUIDFormat) || errors.Is(err, errs.ErrInvalidPageType) || errors.Is(err, errs.ErrInvalidLinkStatus) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid link check filter",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"message": "failed to find link checks",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "successfully get link checks",
+		"totalCount": totalCount,
+		"page":       page,
+		"limit":      limit,
+		"items":      results,
+	})
+}
+
+// HandleRunLinkCheck handles POST requests to re-check every link now
+// @Summary      Run Link Check
+// @Description  Start checking every link of the published contents in the background. The report is updated when the run finishes.
+// @Description  A run covers every page, so it needs page access to all of them.
+// @Tags         CMS - Link Checks
+// @Produce      json
+// @Success      202  {object}  dto.CMSLinkCheckRunSuccessResponse202
+// @Failure      403  {object}  dto.ErrorResponse "Page access does not cover every page"
+// @Failure      409  {object}  dto.ErrorResponse "A link check is already in progress"
+// @Router       /cms/link-checks/run [post]
+func (h *CMSLinkCheckHandler) HandleRunLinkCheck(c *fiber.Ctx) error {
+	if err := h.service(c).CheckRunAccess(); err != nil {
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"message": "failed to start link check",
+			"error":   err.Error(),
+		})
+	}
+	if h.Service.IsLinkCheckRunning() {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": "failed to start link check",
+			"error":   errs.ErrLinkCheckInProgress.Error(),
+		})
+	}
+
+	go func() {
+		summary, err := h.Service.RunLinkCheck(context.Background())
+		if err != nil {
+			log.Printf("Link check failed: %v", err)
+			return
+		}
+		log.Printf("Link check done: %d links in %d contents, %d broken, %d errors",
+			summary.LinksChecked, summary.ContentsScanned, summary.Broken, summary.Errors)
+	}()
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "link check started",
+	})
+}
@@ -0,0 +1,67 @@
+package cms
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CMSMaintenanceHandler struct {
+	Service services.CMSMaintenanceServiceInterface
+}
+
+func NewCMSMaintenanceHandler(service services.CMSMaintenanceServiceInterface) *CMSMaintenanceHandler {
+	return &CMSMaintenanceHandler{Service: service}
+}
+
+// HandleRunMaintenance handles POST requests to run the maintenance cleanup now
+// @Summary      Run Maintenance Cleanup
+// @Description  Purge expired preview contents, history versions beyond the retention policy and orphaned meta tags, components, revisions and relations of deleted pages. With dryRun nothing is deleted and the report shows what would be removed.
+// @Tags         CMS - Maintenance
+// @Produce      json
+// @Param        dryRun  query  bool  false  "Only report what would be removed"
+// @Success      200  {object}  dto.CMSMaintenanceReportSuccessResponse200
+// @Failure      409  {object}  dto.ErrorResponse "A maintenance cleanup is already in progress"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/maintenance/cleanup [post]
+func (h *CMSMaintenanceHandler) HandleRunMaintenance(c *fiber.Ctx) error {
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun", "false"))
+
+	report, err := h.Service.RunMaintenance(context.Background(), dryRun)
+	if err != nil {
+		if errors.Is(err, errs.ErrMaintenanceInProgress) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "failed to run maintenance cleanup",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to run maintenance cleanup",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully run maintenance cleanup",
+		"data":    report,
+	})
+}
+
+// HandleGetMaintenanceMetrics handles GET requests to retrieve what the maintenance cleanup removed
+// @Summary      Get Maintenance Metrics
+// @Description  Retrieve the number of runs, the rows removed per table since the server started and the last report.
+// @Tags         CMS - Maintenance
+// @Produce      json
+// @Success      200  {object}  dto.CMSMaintenanceMetricsSuccessResponse200
+// @Router       /cms/maintenance/metrics [get]
+func (h *CMSMaintenanceHandler) HandleGetMaintenanceMetrics(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get maintenance metrics",
+		"data":    h.Service.GetMaintenanceMetrics(),
+	})
+}
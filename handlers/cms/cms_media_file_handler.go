@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/go-playground/validator/v10"
@@ -19,14 +21,16 @@ import (
 )
 
 type MediaFileHandler struct {
-	Service  services.MediaFileServiceInterface
-	validate *validator.Validate
+	Service      services.MediaFileServiceInterface
+	UsageService services.CMSUsageServiceInterface
+	validate     *validator.Validate
 }
 
-func NewMediaFileHandler(service services.MediaFileServiceInterface) *MediaFileHandler {
+func NewMediaFileHandler(service services.MediaFileServiceInterface, usageService services.CMSUsageServiceInterface) *MediaFileHandler {
 	return &MediaFileHandler{
-		Service:  service,
-		validate: validator.New(),
+		Service:      service,
+		UsageService: usageService,
+		validate:     validator.New(),
 	}
 }
 
@@ -134,6 +138,9 @@ func (h *MediaFileHandler) HandleUploadMediaFile(c *fiber.Ctx) error {
 // @Param        pageSize query int false "Items per page (default: 20, max: 100)"
 // @Param        sortBy query string false "Sort by 'name' or 'created_at' (default: 'created_at')" Enums(name, created_at)
 // @Param        order query string false "Sort order 'asc' or 'desc' (default: 'desc')" Enums(asc, desc)
+// @Param        cursor query string false "Switches to cursor pagination, empty for the first page, then the next or prev cursor of the previous response"
+// @Param        limit query int false "Items per page in cursor pagination (default: 20, max: 100)"
+// @Param        withTotal query bool false "Also return totalCount in cursor pagination"
 // @Success      200  {object} dto.MediaFilesListResponse
 // @Failure      400  {object} dto.ErrorResponse "Invalid query parameters"
 // @Failure      500  {object} dto.ErrorResponse "Internal Server Error"
@@ -149,6 +156,14 @@ func (h *MediaFileHandler) HandleListMediaFiles(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "Validation failed for query parameters", Message: err.Error()})
 	}
 
+	if cursorQuery, ok := cursorQueryFromRequest(c); ok {
+		files, cursorPage, err := h.Service.ListMediaFilesByCursor(filter, cursorQuery)
+		if err != nil {
+			return cursorErrorResponse(c, "Failed to list media files", err)
+		}
+		return cursorPageResponse(c, "successfully list media files", files, cursorPage)
+	}
+
 	response, err := h.Service.ListMediaFiles(filter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "Failed to list media files", Message: err.Error()})
@@ -184,12 +199,14 @@ func (h *MediaFileHandler) HandleGetMediaFileByID(c *fiber.Ctx) error {
 
 // HandleDeleteMediaFile deletes a media file.
 // @Summary      Delete Media File
-// @Description  Deletes a media file by its UUID from both the database and the disk.
+// @Description  Deletes a media file by its UUID from both the database and the disk. Refused while published content shows it, unless forced.
 // @Tags         CMS - Media Files
 // @Param        id path string true "Media File ID (UUID)"
+// @Param        force query bool false "Delete even when published content still refers to the file"
 // @Success      204  "No Content - File deleted successfully"
 // @Failure      400  {object} dto.ErrorResponse "Invalid ID format"
 // @Failure      404  {object} dto.ErrorResponse "Media file not found"
+// @Failure      409  {object} dto.UsageConflictResponse409 "Media file in use"
 // @Failure      500  {object} dto.ErrorResponse "Internal Server Error (e.g., failed to delete file from disk or DB)"
 // @Router       /cms/media-files/{id} [delete]
 func (h *MediaFileHandler) HandleDeleteMediaFile(c *fiber.Ctx) error {
@@ -209,7 +226,18 @@ func (h *MediaFileHandler) HandleDeleteMediaFile(c *fiber.Ctx) error {
 		userID = uuid.New()
 	}
 
-	err := h.Service.DeleteMediaFile(idStr, userID)
+	// An invalid ID is reported by the service
+	if mediaFileId, err := uuid.Parse(idStr); err == nil {
+		if stop, err := checkDeleteUsage(c, h.UsageService, enums.UsageItemMediaFile, mediaFileId); stop {
+			return err
+		}
+	}
+
+	err := h.Service.DeleteMediaFile(idStr, userID, c.QueryBool("force"))
+	if errors.Is(err, errs.ErrItemInUse) {
+		mediaFileId, _ := uuid.Parse(idStr)
+		return deleteConflictResponse(c, h.UsageService, enums.UsageItemMediaFile, mediaFileId, err)
+	}
 	if err != nil {
 		if errors.Is(err, errors.New("invalid ID format")) {
 			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "Invalid ID format", Message: err.Error()})
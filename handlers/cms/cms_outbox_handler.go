@@ -0,0 +1,141 @@
+package cms
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSOutboxHandler struct {
+	Service services.CMSOutboxServiceInterface
+}
+
+func NewCMSOutboxHandler(service services.CMSOutboxServiceInterface) *CMSOutboxHandler {
+	return &CMSOutboxHandler{Service: service}
+}
+
+func outboxErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidDomainEventType), errors.Is(err, errs.ErrInvalidOutboxDeliveryStatus):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, errs.ErrOutboxDeliverySucceeded):
+		status = fiber.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = fiber.StatusNotFound
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// HandleGetOutboxDeliveries handles GET requests to list the deliveries of domain events to their subscribers, newest first
+// @Summary      List Outbox Deliveries
+// @Description  Every change to contents, form submissions and media files is recorded as a domain event in the same transaction,
+// @Description  then handled by each subscriber (emails, app cache, usage index, webhooks) with retries. Dead deliveries ran out of attempts.
+// @Tags         CMS - Outbox
+// @Produce      json
+// @Param        subscriber  query  string  false  "Filter by subscriber"
+// @Param        eventType   query  string  false  "Filter by event type"  Enums(content.saved, content.deleted, form_submission.created, media_file.uploaded, media_file.deleted, email.requested)
+// @Param        status      query  string  false  "Filter by status"  Enums(pending, succeeded, dead)
+// @Param        page        query  int     false  "Page number for pagination (default is 1)"
+// @Param        limit       query  int     false  "Number of items per page (default is 10)"
+// @Success      200  {object}  dto.OutboxDeliveriesSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/outbox/deliveries [get]
+func (h *CMSOutboxHandler) HandleGetOutboxDeliveries(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	query := dto.OutboxDeliveryQuery{
+		Subscriber: c.Query("subscriber"),
+		EventType:  enums.DomainEventType(c.Query("eventType")),
+		Status:     enums.OutboxDeliveryStatus(c.Query("status")),
+	}
+
+	deliveries, totalCount, err := h.Service.FindDeliveries(query, page, limit)
+	if err != nil {
+		return outboxErrorResponse(c, "failed to get outbox deliveries", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "successfully get outbox deliveries",
+		"totalCount": totalCount,
+		"page":       page,
+		"limit":      limit,
+		"items":      deliveries,
+	})
+}
+
+// HandleGetOutboxDelivery handles GET requests to retrieve a delivery with its event
+// @Summary      Get Outbox Delivery
+// @Tags         CMS - Outbox
+// @Produce      json
+// @Param        deliveryId  path  string  true  "Delivery ID (UUID)"
+// @Success      200  {object}  dto.OutboxDeliverySuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/outbox/deliveries/{deliveryId} [get]
+func (h *CMSOutboxHandler) HandleGetOutboxDelivery(c *fiber.Ctx) error {
+	deliveryId, err := uuid.Parse(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the deliveryId",
+			"error":   err.Error(),
+		})
+	}
+
+	delivery, err := h.Service.FindDeliveryByID(deliveryId)
+	if err != nil {
+		return outboxErrorResponse(c, "failed to get outbox delivery", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get outbox delivery",
+		"item":    delivery,
+	})
+}
+
+// HandleRetryOutboxDelivery handles POST requests to handle a delivery again
+// @Summary      Retry Outbox Delivery
+// @Description  Queues a dead or pending delivery for the next dispatch round with a fresh set of attempts.
+// @Tags         CMS - Outbox
+// @Produce      json
+// @Param        deliveryId  path  string  true  "Delivery ID (UUID)"
+// @Success      202  {object}  dto.OutboxDeliveryRetrySuccessResponse202
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      409  {object}  dto.ErrorResponse "Delivery already succeeded"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/outbox/deliveries/{deliveryId}/retry [post]
+func (h *CMSOutboxHandler) HandleRetryOutboxDelivery(c *fiber.Ctx) error {
+	deliveryId, err := uuid.Parse(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the deliveryId",
+			"error":   err.Error(),
+		})
+	}
+
+	delivery, err := h.Service.RetryDelivery(deliveryId)
+	if err != nil {
+		return outboxErrorResponse(c, "failed to retry outbox delivery", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "outbox delivery queued",
+		"item":    delivery,
+	})
+}
@@ -0,0 +1,17 @@
+package cms
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// pageErrorStatus is the status of an error of the page services, 403 when the page is outside the page grants of the user
+func pageErrorStatus(err error, status int) int {
+	if errors.Is(err, errs.ErrPageAccessDenied) {
+		return fiber.StatusForbidden
+	}
+	return status
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"strconv"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,11 +16,18 @@ import (
 )
 
 type CMSPartnerPageHandler struct {
-	Service services.CMSPartnerPageServiceInterface
+	Service            services.CMSPartnerPageServiceInterface
+	PreviewLinkService services.CMSPreviewLinkServiceInterface
+	UsageService       services.CMSUsageServiceInterface
 }
 
-func NewCMSPartnerPageHandler(service services.CMSPartnerPageServiceInterface) *CMSPartnerPageHandler {
-	return &CMSPartnerPageHandler{Service: service}
+func NewCMSPartnerPageHandler(service services.CMSPartnerPageServiceInterface, previewLinkService services.CMSPreviewLinkServiceInterface, usageService services.CMSUsageServiceInterface) *CMSPartnerPageHandler {
+	return &CMSPartnerPageHandler{Service: service, PreviewLinkService: previewLinkService, UsageService: usageService}
+}
+
+// service limits the page service to the page access of the user of the request
+func (h *CMSPartnerPageHandler) service(c *fiber.Ctx) services.CMSPartnerPageServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
 }
 
 // HandleCreatePartnerPage handles POST requests to create a new Partner page
@@ -30,6 +39,8 @@ func NewCMSPartnerPageHandler(service services.CMSPartnerPageServiceInterface) *
 // @Param        Partner_page_data  body  dto.CreatePartnerPageRequest  true  "Partner Page payload (optional: categories, components)"
 // @Success      200  {object} dto.CMSPartnerPageSuccessResponse200
 // @Failure 		 400  {object} dto.ErrorResponse400
+// @Failure 		 403  {object} dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      422  {object} dto.ErrorResponse "Critical audit findings block publishing"
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /cms/partnerpages [post]
 func (h *CMSPartnerPageHandler) HandleCreatePartnerPage(c *fiber.Ctx) error {
@@ -43,9 +54,15 @@ func (h *CMSPartnerPageHandler) HandleCreatePartnerPage(c *fiber.Ctx) error {
 
 	helpers.SanitizePartnerPage(&partnerPage)
 
-	createdPartnerPage, err := h.Service.CreatePartnerPage(&partnerPage)
+	createdPartnerPage, err := h.service(c).CreatePartnerPage(&partnerPage)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, errs.ErrCriticalAuditFindings) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "content cannot be published until critical audit findings are fixed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "Failed to create Partner page",
 			"error":   err.Error(),
 		})
@@ -67,6 +84,8 @@ func (h *CMSPartnerPageHandler) HandleCreatePartnerPage(c *fiber.Ctx) error {
 // @Param        page     query  int     false  "Page number for pagination (default is 1)"
 // @Param        limit    query  int     false  "Number of items per page (default is 10)"
 // @Param        language  query  string  false  "Language code for localized content (e.g., en, th)"
+// @Param        cursor     query  string  false  "Switches to cursor pagination, empty for the first page, then the next or prev cursor of the previous response. Sorts on created_at or updated_at only"
+// @Param        withTotal  query  bool    false  "Also return totalCount in cursor pagination"
 // @Success      200  {object} dto.CMSPartnerPageSuccessResponse200
 // @Failure 		 400  {object} dto.ErrorResponse400
 // @Failure      500  {object} dto.ErrorResponse500
@@ -78,7 +97,15 @@ func (h *CMSPartnerPageHandler) HandleGetPartnerPages(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	language := c.Query("language", "")
 
-	results, totalCount, err := h.Service.FindPartnerPages(rawQuery, sort, page, limit, language)
+	if cursorQuery, ok := cursorQueryFromRequest(c); ok {
+		results, cursorPage, err := h.service(c).FindPartnerPagesByCursor(rawQuery, sort, cursorQuery, language)
+		if err != nil {
+			return cursorErrorResponse(c, "failed to find Partner pages", err)
+		}
+		return cursorPageResponse(c, "successfully get all Partner pages", results, cursorPage)
+	}
+
+	results, totalCount, err := h.service(c).FindPartnerPages(rawQuery, sort, page, limit, language)
 	if err != nil {
 		if errors.Is(err, errs.ErrInvalidQuery) {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -109,6 +136,7 @@ func (h *CMSPartnerPageHandler) HandleGetPartnerPages(c *fiber.Ctx) error {
 // @Param        pageid  path  string  true  "Partner Page ID"
 // @Success      200  {object} dto.CMSPartnerPageSuccessResponse200
 // @Failure      400  {object} dto.ErrorResponse400
+// @Failure      403  {object} dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object} dto.ErrorResponse500
 // @Router       /cms/partnerpages/{pageid} [get]
 func (h *CMSPartnerPageHandler) HandleGetPartnerPageById(c *fiber.Ctx) error {
@@ -121,9 +149,9 @@ func (h *CMSPartnerPageHandler) HandleGetPartnerPageById(c *fiber.Ctx) error {
 		})
 	}
 
-	partnerPage, err := h.Service.FindPartnerPageById(id)
+	partnerPage, err := h.service(c).FindPartnerPageById(id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to find Partner page",
 			"error":   err.Error(),
 		})
@@ -140,9 +168,12 @@ func (h *CMSPartnerPageHandler) HandleGetPartnerPageById(c *fiber.Ctx) error {
 // @Description  Delete an existing Partner page using its unique identifier.
 // @Tags         CMS - Partner Pages
 // @Produce      json
-// @Param        pageId  path  string  true  "Partner Page ID"
+// @Param        pageId  path   string  true   "Partner Page ID"
+// @Param        force   query  bool    false  "Delete even when published content still refers to the page"
 // @Success      200  {object}  dto.CMSSuccessResponse
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      409  {object}  dto.UsageConflictResponse409
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/{pageId} [delete]
 func (h *CMSPartnerPageHandler) HandleDeletePartnerPage(c *fiber.Ctx) error {
@@ -155,9 +186,16 @@ func (h *CMSPartnerPageHandler) HandleDeletePartnerPage(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.Service.DeletePartnerPage(id)
+	if stop, err := checkDeleteUsage(c, h.UsageService, enums.UsageItemPartnerPage, id); stop {
+		return err
+	}
+
+	err = h.service(c).DeletePartnerPage(id, c.QueryBool("force"))
+	if errors.Is(err, errs.ErrItemInUse) {
+		return deleteConflictResponse(c, h.UsageService, enums.UsageItemPartnerPage, id, err)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to delete Partner page",
 			"error":   err.Error(),
 		})
@@ -178,6 +216,7 @@ func (h *CMSPartnerPageHandler) HandleDeletePartnerPage(c *fiber.Ctx) error {
 // @Param        mode          query     string  false   "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSPartnerContentSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/{pageId}/contents/{languageCode} [get]
 func (h *CMSPartnerPageHandler) HandleGetContentByPartnerPageId(c *fiber.Ctx) error {
@@ -192,9 +231,9 @@ func (h *CMSPartnerPageHandler) HandleGetContentByPartnerPageId(c *fiber.Ctx) er
 		})
 	}
 
-	PartnerContent, err := h.Service.FindContentByPartnerPageId(id, language, mode)
+	PartnerContent, err := h.service(c).FindContentByPartnerPageId(id, language, mode)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "internal server error",
 			"error":   err.Error(),
 		})
@@ -215,6 +254,7 @@ func (h *CMSPartnerPageHandler) HandleGetContentByPartnerPageId(c *fiber.Ctx) er
 // @Param        languageCode  path      string  true   "Language Code (e.g., en, th)"
 // @Success      200  {object}  dto.CMSPartnerContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/{pageId}/latestcontent/{languageCode} [get]
 func (h *CMSPartnerPageHandler) HandleGetLatestContentByPartnerPageId(c *fiber.Ctx) error {
@@ -228,9 +268,9 @@ func (h *CMSPartnerPageHandler) HandleGetLatestContentByPartnerPageId(c *fiber.C
 		})
 	}
 
-	PartnerContent, err := h.Service.FindLatestContentByPageId(pageId, language)
+	PartnerContent, err := h.service(c).FindLatestContentByPageId(pageId, language)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get the latest content",
 			"error":   err.Error(),
 		})
@@ -252,6 +292,7 @@ func (h *CMSPartnerPageHandler) HandleGetLatestContentByPartnerPageId(c *fiber.C
 // @Param        mode          query     string  false  "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSSuccessResponse
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/{pageId}/contents/{languageCode} [delete]
 func (h *CMSPartnerPageHandler) HandleDeletePartnerContentByPageId(c *fiber.Ctx) error {
@@ -267,8 +308,8 @@ func (h *CMSPartnerPageHandler) HandleDeletePartnerContentByPageId(c *fiber.Ctx)
 		})
 	}
 
-	if err := h.Service.DeleteContentByPartnerPageId(pageId, language, mode); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	if err := h.service(c).DeleteContentByPartnerPageId(pageId, language, mode); err != nil {
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to Delete Partner content",
 			"error":   err.Error(),
 		})
@@ -287,6 +328,7 @@ func (h *CMSPartnerPageHandler) HandleDeletePartnerContentByPageId(c *fiber.Ctx)
 // @Param        pageId        path      string  true  "Partner Page ID (UUID)"
 // @Success      200           {object}  dto.CMSSuccessResponse
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/duplicate/{pageId} [post]
 func (h *CMSPartnerPageHandler) HandleDuplicatePartnerPage(c *fiber.Ctx) error {
@@ -299,9 +341,9 @@ func (h *CMSPartnerPageHandler) HandleDuplicatePartnerPage(c *fiber.Ctx) error {
 		})
 	}
 
-	partnerPage, err := h.Service.DuplicatePartnerPage(pageId)
+	partnerPage, err := h.service(c).DuplicatePartnerPage(pageId)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to duplicate page",
 			"error":   err.Error(),
 		})
@@ -323,6 +365,7 @@ func (h *CMSPartnerPageHandler) HandleDuplicatePartnerPage(c *fiber.Ctx) error {
 // @Param        revision  body  dto.CreateRevisionRequest  true  "Revision payload"
 // @Success      200           {object}  dto.CMSPartnerContentSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/duplicate/{contentId}/contents [post]
 func (h *CMSPartnerPageHandler) HandleDuplicatePartnerContentToAnotherLanguage(c *fiber.Ctx) error {
@@ -345,9 +388,9 @@ func (h *CMSPartnerPageHandler) HandleDuplicatePartnerContentToAnotherLanguage(c
 
 	helpers.SanitizeRevision(&revision)
 
-	partnerContent, err := h.Service.DuplicatePartnerContentToAnotherLanguage(contentId, &revision)
+	partnerContent, err := h.service(c).DuplicatePartnerContentToAnotherLanguage(contentId, &revision)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to duplicate content to another language",
 			"error":   err.Error(),
 		})
@@ -369,6 +412,7 @@ func (h *CMSPartnerPageHandler) HandleDuplicatePartnerContentToAnotherLanguage(c
 // @Param        revision    body  dto.CreateRevisionRequest  true  "Revision payload"
 // @Success      200  {object}  dto.CMSPartnerContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/{revisionId}/revisions [post]
 func (h *CMSPartnerPageHandler) HandleRevertPartnerContent(c *fiber.Ctx) error {
@@ -391,9 +435,9 @@ func (h *CMSPartnerPageHandler) HandleRevertPartnerContent(c *fiber.Ctx) error {
 
 	helpers.SanitizeRevision(&revision)
 
-	PartnerContent, err := h.Service.RevertPartnerContent(revisionId, &revision)
+	PartnerContent, err := h.service(c).RevertPartnerContent(revisionId, &revision)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to revert Partner content",
 			"error":   err.Error(),
 		})
@@ -417,6 +461,8 @@ func (h *CMSPartnerPageHandler) HandleRevertPartnerContent(c *fiber.Ctx) error {
 // @Param        partnerContent     body  dto.CreatePartnerContentRequest  true  "Updated Partner Content"
 // @Success      200  {object}  dto.CMSPartnerContentSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      422  {object}  dto.ErrorResponse "Critical audit findings block publishing"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/{contentId}/contents [put]
 func (h *CMSPartnerPageHandler) HandleUpdatePartnerContent(c *fiber.Ctx) error {
@@ -439,9 +485,15 @@ func (h *CMSPartnerPageHandler) HandleUpdatePartnerContent(c *fiber.Ctx) error {
 
 	helpers.SanitizePartnerContent(&updatedContent)
 
-	PartnerContent, err := h.Service.UpdatePartnerContent(&updatedContent, contentId)
+	PartnerContent, err := h.service(c).UpdatePartnerContent(&updatedContent, contentId)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, errs.ErrCriticalAuditFindings) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "content cannot be published until critical audit findings are fixed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to update Partner content",
 			"error":   err.Error(),
 		})
@@ -464,6 +516,7 @@ func (h *CMSPartnerPageHandler) HandleUpdatePartnerContent(c *fiber.Ctx) error {
 // @Param        mode              query     string  false "Mode (e.g., draft, published, histories, preview). Defaults to 'published'."
 // @Success      200           {object}  dto.CMSPartnerCategoriesSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/category/{categoryTypeCode}/{pageId}/{languageCode} [get]
 func (h *CMSPartnerPageHandler) HandleGetCategory(c *fiber.Ctx) error {
@@ -478,9 +531,9 @@ func (h *CMSPartnerPageHandler) HandleGetCategory(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
-	categories, err := h.Service.GetCategory(pageId, categoryTypeCode, language, mode)
+	categories, err := h.service(c).GetCategory(pageId, categoryTypeCode, language, mode)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get categories",
 			"error":   err.Error(),
 		})
@@ -501,6 +554,7 @@ func (h *CMSPartnerPageHandler) HandleGetCategory(c *fiber.Ctx) error {
 // @Param        languageCode      path      string  true  "Language Code (e.g., en, th)"
 // @Success      200           {object}  dto.CMSPartnerRevsionsSuccessResponse200
 // @Failure      400           {object}  dto.ErrorResponse400
+// @Failure      403           {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500           {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/revisions/{languageCode}/{pageId} [get]
 func (h *CMSPartnerPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
@@ -514,9 +568,9 @@ func (h *CMSPartnerPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
 		})
 	}
 
-	revisions, err := h.Service.FindRevisions(pageId, language)
+	revisions, err := h.service(c).FindRevisions(pageId, language)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to get revisions",
 			"error":   err.Error(),
 		})
@@ -530,15 +584,19 @@ func (h *CMSPartnerPageHandler) HandleGetRevisions(c *fiber.Ctx) error {
 
 // HandlePreviewPartnerContent handles POST request to preview Partner content.
 // @Summary      Preview Partner Content
-// @Description  Preview an existing Partner content by its content ID.
+// @Description  Save the preview copy of a Partner content and issue a signed preview link for it.
 // @Description  This operation does not merge with previous content — it overwrites everything.
+// @Description  The link expires after the ttl and can be revoked from /cms/previews.
 // @Tags         CMS - Partner Pages
 // @Accept       json
 // @Produce      json
 // @Param        pageId      path  string           true  "Partner Page ID (UUID)"
+// @Param        ttl         query  string  false  "Link lifetime such as 30m or 48h (default from PREVIEW_DEFAULT_TTL)"
+// @Param        X-Preview-Password  header  string  false  "Optional password required to open the link"
 // @Param        partnerContent     body  dto.CreatePartnerContentPreviewRequest  true  "Preview Partner Content"
-// @Success      200  {object}  dto.CMSPartnerContentSuccessResponse200
+// @Success      200  {object}  dto.CMSPreviewLinkSuccessResponse200
 // @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
 // @Failure      500  {object}  dto.ErrorResponse500
 // @Router       /cms/partnerpages/previews/{pageId} [post]
 func (h *CMSPartnerPageHandler) HandlePreviewPartnerContent(c *fiber.Ctx) error {
@@ -551,26 +609,50 @@ func (h *CMSPartnerPageHandler) HandlePreviewPartnerContent(c *fiber.Ctx) error
 		})
 	}
 
+	ttl, err := h.PreviewLinkService.ParsePreviewTTL(c.Query("ttl"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid preview ttl",
+			"error":   err.Error(),
+		})
+	}
+
 	var partnerContentPreview models.PartnerContent
 	if err := c.BodyParser(&partnerContentPreview); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "Invalid query JSON",
 			"error":   err.Error(),
 		})
-	}	
+	}
 
 	helpers.SanitizePartnerContent(&partnerContentPreview)
 
-	url, err := h.Service.PreviewPartnerContent(pageId, &partnerContentPreview)
+	savedContent, err := h.service(c).PreviewPartnerContent(pageId, &partnerContentPreview)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"message": "failed to preview content",
 			"error":   err.Error(),
 		})
-	}	
+	}
+
+	previewLink, err := h.PreviewLinkService.IssuePreviewLink(dto.IssuePreviewLinkRequest{
+		PageType:  enums.PageTypePartner,
+		PageID:    pageId,
+		ContentID: savedContent.ID,
+		Language:  savedContent.Language,
+		TTL:       ttl,
+		Password:  c.Get("X-Preview-Password"),
+	})
+	if err != nil {
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"message": "failed to issue preview link",
+			"error":   err.Error(),
+		})
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "successfully preview partner content",
-		"url":    url,
+		"url":     previewLink.URL,
+		"item":    previewLink,
 	})
 }
\ No newline at end of file
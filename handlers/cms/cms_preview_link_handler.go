@@ -0,0 +1,115 @@
+package cms
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSPreviewLinkHandler struct {
+	Service services.CMSPreviewLinkServiceInterface
+}
+
+func NewCMSPreviewLinkHandler(service services.CMSPreviewLinkServiceInterface) *CMSPreviewLinkHandler {
+	return &CMSPreviewLinkHandler{Service: service}
+}
+
+// service limits the preview link service to the page access of the user of the request
+func (h *CMSPreviewLinkHandler) service(c *fiber.Ctx) services.CMSPreviewLinkServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
+}
+
+// HandleGetPreviewLinks handles GET requests to list the issued preview links
+// @Summary      List Preview Links
+// @Description  List the preview links issued for landing, partner and faq contents. Only active links are listed unless includeInvalid is set.
+// @Tags         CMS - Previews
+// @Produce      json
+// @Param        pageId          query  string  false  "Filter by page ID (UUID)"
+// @Param        pageType        query  string  false  "Filter by page type"  Enums(landing, partner, faq)
+// @Param        includeInvalid  query  bool    false  "Also list expired and revoked links"
+// @Param        page            query  int     false  "Page number for pagination (default is 1)"
+// @Param        limit           query  int     false  "Number of items per page (default is 10)"
+// @Success      200  {object}  dto.CMSPreviewLinksSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/previews [get]
+func (h *CMSPreviewLinkHandler) HandleGetPreviewLinks(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	query := dto.PreviewLinkQuery{
+		PageID:   c.Query("pageId"),
+		PageType: enums.PageType(c.Query("pageType")),
+	}
+	query.IncludeInvalid, _ = strconv.ParseBool(c.Query("includeInvalid"))
+
+	previewLinks, totalCount, err := h.service(c).FindPreviewLinks(query, page, limit)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidUUIDFormat) || errors.Is(err, errs.ErrInvalidPageType) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid preview link filter",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to find preview links",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "successfully get preview links",
+		"totalCount": totalCount,
+		"page":       page,
+		"limit":      limit,
+		"items":      previewLinks,
+	})
+}
+
+// HandleRevokePreviewLink handles DELETE requests to revoke a preview link
+// @Summary      Revoke Preview Link
+// @Description  Revoke a preview link so its url stops working before it expires.
+// @Tags         CMS - Previews
+// @Produce      json
+// @Param        id  path  string  true  "Preview Link ID (UUID)"
+// @Success      200  {object}  dto.SuccessResponse
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/previews/{id} [delete]
+func (h *CMSPreviewLinkHandler) HandleRevokePreviewLink(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the id",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := h.service(c).RevokePreviewLink(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "preview link not found",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(pageErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"message": "failed to revoke preview link",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully revoke preview link",
+	})
+}
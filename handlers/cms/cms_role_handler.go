@@ -0,0 +1,217 @@
+package cms
+
+import (
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSRoleHandler struct {
+	Service services.CMSRoleServiceInterface
+}
+
+func NewCMSRoleHandler(service services.CMSRoleServiceInterface) *CMSRoleHandler {
+	return &CMSRoleHandler{Service: service}
+}
+
+func roleErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidRole), errors.Is(err, errs.ErrInvalidPageGrant):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = fiber.StatusNotFound
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// HandleGetRoles handles GET requests to list the roles with their permissions
+// @Summary      List Roles
+// @Description  A permission allows one action (read, create, update, delete, publish, send) on one resource, "*" matches any.
+// @Description  Roles are stored in the database, a user has at most one and a user without a role is refused every CMS route.
+// @Tags         CMS - Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  dto.RolesSuccessResponse200
+// @Failure      401  {object}  dto.ErrorResponse "Missing or invalid token"
+// @Failure      403  {object}  dto.ErrorResponse "Role does not allow reading users"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/roles [get]
+func (h *CMSRoleHandler) HandleGetRoles(c *fiber.Ctx) error {
+	roles, err := h.Service.FindRoles()
+	if err != nil {
+		return roleErrorResponse(c, "failed to get roles", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get roles",
+		"items":   roles,
+	})
+}
+
+// HandleAssignUserRole handles PUT requests to give a user a role
+// @Summary      Assign User Role
+// @Description  The new role is in the tokens issued from the next login, LINE logins use it right away.
+// @Tags         CMS - Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId   path  string                     true  "User ID (UUID)"
+// @Param        request  body  dto.AssignUserRoleRequest  true  "Role"
+// @Success      200  {object}  dto.UserRoleSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      401  {object}  dto.ErrorResponse "Missing or invalid token"
+// @Failure      403  {object}  dto.ErrorResponse "Role does not allow updating users"
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/users/{userId}/role [put]
+func (h *CMSRoleHandler) HandleAssignUserRole(c *fiber.Ctx) error {
+	userId, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the userId",
+			"error":   err.Error(),
+		})
+	}
+
+	var request dto.AssignUserRoleRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the body",
+			"error":   err.Error(),
+		})
+	}
+
+	user, err := h.Service.AssignUserRole(userId, request.Role)
+	if err != nil {
+		return roleErrorResponse(c, "failed to assign role", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully assign role",
+		"item":    user,
+	})
+}
+
+// HandleGetPageGrants handles GET requests to list the page grants of a user
+// @Summary      List User Page Grants
+// @Description  A user without page grants reaches every page their role allows. With grants, they only reach the pages
+// @Description  of a grant's page type, in its language and category when it has them, and only for the grant's action.
+// @Tags         CMS - Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId  path  string  true  "User ID (UUID)"
+// @Success      200  {object}  dto.PageGrantsSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/users/{userId}/page-grants [get]
+func (h *CMSRoleHandler) HandleGetPageGrants(c *fiber.Ctx) error {
+	userId, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the userId",
+			"error":   err.Error(),
+		})
+	}
+
+	grants, err := h.Service.FindPageGrants(userId)
+	if err != nil {
+		return roleErrorResponse(c, "failed to get page grants", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get page grants",
+		"items":   grants,
+	})
+}
+
+// HandleCreatePageGrant handles POST requests to give a user a page grant
+// @Summary      Create User Page Grant
+// @Description  The first grant of a user limits them to the pages their grants match, from their next request.
+// @Tags         CMS - Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId   path  string                      true  "User ID (UUID)"
+// @Param        request  body  dto.CreatePageGrantRequest  true  "Page grant"
+// @Success      201  {object}  dto.PageGrantSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/users/{userId}/page-grants [post]
+func (h *CMSRoleHandler) HandleCreatePageGrant(c *fiber.Ctx) error {
+	userId, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the userId",
+			"error":   err.Error(),
+		})
+	}
+
+	var request dto.CreatePageGrantRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the body",
+			"error":   err.Error(),
+		})
+	}
+
+	grant, err := h.Service.CreatePageGrant(userId, request)
+	if err != nil {
+		return roleErrorResponse(c, "failed to create page grant", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "successfully create page grant",
+		"item":    grant,
+	})
+}
+
+// HandleDeletePageGrant handles DELETE requests to take a page grant away from a user
+// @Summary      Delete User Page Grant
+// @Description  Deleting the last grant of a user lets them reach every page their role allows again.
+// @Tags         CMS - Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId   path  string  true  "User ID (UUID)"
+// @Param        grantId  path  string  true  "Page grant ID (UUID)"
+// @Success      200  {object}  dto.CMSSuccessResponse
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/users/{userId}/page-grants/{grantId} [delete]
+func (h *CMSRoleHandler) HandleDeletePageGrant(c *fiber.Ctx) error {
+	userId, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the userId",
+			"error":   err.Error(),
+		})
+	}
+	grantId, err := uuid.Parse(c.Params("grantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the grantId",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := h.Service.DeletePageGrant(userId, grantId); err != nil {
+		return roleErrorResponse(c, "failed to delete page grant", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully delete page grant",
+	})
+}
@@ -0,0 +1,158 @@
+package cms
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CMSUsageHandler struct {
+	Service services.CMSUsageServiceInterface
+}
+
+func NewCMSUsageHandler(service services.CMSUsageServiceInterface) *CMSUsageHandler {
+	return &CMSUsageHandler{Service: service}
+}
+
+// service limits the usage service to the page access of the user of the request
+func (h *CMSUsageHandler) service(c *fiber.Ctx) services.CMSUsageServiceInterface {
+	return h.Service.WithPageAccess(helpers.GetPageAccessFromContext(c))
+}
+
+func usageErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidUsageItemType):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, errs.ErrPageAccessDenied):
+		status = fiber.StatusForbidden
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// checkDeleteUsage runs the pre-delete impact check shared by the delete handlers.
+// It returns true when the handler must stop, the response has then already been written.
+func checkDeleteUsage(c *fiber.Ctx, service services.CMSUsageServiceInterface, itemType enums.UsageItemType, itemId uuid.UUID) (bool, error) {
+	impact, err := service.CheckDelete(itemType, itemId, c.QueryBool("force"))
+	if err == nil {
+		return false, nil
+	}
+	if errors.Is(err, errs.ErrItemInUse) {
+		return true, usageConflictResponse(c, impact, err)
+	}
+	return true, usageErrorResponse(c, "failed to check the delete impact", err)
+}
+
+// deleteConflictResponse answers a delete whose transaction found published references the pre-delete check had not seen yet
+func deleteConflictResponse(c *fiber.Ctx, service services.CMSUsageServiceInterface, itemType enums.UsageItemType, itemId uuid.UUID, err error) error {
+	impact, impactErr := service.GetDeleteImpact(itemType, itemId)
+	if impactErr != nil {
+		impact = nil
+	}
+	return usageConflictResponse(c, impact, err)
+}
+
+func usageConflictResponse(c *fiber.Ctx, impact *dto.UsageImpact, err error) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"message": "item is still in use, pass force=true to delete it anyway",
+		"error":   err.Error(),
+		"data":    impact,
+	})
+}
+
+// HandleGetUsages handles GET requests to list where an item is used
+// @Summary      List Item Usages
+// @Description  Where used lookup of a category, media file or page across contents, components, relations, categories, email templates and forms.
+// @Description  Read from the usage index, which is rebuilt first when older than USAGE_INDEX_MAX_AGE.
+// @Tags         CMS - Usages
+// @Produce      json
+// @Param        itemType  path  string  true  "Item type"  Enums(category, media_file, landing_page, partner_page, faq_page)
+// @Param        itemId    path  string  true  "Item ID (UUID)"
+// @Success      200  {object}  dto.CMSUsagesSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/usages/{itemType}/{itemId} [get]
+func (h *CMSUsageHandler) HandleGetUsages(c *fiber.Ctx) error {
+	itemId, err := uuid.Parse(c.Params("itemId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the itemId",
+			"error":   err.Error(),
+		})
+	}
+
+	usages, err := h.service(c).GetUsages(enums.UsageItemType(c.Params("itemType")), itemId)
+	if err != nil {
+		return usageErrorResponse(c, "failed to get usages", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get usages",
+		"items":   usages,
+	})
+}
+
+// HandleGetDeleteImpact handles GET requests to report what a delete of an item would break
+// @Summary      Get Delete Impact
+// @Description  Deletes of an item referenced by published content are refused unless forced with ?force=true, this report tells beforehand.
+// @Tags         CMS - Usages
+// @Produce      json
+// @Param        itemType  path  string  true  "Item type"  Enums(category, media_file, landing_page, partner_page, faq_page)
+// @Param        itemId    path  string  true  "Item ID (UUID)"
+// @Success      200  {object}  dto.CMSUsageImpactSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      403  {object}  dto.ErrorResponse "Page is outside the page grants of the user"
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/usages/{itemType}/{itemId}/impact [get]
+func (h *CMSUsageHandler) HandleGetDeleteImpact(c *fiber.Ctx) error {
+	itemId, err := uuid.Parse(c.Params("itemId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the itemId",
+			"error":   err.Error(),
+		})
+	}
+
+	impact, err := h.service(c).GetDeleteImpact(enums.UsageItemType(c.Params("itemType")), itemId)
+	if err != nil {
+		return usageErrorResponse(c, "failed to get delete impact", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get delete impact",
+		"data":    impact,
+	})
+}
+
+// HandleRebuildUsageIndex handles POST requests to rebuild the usage index now
+// @Summary      Rebuild Usage Index
+// @Tags         CMS - Usages
+// @Produce      json
+// @Success      200  {object}  dto.CMSUsageIndexSuccessResponse200
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/usages/rebuild [post]
+func (h *CMSUsageHandler) HandleRebuildUsageIndex(c *fiber.Ctx) error {
+	// Not tied to the request, a client hanging up must not leave the index half scanned
+	summary, err := h.Service.RebuildUsageIndex(context.Background())
+	if err != nil {
+		return usageErrorResponse(c, "failed to rebuild usage index", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully rebuild usage index",
+		"data":    summary,
+	})
+}
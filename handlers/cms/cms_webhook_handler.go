@@ -0,0 +1,319 @@
+package cms
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSWebhookHandler struct {
+	Service services.CMSWebhookServiceInterface
+}
+
+func NewCMSWebhookHandler(service services.CMSWebhookServiceInterface) *CMSWebhookHandler {
+	return &CMSWebhookHandler{Service: service}
+}
+
+func webhookErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.ErrInvalidWebhookEndpoint), errors.Is(err, errs.ErrInvalidWebhookEventType),
+		errors.Is(err, errs.ErrInvalidWebhookDeliveryStatus), errors.Is(err, errs.ErrInvalidUUIDFormat):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = fiber.StatusNotFound
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// HandleCreateWebhook handles POST requests to register a webhook endpoint
+// @Summary      Create Webhook
+// @Description  Registers an endpoint for the event types it lists. Events are posted as JSON with the headers X-Webhook-Id (event id, shared by replays),
+// @Description  X-Webhook-Event, X-Webhook-Timestamp (unix seconds) and X-Webhook-Signature, "sha256=" followed by the hex HMAC-SHA256 of "{timestamp}.{body}" keyed with the secret.
+// @Description  The secret is only returned here and when it is rotated. Failed deliveries are retried with exponential backoff.
+// @Tags         CMS - Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.WebhookEndpointRequest  true  "Webhook"
+// @Success      201  {object}  dto.WebhookEndpointSecretSuccessResponse201
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks [post]
+func (h *CMSWebhookHandler) HandleCreateWebhook(c *fiber.Ctx) error {
+	var request dto.WebhookEndpointRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the body",
+			"error":   err.Error(),
+		})
+	}
+
+	endpoint, err := h.Service.CreateEndpoint(request)
+	if err != nil {
+		return webhookErrorResponse(c, "failed to create webhook", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "successfully create webhook",
+		"item":    endpoint,
+	})
+}
+
+// HandleGetWebhooks handles GET requests to list the webhook endpoints
+// @Summary      List Webhooks
+// @Tags         CMS - Webhooks
+// @Produce      json
+// @Success      200  {object}  dto.WebhookEndpointsSuccessResponse200
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks [get]
+func (h *CMSWebhookHandler) HandleGetWebhooks(c *fiber.Ctx) error {
+	endpoints, err := h.Service.FindEndpoints()
+	if err != nil {
+		return webhookErrorResponse(c, "failed to get webhooks", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get webhooks",
+		"items":   endpoints,
+	})
+}
+
+// HandleGetWebhook handles GET requests to retrieve one webhook endpoint
+// @Summary      Get Webhook
+// @Tags         CMS - Webhooks
+// @Produce      json
+// @Param        webhookId  path  string  true  "Webhook ID (UUID)"
+// @Success      200  {object}  dto.WebhookEndpointSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks/{webhookId} [get]
+func (h *CMSWebhookHandler) HandleGetWebhook(c *fiber.Ctx) error {
+	webhookId, err := uuid.Parse(c.Params("webhookId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the webhookId",
+			"error":   err.Error(),
+		})
+	}
+
+	endpoint, err := h.Service.FindEndpointByID(webhookId)
+	if err != nil {
+		return webhookErrorResponse(c, "failed to get webhook", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get webhook",
+		"item":    endpoint,
+	})
+}
+
+// HandleUpdateWebhook handles PUT requests to replace the settings of a webhook endpoint
+// @Summary      Update Webhook
+// @Description  Replaces the name, url and event types. is_active is kept when left out, a disabled webhook fails its pending deliveries.
+// @Tags         CMS - Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhookId  path  string                      true  "Webhook ID (UUID)"
+// @Param        request    body  dto.WebhookEndpointRequest  true  "Webhook"
+// @Success      200  {object}  dto.WebhookEndpointSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks/{webhookId} [put]
+func (h *CMSWebhookHandler) HandleUpdateWebhook(c *fiber.Ctx) error {
+	webhookId, err := uuid.Parse(c.Params("webhookId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the webhookId",
+			"error":   err.Error(),
+		})
+	}
+
+	var request dto.WebhookEndpointRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the body",
+			"error":   err.Error(),
+		})
+	}
+
+	endpoint, err := h.Service.UpdateEndpoint(webhookId, request)
+	if err != nil {
+		return webhookErrorResponse(c, "failed to update webhook", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully update webhook",
+		"item":    endpoint,
+	})
+}
+
+// HandleRotateWebhookSecret handles POST requests to replace the signing secret of a webhook endpoint
+// @Summary      Rotate Webhook Secret
+// @Description  Deliveries sent from now on, retries included, are signed with the new secret.
+// @Tags         CMS - Webhooks
+// @Produce      json
+// @Param        webhookId  path  string  true  "Webhook ID (UUID)"
+// @Success      200  {object}  dto.WebhookEndpointSecretSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks/{webhookId}/secret [post]
+func (h *CMSWebhookHandler) HandleRotateWebhookSecret(c *fiber.Ctx) error {
+	webhookId, err := uuid.Parse(c.Params("webhookId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the webhookId",
+			"error":   err.Error(),
+		})
+	}
+
+	endpoint, err := h.Service.RotateEndpointSecret(webhookId)
+	if err != nil {
+		return webhookErrorResponse(c, "failed to rotate webhook secret", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully rotate webhook secret",
+		"item":    endpoint,
+	})
+}
+
+// HandleDeleteWebhook handles DELETE requests to remove a webhook endpoint and its deliveries
+// @Summary      Delete Webhook
+// @Tags         CMS - Webhooks
+// @Produce      json
+// @Param        webhookId  path  string  true  "Webhook ID (UUID)"
+// @Success      200  {object}  dto.SuccessResponse
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks/{webhookId} [delete]
+func (h *CMSWebhookHandler) HandleDeleteWebhook(c *fiber.Ctx) error {
+	webhookId, err := uuid.Parse(c.Params("webhookId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the webhookId",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := h.Service.DeleteEndpoint(webhookId); err != nil {
+		return webhookErrorResponse(c, "failed to delete webhook", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully delete webhook",
+	})
+}
+
+// HandleGetWebhookDeliveries handles GET requests to list the delivery log, newest first
+// @Summary      List Webhook Deliveries
+// @Tags         CMS - Webhooks
+// @Produce      json
+// @Param        webhookId  query  string  false  "Filter by webhook ID (UUID)"
+// @Param        eventType  query  string  false  "Filter by event type"  Enums(content.published, content.unpublished, content.deleted, form.submitted, media.uploaded)
+// @Param        status     query  string  false  "Filter by status"  Enums(pending, succeeded, failed)
+// @Param        page       query  int     false  "Page number for pagination (default is 1)"
+// @Param        limit      query  int     false  "Number of items per page (default is 10)"
+// @Success      200  {object}  dto.WebhookDeliveriesSuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks/deliveries [get]
+func (h *CMSWebhookHandler) HandleGetWebhookDeliveries(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	query := dto.WebhookDeliveryQuery{
+		EndpointID: c.Query("webhookId"),
+		EventType:  enums.WebhookEventType(c.Query("eventType")),
+		Status:     enums.WebhookDeliveryStatus(c.Query("status")),
+	}
+
+	deliveries, totalCount, err := h.Service.FindDeliveries(query, page, limit)
+	if err != nil {
+		return webhookErrorResponse(c, "failed to get webhook deliveries", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "successfully get webhook deliveries",
+		"totalCount": totalCount,
+		"page":       page,
+		"limit":      limit,
+		"items":      deliveries,
+	})
+}
+
+// HandleGetWebhookDelivery handles GET requests to retrieve a delivery with the log of its attempts
+// @Summary      Get Webhook Delivery
+// @Tags         CMS - Webhooks
+// @Produce      json
+// @Param        deliveryId  path  string  true  "Delivery ID (UUID)"
+// @Success      200  {object}  dto.WebhookDeliverySuccessResponse200
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks/deliveries/{deliveryId} [get]
+func (h *CMSWebhookHandler) HandleGetWebhookDelivery(c *fiber.Ctx) error {
+	deliveryId, err := uuid.Parse(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the deliveryId",
+			"error":   err.Error(),
+		})
+	}
+
+	delivery, err := h.Service.FindDeliveryByID(deliveryId)
+	if err != nil {
+		return webhookErrorResponse(c, "failed to get webhook delivery", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "successfully get webhook delivery",
+		"item":    delivery,
+	})
+}
+
+// HandleReplayWebhookDelivery handles POST requests to send a delivery again
+// @Summary      Replay Webhook Delivery
+// @Description  Queues the same payload again as a new delivery with a fresh set of attempts, whatever the status of the original one.
+// @Tags         CMS - Webhooks
+// @Produce      json
+// @Param        deliveryId  path  string  true  "Delivery ID (UUID)"
+// @Success      202  {object}  dto.WebhookDeliveryReplaySuccessResponse202
+// @Failure      400  {object}  dto.ErrorResponse400
+// @Failure      404  {object}  dto.ErrorResponse404
+// @Failure      500  {object}  dto.ErrorResponse500
+// @Router       /cms/webhooks/deliveries/{deliveryId}/replay [post]
+func (h *CMSWebhookHandler) HandleReplayWebhookDelivery(c *fiber.Ctx) error {
+	deliveryId, err := uuid.Parse(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse the deliveryId",
+			"error":   err.Error(),
+		})
+	}
+
+	delivery, err := h.Service.ReplayDelivery(deliveryId)
+	if err != nil {
+		return webhookErrorResponse(c, "failed to replay webhook delivery", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "webhook delivery queued",
+		"item":    delivery,
+	})
+}
@@ -0,0 +1,358 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"golang.org/x/net/html"
+	"gorm.io/datatypes"
+)
+
+const (
+	maxMetaTitleLength       = 60
+	maxMetaDescriptionLength = 160
+	minContentLength         = 300 // characters of visible text
+)
+
+var (
+	// Lowercase words (thai allowed) separated by single hyphens or slashes
+	urlAliasRegex = regexp.MustCompile(`^/?[a-z0-9ก-๙]+(?:[-/][a-z0-9ก-๙]+)*$`)
+
+	imagePropKeys = []string{"image", "img", "src", "image_url", "imageUrl", "thumbnail"}
+	altPropKeys   = []string{"alt", "alt_text", "altText", "image_alt", "imageAlt"}
+)
+
+// auditTarget is the common shape of landing, partner and faq contents for auditing
+type auditTarget struct {
+	metaTag       *models.MetaTag
+	htmlInputs    [][2]string // field name, html
+	components    []*models.Component
+	urlAlias      string
+	aliasRequired bool
+}
+
+func AuditLandingContent(content *models.LandingContent) []dto.ContentAuditFinding {
+	return auditContent(auditTarget{
+		metaTag:       content.MetaTag,
+		htmlInputs:    [][2]string{{"html_input", content.HTMLInput}},
+		components:    content.Components,
+		urlAlias:      content.UrlAlias,
+		aliasRequired: true,
+	})
+}
+
+func AuditPartnerContent(content *models.PartnerContent) []dto.ContentAuditFinding {
+	return auditContent(auditTarget{
+		metaTag: content.MetaTag,
+		htmlInputs: [][2]string{
+			{"html_input", content.HTMLInput},
+			{"company_detail", content.CompanyDetail},
+			{"lead_body", content.LeadBody},
+			{"challenges", content.Challenges},
+			{"solutions", content.Solutions},
+			{"results", content.Results},
+		},
+		components: content.Components,
+		urlAlias:   content.URLAlias,
+	})
+}
+
+func AuditFaqContent(content *models.FaqContent) []dto.ContentAuditFinding {
+	return auditContent(auditTarget{
+		metaTag:    content.MetaTag,
+		htmlInputs: [][2]string{{"html_input", content.HTMLInput}},
+		components: content.Components,
+		urlAlias:   content.URLAlias,
+	})
+}
+
+// ScoreAuditFindings starts from 100 and deducts per finding depending on its severity
+func ScoreAuditFindings(findings []dto.ContentAuditFinding) int {
+	score := 100
+	for _, finding := range findings {
+		switch finding.Severity {
+		case enums.AuditSeverityCritical:
+			score -= 25
+		case enums.AuditSeverityWarning:
+			score -= 10
+		case enums.AuditSeverityInfo:
+			score -= 2
+		}
+	}
+
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+func HasCriticalAuditFinding(findings []dto.ContentAuditFinding) bool {
+	for _, finding := range findings {
+		if finding.Severity == enums.AuditSeverityCritical {
+			return true
+		}
+	}
+	return false
+}
+
+func auditContent(target auditTarget) []dto.ContentAuditFinding {
+	findings := []dto.ContentAuditFinding{}
+	findings = append(findings, auditMetaTag(target.metaTag)...)
+
+	textLength := 0
+	for _, htmlInput := range target.htmlInputs {
+		textLength += auditHTML(htmlInput[0], htmlInput[1], &findings)
+	}
+
+	for i, component := range target.components {
+		textLength += auditComponentProps(fmt.Sprintf("components[%d]", i), component.Props, &findings)
+	}
+
+	if textLength < minContentLength {
+		findings = append(findings, dto.ContentAuditFinding{
+			Code:     "content_too_short",
+			Severity: enums.AuditSeverityWarning,
+			Field:    "html_input",
+			Message:  fmt.Sprintf("Content has %d characters of text, at least %d are recommended", textLength, minContentLength),
+		})
+	}
+
+	findings = append(findings, auditURLAlias(target.urlAlias, target.aliasRequired)...)
+
+	return findings
+}
+
+func auditMetaTag(metaTag *models.MetaTag) []dto.ContentAuditFinding {
+	if metaTag == nil {
+		return []dto.ContentAuditFinding{{
+			Code:     "meta_tag_missing",
+			Severity: enums.AuditSeverityCritical,
+			Field:    "meta_tag",
+			Message:  "Meta tag is missing",
+		}}
+	}
+
+	var findings []dto.ContentAuditFinding
+	title := strings.TrimSpace(metaTag.Title)
+	description := strings.TrimSpace(metaTag.Description)
+
+	switch {
+	case title == "":
+		findings = append(findings, dto.ContentAuditFinding{
+			Code:     "meta_title_missing",
+			Severity: enums.AuditSeverityCritical,
+			Field:    "meta_tag.title",
+			Message:  "Meta title is missing",
+		})
+	case utf8.RuneCountInString(title) > maxMetaTitleLength:
+		findings = append(findings, dto.ContentAuditFinding{
+			Code:     "meta_title_too_long",
+			Severity: enums.AuditSeverityWarning,
+			Field:    "meta_tag.title",
+			Message:  fmt.Sprintf("Meta title is longer than %d characters", maxMetaTitleLength),
+		})
+	}
+
+	switch {
+	case description == "":
+		findings = append(findings, dto.ContentAuditFinding{
+			Code:     "meta_description_missing",
+			Severity: enums.AuditSeverityWarning,
+			Field:    "meta_tag.description",
+			Message:  "Meta description is missing",
+		})
+	case utf8.RuneCountInString(description) > maxMetaDescriptionLength:
+		findings = append(findings, dto.ContentAuditFinding{
+			Code:     "meta_description_too_long",
+			Severity: enums.AuditSeverityWarning,
+			Field:    "meta_tag.description",
+			Message:  fmt.Sprintf("Meta description is longer than %d characters", maxMetaDescriptionLength),
+		})
+	}
+
+	if strings.TrimSpace(metaTag.CoverImage) == "" && strings.TrimSpace(metaTag.OGImage) == "" {
+		findings = append(findings, dto.ContentAuditFinding{
+			Code:     "cover_image_missing",
+			Severity: enums.AuditSeverityWarning,
+			Field:    "meta_tag.cover_image",
+			Message:  "Cover image is missing",
+		})
+	}
+
+	return findings
+}
+
+// auditHTML checks images and headings of the html and returns the length of its visible text
+func auditHTML(field, htmlInput string, findings *[]dto.ContentAuditFinding) int {
+	if strings.TrimSpace(htmlInput) == "" {
+		return 0
+	}
+
+	textLength := 0
+	h1Count := 0
+	previousLevel := 0
+	tokenizer := html.NewTokenizer(strings.NewReader(htmlInput))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.TextToken:
+			textLength += utf8.RuneCountInString(strings.TrimSpace(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if token.Data == "img" && strings.TrimSpace(htmlAttr(token, "alt")) == "" {
+				*findings = append(*findings, dto.ContentAuditFinding{
+					Code:     "image_alt_missing",
+					Severity: enums.AuditSeverityWarning,
+					Field:    field,
+					Message:  fmt.Sprintf("Image %q has no alt text", htmlAttr(token, "src")),
+				})
+			}
+
+			level := headingLevel(token.Data)
+			if level == 0 {
+				continue
+			}
+			if level == 1 {
+				h1Count++
+				if h1Count == 2 {
+					*findings = append(*findings, dto.ContentAuditFinding{
+						Code:     "heading_multiple_h1",
+						Severity: enums.AuditSeverityWarning,
+						Field:    field,
+						Message:  "More than one h1 heading",
+					})
+				}
+			}
+			if previousLevel != 0 && level > previousLevel+1 {
+				*findings = append(*findings, dto.ContentAuditFinding{
+					Code:     "heading_level_skipped",
+					Severity: enums.AuditSeverityWarning,
+					Field:    field,
+					Message:  fmt.Sprintf("Heading jumps from h%d to h%d", previousLevel, level),
+				})
+			}
+			previousLevel = level
+		}
+	}
+
+	return textLength
+}
+
+// auditComponentProps checks images in the props and returns the length of their text values
+func auditComponentProps(field string, props datatypes.JSON, findings *[]dto.ContentAuditFinding) int {
+	if len(props) == 0 {
+		return 0
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(props, &decoded); err != nil {
+		return 0
+	}
+
+	textLength := 0
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if image := firstStringProp(value, imagePropKeys); image != "" && firstStringProp(value, altPropKeys) == "" {
+				*findings = append(*findings, dto.ContentAuditFinding{
+					Code:     "image_alt_missing",
+					Severity: enums.AuditSeverityWarning,
+					Field:    field,
+					Message:  fmt.Sprintf("Image %q has no alt text", image),
+				})
+			}
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				child := value[key]
+				if text, ok := child.(string); ok && !containsString(imagePropKeys, key) {
+					textLength += auditHTML(field, text, findings)
+					continue
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, item := range value {
+				walk(item)
+			}
+		}
+	}
+	walk(decoded)
+
+	return textLength
+}
+
+func auditURLAlias(urlAlias string, required bool) []dto.ContentAuditFinding {
+	if urlAlias == "" {
+		if !required {
+			return nil
+		}
+		return []dto.ContentAuditFinding{{
+			Code:     "url_alias_missing",
+			Severity: enums.AuditSeverityCritical,
+			Field:    "url_alias",
+			Message:  "URL alias is missing",
+		}}
+	}
+
+	if !urlAliasRegex.MatchString(urlAlias) {
+		return []dto.ContentAuditFinding{{
+			Code:     "url_alias_invalid_format",
+			Severity: enums.AuditSeverityWarning,
+			Field:    "url_alias",
+			Message:  "URL alias should be lowercase words separated by single hyphens, without spaces or trailing slashes",
+		}}
+	}
+
+	return nil
+}
+
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+func htmlAttr(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func firstStringProp(props map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if value, ok := props[key].(string); ok && strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// BuildCMSEditURL links to the CMS editor of a content, relative when no CMS base url is configured
+func BuildCMSEditURL(cmsBaseURL string, pageType enums.PageType, pageId, contentId uuid.UUID, language enums.PageLanguage) string {
+	baseURL := strings.TrimSuffix(cmsBaseURL, "/")
+	return fmt.Sprintf("%s/%s-pages/%s/content/%s/edit?lang=%s", baseURL, pageType, pageId, contentId, language)
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+)
+
+func BuildContentURL(base, language, urlPath string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url: %w", err)
+	}
+
+	// Public pages live under /{language}/{url}
+	baseURL.Path = path.Join(baseURL.Path, language, urlPath)
+
+	return baseURL.String(), nil
+}
@@ -0,0 +1,30 @@
+package helpers
+
+import (
+	"sort"
+
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+)
+
+// BuildHrefLangAlternates turns a language -> url path map into absolute hreflang links, sorted by language
+func BuildHrefLangAlternates(base string, paths map[enums.PageLanguage]string) ([]models.HrefLangAlternate, error) {
+	alternates := []models.HrefLangAlternate{}
+	for language, urlPath := range paths {
+		href, err := BuildContentURL(base, string(language), urlPath)
+		if err != nil {
+			return nil, err
+		}
+
+		alternates = append(alternates, models.HrefLangAlternate{
+			HrefLang: string(language),
+			Href:     href,
+		})
+	}
+
+	sort.Slice(alternates, func(i, j int) bool {
+		return alternates[i].HrefLang < alternates[j].HrefLang
+	})
+
+	return alternates, nil
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+)
+
+const iCalendarTimeFormat = "20060102T150405Z"
+
+// BuildICalendar renders the events as an RFC 5545 calendar, each event is a point in time
+func BuildICalendar(name, uidDomain string, events []dto.CalendarEvent, now time.Time) string {
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(foldICalendarLine(line))
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//cms-api//Editorial Calendar//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:" + escapeICalendarText(name))
+
+	stamp := now.UTC().Format(iCalendarTimeFormat)
+	for _, event := range events {
+		summary := fmt.Sprintf("[%s] %s (%s, %s)", strings.ToUpper(string(event.Type)), event.Title, event.PageType, event.Language)
+		description := fmt.Sprintf("Status: %s", event.WorkflowStatus)
+		if event.Author != "" {
+			description += "\nAuthor: " + event.Author
+		}
+
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + escapeICalendarText(event.UID) + "@" + uidDomain)
+		writeLine("DTSTAMP:" + stamp)
+		writeLine("DTSTART:" + event.At.UTC().Format(iCalendarTimeFormat))
+		writeLine("DTEND:" + event.At.UTC().Format(iCalendarTimeFormat))
+		writeLine("SUMMARY:" + escapeICalendarText(summary))
+		writeLine("DESCRIPTION:" + escapeICalendarText(description))
+		writeLine("CATEGORIES:" + escapeICalendarText(string(event.PageType)))
+		if event.EditURL != "" {
+			writeLine("URL:" + event.EditURL)
+		}
+		writeLine("TRANSP:TRANSPARENT")
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+	return b.String()
+}
+
+func escapeICalendarText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return replacer.Replace(text)
+}
+
+// foldICalendarLine splits lines longer than 75 octets without cutting a UTF-8 character
+func foldICalendarLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -4,11 +4,9 @@ import (
 	"fmt"
 	"net/url"
 	"path"
-
-	"github.com/google/uuid"
 )
 
-func BuildPreviewURL(base, language, urlPath string, id uuid.UUID) (string, error) {
+func BuildPreviewURL(base, language, urlPath, token string) (string, error) {
 	baseURL, err := url.Parse(base)
 	if err != nil {
 		return "", fmt.Errorf("invalid base url: %w", err)
@@ -17,10 +15,10 @@ func BuildPreviewURL(base, language, urlPath string, id uuid.UUID) (string, erro
 	// Join the path segments safely
 	baseURL.Path = path.Join(baseURL.Path, "preview", language, urlPath)
 
-	// Add query parameter ?id=UUID
+	// Add query parameter ?token=<signed preview token>
 	query := baseURL.Query()
-	query.Set("id", id.String())
+	query.Set("token", token)
 	baseURL.RawQuery = query.Encode()
 
 	return baseURL.String(), nil
-}
\ No newline at end of file
+}
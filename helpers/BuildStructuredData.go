@@ -0,0 +1,161 @@
+package helpers
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/models"
+
+	"github.com/microcosm-cc/bluemonday"
+	"gorm.io/datatypes"
+)
+
+const schemaContext = "https://schema.org"
+
+// BuildFaqStructuredData yields a FAQPage whose question is the title of the content and answer its body as plain text
+func BuildFaqStructuredData(content *models.FaqContent, pageURL string) (datatypes.JSON, error) {
+	if override := structuredDataOverride(content.MetaTag); override != nil {
+		return override, nil
+	}
+
+	questions := []map[string]interface{}{}
+	// The body is authored as HTML, JSON-LD wants plain text
+	question := strings.TrimSpace(content.Title)
+	answer := strings.TrimSpace(html.UnescapeString(bluemonday.StrictPolicy().Sanitize(content.HTMLInput)))
+	if question != "" && answer != "" {
+		questions = append(questions, map[string]interface{}{
+			"@type": "Question",
+			"name":  question,
+			"acceptedAnswer": map[string]interface{}{
+				"@type": "Answer",
+				"text":  answer,
+			},
+		})
+	}
+
+	return marshalGraph(map[string]interface{}{
+		"@type":      "FAQPage",
+		"@id":        pageURL,
+		"url":        pageURL,
+		"name":       content.Title,
+		"inLanguage": string(content.Language),
+		"mainEntity": questions,
+	})
+}
+
+// BuildPartnerStructuredData yields an Article published by the partner Organization
+func BuildPartnerStructuredData(content *models.PartnerContent, pageURL string) (datatypes.JSON, error) {
+	if override := structuredDataOverride(content.MetaTag); override != nil {
+		return override, nil
+	}
+
+	organizationID := pageURL + "#organization"
+	organization := map[string]interface{}{
+		"@type": "Organization",
+		"@id":   organizationID,
+		"name":  content.CompanyName,
+	}
+	if content.CompanyLogo != "" {
+		organization["logo"] = content.CompanyLogo
+	}
+
+	article := map[string]interface{}{
+		"@type":            "Article",
+		"@id":              pageURL + "#article",
+		"mainEntityOfPage": pageURL,
+		"headline":         content.Title,
+		"inLanguage":       string(content.Language),
+		"author":           map[string]interface{}{"@id": organizationID},
+		"publisher":        map[string]interface{}{"@id": organizationID},
+		"dateModified":     content.UpdatedAt.Format(time.RFC3339),
+	}
+	if content.ThumbnailImage != "" {
+		article["image"] = content.ThumbnailImage
+	}
+	if published := firstNonZeroTime(content.PublishOn, content.AuthoredAt, content.CreatedAt); !published.IsZero() {
+		article["datePublished"] = published.Format(time.RFC3339)
+	}
+	if content.MetaTag != nil && content.MetaTag.Description != "" {
+		article["description"] = content.MetaTag.Description
+	}
+
+	return marshalGraph(article, organization)
+}
+
+// BuildLandingStructuredData yields a WebPage with a BreadcrumbList built from the url alias
+func BuildLandingStructuredData(content *models.LandingContent, homeURL, pageURL string) (datatypes.JSON, error) {
+	if override := structuredDataOverride(content.MetaTag); override != nil {
+		return override, nil
+	}
+
+	breadcrumbs := []map[string]interface{}{
+		{"@type": "ListItem", "position": 1, "name": "Home", "item": homeURL},
+	}
+	segments := strings.Split(strings.Trim(content.UrlAlias, "/"), "/")
+	crumbURL := strings.TrimSuffix(homeURL, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		crumbURL += "/" + segment
+
+		name := strings.ReplaceAll(segment, "-", " ")
+		if i == len(segments)-1 && content.Title != "" {
+			name = content.Title
+		}
+
+		breadcrumbs = append(breadcrumbs, map[string]interface{}{
+			"@type":    "ListItem",
+			"position": len(breadcrumbs) + 1,
+			"name":     name,
+			"item":     crumbURL,
+		})
+	}
+
+	webPage := map[string]interface{}{
+		"@type":      "WebPage",
+		"@id":        pageURL,
+		"url":        pageURL,
+		"name":       content.Title,
+		"inLanguage": string(content.Language),
+		"breadcrumb": map[string]interface{}{"@id": pageURL + "#breadcrumb"},
+	}
+	if content.MetaTag != nil && content.MetaTag.Description != "" {
+		webPage["description"] = content.MetaTag.Description
+	}
+
+	return marshalGraph(webPage, map[string]interface{}{
+		"@type":           "BreadcrumbList",
+		"@id":             pageURL + "#breadcrumb",
+		"itemListElement": breadcrumbs,
+	})
+}
+
+func structuredDataOverride(metaTag *models.MetaTag) datatypes.JSON {
+	if metaTag == nil || len(metaTag.StructuredData) == 0 || string(metaTag.StructuredData) == "null" {
+		return nil
+	}
+	return metaTag.StructuredData
+}
+
+func marshalGraph(nodes ...map[string]interface{}) (datatypes.JSON, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"@context": schemaContext,
+		"@graph":   nodes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(data), nil
+}
+
+func firstNonZeroTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/MadManJJ/cms-api/errs"
+
+	"github.com/google/uuid"
+)
+
+// Cursor is the position of a keyset page: the sort values and ID of the row the page starts after
+type Cursor struct {
+	Sort     string    `json:"s"`           // Sort the cursor was made for, it means nothing under another one
+	Values   []string  `json:"v"`           // Sort column values of the boundary row, times as RFC 3339
+	ID       uuid.UUID `json:"id"`          // Breaks ties between rows with equal sort values
+	Backward bool      `json:"b,omitempty"` // A prev cursor, the page holds the rows before the boundary
+}
+
+// EncodeCursor returns the cursor as an opaque url-safe token
+func EncodeCursor(cursor Cursor) string {
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errs.ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, errs.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
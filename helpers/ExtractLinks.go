@@ -0,0 +1,137 @@
+package helpers
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"gorm.io/datatypes"
+)
+
+// Attributes holding a link, per tag
+var linkAttrsByTag = map[string][]string{
+	"a":      {"href"},
+	"area":   {"href"},
+	"link":   {"href"},
+	"img":    {"src"},
+	"iframe": {"src"},
+	"source": {"src"},
+	"video":  {"src", "poster"},
+	"audio":  {"src"},
+	"embed":  {"src"},
+	"script": {"src"},
+}
+
+// ExtractHTMLLinks returns every distinct href/src found in the html, in order of appearance
+func ExtractHTMLLinks(htmlInput string) []string {
+	links := []string{}
+	if strings.TrimSpace(htmlInput) == "" {
+		return links
+	}
+
+	seen := map[string]bool{}
+	tokenizer := html.NewTokenizer(strings.NewReader(htmlInput))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		for _, attrName := range linkAttrsByTag[token.Data] {
+			link := strings.TrimSpace(htmlAttr(token, attrName))
+			if IsCheckableLink(link) && !seen[link] {
+				seen[link] = true
+				links = append(links, link)
+			}
+		}
+	}
+
+	return links
+}
+
+// ExtractComponentLinks returns every distinct URL-like value in the props, including links inside HTML values
+func ExtractComponentLinks(props datatypes.JSON) []string {
+	links := []string{}
+	if len(props) == 0 {
+		return links
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(props, &decoded); err != nil {
+		return links
+	}
+
+	seen := map[string]bool{}
+	add := func(link string) {
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(value[key])
+			}
+		case []interface{}:
+			for _, item := range value {
+				walk(item)
+			}
+		case string:
+			text := strings.TrimSpace(value)
+			if strings.Contains(text, "<") {
+				for _, link := range ExtractHTMLLinks(text) {
+					add(link)
+				}
+			} else if looksLikeURL(text) {
+				add(text)
+			}
+		}
+	}
+	walk(decoded)
+
+	return links
+}
+
+// IsCheckableLink filters out anchors and schemes that cannot be requested
+func IsCheckableLink(link string) bool {
+	if link == "" || strings.HasPrefix(link, "#") {
+		return false
+	}
+
+	lower := strings.ToLower(link)
+	for _, prefix := range []string{"mailto:", "tel:", "javascript:", "data:", "sms:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func looksLikeURL(value string) bool {
+	if strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+
+	lower := strings.ToLower(value)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return true
+	}
+
+	// Root-relative paths, but not protocol-relative or bare slashes
+	return len(value) > 1 && strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//")
+}
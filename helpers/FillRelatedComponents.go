@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"encoding/json"
+
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"gorm.io/datatypes"
+)
+
+var relationTypeByComponentType = map[enums.ComponentType]enums.RelationType{
+	enums.ComponentRelatedArticles: enums.RelationRelatedArticle,
+	enums.ComponentRelatedLinks:    enums.RelationRelatedLink,
+}
+
+// FillRelatedComponents replaces the hard-coded items of the related articles and links components with the
+// resolved related pages of their relation type. Components keep their own items when no page of the type is related.
+func FillRelatedComponents(components []*models.Component, related []models.RelatedPage) error {
+	items := map[enums.RelationType][]map[string]interface{}{}
+	for _, page := range related {
+		items[page.RelationType] = append(items[page.RelationType], map[string]interface{}{
+			"page_type": page.PageType,
+			"page_id":   page.PageID,
+			"title":     page.Title,
+			"url":       page.URL,
+			"thumbnail": page.Thumbnail,
+		})
+	}
+
+	for _, component := range components {
+		if component == nil {
+			continue
+		}
+		relationType, ok := relationTypeByComponentType[component.Type]
+		if !ok || len(items[relationType]) == 0 {
+			continue
+		}
+
+		props := map[string]interface{}{}
+		if len(component.Props) > 0 {
+			if err := json.Unmarshal(component.Props, &props); err != nil {
+				props = map[string]interface{}{}
+			}
+		}
+		// The links and articles keys take precedence over items when rendering
+		delete(props, "links")
+		delete(props, "articles")
+		props["items"] = items[relationType]
+
+		filled, err := json.Marshal(props)
+		if err != nil {
+			return err
+		}
+		component.Props = datatypes.JSON(filled)
+	}
+
+	return nil
+}
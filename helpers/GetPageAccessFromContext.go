@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"github.com/MadManJJ/cms-api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PageAccessKey is the Locals key the page access of the user of a request is kept under
+const PageAccessKey = "page_access"
+
+// GetPageAccessFromContext returns the page access left by the page access middleware, nil when there is none
+func GetPageAccessFromContext(c *fiber.Ctx) *dto.PageAccess {
+	access, _ := c.Locals(PageAccessKey).(*dto.PageAccess)
+	return access
+}
@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewCachedResponse encodes the body the way c.JSON would and derives its ETag from the encoded bytes
+func NewCachedResponse(c *fiber.Ctx, value interface{}, lastModified, loadedAt time.Time) (*dto.CachedResponse, error) {
+	body, err := c.App().Config().JSONEncoder(value)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(body)
+	return &dto.CachedResponse{
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: lastModified.UTC().Truncate(time.Second), // HTTP dates have no sub-second part
+		LoadedAt:     loadedAt,
+	}, nil
+}
+
+// SendCachedResponse writes the response with its validators and Cache-Control, or a 304 when the
+// If-None-Match or If-Modified-Since of the request shows the client already has it
+func SendCachedResponse(c *fiber.Ctx, response *dto.CachedResponse, maxAge time.Duration) error {
+	c.Set(fiber.HeaderETag, response.ETag)
+	if !response.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, response.LastModified.Format(http.TimeFormat))
+	}
+	if maxAge > 0 {
+		c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	} else {
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+	}
+
+	if clientHasResponse(c, response) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(response.Body)
+}
+
+// clientHasResponse follows RFC 9110, If-Modified-Since is only looked at without If-None-Match
+func clientHasResponse(c *fiber.Ctx, response *dto.CachedResponse) bool {
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == response.ETag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := c.Get(fiber.HeaderIfModifiedSince); since != "" && !response.LastModified.IsZero() {
+		sinceTime, err := http.ParseTime(since)
+		return err == nil && !response.LastModified.After(sinceTime)
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// NewOutboxEvent builds a domain event with its payload. Without an idempotency key the event id is used,
+// so only events that may be produced twice, like the ones queued by a retried subscriber, need one.
+func NewOutboxEvent(eventType enums.DomainEventType, aggregateId uuid.UUID, idempotencyKey string, payload interface{}) (*models.OutboxEvent, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	if idempotencyKey == "" {
+		idempotencyKey = id.String()
+	}
+
+	return &models.OutboxEvent{
+		ID:             id,
+		EventType:      eventType,
+		AggregateID:    aggregateId,
+		IdempotencyKey: idempotencyKey,
+		Payload:        datatypes.JSON(body),
+		CreatedAt:      time.Now(),
+	}, nil
+}
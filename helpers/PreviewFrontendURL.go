@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/MadManJJ/cms-api/errs"
+)
+
+// PreviewFrontendURL picks the site rendering previews: the preferred url when set,
+// otherwise the second FRONTEND_URLS entry (the first one is the CMS) or the only one
+func PreviewFrontendURL(preferred, frontendURLs string) (string, error) {
+	candidate := strings.TrimSpace(preferred)
+	if candidate == "" {
+		entries := []string{}
+		for _, entry := range strings.Split(frontendURLs, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+
+		switch len(entries) {
+		case 0:
+			return "", errs.ErrPreviewFrontendURLMissing
+		case 1:
+			candidate = entries[0]
+		default:
+			candidate = entries[1]
+		}
+	}
+
+	parsed, err := url.Parse(candidate)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errs.ErrPreviewFrontendURLMissing
+	}
+
+	return strings.TrimSuffix(candidate, "/"), nil
+}
@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// PreviewTokenType is the typ and the audience of every preview token, so no other token check can take one for a login
+const PreviewTokenType = "preview"
+
+// PreviewTokenClaims identifies the previewed content, the token id is the preview link id
+type PreviewTokenClaims struct {
+	Type      string `json:"typ"`
+	PageType  string `json:"page_type"`
+	ContentID string `json:"content_id"`
+	Language  string `json:"language"`
+	jwt.RegisteredClaims
+}
+
+func SignPreviewToken(claims PreviewTokenClaims, secretKey string) (string, error) {
+	if secretKey == "" {
+		return "", errors.New("preview secret key is not configured")
+	}
+
+	claims.Type = PreviewTokenType
+	claims.Audience = jwt.ClaimStrings{PreviewTokenType}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+}
+
+// ParsePreviewToken checks the signature, expiry and type, expired tokens return jwt.ErrTokenExpired
+func ParsePreviewToken(tokenStr string, secretKey string) (*PreviewTokenClaims, error) {
+	claims := &PreviewTokenClaims{}
+	parsedToken, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !parsedToken.Valid || claims.Type != PreviewTokenType || !claims.VerifyAudience(PreviewTokenType, true) {
+		return nil, errors.New("invalid preview token")
+	}
+
+	return claims, nil
+}
+
+// IsPreviewToken reports whether the token claims to be a preview token. The signature is not checked, so only use it to refuse tokens.
+func IsPreviewToken(tokenStr string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenStr, claims); err != nil {
+		return false
+	}
+
+	return claims["typ"] == PreviewTokenType || claims.VerifyAudience(PreviewTokenType, true)
+}
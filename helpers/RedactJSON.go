@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+const RedactedValue = "[REDACTED]"
+
+// secretKeyParts are matched against lower case keys without "_" and "-"
+var secretKeyParts = []string{"password", "secret", "token", "authorization", "apikey", "privatekey", "cookie"}
+
+func isSecretKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, part := range secretKeyParts {
+		if strings.Contains(normalized, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isSecretKey(key) {
+				if child != nil {
+					v[key] = RedactedValue
+				}
+				continue
+			}
+			v[key] = redactValue(child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// RedactJSON replaces the value of every key that looks like a password, secret or token, at any depth.
+// It returns nil when data is empty or not JSON, so nothing unredacted is ever kept.
+func RedactJSON(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+
+	// Numbers are kept as written instead of going through float64
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
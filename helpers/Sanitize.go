@@ -1,6 +1,10 @@
 package helpers
 
 import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
 	"github.com/MadManJJ/cms-api/models"
 
 	"github.com/microcosm-cc/bluemonday"
@@ -27,12 +31,77 @@ func SanitizeCategories(categories []*models.Category) {
 	}
 }
 
+// SanitizeURL keeps a link or image url when it is an absolute http or https url or a reference relative to the site,
+// anything else, like a javascript: url, is dropped. The url is kept as written, escaping it is up to where it is output.
+func SanitizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	switch parsed.Scheme {
+	case "http", "https":
+		if parsed.Host == "" {
+			return ""
+		}
+	case "":
+		// Protocol relative urls like //host/path would leave the site
+		if parsed.Host != "" {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	return raw
+}
+
 func SanitizeMetaTag(metaTag *models.MetaTag) {
 	// Plain text
 	textPolicy := bluemonday.StrictPolicy()
 
 	metaTag.Title = textPolicy.Sanitize(metaTag.Title)
 	metaTag.Description = textPolicy.Sanitize(metaTag.Description)
+	metaTag.CoverImage = SanitizeURL(metaTag.CoverImage)
+	metaTag.Robots = textPolicy.Sanitize(metaTag.Robots)
+	metaTag.CanonicalURL = SanitizeURL(metaTag.CanonicalURL)
+	metaTag.OGTitle = textPolicy.Sanitize(metaTag.OGTitle)
+	metaTag.OGDescription = textPolicy.Sanitize(metaTag.OGDescription)
+	metaTag.OGImage = SanitizeURL(metaTag.OGImage)
+	metaTag.OGType = textPolicy.Sanitize(metaTag.OGType)
+	metaTag.TwitterCard = textPolicy.Sanitize(metaTag.TwitterCard)
+	metaTag.TwitterTitle = textPolicy.Sanitize(metaTag.TwitterTitle)
+	metaTag.TwitterDescription = textPolicy.Sanitize(metaTag.TwitterDescription)
+	metaTag.TwitterImage = SanitizeURL(metaTag.TwitterImage)
+
+	// Custom meta is rendered as <meta name="key" content="value">, so both sides are plain text
+	if metaTag.CustomMeta != nil {
+		sanitized := make(map[string]interface{}, len(metaTag.CustomMeta))
+		for key, value := range metaTag.CustomMeta {
+			key = textPolicy.Sanitize(key)
+			if key == "" {
+				continue
+			}
+			if str, ok := value.(string); ok {
+				sanitized[key] = textPolicy.Sanitize(str)
+			}
+		}
+		metaTag.CustomMeta = sanitized
+	}
+
+	// Structured data ends up inside <script type="application/ld+json">, re-encoding escapes <, > and &
+	if len(metaTag.StructuredData) > 0 {
+		var structuredData interface{}
+		if err := json.Unmarshal(metaTag.StructuredData, &structuredData); err != nil {
+			metaTag.StructuredData = nil
+		} else if encoded, err := json.Marshal(structuredData); err == nil {
+			metaTag.StructuredData = encoded
+		}
+	}
 }
 
 func SanitizeFaqContent(content *models.FaqContent) {
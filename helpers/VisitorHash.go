@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// HashVisitor returns a keyed hash of what identifies a visitor, so the visitor can be counted without storing them.
+// The salt is the dedicated HASH_SALT, never a signing key, so rotating a signing key keeps the counts.
+func HashVisitor(salt string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
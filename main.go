@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/MadManJJ/cms-api/config"
@@ -13,6 +16,7 @@ import (
 	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
 	commonHandler "github.com/MadManJJ/cms-api/handlers/common"
 	"github.com/MadManJJ/cms-api/middleware"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/repositories"
 	"github.com/MadManJJ/cms-api/services"
 
@@ -47,6 +51,15 @@ func main() {
 	// Load configuration
 	cfg := config.New()
 
+	// A preview link must never verify as a login token, nor a login token as a preview link
+	if cfg.Preview.SecretKey == "" || cfg.Preview.SecretKey == cfg.SecretKey.NormalKey || cfg.Preview.SecretKey == cfg.SecretKey.LineKey {
+		log.Fatal("PREVIEW_SECRET_KEY must be set and differ from JWT_SECRET_KEY and OAUTH_CLIENT_SECRET")
+	}
+	// Rotating a signing key must not reset the visitor, feedback and experiment hashes
+	if cfg.SecretKey.HashSalt == "" || cfg.SecretKey.HashSalt == cfg.SecretKey.NormalKey || cfg.SecretKey.HashSalt == cfg.SecretKey.LineKey || cfg.SecretKey.HashSalt == cfg.Preview.SecretKey {
+		log.Fatal("HASH_SALT must be set and differ from JWT_SECRET_KEY, OAUTH_CLIENT_SECRET and PREVIEW_SECRET_KEY")
+	}
+
 	// convert port number from string to int
 	Port, err := strconv.Atoi(cfg.Database.Port)
 	if err != nil {
@@ -81,6 +94,11 @@ func main() {
 		AppName:      cfg.Server.AppName,
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
+		// c.IP() is the client ip for rate limits, feedback and analytics, only trusted proxies may set it with ProxyHeader
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	// Set up static file serving
@@ -138,12 +156,30 @@ func main() {
 	mediaFileRepo := repositories.NewMediaFileRepository(db)
 	formRepo := repositories.NewFormRepository(db)
 	formSubmissionRepo := repositories.NewFormSubmissionRepository(db)
+	cmsContentAuditRepo := repositories.NewCMSContentAuditRepository(db)
+	cmsLinkCheckRepo := repositories.NewCMSLinkCheckRepository(db)
+	cmsPreviewLinkRepo := repositories.NewCMSPreviewLinkRepository(db)
+	cmsMaintenanceRepo := repositories.NewCMSMaintenanceRepository(db)
+	cmsAutosaveRepo := repositories.NewCMSAutosaveRepository(db)
+	cmsCalendarRepo := repositories.NewCMSCalendarRepository(db)
+	cmsFaqFeedbackRepo := repositories.NewCMSFaqFeedbackRepository(db)
+	cmsAnalyticsRepo := repositories.NewCMSAnalyticsRepository(db)
+	cmsLandingExperimentRepo := repositories.NewCMSLandingExperimentRepository(db)
+	cmsContentRelationRepo := repositories.NewCMSContentRelationRepository(db)
+	cmsUsageRepo := repositories.NewCMSUsageRepository(db)
+	appGraphQLRepo := repositories.NewAppGraphQLRepository(db)
+	cmsWebhookRepo := repositories.NewCMSWebhookRepository(db)
+	cmsOutboxRepo := repositories.NewOutboxRepository(db)
+	cmsAuditLogRepo := repositories.NewCMSAuditLogRepository(db)
+	cmsRoleRepo := repositories.NewCMSRoleRepository(db)
+	appCacheRepo := repositories.NewAppCacheRepository(db)
 
 	// Initialize services
 	appService := services.NewAppService(appRepo)
-	appLandingPageService := services.NewAppLandingPageService(appLandingPageRepo)
-	appPartnerPageService := services.NewAppPartnerPageService(appPartnerPageRepo)
-	appFaqPageService := services.NewAppFaqPageService(appFaqPageRepo)
+	cmsContentRelationService := services.NewCMSContentRelationService(cmsContentRelationRepo, cfg)
+	appLandingPageService := services.NewAppLandingPageService(appLandingPageRepo, cmsContentRelationService, cfg)
+	appPartnerPageService := services.NewAppPartnerPageService(appPartnerPageRepo, cmsContentRelationService, cfg)
+	appFaqPageService := services.NewAppFaqPageService(appFaqPageRepo, cmsContentRelationService, cfg)
 	cmsService := services.NewCMSService(cmsRepo)
 	cmsAuthService := services.NewCMSAuthService(cmsAuthRepo)
 	categoryService := services.NewCMSCategoryService(cmsCategoryRepo, cmsCategoryTypeRepo)
@@ -152,33 +188,88 @@ func main() {
 	emailCategoryService := services.NewEmailCategoryService(emailCategoryRepo, emailContentRepo)
 	emailContentService := services.NewEmailContentService(emailContentRepo, emailCategoryRepo)
 	emailSendingService := services.NewEmailSendingService(cfg, emailCategoryRepo, emailContentRepo)
+	cmsOutboxService := services.NewCMSOutboxService(cmsOutboxRepo, cfg)
+	cmsAuditLogService := services.NewCMSAuditLogService(cmsAuditLogRepo)
+	cmsRoleService := services.NewCMSRoleService(cmsRoleRepo, cmsAuthRepo, cfg)
+	queuedEmailSender := services.NewQueuedEmailSender(cmsOutboxService, emailSendingService)
 	mediaFileService := services.NewMediaFileService(cfg, mediaFileRepo)
-	cmsLandingPageService := services.NewCMSLandingPageService(cmsLandingPageRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)
-	cmsPartnerPageService := services.NewCMSPartnerPageService(cmsPartnerPageRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)
+	cmsLandingPageService := services.NewCMSLandingPageService(cmsLandingPageRepo, queuedEmailSender, emailContentRepo, emailCategoryRepo, cfg)
+	cmsPartnerPageService := services.NewCMSPartnerPageService(cmsPartnerPageRepo, queuedEmailSender, emailContentRepo, emailCategoryRepo, cfg)
 	cmsFormService := services.NewCMSFormService(db, formRepo, emailCategoryRepo, cfg)
 	commonLineLoginService := services.NewLineLoginService(cfg, cmsAuthRepo)
-	cmsFormSubmissionService := services.NewCMSFormSubmissionService(formSubmissionRepo, emailSendingService)
+	cmsFormSubmissionService := services.NewCMSFormSubmissionService(formSubmissionRepo, queuedEmailSender)
+	cmsContentAuditService := services.NewCMSContentAuditService(cmsContentAuditRepo)
+	cmsLinkCheckService := services.NewCMSLinkCheckService(cmsLinkCheckRepo, cfg)
+	cmsPreviewLinkService := services.NewCMSPreviewLinkService(cmsPreviewLinkRepo, cfg)
+	cmsMaintenanceService := services.NewCMSMaintenanceService(cmsMaintenanceRepo, cfg)
+	cmsAutosaveService := services.NewCMSAutosaveService(cmsAutosaveRepo, cmsLandingPageService, cmsPartnerPageService, cmsFaqPageService)
+	cmsCalendarService := services.NewCMSCalendarService(cmsCalendarRepo, cmsAuthRepo, cmsRoleService, cfg)
+	cmsFaqFeedbackService := services.NewCMSFaqFeedbackService(cmsFaqFeedbackRepo, cfg)
+	cmsAnalyticsService := services.NewCMSAnalyticsService(cmsAnalyticsRepo, cfg)
+	cmsLandingExperimentService := services.NewCMSLandingExperimentService(cmsLandingExperimentRepo, cmsLandingPageService, cfg)
+	cmsUsageService := services.NewCMSUsageService(cmsUsageRepo, cfg)
+	appResponseCache := services.NewAppResponseCache(appCacheRepo, cfg)
+	cmsWebhookService := services.NewCMSWebhookService(cmsWebhookRepo, cfg)
+
+	// Subscriber names are stored with their deliveries, keep them stable
+	cmsOutboxService.Subscribe("email", queuedEmailSender.HandleDomainEvent, enums.DomainEventEmailRequested)
+	cmsOutboxService.Subscribe("approval-email-landing", cmsLandingPageService.HandleDomainEvent, enums.DomainEventContentSaved)
+	cmsOutboxService.Subscribe("approval-email-partner", cmsPartnerPageService.HandleDomainEvent, enums.DomainEventContentSaved)
+	cmsOutboxService.Subscribe("form-submission-email", cmsFormSubmissionService.HandleDomainEvent, enums.DomainEventFormSubmissionCreated)
+	cmsOutboxService.Subscribe("cache", appResponseCache.HandleDomainEvent,
+		enums.DomainEventContentSaved, enums.DomainEventContentDeleted, enums.DomainEventMediaFileUploaded, enums.DomainEventMediaFileDeleted)
+	cmsOutboxService.Subscribe("usage-index", cmsUsageService.HandleDomainEvent,
+		enums.DomainEventContentSaved, enums.DomainEventContentDeleted, enums.DomainEventMediaFileUploaded, enums.DomainEventMediaFileDeleted)
+	cmsOutboxService.Subscribe("webhooks", cmsWebhookService.HandleDomainEvent,
+		enums.DomainEventContentSaved, enums.DomainEventContentDeleted, enums.DomainEventFormSubmissionCreated, enums.DomainEventMediaFileUploaded)
+	componentRenderer, err := services.NewComponentRenderer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load component templates: %v", err)
+	}
+	appGraphQLService, err := services.NewAppGraphQLService(appLandingPageService, appPartnerPageService, appFaqPageService, formRepo, appGraphQLRepo, cfg)
+	if err != nil {
+		log.Fatalf("Failed to build the GraphQL schema: %v", err)
+	}
 
 	// Initialize handlers
 	healthHandler := commonHandler.NewHealthHandler()
 	commonLineLoginHandler := commonHandler.NewLineLoginHandler(commonLineLoginService)
 	testMiddlewareHanlder := commonHandler.NewTestMiddlewareHandler()
-	appLandingPageHandler := appHandler.NewAppLandingPageHandler(appLandingPageService)
-	appPartnerPageHandler := appHandler.NewAppPartnerPageHandler(appPartnerPageService)
-	appFaqPageHandler := appHandler.NewAppFaqPageHandler(appFaqPageService)
+	appLandingPageHandler := appHandler.NewAppLandingPageHandler(appLandingPageService, cmsPreviewLinkService, componentRenderer, cmsLandingExperimentService, appResponseCache)
+	appPartnerPageHandler := appHandler.NewAppPartnerPageHandler(appPartnerPageService, cmsPreviewLinkService, componentRenderer, appResponseCache)
+	appFaqPageHandler := appHandler.NewAppFaqPageHandler(appFaqPageService, cmsPreviewLinkService, componentRenderer, appResponseCache)
+	appFaqFeedbackHandler := appHandler.NewAppFaqFeedbackHandler(cmsFaqFeedbackService)
+	appAnalyticsHandler := appHandler.NewAppAnalyticsHandler(cmsAnalyticsService)
+	appLandingExperimentHandler := appHandler.NewAppLandingExperimentHandler(cmsLandingExperimentService)
+	appGraphQLHandler := appHandler.NewAppGraphQLHandler(appGraphQLService)
 	appHandler := appHandler.NewAppHandler(appService)
 	cmsCategoryTypeHandler := cmsHandler.NewCMSCategoryTypeHandler(cmsCategoryTypeService)
-	cmsCategoryHandler := cmsHandler.NewCMSCategoryHandler(categoryService)
-	cmsFaqPageHandler := cmsHandler.NewCMSFaqPageHandler(cmsFaqPageService)
-	cmsLandingPageHandler := cmsHandler.NewCMSLandingPageHandler(cmsLandingPageService)
-	cmsPartnerPageHandler := cmsHandler.NewCMSPartnerPageHandler(cmsPartnerPageService)
+	cmsCategoryHandler := cmsHandler.NewCMSCategoryHandler(categoryService, cmsUsageService, appResponseCache)
+	cmsFaqPageHandler := cmsHandler.NewCMSFaqPageHandler(cmsFaqPageService, cmsPreviewLinkService, cmsUsageService)
+	cmsLandingPageHandler := cmsHandler.NewCMSLandingPageHandler(cmsLandingPageService, cmsPreviewLinkService, cmsUsageService)
+	cmsPartnerPageHandler := cmsHandler.NewCMSPartnerPageHandler(cmsPartnerPageService, cmsPreviewLinkService, cmsUsageService)
 	cmsAuthHandler := cmsHandler.NewAuthCMSHandler(cmsAuthService)
-	cmsFormHandler := cmsHandler.NewCMSFormHandler(cmsFormService)
+	cmsFormHandler := cmsHandler.NewCMSFormHandler(cmsFormService, appResponseCache)
 	cmsFormSubmissionHandler := cmsHandler.NewCMSFormSubmissionHandler(cmsFormSubmissionService)
+	cmsContentAuditHandler := cmsHandler.NewCMSContentAuditHandler(cmsContentAuditService)
+	cmsLinkCheckHandler := cmsHandler.NewCMSLinkCheckHandler(cmsLinkCheckService)
+	cmsPreviewLinkHandler := cmsHandler.NewCMSPreviewLinkHandler(cmsPreviewLinkService)
+	cmsMaintenanceHandler := cmsHandler.NewCMSMaintenanceHandler(cmsMaintenanceService)
+	cmsAutosaveHandler := cmsHandler.NewCMSAutosaveHandler(cmsAutosaveService)
+	cmsCalendarHandler := cmsHandler.NewCMSCalendarHandler(cmsCalendarService)
+	cmsFaqFeedbackHandler := cmsHandler.NewCMSFaqFeedbackHandler(cmsFaqFeedbackService)
+	cmsAnalyticsHandler := cmsHandler.NewCMSAnalyticsHandler(cmsAnalyticsService)
+	cmsLandingExperimentHandler := cmsHandler.NewCMSLandingExperimentHandler(cmsLandingExperimentService, appResponseCache)
+	cmsContentRelationHandler := cmsHandler.NewCMSContentRelationHandler(cmsContentRelationService)
+	cmsUsageHandler := cmsHandler.NewCMSUsageHandler(cmsUsageService)
+	cmsWebhookHandler := cmsHandler.NewCMSWebhookHandler(cmsWebhookService)
+	cmsOutboxHandler := cmsHandler.NewCMSOutboxHandler(cmsOutboxService)
+	cmsAuditLogHandler := cmsHandler.NewCMSAuditLogHandler(cmsAuditLogService)
+	cmsRoleHandler := cmsHandler.NewCMSRoleHandler(cmsRoleService)
 	emailCategoryCMSHandler := cmsHandler.NewEmailCategoryHandler(emailCategoryService)
 	emailContentCMSHandler := cmsHandler.NewEmailContentHandler(emailContentService)
 	emailSendingHandler := commonHandler.NewEmailSendingHandler(emailSendingService)
-	mediaFileCMSHandler := cmsHandler.NewMediaFileHandler(mediaFileService)
+	mediaFileCMSHandler := cmsHandler.NewMediaFileHandler(mediaFileService, cmsUsageService)
 	cmsHandler := cmsHandler.NewCMSHandler(cmsService)
 
 	// Setup routes directly in main.go
@@ -195,34 +286,63 @@ func main() {
 
 	appLandingGroup := appGroup.Group("/landingpages")
 	appLandingGroup.Get("/:languageCode/by-alias", appLandingPageHandler.HandleGetLandingPageByUrlAlias)
-	appLandingGroup.Get("/previews/:id", appLandingPageHandler.HandleGetLandingContentPreview)
+	appLandingGroup.Get("/previews/:token", appLandingPageHandler.HandleGetLandingContentPreview)
+	appLandingGroup.Post("/experiments/:experimentId/conversions", middleware.RateLimit(cfg.Analytics.RateLimit, cfg.Analytics.RateWindow), appLandingExperimentHandler.HandleRecordConversion)
 
 	appPartnerGroup := appGroup.Group("/partnerpages")
 	appPartnerGroup.Get("/:languageCode/by-alias", appPartnerPageHandler.HandleGetPartnerPageByAlias)
 	appPartnerGroup.Get("/:languageCode/by-url", appPartnerPageHandler.HandleGetPartnerPageByUrl)
-	appPartnerGroup.Get("/previews/:id", appPartnerPageHandler.HandleGetPartnerContentPreview)
+	appPartnerGroup.Get("/previews/:token", appPartnerPageHandler.HandleGetPartnerContentPreview)
+	appPartnerGroup.Get("/:languageCode", appPartnerPageHandler.HandleGetPartnerListing)
 
 	appFaqGroup := appGroup.Group("/faqpages")
 	appFaqGroup.Get("/:languageCode/by-alias", appFaqPageHandler.HandleGetFaqPageByAlias)
 	appFaqGroup.Get("/:languageCode/by-url", appFaqPageHandler.HandleGetFaqPageByUrl)
-	appFaqGroup.Get("/previews/:id", appFaqPageHandler.HandleGetFaqContentPreview)
+	appFaqGroup.Get("/previews/:token", appFaqPageHandler.HandleGetFaqContentPreview)
+	appFaqGroup.Get("/:languageCode/categories", appFaqPageHandler.HandleGetFaqCategoryTree)
+	appFaqGroup.Get("/:languageCode/categories/:categoryId", appFaqPageHandler.HandleGetFaqsByCategory)
+	appFaqGroup.Get("/:languageCode/grouped", appFaqPageHandler.HandleGetFaqsGroupedByCategory)
+	appFaqGroup.Post("/feedback/:contentId", middleware.RateLimit(cfg.Feedback.RateLimit, cfg.Feedback.RateWindow), appFaqFeedbackHandler.HandleCreateFaqFeedback)
+
+	appAnalyticsGroup := appGroup.Group("/analytics")
+	appAnalyticsGroup.Post("/views", middleware.RateLimit(cfg.Analytics.RateLimit, cfg.Analytics.RateWindow), appAnalyticsHandler.HandleTrackPageView)
+
+	appGroup.Get("/graphql", appGraphQLHandler.HandleGraphQL)
+	appGroup.Post("/graphql", appGraphQLHandler.HandleGraphQL)
 
 	// CMS routes under v1
 	cmsGroup := apiGroup.Group("/cms")
-	cmsGroup.Get("/test", cmsHandler.HandleTest)
-	cmsGroup.Get("/additional", cmsHandler.HandleAdditional)
+	// Every mutating CMS request is audited, auditSnapshot keeps the row an update or delete route changes
+	auditLog := middleware.AuditLog(cmsAuditLogService, cfg.SecretKey.LineKey, cfg.SecretKey.NormalKey)
+	cmsGroup.Use(auditLog)
+	auditSnapshot := func(table, param string) fiber.Handler {
+		return middleware.AuditSnapshot(cmsAuditLogService, table, param)
+	}
+	// Every CMS route but login needs a token whose role allows the request on the resource of the route,
+	// can checks the action of the request method and canDo an explicit one
+	authenticated := middleware.CheckAnyTokenMiddleware(cfg.SecretKey.LineKey, cfg.SecretKey.NormalKey, cmsAuthRepo)
+	can := func(resource enums.PermissionResource) fiber.Handler {
+		return middleware.CheckPermissionMiddleware(cmsRoleService, resource)
+	}
+	canDo := func(resource enums.PermissionResource, action enums.PermissionAction) fiber.Handler {
+		return middleware.CheckActionPermissionMiddleware(cmsRoleService, resource, action)
+	}
+	// The page services are further limited to the page grants of the user
+	pageAccess := middleware.PageAccessMiddleware(cmsRoleService)
+	cmsGroup.Get("/test", authenticated, cmsHandler.HandleTest)
+	cmsGroup.Get("/additional", authenticated, cmsHandler.HandleAdditional)
 	cmsAuthGroup := cmsGroup.Group("/auth")
-	cmsAuthGroup.Post("/register", cmsAuthHandler.HandleRegister)
+	cmsAuthGroup.Post("/register", authenticated, canDo(enums.PermissionResourceUsers, enums.PermissionActionCreate), cmsAuthHandler.HandleRegister)
 	cmsAuthGroup.Post("/login", cmsAuthHandler.HandleLogin)
 
-	cmsFaqPageGroup := cmsGroup.Group("/faqpages")
+	cmsFaqPageGroup := cmsGroup.Group("/faqpages", authenticated, can(enums.PermissionResourcePages), pageAccess)
 	cmsFaqPageGroup.Post("/", cmsFaqPageHandler.HandleCreateFaqPage)
 	cmsFaqPageGroup.Get("/", cmsFaqPageHandler.HandleGetFaqPages)
 	cmsFaqPageGroup.Get("/:pageId", cmsFaqPageHandler.HandleGetFaqPageById)
 	cmsFaqPageGroup.Get("/:pageId/latestcontents/:languageCode", cmsFaqPageHandler.HandleGetLatestContentByFaqPageId)
-	cmsFaqPageGroup.Post("/:revisionId/revisions", cmsFaqPageHandler.HandleRevertFaqContent)
-	cmsFaqPageGroup.Put("/:contentId/contents", cmsFaqPageHandler.HandleUpdateFaqContent)
-	cmsFaqPageGroup.Delete("/:pageId", cmsFaqPageHandler.HandleDeleteFaqPage)
+	cmsFaqPageGroup.Post("/:revisionId/revisions", middleware.AuditAction("revert"), cmsFaqPageHandler.HandleRevertFaqContent)
+	cmsFaqPageGroup.Put("/:contentId/contents", auditSnapshot("faq_contents", "contentId"), cmsFaqPageHandler.HandleUpdateFaqContent)
+	cmsFaqPageGroup.Delete("/:pageId", auditSnapshot("faq_pages", "pageId"), cmsFaqPageHandler.HandleDeleteFaqPage)
 	cmsFaqPageGroup.Get("/:pageId/contents/:languageCode", cmsFaqPageHandler.HandleGetContentByFaqPageId)
 	cmsFaqPageGroup.Delete("/:pageId/contents/:languageCode", cmsFaqPageHandler.HandleDeleteFaqContentByPageId)
 	cmsFaqPageGroup.Post("/duplicate/:pageId/pages", cmsFaqPageHandler.HandleDuplicateFaqPage)
@@ -231,14 +351,14 @@ func main() {
 	cmsFaqPageGroup.Get("/revisions/:languageCode/:pageId", cmsFaqPageHandler.HandleGetRevisions)
 	cmsFaqPageGroup.Post("/previews/:pageId", cmsFaqPageHandler.HandlePreviewFaqContent)
 
-	cmsLandingPageGroup := cmsGroup.Group("/landingpages")
+	cmsLandingPageGroup := cmsGroup.Group("/landingpages", authenticated, can(enums.PermissionResourcePages), pageAccess)
 	cmsLandingPageGroup.Post("/", cmsLandingPageHandler.HandleCreateLandingPage)
 	cmsLandingPageGroup.Get("/", cmsLandingPageHandler.HandleGetLandingPages)
 	cmsLandingPageGroup.Get("/:pageId", cmsLandingPageHandler.HandleGetLandingPageById)
 	cmsLandingPageGroup.Get("/:pageId/latestcontents/:languageCode", cmsLandingPageHandler.HandleGetLatestContentByLandingPageId)
-	cmsLandingPageGroup.Post("/:revisionId/revisions", cmsLandingPageHandler.HandleRevertLandingContent)
-	cmsLandingPageGroup.Put("/:contentId/contents", cmsLandingPageHandler.HandleUpdateLandingContent)
-	cmsLandingPageGroup.Delete("/:pageId", cmsLandingPageHandler.HandleDeleteLandingPage)
+	cmsLandingPageGroup.Post("/:revisionId/revisions", middleware.AuditAction("revert"), cmsLandingPageHandler.HandleRevertLandingContent)
+	cmsLandingPageGroup.Put("/:contentId/contents", auditSnapshot("landing_contents", "contentId"), cmsLandingPageHandler.HandleUpdateLandingContent)
+	cmsLandingPageGroup.Delete("/:pageId", auditSnapshot("landing_pages", "pageId"), cmsLandingPageHandler.HandleDeleteLandingPage)
 	cmsLandingPageGroup.Get("/:pageId/contents/:languageCode", cmsLandingPageHandler.HandleGetContentByLandingPageId)
 	cmsLandingPageGroup.Delete("/:pageId/contents/:languageCode", cmsLandingPageHandler.HandleDeleteLandingContentByPageId)
 	cmsLandingPageGroup.Post("/duplicate/:pageId/pages", cmsLandingPageHandler.HandleDuplicateLandingPage)
@@ -247,14 +367,14 @@ func main() {
 	cmsLandingPageGroup.Get("/revisions/:languageCode/:pageId", cmsLandingPageHandler.HandleGetRevisions)
 	cmsLandingPageGroup.Post("/previews/:pageId", cmsLandingPageHandler.HandlePreviewLandingContent)
 
-	cmsPartnerPageGroup := cmsGroup.Group("/partnerpages")
+	cmsPartnerPageGroup := cmsGroup.Group("/partnerpages", authenticated, can(enums.PermissionResourcePages), pageAccess)
 	cmsPartnerPageGroup.Post("/", cmsPartnerPageHandler.HandleCreatePartnerPage)
 	cmsPartnerPageGroup.Get("/", cmsPartnerPageHandler.HandleGetPartnerPages)
 	cmsPartnerPageGroup.Get("/:pageId", cmsPartnerPageHandler.HandleGetPartnerPageById)
 	cmsPartnerPageGroup.Get("/:pageId/latestcontents/:languageCode", cmsPartnerPageHandler.HandleGetLatestContentByPartnerPageId)
-	cmsPartnerPageGroup.Post("/:revisionId/revisions", cmsPartnerPageHandler.HandleRevertPartnerContent)
-	cmsPartnerPageGroup.Put("/:contentId/contents", cmsPartnerPageHandler.HandleUpdatePartnerContent)
-	cmsPartnerPageGroup.Delete("/:pageId", cmsPartnerPageHandler.HandleDeletePartnerPage)
+	cmsPartnerPageGroup.Post("/:revisionId/revisions", middleware.AuditAction("revert"), cmsPartnerPageHandler.HandleRevertPartnerContent)
+	cmsPartnerPageGroup.Put("/:contentId/contents", auditSnapshot("partner_contents", "contentId"), cmsPartnerPageHandler.HandleUpdatePartnerContent)
+	cmsPartnerPageGroup.Delete("/:pageId", auditSnapshot("partner_pages", "pageId"), cmsPartnerPageHandler.HandleDeletePartnerPage)
 	cmsPartnerPageGroup.Get("/:pageId/contents/:languageCode", cmsPartnerPageHandler.HandleGetContentByPartnerPageId)
 	cmsPartnerPageGroup.Delete("/:pageId/contents/:languageCode", cmsPartnerPageHandler.HandleDeletePartnerContentByPageId)
 	cmsPartnerPageGroup.Post("/duplicate/:pageId/pages", cmsPartnerPageHandler.HandleDuplicatePartnerPage)
@@ -263,46 +383,135 @@ func main() {
 	cmsPartnerPageGroup.Get("/revisions/:languageCode/:pageId", cmsPartnerPageHandler.HandleGetRevisions)
 	cmsPartnerPageGroup.Post("/previews/:pageId", cmsPartnerPageHandler.HandlePreviewPartnerContent)
 
-	cmsCategoryTypesGroup := cmsGroup.Group("/category-types")
+	cmsAuditGroup := cmsGroup.Group("/audits", authenticated, can(enums.PermissionResourcePages), pageAccess)
+	cmsAuditGroup.Get("/:pageType/:contentId", cmsContentAuditHandler.HandleAuditContent)
+
+	cmsLinkCheckGroup := cmsGroup.Group("/link-checks", authenticated, can(enums.PermissionResourcePages), pageAccess)
+	cmsLinkCheckGroup.Get("/", cmsLinkCheckHandler.HandleGetLinkChecks)
+	cmsLinkCheckGroup.Post("/run", cmsLinkCheckHandler.HandleRunLinkCheck)
+
+	cmsPreviewLinkGroup := cmsGroup.Group("/previews", authenticated, can(enums.PermissionResourcePages), pageAccess)
+	cmsPreviewLinkGroup.Get("/", cmsPreviewLinkHandler.HandleGetPreviewLinks)
+	cmsPreviewLinkGroup.Delete("/:id", auditSnapshot("preview_links", "id"), cmsPreviewLinkHandler.HandleRevokePreviewLink)
+
+	cmsMaintenanceGroup := cmsGroup.Group("/maintenance", authenticated, can(enums.PermissionResourceSystem))
+	cmsMaintenanceGroup.Post("/cleanup", cmsMaintenanceHandler.HandleRunMaintenance)
+	cmsMaintenanceGroup.Get("/metrics", cmsMaintenanceHandler.HandleGetMaintenanceMetrics)
+
+	// Autosaves are per user, so the user must be known from the token
+	cmsAutosaveGroup := cmsGroup.Group("/autosaves", authenticated, can(enums.PermissionResourcePages), pageAccess)
+	cmsAutosaveGroup.Get("/:pageType/:contentId", cmsAutosaveHandler.HandleGetAutosave)
+	cmsAutosaveGroup.Put("/:pageType/:contentId", cmsAutosaveHandler.HandleSaveAutosave)
+	cmsAutosaveGroup.Delete("/:pageType/:contentId", cmsAutosaveHandler.HandleDiscardAutosave)
+	cmsAutosaveGroup.Post("/:pageType/:contentId/promote", cmsAutosaveHandler.HandlePromoteAutosave)
+
+	// A calendar feed token only reads, so reading pages is enough to issue and revoke one
+	cmsCalendarGroup := cmsGroup.Group("/calendar", authenticated, canDo(enums.PermissionResourcePages, enums.PermissionActionRead), pageAccess)
+	cmsCalendarGroup.Get("/", cmsCalendarHandler.HandleGetCalendarEvents)
+	cmsCalendarGroup.Post("/feed", cmsCalendarHandler.HandleIssueFeedToken)
+	cmsCalendarGroup.Delete("/feed", cmsCalendarHandler.HandleRevokeFeedToken)
+
+	// Calendar apps cannot log in, the feed is authenticated by its secret token
+	apiGroup.Get("/calendar/feeds/:token", cmsCalendarHandler.HandleGetCalendarFeed)
+
+	cmsFaqFeedbackGroup := cmsGroup.Group("/faqfeedback", authenticated, can(enums.PermissionResourceAnalytics))
+	cmsFaqFeedbackGroup.Get("/stats", cmsFaqFeedbackHandler.HandleGetHelpfulnessSeries)
+	cmsFaqFeedbackGroup.Get("/lowest", cmsFaqFeedbackHandler.HandleGetLowestRated)
+	cmsFaqFeedbackGroup.Get("/comments", cmsFaqFeedbackHandler.HandleGetFeedbackComments)
+
+	cmsAnalyticsGroup := cmsGroup.Group("/analytics", authenticated, can(enums.PermissionResourceAnalytics))
+	cmsAnalyticsGroup.Get("/pages/:pageType/:pageId", cmsAnalyticsHandler.HandleGetPageAnalytics)
+	cmsAnalyticsGroup.Get("/top", cmsAnalyticsHandler.HandleGetTopPages)
+	cmsAnalyticsGroup.Get("/campaigns", cmsAnalyticsHandler.HandleGetCampaignTraffic)
+
+	cmsExperimentGroup := cmsGroup.Group("/experiments", authenticated, can(enums.PermissionResourcePages), pageAccess)
+	cmsExperimentGroup.Post("/", cmsLandingExperimentHandler.HandleCreateExperiment)
+	cmsExperimentGroup.Get("/", cmsLandingExperimentHandler.HandleGetExperiments)
+	cmsExperimentGroup.Get("/:experimentId/results", cmsLandingExperimentHandler.HandleGetExperimentResults)
+	cmsExperimentGroup.Post("/:experimentId/stop", auditSnapshot("landing_experiments", "experimentId"), cmsLandingExperimentHandler.HandleStopExperiment)
+	cmsExperimentGroup.Post("/:experimentId/promote", canDo(enums.PermissionResourcePages, enums.PermissionActionPublish), auditSnapshot("landing_experiments", "experimentId"), cmsLandingExperimentHandler.HandlePromoteWinner)
+
+	cmsRelationGroup := cmsGroup.Group("/relations", authenticated, can(enums.PermissionResourcePages), pageAccess)
+	cmsRelationGroup.Get("/incoming/:pageType/:pageId", cmsContentRelationHandler.HandleGetIncomingRelations)
+	cmsRelationGroup.Get("/:pageType/contents/:contentId", cmsContentRelationHandler.HandleGetRelations)
+	cmsRelationGroup.Put("/:pageType/contents/:contentId", cmsContentRelationHandler.HandleReplaceRelations)
+
+	cmsUsageGroup := cmsGroup.Group("/usages", authenticated)
+	cmsUsageGroup.Post("/rebuild", canDo(enums.PermissionResourceSystem, enums.PermissionActionUpdate), cmsUsageHandler.HandleRebuildUsageIndex)
+	cmsUsageGroup.Get("/:itemType/:itemId", can(enums.PermissionResourcePages), pageAccess, cmsUsageHandler.HandleGetUsages)
+	cmsUsageGroup.Get("/:itemType/:itemId/impact", can(enums.PermissionResourcePages), pageAccess, cmsUsageHandler.HandleGetDeleteImpact)
+
+	cmsWebhookGroup := cmsGroup.Group("/webhooks", authenticated, can(enums.PermissionResourceWebhooks))
+	cmsWebhookGroup.Post("/", cmsWebhookHandler.HandleCreateWebhook)
+	cmsWebhookGroup.Get("/", cmsWebhookHandler.HandleGetWebhooks)
+	cmsWebhookGroup.Get("/deliveries", cmsWebhookHandler.HandleGetWebhookDeliveries)
+	cmsWebhookGroup.Get("/deliveries/:deliveryId", cmsWebhookHandler.HandleGetWebhookDelivery)
+	cmsWebhookGroup.Post("/deliveries/:deliveryId/replay", cmsWebhookHandler.HandleReplayWebhookDelivery)
+	cmsWebhookGroup.Get("/:webhookId", cmsWebhookHandler.HandleGetWebhook)
+	cmsWebhookGroup.Put("/:webhookId", auditSnapshot("webhook_endpoints", "webhookId"), cmsWebhookHandler.HandleUpdateWebhook)
+	cmsWebhookGroup.Delete("/:webhookId", auditSnapshot("webhook_endpoints", "webhookId"), cmsWebhookHandler.HandleDeleteWebhook)
+	cmsWebhookGroup.Post("/:webhookId/secret", middleware.AuditAction("rotate_secret"), cmsWebhookHandler.HandleRotateWebhookSecret)
+
+	cmsOutboxGroup := cmsGroup.Group("/outbox", authenticated, can(enums.PermissionResourceSystem))
+	cmsOutboxGroup.Get("/deliveries", cmsOutboxHandler.HandleGetOutboxDeliveries)
+	cmsOutboxGroup.Get("/deliveries/:deliveryId", cmsOutboxHandler.HandleGetOutboxDelivery)
+	cmsOutboxGroup.Post("/deliveries/:deliveryId/retry", cmsOutboxHandler.HandleRetryOutboxDelivery)
+
+	cmsAuditLogGroup := cmsGroup.Group("/audit-logs", authenticated, can(enums.PermissionResourceAuditLogs))
+	cmsAuditLogGroup.Get("/", cmsAuditLogHandler.HandleGetAuditLogs)
+	cmsAuditLogGroup.Get("/export", cmsAuditLogHandler.HandleExportAuditLogs)
+	cmsAuditLogGroup.Get("/:auditLogId", cmsAuditLogHandler.HandleGetAuditLog)
+
+	cmsRoleGroup := cmsGroup.Group("/roles", authenticated, can(enums.PermissionResourceUsers))
+	cmsRoleGroup.Get("/", cmsRoleHandler.HandleGetRoles)
+
+	cmsUserGroup := cmsGroup.Group("/users", authenticated, can(enums.PermissionResourceUsers))
+	cmsUserGroup.Put("/:userId/role", cmsRoleHandler.HandleAssignUserRole)
+	cmsUserGroup.Get("/:userId/page-grants", cmsRoleHandler.HandleGetPageGrants)
+	cmsUserGroup.Post("/:userId/page-grants", cmsRoleHandler.HandleCreatePageGrant)
+	cmsUserGroup.Delete("/:userId/page-grants/:grantId", cmsRoleHandler.HandleDeletePageGrant)
+
+	cmsCategoryTypesGroup := cmsGroup.Group("/category-types", authenticated, can(enums.PermissionResourceCategories))
 	cmsCategoryTypesGroup.Post("/", cmsCategoryTypeHandler.HandleCreateCategoryType)
 	cmsCategoryTypesGroup.Get("/", cmsCategoryTypeHandler.HandleListCategoryTypes)
 	cmsCategoryTypesGroup.Get("/:id", cmsCategoryTypeHandler.HandleGetCategoryType)
-	cmsCategoryTypesGroup.Patch("/:id", cmsCategoryTypeHandler.HandleUpdateCategoryType)
-	cmsCategoryTypesGroup.Delete("/:id", cmsCategoryTypeHandler.HandleDeleteCategoryType)
+	cmsCategoryTypesGroup.Patch("/:id", auditSnapshot("category_types", "id"), cmsCategoryTypeHandler.HandleUpdateCategoryType)
+	cmsCategoryTypesGroup.Delete("/:id", auditSnapshot("category_types", "id"), cmsCategoryTypeHandler.HandleDeleteCategoryType)
 	cmsCategoryTypesGroup.Get("/:categoryTypeId/categories", cmsCategoryTypeHandler.HandleListCategoriesForType)
 
-	categoriesGroup := cmsGroup.Group("/categories")
+	categoriesGroup := cmsGroup.Group("/categories", authenticated, can(enums.PermissionResourceCategories))
 	categoriesGroup.Post("/", cmsCategoryHandler.HandleCreateCategory)
 	categoriesGroup.Get("/", cmsCategoryHandler.HandleListAllCategories)
 	categoriesGroup.Get("/:categoryUuid", cmsCategoryHandler.HandleGetCategoryByUUID) 
-	categoriesGroup.Patch("/:categoryUuid", cmsCategoryHandler.HandleUpdateCategory)
-	categoriesGroup.Delete("/:categoryUuid", cmsCategoryHandler.HandleDeleteCategory)
+	categoriesGroup.Patch("/:categoryUuid", auditSnapshot("categories", "categoryUuid"), cmsCategoryHandler.HandleUpdateCategory)
+	categoriesGroup.Delete("/:categoryUuid", auditSnapshot("categories", "categoryUuid"), cmsCategoryHandler.HandleDeleteCategory)
 
-	emailCategoriesCMSGroup := cmsGroup.Group("/email-categories")
+	emailCategoriesCMSGroup := cmsGroup.Group("/email-categories", authenticated, can(enums.PermissionResourceEmails))
 	emailCategoriesCMSGroup.Post("/", emailCategoryCMSHandler.HandleCreateEmailCategory)
 	emailCategoriesCMSGroup.Get("/", emailCategoryCMSHandler.HandleListEmailCategories)
 	emailCategoriesCMSGroup.Get("/:id", emailCategoryCMSHandler.HandleGetEmailCategory)
-	emailCategoriesCMSGroup.Patch("/:id", emailCategoryCMSHandler.HandleUpdateEmailCategory)
-	emailCategoriesCMSGroup.Delete("/:id", emailCategoryCMSHandler.HandleDeleteEmailCategory)
+	emailCategoriesCMSGroup.Patch("/:id", auditSnapshot("email_categories", "id"), emailCategoryCMSHandler.HandleUpdateEmailCategory)
+	emailCategoriesCMSGroup.Delete("/:id", auditSnapshot("email_categories", "id"), emailCategoryCMSHandler.HandleDeleteEmailCategory)
 
-	emailContentsCMSGroup := cmsGroup.Group("/email-contents")
+	emailContentsCMSGroup := cmsGroup.Group("/email-contents", authenticated, can(enums.PermissionResourceEmails))
 	emailContentsCMSGroup.Post("/", emailContentCMSHandler.HandleCreateEmailContent)
 	emailContentsCMSGroup.Get("/", emailContentCMSHandler.HandleListEmailContents)
 	emailContentsCMSGroup.Get("/category/:email_category_id/language/:language", emailContentCMSHandler.HandleGetEmailContentByCategoryAndLanguage)
 	emailContentsCMSGroup.Get("", emailContentCMSHandler.HandleGetEmailContent)
-	emailContentsCMSGroup.Patch("/:id", emailContentCMSHandler.HandleUpdateEmailContent)
-	emailContentsCMSGroup.Delete("/:id", emailContentCMSHandler.HandleDeleteEmailContent)
+	emailContentsCMSGroup.Patch("/:id", auditSnapshot("email_contents", "id"), emailContentCMSHandler.HandleUpdateEmailContent)
+	emailContentsCMSGroup.Delete("/:id", auditSnapshot("email_contents", "id"), emailContentCMSHandler.HandleDeleteEmailContent)
 
 	// Media files CMS routes
-	mediaFilesCMSGroup := cmsGroup.Group("/media-files")
+	mediaFilesCMSGroup := cmsGroup.Group("/media-files", authenticated, can(enums.PermissionResourceMedia))
 	mediaFilesCMSGroup.Post("/", mediaFileCMSHandler.HandleUploadMediaFile)    
 	mediaFilesCMSGroup.Get("/", mediaFileCMSHandler.HandleListMediaFiles)      
 	mediaFilesCMSGroup.Get("/:id", mediaFileCMSHandler.HandleGetMediaFileByID) 
-	mediaFilesCMSGroup.Delete("/:id", mediaFileCMSHandler.HandleDeleteMediaFile)
+	mediaFilesCMSGroup.Delete("/:id", auditSnapshot("media_files", "id"), mediaFileCMSHandler.HandleDeleteMediaFile)
 
 	// EMAIL SENDING ROUTE (can be under /api/v1 or /api/v1/common etc.)
-	emailSendingGroup := apiGroup.Group("/emails")
-	emailSendingGroup.Post("/send", emailSendingHandler.HandleSendEmail)
+	// Outside the cms group, so it records its own audit log of the mails sent on behalf of the user
+	emailSendingGroup := apiGroup.Group("/emails", auditLog, middleware.AuditEntity("emails"), authenticated, canDo(enums.PermissionResourceEmails, enums.PermissionActionSend))
+	emailSendingGroup.Post("/send", middleware.AuditAction("send"), emailSendingHandler.HandleSendEmail)
 
 	// Common routes
 	commonGroup := apiGroup.Group("/common")
@@ -310,24 +519,63 @@ func main() {
 	commonGroup.Post("/authenticate", commonLineLoginHandler.HandleAuthenticate)
 	commonGroup.Post("/refresh-token", commonLineLoginHandler.HandleRefreshToken)
 
-	cmsFormBuilderGroup := cmsGroup.Group("/forms")
-	cmsFormBuilderGroup.Post("/", cmsFormHandler.HandleCreateForm)
-	cmsFormBuilderGroup.Get("/", cmsFormHandler.HandleListForms)
-	cmsFormBuilderGroup.Get("/:formId", cmsFormHandler.HandleGetForm)
-	cmsFormBuilderGroup.Put("/:formId", cmsFormHandler.HandleUpdateForm)
-	cmsFormBuilderGroup.Delete("/:formId", cmsFormHandler.HandleDeleteForm)
-	cmsFormBuilderGroup.Post("/:formId/submissions", cmsFormSubmissionHandler.HandleCreateFormSubmission)
-	cmsFormBuilderGroup.Get("/:formId/submissions", cmsFormSubmissionHandler.HandleGetFormSubmissions)
-	cmsFormBuilderGroup.Get("/submissions/:submissionId", cmsFormSubmissionHandler.HandleGetFormSubmission)
+	cmsFormBuilderGroup := cmsGroup.Group("/forms", authenticated)
+	cmsFormBuilderGroup.Post("/", can(enums.PermissionResourceForms), cmsFormHandler.HandleCreateForm)
+	cmsFormBuilderGroup.Get("/", can(enums.PermissionResourceForms), cmsFormHandler.HandleListForms)
+	cmsFormBuilderGroup.Get("/:formId", can(enums.PermissionResourceForms), cmsFormHandler.HandleGetForm)
+	cmsFormBuilderGroup.Put("/:formId", can(enums.PermissionResourceForms), auditSnapshot("forms", "formId"), cmsFormHandler.HandleUpdateForm)
+	cmsFormBuilderGroup.Delete("/:formId", can(enums.PermissionResourceForms), auditSnapshot("forms", "formId"), cmsFormHandler.HandleDeleteForm)
+	cmsFormBuilderGroup.Post("/:formId/submissions", can(enums.PermissionResourceFormSubmissions), cmsFormSubmissionHandler.HandleCreateFormSubmission)
+	cmsFormBuilderGroup.Get("/:formId/submissions", can(enums.PermissionResourceFormSubmissions), cmsFormSubmissionHandler.HandleGetFormSubmissions)
+	cmsFormBuilderGroup.Get("/submissions/:submissionId", can(enums.PermissionResourceFormSubmissions), cmsFormSubmissionHandler.HandleGetFormSubmission)
 
 	appFormGroup := appGroup.Group("/forms")
 	appFormGroup.Get("/:formId/structure", cmsFormHandler.HandleGetFormStructure)
+	// Visitors submit forms here, the CMS route needs a login
+	appFormGroup.Post("/:formId/submissions", cmsFormSubmissionHandler.HandleCreateFormSubmission)
 
 	// Test routes for middleware
 	testGroup := apiGroup.Group("/middleware")
 	testGroup.Get("/test", middleware.CheckAnyTokenMiddleware(cfg.SecretKey.LineKey, cfg.SecretKey.NormalKey, cmsAuthRepo), testMiddlewareHanlder.HandleTestMiddleware)
 
+	// Background jobs, stopped on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go cmsLinkCheckService.StartScheduler(ctx)
+	go cmsMaintenanceService.StartScheduler(ctx)
+	go cmsAnalyticsService.StartRollupScheduler(ctx)
+	go cmsUsageService.StartScheduler(ctx)
+	go cmsWebhookService.StartDispatcher(ctx)
+	go cmsOutboxService.StartDispatcher(ctx)
+	go appResponseCache.Listen(ctx, dsn)
+
+	// The batch writer stops after the server, so the views queued by the last requests are still written
+	writerCtx, stopWriter := context.WithCancel(context.Background())
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		cmsAnalyticsService.StartBatchWriter(writerCtx)
+	}()
+
 	// Start the server
 	log.Printf("Starting server on port %s in %s mode", cfg.Server.Port, cfg.App.Environment)
-	log.Fatal(app.Listen(":" + cfg.Server.Port))
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + cfg.Server.Port)
+	}()
+
+	select {
+	case err := <-listenErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down server")
+	if err := app.ShutdownWithTimeout(cfg.Server.ShutdownTimeout); err != nil {
+		log.Printf("Failed to shut down the server: %v", err)
+	}
+	stopWriter()
+	<-writerDone
+	log.Println("Server stopped")
 }
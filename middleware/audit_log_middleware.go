@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	auditBeforeKey   = "audit_before"
+	auditEntityIDKey = "audit_entity_id"
+	auditActionKey   = "audit_action"
+	auditEntityKey   = "audit_entity_type"
+)
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// AuditLog records every mutating request of the group it is used on once the request was handled,
+// with the user from the JWT, the route, the entity and the snapshots left by AuditSnapshot.
+// lineKey and normalKey identify the user when no token middleware ran before it.
+func AuditLog(service services.CMSAuditLogServiceInterface, lineKey, normalKey string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !isMutatingMethod(c.Method()) {
+			return c.Next()
+		}
+
+		prefix := c.Route().Path
+		err := c.Next()
+
+		route := c.Route()
+		if route.Path == prefix {
+			// No route matched, nothing was changed
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		entityType, action := auditRoute(c.Method(), strings.TrimPrefix(route.Path, prefix))
+		if override, ok := c.Locals(auditActionKey).(string); ok {
+			action = override
+		}
+		if override, ok := c.Locals(auditEntityKey).(string); ok {
+			entityType = override
+		}
+
+		auditLog := &models.AuditLog{
+			UserID:     auditUserID(c, lineKey, normalKey),
+			Action:     action,
+			Method:     c.Method(),
+			Path:       route.Path,
+			EntityType: entityType,
+			StatusCode: status,
+			IP:         c.IP(),
+			UserAgent:  c.Get(fiber.HeaderUserAgent),
+		}
+		if before, ok := c.Locals(auditBeforeKey).(datatypes.JSON); ok {
+			auditLog.Before = before
+		}
+		if err == nil && status < fiber.StatusBadRequest && c.Method() != fiber.MethodDelete {
+			auditLog.After = auditResponseItem(c.Response().Body())
+		}
+		auditLog.EntityID = auditEntityID(c, route, auditLog.After)
+
+		if recordErr := service.Record(auditLog); recordErr != nil {
+			log.Printf("failed to record audit log for %s %s: %v", c.Method(), route.Path, recordErr)
+		}
+
+		return err
+	}
+}
+
+// AuditSnapshot keeps the row of table whose id is in the param for the audit log before the handler changes it.
+// Use it on the update and delete routes of a group using AuditLog.
+func AuditSnapshot(service services.CMSAuditLogServiceInterface, table, param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params(param))
+		if err != nil {
+			// The handler answers the invalid id
+			return c.Next()
+		}
+
+		c.Locals(auditEntityIDKey, id)
+		before, err := service.Snapshot(table, id)
+		if err != nil {
+			log.Printf("failed to snapshot %s %s for the audit log: %v", table, id, err)
+		} else if before != nil {
+			c.Locals(auditBeforeKey, before)
+		}
+
+		return c.Next()
+	}
+}
+
+// AuditAction names the action of a route whose path does not say what it does
+func AuditAction(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(auditActionKey, action)
+		return c.Next()
+	}
+}
+
+// AuditEntity overrides the entity type read from the path, for routes audited outside the cms group
+func AuditEntity(entityType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(auditEntityKey, entityType)
+		return c.Next()
+	}
+}
+
+// auditRoute reads the entity type and action from a route path relative to the group, e.g.
+// "/categories/:categoryUuid" is an update of categories and "/webhooks/deliveries/:deliveryId/replay" a deliveries.replay of webhooks
+func auditRoute(method, path string) (string, string) {
+	var static []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" && !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			static = append(static, segment)
+		}
+	}
+	if len(static) == 0 {
+		return "", strings.ToLower(method)
+	}
+
+	entityType := static[0]
+	switch method {
+	case fiber.MethodPut, fiber.MethodPatch:
+		return entityType, "update"
+	case fiber.MethodDelete:
+		return entityType, "delete"
+	}
+	if len(static) == 1 {
+		return entityType, "create"
+	}
+	return entityType, strings.Join(static[1:], ".")
+}
+
+// auditUserID is the user from the claims of the token middleware, or else from a valid bearer token
+func auditUserID(c *fiber.Ctx, lineKey, normalKey string) *uuid.UUID {
+	if userID, err := helpers.GetUserIDFromContext(c); err == nil {
+		return &userID
+	}
+
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" {
+		return nil
+	}
+	if claims, err := helpers.ParseJWTWithKey(token, lineKey); err == nil {
+		if sub, ok := claims["sub"].(string); ok {
+			userID := helpers.UUIDFromSub(sub)
+			return &userID
+		}
+	}
+	if claims, err := helpers.ParseJWTWithKey(token, normalKey); err == nil {
+		if value, ok := claims["user_id"].(string); ok {
+			if userID, err := uuid.Parse(value); err == nil {
+				return &userID
+			}
+		}
+	}
+	return nil
+}
+
+// auditResponseItem is the item a handler answered with, the whole body when it has no item
+func auditResponseItem(body []byte) datatypes.JSON {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil
+	}
+	for _, key := range []string{"item", "data"} {
+		if item, ok := envelope[key]; ok {
+			return datatypes.JSON(item)
+		}
+	}
+	return datatypes.JSON(body)
+}
+
+// auditEntityID is the id given to AuditSnapshot, else the first id in the route, else the id of the created item
+func auditEntityID(c *fiber.Ctx, route *fiber.Route, after datatypes.JSON) *uuid.UUID {
+	if id, ok := c.Locals(auditEntityIDKey).(uuid.UUID); ok {
+		return &id
+	}
+	for _, param := range route.Params {
+		if id, err := uuid.Parse(c.Params(param)); err == nil {
+			return &id
+		}
+	}
+
+	var item struct {
+		ID string `json:"id"`
+	}
+	if len(after) > 0 && json.Unmarshal(after, &item) == nil {
+		if id, err := uuid.Parse(item.ID); err == nil {
+			return &id
+		}
+	}
+	return nil
+}
@@ -3,6 +3,10 @@ package middleware
 import (
 	"fmt"
 
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -36,4 +40,94 @@ func CheckRoleMiddleware(allowedRoles ...string) fiber.Handler {
 			"error": "Forbidden: insufficient permissions",
 		})
 	}
-}
\ No newline at end of file
+}
+
+// methodPermissionAction is the action a request does by its method
+func methodPermissionAction(method string) enums.PermissionAction {
+	switch method {
+	case fiber.MethodPost:
+		return enums.PermissionActionCreate
+	case fiber.MethodPut, fiber.MethodPatch:
+		return enums.PermissionActionUpdate
+	case fiber.MethodDelete:
+		return enums.PermissionActionDelete
+	default:
+		return enums.PermissionActionRead
+	}
+}
+
+// CheckPermissionMiddleware checks if the role of the user may do the action of the request method on the resource:
+// GET reads, POST creates, PUT and PATCH update and DELETE deletes.
+// Use after CheckAnyTokenMiddleware, a request without its claims is answered 401 and a role without the permission 403.
+func CheckPermissionMiddleware(service services.CMSRoleServiceInterface, resource enums.PermissionResource) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return checkPermission(c, service, resource, methodPermissionAction(c.Method()))
+	}
+}
+
+// CheckActionPermissionMiddleware is CheckPermissionMiddleware for a route whose method does not say what it does
+func CheckActionPermissionMiddleware(service services.CMSRoleServiceInterface, resource enums.PermissionResource, action enums.PermissionAction) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return checkPermission(c, service, resource, action)
+	}
+}
+
+func checkPermission(c *fiber.Ctx, service services.CMSRoleServiceInterface, resource enums.PermissionResource, action enums.PermissionAction) error {
+	user, ok := c.Locals("user").(jwt.MapClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	role, _ := user["role"].(string)
+	if role == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Role not found",
+		})
+	}
+
+	allowed, err := service.HasPermission(role, resource, action)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check permissions",
+		})
+	}
+	if !allowed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": fmt.Sprintf("Forbidden: %s on %s is not allowed", action, resource),
+		})
+	}
+
+	return c.Next()
+}
+
+// PageAccessMiddleware leaves the page access of the user for the page services to limit the request to,
+// handlers read it with helpers.GetPageAccessFromContext. Use after CheckPermissionMiddleware on the routes of pages.
+func PageAccessMiddleware(service services.CMSRoleServiceInterface) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+		userId, err := helpers.GetUserIDFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		role, _ := user["role"].(string)
+		access, err := service.FindPageAccess(role, userId)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check permissions",
+			})
+		}
+
+		c.Locals(helpers.PageAccessKey, access)
+		return c.Next()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/MadManJJ/cms-api/repositories"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 // CheckTokenMiddleware checks if the request has a valid JWT token in the Authorization header.
@@ -25,6 +26,13 @@ func CheckAnyTokenMiddleware(lineKey, normalKey string, repo repositories.CMSAut
 
 		actualToken := strings.TrimPrefix(token, "Bearer ")
 
+		// Preview links only open one content, never the CMS
+		if helpers.IsPreviewToken(actualToken) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid token",
+			})
+		}
+
 		// Try LineKey first
 		claims, err := helpers.ParseJWTWithKey(actualToken, lineKey)
 		if err == nil {
@@ -36,6 +44,11 @@ func CheckAnyTokenMiddleware(lineKey, normalKey string, repo repositories.CMSAut
 				})
 			}
 			claims["user_id"] = user.ID.String()
+			// LINE issues these tokens, so the role comes from the user instead of a claim
+			delete(claims, "role")
+			if user.Role != nil {
+				claims["role"] = user.Role.Name
+			}
 			c.Locals("user", claims)
 			c.Locals("token_type", "line")
 			return c.Next()
@@ -45,6 +58,24 @@ func CheckAnyTokenMiddleware(lineKey, normalKey string, repo repositories.CMSAut
 		// Try NormalKey if LineKey fails
 		claims, err = helpers.ParseJWTWithKey(actualToken, normalKey)
 		if err == nil {
+			userId, _ := claims["user_id"].(string)
+			id, err := uuid.Parse(userId)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Invalid token",
+				})
+			}
+			user, err := repo.FindUserById(id)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "User not found",
+				})
+			}
+			// The role of the claim is the one at login, a changed or taken role applies from the next request
+			delete(claims, "role")
+			if user.Role != nil {
+				claims["role"] = user.Role.Name
+			}
 			c.Locals("user", claims)
 			c.Locals("token_type", "normal")
 			return c.Next()
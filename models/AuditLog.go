@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// AuditLog is one mutating CMS request, rows are only ever inserted
+type AuditLog struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID     *uuid.UUID     `gorm:"type:uuid;index" json:"user_id"` // From the JWT, empty when the request carried no valid token
+	Action     string         `gorm:"type:varchar(50);not null;index" json:"action"`
+	Method     string         `gorm:"type:varchar(10);not null" json:"method"`
+	Path       string         `gorm:"not null" json:"path"` // Route pattern, e.g. /api/v1/cms/categories/:categoryUuid
+	EntityType string         `gorm:"type:varchar(50);not null" json:"entity_type"`
+	EntityID   *uuid.UUID     `gorm:"type:uuid" json:"entity_id"`
+	Before     datatypes.JSON `gorm:"type:jsonb" json:"before" swaggertype:"object"` // Stored row before the change, secrets redacted
+	After      datatypes.JSON `gorm:"type:jsonb" json:"after" swaggertype:"object"`  // Returned item after the change, secrets redacted
+	StatusCode int            `gorm:"not null" json:"status_code"`
+	IP         string         `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent  string         `json:"user_agent"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime;index" json:"created_at"`
+}
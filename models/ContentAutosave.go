@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ContentAutosave is the single overwritable draft slot of one user for one page and language
+type ContentAutosave struct {
+	ID            uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PageType      enums.PageType     `gorm:"not null;uniqueIndex:idx_content_autosaves_slot" json:"page_type"`
+	PageID        uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex:idx_content_autosaves_slot" json:"page_id"`
+	Language      enums.PageLanguage `gorm:"not null;uniqueIndex:idx_content_autosaves_slot" json:"language"`
+	UserID        uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex:idx_content_autosaves_slot" json:"user_id"`
+	User          *User              `gorm:"foreignKey:UserID" json:"-"`
+	BaseContentID uuid.UUID          `gorm:"type:uuid;not null" json:"base_content_id"` // The content version the editor was opened on
+	Payload       datatypes.JSON     `gorm:"type:jsonb;not null" json:"payload"`
+	CreatedAt     time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// ContentRelation points a content version to another page. New versions of the content copy the relations,
+// so they go through draft, approval and revert with the rest of the content.
+type ContentRelation struct {
+	ID              uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	SourcePageType  enums.PageType     `gorm:"not null" json:"source_page_type"`
+	SourcePageID    uuid.UUID          `gorm:"type:uuid;not null" json:"source_page_id"`
+	SourceContentID uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex:idx_content_relations_pair" json:"source_content_id"`
+	Language        enums.PageLanguage `gorm:"not null" json:"language"`
+	RelationType    enums.RelationType `gorm:"not null;uniqueIndex:idx_content_relations_pair" json:"relation_type"`
+	TargetPageType  enums.PageType     `gorm:"not null;uniqueIndex:idx_content_relations_pair" json:"target_page_type"`
+	TargetPageID    uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex:idx_content_relations_pair" json:"target_page_id"`
+	Position        int                `gorm:"not null" json:"position"` // Order within the relation type
+	CreatedAt       time.Time          `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// RelatedPage is a relation resolved to the published content of its target in the same language
+type RelatedPage struct {
+	RelationType enums.RelationType `json:"relation_type"`
+	PageType     enums.PageType     `json:"page_type"`
+	PageID       uuid.UUID          `json:"page_id"`
+	Title        string             `json:"title"`
+	URL          string             `json:"url"`
+	Thumbnail    string             `json:"thumbnail,omitempty"`
+}
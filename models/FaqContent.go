@@ -6,6 +6,7 @@ import (
 	"github.com/MadManJJ/cms-api/models/enums"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
 type FaqContent struct {
@@ -33,4 +34,8 @@ type FaqContent struct {
 	Revision   *Revision    `gorm:"foreignKey:FaqContentID" json:"revision,omitempty"`
 	Categories []*Category  `gorm:"many2many:faq_content_categories;joinForeignKey:FaqContentID;joinReferences:CategoryID" json:"categories,omitempty"`
 	Components []*Component `gorm:"foreignKey:FaqContentID" json:"components,omitempty"`
+
+	JSONLD       datatypes.JSON `gorm:"-" json:"json_ld,omitempty"`       // Computed for app responses only
+	RenderedHTML string         `gorm:"-" json:"rendered_html,omitempty"` // Computed for app responses with ?render=html
+	Related      []RelatedPage  `gorm:"-" json:"related,omitempty"`       // Computed for app responses only
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// FaqFeedback is one client's "Was this answer helpful?" vote on a faq page language, voting again replaces it
+type FaqFeedback struct {
+	ID           uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PageID       uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex:idx_faq_feedbacks_client" json:"page_id"`
+	Language     enums.PageLanguage `gorm:"type:varchar(10);not null;uniqueIndex:idx_faq_feedbacks_client" json:"language"`
+	ClientHash   string             `gorm:"type:varchar(64);not null;uniqueIndex:idx_faq_feedbacks_client" json:"-"` // Keyed hash of the client ip and user agent
+	FaqContentID uuid.UUID          `gorm:"type:uuid;not null;index" json:"faq_content_id"`                          // Content version the vote was cast on
+	Helpful      bool               `gorm:"not null" json:"helpful"`
+	Feedback     string             `gorm:"type:text" json:"feedback,omitempty"`
+	CreatedAt    time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"gorm.io/datatypes"
 )
 
 type LandingContent struct {
@@ -35,4 +36,8 @@ type LandingContent struct {
 	Revision      *Revision             `gorm:"foreignKey:LandingContentID" json:"revision,omitempty"`
 	Categories    []*Category           `gorm:"many2many:landing_content_categories;joinForeignKey:LandingContentID;joinReferences:CategoryID" json:"categories,omitempty"`
 	Components    []*Component          `gorm:"foreignKey:LandingContentID" json:"components,omitempty"`
+
+	JSONLD       datatypes.JSON `gorm:"-" json:"json_ld,omitempty"`       // Computed for app responses only
+	RenderedHTML string         `gorm:"-" json:"rendered_html,omitempty"` // Computed for app responses with ?render=html
+	Related      []RelatedPage  `gorm:"-" json:"related,omitempty"`       // Computed for app responses only
 }
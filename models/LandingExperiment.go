@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// LandingExperiment splits the visitors of one landing page language between variants of its published content
+type LandingExperiment struct {
+	ID              uuid.UUID              `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PageID          uuid.UUID              `gorm:"type:uuid;not null;index" json:"page_id"`
+	Language        enums.PageLanguage     `gorm:"type:varchar(10);not null" json:"language"`
+	Name            string                 `gorm:"not null" json:"name"`
+	Status          enums.ExperimentStatus `gorm:"type:varchar(20);not null" json:"status"`
+	WinnerVariantID *uuid.UUID             `gorm:"type:uuid" json:"winner_variant_id,omitempty"`
+	StartedAt       time.Time              `json:"started_at"`
+	EndedAt         *time.Time             `json:"ended_at,omitempty"`
+	CreatedAt       time.Time              `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time              `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Variants []*LandingExperimentVariant `gorm:"foreignKey:ExperimentID" json:"variants,omitempty"`
+}
+
+// LandingExperimentVariant is one arm of an experiment, the control serves the published content unchanged
+type LandingExperimentVariant struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ExperimentID uuid.UUID      `gorm:"type:uuid;not null;index" json:"experiment_id"`
+	Name         string         `gorm:"not null" json:"name"`
+	Weight       int            `gorm:"not null" json:"weight"` // Share of the traffic relative to the other variants
+	IsControl    bool           `gorm:"not null" json:"is_control"`
+	Content      datatypes.JSON `gorm:"type:jsonb" json:"content,omitempty"` // dto.LandingVariantContent served instead of the published one
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// LandingExperimentEvent is counted once per visitor and type, so rates are per visitor
+type LandingExperimentEvent struct {
+	ID             uuid.UUID                 `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ExperimentID   uuid.UUID                 `gorm:"type:uuid;not null;uniqueIndex:idx_landing_experiment_events_visitor" json:"experiment_id"`
+	VariantID      uuid.UUID                 `gorm:"type:uuid;not null;index" json:"variant_id"`
+	AssignmentHash string                    `gorm:"type:varchar(64);not null;uniqueIndex:idx_landing_experiment_events_visitor" json:"-"` // Keyed hash of the assignment cookie or LINE user id
+	Type           enums.ExperimentEventType `gorm:"type:varchar(20);not null;uniqueIndex:idx_landing_experiment_events_visitor" json:"type"`
+	CreatedAt      time.Time                 `gorm:"autoCreateTime" json:"created_at"`
+}
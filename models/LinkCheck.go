@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// LinkCheck is the last known status of one link found in a published content
+type LinkCheck struct {
+	ID            uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PageType      enums.PageType     `gorm:"not null" json:"page_type"`
+	PageID        uuid.UUID          `gorm:"type:uuid;not null;index" json:"page_id"`
+	ContentID     uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex:idx_link_checks_content_field_url" json:"content_id"`
+	Language      enums.PageLanguage `json:"language"`
+	Field         string             `gorm:"not null;uniqueIndex:idx_link_checks_content_field_url" json:"field"` // e.g. html_input, components[2]
+	URL           string             `gorm:"not null;uniqueIndex:idx_link_checks_content_field_url" json:"url"`
+	IsInternal    bool               `json:"is_internal"`
+	Status        enums.LinkStatus   `gorm:"not null;index" json:"status"`
+	StatusCode    int                `json:"status_code,omitempty"`
+	ErrorMessage  string             `json:"error_message,omitempty"`
+	LastCheckedAt time.Time          `json:"last_checked_at"`
+	CreatedAt     time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -4,13 +4,35 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
 type MetaTag struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Title       string    `json:"title,omitempty"`
-	Description string    `json:"description,omitempty"`
-	CoverImage  string    `json:"cover_image,omitempty"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                 uuid.UUID         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Title              string            `json:"title,omitempty"`
+	Description        string            `json:"description,omitempty"`
+	CoverImage         string            `json:"cover_image,omitempty"`
+	Robots             string            `json:"robots,omitempty"`        // e.g. "noindex,nofollow"
+	CanonicalURL       string            `json:"canonical_url,omitempty"` // Overrides the computed canonical
+	OGTitle            string            `json:"og_title,omitempty"`
+	OGDescription      string            `json:"og_description,omitempty"`
+	OGImage            string            `json:"og_image,omitempty"`
+	OGType             string            `json:"og_type,omitempty"`
+	TwitterCard        string            `json:"twitter_card,omitempty"`
+	TwitterTitle       string            `json:"twitter_title,omitempty"`
+	TwitterDescription string            `json:"twitter_description,omitempty"`
+	TwitterImage       string            `json:"twitter_image,omitempty"`
+	CustomMeta         datatypes.JSONMap `gorm:"type:jsonb" json:"custom_meta,omitempty"`     // Extra <meta name=key content=value>
+	StructuredData     datatypes.JSON    `gorm:"type:jsonb" json:"structured_data,omitempty"` // Replaces the generated JSON-LD
+	CreatedAt          time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Computed for app responses only, never stored
+	Alternates []HrefLangAlternate `gorm:"-" json:"alternates,omitempty"`
+}
+
+// HrefLangAlternate points to the same page in another published language
+type HrefLangAlternate struct {
+	HrefLang string `json:"hreflang"`
+	Href     string `json:"href"`
 }
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// OutboxEvent is a domain event written in the transaction of the change it describes,
+// so it exists if and only if the change was committed.
+type OutboxEvent struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primary_key" json:"id"`
+	EventType      enums.DomainEventType `gorm:"type:varchar(50);not null" json:"event_type"`
+	AggregateID    uuid.UUID             `gorm:"type:uuid;not null" json:"aggregate_id"`      // Page, submission or media file the event is about
+	IdempotencyKey string                `gorm:"not null;uniqueIndex" json:"idempotency_key"` // A second event with the same key is dropped
+	Payload        datatypes.JSON        `gorm:"type:jsonb;not null" json:"payload" swaggertype:"object"`
+	DispatchedAt   *time.Time            `json:"dispatched_at"` // Set once a delivery was queued for every subscriber
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+// OutboxDelivery is one event handled by one subscriber. The table is the retry queue,
+// pending deliveries are handled once NextAttemptAt has passed.
+type OutboxDelivery struct {
+	ID            uuid.UUID                  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EventID       uuid.UUID                  `gorm:"type:uuid;not null;uniqueIndex:idx_outbox_deliveries_event_subscriber" json:"event_id"`
+	Subscriber    string                     `gorm:"not null;uniqueIndex:idx_outbox_deliveries_event_subscriber" json:"subscriber"`
+	Status        enums.OutboxDeliveryStatus `gorm:"type:varchar(20);not null" json:"status"`
+	Attempts      int                        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time                  `gorm:"not null" json:"next_attempt_at"`
+	LastError     string                     `json:"last_error,omitempty"`
+	ProcessedAt   *time.Time                 `json:"processed_at"`
+	CreatedAt     time.Time                  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time                  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Event *OutboxEvent `gorm:"foreignKey:EventID" json:"event,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// PageGrant narrows the pages permission of a user's role to the pages of one type,
+// optionally in one language and one category. A user with grants only reaches the pages one of them matches.
+type PageGrant struct {
+	ID         uuid.UUID              `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID     uuid.UUID              `gorm:"type:uuid;not null;index" json:"user_id"`
+	PageType   enums.PageType         `gorm:"type:varchar(20);not null" json:"page_type"`
+	Language   *enums.PageLanguage    `gorm:"type:varchar(10)" json:"language,omitempty"` // Any language when empty
+	CategoryID *uuid.UUID             `gorm:"type:uuid" json:"category_id,omitempty"`     // Any category when empty
+	Action     enums.PermissionAction `gorm:"type:varchar(20);not null" json:"action"`
+	CreatedAt  time.Time              `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Matches reports whether the grant allows the action on a content of the page type in the language with the categories
+func (g *PageGrant) Matches(pageType enums.PageType, action enums.PermissionAction, language enums.PageLanguage, categoryIds []uuid.UUID) bool {
+	if g.PageType != pageType || (g.Action != action && g.Action != enums.PermissionActionAll) {
+		return false
+	}
+	if g.Language != nil && *g.Language != language {
+		return false
+	}
+	return g.CategoryID == nil || slices.Contains(categoryIds, *g.CategoryID)
+}
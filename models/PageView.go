@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// PageView is one raw view of a published page, kept until RawRetention after it is rolled up into PageViewDaily
+type PageView struct {
+	ID           uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PageType     enums.PageType     `gorm:"type:varchar(20);not null" json:"page_type"`
+	PageID       uuid.UUID          `gorm:"type:uuid;not null" json:"page_id"`
+	Language     enums.PageLanguage `gorm:"type:varchar(10);not null" json:"language"`
+	VisitorHash  string             `gorm:"type:varchar(64);not null" json:"-"` // Keyed hash of the day, client ip and user agent, changes every day
+	ReferrerHost string             `gorm:"type:varchar(255);not null;default:''" json:"referrer_host"`
+	UTMSource    string             `gorm:"column:utm_source;type:varchar(100);not null;default:''" json:"utm_source"`
+	UTMMedium    string             `gorm:"column:utm_medium;type:varchar(100);not null;default:''" json:"utm_medium"`
+	UTMCampaign  string             `gorm:"column:utm_campaign;type:varchar(100);not null;default:''" json:"utm_campaign"`
+	CreatedAt    time.Time          `gorm:"index" json:"created_at"`
+}
+
+// PageViewDaily counts the views of a page language per day, referrer and campaign, its visitors are in PageVisitorDaily
+type PageViewDaily struct {
+	ID           uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Day          time.Time          `gorm:"type:date;not null;index" json:"day"`
+	PageType     enums.PageType     `gorm:"type:varchar(20);not null" json:"page_type"`
+	PageID       uuid.UUID          `gorm:"type:uuid;not null;index" json:"page_id"`
+	Language     enums.PageLanguage `gorm:"type:varchar(10);not null" json:"language"`
+	ReferrerHost string             `gorm:"type:varchar(255);not null;default:''" json:"referrer_host"`
+	UTMSource    string             `gorm:"column:utm_source;type:varchar(100);not null;default:''" json:"utm_source"`
+	UTMMedium    string             `gorm:"column:utm_medium;type:varchar(100);not null;default:''" json:"utm_medium"`
+	UTMCampaign  string             `gorm:"column:utm_campaign;type:varchar(100);not null;default:''" json:"utm_campaign"`
+	Views        int64              `gorm:"not null" json:"views"`
+}
+
+// PageVisitorDaily keeps each visitor of a page language once per day and campaign, so the visitors of any group of rows
+// are counted distinct instead of summed
+type PageVisitorDaily struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Day         time.Time          `gorm:"type:date;not null;index" json:"day"`
+	PageType    enums.PageType     `gorm:"type:varchar(20);not null" json:"page_type"`
+	PageID      uuid.UUID          `gorm:"type:uuid;not null;index" json:"page_id"`
+	Language    enums.PageLanguage `gorm:"type:varchar(10);not null" json:"language"`
+	UTMSource   string             `gorm:"column:utm_source;type:varchar(100);not null;default:''" json:"utm_source"`
+	UTMMedium   string             `gorm:"column:utm_medium;type:varchar(100);not null;default:''" json:"utm_medium"`
+	UTMCampaign string             `gorm:"column:utm_campaign;type:varchar(100);not null;default:''" json:"utm_campaign"`
+	VisitorHash string             `gorm:"type:varchar(64);not null" json:"-"`
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"gorm.io/datatypes"
 )
 
 type PartnerContent struct {
@@ -46,4 +47,8 @@ type PartnerContent struct {
 	Revision      *Revision      `gorm:"foreignKey:PartnerContentID" json:"revision,omitempty"`
 	Categories    []*Category    `gorm:"many2many:partner_content_categories;joinForeignKey:PartnerContentID;joinReferences:CategoryID" json:"categories,omitempty"`
 	Components    []*Component   `gorm:"foreignKey:PartnerContentID" json:"components,omitempty"`
+
+	JSONLD       datatypes.JSON `gorm:"-" json:"json_ld,omitempty"`       // Computed for app responses only
+	RenderedHTML string         `gorm:"-" json:"rendered_html,omitempty"` // Computed for app responses with ?render=html
+	Related      []RelatedPage  `gorm:"-" json:"related,omitempty"`       // Computed for app responses only
 }
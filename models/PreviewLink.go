@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// PreviewLink is one issued preview token, its ID is the token id so the link can be revoked
+type PreviewLink struct {
+	ID           uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PageType     enums.PageType     `gorm:"not null" json:"page_type"`
+	PageID       uuid.UUID          `gorm:"type:uuid;not null;index" json:"page_id"`
+	ContentID    uuid.UUID          `gorm:"type:uuid;not null;index" json:"content_id"`
+	Language     enums.PageLanguage `json:"language"`
+	PasswordHash string             `json:"-"`
+	ExpiresAt    time.Time          `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time         `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time          `gorm:"autoCreateTime" json:"created_at"`
+}
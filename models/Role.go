@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// Role is a named set of permissions, a user has at most one and no role allows nothing
+type Role struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name        string           `gorm:"type:varchar(50);not null;uniqueIndex" json:"name"`
+	Description string           `json:"description"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID" json:"permissions,omitempty"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// RolePermission allows a role one action on one resource, "*" matches any
+type RolePermission struct {
+	ID       uuid.UUID                `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	RoleID   uuid.UUID                `gorm:"type:uuid;not null;uniqueIndex:idx_role_permissions_role_resource_action" json:"role_id"`
+	Resource enums.PermissionResource `gorm:"type:varchar(50);not null;uniqueIndex:idx_role_permissions_role_resource_action" json:"resource"`
+	Action   enums.PermissionAction   `gorm:"type:varchar(20);not null;uniqueIndex:idx_role_permissions_role_resource_action" json:"action"`
+}
+
+// Allows reports whether one of the permissions covers the action on the resource
+func (r *Role) Allows(resource enums.PermissionResource, action enums.PermissionAction) bool {
+	for _, permission := range r.Permissions {
+		if (permission.Resource == resource || permission.Resource == enums.PermissionResourceAll) &&
+			(permission.Action == action || permission.Action == enums.PermissionActionAll) {
+			return true
+		}
+	}
+	return false
+}
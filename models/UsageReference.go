@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// UsageReference is one row of the usage index, an item referred to by one field of a referrer.
+// The index is rebuilt from the contents, so rows are replaced rather than updated.
+type UsageReference struct {
+	ID           uuid.UUID               `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ItemType     enums.UsageItemType     `gorm:"not null;index:idx_usage_references_item" json:"item_type"`
+	ItemID       uuid.UUID               `gorm:"type:uuid;not null;index:idx_usage_references_item" json:"item_id"`
+	ReferrerType enums.UsageReferrerType `gorm:"not null" json:"referrer_type"`
+	ReferrerID   uuid.UUID               `gorm:"type:uuid;not null" json:"referrer_id"`
+	PageType     enums.PageType          `json:"page_type,omitempty"` // Set for content and relation referrers
+	PageID       *uuid.UUID              `gorm:"type:uuid" json:"page_id,omitempty"`
+	Language     enums.PageLanguage      `json:"language,omitempty"`
+	Label        string                  `json:"label"` // Title or name of the referrer
+	Field        string                  `gorm:"not null" json:"field"`
+	Published    bool                    `gorm:"not null" json:"published"` // The referrer is live on the site
+	IndexedAt    time.Time               `gorm:"not null" json:"indexed_at"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+// WebhookEndpoint receives the events it subscribes to, signed with its secret
+type WebhookEndpoint struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name       string         `gorm:"not null" json:"name"`
+	URL        string         `gorm:"not null" json:"url"`
+	Secret     string         `gorm:"not null" json:"-"` // Kept in clear, every delivery is signed with it
+	EventTypes pq.StringArray `gorm:"type:text[];not null" json:"event_types" swaggertype:"array,string"`
+	IsActive   bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one endpoint. The table is the retry queue,
+// pending deliveries are sent once NextAttemptAt has passed.
+type WebhookDelivery struct {
+	ID             uuid.UUID                   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EndpointID     uuid.UUID                   `gorm:"type:uuid;not null;index" json:"endpoint_id"`
+	EventID        uuid.UUID                   `gorm:"type:uuid;not null" json:"event_id"` // Shared by the deliveries of one event and their replays
+	EventType      enums.WebhookEventType      `gorm:"not null" json:"event_type"`
+	Payload        datatypes.JSON              `gorm:"type:jsonb;not null" json:"payload" swaggertype:"object"`
+	Status         enums.WebhookDeliveryStatus `gorm:"not null;index:idx_webhook_deliveries_due" json:"status"`
+	Attempts       int                         `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time                   `gorm:"not null;index:idx_webhook_deliveries_due" json:"next_attempt_at"`
+	LastStatusCode int                         `json:"last_status_code,omitempty"`
+	LastError      string                      `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time                  `json:"delivered_at,omitempty"`
+	ReplayOfID     *uuid.UUID                  `gorm:"type:uuid" json:"replay_of_id,omitempty"`
+	CreatedAt      time.Time                   `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time                   `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Endpoint    *WebhookEndpoint         `gorm:"foreignKey:EndpointID;constraint:OnDelete:CASCADE" json:"endpoint,omitempty"`
+	AttemptsLog []WebhookDeliveryAttempt `gorm:"foreignKey:DeliveryID" json:"attempts_log,omitempty"`
+}
+
+// WebhookDeliveryAttempt logs one request of a delivery and how the endpoint answered
+type WebhookDeliveryAttempt struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	DeliveryID   uuid.UUID `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	Attempt      int       `gorm:"not null" json:"attempt"`
+	StatusCode   int       `json:"status_code,omitempty"`   // 0 when no response was received
+	ResponseBody string    `json:"response_body,omitempty"` // Truncated
+	Error        string    `json:"error,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+	AttemptedAt  time.Time `gorm:"not null" json:"attempted_at"`
+}
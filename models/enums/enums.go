@@ -142,6 +142,169 @@ const (
 	ComponentGridContentsLfcFilter          ComponentType = "GridContentsLfcFilter"
 )
 
+// PageType represents the kinds of pages managed by the CMS.
+type PageType string
+
+const (
+	PageTypeLanding PageType = "landing"
+	PageTypePartner PageType = "partner"
+	PageTypeFaq     PageType = "faq"
+)
+
+// AuditSeverity represents how serious a content audit finding is.
+type AuditSeverity string
+
+const (
+	AuditSeverityCritical AuditSeverity = "critical"
+	AuditSeverityWarning  AuditSeverity = "warning"
+	AuditSeverityInfo     AuditSeverity = "info"
+)
+
+// LinkStatus represents the result of the last broken link check.
+type LinkStatus string
+
+const (
+	LinkStatusOK     LinkStatus = "ok"     // Reachable, or registered for internal links
+	LinkStatusBroken LinkStatus = "broken" // 4xx/5xx, or not registered for internal links
+	LinkStatusError  LinkStatus = "error"  // Timeout, DNS or connection failure
+)
+
+// CalendarEventType represents which schedule date of a content an editorial calendar event is for.
+type CalendarEventType string
+
+const (
+	CalendarEventPublish   CalendarEventType = "publish"   // PublishOn
+	CalendarEventUnpublish CalendarEventType = "unpublish" // UnpublishOn
+	CalendarEventExpire    CalendarEventType = "expire"    // ExpiredAt
+)
+
+// RenderMode represents how app endpoints deliver the components of a content.
+type RenderMode string
+
+const (
+	RenderModeJSON     RenderMode = ""          // Components as JSON only
+	RenderModeHTML     RenderMode = "html"      // Rendered HTML alongside the components
+	RenderModeHTMLOnly RenderMode = "html-only" // Rendered HTML instead of the components
+)
+
+// ExperimentStatus represents the lifecycle of a landing page A/B experiment.
+type ExperimentStatus string
+
+const (
+	ExperimentStatusRunning   ExperimentStatus = "running"   // Variants are assigned to visitors
+	ExperimentStatusCompleted ExperimentStatus = "completed" // Stopped, with or without a promoted winner
+)
+
+// ExperimentEventType represents what an experiment event counts.
+type ExperimentEventType string
+
+const (
+	ExperimentEventExposure   ExperimentEventType = "exposure"   // A variant was served to the visitor
+	ExperimentEventConversion ExperimentEventType = "conversion" // The visitor converted after being served
+)
+
+// RelationType represents how a content refers to a related page.
+type RelationType string
+
+const (
+	RelationRelatedArticle RelationType = "related_article" // Listed by RelatedArticles components
+	RelationRelatedLink    RelationType = "related_link"    // Listed by RelatedLinks components
+)
+
+// UsageItemType represents the kinds of items tracked by the usage index.
+type UsageItemType string
+
+const (
+	UsageItemCategory    UsageItemType = "category"
+	UsageItemMediaFile   UsageItemType = "media_file"
+	UsageItemLandingPage UsageItemType = "landing_page"
+	UsageItemPartnerPage UsageItemType = "partner_page"
+	UsageItemFaqPage     UsageItemType = "faq_page"
+)
+
+// UsageReferrerType represents what refers to an item in the usage index.
+type UsageReferrerType string
+
+const (
+	UsageReferrerContent      UsageReferrerType = "content"       // A landing, partner or faq content, including its components
+	UsageReferrerRelation     UsageReferrerType = "relation"      // A content relation of a page language
+	UsageReferrerCategory     UsageReferrerType = "category"      // The description of a category
+	UsageReferrerEmailContent UsageReferrerType = "email_content" // An email template
+	UsageReferrerForm         UsageReferrerType = "form"          // A form, its sections or its fields
+)
+
+// WebhookEventType represents the changes outgoing webhooks can subscribe to.
+type WebhookEventType string
+
+const (
+	WebhookEventContentPublished   WebhookEventType = "content.published"   // A landing, partner or faq content was saved as Published
+	WebhookEventContentUnpublished WebhookEventType = "content.unpublished" // A content was saved as UnPublished
+	WebhookEventContentDeleted     WebhookEventType = "content.deleted"     // A page, or one language of it, was deleted
+	WebhookEventFormSubmitted      WebhookEventType = "form.submitted"
+	WebhookEventMediaUploaded      WebhookEventType = "media.uploaded"
+)
+
+// WebhookDeliveryStatus represents where a webhook delivery is in its retries.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // Queued, or waiting for the next retry
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded" // The endpoint answered 2xx
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // Every attempt failed, only a replay sends it again
+)
+
+// DomainEventType represents the changes recorded in the outbox for the in-process subscribers.
+type DomainEventType string
+
+const (
+	DomainEventContentSaved          DomainEventType = "content.saved"           // A new version of a landing, partner or faq content was saved
+	DomainEventContentDeleted        DomainEventType = "content.deleted"         // A page, or one content of it, was deleted
+	DomainEventFormSubmissionCreated DomainEventType = "form_submission.created" // A form was submitted
+	DomainEventMediaFileUploaded     DomainEventType = "media_file.uploaded"
+	DomainEventMediaFileDeleted      DomainEventType = "media_file.deleted"
+	DomainEventEmailRequested        DomainEventType = "email.requested" // An email to send, queued by another subscriber
+)
+
+// OutboxDeliveryStatus represents where the handling of an event by one subscriber is.
+type OutboxDeliveryStatus string
+
+const (
+	OutboxDeliveryPending   OutboxDeliveryStatus = "pending"   // Queued, or waiting for the next retry
+	OutboxDeliverySucceeded OutboxDeliveryStatus = "succeeded" // The subscriber handled the event
+	OutboxDeliveryDead      OutboxDeliveryStatus = "dead"      // Every attempt failed, dead-lettered until retried by hand
+)
+
+// PermissionResource is what a role permission covers, every CMS route belongs to one. "*" is every resource.
+type PermissionResource string
+
+const (
+	PermissionResourceAll             PermissionResource = "*"
+	PermissionResourcePages           PermissionResource = "pages"      // Landing, partner and faq pages with their previews, autosaves, relations, experiments and link checks
+	PermissionResourceCategories      PermissionResource = "categories" // Category types and categories
+	PermissionResourceMedia           PermissionResource = "media"
+	PermissionResourceForms           PermissionResource = "forms"
+	PermissionResourceFormSubmissions PermissionResource = "form_submissions"
+	PermissionResourceEmails          PermissionResource = "emails"    // Email categories, email contents and sending emails
+	PermissionResourceAnalytics       PermissionResource = "analytics" // Page analytics and faq feedback
+	PermissionResourceWebhooks        PermissionResource = "webhooks"
+	PermissionResourceSystem          PermissionResource = "system" // Maintenance, the outbox and usage index rebuilds
+	PermissionResourceUsers           PermissionResource = "users"  // Accounts and their roles
+	PermissionResourceAuditLogs       PermissionResource = "audit_logs"
+)
+
+// PermissionAction is what a role may do to a resource. "*" is every action.
+type PermissionAction string
+
+const (
+	PermissionActionAll     PermissionAction = "*"
+	PermissionActionRead    PermissionAction = "read"
+	PermissionActionCreate  PermissionAction = "create"
+	PermissionActionUpdate  PermissionAction = "update"
+	PermissionActionDelete  PermissionAction = "delete"
+	PermissionActionPublish PermissionAction = "publish" // Move a content to a published or scheduled workflow status
+	PermissionActionSend    PermissionAction = "send"
+)
+
 type FormFieldType string
 
 const (
@@ -13,6 +13,8 @@ type User struct {
 	Email     *string            `json:"email,omitempty" validate:"omitempty,email" gorm:"uniqueIndex"`
 	Password  *string            `json:"password,omitempty" validate:"omitempty,min=6"`
 	Provider  enums.ProviderType `json:"provider" gorm:"type:varchar(20);default:'normal'"`
+	RoleID    *uuid.UUID         `json:"role_id,omitempty" gorm:"type:uuid"` // No role allows nothing in the CMS
+	Role      *Role              `json:"role,omitempty" gorm:"foreignKey:RoleID"`
 	CreatedAt time.Time          `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
 }
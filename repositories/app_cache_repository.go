@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+// AppCacheChannel is the Postgres channel the app response cache invalidations are sent on
+const AppCacheChannel = "app_cache_invalidations"
+
+type AppCacheRepositoryInterface interface {
+	NotifyInvalidation(tag string) error
+}
+
+type AppCacheRepository struct {
+	db *gorm.DB
+}
+
+func NewAppCacheRepository(db *gorm.DB) *AppCacheRepository {
+	return &AppCacheRepository{db: db}
+}
+
+// NotifyInvalidation sends the tag to every instance listening on the channel, this one included
+func (r *AppCacheRepository) NotifyInvalidation(tag string) error {
+	return r.db.Exec("SELECT pg_notify(?, ?)", AppCacheChannel, tag).Error
+}
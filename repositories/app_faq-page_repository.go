@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
@@ -13,8 +14,13 @@ import (
 )
 
 type AppFaqPageRepositoryInterface interface {
-	GetFaqPageBySlug(slug string, preloads []string, isAlias bool, language string) (*models.FaqPage, error)
-	GetFaqContentPreview(id uuid.UUID) (*models.FaqContent, error)	
+	GetFaqPageBySlug(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.FaqPage, error)
+	GetFaqContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.FaqContent, error)	
+	FindPublishedAlternates(pageId uuid.UUID) ([]models.FaqContent, error)
+	FindFaqCategoryCounts(language string, typeCode string) ([]dto.FaqCategoryCount, error)
+	FindFaqCategoryCount(language string, categoryId uuid.UUID) (*dto.FaqCategoryCount, error)
+	FindTopFaqSummaries(categoryIds []uuid.UUID, language string, perCategory int) ([]dto.FaqCategorySummary, error)
+	FindFaqSummariesByCategory(categoryId uuid.UUID, language string, page, limit int) ([]dto.FaqSummary, int64, error)
 }
 
 type AppFaqPageRepository struct {
@@ -25,28 +31,18 @@ func NewAppFaqPageRepository(db *gorm.DB) *AppFaqPageRepository {
 	return &AppFaqPageRepository{db: db}
 }
 
-func (r *AppFaqPageRepository) GetFaqPageBySlug(slug string, preloads []string, isAlias bool, language string) (*models.FaqPage, error) {
+func (r *AppFaqPageRepository) GetFaqPageBySlug(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.FaqPage, error) {
 	var faqPage models.FaqPage
 	query := r.db
 
 	query = query.
 		Preload("Contents", func(db *gorm.DB) *gorm.DB {
-				return db.
+				return selectProjected(db.
 						Where("faq_contents.workflow_status = ? AND faq_contents.language = ? AND faq_contents.mode != ?", enums.WorkflowPublished, language, "Histories").
-						Order("faq_contents.created_at DESC")
+						Order("faq_contents.created_at DESC"), projection)
 		})		
 
-	if len(preloads) == 0 {
-		query = query.
-							Preload("Contents.Revision").
-							Preload("Contents.Categories").
-							Preload("Contents.Components").
-							Preload("Contents.MetaTag")
-	} else {
-		for _, preload := range preloads {
-			query = query.Preload(preload)
-		}
-	}
+	query = preloadProjected(query, projection, "Contents.", []string{"Revision", "Categories", "Components", "MetaTag"})
 
 	// Correctly query using joined faq_contents
 	if isAlias {
@@ -72,12 +68,11 @@ func (r *AppFaqPageRepository) GetFaqPageBySlug(slug string, preloads []string,
 	return &faqPage, nil
 }
 
-func (r *AppFaqPageRepository) GetFaqContentPreview(id uuid.UUID) (*models.FaqContent, error) {
+func (r *AppFaqPageRepository) GetFaqContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.FaqContent, error) {
 	var faqContent models.FaqContent
 	// Exclude expired_at = null
-	err := r.db.
-				Preload("MetaTag").
-				Preload("Components").
+	query := preloadProjected(selectProjected(r.db, projection), projection, "", []string{"MetaTag", "Components"})
+	err := query.
 				Where("id = ? AND mode = ? AND (expired_at > ?)", id, "Preview", time.Now()).
 				First(&faqContent).Error
 
@@ -86,4 +81,134 @@ func (r *AppFaqPageRepository) GetFaqContentPreview(id uuid.UUID) (*models.FaqCo
 	}
 
 	return &faqContent, nil
-}
\ No newline at end of file
+}
+
+// FindPublishedAlternates returns the published contents of the page in every language, for the hreflang links of all of them
+func (r *AppFaqPageRepository) FindPublishedAlternates(pageId uuid.UUID) ([]models.FaqContent, error) {
+	var faqContents []models.FaqContent
+	err := r.db.
+				Select("language", "url", "url_alias").
+				Where("page_id = ? AND workflow_status = ? AND mode != ?", pageId, enums.WorkflowPublished, "Histories").
+				Find(&faqContents).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return faqContents, nil
+}
+
+// publishedFaqCondition limits faq_contents to the published contents of a language, its args are language then publishedFaqArgs
+const publishedFaqCondition = "faq_contents.language = ? AND faq_contents.workflow_status = ? AND faq_contents.mode NOT IN ?"
+
+var publishedFaqArgs = []interface{}{enums.WorkflowPublished, []enums.PageMode{enums.PageModeHistories, enums.PageModePreview}}
+
+// faqCategoryTypeCondition keeps the category types the faq pages use, categories are shared by every page type
+const faqCategoryTypeCondition = `EXISTS (SELECT 1 FROM faq_content_categories
+	JOIN categories AS faq_categories ON faq_categories.id = faq_content_categories.category_id
+	WHERE faq_categories.category_type_id = category_types.id)`
+
+// faqCategoryCounts selects the published categories of the language of faq category types, each with its number of published faqs
+func (r *AppFaqPageRepository) faqCategoryCounts(language string) *gorm.DB {
+	return r.db.Table("categories").
+		Select("category_types.id AS category_type_id, category_types.type_code, category_types.name AS category_type_name, "+
+			"categories.id AS category_id, categories.name, categories.description, categories.weight, COUNT(DISTINCT faq_contents.id) AS count").
+		Joins("JOIN category_types ON category_types.id = categories.category_type_id").
+		Joins("LEFT JOIN faq_content_categories ON faq_content_categories.category_id = categories.id").
+		Joins("LEFT JOIN faq_contents ON faq_contents.id = faq_content_categories.faq_content_id AND "+publishedFaqCondition,
+			append([]interface{}{language}, publishedFaqArgs...)...).
+		Where("categories.language_code = ? AND categories.publish_status = ? AND category_types.is_active = ?", language, enums.PublishStatusPublished, true).
+		Where(faqCategoryTypeCondition).
+		Group("category_types.id, category_types.type_code, category_types.name, categories.id, categories.name, categories.description, categories.weight")
+}
+
+// FindFaqCategoryCounts returns the published faq categories of the language, optionally of one category type,
+// each with its number of published faqs ordered by type, weight and name
+func (r *AppFaqPageRepository) FindFaqCategoryCounts(language string, typeCode string) ([]dto.FaqCategoryCount, error) {
+	counts := []dto.FaqCategoryCount{}
+
+	query := r.faqCategoryCounts(language)
+	if typeCode != "" {
+		query = query.Where("category_types.type_code = ?", typeCode)
+	}
+
+	err := query.
+		Order("category_types.type_code ASC, categories.weight ASC, categories.name ASC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// FindFaqCategoryCount returns one published faq category of the language with its number of published faqs
+func (r *AppFaqPageRepository) FindFaqCategoryCount(language string, categoryId uuid.UUID) (*dto.FaqCategoryCount, error) {
+	var count dto.FaqCategoryCount
+	result := r.faqCategoryCounts(language).
+		Where("categories.id = ?", categoryId).
+		Scan(&count)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &count, nil
+}
+
+// FindTopFaqSummaries returns up to perCategory of the most helpful published faqs of every category,
+// scored by their helpful minus not helpful votes, newest first among equal scores
+func (r *AppFaqPageRepository) FindTopFaqSummaries(categoryIds []uuid.UUID, language string, perCategory int) ([]dto.FaqCategorySummary, error) {
+	summaries := []dto.FaqCategorySummary{}
+	if len(categoryIds) == 0 || perCategory <= 0 {
+		return summaries, nil
+	}
+
+	query := `SELECT category_id, id, title, url, url_alias, publish_on FROM (
+		SELECT faq_content_categories.category_id, faq_contents.id, faq_contents.title, faq_contents.url, faq_contents.url_alias, faq_contents.publish_on,
+			ROW_NUMBER() OVER (PARTITION BY faq_content_categories.category_id
+				ORDER BY COALESCE(feedback_scores.score, 0) DESC, faq_contents.publish_on DESC, faq_contents.title ASC) AS position
+		FROM faq_contents JOIN faq_content_categories ON faq_content_categories.faq_content_id = faq_contents.id
+		LEFT JOIN (
+			SELECT page_id, COUNT(*) FILTER (WHERE helpful) - COUNT(*) FILTER (WHERE NOT helpful) AS score
+			FROM faq_feedbacks WHERE language = ? GROUP BY page_id
+		) AS feedback_scores ON feedback_scores.page_id = faq_contents.page_id
+		WHERE faq_content_categories.category_id IN ? AND ` + publishedFaqCondition + `
+	) AS ranked WHERE position <= ? ORDER BY category_id, position`
+
+	args := append([]interface{}{language, categoryIds, language}, publishedFaqArgs...)
+	if err := r.db.Raw(query, append(args, perCategory)...).Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// FindFaqSummariesByCategory returns one page of the published faqs of a category, newest first
+func (r *AppFaqPageRepository) FindFaqSummariesByCategory(categoryId uuid.UUID, language string, page, limit int) ([]dto.FaqSummary, int64, error) {
+	baseQuery := r.db.Model(&models.FaqContent{}).
+		Joins("JOIN faq_content_categories ON faq_content_categories.faq_content_id = faq_contents.id").
+		Where("faq_content_categories.category_id = ?", categoryId).
+		Where(publishedFaqCondition, append([]interface{}{language}, publishedFaqArgs...)...)
+
+	var totalCount int64
+	countQuery := baseQuery.Session(&gorm.Session{})
+	if err := countQuery.Distinct("faq_contents.id").Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	summaries := []dto.FaqSummary{}
+	err := baseQuery.
+		Select("faq_contents.id", "faq_contents.title", "faq_contents.url", "faq_contents.url_alias", "faq_contents.publish_on").
+		Order("faq_contents.publish_on DESC, faq_contents.title ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return summaries, totalCount, nil
+}
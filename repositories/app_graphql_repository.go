@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AppGraphQLRepositoryInterface loads the children of many contents at once, the GraphQL loaders batch through it
+type AppGraphQLRepositoryInterface interface {
+	FindComponentsByContentIDs(pageType enums.PageType, contentIds []uuid.UUID) ([]models.Component, error)
+	FindCategoriesByContentIDs(pageType enums.PageType, contentIds []uuid.UUID) ([]dto.ContentCategory, error)
+	FindCategoryTypesByIDs(ids []uuid.UUID) ([]models.CategoryType, error)
+}
+
+type AppGraphQLRepository struct {
+	db *gorm.DB
+}
+
+func NewAppGraphQLRepository(db *gorm.DB) *AppGraphQLRepository {
+	return &AppGraphQLRepository{db: db}
+}
+
+func (r *AppGraphQLRepository) FindComponentsByContentIDs(pageType enums.PageType, contentIds []uuid.UUID) ([]models.Component, error) {
+	tables, ok := contentTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	var components []models.Component
+	err := r.db.
+		Where(tables.foreignKey+" IN ?", contentIds).
+		Order("created_at, id").
+		Find(&components).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+// FindCategoriesByContentIDs returns the published categories of the contents, a category shared by two contents comes back twice
+func (r *AppGraphQLRepository) FindCategoriesByContentIDs(pageType enums.PageType, contentIds []uuid.UUID) ([]dto.ContentCategory, error) {
+	tables, ok := contentTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	var categories []dto.ContentCategory
+	err := r.db.
+		Table("categories").
+		Select("categories.*, "+tables.categories+"."+tables.foreignKey+" AS content_id").
+		Joins("JOIN "+tables.categories+" ON "+tables.categories+".category_id = categories.id").
+		Where(tables.categories+"."+tables.foreignKey+" IN ? AND categories.publish_status = ?", contentIds, enums.PublishStatusPublished).
+		Order("categories.weight, categories.name").
+		Scan(&categories).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *AppGraphQLRepository) FindCategoryTypesByIDs(ids []uuid.UUID) ([]models.CategoryType, error) {
+	var categoryTypes []models.CategoryType
+	if err := r.db.Where("id IN ?", ids).Find(&categoryTypes).Error; err != nil {
+		return nil, err
+	}
+
+	return categoryTypes, nil
+}
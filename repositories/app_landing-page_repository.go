@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
@@ -13,8 +14,9 @@ import (
 )
 
 type AppLandingPageRepositoryInterface interface {
-	GetLandingPageByUrlAlias(urlAlias string, preloads []string, language string) (*models.LandingPage, error)
-	GetLandingContentPreview(id uuid.UUID) (*models.LandingContent, error)
+	GetLandingPageByUrlAlias(urlAlias string, projection dto.ContentProjection, language string) (*models.LandingPage, error)
+	GetLandingContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.LandingContent, error)
+	FindPublishedAlternates(pageId uuid.UUID) ([]models.LandingContent, error)
 }
 
 type AppLandingPageRepository struct {
@@ -25,29 +27,18 @@ func NewAppLandingPageRepository(db *gorm.DB) *AppLandingPageRepository {
 	return &AppLandingPageRepository{db: db}
 }
 
-func (r *AppLandingPageRepository) GetLandingPageByUrlAlias(urlAlias string, preloads []string, language string) (*models.LandingPage, error) {
+func (r *AppLandingPageRepository) GetLandingPageByUrlAlias(urlAlias string, projection dto.ContentProjection, language string) (*models.LandingPage, error) {
 	var landingPage models.LandingPage
 	query := r.db
 
 	query = query.
 		Preload("Contents", func(db *gorm.DB) *gorm.DB {
-				return db.
+				return selectProjected(db.
 						Where("landing_contents.workflow_status = ? AND landing_contents.language = ? AND landing_contents.mode != ?", enums.WorkflowPublished, language, "Histories").
-						Order("landing_contents.created_at DESC")
+						Order("landing_contents.created_at DESC"), projection)
 		})
 
-	if len(preloads) == 0 {
-		query = query.
-							Preload("Contents.Files").
-							Preload("Contents.Revision").
-							Preload("Contents.Categories").
-							Preload("Contents.Components").
-							Preload("Contents.MetaTag")
-	} else {
-		for _, preload := range preloads {
-			query = query.Preload(preload)
-		}
-	}
+	query = preloadProjected(query, projection, "Contents.", []string{"Files", "Revision", "Categories", "Components", "MetaTag"})
 
 	// find landing page by url_alias
 	query = query.
@@ -65,13 +56,11 @@ func (r *AppLandingPageRepository) GetLandingPageByUrlAlias(urlAlias string, pre
 	return &landingPage, nil
 }
 
-func (r *AppLandingPageRepository) GetLandingContentPreview(id uuid.UUID) (*models.LandingContent, error) {
+func (r *AppLandingPageRepository) GetLandingContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.LandingContent, error) {
 	var landingContent models.LandingContent
 	// Exclude expired_at = null
-	err := r.db.
-				Preload("Files").
-				Preload("MetaTag").
-				Preload("Components").	
+	query := preloadProjected(selectProjected(r.db, projection), projection, "", []string{"Files", "MetaTag", "Components"})
+	err := query.
 				Where("id = ? AND mode = ? AND (expired_at > ?)", id, "Preview", time.Now()).
 				First(&landingContent).Error
 
@@ -80,4 +69,19 @@ func (r *AppLandingPageRepository) GetLandingContentPreview(id uuid.UUID) (*mode
 	}
 
 	return &landingContent, nil
+}
+
+// FindPublishedAlternates returns the published contents of the page in every language, for the hreflang links of all of them
+func (r *AppLandingPageRepository) FindPublishedAlternates(pageId uuid.UUID) ([]models.LandingContent, error) {
+	var landingContents []models.LandingContent
+	err := r.db.
+				Select("language", "url_alias").
+				Where("page_id = ? AND workflow_status = ? AND mode != ?", pageId, enums.WorkflowPublished, "Histories").
+				Find(&landingContents).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return landingContents, nil
 }
\ No newline at end of file
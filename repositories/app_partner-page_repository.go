@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
@@ -13,8 +15,11 @@ import (
 )
 
 type AppPartnerPageRepositoryInterface interface {
-	GetPartnerPageBySlug(slug string, preloads []string, isAlias bool, language string) (*models.PartnerPage, error)
-	GetPartnerContentPreview(id uuid.UUID) (*models.PartnerContent, error)
+	GetPartnerPageBySlug(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.PartnerPage, error)
+	GetPartnerContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.PartnerContent, error)
+	FindPublishedAlternates(pageId uuid.UUID) ([]models.PartnerContent, error)
+	FindPartnerListing(query dto.PartnerListingQuery) ([]models.PartnerContent, int64, error)
+	FindPartnerListingFacets(query dto.PartnerListingQuery) (*dto.PartnerListingFacets, error)
 }
 
 type AppPartnerPageRepository struct {
@@ -25,28 +30,18 @@ func NewAppPartnerPageRepository(db *gorm.DB) *AppPartnerPageRepository {
 	return &AppPartnerPageRepository{db: db}
 }
 
-func (r *AppPartnerPageRepository) GetPartnerPageBySlug(slug string, preloads []string, isAlias bool, language string) (*models.PartnerPage, error) {
+func (r *AppPartnerPageRepository) GetPartnerPageBySlug(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.PartnerPage, error) {
 	var partnerPage models.PartnerPage
 	query := r.db
 
 	query = query.
 		Preload("Contents", func(db *gorm.DB) *gorm.DB {
-				return db.
+				return selectProjected(db.
 						Where("partner_contents.workflow_status = ? AND partner_contents.language = ? AND partner_contents.mode != ?", enums.WorkflowPublished, language, "Histories").
-						Order("partner_contents.created_at DESC")
+						Order("partner_contents.created_at DESC"), projection)
 		})
 
-	if len(preloads) == 0 {
-		query = query.
-							Preload("Contents.Revision").
-							Preload("Contents.Categories").
-							Preload("Contents.Components").
-							Preload("Contents.MetaTag")
-	} else {
-		for _, preload := range preloads {
-			query = query.Preload(preload)
-		}
-	}	
+	query = preloadProjected(query, projection, "Contents.", []string{"Revision", "Categories", "Components", "MetaTag"})
 	
 	// can query for both url_alias and url
 	if isAlias {
@@ -71,12 +66,11 @@ func (r *AppPartnerPageRepository) GetPartnerPageBySlug(slug string, preloads []
 	return &partnerPage, nil	
 }
 
-func (r *AppPartnerPageRepository) GetPartnerContentPreview(id uuid.UUID) (*models.PartnerContent, error) {
+func (r *AppPartnerPageRepository) GetPartnerContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.PartnerContent, error) {
 	var partnerContent models.PartnerContent
 	// Exclude expired_at = null
-	err := r.db.
-				Preload("MetaTag").
-				Preload("Components").	
+	query := preloadProjected(selectProjected(r.db, projection), projection, "", []string{"MetaTag", "Components"})
+	err := query.
 				Where("id = ? AND mode = ? AND (expired_at > ?)", id, "Preview", time.Now()).
 				First(&partnerContent).Error
 
@@ -85,4 +79,185 @@ func (r *AppPartnerPageRepository) GetPartnerContentPreview(id uuid.UUID) (*mode
 	}
 
 	return &partnerContent, nil
-}
\ No newline at end of file
+}
+
+// FindPublishedAlternates returns the published contents of the page in every language, for the hreflang links of all of them
+func (r *AppPartnerPageRepository) FindPublishedAlternates(pageId uuid.UUID) ([]models.PartnerContent, error) {
+	var partnerContents []models.PartnerContent
+	err := r.db.
+				Select("language", "url", "url_alias").
+				Where("page_id = ? AND workflow_status = ? AND mode != ?", pageId, enums.WorkflowPublished, "Histories").
+				Find(&partnerContents).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return partnerContents, nil
+}
+
+var partnerListingOrders = map[string]string{
+	dto.PartnerSortRecommended: "partner_contents.is_recommended DESC, partner_contents.publish_on DESC, partner_contents.title ASC",
+	dto.PartnerSortPublishDesc: "partner_contents.publish_on DESC, partner_contents.title ASC",
+	dto.PartnerSortPublishAsc:  "partner_contents.publish_on ASC, partner_contents.title ASC",
+	dto.PartnerSortTitleAsc:    "partner_contents.title ASC, partner_contents.publish_on DESC",
+	dto.PartnerSortTitleDesc:   "partner_contents.title DESC, partner_contents.publish_on DESC",
+}
+
+// FindPartnerListing returns one page of published partner cards matching the query and the total count
+func (r *AppPartnerPageRepository) FindPartnerListing(query dto.PartnerListingQuery) ([]models.PartnerContent, int64, error) {
+	categoryScope, err := r.partnerCategoryScope(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	baseQuery := r.db.Model(&models.PartnerContent{}).
+		Scopes(partnerListingScope(query), partnerRecommendedScope(query), categoryScope)
+
+	var totalCount int64
+	countQuery := baseQuery.Session(&gorm.Session{})
+	if err := countQuery.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := partnerListingOrders[query.Sort]
+	if !ok {
+		order = partnerListingOrders[dto.PartnerSortRecommended]
+	}
+
+	var partnerContents []models.PartnerContent
+	err = baseQuery.
+		Select(partnerCardColumns(query.Fields)).
+		Order(order).
+		Offset((query.Page - 1) * query.Limit).
+		Limit(query.Limit).
+		Find(&partnerContents).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return partnerContents, totalCount, nil
+}
+
+// partnerCardColumns selects the card fields asked for, the card fields have columns of the same name
+func partnerCardColumns(fields []string) []string {
+	if len(fields) == 0 {
+		fields = []string{"title", "thumbnail_image", "thumbnail_alt_text", "company_logo", "company_alt_text", "company_name", "lead_body",
+			"url", "url_alias", "is_recommended", "publish_on"}
+	}
+
+	columns := []string{"partner_contents.id"}
+	for _, field := range fields {
+		if field != "id" {
+			columns = append(columns, "partner_contents."+field)
+		}
+	}
+	return columns
+}
+
+// FindPartnerListingFacets counts the published partner contents per category and the recommended ones,
+// each count leaves out its own filter so the other options stay selectable
+func (r *AppPartnerPageRepository) FindPartnerListingFacets(query dto.PartnerListingQuery) (*dto.PartnerListingFacets, error) {
+	categoryScope, err := r.partnerCategoryScope(query)
+	if err != nil {
+		return nil, err
+	}
+
+	facets := dto.PartnerListingFacets{Categories: []dto.PartnerCategoryFacet{}}
+
+	err = r.db.Model(&models.PartnerContent{}).
+		Scopes(partnerListingScope(query), partnerRecommendedScope(query)).
+		Select("categories.id AS category_id, categories.name, categories.category_type_id, category_types.type_code, COUNT(DISTINCT partner_contents.id) AS count").
+		Joins("JOIN partner_content_categories ON partner_content_categories.partner_content_id = partner_contents.id").
+		Joins("JOIN categories ON categories.id = partner_content_categories.category_id").
+		Joins("JOIN category_types ON category_types.id = categories.category_type_id").
+		Where("categories.publish_status = ?", enums.PublishStatusPublished).
+		Group("categories.id, categories.name, categories.category_type_id, category_types.type_code, categories.weight").
+		Order("category_types.type_code ASC, categories.weight ASC, categories.name ASC").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&models.PartnerContent{}).
+		Scopes(partnerListingScope(query), categoryScope).
+		Where("partner_contents.is_recommended = ?", true).
+		Count(&facets.Recommended).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &facets, nil
+}
+
+// partnerListingScope limits to the published contents of the language matching the text query
+func partnerListingScope(query dto.PartnerListingQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("partner_contents.language = ? AND partner_contents.workflow_status = ? AND partner_contents.mode NOT IN ?",
+			query.Language, enums.WorkflowPublished, []enums.PageMode{enums.PageModeHistories, enums.PageModePreview})
+
+		if query.Q != "" {
+			pattern := "%" + escapeLikePattern(query.Q) + "%"
+			db = db.Where(`partner_contents.title ILIKE ? ESCAPE '\' OR partner_contents.company_name ILIKE ? ESCAPE '\' OR partner_contents.lead_body ILIKE ? ESCAPE '\'`,
+				pattern, pattern, pattern)
+		}
+		return db
+	}
+}
+
+// escapeLikePattern makes the wildcards of a text query match literally
+func escapeLikePattern(q string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(q)
+}
+
+func partnerRecommendedScope(query dto.PartnerListingQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.IsRecommended != nil {
+			db = db.Where("partner_contents.is_recommended = ?", *query.IsRecommended)
+		}
+		return db
+	}
+}
+
+// partnerCategoryScope ORs the selected categories of the same type, types are then combined per CategoryMatch
+func (r *AppPartnerPageRepository) partnerCategoryScope(query dto.PartnerListingQuery) (func(db *gorm.DB) *gorm.DB, error) {
+	if len(query.CategoryIDs) == 0 {
+		return func(db *gorm.DB) *gorm.DB { return db }, nil
+	}
+
+	const hasCategory = "EXISTS (SELECT 1 FROM partner_content_categories WHERE partner_content_categories.partner_content_id = partner_contents.id AND partner_content_categories.category_id IN ?)"
+
+	if query.CategoryMatch == dto.CategoryMatchAny {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where(hasCategory, query.CategoryIDs)
+		}, nil
+	}
+
+	var categories []models.Category
+	if err := r.db.Select("id", "category_type_id").Where("id IN ?", query.CategoryIDs).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var groups [][]uuid.UUID
+	groupIndex := map[uuid.UUID]int{}
+	for _, category := range categories {
+		index, ok := groupIndex[category.CategoryTypeID]
+		if !ok {
+			index = len(groups)
+			groupIndex[category.CategoryTypeID] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], category.ID)
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		// Unknown categories can never match
+		if len(categories) < len(query.CategoryIDs) {
+			return db.Where("1 = 0")
+		}
+		for _, group := range groups {
+			db = db.Where(hasCategory, group)
+		}
+		return db
+	}, nil
+}
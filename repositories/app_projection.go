@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"sort"
+
+	"github.com/MadManJJ/cms-api/dto"
+
+	"gorm.io/gorm"
+)
+
+// preloadProjected preloads the relations of a projection, or the defaults for the zero projection.
+// prefix is "Contents." when the contents themselves are preloaded from their page.
+func preloadProjected(query *gorm.DB, projection dto.ContentProjection, prefix string, defaults []string) *gorm.DB {
+	if projection.Preloads == nil {
+		for _, preload := range defaults {
+			query = query.Preload(prefix + preload)
+		}
+		return query
+	}
+
+	// Sorted so the same projection always runs the same queries
+	preloads := make([]string, 0, len(projection.Preloads))
+	for preload := range projection.Preloads {
+		preloads = append(preloads, preload)
+	}
+	sort.Strings(preloads)
+
+	for _, preload := range preloads {
+		columns := projection.Preloads[preload]
+		if len(columns) == 0 {
+			query = query.Preload(prefix + preload)
+			continue
+		}
+		query = query.Preload(prefix+preload, func(db *gorm.DB) *gorm.DB {
+			return db.Select(columns)
+		})
+	}
+	return query
+}
+
+// selectProjected narrows a content query to the projected columns
+func selectProjected(query *gorm.DB, projection dto.ContentProjection) *gorm.DB {
+	if len(projection.Columns) == 0 {
+		return query
+	}
+	return query.Select(projection.Columns)
+}
@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"gorm.io/gorm"
+)
+
+// Rows per INSERT when writing page views, keeps a drained buffer under the bind parameter limit
+const pageViewInsertBatch = 1000
+
+type CMSAnalyticsRepositoryInterface interface {
+	CreatePageViews(views []*models.PageView) error
+	FindEarliestPageView() (*time.Time, error)
+	FindLatestRollupDay() (*time.Time, error)
+	RollupDay(day time.Time) error
+	DeletePageViewsBefore(before time.Time) (int64, error)
+	FindPageViewSeries(query dto.AnalyticsQuery) ([]dto.PageViewPoint, error)
+	CountVisitors(query dto.AnalyticsQuery) (int64, error)
+	FindTopReferrers(query dto.AnalyticsQuery, limit int) ([]dto.ReferrerTraffic, error)
+	FindTopPages(query dto.AnalyticsQuery, limit int) ([]dto.TopPage, error)
+	FindCampaignTraffic(query dto.AnalyticsQuery, limit int) ([]dto.CampaignTraffic, error)
+}
+
+type CMSAnalyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSAnalyticsRepository(db *gorm.DB) *CMSAnalyticsRepository {
+	return &CMSAnalyticsRepository{db: db}
+}
+
+func (r *CMSAnalyticsRepository) CreatePageViews(views []*models.PageView) error {
+	if len(views) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(views, pageViewInsertBatch).Error
+}
+
+// FindEarliestPageView returns nil when there are no page views
+func (r *CMSAnalyticsRepository) FindEarliestPageView() (*time.Time, error) {
+	var earliest sql.NullTime
+	if err := r.db.Model(&models.PageView{}).Select("MIN(created_at)").Scan(&earliest).Error; err != nil {
+		return nil, err
+	}
+	if !earliest.Valid {
+		return nil, nil
+	}
+	return &earliest.Time, nil
+}
+
+// FindLatestRollupDay returns nil when nothing was rolled up yet
+func (r *CMSAnalyticsRepository) FindLatestRollupDay() (*time.Time, error) {
+	var latest sql.NullTime
+	if err := r.db.Model(&models.PageViewDaily{}).Select("MAX(day)").Scan(&latest).Error; err != nil {
+		return nil, err
+	}
+	if !latest.Valid {
+		return nil, nil
+	}
+	return &latest.Time, nil
+}
+
+// RollupDay replaces the daily rows of the UTC day with counts of its raw page views, so it can be re-run.
+// The distinct visitors of the day are kept apart, a sum of per row distinct counts would count a visitor once per row.
+func (r *CMSAnalyticsRepository) RollupDay(day time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("day = ?", day).Delete(&models.PageViewDaily{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("day = ?", day).Delete(&models.PageVisitorDaily{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("INSERT INTO page_view_dailies (day, page_type, page_id, language, referrer_host, utm_source, utm_medium, utm_campaign, views) "+
+			"SELECT CAST(? AS date), page_type, page_id, language, referrer_host, utm_source, utm_medium, utm_campaign, COUNT(*) "+
+			"FROM page_views WHERE created_at >= ? AND created_at < ? "+
+			"GROUP BY page_type, page_id, language, referrer_host, utm_source, utm_medium, utm_campaign",
+			day, day, day.Add(24*time.Hour)).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("INSERT INTO page_visitor_dailies (day, page_type, page_id, language, utm_source, utm_medium, utm_campaign, visitor_hash) "+
+			"SELECT DISTINCT CAST(? AS date), page_type, page_id, language, utm_source, utm_medium, utm_campaign, visitor_hash "+
+			"FROM page_views WHERE created_at >= ? AND created_at < ?",
+			day, day, day.Add(24*time.Hour)).Error
+	})
+}
+
+func (r *CMSAnalyticsRepository) DeletePageViewsBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.PageView{})
+	return result.RowsAffected, result.Error
+}
+
+// analyticsScope filters the daily rows of the table in the range, optionally of one page type, language and page
+func analyticsScope(table string, query dto.AnalyticsQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where(table+".day >= ? AND "+table+".day <= ?", query.From, query.To)
+		if query.PageType != "" {
+			db = db.Where(table+".page_type = ?", query.PageType)
+		}
+		if query.Language != "" {
+			db = db.Where(table+".language = ?", query.Language)
+		}
+		if query.PageID != nil {
+			db = db.Where(table+".page_id = ?", *query.PageID)
+		}
+		return db
+	}
+}
+
+// joinVisitors joins the distinct visitors of the range per group as page_visitors.visitors, counted over the visitor rows
+// of the group so a visitor seen in several daily rows of the group is counted once
+func (r *CMSAnalyticsRepository) joinVisitors(query dto.AnalyticsQuery, columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		visitors := r.db.Model(&models.PageVisitorDaily{}).
+			Scopes(analyticsScope("page_visitor_dailies", query)).
+			Select(strings.Join(columns, ", ") + ", COUNT(DISTINCT visitor_hash) AS visitors").
+			Group(strings.Join(columns, ", "))
+
+		conditions := make([]string, 0, len(columns))
+		for _, column := range columns {
+			conditions = append(conditions, "page_visitors."+column+" = page_view_dailies."+column)
+		}
+		return db.Joins("LEFT JOIN (?) AS page_visitors ON "+strings.Join(conditions, " AND "), visitors)
+	}
+}
+
+func (r *CMSAnalyticsRepository) FindPageViewSeries(query dto.AnalyticsQuery) ([]dto.PageViewPoint, error) {
+	points := []dto.PageViewPoint{}
+	err := r.db.Model(&models.PageViewDaily{}).
+		Scopes(analyticsScope("page_view_dailies", query), r.joinVisitors(query, "day")).
+		Select("page_view_dailies.day, SUM(page_view_dailies.views) AS views, COALESCE(MAX(page_visitors.visitors), 0) AS visitors").
+		Group("page_view_dailies.day").
+		Order("page_view_dailies.day").
+		Scan(&points).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// CountVisitors counts the distinct visitors of the range, the visitor hash changes every day so a visitor is counted once per day
+func (r *CMSAnalyticsRepository) CountVisitors(query dto.AnalyticsQuery) (int64, error) {
+	var visitors int64
+	err := r.db.Model(&models.PageVisitorDaily{}).
+		Scopes(analyticsScope("page_visitor_dailies", query)).
+		Select("COUNT(DISTINCT page_visitor_dailies.visitor_hash)").
+		Scan(&visitors).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return visitors, nil
+}
+
+func (r *CMSAnalyticsRepository) FindTopReferrers(query dto.AnalyticsQuery, limit int) ([]dto.ReferrerTraffic, error) {
+	referrers := []dto.ReferrerTraffic{}
+	err := r.db.Model(&models.PageViewDaily{}).
+		Scopes(analyticsScope("page_view_dailies", query)).
+		Where("page_view_dailies.referrer_host <> ''").
+		Select("page_view_dailies.referrer_host, SUM(page_view_dailies.views) AS views").
+		Group("page_view_dailies.referrer_host").
+		Order("views DESC").
+		Limit(limit).
+		Scan(&referrers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return referrers, nil
+}
+
+// pageTitleSelect picks the title of the latest current content of the row's page and language from the table of its page type,
+// the columns name where the row keeps its page type, page id and language
+func pageTitleSelect(pageTypeColumn, pageIdColumn, languageColumn string) (string, []interface{}) {
+	modes := []enums.PageMode{enums.PageModeHistories, enums.PageModePreview}
+	tables := []struct {
+		pageType enums.PageType
+		table    string
+	}{
+		{enums.PageTypeLanding, "landing_contents"},
+		{enums.PageTypePartner, "partner_contents"},
+		{enums.PageTypeFaq, "faq_contents"},
+	}
+
+	var query strings.Builder
+	args := []interface{}{}
+	query.WriteString("CASE " + pageTypeColumn)
+	for _, t := range tables {
+		fmt.Fprintf(&query, " WHEN ? THEN (SELECT %[1]s.title FROM %[1]s WHERE %[1]s.page_id = %[2]s "+
+			"AND %[1]s.language = %[3]s AND %[1]s.mode NOT IN ? ORDER BY %[1]s.updated_at DESC LIMIT 1)", t.table, pageIdColumn, languageColumn)
+		args = append(args, t.pageType, modes)
+	}
+	query.WriteString(" END")
+
+	return query.String(), args
+}
+
+func (r *CMSAnalyticsRepository) FindTopPages(query dto.AnalyticsQuery, limit int) ([]dto.TopPage, error) {
+	titleSelect, titleArgs := pageTitleSelect("page_view_dailies.page_type", "page_view_dailies.page_id", "page_view_dailies.language")
+
+	pages := []dto.TopPage{}
+	err := r.db.Model(&models.PageViewDaily{}).
+		Scopes(analyticsScope("page_view_dailies", query), r.joinVisitors(query, "page_type", "page_id", "language")).
+		Select("page_view_dailies.page_type, page_view_dailies.page_id, page_view_dailies.language, "+titleSelect+" AS title, "+
+			"SUM(page_view_dailies.views) AS views, COALESCE(MAX(page_visitors.visitors), 0) AS visitors", titleArgs...).
+		Group("page_view_dailies.page_type, page_view_dailies.page_id, page_view_dailies.language").
+		Order("views DESC").
+		Limit(limit).
+		Scan(&pages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+func (r *CMSAnalyticsRepository) FindCampaignTraffic(query dto.AnalyticsQuery, limit int) ([]dto.CampaignTraffic, error) {
+	campaigns := []dto.CampaignTraffic{}
+	err := r.db.Model(&models.PageViewDaily{}).
+		Scopes(analyticsScope("page_view_dailies", query), r.joinVisitors(query, "utm_source", "utm_medium", "utm_campaign")).
+		Where("page_view_dailies.utm_campaign <> ''").
+		Select("page_view_dailies.utm_source, page_view_dailies.utm_medium, page_view_dailies.utm_campaign, " +
+			"SUM(page_view_dailies.views) AS views, COALESCE(MAX(page_visitors.visitors), 0) AS visitors, COUNT(DISTINCT page_view_dailies.page_id) AS pages").
+		Group("page_view_dailies.utm_source, page_view_dailies.utm_medium, page_view_dailies.utm_campaign").
+		Order("views DESC").
+		Limit(limit).
+		Scan(&campaigns).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return campaigns, nil
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CMSAuditLogRepositoryInterface is append-only, there is no way to change or remove a written entry
+type CMSAuditLogRepositoryInterface interface {
+	CreateAuditLog(auditLog *models.AuditLog) error
+	FindAuditLogs(query dto.AuditLogQuery, page, limit int) ([]models.AuditLog, int64, error)
+	FindAuditLogByID(id uuid.UUID) (*models.AuditLog, error)
+	FindAuditLogsInBatches(query dto.AuditLogQuery, batchSize int, fn func(auditLogs []models.AuditLog) error) error
+	FindRowSnapshot(table string, id uuid.UUID) (datatypes.JSON, error)
+}
+
+type CMSAuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSAuditLogRepository(db *gorm.DB) *CMSAuditLogRepository {
+	return &CMSAuditLogRepository{db: db}
+}
+
+func (r *CMSAuditLogRepository) CreateAuditLog(auditLog *models.AuditLog) error {
+	return r.db.Create(auditLog).Error
+}
+
+func (r *CMSAuditLogRepository) filter(query dto.AuditLogQuery) *gorm.DB {
+	baseQuery := r.db.Model(&models.AuditLog{})
+	if query.UserID != nil {
+		baseQuery = baseQuery.Where("user_id = ?", *query.UserID)
+	}
+	if query.Action != "" {
+		baseQuery = baseQuery.Where("action = ?", query.Action)
+	}
+	if query.EntityType != "" {
+		baseQuery = baseQuery.Where("entity_type = ?", query.EntityType)
+	}
+	if query.EntityID != nil {
+		baseQuery = baseQuery.Where("entity_id = ?", *query.EntityID)
+	}
+	if !query.From.IsZero() {
+		baseQuery = baseQuery.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		baseQuery = baseQuery.Where("created_at <= ?", query.To)
+	}
+	return baseQuery
+}
+
+func (r *CMSAuditLogRepository) FindAuditLogs(query dto.AuditLogQuery, page, limit int) ([]models.AuditLog, int64, error) {
+	var auditLogs []models.AuditLog
+	var totalCount int64
+
+	if err := r.filter(query).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.filter(query).
+		Order("created_at DESC, id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&auditLogs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return auditLogs, totalCount, nil
+}
+
+func (r *CMSAuditLogRepository) FindAuditLogByID(id uuid.UUID) (*models.AuditLog, error) {
+	var auditLog models.AuditLog
+	if err := r.db.First(&auditLog, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &auditLog, nil
+}
+
+// FindAuditLogsInBatches walks the matching entries oldest first, so an export never holds the whole log in memory
+func (r *CMSAuditLogRepository) FindAuditLogsInBatches(query dto.AuditLogQuery, batchSize int, fn func(auditLogs []models.AuditLog) error) error {
+	var last *models.AuditLog
+	for {
+		batchQuery := r.filter(query)
+		if last != nil {
+			// Keyset paging, entries written while exporting are picked up instead of shifting the pages
+			batchQuery = batchQuery.Where("(created_at, id) > (?, ?)", last.CreatedAt, last.ID)
+		}
+
+		var auditLogs []models.AuditLog
+		if err := batchQuery.Order("created_at ASC, id ASC").Limit(batchSize).Find(&auditLogs).Error; err != nil {
+			return err
+		}
+		if len(auditLogs) == 0 {
+			return nil
+		}
+		if err := fn(auditLogs); err != nil {
+			return err
+		}
+		if len(auditLogs) < batchSize {
+			return nil
+		}
+		last = &auditLogs[len(auditLogs)-1]
+	}
+}
+
+// FindRowSnapshot returns the row with the id as JSON, or nil when there is none.
+// The table name comes from the route setup, never from a request.
+func (r *CMSAuditLogRepository) FindRowSnapshot(table string, id uuid.UUID) (datatypes.JSON, error) {
+	var snapshot datatypes.JSON
+	err := r.db.Raw("SELECT row_to_json(t) FROM ? t WHERE t.id = ?", clause.Table{Name: table}, id).Row().Scan(&snapshot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
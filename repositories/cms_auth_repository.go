@@ -37,7 +37,7 @@ func (r *CMSAuthRepository) RegisterUser(user *models.User) (*models.User, error
 // FindUserByEmail implements the CMSAuthRepository interface
 func (r *CMSAuthRepository) FindUserByEmail(email string) (*models.User, error) {
 	var user models.User
-	result := r.db.Where("email = ?", email).First(&user)
+	result := r.db.Preload("Role").Where("email = ?", email).First(&user)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -48,7 +48,7 @@ func (r *CMSAuthRepository) FindUserByEmail(email string) (*models.User, error)
 
 func (r *CMSAuthRepository) FindUserById(id uuid.UUID) (*models.User, error) {
 	var user models.User
-	result := r.db.Where("id = ?", id).First(&user)
+	result := r.db.Preload("Role").Where("id = ?", id).First(&user)
 
 	if result.Error != nil {
 		return nil, result.Error
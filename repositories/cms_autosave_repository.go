@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CMSAutosaveRepositoryInterface interface {
+	FindContentRef(pageType enums.PageType, contentId uuid.UUID) (*ContentRef, error)
+	FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+	FindAutosave(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID) (*models.ContentAutosave, error)
+	FindNewerAutosaves(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID, updatedAfter time.Time) ([]models.ContentAutosave, error)
+	UpsertAutosave(autosave *models.ContentAutosave) error
+	DeleteAutosave(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID) error
+}
+
+type CMSAutosaveRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSAutosaveRepository(db *gorm.DB) *CMSAutosaveRepository {
+	return &CMSAutosaveRepository{db: db}
+}
+
+// ContentRef locates a content version within its page
+type ContentRef struct {
+	PageID   uuid.UUID
+	Language enums.PageLanguage
+	Mode     enums.PageMode
+}
+
+func (r *CMSAutosaveRepository) FindContentRef(pageType enums.PageType, contentId uuid.UUID) (*ContentRef, error) {
+	tables, ok := contentTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	var ref ContentRef
+	result := r.db.Table(tables.contents).
+		Select("page_id, language, mode").
+		Where("id = ?", contentId).
+		Limit(1).
+		Scan(&ref)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &ref, nil
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the page
+func (r *CMSAutosaveRepository) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return findPageContentScopes(r.db, pageType, pageId)
+}
+
+func (r *CMSAutosaveRepository) FindAutosave(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID) (*models.ContentAutosave, error) {
+	var autosave models.ContentAutosave
+	if err := r.db.
+		Where("page_type = ? AND page_id = ? AND language = ? AND user_id = ?", pageType, pageId, language, userId).
+		First(&autosave).Error; err != nil {
+		return nil, err
+	}
+
+	return &autosave, nil
+}
+
+// FindNewerAutosaves returns the autosaves of the other users updated after the given time, newest first
+func (r *CMSAutosaveRepository) FindNewerAutosaves(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID, updatedAfter time.Time) ([]models.ContentAutosave, error) {
+	var autosaves []models.ContentAutosave
+	if err := r.db.
+		Preload("User").
+		Where("page_type = ? AND page_id = ? AND language = ? AND user_id != ? AND updated_at > ?", pageType, pageId, language, userId, updatedAfter).
+		Order("updated_at DESC").
+		Find(&autosaves).Error; err != nil {
+		return nil, err
+	}
+
+	return autosaves, nil
+}
+
+// UpsertAutosave overwrites the slot of the user, so autosaving never grows the table
+func (r *CMSAutosaveRepository) UpsertAutosave(autosave *models.ContentAutosave) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "page_type"}, {Name: "page_id"}, {Name: "language"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"base_content_id", "payload", "updated_at"}),
+	}).Create(autosave).Error
+}
+
+func (r *CMSAutosaveRepository) DeleteAutosave(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID) error {
+	return r.db.
+		Where("page_type = ? AND page_id = ? AND language = ? AND user_id = ?", pageType, pageId, language, userId).
+		Delete(&models.ContentAutosave{}).Error
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CMSCalendarRepositoryInterface interface {
+	FindCalendarLandingContents(query dto.CalendarQuery) ([]models.LandingContent, error)
+	FindCalendarPartnerContents(query dto.CalendarQuery) ([]models.PartnerContent, error)
+	FindCalendarFaqContents(query dto.CalendarQuery) ([]models.FaqContent, error)
+	UpsertFeedToken(feedToken *models.CalendarFeedToken) error
+	FindFeedTokenByHash(tokenHash string) (*models.CalendarFeedToken, error)
+	TouchFeedToken(id uuid.UUID, usedAt time.Time) error
+	DeleteFeedToken(userId uuid.UUID) error
+}
+
+type CMSCalendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSCalendarRepository(db *gorm.DB) *CMSCalendarRepository {
+	return &CMSCalendarRepository{db: db}
+}
+
+// calendarScope keeps the current versions with a schedule date in the range, plus the optional filters
+func calendarScope(pageType enums.PageType, query dto.CalendarQuery) func(db *gorm.DB) *gorm.DB {
+	tables := contentTablesByPageType[pageType]
+
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.
+			Where("mode NOT IN ?", []enums.PageMode{enums.PageModeHistories, enums.PageModePreview}).
+			Where("(publish_on BETWEEN ? AND ?) OR (unpublish_on BETWEEN ? AND ?) OR (expired_at BETWEEN ? AND ?)",
+				query.From, query.To, query.From, query.To, query.From, query.To)
+
+		if query.Language != "" {
+			db = db.Where("language = ?", query.Language)
+		}
+		if query.Status != "" {
+			db = db.Where("workflow_status = ?", query.Status)
+		}
+		if query.CategoryID != "" {
+			db = db.Where(fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s.%s = %s.id AND %s.category_id = ?)",
+				tables.categories, tables.categories, tables.foreignKey, tables.contents, tables.categories), query.CategoryID)
+		}
+		if query.Author != "" {
+			db = db.Where(fmt.Sprintf("EXISTS (SELECT 1 FROM revisions WHERE revisions.%s = %s.id AND revisions.author ILIKE ?)",
+				tables.foreignKey, tables.contents), "%"+query.Author+"%")
+		}
+
+		return pageContentTablesByPageType[pageType].applyPageGrants(db, query.Access)
+	}
+}
+
+func (r *CMSCalendarRepository) FindCalendarLandingContents(query dto.CalendarQuery) ([]models.LandingContent, error) {
+	var landingContents []models.LandingContent
+	if err := r.db.
+		Preload("Revision").
+		Scopes(calendarScope(enums.PageTypeLanding, query)).
+		Find(&landingContents).Error; err != nil {
+		return nil, err
+	}
+
+	return landingContents, nil
+}
+
+func (r *CMSCalendarRepository) FindCalendarPartnerContents(query dto.CalendarQuery) ([]models.PartnerContent, error) {
+	var partnerContents []models.PartnerContent
+	if err := r.db.
+		Preload("Revision").
+		Scopes(calendarScope(enums.PageTypePartner, query)).
+		Find(&partnerContents).Error; err != nil {
+		return nil, err
+	}
+
+	return partnerContents, nil
+}
+
+func (r *CMSCalendarRepository) FindCalendarFaqContents(query dto.CalendarQuery) ([]models.FaqContent, error) {
+	var faqContents []models.FaqContent
+	if err := r.db.
+		Preload("Revision").
+		Scopes(calendarScope(enums.PageTypeFaq, query)).
+		Find(&faqContents).Error; err != nil {
+		return nil, err
+	}
+
+	return faqContents, nil
+}
+
+// UpsertFeedToken replaces the token of the user, so issuing a new feed url revokes the previous one
+func (r *CMSCalendarRepository) UpsertFeedToken(feedToken *models.CalendarFeedToken) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "last_used_at", "created_at"}),
+	}).Create(feedToken).Error
+}
+
+func (r *CMSCalendarRepository) FindFeedTokenByHash(tokenHash string) (*models.CalendarFeedToken, error) {
+	var feedToken models.CalendarFeedToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&feedToken).Error; err != nil {
+		return nil, err
+	}
+
+	return &feedToken, nil
+}
+
+func (r *CMSCalendarRepository) TouchFeedToken(id uuid.UUID, usedAt time.Time) error {
+	return r.db.Model(&models.CalendarFeedToken{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+func (r *CMSCalendarRepository) DeleteFeedToken(userId uuid.UUID) error {
+	result := r.db.Where("user_id = ?", userId).Delete(&models.CalendarFeedToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
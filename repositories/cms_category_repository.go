@@ -16,9 +16,10 @@ type CMSCategoryRepositoryInterface interface {
 	CreateCategory(category *models.Category) (*models.Category, error)
 	GetCategoryByID(categoryID uuid.UUID) (*models.Category, error)
 	UpdateCategory(category *models.Category) (*models.Category, error)
-	DeleteCategory(categoryID uuid.UUID) error
+	DeleteCategory(categoryID uuid.UUID, force bool) error
 	CountCategoriesByTypeAndLanguage(categoryTypeID uuid.UUID) (map[string]int, error)
 	ListCategoriesByFilter(filters dto.CategoryFilter) ([]models.Category, error)
+	ListCategoriesByCursor(filters dto.CategoryFilter, cursorQuery dto.CursorQuery) ([]models.Category, *dto.CursorPage, error)
 }
 
 type cmsCategoryRepository struct {
@@ -52,7 +53,65 @@ func (r *cmsCategoryRepository) GetCategoryByID(categoryID uuid.UUID) (*models.C
 // ListCategoriesByFilter lists categories based on provided filters.
 func (r *cmsCategoryRepository) ListCategoriesByFilter(filters dto.CategoryFilter) ([]models.Category, error) {
 	var categories []models.Category
-	query := r.db.Model(&models.Category{}).Preload("CategoryType")
+	query, err := r.filterQuery(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Preload("CategoryType").Order("weight asc, created_at asc, id asc").Find(&categories).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding categories with filter: %w", err)
+	}
+	return categories, nil
+}
+
+// categoryKeyset is the weight then created_at order of ListCategoriesByFilter
+var categoryKeyset = keysetSort{
+	key:      "weight:asc",
+	columns:  []keysetColumn{{"weight", keysetInt}, {"created_at", keysetTime}},
+	idColumn: "id",
+}
+
+// ListCategoriesByCursor lists categories based on provided filters one keyset page at a time.
+func (r *cmsCategoryRepository) ListCategoriesByCursor(filters dto.CategoryFilter, cursorQuery dto.CursorQuery) ([]models.Category, *dto.CursorPage, error) {
+	cursor, limit, err := decodeCursorQuery(cursorQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err := r.filterQuery(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var total int64
+	if cursorQuery.WithTotal {
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, nil, fmt.Errorf("error counting categories with filter: %w", err)
+		}
+	}
+
+	query, err = categoryKeyset.apply(query.Preload("CategoryType"), cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var categories []models.Category
+	if err := query.Find(&categories).Error; err != nil {
+		return nil, nil, fmt.Errorf("error finding categories with filter: %w", err)
+	}
+
+	categories, page := keysetPage(categories, categoryKeyset, cursor, limit, func(category models.Category) ([]interface{}, uuid.UUID) {
+		return []interface{}{category.Weight, category.CreatedAt}, category.ID
+	})
+	if cursorQuery.WithTotal {
+		page.TotalCount = &total
+	}
+
+	return categories, &page, nil
+}
+
+func (r *cmsCategoryRepository) filterQuery(filters dto.CategoryFilter) (*gorm.DB, error) {
+	query := r.db.Model(&models.Category{})
 
 	if filters.CategoryTypeID != nil && *filters.CategoryTypeID != "" {
 		catTypeUUID, err := uuid.Parse(*filters.CategoryTypeID)
@@ -74,23 +133,25 @@ func (r *cmsCategoryRepository) ListCategoriesByFilter(filters dto.CategoryFilte
 		query = query.Where("publish_status = ?", *filters.PublishStatus)
 	}
 
-	err := query.Order("weight asc, created_at asc").Find(&categories).Error
-	if err != nil {
-		return nil, fmt.Errorf("error finding categories with filter: %w", err)
-	}
-	return categories, nil
+	return query, nil
 }
 
-func (r *cmsCategoryRepository) DeleteCategory(categoryID uuid.UUID) error {
-	// Directly delete the Category (Detail) itself
-	result := r.db.Delete(&models.Category{}, categoryID)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete category ID %s: %w", categoryID, result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+func (r *cmsCategoryRepository) DeleteCategory(categoryID uuid.UUID, force bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureUnused(tx, enums.UsageItemCategory, categoryID, force); err != nil {
+			return err
+		}
+
+		// Directly delete the Category (Detail) itself
+		result := tx.Delete(&models.Category{}, categoryID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete category ID %s: %w", categoryID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
 }
 
 // UpdateCategory updates a category (detail).
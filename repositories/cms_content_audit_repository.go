@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSContentAuditRepositoryInterface interface {
+	FindLandingContentById(contentId uuid.UUID) (*models.LandingContent, error)
+	FindPartnerContentById(contentId uuid.UUID) (*models.PartnerContent, error)
+	FindFaqContentById(contentId uuid.UUID) (*models.FaqContent, error)
+	CountDuplicateTitles(pageType enums.PageType, title string, language enums.PageLanguage, pageId uuid.UUID) (int64, error)
+	FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+type CMSContentAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSContentAuditRepository(db *gorm.DB) *CMSContentAuditRepository {
+	return &CMSContentAuditRepository{db: db}
+}
+
+func (r *CMSContentAuditRepository) FindLandingContentById(contentId uuid.UUID) (*models.LandingContent, error) {
+	var landingContent models.LandingContent
+	if err := r.db.
+		Preload("MetaTag").
+		Preload("Components").
+		First(&landingContent, "id = ?", contentId).Error; err != nil {
+		return nil, err
+	}
+
+	return &landingContent, nil
+}
+
+func (r *CMSContentAuditRepository) FindPartnerContentById(contentId uuid.UUID) (*models.PartnerContent, error) {
+	var partnerContent models.PartnerContent
+	if err := r.db.
+		Preload("MetaTag").
+		Preload("Components").
+		First(&partnerContent, "id = ?", contentId).Error; err != nil {
+		return nil, err
+	}
+
+	return &partnerContent, nil
+}
+
+func (r *CMSContentAuditRepository) FindFaqContentById(contentId uuid.UUID) (*models.FaqContent, error) {
+	var faqContent models.FaqContent
+	if err := r.db.
+		Preload("MetaTag").
+		Preload("Components").
+		First(&faqContent, "id = ?", contentId).Error; err != nil {
+		return nil, err
+	}
+
+	return &faqContent, nil
+}
+
+// CountDuplicateTitles counts the other pages of the same type whose current content uses the same title
+func (r *CMSContentAuditRepository) CountDuplicateTitles(pageType enums.PageType, title string, language enums.PageLanguage, pageId uuid.UUID) (int64, error) {
+	var model interface{}
+	switch pageType {
+	case enums.PageTypeLanding:
+		model = &models.LandingContent{}
+	case enums.PageTypePartner:
+		model = &models.PartnerContent{}
+	case enums.PageTypeFaq:
+		model = &models.FaqContent{}
+	default:
+		return 0, nil
+	}
+
+	var count int64
+	if err := r.db.Model(model).
+		Where("LOWER(title) = LOWER(?) AND language = ? AND page_id != ? AND mode != ?", title, language, pageId, enums.PageModeHistories).
+		Distinct("page_id").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the page
+func (r *CMSContentAuditRepository) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return findPageContentScopes(r.db, pageType, pageId)
+}
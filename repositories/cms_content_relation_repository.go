@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSContentRelationRepositoryInterface interface {
+	FindRelationSource(pageType enums.PageType, contentId uuid.UUID) (*dto.RelationSource, error)
+	FindRelations(contentId uuid.UUID) ([]models.ContentRelation, error)
+	ReplaceRelations(contentId uuid.UUID, relations []*models.ContentRelation) error
+	FindIncomingRelations(pageType enums.PageType, pageId uuid.UUID) ([]dto.IncomingRelation, error)
+	PageExists(pageType enums.PageType, pageId uuid.UUID) (bool, error)
+	FindPublishedTargets(pageType enums.PageType, language enums.PageLanguage, pageIds []uuid.UUID) ([]dto.RelationTarget, error)
+	FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+type CMSContentRelationRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSContentRelationRepository(db *gorm.DB) *CMSContentRelationRepository {
+	return &CMSContentRelationRepository{db: db}
+}
+
+// relationTables names where a page type keeps its pages, and how its contents give the path and thumbnail of a related page
+type relationTables struct {
+	pages     string
+	contents  string
+	path      string
+	thumbnail string
+}
+
+var relationTablesByPageType = map[enums.PageType]relationTables{
+	enums.PageTypeLanding: {"landing_pages", "landing_contents", "landing_contents.url_alias", "meta_tags.cover_image"},
+	enums.PageTypePartner: {"partner_pages", "partner_contents", "partner_contents.url", "COALESCE(NULLIF(partner_contents.thumbnail_image, ''), meta_tags.cover_image)"},
+	enums.PageTypeFaq:     {"faq_pages", "faq_contents", "faq_contents.url", "meta_tags.cover_image"},
+}
+
+// currentRelationSource matches the relations of contents that are not history versions or preview copies
+func currentRelationSource() (string, []interface{}) {
+	modes := []enums.PageMode{enums.PageModeHistories, enums.PageModePreview}
+
+	conditions := []string{}
+	args := []interface{}{}
+	for _, pageType := range []enums.PageType{enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq} {
+		conditions = append(conditions, fmt.Sprintf("(content_relations.source_page_type = ? AND EXISTS (SELECT 1 FROM %[1]s "+
+			"WHERE %[1]s.id = content_relations.source_content_id AND %[1]s.mode NOT IN ?))", relationTablesByPageType[pageType].contents))
+		args = append(args, pageType, modes)
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// copyContentRelations gives a new content version the relations of the content it was made from
+func copyContentRelations(tx *gorm.DB, fromContentId, toContentId, pageId uuid.UUID, language enums.PageLanguage) error {
+	return tx.Exec("INSERT INTO content_relations (source_page_type, source_page_id, source_content_id, language, relation_type, target_page_type, target_page_id, position) "+
+		"SELECT source_page_type, ?, ?, ?, relation_type, target_page_type, target_page_id, position FROM content_relations WHERE source_content_id = ?",
+		pageId, toContentId, language, fromContentId).Error
+}
+
+// copyLatestContentRelations replaces the relations of a preview copy with those of the newest saved content of its page language
+func copyLatestContentRelations(tx *gorm.DB, pageType enums.PageType, previewId, pageId uuid.UUID, language enums.PageLanguage) error {
+	if err := tx.Where("source_content_id = ?", previewId).Delete(&models.ContentRelation{}).Error; err != nil {
+		return err
+	}
+
+	latest := tx.Table(relationTablesByPageType[pageType].contents).
+		Select("id").
+		Where("page_id = ? AND language = ? AND mode <> ?", pageId, language, enums.PageModePreview).
+		Order("created_at DESC").
+		Limit(1)
+	return tx.Exec("INSERT INTO content_relations (source_page_type, source_page_id, source_content_id, language, relation_type, target_page_type, target_page_id, position) "+
+		"SELECT source_page_type, source_page_id, ?, language, relation_type, target_page_type, target_page_id, position FROM content_relations WHERE source_content_id = (?)",
+		previewId, latest).Error
+}
+
+// FindRelationSource returns the page, language and status of the content
+func (r *CMSContentRelationRepository) FindRelationSource(pageType enums.PageType, contentId uuid.UUID) (*dto.RelationSource, error) {
+	tables, ok := relationTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	var source dto.RelationSource
+	if err := r.db.Table(tables.contents).
+		Select("page_id, language, mode, workflow_status").
+		Where("id = ?", contentId).
+		Take(&source).Error; err != nil {
+		return nil, err
+	}
+
+	return &source, nil
+}
+
+// FindRelations returns the relations of the content ordered by relation type, then position
+func (r *CMSContentRelationRepository) FindRelations(contentId uuid.UUID) ([]models.ContentRelation, error) {
+	relations := []models.ContentRelation{}
+	err := r.db.
+		Where("source_content_id = ?", contentId).
+		Order("relation_type, position").
+		Find(&relations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return relations, nil
+}
+
+// ReplaceRelations swaps every relation of the content for the given ones
+func (r *CMSContentRelationRepository) ReplaceRelations(contentId uuid.UUID, relations []*models.ContentRelation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_content_id = ?", contentId).Delete(&models.ContentRelation{}).Error; err != nil {
+			return err
+		}
+
+		if len(relations) == 0 {
+			return nil
+		}
+		return tx.Create(relations).Error
+	})
+}
+
+// FindIncomingRelations returns the relations pointing to the page from the current contents of any page language
+func (r *CMSContentRelationRepository) FindIncomingRelations(pageType enums.PageType, pageId uuid.UUID) ([]dto.IncomingRelation, error) {
+	titleSelect, titleArgs := pageTitleSelect("content_relations.source_page_type", "content_relations.source_page_id", "content_relations.language")
+	currentSource, currentArgs := currentRelationSource()
+
+	relations := []dto.IncomingRelation{}
+	err := r.db.Model(&models.ContentRelation{}).
+		Select("content_relations.*, "+titleSelect+" AS source_title", titleArgs...).
+		Where("content_relations.target_page_type = ? AND content_relations.target_page_id = ?", pageType, pageId).
+		Where(currentSource, currentArgs...).
+		Order("content_relations.source_page_type, content_relations.source_page_id, content_relations.language").
+		Scan(&relations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return relations, nil
+}
+
+func (r *CMSContentRelationRepository) PageExists(pageType enums.PageType, pageId uuid.UUID) (bool, error) {
+	tables, ok := relationTablesByPageType[pageType]
+	if !ok {
+		return false, errs.ErrInvalidPageType
+	}
+
+	var count int64
+	if err := r.db.Table(tables.pages).Where("id = ?", pageId).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// FindPublishedTargets returns the published contents of the pages in the language, newest first,
+// pages without one are left out
+func (r *CMSContentRelationRepository) FindPublishedTargets(pageType enums.PageType, language enums.PageLanguage, pageIds []uuid.UUID) ([]dto.RelationTarget, error) {
+	tables, ok := relationTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	targets := []dto.RelationTarget{}
+	if len(pageIds) == 0 {
+		return targets, nil
+	}
+
+	err := r.db.Table(tables.contents).
+		Select(fmt.Sprintf("%[1]s.page_id, %[1]s.title, %[2]s AS path, COALESCE(%[3]s, '') AS thumbnail", tables.contents, tables.path, tables.thumbnail)).
+		Joins(fmt.Sprintf("LEFT JOIN meta_tags ON meta_tags.id = %s.meta_tag_id", tables.contents)).
+		Where(fmt.Sprintf("%[1]s.page_id IN ? AND %[1]s.language = ? AND %[1]s.workflow_status = ? AND %[1]s.mode NOT IN ?", tables.contents),
+			pageIds, language, enums.WorkflowPublished, []enums.PageMode{enums.PageModeHistories, enums.PageModePreview}).
+		Order(fmt.Sprintf("%s.updated_at DESC", tables.contents)).
+		Scan(&targets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the page
+func (r *CMSContentRelationRepository) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return findPageContentScopes(r.db, pageType, pageId)
+}
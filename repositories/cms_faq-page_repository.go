@@ -20,9 +20,10 @@ import (
 type CMSFaqPageRepositoryInterface interface {
 	CreateFaqPage(faqPage *models.FaqPage) (*models.FaqPage, error)
 	FindAllFaqPage(query dto.FaqPageQuery, sort string, page, limit int, language string) ([]models.FaqPage, int64, error)
+	FindFaqPagesByCursor(query dto.FaqPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.FaqPage, *dto.CursorPage, error)
 	FindFaqPageById(id uuid.UUID) (*models.FaqPage, error)
 	UpdateFaqContent(updateFaqContent *models.FaqContent, prevContentId uuid.UUID) (*models.FaqContent, error)
-	DeleteFaqPage(id uuid.UUID) error
+	DeleteFaqPage(id uuid.UUID, force bool) error
 	FindContentByFaqPageId(pageId uuid.UUID, language string, mode string) (*models.FaqContent, error)
 	FindLatestContentByPageId(pageId uuid.UUID, language string) (*models.FaqContent, error)
 	// Deprecate
@@ -36,6 +37,9 @@ type CMSFaqPageRepositoryInterface interface {
 	IsUrlDuplicate(url string, pageId uuid.UUID) (bool, error)
 	IsUrlAliasDuplicate(urlAlias string, pageId uuid.UUID) (bool, error)
 	GetPageIdByContentId(contentId uuid.UUID) (uuid.UUID, error)
+	GetContentRefByRevisionId(revisionId uuid.UUID) (pageId uuid.UUID, contentId uuid.UUID, err error)
+	FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error)
+	FindContentById(contentId uuid.UUID) (*models.FaqContent, error)
 	CreateFaqContentPreview(faqContentPreview *models.FaqContent) (*models.FaqContent, error)
 	UpdateFaqContentPreview(faqContentPreview *models.FaqContent) (*models.FaqContent, error)
 	FindFaqContentPreviewById(pageId uuid.UUID, language string) (*models.FaqContent, error)
@@ -50,8 +54,20 @@ func NewCMSFaqPageRepository(db *gorm.DB) *CMSFaqPageRepository {
 }
 
 func (r *CMSFaqPageRepository) CreateFaqPage(faqPage *models.FaqPage) (*models.FaqPage, error) {
-	// Create the faqPage (and its content)
-	if err := r.db.Create(faqPage).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Create the faqPage (and its content)
+		if err := tx.Create(faqPage).Error; err != nil {
+			return err
+		}
+
+		for _, content := range faqPage.Contents {
+			if err := recordContentSaved(tx, faqContentEventData(content)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -80,39 +96,7 @@ func (r *CMSFaqPageRepository) FindAllFaqPage(query dto.FaqPageQuery, sort strin
 	baseQuery := r.db.Model(&models.FaqPage{}).
 		Joins("JOIN faq_contents ON faq_contents.page_id = faq_pages.id").
 		Where("faq_contents.mode != ? AND faq_contents.mode != ?", "Histories", "Preview")
-
-	// Content filters
-	if query.Title != "" {
-		baseQuery = baseQuery.Where("faq_contents.title ILIKE ?", "%"+query.Title+"%")
-	}
-	if query.UrlAlias != "" {
-		baseQuery = baseQuery.Where("faq_contents.url_alias ILIKE ?", "%"+query.UrlAlias+"%")
-	}
-	if query.URL != "" {
-		baseQuery = baseQuery.Where("faq_contents.url ILIKE ?", "%"+query.URL+"%")
-	}
-	if query.Status != "" {
-		baseQuery = baseQuery.Where("faq_contents.workflow_status = ?", query.Status)
-	}
-	if language != "" {
-		baseQuery = baseQuery.Where("faq_contents.language = ?", language)
-	}
-
-	// Category filters
-	categoryFilters := map[string]string{
-		"faq":               query.CategoryFaq,
-		"category-keywords": query.CategoryKeywords,
-	}
-	for typeCode, filterValue := range categoryFilters {
-		if filterValue != "" {
-			subQuery := r.db.Table("faq_content_categories").
-				Select("faq_content_categories.faq_content_id").
-				Joins("JOIN categories ON faq_content_categories.category_id = categories.id").
-				Joins("JOIN category_types ON category_types.id = categories.category_type_id").
-				Where("category_types.type_code = ? AND categories.name ILIKE ?", typeCode, "%"+filterValue+"%")
-			baseQuery = baseQuery.Where("faq_contents.id IN (?)", subQuery)
-		}
-	}
+	baseQuery = r.applyFaqContentFilters(baseQuery, query, language)
 
 	// Clone query for counting
 	countQuery := baseQuery.Session(&gorm.Session{})
@@ -169,6 +153,7 @@ func (r *CMSFaqPageRepository) FindAllFaqPage(query dto.FaqPageQuery, sort strin
 	offset := (page - 1) * limit
 	err := finalQuery.
 		Order(sortColumn).
+		Order("faq_pages.id").
 		Offset(offset).
 		Limit(limit).
 		Preload("Contents", func(db *gorm.DB) *gorm.DB {
@@ -189,6 +174,104 @@ func (r *CMSFaqPageRepository) FindAllFaqPage(query dto.FaqPageQuery, sort strin
 
 	return faqPages, totalCount, nil
 }
+
+// applyFaqContentFilters narrows a query over faq_contents to the contents matching the list filters
+func (r *CMSFaqPageRepository) applyFaqContentFilters(db *gorm.DB, query dto.FaqPageQuery, language string) *gorm.DB {
+	// Content filters
+	if query.Title != "" {
+		db = db.Where("faq_contents.title ILIKE ?", "%"+query.Title+"%")
+	}
+	if query.UrlAlias != "" {
+		db = db.Where("faq_contents.url_alias ILIKE ?", "%"+query.UrlAlias+"%")
+	}
+	if query.URL != "" {
+		db = db.Where("faq_contents.url ILIKE ?", "%"+query.URL+"%")
+	}
+	if query.Status != "" {
+		db = db.Where("faq_contents.workflow_status = ?", query.Status)
+	}
+	if language != "" {
+		db = db.Where("faq_contents.language = ?", language)
+	}
+	db = faqContentTables.applyPageGrants(db, query.Access)
+
+	// Category filters
+	categoryFilters := map[string]string{
+		"faq":               query.CategoryFaq,
+		"category-keywords": query.CategoryKeywords,
+	}
+	for typeCode, filterValue := range categoryFilters {
+		if filterValue != "" {
+			subQuery := r.db.Table("faq_content_categories").
+				Select("faq_content_categories.faq_content_id").
+				Joins("JOIN categories ON faq_content_categories.category_id = categories.id").
+				Joins("JOIN category_types ON category_types.id = categories.category_type_id").
+				Where("category_types.type_code = ? AND categories.name ILIKE ?", typeCode, "%"+filterValue+"%")
+			db = db.Where("faq_contents.id IN (?)", subQuery)
+		}
+	}
+
+	return db
+}
+
+// FindFaqPagesByCursor is the keyset paginated counterpart of FindAllFaqPage. Pages are matched with EXISTS over their contents
+// instead of a join, so no DISTINCT or GROUP BY is needed, and they are only counted when asked to.
+func (r *CMSFaqPageRepository) FindFaqPagesByCursor(query dto.FaqPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.FaqPage, *dto.CursorPage, error) {
+	keyset, err := timestampKeysetSort("faq_pages", sort)
+	if err != nil {
+		return nil, nil, err
+	}
+	cursor, limit, err := decodeCursorQuery(cursorQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contents := r.applyFaqContentFilters(
+		r.db.Table("faq_contents").
+			Select("1").
+			Where("faq_contents.page_id = faq_pages.id").
+			Where("faq_contents.mode != ? AND faq_contents.mode != ?", "Histories", "Preview"),
+		query, language)
+	baseQuery := r.db.Model(&models.FaqPage{}).Where("EXISTS (?)", contents)
+
+	var totalCount *int64
+	if cursorQuery.WithTotal {
+		var count int64
+		if err := baseQuery.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return nil, nil, err
+		}
+		totalCount = &count
+	}
+
+	pageQuery, err := keyset.apply(baseQuery, cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var faqPages []models.FaqPage
+	if err := pageQuery.
+		Preload("Contents", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Where("faq_contents.mode != ? AND faq_contents.mode != ?", "Histories", "Preview").
+				Order("faq_contents.created_at DESC")
+		}).
+		Preload("Contents.Revision").
+		Preload("Contents.Categories").
+		Preload("Contents.Categories.CategoryType").
+		Preload("Contents.Components").
+		Preload("Contents.MetaTag").
+		Find(&faqPages).Error; err != nil {
+		return nil, nil, err
+	}
+
+	faqPages, page := keysetPage(faqPages, keyset, cursor, limit, func(p models.FaqPage) ([]interface{}, uuid.UUID) {
+		return []interface{}{keysetValue(keyset, p.CreatedAt, p.UpdatedAt)}, p.ID
+	})
+	page.TotalCount = totalCount
+
+	return faqPages, &page, nil
+}
+
 func (r *CMSFaqPageRepository) FindFaqPageById(id uuid.UUID) (*models.FaqPage, error) {
 	var faqPage models.FaqPage
 
@@ -242,12 +325,16 @@ func (r *CMSFaqPageRepository) UpdateFaqContent(updateFaqContent *models.FaqCont
 			return err
 		}
 
+		if err := copyContentRelations(tx, prevContentId, updateFaqContent.ID, updateFaqContent.PageID, updateFaqContent.Language); err != nil {
+			return err
+		}
+
 		// Update the page's updated_at to the current time
 		if err := tx.Model(&models.FaqPage{}).Where("id = ?", updateFaqContent.PageID).Update("updated_at", now).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return recordContentSaved(tx, faqContentEventData(updateFaqContent))
 	})
 
 	if err != nil {
@@ -257,7 +344,7 @@ func (r *CMSFaqPageRepository) UpdateFaqContent(updateFaqContent *models.FaqCont
 	return updateFaqContent, nil
 }
 
-func (r *CMSFaqPageRepository) DeleteFaqPage(id uuid.UUID) error {
+func (r *CMSFaqPageRepository) DeleteFaqPage(id uuid.UUID, force bool) error {
 	err := r.db.Transaction(func(tx *gorm.DB) error {
 
 		var contents []models.FaqContent
@@ -271,6 +358,10 @@ func (r *CMSFaqPageRepository) DeleteFaqPage(id uuid.UUID) error {
 			return err
 		}
 
+		if err := ensureUnused(tx, enums.UsageItemFaqPage, id, force); err != nil {
+			return err
+		}
+
 		// Step 1: Get all FaqContent entries for this page
 		if err := tx.Where("page_id = ?", id).Find(&contents).Error; err != nil {
 			return err
@@ -309,7 +400,7 @@ func (r *CMSFaqPageRepository) DeleteFaqPage(id uuid.UUID) error {
 			return err
 		}
 
-		return nil
+		return recordContentDeleted(tx, dto.ContentEventData{PageType: enums.PageTypeFaq, PageID: id})
 	})
 
 	if err != nil {
@@ -351,7 +442,14 @@ func (r *CMSFaqPageRepository) FindLatestContentByPageId(pageId uuid.UUID, langu
 
 // Might be deprecate
 func (r *CMSFaqPageRepository) CreateContentForFaqPage(faqContent *models.FaqContent, lang string, mode string) (*models.FaqContent, error) {
-	if err := r.db.Create(faqContent).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(faqContent).Error; err != nil {
+			return err
+		}
+
+		return recordContentSaved(tx, faqContentEventData(faqContent))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -386,7 +484,7 @@ func (r *CMSFaqPageRepository) DeleteFaqContent(pageId uuid.UUID, lang, mode str
 			return err
 		}
 
-		return nil
+		return recordContentDeleted(tx, faqContentEventData(&faqContent))
 	})
 
 	if err != nil {
@@ -490,7 +588,20 @@ func (r *CMSFaqPageRepository) DuplicateFaqPage(pageId uuid.UUID) (*models.FaqPa
 	copyFaqPage.Contents = newContents
 
 	// Create the new faqPage
-	if err := r.db.Create(&copyFaqPage).Error; err != nil {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&copyFaqPage).Error; err != nil {
+			return err
+		}
+
+		// The copies keep the relations of the contents they were made from
+		for i, content := range newContents {
+			if err := copyContentRelations(tx, faqContents[i].ID, content.ID, copyFaqPage.ID, content.Language); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -546,7 +657,17 @@ func (r *CMSFaqPageRepository) DuplicateFaqContentToAnotherLanguage(contentId uu
 		}
 	}
 
-	if err := r.db.Create(&faqContent).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&faqContent).Error; err != nil {
+			return err
+		}
+		if err := copyContentRelations(tx, contentId, faqContent.ID, faqContent.PageID, faqContent.Language); err != nil {
+			return err
+		}
+
+		return recordContentSaved(tx, faqContentEventData(&faqContent))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -577,6 +698,7 @@ func (r *CMSFaqPageRepository) RevertFaqContent(revisionId uuid.UUID, newRevisio
 		return nil, err
 	}
 
+	revertedContentId := faqContent.ID
 	err = r.db.Transaction(func(tx *gorm.DB) error {
 		// Update the old content
 		oldContent.Mode = enums.PageModeHistories
@@ -619,8 +741,11 @@ func (r *CMSFaqPageRepository) RevertFaqContent(revisionId uuid.UUID, newRevisio
 		if err := tx.Create(faqContent).Error; err != nil {
 			return err
 		}
+		if err := copyContentRelations(tx, revertedContentId, faqContent.ID, faqContent.PageID, faqContent.Language); err != nil {
+			return err
+		}
 
-		return nil
+		return recordContentSaved(tx, faqContentEventData(faqContent))
 	})
 
 	if err != nil {
@@ -757,7 +882,14 @@ func (r *CMSFaqPageRepository) GetPageIdByContentId(contentId uuid.UUID) (uuid.U
 func (r *CMSFaqPageRepository) CreateFaqContentPreview(faqContentPreview *models.FaqContent) (*models.FaqContent, error) {
 	faqContentPreview.Revision = nil
 	faqContentPreview.Categories = nil
-	if err := r.db.Create(faqContentPreview).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(faqContentPreview).Error; err != nil {
+			return err
+		}
+
+		return copyLatestContentRelations(tx, enums.PageTypeFaq, faqContentPreview.ID, faqContentPreview.PageID, faqContentPreview.Language)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -779,7 +911,7 @@ func (r *CMSFaqPageRepository) UpdateFaqContentPreview(faqContentPreview *models
 			return err
 		}
 
-		return nil
+		return copyLatestContentRelations(tx, enums.PageTypeFaq, faqContentPreview.ID, faqContentPreview.PageID, faqContentPreview.Language)
 	})
 
 	if err != nil {
@@ -801,3 +933,26 @@ func (r *CMSFaqPageRepository) FindFaqContentPreviewById(pageId uuid.UUID, langu
 
 	return &faqContentPreview, nil
 }
+
+// GetContentRefByRevisionId returns the page and the content the revision belongs to
+func (r *CMSFaqPageRepository) GetContentRefByRevisionId(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	return faqContentTables.getContentRefByRevisionId(r.db, revisionId)
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the page
+func (r *CMSFaqPageRepository) FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return faqContentTables.findContentScopes(r.db, pageId)
+}
+
+// FindContentById returns a content with what the publish audit looks at
+func (r *CMSFaqPageRepository) FindContentById(contentId uuid.UUID) (*models.FaqContent, error) {
+	var faqContent models.FaqContent
+	if err := r.db.
+		Preload("MetaTag").
+		Preload("Components").
+		First(&faqContent, "id = ?", contentId).Error; err != nil {
+		return nil, err
+	}
+
+	return &faqContent, nil
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CMSFaqFeedbackRepositoryInterface interface {
+	FindPublishedFaqContent(id uuid.UUID) (*models.FaqContent, error)
+	UpsertFeedback(feedback *models.FaqFeedback) error
+	FindHelpfulnessSeries(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error)
+	FindLowestRated(query dto.FaqFeedbackQuery, minVotes, limit int) ([]dto.FaqHelpfulnessRank, error)
+	FindFeedbackComments(query dto.FaqFeedbackQuery, page, limit int) ([]dto.FaqFeedbackComment, int64, error)
+}
+
+type CMSFaqFeedbackRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSFaqFeedbackRepository(db *gorm.DB) *CMSFaqFeedbackRepository {
+	return &CMSFaqFeedbackRepository{db: db}
+}
+
+// FindPublishedFaqContent returns the page and language of a published faq content, votes on anything else are refused
+func (r *CMSFaqFeedbackRepository) FindPublishedFaqContent(id uuid.UUID) (*models.FaqContent, error) {
+	var faqContent models.FaqContent
+	err := r.db.
+		Select("id", "page_id", "language").
+		Where("id = ? AND workflow_status = ? AND mode NOT IN ?", id, enums.WorkflowPublished, []enums.PageMode{enums.PageModeHistories, enums.PageModePreview}).
+		First(&faqContent).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &faqContent, nil
+}
+
+// UpsertFeedback keeps one vote per client, page and language, voting again replaces the previous vote
+func (r *CMSFaqFeedbackRepository) UpsertFeedback(feedback *models.FaqFeedback) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "page_id"}, {Name: "language"}, {Name: "client_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"faq_content_id", "helpful", "feedback", "updated_at"}),
+	}).Create(feedback).Error
+}
+
+// feedbackScope filters the votes cast in the range, optionally of one language and page
+func feedbackScope(query dto.FaqFeedbackQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("faq_feedbacks.updated_at >= ? AND faq_feedbacks.updated_at <= ?", query.From, query.To)
+		if query.Language != "" {
+			db = db.Where("faq_feedbacks.language = ?", query.Language)
+		}
+		if query.PageID != nil {
+			db = db.Where("faq_feedbacks.page_id = ?", *query.PageID)
+		}
+		return db
+	}
+}
+
+// FindHelpfulnessSeries counts the votes per day, week or month
+func (r *CMSFaqFeedbackRepository) FindHelpfulnessSeries(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error) {
+	points := []dto.HelpfulnessPoint{}
+	err := r.db.Model(&models.FaqFeedback{}).
+		Scopes(feedbackScope(query)).
+		Select("date_trunc(?, faq_feedbacks.updated_at) AS period, "+
+			"COUNT(*) FILTER (WHERE faq_feedbacks.helpful) AS helpful, COUNT(*) FILTER (WHERE NOT faq_feedbacks.helpful) AS not_helpful", interval).
+		Group("period").
+		Order("period").
+		Scan(&points).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// FindLowestRated ranks the faq page languages with at least minVotes votes by their helpful share, lowest first
+func (r *CMSFaqFeedbackRepository) FindLowestRated(query dto.FaqFeedbackQuery, minVotes, limit int) ([]dto.FaqHelpfulnessRank, error) {
+	ranks := []dto.FaqHelpfulnessRank{}
+	err := r.db.Model(&models.FaqFeedback{}).
+		Scopes(feedbackScope(query)).
+		Select("faq_feedbacks.page_id, faq_feedbacks.language, "+
+			"(SELECT faq_contents.title FROM faq_contents WHERE faq_contents.page_id = faq_feedbacks.page_id AND faq_contents.language = faq_feedbacks.language "+
+			"AND faq_contents.mode NOT IN ? ORDER BY faq_contents.updated_at DESC LIMIT 1) AS title, "+
+			"COUNT(*) FILTER (WHERE faq_feedbacks.helpful) AS helpful, COUNT(*) FILTER (WHERE NOT faq_feedbacks.helpful) AS not_helpful, "+
+			"AVG(CASE WHEN faq_feedbacks.helpful THEN 1.0 ELSE 0.0 END) AS ratio",
+			[]enums.PageMode{enums.PageModeHistories, enums.PageModePreview}).
+		Group("faq_feedbacks.page_id, faq_feedbacks.language").
+		Having("COUNT(*) >= ?", minVotes).
+		Order("ratio ASC, COUNT(*) DESC").
+		Limit(limit).
+		Scan(&ranks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ranks, nil
+}
+
+// FindFeedbackComments returns one page of the votes that came with free-text feedback, newest first
+func (r *CMSFaqFeedbackRepository) FindFeedbackComments(query dto.FaqFeedbackQuery, page, limit int) ([]dto.FaqFeedbackComment, int64, error) {
+	baseQuery := r.db.Model(&models.FaqFeedback{}).
+		Scopes(feedbackScope(query)).
+		Where("faq_feedbacks.feedback <> ''")
+
+	var totalCount int64
+	countQuery := baseQuery.Session(&gorm.Session{})
+	if err := countQuery.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	comments := []dto.FaqFeedbackComment{}
+	err := baseQuery.
+		Select("faq_feedbacks.id, faq_feedbacks.page_id, faq_feedbacks.faq_content_id, faq_feedbacks.language, faq_contents.title, " +
+			"faq_feedbacks.helpful, faq_feedbacks.feedback, faq_feedbacks.updated_at").
+		Joins("LEFT JOIN faq_contents ON faq_contents.id = faq_feedbacks.faq_content_id").
+		Order("faq_feedbacks.updated_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&comments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, totalCount, nil
+}
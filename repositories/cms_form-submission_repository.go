@@ -1,11 +1,14 @@
 package repositories
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -14,6 +17,7 @@ import (
 type FormSubmissionRepositoryInterface interface {
 	CreateFormSubmission(formSubmission *models.FormSubmission) (*models.FormSubmission, error)
 	GetFormSubmissions(formId uuid.UUID, sort string, page, limit int) ([]*models.FormSubmission, int64, error)
+	GetFormSubmissionsByCursor(formId uuid.UUID, sort string, cursorQuery dto.CursorQuery) ([]*models.FormSubmission, *dto.CursorPage, error)
 	GetFormSubmission(submissionId uuid.UUID) (*models.FormSubmission, error)
 	GetEmailContentsFormFormId(formId uuid.UUID) ([]*models.EmailContent, error)
 }
@@ -27,7 +31,19 @@ func NewFormSubmissionRepository(db *gorm.DB) FormSubmissionRepositoryInterface
 }
 
 func (r *FormSubmissionRepository) CreateFormSubmission(formSubmission *models.FormSubmission) (*models.FormSubmission, error) {
-	if err := r.db.Create(formSubmission).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(formSubmission).Error; err != nil {
+			return err
+		}
+
+		return appendOutboxEvent(tx, enums.DomainEventFormSubmissionCreated, formSubmission.ID, dto.FormSubmissionEventData{
+			FormID:        formSubmission.FormID,
+			SubmissionID:  formSubmission.ID,
+			SubmittedAt:   formSubmission.SubmittedAt,
+			SubmittedData: json.RawMessage(formSubmission.SubmittedData),
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -69,6 +85,9 @@ func (r *FormSubmissionRepository) GetFormSubmissions(formId uuid.UUID, sort str
 		db = db.Order("created_at DESC")
 	}
 
+	// Submissions sharing a timestamp keep their place across pages
+	db = db.Order("id")
+
 	offset := (page - 1) * limit
 	db = db.Offset(offset).Limit(limit)
 
@@ -80,6 +99,46 @@ func (r *FormSubmissionRepository) GetFormSubmissions(formId uuid.UUID, sort str
 	return formSubmissions, totalCount, nil
 }
 
+// GetFormSubmissionsByCursor is the keyset paginated counterpart of GetFormSubmissions, sorted by created_at or updated_at only
+func (r *FormSubmissionRepository) GetFormSubmissionsByCursor(formId uuid.UUID, sort string, cursorQuery dto.CursorQuery) ([]*models.FormSubmission, *dto.CursorPage, error) {
+	cursor, limit, err := decodeCursorQuery(cursorQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyset, err := timestampKeysetSort("form_submissions", sort)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db := r.db.Model(&models.FormSubmission{}).Where("form_id = ?", formId)
+
+	var totalCount int64
+	if cursorQuery.WithTotal {
+		if err := db.Count(&totalCount).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	db, err = keyset.apply(db.Preload("Form"), cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var formSubmissions []*models.FormSubmission
+	if err := db.Find(&formSubmissions).Error; err != nil {
+		return nil, nil, err
+	}
+
+	formSubmissions, page := keysetPage(formSubmissions, keyset, cursor, limit, func(formSubmission *models.FormSubmission) ([]interface{}, uuid.UUID) {
+		return []interface{}{keysetValue(keyset, formSubmission.CreatedAt, formSubmission.UpdatedAt)}, formSubmission.ID
+	})
+	if cursorQuery.WithTotal {
+		page.TotalCount = &totalCount
+	}
+
+	return formSubmissions, &page, nil
+}
+
 func (r *FormSubmissionRepository) GetFormSubmission(submissionId uuid.UUID) (*models.FormSubmission, error) {
 	var formSubmission models.FormSubmission
 	if err := r.db.Preload("Form").First(&formSubmission, "id = ?", submissionId).Error; err != nil {
@@ -19,6 +19,7 @@ type FormRepositoryInterface interface {
 	GetFormByID(formID uuid.UUID) (*models.Form, error)
 	GetFormStructure(formID uuid.UUID) (*models.Form, error)
 	ListForms(filter dto.FormListFilter) ([]models.Form, int64, error)
+	ListFormsByCursor(filter dto.FormListFilter, cursorQuery dto.CursorQuery) ([]models.Form, *dto.CursorPage, error)
 	UpdateForm(tx *gorm.DB, form *models.Form) (*models.Form, error)
 	DeleteForm(tx *gorm.DB, formID uuid.UUID) error
 	CheckFieldKeyExistsInForm(formID uuid.UUID, fieldKey string, excludeFieldID *uuid.UUID) (bool, error)
@@ -90,6 +91,44 @@ func (r *formRepository) ListForms(filter dto.FormListFilter) ([]models.Form, in
 	return forms, totalItems, nil
 }
 
+// ListFormsByCursor is the keyset paginated counterpart of ListForms, Page and ItemsPerPage of the filter are ignored
+func (r *formRepository) ListFormsByCursor(filter dto.FormListFilter, cursorQuery dto.CursorQuery) ([]models.Form, *dto.CursorPage, error) {
+	cursor, limit, err := decodeCursorQuery(cursorQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyset := formKeysetSort(filter.Sort)
+
+	var totalItems int64
+	if cursorQuery.WithTotal {
+		if err := r.buildFilterQuery(filter).Count(&totalItems).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to count forms: %w", err)
+		}
+	}
+
+	query, err := keyset.apply(r.buildFilterQuery(filter), cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var forms []models.Form
+	if err := query.Find(&forms).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list forms: %w", err)
+	}
+
+	forms, page := keysetPage(forms, keyset, cursor, limit, func(form models.Form) ([]interface{}, uuid.UUID) {
+		if keyset.columns[0].kind == keysetString {
+			return []interface{}{form.Name}, form.ID
+		}
+		return []interface{}{form.UpdatedAt}, form.ID
+	})
+	if cursorQuery.WithTotal {
+		page.TotalCount = &totalItems
+	}
+
+	return forms, &page, nil
+}
+
 func (r *formRepository) UpdateForm(tx *gorm.DB, form *models.Form) (*models.Form, error) {
 	if form.ID == uuid.Nil {
 		return nil, errors.New("form ID is required for update")
@@ -234,20 +273,21 @@ func (r *formRepository) buildFilterQuery(filter dto.FormListFilter) *gorm.DB {
 }
 
 func (r *formRepository) applySortingAndPagination(query *gorm.DB, filter dto.FormListFilter) *gorm.DB {
-	sortOrder := "updated_at DESC" // Default sort order (Newest)
+	sortOrder := "updated_at DESC, id DESC" // Default sort order (Newest)
 
 	if filter.Sort != nil && *filter.Sort != "" {
 		switch *filter.Sort {
 		case "updated_at_asc":
-			sortOrder = "updated_at ASC" // Oldest
+			sortOrder = "updated_at ASC, id ASC" // Oldest
 		case "updated_at_desc":
-			sortOrder = "updated_at DESC" // Newest
+			sortOrder = "updated_at DESC, id DESC" // Newest
 		case "name_asc":
-			sortOrder = "name ASC" // A-Z
+			sortOrder = "name ASC, id ASC" // A-Z
 		case "name_desc":
-			sortOrder = "name DESC" // Z-A
+			sortOrder = "name DESC, id DESC" // Z-A
 		}
 	}
+	// The id tiebreaker keeps forms with the same name or timestamp from moving between pages
 	query = query.Order(sortOrder)
 
 	page := r.getPage(filter.Page)
@@ -257,6 +297,23 @@ func (r *formRepository) applySortingAndPagination(query *gorm.DB, filter dto.Fo
 	return query.Offset(offset).Limit(limit)
 }
 
+// formKeysetSort maps the sorts of ListForms to their keyset, defaulting to updated_at DESC like the offset list
+func formKeysetSort(sort *string) keysetSort {
+	keyset := keysetSort{key: "updated_at_desc", columns: []keysetColumn{{"updated_at", keysetTime}}, idColumn: "id", desc: true}
+	if sort == nil {
+		return keyset
+	}
+
+	switch *sort {
+	case "updated_at_asc":
+		keyset.key, keyset.desc = *sort, false
+	case "name_asc", "name_desc":
+		keyset.key, keyset.desc = *sort, *sort == "name_desc"
+		keyset.columns = []keysetColumn{{"name", keysetString}}
+	}
+	return keyset
+}
+
 func (r *formRepository) getPage(page *int) int {
 	if page != nil && *page > 0 {
 		return *page
@@ -21,9 +21,10 @@ import (
 type CMSLandingPageRepositoryInterface interface {
 	CreateLandingPage(LandingPage *models.LandingPage) (*models.LandingPage, error)
 	FindAllLandingPage(query dto.LandingPageQuery, sort string, page, limit int, language string) ([]models.LandingPage, int64, error)
+	FindLandingPagesByCursor(query dto.LandingPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.LandingPage, *dto.CursorPage, error)
 	FindLandingPageById(id uuid.UUID) (*models.LandingPage, error)
 	UpdateLandingContent(updateLandingContent *models.LandingContent, prevContentId uuid.UUID) (*models.LandingContent, error)
-	DeleteLandingPage(id uuid.UUID) error
+	DeleteLandingPage(id uuid.UUID, force bool) error
 	FindContentByLandingPageId(pageId uuid.UUID, language string, mode string) (*models.LandingContent, error)
 	FindLatestContentByPageId(pageId uuid.UUID, language string) (*models.LandingContent, error)
 	// Deprecate
@@ -36,6 +37,9 @@ type CMSLandingPageRepositoryInterface interface {
 	GetRevisionByLandingPageId(pageId uuid.UUID, language string) ([]models.Revision, error)
 	IsUrlAliasDuplicate(urlAlias string, pageId uuid.UUID) (bool, error)
 	GetPageIdByContentId(contentId uuid.UUID) (uuid.UUID, error)
+	GetContentRefByRevisionId(revisionId uuid.UUID) (pageId uuid.UUID, contentId uuid.UUID, err error)
+	FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error)
+	FindContentById(contentId uuid.UUID) (*models.LandingContent, error)
 	CreateLandingContentPreview(landingContentPreview *models.LandingContent) (*models.LandingContent, error)
 	UpdateLandingContentPreview(landingContentPreview *models.LandingContent) (*models.LandingContent, error)
 	FindLandingContentPreviewById(pageId uuid.UUID, language string) (*models.LandingContent, error)
@@ -50,8 +54,20 @@ func NewCMSLandingPageRepository(db *gorm.DB) *CMSLandingPageRepository {
 }
 
 func (r *CMSLandingPageRepository) CreateLandingPage(LandingPage *models.LandingPage) (*models.LandingPage, error) {
-	//Create the LandingPage
-	if err := r.db.Create(LandingPage).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		//Create the LandingPage
+		if err := tx.Create(LandingPage).Error; err != nil {
+			return err
+		}
+
+		for _, content := range LandingPage.Contents {
+			if err := recordContentSaved(tx, landingContentEventData(content)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -80,28 +96,7 @@ func (r *CMSLandingPageRepository) FindAllLandingPage(query dto.LandingPageQuery
 	baseQuery := r.db.Model(&models.LandingPage{}).
 		Joins("JOIN landing_contents ON landing_contents.page_id = landing_pages.id").
 		Where("landing_contents.mode != ? AND landing_contents.mode != ?", "Histories", "Preview")
-
-	// Apply filters
-	if query.Title != "" {
-		baseQuery = baseQuery.Where("landing_contents.title ILIKE ?", "%"+query.Title+"%")
-	}
-	if query.UrlAlias != "" {
-		baseQuery = baseQuery.Where("landing_contents.url_alias ILIKE ?", "%"+query.UrlAlias+"%")
-	}
-	if query.Status != "" {
-		baseQuery = baseQuery.Where("landing_contents.workflow_status = ?", query.Status)
-	}
-	if language != "" {
-		baseQuery = baseQuery.Where("landing_contents.language = ?", language)
-	}
-	if query.CategoryKeywords != "" {
-		categorySubQuery := r.db.Table("landing_content_categories").
-			Select("landing_content_categories.landing_content_id").
-			Joins("JOIN categories ON landing_content_categories.category_id = categories.id").
-			Joins("JOIN category_types ON category_types.id = categories.category_type_id").
-			Where("category_types.type_code = ? AND categories.name ILIKE ?", "category-keywords", "%"+query.CategoryKeywords+"%")
-		baseQuery = baseQuery.Where("landing_contents.id IN (?)", categorySubQuery)
-	}
+	baseQuery = r.applyLandingContentFilters(baseQuery, query, language)
 
 	// Clone query for counting to avoid modification
 	countQuery := baseQuery.Session(&gorm.Session{})
@@ -160,6 +155,7 @@ func (r *CMSLandingPageRepository) FindAllLandingPage(query dto.LandingPageQuery
 	offset := (page - 1) * limit
 	err := finalQuery.
 		Order(sortColumn).
+		Order("landing_pages.id").
 		Offset(offset).
 		Limit(limit).
 		Preload("Contents", func(db *gorm.DB) *gorm.DB {
@@ -181,6 +177,93 @@ func (r *CMSLandingPageRepository) FindAllLandingPage(query dto.LandingPageQuery
 
 	return landingPages, totalCount, nil
 }
+
+// applyLandingContentFilters narrows a query over landing_contents to the contents matching the list filters
+func (r *CMSLandingPageRepository) applyLandingContentFilters(db *gorm.DB, query dto.LandingPageQuery, language string) *gorm.DB {
+	if query.Title != "" {
+		db = db.Where("landing_contents.title ILIKE ?", "%"+query.Title+"%")
+	}
+	if query.UrlAlias != "" {
+		db = db.Where("landing_contents.url_alias ILIKE ?", "%"+query.UrlAlias+"%")
+	}
+	if query.Status != "" {
+		db = db.Where("landing_contents.workflow_status = ?", query.Status)
+	}
+	if language != "" {
+		db = db.Where("landing_contents.language = ?", language)
+	}
+	db = landingContentTables.applyPageGrants(db, query.Access)
+	if query.CategoryKeywords != "" {
+		categorySubQuery := r.db.Table("landing_content_categories").
+			Select("landing_content_categories.landing_content_id").
+			Joins("JOIN categories ON landing_content_categories.category_id = categories.id").
+			Joins("JOIN category_types ON category_types.id = categories.category_type_id").
+			Where("category_types.type_code = ? AND categories.name ILIKE ?", "category-keywords", "%"+query.CategoryKeywords+"%")
+		db = db.Where("landing_contents.id IN (?)", categorySubQuery)
+	}
+
+	return db
+}
+
+// FindLandingPagesByCursor is the keyset paginated counterpart of FindAllLandingPage. Pages are matched with EXISTS over their contents
+// instead of a join, so no DISTINCT or GROUP BY is needed, and they are only counted when asked to.
+func (r *CMSLandingPageRepository) FindLandingPagesByCursor(query dto.LandingPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.LandingPage, *dto.CursorPage, error) {
+	keyset, err := timestampKeysetSort("landing_pages", sort)
+	if err != nil {
+		return nil, nil, err
+	}
+	cursor, limit, err := decodeCursorQuery(cursorQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contents := r.applyLandingContentFilters(
+		r.db.Table("landing_contents").
+			Select("1").
+			Where("landing_contents.page_id = landing_pages.id").
+			Where("landing_contents.mode != ? AND landing_contents.mode != ?", "Histories", "Preview"),
+		query, language)
+	baseQuery := r.db.Model(&models.LandingPage{}).Where("EXISTS (?)", contents)
+
+	var totalCount *int64
+	if cursorQuery.WithTotal {
+		var count int64
+		if err := baseQuery.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return nil, nil, err
+		}
+		totalCount = &count
+	}
+
+	pageQuery, err := keyset.apply(baseQuery, cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var landingPages []models.LandingPage
+	if err := pageQuery.
+		Preload("Contents", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Where("landing_contents.mode != ? AND landing_contents.mode != ?", "Histories", "Preview").
+				Order("landing_contents.created_at DESC")
+		}).
+		Preload("Contents.Revision").
+		Preload("Contents.Categories").
+		Preload("Contents.Categories.CategoryType").
+		Preload("Contents.Components").
+		Preload("Contents.MetaTag").
+		Preload("Contents.Files").
+		Find(&landingPages).Error; err != nil {
+		return nil, nil, err
+	}
+
+	landingPages, page := keysetPage(landingPages, keyset, cursor, limit, func(p models.LandingPage) ([]interface{}, uuid.UUID) {
+		return []interface{}{keysetValue(keyset, p.CreatedAt, p.UpdatedAt)}, p.ID
+	})
+	page.TotalCount = totalCount
+
+	return landingPages, &page, nil
+}
+
 func (r *CMSLandingPageRepository) FindLandingPageById(id uuid.UUID) (*models.LandingPage, error) {
 	var LandingPage models.LandingPage
 
@@ -263,11 +346,15 @@ func (r *CMSLandingPageRepository) UpdateLandingContent(updateLandingContent *mo
 			return fmt.Errorf("failed to create new content version: %w", err)
 		}
 
+		if err := copyContentRelations(tx, prevContentId, updateLandingContent.ID, updateLandingContent.PageID, updateLandingContent.Language); err != nil {
+			return err
+		}
+
 		if err := tx.Model(&models.LandingPage{}).Where("id = ?", updateLandingContent.PageID).Update("updated_at", time.Now()).Error; err != nil {
 			return fmt.Errorf("failed to update page timestamp: %w", err)
 		}
 
-		return nil
+		return recordContentSaved(tx, landingContentEventData(updateLandingContent))
 	})
 
 	if err != nil {
@@ -289,7 +376,7 @@ func (r *CMSLandingPageRepository) UpdateLandingContent(updateLandingContent *mo
 	return updateLandingContent, nil
 }
 
-func (r *CMSLandingPageRepository) DeleteLandingPage(id uuid.UUID) error {
+func (r *CMSLandingPageRepository) DeleteLandingPage(id uuid.UUID, force bool) error {
 	err := r.db.Transaction(func(tx *gorm.DB) error {
 
 		var contents []models.LandingContent
@@ -303,6 +390,10 @@ func (r *CMSLandingPageRepository) DeleteLandingPage(id uuid.UUID) error {
 			return err
 		}
 
+		if err := ensureUnused(tx, enums.UsageItemLandingPage, id, force); err != nil {
+			return err
+		}
+
 		// Step 1: Get all LandingContent entries for this page
 		if err := tx.Where("page_id = ?", id).Find(&contents).Error; err != nil {
 			return err
@@ -346,7 +437,7 @@ func (r *CMSLandingPageRepository) DeleteLandingPage(id uuid.UUID) error {
 			return err
 		}
 
-		return nil
+		return recordContentDeleted(tx, dto.ContentEventData{PageType: enums.PageTypeLanding, PageID: id})
 	})
 
 	if err != nil {
@@ -388,7 +479,14 @@ func (r *CMSLandingPageRepository) FindLatestContentByPageId(pageId uuid.UUID, l
 
 // Might be deprecate
 func (r *CMSLandingPageRepository) CreateContentForLandingPage(LandingContent *models.LandingContent, lang string, mode string) (*models.LandingContent, error) {
-	if err := r.db.Create(LandingContent).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(LandingContent).Error; err != nil {
+			return err
+		}
+
+		return recordContentSaved(tx, landingContentEventData(LandingContent))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -424,7 +522,7 @@ func (r *CMSLandingPageRepository) DeleteLandingContent(pageId uuid.UUID, lang,
 			return err
 		}
 
-		return nil
+		return recordContentDeleted(tx, landingContentEventData(&LandingContent))
 	})
 
 	if err != nil {
@@ -523,7 +621,20 @@ func (r *CMSLandingPageRepository) DuplicateLandingPage(pageId uuid.UUID) (*mode
 	copyLandingPage.Contents = newContents
 
 	// Create the new landingPage
-	if err := r.db.Create(&copyLandingPage).Error; err != nil {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&copyLandingPage).Error; err != nil {
+			return err
+		}
+
+		// The copies keep the relations of the contents they were made from
+		for i, content := range newContents {
+			if err := copyContentRelations(tx, landingContents[i].ID, content.ID, copyLandingPage.ID, content.Language); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -579,7 +690,17 @@ func (r *CMSLandingPageRepository) DuplicateLandingContentToAnotherLanguage(cont
 		}
 	}
 
-	if err := r.db.Create(&landingContent).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&landingContent).Error; err != nil {
+			return err
+		}
+		if err := copyContentRelations(tx, contentId, landingContent.ID, landingContent.PageID, landingContent.Language); err != nil {
+			return err
+		}
+
+		return recordContentSaved(tx, landingContentEventData(&landingContent))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -610,6 +731,7 @@ func (r *CMSLandingPageRepository) RevertLandingContent(revisionId uuid.UUID, ne
 		return nil, err
 	}
 
+	revertedContentId := LandingContent.ID
 	err = r.db.Transaction(func(tx *gorm.DB) error {
 		// Update the old content
 
@@ -653,8 +775,11 @@ func (r *CMSLandingPageRepository) RevertLandingContent(revisionId uuid.UUID, ne
 		if err := tx.Create(LandingContent).Error; err != nil {
 			return err
 		}
+		if err := copyContentRelations(tx, revertedContentId, LandingContent.ID, LandingContent.PageID, LandingContent.Language); err != nil {
+			return err
+		}
 
-		return nil
+		return recordContentSaved(tx, landingContentEventData(LandingContent))
 	})
 
 	if err != nil {
@@ -767,7 +892,14 @@ func (r *CMSLandingPageRepository) GetPageIdByContentId(contentId uuid.UUID) (uu
 func (r *CMSLandingPageRepository) CreateLandingContentPreview(landingContentPreview *models.LandingContent) (*models.LandingContent, error) {
 	landingContentPreview.Revision = nil
 	landingContentPreview.Categories = nil
-	if err := r.db.Create(landingContentPreview).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(landingContentPreview).Error; err != nil {
+			return err
+		}
+
+		return copyLatestContentRelations(tx, enums.PageTypeLanding, landingContentPreview.ID, landingContentPreview.PageID, landingContentPreview.Language)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -788,7 +920,7 @@ func (r *CMSLandingPageRepository) UpdateLandingContentPreview(landingContentPre
 			return err
 		}
 
-		return nil
+		return copyLatestContentRelations(tx, enums.PageTypeLanding, landingContentPreview.ID, landingContentPreview.PageID, landingContentPreview.Language)
 	})
 
 	if err != nil {
@@ -810,3 +942,26 @@ func (r *CMSLandingPageRepository) FindLandingContentPreviewById(pageId uuid.UUI
 
 	return &landingContentPreview, nil
 }
+
+// GetContentRefByRevisionId returns the page and the content the revision belongs to
+func (r *CMSLandingPageRepository) GetContentRefByRevisionId(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	return landingContentTables.getContentRefByRevisionId(r.db, revisionId)
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the page
+func (r *CMSLandingPageRepository) FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return landingContentTables.findContentScopes(r.db, pageId)
+}
+
+// FindContentById returns a content with what the publish audit looks at
+func (r *CMSLandingPageRepository) FindContentById(contentId uuid.UUID) (*models.LandingContent, error) {
+	var landingContent models.LandingContent
+	if err := r.db.
+		Preload("MetaTag").
+		Preload("Components").
+		First(&landingContent, "id = ?", contentId).Error; err != nil {
+		return nil, err
+	}
+
+	return &landingContent, nil
+}
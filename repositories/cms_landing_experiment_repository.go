@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CMSLandingExperimentRepositoryInterface interface {
+	CreateExperiment(experiment *models.LandingExperiment) error
+	FindExperimentById(id uuid.UUID) (*models.LandingExperiment, error)
+	FindExperiments(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error)
+	FindRunningExperiment(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error)
+	FindPublishedContent(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error)
+	CreateEvent(event *models.LandingExperimentEvent) error
+	FindExposure(experimentId uuid.UUID, assignmentHash string) (*models.LandingExperimentEvent, error)
+	CountEvents(experimentId uuid.UUID) ([]dto.ExperimentEventCount, error)
+	CompleteExperiment(id uuid.UUID, winnerVariantId *uuid.UUID, endedAt time.Time) (int64, error)
+	FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+type CMSLandingExperimentRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSLandingExperimentRepository(db *gorm.DB) *CMSLandingExperimentRepository {
+	return &CMSLandingExperimentRepository{db: db}
+}
+
+func preloadExperimentVariants(db *gorm.DB) *gorm.DB {
+	return db.Order("landing_experiment_variants.is_control DESC, landing_experiment_variants.name")
+}
+
+// CreateExperiment inserts the experiment with its variants
+func (r *CMSLandingExperimentRepository) CreateExperiment(experiment *models.LandingExperiment) error {
+	return r.db.Create(experiment).Error
+}
+
+func (r *CMSLandingExperimentRepository) FindExperimentById(id uuid.UUID) (*models.LandingExperiment, error) {
+	var experiment models.LandingExperiment
+	if err := r.db.Preload("Variants", preloadExperimentVariants).First(&experiment, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &experiment, nil
+}
+
+func (r *CMSLandingExperimentRepository) FindExperiments(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error) {
+	query := r.db.Model(&models.LandingExperiment{}).Preload("Variants", preloadExperimentVariants)
+	if pageId != nil {
+		query = query.Where("page_id = ?", *pageId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	experiments := []models.LandingExperiment{}
+	if err := query.Order("created_at DESC").Find(&experiments).Error; err != nil {
+		return nil, err
+	}
+
+	return experiments, nil
+}
+
+// FindRunningExperiment returns gorm.ErrRecordNotFound when the page language is not under experiment
+func (r *CMSLandingExperimentRepository) FindRunningExperiment(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error) {
+	var experiment models.LandingExperiment
+	err := r.db.
+		Preload("Variants", preloadExperimentVariants).
+		Where("page_id = ? AND language = ? AND status = ?", pageId, language, enums.ExperimentStatusRunning).
+		First(&experiment).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &experiment, nil
+}
+
+// FindPublishedContent loads the live content of the page language with everything needed to save it again
+func (r *CMSLandingExperimentRepository) FindPublishedContent(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error) {
+	var content models.LandingContent
+	err := r.db.
+		Preload("MetaTag").
+		Preload("Revision").
+		Preload("Components").
+		Preload("Files").
+		Preload("Categories").
+		Where("page_id = ? AND language = ? AND workflow_status = ? AND mode NOT IN ?",
+			pageId, language, enums.WorkflowPublished, []enums.PageMode{enums.PageModeHistories, enums.PageModePreview}).
+		Order("created_at DESC").
+		First(&content).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &content, nil
+}
+
+// CreateEvent keeps the first event of the visitor and type, repeated ones are ignored
+func (r *CMSLandingExperimentRepository) CreateEvent(event *models.LandingExperimentEvent) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error
+}
+
+func (r *CMSLandingExperimentRepository) FindExposure(experimentId uuid.UUID, assignmentHash string) (*models.LandingExperimentEvent, error) {
+	var event models.LandingExperimentEvent
+	err := r.db.
+		Where("experiment_id = ? AND assignment_hash = ? AND type = ?", experimentId, assignmentHash, enums.ExperimentEventExposure).
+		First(&event).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (r *CMSLandingExperimentRepository) CountEvents(experimentId uuid.UUID) ([]dto.ExperimentEventCount, error) {
+	counts := []dto.ExperimentEventCount{}
+	err := r.db.Model(&models.LandingExperimentEvent{}).
+		Select("variant_id, type, COUNT(*) AS visitors").
+		Where("experiment_id = ?", experimentId).
+		Group("variant_id, type").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// CompleteExperiment stops a running experiment, or records the winner of one that has none yet.
+// It returns 0 when the experiment was already in that state.
+func (r *CMSLandingExperimentRepository) CompleteExperiment(id uuid.UUID, winnerVariantId *uuid.UUID, endedAt time.Time) (int64, error) {
+	query := r.db.Model(&models.LandingExperiment{}).Where("id = ?", id)
+	if winnerVariantId != nil {
+		query = query.Where("winner_variant_id IS NULL")
+	} else {
+		query = query.Where("status = ?", enums.ExperimentStatusRunning)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"status":            enums.ExperimentStatusCompleted,
+		"winner_variant_id": winnerVariantId,
+		"ended_at":          gorm.Expr("COALESCE(ended_at, ?)", endedAt),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the landing page
+func (r *CMSLandingExperimentRepository) FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return landingContentTables.findContentScopes(r.db, pageId)
+}
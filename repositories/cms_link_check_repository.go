@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CMSLinkCheckRepositoryInterface interface {
+	FindPublishedLandingContents() ([]models.LandingContent, error)
+	FindPublishedPartnerContents() ([]models.PartnerContent, error)
+	FindPublishedFaqContents() ([]models.FaqContent, error)
+	IsContentPathRegistered(urlPath string) (bool, error)
+	IsMediaFileRegistered(downloadURL string) (bool, error)
+	UpsertLinkChecks(linkChecks []models.LinkCheck) error
+	DeleteLinkChecksCheckedBefore(checkedBefore time.Time) error
+	FindLinkChecks(query dto.LinkCheckQuery, page, limit int) ([]models.LinkCheck, int64, error)
+}
+
+type CMSLinkCheckRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSLinkCheckRepository(db *gorm.DB) *CMSLinkCheckRepository {
+	return &CMSLinkCheckRepository{db: db}
+}
+
+func (r *CMSLinkCheckRepository) FindPublishedLandingContents() ([]models.LandingContent, error) {
+	var landingContents []models.LandingContent
+	if err := r.db.
+		Preload("Components").
+		Where("workflow_status = ? AND mode != ?", enums.WorkflowPublished, enums.PageModeHistories).
+		Find(&landingContents).Error; err != nil {
+		return nil, err
+	}
+
+	return landingContents, nil
+}
+
+func (r *CMSLinkCheckRepository) FindPublishedPartnerContents() ([]models.PartnerContent, error) {
+	var partnerContents []models.PartnerContent
+	if err := r.db.
+		Preload("Components").
+		Where("workflow_status = ? AND mode != ?", enums.WorkflowPublished, enums.PageModeHistories).
+		Find(&partnerContents).Error; err != nil {
+		return nil, err
+	}
+
+	return partnerContents, nil
+}
+
+func (r *CMSLinkCheckRepository) FindPublishedFaqContents() ([]models.FaqContent, error) {
+	var faqContents []models.FaqContent
+	if err := r.db.
+		Preload("Components").
+		Where("workflow_status = ? AND mode != ?", enums.WorkflowPublished, enums.PageModeHistories).
+		Find(&faqContents).Error; err != nil {
+		return nil, err
+	}
+
+	return faqContents, nil
+}
+
+// IsContentPathRegistered checks the path against the url and url alias of every published content
+func (r *CMSLinkCheckRepository) IsContentPathRegistered(urlPath string) (bool, error) {
+	urlPath = strings.Trim(urlPath, "/")
+
+	registries := []struct {
+		model   interface{}
+		columns []string
+	}{
+		{&models.LandingContent{}, []string{"url_alias"}},
+		{&models.PartnerContent{}, []string{"url", "url_alias"}},
+		{&models.FaqContent{}, []string{"url", "url_alias"}},
+	}
+
+	for _, registry := range registries {
+		for _, column := range registry.columns {
+			var count int64
+			if err := r.db.Model(registry.model).
+				Where("TRIM(BOTH '/' FROM "+column+") = ? AND workflow_status = ? AND mode != ?", urlPath, enums.WorkflowPublished, enums.PageModeHistories).
+				Count(&count).Error; err != nil {
+				return false, err
+			}
+			if count > 0 {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (r *CMSLinkCheckRepository) IsMediaFileRegistered(downloadURL string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&models.MediaFile{}).
+		Where("download_url = ?", downloadURL).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *CMSLinkCheckRepository) UpsertLinkChecks(linkChecks []models.LinkCheck) error {
+	if len(linkChecks) == 0 {
+		return nil
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "content_id"}, {Name: "field"}, {Name: "url"}},
+		DoUpdates: clause.AssignmentColumns([]string{"page_type", "page_id", "language", "is_internal", "status", "status_code", "error_message", "last_checked_at", "updated_at"}),
+	}).CreateInBatches(linkChecks, 100).Error
+}
+
+// DeleteLinkChecksCheckedBefore drops links that were not seen by the latest run, they were removed or unpublished
+func (r *CMSLinkCheckRepository) DeleteLinkChecksCheckedBefore(checkedBefore time.Time) error {
+	return r.db.Where("last_checked_at < ?", checkedBefore).Delete(&models.LinkCheck{}).Error
+}
+
+func (r *CMSLinkCheckRepository) FindLinkChecks(query dto.LinkCheckQuery, page, limit int) ([]models.LinkCheck, int64, error) {
+	var linkChecks []models.LinkCheck
+	var totalCount int64
+
+	baseQuery := r.db.Model(&models.LinkCheck{})
+	if query.PageID != "" {
+		baseQuery = baseQuery.Where("page_id = ?", query.PageID)
+	}
+	if query.PageType != "" {
+		baseQuery = baseQuery.Where("page_type = ?", query.PageType)
+	}
+	if query.Status != "" {
+		baseQuery = baseQuery.Where("status = ?", query.Status)
+	}
+	if query.IsInternal != nil {
+		baseQuery = baseQuery.Where("is_internal = ?", *query.IsInternal)
+	}
+	baseQuery = applyContentGrants(baseQuery, query.Access, "link_checks.page_type", "link_checks.content_id")
+
+	if err := baseQuery.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := baseQuery.
+		Order("last_checked_at DESC, url ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&linkChecks).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return linkChecks, totalCount, nil
+}
@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSMaintenanceRepositoryInterface interface {
+	FindExpiredPreviewContentIds(pageType enums.PageType, now time.Time) ([]uuid.UUID, error)
+	FindExcessHistoryContentIds(pageType enums.PageType, keep int) ([]uuid.UUID, error)
+	PurgeContents(pageType enums.PageType, contentIds []uuid.UUID, dryRun bool) (map[string]int64, error)
+	PurgeOrphans(createdBefore time.Time, dryRun bool) (map[string]int64, error)
+}
+
+type CMSMaintenanceRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSMaintenanceRepository(db *gorm.DB) *CMSMaintenanceRepository {
+	return &CMSMaintenanceRepository{db: db}
+}
+
+// contentTables names the tables holding one page type's contents and their dependent rows
+type contentTables struct {
+	contents   string
+	categories string
+	files      string // Only landing contents have files
+	foreignKey string
+	feedbacks  string // Only faq contents have feedback votes
+}
+
+var contentTablesByPageType = map[enums.PageType]contentTables{
+	enums.PageTypeLanding: {"landing_contents", "landing_content_categories", "landing_content_files", "landing_content_id", ""},
+	enums.PageTypePartner: {"partner_contents", "partner_content_categories", "", "partner_content_id", ""},
+	enums.PageTypeFaq:     {"faq_contents", "faq_content_categories", "", "faq_content_id", "faq_feedbacks"},
+}
+
+// pageLanguageScope is one language of one page
+type pageLanguageScope struct {
+	PageID   uuid.UUID
+	Language enums.PageLanguage
+}
+
+// errDryRun rolls the transaction back once the dry run has counted the rows
+var errDryRun = errors.New("maintenance dry run")
+
+func (r *CMSMaintenanceRepository) FindExpiredPreviewContentIds(pageType enums.PageType, now time.Time) ([]uuid.UUID, error) {
+	tables, ok := contentTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	var contentIds []uuid.UUID
+	if err := r.db.Table(tables.contents).
+		Where("mode = ? AND expired_at < ?", enums.PageModePreview, now).
+		Pluck("id", &contentIds).Error; err != nil {
+		return nil, err
+	}
+
+	return contentIds, nil
+}
+
+// FindExcessHistoryContentIds returns the history versions beyond the newest keep ones of each page and language
+func (r *CMSMaintenanceRepository) FindExcessHistoryContentIds(pageType enums.PageType, keep int) ([]uuid.UUID, error) {
+	tables, ok := contentTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	var contentIds []uuid.UUID
+	query := fmt.Sprintf(`SELECT id FROM (
+		SELECT id, ROW_NUMBER() OVER (PARTITION BY page_id, language ORDER BY created_at DESC) AS version
+		FROM %s WHERE mode = ?
+	) AS ranked WHERE version > ?`, tables.contents)
+	if err := r.db.Raw(query, enums.PageModeHistories, keep).Scan(&contentIds).Error; err != nil {
+		return nil, err
+	}
+
+	return contentIds, nil
+}
+
+// PurgeContents deletes the contents with their components, categories, revisions, files, preview links, meta tags, autosaves,
+// link checks, relations and feedback votes. Experiments and analytics of a page language go with its last content.
+func (r *CMSMaintenanceRepository) PurgeContents(pageType enums.PageType, contentIds []uuid.UUID, dryRun bool) (map[string]int64, error) {
+	tables, ok := contentTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	removed := map[string]int64{}
+	if len(contentIds) == 0 {
+		return removed, nil
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var metaTagIds []uuid.UUID
+		if err := tx.Table(tables.contents).Where("id IN ?", contentIds).Pluck("meta_tag_id", &metaTagIds).Error; err != nil {
+			return err
+		}
+
+		var scopes []pageLanguageScope
+		if err := tx.Table(tables.contents).Distinct("page_id", "language").Where("id IN ?", contentIds).Scan(&scopes).Error; err != nil {
+			return err
+		}
+
+		steps := []struct {
+			table  string
+			column string
+			ids    []uuid.UUID
+		}{
+			{"components", tables.foreignKey, contentIds},
+			{tables.categories, tables.foreignKey, contentIds},
+			{"revisions", tables.foreignKey, contentIds},
+			{tables.files, tables.foreignKey, contentIds},
+			{"preview_links", "content_id", contentIds},
+			{"content_autosaves", "base_content_id", contentIds},
+			{"link_checks", "content_id", contentIds},
+			{"content_relations", "source_content_id", contentIds},
+			{tables.feedbacks, tables.foreignKey, contentIds},
+			{tables.contents, "id", contentIds},
+			{"meta_tags", "id", metaTagIds},
+		}
+
+		for _, step := range steps {
+			if step.table == "" || len(step.ids) == 0 {
+				continue
+			}
+			result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s IN ?", step.table, step.column), step.ids)
+			if result.Error != nil {
+				return result.Error
+			}
+			removed[step.table] += result.RowsAffected
+		}
+
+		if err := purgeEmptiedPageLanguages(tx, pageType, tables, scopes, removed); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+// purgeEmptiedPageLanguages deletes the analytics and experiments of the page languages left without any content
+func purgeEmptiedPageLanguages(tx *gorm.DB, pageType enums.PageType, tables contentTables, scopes []pageLanguageScope, removed map[string]int64) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	pageLanguages := make([][]interface{}, 0, len(scopes))
+	for _, scope := range scopes {
+		pageLanguages = append(pageLanguages, []interface{}{scope.PageID, scope.Language})
+	}
+	// The page languages of the purged contents that have no content left
+	emptied := func(table string) string {
+		return fmt.Sprintf(`(%[1]s.page_id, %[1]s.language) IN ?
+			AND NOT EXISTS (SELECT 1 FROM %[2]s WHERE %[2]s.page_id = %[1]s.page_id AND %[2]s.language = %[1]s.language)`, table, tables.contents)
+	}
+
+	type pageStep struct {
+		table     string
+		condition string
+		args      []interface{}
+	}
+	pageSteps := []pageStep{
+		{"page_views", "page_type = ? AND " + emptied("page_views"), []interface{}{pageType, pageLanguages}},
+		{"page_view_dailies", "page_type = ? AND " + emptied("page_view_dailies"), []interface{}{pageType, pageLanguages}},
+		{"page_visitor_dailies", "page_type = ? AND " + emptied("page_visitor_dailies"), []interface{}{pageType, pageLanguages}},
+	}
+	if pageType == enums.PageTypeLanding {
+		experiments := "SELECT id FROM landing_experiments WHERE " + emptied("landing_experiments")
+		pageSteps = append(pageSteps,
+			pageStep{"landing_experiment_events", "experiment_id IN (" + experiments + ")", []interface{}{pageLanguages}},
+			pageStep{"landing_experiment_variants", "experiment_id IN (" + experiments + ")", []interface{}{pageLanguages}},
+			pageStep{"landing_experiments", emptied("landing_experiments"), []interface{}{pageLanguages}},
+		)
+	}
+
+	for _, step := range pageSteps {
+		result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", step.table, step.condition), step.args...)
+		if result.Error != nil {
+			return result.Error
+		}
+		removed[step.table] += result.RowsAffected
+	}
+
+	return nil
+}
+
+// relationContentMissing matches the content relations whose source content was deleted
+func relationContentMissing() string {
+	return `NOT EXISTS (SELECT 1 FROM landing_contents WHERE content_relations.source_page_type = 'landing' AND landing_contents.id = content_relations.source_content_id)
+			AND NOT EXISTS (SELECT 1 FROM partner_contents WHERE content_relations.source_page_type = 'partner' AND partner_contents.id = content_relations.source_content_id)
+			AND NOT EXISTS (SELECT 1 FROM faq_contents WHERE content_relations.source_page_type = 'faq' AND faq_contents.id = content_relations.source_content_id)`
+}
+
+// relationPageMissing matches the content relations whose target page was deleted
+func relationPageMissing(side string) string {
+	return fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM landing_pages WHERE content_relations.%[1]s_page_type = 'landing' AND landing_pages.id = content_relations.%[1]s_page_id)
+			AND NOT EXISTS (SELECT 1 FROM partner_pages WHERE content_relations.%[1]s_page_type = 'partner' AND partner_pages.id = content_relations.%[1]s_page_id)
+			AND NOT EXISTS (SELECT 1 FROM faq_pages WHERE content_relations.%[1]s_page_type = 'faq' AND faq_pages.id = content_relations.%[1]s_page_id)`, side)
+}
+
+// PurgeOrphans deletes components, revisions and meta tags no content refers to anymore, and relations of deleted contents or pages
+func (r *CMSMaintenanceRepository) PurgeOrphans(createdBefore time.Time, dryRun bool) (map[string]int64, error) {
+	removed := map[string]int64{}
+
+	orphans := []struct {
+		table     string
+		condition string
+	}{
+		{"components", `NOT EXISTS (SELECT 1 FROM landing_contents WHERE landing_contents.id = components.landing_content_id)
+			AND NOT EXISTS (SELECT 1 FROM partner_contents WHERE partner_contents.id = components.partner_content_id)
+			AND NOT EXISTS (SELECT 1 FROM faq_contents WHERE faq_contents.id = components.faq_content_id)`},
+		{"revisions", `NOT EXISTS (SELECT 1 FROM landing_contents WHERE landing_contents.id = revisions.landing_content_id)
+			AND NOT EXISTS (SELECT 1 FROM partner_contents WHERE partner_contents.id = revisions.partner_content_id)
+			AND NOT EXISTS (SELECT 1 FROM faq_contents WHERE faq_contents.id = revisions.faq_content_id)`},
+		{"meta_tags", `NOT EXISTS (SELECT 1 FROM landing_contents WHERE landing_contents.meta_tag_id = meta_tags.id)
+			AND NOT EXISTS (SELECT 1 FROM partner_contents WHERE partner_contents.meta_tag_id = meta_tags.id)
+			AND NOT EXISTS (SELECT 1 FROM faq_contents WHERE faq_contents.meta_tag_id = meta_tags.id)`},
+		{"content_relations", "((" + relationContentMissing() + ") OR (" + relationPageMissing("target") + "))"},
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, orphan := range orphans {
+			result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE created_at < ? AND %s", orphan.table, orphan.condition), createdBefore)
+			if result.Error != nil {
+				return result.Error
+			}
+			removed[orphan.table] += result.RowsAffected
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	return removed, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/MadManJJ/cms-api/dto" // For filter DTO
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -14,10 +15,12 @@ import (
 
 type MediaFileRepositoryInterface interface {
 	Create(file *models.MediaFile) (*models.MediaFile, error)
+	Replace(oldId uuid.UUID, file *models.MediaFile) (*models.MediaFile, error)
 	FindByID(id uuid.UUID) (*models.MediaFile, error)
 	FindByNameAndPath(name string, path string) (*models.MediaFile, error)
 	List(filter dto.MediaFileListFilter) ([]models.MediaFile, int64, error)
-	Delete(id uuid.UUID) error
+	ListByCursor(filter dto.MediaFileListFilter, cursorQuery dto.CursorQuery) ([]models.MediaFile, *dto.CursorPage, error)
+	Delete(id uuid.UUID, force bool) error
 }
 
 type mediaFileRepository struct {
@@ -29,12 +32,46 @@ func NewMediaFileRepository(db *gorm.DB) MediaFileRepositoryInterface {
 }
 
 func (r *mediaFileRepository) Create(file *models.MediaFile) (*models.MediaFile, error) {
-	if err := r.db.Create(file).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(file).Error; err != nil {
+			return err
+		}
+		return recordMediaFileUploaded(tx, file, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Replace swaps the record of an existing file for the one uploaded in its place
+func (r *mediaFileRepository) Replace(oldId uuid.UUID, file *models.MediaFile) (*models.MediaFile, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.MediaFile{}, "id = ?", oldId).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(file).Error; err != nil {
+			return err
+		}
+		return recordMediaFileUploaded(tx, file, &oldId)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return file, nil
 }
 
+// recordMediaFileUploaded records the upload, replacedId is the file the upload took the place of
+func recordMediaFileUploaded(tx *gorm.DB, file *models.MediaFile, replacedId *uuid.UUID) error {
+	return appendOutboxEvent(tx, enums.DomainEventMediaFileUploaded, file.ID, dto.MediaFileEventData{
+		MediaFileID: file.ID,
+		Name:        file.Name,
+		DownloadURL: file.DownloadURL,
+		Replaced:    replacedId != nil,
+		ReplacedID:  replacedId,
+	})
+}
+
 func (r *mediaFileRepository) FindByID(id uuid.UUID) (*models.MediaFile, error) {
 	var file models.MediaFile
 	if err := r.db.First(&file, "id = ?", id).Error; err != nil {
@@ -71,12 +108,7 @@ func (r *mediaFileRepository) List(filter dto.MediaFileListFilter) ([]models.Med
 	var files []models.MediaFile
 	var total int64
 
-	query := r.db.Model(&models.MediaFile{})
-
-	if filter.Search != nil && *filter.Search != "" {
-		// PostgreSQL case-insensitive search
-		query = query.Where("name ILIKE ?", "%"+strings.ToLower(*filter.Search)+"%")
-	}
+	query := r.searchQuery(filter)
 
 	// Count total records matching the filter (before pagination)
 	if err := query.Count(&total).Error; err != nil {
@@ -100,6 +132,9 @@ func (r *mediaFileRepository) List(filter dto.MediaFileListFilter) ([]models.Med
 		query = query.Order("created_at DESC") // Default sort
 	}
 
+	// Files sharing a name or upload time keep their place across pages
+	query = query.Order("id")
+
 	// Apply pagination
 	page := 1
 	if filter.Page > 0 {
@@ -119,13 +154,89 @@ func (r *mediaFileRepository) List(filter dto.MediaFileListFilter) ([]models.Med
 	return files, total, nil
 }
 
-func (r *mediaFileRepository) Delete(id uuid.UUID) error {
-	result := r.db.Delete(&models.MediaFile{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
+// ListByCursor is the keyset paginated counterpart of List, Page and PageSize of the filter are ignored
+func (r *mediaFileRepository) ListByCursor(filter dto.MediaFileListFilter, cursorQuery dto.CursorQuery) ([]models.MediaFile, *dto.CursorPage, error) {
+	cursor, limit, err := decodeCursorQuery(cursorQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Same sorts as List, created_at DESC unless a valid column is asked for
+	keyset := keysetSort{key: "created_at:desc", columns: []keysetColumn{{"created_at", keysetTime}}, idColumn: "id", desc: true}
+	if filter.SortBy != nil && (*filter.SortBy == "name" || *filter.SortBy == "created_at") {
+		keyset.desc = filter.Order != nil && strings.ToLower(*filter.Order) == "desc"
+		keyset.key = *filter.SortBy + ":asc"
+		if keyset.desc {
+			keyset.key = *filter.SortBy + ":desc"
+		}
+		if *filter.SortBy == "name" {
+			keyset.columns = []keysetColumn{{"name", keysetString}}
+		}
 	}
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound // Or a custom "not found" error
+
+	var total int64
+	if cursorQuery.WithTotal {
+		if err := r.searchQuery(filter).Count(&total).Error; err != nil {
+			return nil, nil, fmt.Errorf("error counting media files: %w", err)
+		}
 	}
-	return nil
+
+	query, err := keyset.apply(r.searchQuery(filter), cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []models.MediaFile
+	if err := query.Find(&files).Error; err != nil {
+		return nil, nil, fmt.Errorf("error listing media files: %w", err)
+	}
+
+	files, page := keysetPage(files, keyset, cursor, limit, func(file models.MediaFile) ([]interface{}, uuid.UUID) {
+		if keyset.columns[0].kind == keysetString {
+			return []interface{}{file.Name}, file.ID
+		}
+		return []interface{}{file.CreatedAt}, file.ID
+	})
+	if cursorQuery.WithTotal {
+		page.TotalCount = &total
+	}
+
+	return files, &page, nil
+}
+
+func (r *mediaFileRepository) searchQuery(filter dto.MediaFileListFilter) *gorm.DB {
+	query := r.db.Model(&models.MediaFile{})
+
+	if filter.Search != nil && *filter.Search != "" {
+		// PostgreSQL case-insensitive search
+		query = query.Where("name ILIKE ?", "%"+strings.ToLower(*filter.Search)+"%")
+	}
+
+	return query
+}
+
+func (r *mediaFileRepository) Delete(id uuid.UUID, force bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var file models.MediaFile
+		if err := tx.First(&file, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := ensureUnused(tx, enums.UsageItemMediaFile, id, force); err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.MediaFile{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound // Or a custom "not found" error
+		}
+
+		return appendOutboxEvent(tx, enums.DomainEventMediaFileDeleted, file.ID, dto.MediaFileEventData{
+			MediaFileID: file.ID,
+			Name:        file.Name,
+			DownloadURL: file.DownloadURL,
+		})
+	})
 }
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepositoryInterface interface {
+	CreateEvents(events []*models.OutboxEvent) error
+	FanOutEvents(limit int, subscribers func(eventType enums.DomainEventType) []string) (int, error)
+	ClaimDueDeliveries(now time.Time, limit int, leaseUntil time.Time) ([]models.OutboxDelivery, error)
+	UpdateDelivery(delivery *models.OutboxDelivery) error
+	FindDeliveries(query dto.OutboxDeliveryQuery, page, limit int) ([]models.OutboxDelivery, int64, error)
+	FindDeliveryByID(id uuid.UUID) (*models.OutboxDelivery, error)
+	DeleteHandledEventsBefore(before time.Time) (int64, error)
+}
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// appendOutboxEvent records an event in the transaction of the change it describes,
+// it is only seen by the dispatcher once that transaction commits
+func appendOutboxEvent(tx *gorm.DB, eventType enums.DomainEventType, aggregateId uuid.UUID, payload interface{}) error {
+	event, err := helpers.NewOutboxEvent(eventType, aggregateId, "", payload)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(event).Error
+}
+
+func recordContentSaved(tx *gorm.DB, data dto.ContentEventData) error {
+	return appendOutboxEvent(tx, enums.DomainEventContentSaved, data.PageID, data)
+}
+
+func recordContentDeleted(tx *gorm.DB, data dto.ContentEventData) error {
+	return appendOutboxEvent(tx, enums.DomainEventContentDeleted, data.PageID, data)
+}
+
+func landingContentEventData(content *models.LandingContent) dto.ContentEventData {
+	data := dto.ContentEventData{
+		PageType:       enums.PageTypeLanding,
+		PageID:         content.PageID,
+		ContentID:      &content.ID,
+		Language:       content.Language,
+		Mode:           content.Mode,
+		Title:          content.Title,
+		Path:           content.UrlAlias,
+		WorkflowStatus: content.WorkflowStatus,
+		ApprovalEmail:  content.ApprovalEmail,
+	}
+	if content.Revision != nil {
+		data.Author = content.Revision.Author
+	}
+	return data
+}
+
+func partnerContentEventData(content *models.PartnerContent) dto.ContentEventData {
+	data := dto.ContentEventData{
+		PageType:       enums.PageTypePartner,
+		PageID:         content.PageID,
+		ContentID:      &content.ID,
+		Language:       content.Language,
+		Mode:           content.Mode,
+		Title:          content.Title,
+		Path:           content.URL,
+		WorkflowStatus: content.WorkflowStatus,
+		ApprovalEmail:  content.ApprovalEmail,
+	}
+	if content.Revision != nil {
+		data.Author = content.Revision.Author
+	}
+	return data
+}
+
+func faqContentEventData(content *models.FaqContent) dto.ContentEventData {
+	data := dto.ContentEventData{
+		PageType:       enums.PageTypeFaq,
+		PageID:         content.PageID,
+		ContentID:      &content.ID,
+		Language:       content.Language,
+		Mode:           content.Mode,
+		Title:          content.Title,
+		Path:           content.URL,
+		WorkflowStatus: content.WorkflowStatus,
+	}
+	if content.Revision != nil {
+		data.Author = content.Revision.Author
+	}
+	return data
+}
+
+// CreateEvents records events outside of any other change, an event whose idempotency key is already known is dropped
+func (r *OutboxRepository) CreateEvents(events []*models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	return r.db.
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "idempotency_key"}}, DoNothing: true}).
+		Create(&events).Error
+}
+
+// FanOutEvents queues a delivery of every new event for each of its subscribers and marks the event dispatched.
+// Events are locked while fanned out, so instances running side by side split them. Returns how many events were fanned out.
+func (r *OutboxRepository) FanOutEvents(limit int, subscribers func(eventType enums.DomainEventType) []string) (int, error) {
+	var events []models.OutboxEvent
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL").
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		ids := make([]uuid.UUID, 0, len(events))
+		var deliveries []models.OutboxDelivery
+		for _, event := range events {
+			ids = append(ids, event.ID)
+			for _, subscriber := range subscribers(event.EventType) {
+				deliveries = append(deliveries, models.OutboxDelivery{
+					EventID:       event.ID,
+					Subscriber:    subscriber,
+					Status:        enums.OutboxDeliveryPending,
+					NextAttemptAt: now,
+				})
+			}
+		}
+
+		if len(deliveries) > 0 {
+			if err := tx.
+				Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "event_id"}, {Name: "subscriber"}}, DoNothing: true}).
+				CreateInBatches(deliveries, 100).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("dispatched_at", now).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+// ClaimDueDeliveries locks the pending deliveries that are due and moves their next attempt to leaseUntil,
+// so other instances skip them while they are handled. A delivery whose handler died is picked up again after the lease.
+func (r *OutboxRepository) ClaimDueDeliveries(now time.Time, limit int, leaseUntil time.Time) ([]models.OutboxDelivery, error) {
+	var deliveries []models.OutboxDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", enums.OutboxDeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.OutboxDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", leaseUntil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	eventIds := make([]uuid.UUID, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		eventIds = append(eventIds, delivery.EventID)
+	}
+	var events []models.OutboxEvent
+	if err := r.db.Where("id IN ?", eventIds).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	eventsById := make(map[uuid.UUID]*models.OutboxEvent, len(events))
+	for i := range events {
+		eventsById[events[i].ID] = &events[i]
+	}
+	for i := range deliveries {
+		deliveries[i].Event = eventsById[deliveries[i].EventID]
+	}
+
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the outcome of an attempt, or a dead delivery queued again
+func (r *OutboxRepository) UpdateDelivery(delivery *models.OutboxDelivery) error {
+	return r.db.Model(&models.OutboxDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_error":      delivery.LastError,
+			"processed_at":    delivery.ProcessedAt,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+func (r *OutboxRepository) FindDeliveries(query dto.OutboxDeliveryQuery, page, limit int) ([]models.OutboxDelivery, int64, error) {
+	var deliveries []models.OutboxDelivery
+	var totalCount int64
+
+	baseQuery := r.db.Model(&models.OutboxDelivery{})
+	if query.Subscriber != "" {
+		baseQuery = baseQuery.Where("outbox_deliveries.subscriber = ?", query.Subscriber)
+	}
+	if query.EventType != "" {
+		baseQuery = baseQuery.
+			Joins("JOIN outbox_events ON outbox_events.id = outbox_deliveries.event_id").
+			Where("outbox_events.event_type = ?", query.EventType)
+	}
+	if query.Status != "" {
+		baseQuery = baseQuery.Where("outbox_deliveries.status = ?", query.Status)
+	}
+
+	if err := baseQuery.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := baseQuery.
+		Preload("Event").
+		Order("outbox_deliveries.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, totalCount, nil
+}
+
+func (r *OutboxRepository) FindDeliveryByID(id uuid.UUID) (*models.OutboxDelivery, error) {
+	var delivery models.OutboxDelivery
+	if err := r.db.Preload("Event").First(&delivery, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// DeleteHandledEventsBefore removes the events dispatched before the given time whose every delivery succeeded,
+// dead and pending deliveries keep their event
+func (r *OutboxRepository) DeleteHandledEventsBefore(before time.Time) (int64, error) {
+	result := r.db.
+		Where("dispatched_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM outbox_deliveries WHERE outbox_deliveries.event_id = outbox_events.id AND outbox_deliveries.status <> ?)", enums.OutboxDeliverySucceeded).
+		Delete(&models.OutboxEvent{})
+
+	return result.RowsAffected, result.Error
+}
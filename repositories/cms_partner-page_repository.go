@@ -21,9 +21,10 @@ import (
 type CMSPartnerPageRepositoryInterface interface {
 	CreatePartnerPage(PartnerPage *models.PartnerPage) (*models.PartnerPage, error)
 	FindAllPartnerPage(query dto.PartnerPageQuery, sort string, page, limit int, language string) ([]models.PartnerPage, int64, error)
+	FindPartnerPagesByCursor(query dto.PartnerPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.PartnerPage, *dto.CursorPage, error)
 	FindPartnerPageById(id uuid.UUID) (*models.PartnerPage, error)
 	UpdatePartnerContent(updatePartnerContent *models.PartnerContent, prevContentId uuid.UUID) (*models.PartnerContent, error)
-	DeletePartnerPage(id uuid.UUID) error
+	DeletePartnerPage(id uuid.UUID, force bool) error
 	FindContentByPartnerPageId(pageId uuid.UUID, language string, mode string) (*models.PartnerContent, error)
 	FindLatestContentByPageId(pageId uuid.UUID, language string) (*models.PartnerContent, error)
 	// Deprecate
@@ -37,6 +38,9 @@ type CMSPartnerPageRepositoryInterface interface {
 	IsUrlDuplicate(url string, pageId uuid.UUID) (bool, error)
 	IsUrlAliasDuplicate(urlAlias string, pageId uuid.UUID) (bool, error)
 	GetPageIdByContentId(contentId uuid.UUID) (uuid.UUID, error)
+	GetContentRefByRevisionId(revisionId uuid.UUID) (pageId uuid.UUID, contentId uuid.UUID, err error)
+	FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error)
+	FindContentById(contentId uuid.UUID) (*models.PartnerContent, error)
 	CreatePartnerContentPreview(partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error)
 	UpdatePartnerContentPreview(partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error)
 	FindPartnerContentPreviewById(pageId uuid.UUID, language string) (*models.PartnerContent, error)
@@ -57,6 +61,11 @@ func (r *CMSPartnerPageRepository) CreatePartnerPage(PartnerPage *models.Partner
 			return err
 		}
 
+		for _, content := range PartnerPage.Contents {
+			if err := recordContentSaved(r, partnerContentEventData(content)); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 
@@ -89,44 +98,7 @@ func (r *CMSPartnerPageRepository) FindAllPartnerPage(query dto.PartnerPageQuery
 	baseQuery := r.db.Model(&models.PartnerPage{}).
 		Joins("JOIN partner_contents ON partner_contents.page_id = partner_pages.id").
 		Where("partner_contents.mode != ? AND partner_contents.mode != ?", "Histories", "Preview")
-
-	// Content filters
-	if query.Title != "" {
-		baseQuery = baseQuery.Where("partner_contents.title ILIKE ?", "%"+query.Title+"%")
-	}
-	if query.UrlAlias != "" {
-		baseQuery = baseQuery.Where("partner_contents.url_alias ILIKE ?", "%"+query.UrlAlias+"%")
-	}
-	if query.URL != "" {
-		baseQuery = baseQuery.Where("partner_contents.url ILIKE ?", "%"+query.URL+"%")
-	}
-	if query.Status != "" {
-		baseQuery = baseQuery.Where("partner_contents.workflow_status = ?", query.Status)
-	}
-	if language != "" {
-		baseQuery = baseQuery.Where("partner_contents.language = ?", language)
-	}
-
-	// Category filters
-	categoryFilters := map[string]string{
-		"partner":            query.CategoryPartner,
-		"category-keywords":  query.CategoryKeywords,
-		"category-scale":     query.CategoryScale,
-		"category-industry":  query.CategoryIndustry,
-		"category-goal":      query.CategoryGoal,
-		"category-functions": query.CategoryFunctions,
-	}
-
-	for typeCode, filterValue := range categoryFilters {
-		if filterValue != "" {
-			subQuery := r.db.Table("partner_content_categories").
-				Select("partner_content_categories.partner_content_id").
-				Joins("JOIN categories ON partner_content_categories.category_id = categories.id").
-				Joins("JOIN category_types ON category_types.id = categories.category_type_id").
-				Where("category_types.type_code = ? AND categories.name ILIKE ?", typeCode, "%"+filterValue+"%")
-			baseQuery = baseQuery.Where("partner_contents.id IN (?)", subQuery)
-		}
-	}
+	baseQuery = r.applyPartnerContentFilters(baseQuery, query, language)
 
 	// Clone query for counting to avoid modification
 	countQuery := baseQuery.Session(&gorm.Session{})
@@ -181,6 +153,7 @@ func (r *CMSPartnerPageRepository) FindAllPartnerPage(query dto.PartnerPageQuery
 	offset := (page - 1) * limit
 	err := finalQuery.
 		Order(sortColumn).
+		Order("partner_pages.id").
 		Offset(offset).
 		Limit(limit).
 		Preload("Contents", func(db *gorm.DB) *gorm.DB {
@@ -201,6 +174,109 @@ func (r *CMSPartnerPageRepository) FindAllPartnerPage(query dto.PartnerPageQuery
 
 	return partnerPages, totalCount, nil
 }
+
+// applyPartnerContentFilters narrows a query over partner_contents to the contents matching the list filters
+func (r *CMSPartnerPageRepository) applyPartnerContentFilters(db *gorm.DB, query dto.PartnerPageQuery, language string) *gorm.DB {
+	// Content filters
+	if query.Title != "" {
+		db = db.Where("partner_contents.title ILIKE ?", "%"+query.Title+"%")
+	}
+	if query.UrlAlias != "" {
+		db = db.Where("partner_contents.url_alias ILIKE ?", "%"+query.UrlAlias+"%")
+	}
+	if query.URL != "" {
+		db = db.Where("partner_contents.url ILIKE ?", "%"+query.URL+"%")
+	}
+	if query.Status != "" {
+		db = db.Where("partner_contents.workflow_status = ?", query.Status)
+	}
+	if language != "" {
+		db = db.Where("partner_contents.language = ?", language)
+	}
+	db = partnerContentTables.applyPageGrants(db, query.Access)
+
+	// Category filters
+	categoryFilters := map[string]string{
+		"partner":            query.CategoryPartner,
+		"category-keywords":  query.CategoryKeywords,
+		"category-scale":     query.CategoryScale,
+		"category-industry":  query.CategoryIndustry,
+		"category-goal":      query.CategoryGoal,
+		"category-functions": query.CategoryFunctions,
+	}
+
+	for typeCode, filterValue := range categoryFilters {
+		if filterValue != "" {
+			subQuery := r.db.Table("partner_content_categories").
+				Select("partner_content_categories.partner_content_id").
+				Joins("JOIN categories ON partner_content_categories.category_id = categories.id").
+				Joins("JOIN category_types ON category_types.id = categories.category_type_id").
+				Where("category_types.type_code = ? AND categories.name ILIKE ?", typeCode, "%"+filterValue+"%")
+			db = db.Where("partner_contents.id IN (?)", subQuery)
+		}
+	}
+
+	return db
+}
+
+// FindPartnerPagesByCursor is the keyset paginated counterpart of FindAllPartnerPage. Pages are matched with EXISTS over their contents
+// instead of a join, so no DISTINCT or GROUP BY is needed, and they are only counted when asked to.
+func (r *CMSPartnerPageRepository) FindPartnerPagesByCursor(query dto.PartnerPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.PartnerPage, *dto.CursorPage, error) {
+	keyset, err := timestampKeysetSort("partner_pages", sort)
+	if err != nil {
+		return nil, nil, err
+	}
+	cursor, limit, err := decodeCursorQuery(cursorQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contents := r.applyPartnerContentFilters(
+		r.db.Table("partner_contents").
+			Select("1").
+			Where("partner_contents.page_id = partner_pages.id").
+			Where("partner_contents.mode != ? AND partner_contents.mode != ?", "Histories", "Preview"),
+		query, language)
+	baseQuery := r.db.Model(&models.PartnerPage{}).Where("EXISTS (?)", contents)
+
+	var totalCount *int64
+	if cursorQuery.WithTotal {
+		var count int64
+		if err := baseQuery.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return nil, nil, err
+		}
+		totalCount = &count
+	}
+
+	pageQuery, err := keyset.apply(baseQuery, cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var partnerPages []models.PartnerPage
+	if err := pageQuery.
+		Preload("Contents", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Where("partner_contents.mode != ? AND partner_contents.mode != ?", "Histories", "Preview").
+				Order("partner_contents.created_at DESC")
+		}).
+		Preload("Contents.Revision").
+		Preload("Contents.Categories").
+		Preload("Contents.Categories.CategoryType").
+		Preload("Contents.Components").
+		Preload("Contents.MetaTag").
+		Find(&partnerPages).Error; err != nil {
+		return nil, nil, err
+	}
+
+	partnerPages, page := keysetPage(partnerPages, keyset, cursor, limit, func(p models.PartnerPage) ([]interface{}, uuid.UUID) {
+		return []interface{}{keysetValue(keyset, p.CreatedAt, p.UpdatedAt)}, p.ID
+	})
+	page.TotalCount = totalCount
+
+	return partnerPages, &page, nil
+}
+
 func (r *CMSPartnerPageRepository) FindPartnerPageById(id uuid.UUID) (*models.PartnerPage, error) {
 	var PartnerPage models.PartnerPage
 
@@ -277,11 +353,15 @@ func (r *CMSPartnerPageRepository) UpdatePartnerContent(updatePartnerContent *mo
 			return fmt.Errorf("failed to create new content version: %w", err)
 		}
 
+		if err := copyContentRelations(tx, prevContentId, updatePartnerContent.ID, updatePartnerContent.PageID, updatePartnerContent.Language); err != nil {
+			return err
+		}
+
 		if err := tx.Model(&models.PartnerPage{}).Where("id = ?", updatePartnerContent.PageID).Update("updated_at", time.Now()).Error; err != nil {
 			return fmt.Errorf("failed to update page timestamp: %w", err)
 		}
 
-		return nil
+		return recordContentSaved(tx, partnerContentEventData(updatePartnerContent))
 	})
 
 	if err != nil {
@@ -301,7 +381,7 @@ func (r *CMSPartnerPageRepository) UpdatePartnerContent(updatePartnerContent *mo
 
 	return updatePartnerContent, nil
 }
-func (r *CMSPartnerPageRepository) DeletePartnerPage(id uuid.UUID) error {
+func (r *CMSPartnerPageRepository) DeletePartnerPage(id uuid.UUID, force bool) error {
 	err := r.db.Transaction(func(tx *gorm.DB) error {
 
 		var contents []models.PartnerContent
@@ -315,6 +395,10 @@ func (r *CMSPartnerPageRepository) DeletePartnerPage(id uuid.UUID) error {
 			return err
 		}
 
+		if err := ensureUnused(tx, enums.UsageItemPartnerPage, id, force); err != nil {
+			return err
+		}
+
 		// Step 1: Get all PartnerContent entries for this page
 		if err := tx.Where("page_id = ?", id).Find(&contents).Error; err != nil {
 			return err
@@ -353,7 +437,7 @@ func (r *CMSPartnerPageRepository) DeletePartnerPage(id uuid.UUID) error {
 			return err
 		}
 
-		return nil
+		return recordContentDeleted(tx, dto.ContentEventData{PageType: enums.PageTypePartner, PageID: id})
 	})
 
 	if err != nil {
@@ -395,7 +479,14 @@ func (r *CMSPartnerPageRepository) FindLatestContentByPageId(pageId uuid.UUID, l
 
 // Might be deprecate
 func (r *CMSPartnerPageRepository) CreateContentForPartnerPage(PartnerContent *models.PartnerContent, lang string, mode string) (*models.PartnerContent, error) {
-	if err := r.db.Create(PartnerContent).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(PartnerContent).Error; err != nil {
+			return err
+		}
+
+		return recordContentSaved(tx, partnerContentEventData(PartnerContent))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -431,7 +522,7 @@ func (r *CMSPartnerPageRepository) DeletePartnerContent(pageId uuid.UUID, lang,
 			return err
 		}
 
-		return nil
+		return recordContentDeleted(tx, partnerContentEventData(&PartnerContent))
 	})
 
 	if err != nil {
@@ -535,7 +626,20 @@ func (r *CMSPartnerPageRepository) DuplicatePartnerPage(pageId uuid.UUID) (*mode
 	copyPartnerPage.Contents = newContents
 
 	// Create the new partnerPage
-	if err := r.db.Create(&copyPartnerPage).Error; err != nil {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&copyPartnerPage).Error; err != nil {
+			return err
+		}
+
+		// The copies keep the relations of the contents they were made from
+		for i, content := range newContents {
+			if err := copyContentRelations(tx, partnerContents[i].ID, content.ID, copyPartnerPage.ID, content.Language); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -591,7 +695,17 @@ func (r *CMSPartnerPageRepository) DuplicatePartnerContentToAnotherLanguage(cont
 		}
 	}
 
-	if err := r.db.Create(&PartnerContent).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&PartnerContent).Error; err != nil {
+			return err
+		}
+		if err := copyContentRelations(tx, contentId, PartnerContent.ID, PartnerContent.PageID, PartnerContent.Language); err != nil {
+			return err
+		}
+
+		return recordContentSaved(tx, partnerContentEventData(&PartnerContent))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -622,6 +736,7 @@ func (r *CMSPartnerPageRepository) RevertPartnerContent(revisionId uuid.UUID, ne
 		return nil, err
 	}
 
+	revertedContentId := PartnerContent.ID
 	err = r.db.Transaction(func(tx *gorm.DB) error {
 		// Update the old content
 
@@ -665,8 +780,11 @@ func (r *CMSPartnerPageRepository) RevertPartnerContent(revisionId uuid.UUID, ne
 		if err := tx.Create(PartnerContent).Error; err != nil {
 			return err
 		}
+		if err := copyContentRelations(tx, revertedContentId, PartnerContent.ID, PartnerContent.PageID, PartnerContent.Language); err != nil {
+			return err
+		}
 
-		return nil
+		return recordContentSaved(tx, partnerContentEventData(PartnerContent))
 	})
 
 	if err != nil {
@@ -803,7 +921,14 @@ func (r *CMSPartnerPageRepository) GetPageIdByContentId(contentId uuid.UUID) (uu
 func (r *CMSPartnerPageRepository) CreatePartnerContentPreview(partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error) {
 	partnerContentPreview.Revision = nil
 	partnerContentPreview.Categories = nil
-	if err := r.db.Create(partnerContentPreview).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(partnerContentPreview).Error; err != nil {
+			return err
+		}
+
+		return copyLatestContentRelations(tx, enums.PageTypePartner, partnerContentPreview.ID, partnerContentPreview.PageID, partnerContentPreview.Language)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -825,7 +950,7 @@ func (r *CMSPartnerPageRepository) UpdatePartnerContentPreview(partnerContentPre
 			return err
 		}
 
-		return nil
+		return copyLatestContentRelations(tx, enums.PageTypePartner, partnerContentPreview.ID, partnerContentPreview.PageID, partnerContentPreview.Language)
 	})
 
 	if err != nil {
@@ -847,3 +972,26 @@ func (r *CMSPartnerPageRepository) FindPartnerContentPreviewById(pageId uuid.UUI
 
 	return &partnerContentPreview, nil
 }
+
+// GetContentRefByRevisionId returns the page and the content the revision belongs to
+func (r *CMSPartnerPageRepository) GetContentRefByRevisionId(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	return partnerContentTables.getContentRefByRevisionId(r.db, revisionId)
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the page
+func (r *CMSPartnerPageRepository) FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return partnerContentTables.findContentScopes(r.db, pageId)
+}
+
+// FindContentById returns a content with what the publish audit looks at
+func (r *CMSPartnerPageRepository) FindContentById(contentId uuid.UUID) (*models.PartnerContent, error) {
+	var partnerContent models.PartnerContent
+	if err := r.db.
+		Preload("MetaTag").
+		Preload("Components").
+		First(&partnerContent, "id = ?", contentId).Error; err != nil {
+		return nil, err
+	}
+
+	return &partnerContent, nil
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSPreviewLinkRepositoryInterface interface {
+	CreatePreviewLink(previewLink *models.PreviewLink) (*models.PreviewLink, error)
+	FindPreviewLinkById(id uuid.UUID) (*models.PreviewLink, error)
+	FindPreviewLinks(query dto.PreviewLinkQuery, page, limit int) ([]models.PreviewLink, int64, error)
+	RevokePreviewLink(id uuid.UUID, revokedAt time.Time) error
+	FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+type CMSPreviewLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSPreviewLinkRepository(db *gorm.DB) *CMSPreviewLinkRepository {
+	return &CMSPreviewLinkRepository{db: db}
+}
+
+func (r *CMSPreviewLinkRepository) CreatePreviewLink(previewLink *models.PreviewLink) (*models.PreviewLink, error) {
+	if err := r.db.Create(previewLink).Error; err != nil {
+		return nil, err
+	}
+
+	return previewLink, nil
+}
+
+func (r *CMSPreviewLinkRepository) FindPreviewLinkById(id uuid.UUID) (*models.PreviewLink, error) {
+	var previewLink models.PreviewLink
+	if err := r.db.Where("id = ?", id).First(&previewLink).Error; err != nil {
+		return nil, err
+	}
+
+	return &previewLink, nil
+}
+
+func (r *CMSPreviewLinkRepository) FindPreviewLinks(query dto.PreviewLinkQuery, page, limit int) ([]models.PreviewLink, int64, error) {
+	var previewLinks []models.PreviewLink
+	var totalCount int64
+
+	baseQuery := r.db.Model(&models.PreviewLink{})
+	if query.PageID != "" {
+		baseQuery = baseQuery.Where("page_id = ?", query.PageID)
+	}
+	if query.PageType != "" {
+		baseQuery = baseQuery.Where("page_type = ?", query.PageType)
+	}
+	if !query.IncludeInvalid {
+		baseQuery = baseQuery.Where("revoked_at IS NULL AND expires_at > ?", time.Now())
+	}
+	baseQuery = applyContentGrants(baseQuery, query.Access, "preview_links.page_type", "preview_links.content_id")
+
+	if err := baseQuery.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := baseQuery.
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&previewLinks).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return previewLinks, totalCount, nil
+}
+
+// RevokePreviewLink marks the link as revoked, revoking twice keeps the first revocation time
+func (r *CMSPreviewLinkRepository) RevokePreviewLink(id uuid.UUID, revokedAt time.Time) error {
+	result := r.db.Model(&models.PreviewLink{}).
+		Where("id = ?", id).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", revokedAt))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the page
+func (r *CMSPreviewLinkRepository) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return findPageContentScopes(r.db, pageType, pageId)
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"github.com/MadManJJ/cms-api/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSRoleRepositoryInterface interface {
+	FindRoles() ([]models.Role, error)
+	FindRoleByName(name string) (*models.Role, error)
+	UpdateUserRole(userId uuid.UUID, roleId *uuid.UUID) error
+	FindPageGrantsByUserId(userId uuid.UUID) ([]models.PageGrant, error)
+	CreatePageGrant(grant *models.PageGrant) (*models.PageGrant, error)
+	DeletePageGrant(userId, grantId uuid.UUID) error
+}
+
+type CMSRoleRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSRoleRepository(db *gorm.DB) *CMSRoleRepository {
+	return &CMSRoleRepository{db: db}
+}
+
+// FindRoles returns every role with its permissions
+func (r *CMSRoleRepository) FindRoles() ([]models.Role, error) {
+	var roles []models.Role
+	if err := r.db.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *CMSRoleRepository) FindRoleByName(name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// UpdateUserRole gives the user the role, or takes their role away when roleId is nil
+func (r *CMSRoleRepository) UpdateUserRole(userId uuid.UUID, roleId *uuid.UUID) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userId).Update("role_id", roleId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *CMSRoleRepository) FindPageGrantsByUserId(userId uuid.UUID) ([]models.PageGrant, error) {
+	var grants []models.PageGrant
+	if err := r.db.Where("user_id = ?", userId).Order("created_at").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+func (r *CMSRoleRepository) CreatePageGrant(grant *models.PageGrant) (*models.PageGrant, error) {
+	if err := r.db.Create(grant).Error; err != nil {
+		return nil, err
+	}
+
+	return grant, nil
+}
+
+// DeletePageGrant deletes the grant of the user, gorm.ErrRecordNotFound when the user has no such grant
+func (r *CMSRoleRepository) DeletePageGrant(userId, grantId uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", grantId, userId).Delete(&models.PageGrant{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSUsageRepositoryInterface interface {
+	FindCurrentLandingContents(scope *dto.UsagePageScope) ([]models.LandingContent, error)
+	FindCurrentPartnerContents(scope *dto.UsagePageScope) ([]models.PartnerContent, error)
+	FindCurrentFaqContents(scope *dto.UsagePageScope) ([]models.FaqContent, error)
+	FindCurrentPagePaths() ([]dto.UsagePagePath, error)
+	FindContentRelations(scope *dto.UsagePageScope) ([]models.ContentRelation, error)
+	FindCategories() ([]models.Category, error)
+	FindEmailContents() ([]models.EmailContent, error)
+	FindForms() ([]models.Form, error)
+	FindMediaFiles() ([]models.MediaFile, error)
+	ReplaceUsageIndex(references []models.UsageReference) error
+	ReplacePageUsages(scope dto.UsagePageScope, references []models.UsageReference) error
+	DeleteItemUsages(itemType enums.UsageItemType, itemId uuid.UUID) error
+	MoveItemUsages(itemType enums.UsageItemType, fromId, toId uuid.UUID) error
+	FindUsages(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error)
+	FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+type CMSUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSUsageRepository(db *gorm.DB) *CMSUsageRepository {
+	return &CMSUsageRepository{db: db}
+}
+
+// currentContents selects the drafts and published contents of every page, or of the scoped one,
+// histories and previews are never shown again
+func currentContents(db *gorm.DB, scope *dto.UsagePageScope) *gorm.DB {
+	db = db.Where("mode NOT IN ?", []enums.PageMode{enums.PageModeHistories, enums.PageModePreview})
+	if scope == nil {
+		return db
+	}
+
+	db = db.Where("page_id = ?", scope.PageID)
+	if scope.Language != "" {
+		db = db.Where("language = ?", scope.Language)
+	}
+	return db
+}
+
+func (r *CMSUsageRepository) FindCurrentLandingContents(scope *dto.UsagePageScope) ([]models.LandingContent, error) {
+	var landingContents []models.LandingContent
+	if err := currentContents(r.db, scope).
+		Preload("Components").
+		Preload("Categories").
+		Preload("MetaTag").
+		Preload("Files").
+		Find(&landingContents).Error; err != nil {
+		return nil, err
+	}
+
+	return landingContents, nil
+}
+
+func (r *CMSUsageRepository) FindCurrentPartnerContents(scope *dto.UsagePageScope) ([]models.PartnerContent, error) {
+	var partnerContents []models.PartnerContent
+	if err := currentContents(r.db, scope).
+		Preload("Components").
+		Preload("Categories").
+		Preload("MetaTag").
+		Find(&partnerContents).Error; err != nil {
+		return nil, err
+	}
+
+	return partnerContents, nil
+}
+
+func (r *CMSUsageRepository) FindCurrentFaqContents(scope *dto.UsagePageScope) ([]models.FaqContent, error) {
+	var faqContents []models.FaqContent
+	if err := currentContents(r.db, scope).
+		Preload("Components").
+		Preload("Categories").
+		Preload("MetaTag").
+		Find(&faqContents).Error; err != nil {
+		return nil, err
+	}
+
+	return faqContents, nil
+}
+
+// FindCurrentPagePaths returns the url aliases and urls of the current contents without loading the contents
+func (r *CMSUsageRepository) FindCurrentPagePaths() ([]dto.UsagePagePath, error) {
+	notCurrent := []enums.PageMode{enums.PageModeHistories, enums.PageModePreview}
+
+	var paths []dto.UsagePagePath
+	if err := r.db.Raw(`SELECT ? AS page_type, page_id, url_alias AS path FROM landing_contents WHERE mode NOT IN ?
+		UNION SELECT ?, page_id, url FROM partner_contents WHERE mode NOT IN ?
+		UNION SELECT ?, page_id, url_alias FROM partner_contents WHERE mode NOT IN ?
+		UNION SELECT ?, page_id, url FROM faq_contents WHERE mode NOT IN ?
+		UNION SELECT ?, page_id, url_alias FROM faq_contents WHERE mode NOT IN ?`,
+		enums.PageTypeLanding, notCurrent,
+		enums.PageTypePartner, notCurrent,
+		enums.PageTypePartner, notCurrent,
+		enums.PageTypeFaq, notCurrent,
+		enums.PageTypeFaq, notCurrent).
+		Scan(&paths).Error; err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// FindContentRelations returns the relations of the contents that are not history versions or preview copies
+func (r *CMSUsageRepository) FindContentRelations(scope *dto.UsagePageScope) ([]models.ContentRelation, error) {
+	currentSource, currentArgs := currentRelationSource()
+
+	db := r.db.Where(currentSource, currentArgs...)
+	if scope != nil {
+		db = db.Where("source_page_type = ? AND source_page_id = ?", scope.PageType, scope.PageID)
+		if scope.Language != "" {
+			db = db.Where("language = ?", scope.Language)
+		}
+	}
+
+	var relations []models.ContentRelation
+	if err := db.Find(&relations).Error; err != nil {
+		return nil, err
+	}
+
+	return relations, nil
+}
+
+func (r *CMSUsageRepository) FindCategories() ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *CMSUsageRepository) FindEmailContents() ([]models.EmailContent, error) {
+	var emailContents []models.EmailContent
+	if err := r.db.Find(&emailContents).Error; err != nil {
+		return nil, err
+	}
+
+	return emailContents, nil
+}
+
+func (r *CMSUsageRepository) FindForms() ([]models.Form, error) {
+	var forms []models.Form
+	if err := r.db.
+		Preload("Sections.Fields").
+		Find(&forms).Error; err != nil {
+		return nil, err
+	}
+
+	return forms, nil
+}
+
+func (r *CMSUsageRepository) FindMediaFiles() ([]models.MediaFile, error) {
+	var mediaFiles []models.MediaFile
+	if err := r.db.Find(&mediaFiles).Error; err != nil {
+		return nil, err
+	}
+
+	return mediaFiles, nil
+}
+
+// ReplaceUsageIndex swaps the whole index at once, readers never see a half built one
+func (r *CMSUsageRepository) ReplaceUsageIndex(references []models.UsageReference) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM usage_references").Error; err != nil {
+			return err
+		}
+		if len(references) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(references, 100).Error
+	})
+}
+
+// ReplacePageUsages swaps the references of the contents and relations of the scoped page, the rest of the index stays
+func (r *CMSUsageRepository) ReplacePageUsages(scope dto.UsagePageScope, references []models.UsageReference) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		db := tx.Where("referrer_type IN ? AND page_type = ? AND page_id = ?",
+			[]enums.UsageReferrerType{enums.UsageReferrerContent, enums.UsageReferrerRelation}, scope.PageType, scope.PageID)
+		if scope.Language != "" {
+			db = db.Where("language = ?", scope.Language)
+		}
+		if err := db.Delete(&models.UsageReference{}).Error; err != nil {
+			return err
+		}
+		if len(references) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(references, 100).Error
+	})
+}
+
+// DeleteItemUsages drops the references to an item that is gone, links to it point to nothing now
+func (r *CMSUsageRepository) DeleteItemUsages(itemType enums.UsageItemType, itemId uuid.UUID) error {
+	return r.db.Where("item_type = ? AND item_id = ?", itemType, itemId).Delete(&models.UsageReference{}).Error
+}
+
+// MoveItemUsages points the references of an item to the one that took its place
+func (r *CMSUsageRepository) MoveItemUsages(itemType enums.UsageItemType, fromId, toId uuid.UUID) error {
+	return r.db.Model(&models.UsageReference{}).
+		Where("item_type = ? AND item_id = ?", itemType, fromId).
+		Update("item_id", toId).Error
+}
+
+func (r *CMSUsageRepository) FindUsages(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error) {
+	var references []models.UsageReference
+	if err := r.db.
+		Where("item_type = ? AND item_id = ?", itemType, itemId).
+		Order("published DESC, referrer_type, label, field").
+		Find(&references).Error; err != nil {
+		return nil, err
+	}
+
+	return references, nil
+}
+
+// ensureUnused runs in the delete transaction of an item, so the check and the delete read the same index.
+// It fails with ErrItemInUse while published referrers of the item are indexed, unless forced.
+func ensureUnused(tx *gorm.DB, itemType enums.UsageItemType, itemId uuid.UUID, force bool) error {
+	if force {
+		return nil
+	}
+
+	var published int64
+	if err := tx.Model(&models.UsageReference{}).
+		Where("item_type = ? AND item_id = ? AND published = ?", itemType, itemId, true).
+		Count(&published).Error; err != nil {
+		return err
+	}
+	if published > 0 {
+		return errs.ErrItemInUse
+	}
+
+	return nil
+}
+
+// FindContentScopes returns what page grants are matched on for every content of the page
+func (r *CMSUsageRepository) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return findPageContentScopes(r.db, pageType, pageId)
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CMSWebhookRepositoryInterface interface {
+	CreateEndpoint(endpoint *models.WebhookEndpoint) error
+	FindEndpoints() ([]models.WebhookEndpoint, error)
+	FindEndpointByID(id uuid.UUID) (*models.WebhookEndpoint, error)
+	UpdateEndpoint(endpoint *models.WebhookEndpoint) error
+	DeleteEndpoint(id uuid.UUID) error
+	FindSubscribedEndpoints(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error)
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	ClaimDueDeliveries(now time.Time, limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error)
+	RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookDeliveryAttempt) error
+	FindDeliveries(query dto.WebhookDeliveryQuery, page, limit int) ([]models.WebhookDelivery, int64, error)
+	FindDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error)
+}
+
+type CMSWebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewCMSWebhookRepository(db *gorm.DB) *CMSWebhookRepository {
+	return &CMSWebhookRepository{db: db}
+}
+
+func (r *CMSWebhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Create(endpoint).Error
+}
+
+func (r *CMSWebhookRepository) FindEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := r.db.Order("created_at ASC").Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *CMSWebhookRepository) FindEndpointByID(id uuid.UUID) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := r.db.First(&endpoint, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+func (r *CMSWebhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Save(endpoint).Error
+}
+
+// DeleteEndpoint removes the endpoint, its deliveries and their attempts go with it
+func (r *CMSWebhookRepository) DeleteEndpoint(id uuid.UUID) error {
+	result := r.db.Delete(&models.WebhookEndpoint{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *CMSWebhookRepository) FindSubscribedEndpoints(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := r.db.
+		Where("is_active = ? AND ? = ANY(event_types)", true, string(eventType)).
+		Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *CMSWebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	// A first delivery of an event already queued for the endpoint is dropped, replays are always added
+	return r.db.
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "endpoint_id"}, {Name: "event_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "replay_of_id IS NULL"}}},
+			DoNothing:   true,
+		}).
+		CreateInBatches(deliveries, 100).Error
+}
+
+// ClaimDueDeliveries locks the pending deliveries that are due and moves their next attempt to leaseUntil,
+// so other instances skip them while they are sent. A delivery whose sender died is picked up again after the lease.
+func (r *CMSWebhookRepository) ClaimDueDeliveries(now time.Time, limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", enums.WebhookDeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", leaseUntil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	endpointIds := make([]uuid.UUID, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		endpointIds = append(endpointIds, delivery.EndpointID)
+	}
+	var endpoints []models.WebhookEndpoint
+	if err := r.db.Where("id IN ?", endpointIds).Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	endpointsById := make(map[uuid.UUID]*models.WebhookEndpoint, len(endpoints))
+	for i := range endpoints {
+		endpointsById[endpoints[i].ID] = &endpoints[i]
+	}
+	for i := range deliveries {
+		deliveries[i].Endpoint = endpointsById[deliveries[i].EndpointID]
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt logs the attempt and saves the delivery status it led to
+func (r *CMSWebhookRepository) RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookDeliveryAttempt) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id = ?", delivery.ID).
+			Updates(map[string]interface{}{
+				"status":           delivery.Status,
+				"attempts":         delivery.Attempts,
+				"next_attempt_at":  delivery.NextAttemptAt,
+				"last_status_code": delivery.LastStatusCode,
+				"last_error":       delivery.LastError,
+				"delivered_at":     delivery.DeliveredAt,
+				"updated_at":       time.Now(),
+			}).Error
+	})
+}
+
+func (r *CMSWebhookRepository) FindDeliveries(query dto.WebhookDeliveryQuery, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var totalCount int64
+
+	baseQuery := r.db.Model(&models.WebhookDelivery{})
+	if query.EndpointID != "" {
+		baseQuery = baseQuery.Where("endpoint_id = ?", query.EndpointID)
+	}
+	if query.EventType != "" {
+		baseQuery = baseQuery.Where("event_type = ?", query.EventType)
+	}
+	if query.Status != "" {
+		baseQuery = baseQuery.Where("status = ?", query.Status)
+	}
+
+	if err := baseQuery.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := baseQuery.
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, totalCount, nil
+}
+
+func (r *CMSWebhookRepository) FindDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.
+		Preload("AttemptsLog", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attempted_at ASC")
+		}).
+		First(&delivery, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
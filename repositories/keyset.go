@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type keysetKind int
+
+const (
+	keysetTime keysetKind = iota
+	keysetString
+	keysetInt
+)
+
+type keysetColumn struct {
+	name string
+	kind keysetKind
+}
+
+// keysetSort orders a list by its columns and then by ID, all in one direction,
+// so a single row comparison finds where a page starts however many rows came before it
+type keysetSort struct {
+	key      string // Sort as asked for, cursors carry it
+	columns  []keysetColumn
+	idColumn string
+	desc     bool
+}
+
+// apply narrows the query to the rows after the cursor, or before it for a prev cursor, and asks for one row
+// more than the limit, that row only tells whether there is another page
+func (k keysetSort) apply(query *gorm.DB, cursor *helpers.Cursor, limit int) (*gorm.DB, error) {
+	desc := k.desc
+	if cursor != nil {
+		if cursor.Sort != k.key || len(cursor.Values) != len(k.columns) {
+			return nil, errs.ErrInvalidCursor
+		}
+
+		names := make([]string, 0, len(k.columns)+1)
+		values := make([]interface{}, 0, len(k.columns)+1)
+		for i, column := range k.columns {
+			value, err := column.parse(cursor.Values[i])
+			if err != nil {
+				return nil, errs.ErrInvalidCursor
+			}
+			names = append(names, column.name)
+			values = append(values, value)
+		}
+		names = append(names, k.idColumn)
+		values = append(values, cursor.ID)
+
+		if cursor.Backward {
+			desc = !desc
+		}
+		operator := ">"
+		if desc {
+			operator = "<"
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		query = query.Where(fmt.Sprintf("(%s) %s (%s)", strings.Join(names, ", "), operator, placeholders), values...)
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	for _, column := range k.columns {
+		query = query.Order(column.name + " " + direction)
+	}
+
+	return query.Order(k.idColumn + " " + direction).Limit(limit + 1), nil
+}
+
+func (c keysetColumn) parse(value string) (interface{}, error) {
+	switch c.kind {
+	case keysetTime:
+		return time.Parse(time.RFC3339Nano, value)
+	case keysetInt:
+		return strconv.Atoi(value)
+	default:
+		return value, nil
+	}
+}
+
+func (c keysetColumn) format(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (k keysetSort) cursorFor(values []interface{}, id uuid.UUID, backward bool) string {
+	cursor := helpers.Cursor{Sort: k.key, ID: id, Backward: backward}
+	for i, column := range k.columns {
+		cursor.Values = append(cursor.Values, column.format(values[i]))
+	}
+	return helpers.EncodeCursor(cursor)
+}
+
+// decodeCursorQuery returns the decoded cursor, nil for the first page, and the limit clamped to MaxLimit
+func decodeCursorQuery(cursorQuery dto.CursorQuery) (*helpers.Cursor, int, error) {
+	limit := cursorQuery.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	if cursorQuery.Cursor == "" {
+		return nil, limit, nil
+	}
+	cursor, err := helpers.DecodeCursor(cursorQuery.Cursor)
+	if err != nil {
+		return nil, 0, err
+	}
+	return cursor, limit, nil
+}
+
+// keysetPage trims the extra row fetched by apply, puts the rows of a prev page back in list order
+// and links the neighbouring pages
+func keysetPage[T any](rows []T, k keysetSort, cursor *helpers.Cursor, limit int, keyOf func(T) ([]interface{}, uuid.UUID)) ([]T, dto.CursorPage) {
+	page := dto.CursorPage{Limit: limit}
+
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, page
+	}
+
+	// The boundary row of the cursor is on the other side, so paging back always has somewhere to go
+	if hasMore || backward {
+		values, id := keyOf(rows[len(rows)-1])
+		page.Next = k.cursorFor(values, id, false)
+	}
+	if (cursor != nil && !backward) || (backward && hasMore) {
+		values, id := keyOf(rows[0])
+		page.Prev = k.cursorFor(values, id, true)
+	}
+
+	return rows, page
+}
+
+// timestampKeysetSort resolves a "column:direction" sort of a list for cursor pagination.
+// Only the created_at and updated_at of the listed table itself can back a cursor, sorts on joined columns cannot.
+func timestampKeysetSort(table, sort string) (keysetSort, error) {
+	column, direction := "created_at", "desc"
+	if sort != "" {
+		sortParts := strings.Split(sort, ":")
+		column = sortParts[0]
+		if len(sortParts) == 2 && strings.EqualFold(sortParts[1], "asc") {
+			direction = "asc"
+		}
+	}
+	if column != "created_at" && column != "updated_at" {
+		return keysetSort{}, errs.ErrUnsupportedCursorSort
+	}
+
+	return keysetSort{
+		key:      column + ":" + direction,
+		columns:  []keysetColumn{{table + "." + column, keysetTime}},
+		idColumn: table + ".id",
+		desc:     direction == "desc",
+	}, nil
+}
+
+// keysetValue picks the timestamp a timestampKeysetSort orders by
+func keysetValue(keyset keysetSort, createdAt, updatedAt time.Time) time.Time {
+	if strings.HasSuffix(keyset.columns[0].name, ".updated_at") {
+		return updatedAt
+	}
+	return createdAt
+}
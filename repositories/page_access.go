@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// pageContentTables names the tables of one page type the page grants are matched on
+type pageContentTables struct {
+	pageType      enums.PageType
+	contents      string // e.g. landing_contents
+	categories    string // e.g. landing_content_categories
+	contentColumn string // Column of the categories and the revisions referencing a content, e.g. landing_content_id
+}
+
+var (
+	landingContentTables = pageContentTables{enums.PageTypeLanding, "landing_contents", "landing_content_categories", "landing_content_id"}
+	partnerContentTables = pageContentTables{enums.PageTypePartner, "partner_contents", "partner_content_categories", "partner_content_id"}
+	faqContentTables     = pageContentTables{enums.PageTypeFaq, "faq_contents", "faq_content_categories", "faq_content_id"}
+
+	pageContentTablesByPageType = map[enums.PageType]pageContentTables{
+		enums.PageTypeLanding: landingContentTables,
+		enums.PageTypePartner: partnerContentTables,
+		enums.PageTypeFaq:     faqContentTables,
+	}
+)
+
+// applyPageGrants narrows a query over the contents to those the access may read, one of its grants has to match each of them
+func (t pageContentTables) applyPageGrants(db *gorm.DB, access *dto.PageAccess) *gorm.DB {
+	grants, scoped := access.ReadGrants(t.pageType)
+	if !scoped {
+		return db
+	}
+	if len(grants) == 0 {
+		return db.Where("FALSE")
+	}
+
+	conditions := make([]string, 0, len(grants))
+	var args []interface{}
+	for _, grant := range grants {
+		var parts []string
+		if grant.Language != nil {
+			parts = append(parts, t.contents+".language = ?")
+			args = append(args, *grant.Language)
+		}
+		if grant.CategoryID != nil {
+			parts = append(parts, fmt.Sprintf("%s.id IN (SELECT %s FROM %s WHERE category_id = ?)", t.contents, t.contentColumn, t.categories))
+			args = append(args, *grant.CategoryID)
+		}
+		if len(parts) == 0 {
+			// The grant reads every page of the type
+			return db
+		}
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// applyContentGrants narrows a query over rows pointing at contents of any page type to those whose content the access may read
+func applyContentGrants(db *gorm.DB, access *dto.PageAccess, pageTypeColumn, contentIdColumn string) *gorm.DB {
+	conditions := make([]string, 0, len(pageContentTablesByPageType))
+	var args []interface{}
+	scoped := false
+	for _, t := range []pageContentTables{landingContentTables, partnerContentTables, faqContentTables} {
+		if _, ok := access.ReadGrants(t.pageType); !ok {
+			conditions = append(conditions, pageTypeColumn+" = ?")
+			args = append(args, t.pageType)
+			continue
+		}
+		scoped = true
+		readable := t.applyPageGrants(db.Session(&gorm.Session{NewDB: true}).Table(t.contents).Select(t.contents+".id"), access)
+		conditions = append(conditions, fmt.Sprintf("(%s = ? AND %s IN (?))", pageTypeColumn, contentIdColumn))
+		args = append(args, t.pageType, readable)
+	}
+	if !scoped {
+		return db
+	}
+
+	return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// findPageContentScopes is findContentScopes for the repositories that are not tied to one page type
+func findPageContentScopes(db *gorm.DB, pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	tables, ok := pageContentTablesByPageType[pageType]
+	if !ok {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	return tables.findContentScopes(db, pageId)
+}
+
+// findContentScopes returns the language, mode and categories of every content of the page
+func (t pageContentTables) findContentScopes(db *gorm.DB, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	var rows []struct {
+		ID         uuid.UUID
+		Language   enums.PageLanguage
+		Mode       enums.PageMode
+		CategoryID *uuid.UUID
+	}
+	if err := db.Table(t.contents).
+		Select(fmt.Sprintf("%[1]s.id, %[1]s.language, %[1]s.mode, %[2]s.category_id", t.contents, t.categories)).
+		Joins(fmt.Sprintf("LEFT JOIN %[2]s ON %[2]s.%[3]s = %[1]s.id", t.contents, t.categories, t.contentColumn)).
+		Where(t.contents+".page_id = ?", pageId).
+		Order(t.contents + ".id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var scopes []dto.PageContentScope
+	for _, row := range rows {
+		if len(scopes) == 0 || scopes[len(scopes)-1].ContentID != row.ID {
+			scopes = append(scopes, dto.PageContentScope{ContentID: row.ID, Language: row.Language, Mode: row.Mode})
+		}
+		if row.CategoryID != nil {
+			scope := &scopes[len(scopes)-1]
+			scope.CategoryIDs = append(scope.CategoryIDs, *row.CategoryID)
+		}
+	}
+
+	return scopes, nil
+}
+
+// getContentRefByRevisionId returns the page and the content a revision belongs to
+func (t pageContentTables) getContentRefByRevisionId(db *gorm.DB, revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	var ref struct {
+		PageID    uuid.UUID
+		ContentID uuid.UUID
+	}
+	result := db.Table("revisions").
+		Select(fmt.Sprintf("%[1]s.page_id, %[1]s.id AS content_id", t.contents)).
+		Joins(fmt.Sprintf("JOIN %[1]s ON %[1]s.id = revisions.%[2]s", t.contents, t.contentColumn)).
+		Where("revisions.id = ?", revisionId).
+		Limit(1).
+		Scan(&ref)
+	if result.Error != nil {
+		return uuid.Nil, uuid.Nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return uuid.Nil, uuid.Nil, gorm.ErrRecordNotFound
+	}
+
+	return ref.PageID, ref.ContentID, nil
+}
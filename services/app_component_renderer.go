@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/models"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+//go:embed templates/components/*.tmpl
+var componentTemplatesFS embed.FS
+
+// Nested components deeper than this are dropped instead of rendered
+const maxComponentDepth = 8
+
+// componentTemplateGroups maps component types without a template of their own to a shared one
+var componentTemplateGroups = map[string]string{
+	"H21":                                "heading2",
+	"H22":                                "heading2",
+	"H23":                                "heading2",
+	"H24":                                "heading2",
+	"H31":                                "heading3",
+	"H32":                                "heading3",
+	"LargeGreenLinkButton":               "linkButton",
+	"LargeWhiteLinkButton":               "linkButton",
+	"MidsizeWhiteLinkButtonLeftAligned":  "linkButton",
+	"MidsizeWhiteLinkButtonCentered":     "linkButton",
+	"MidsizeWhiteLinkButtonRightAligned": "linkButton",
+	"ThreeLargeGreenLinkButton":          "linkButtons",
+	"NormalText":                         "richText",
+	"NormalTextRed":                      "richText",
+	"Bold":                               "richText",
+	"TextCentered":                       "richText",
+	"Chatter":                            "richText",
+	"Box":                                "richText",
+	"SectionContent":                     "richText",
+	"LeadComponent":                      "richText",
+	"DynamicClassTextSection":            "richText",
+	"Quotation":                          "quotation",
+	"Notes":                              "notes",
+	"List":                               "list",
+	"TextList":                           "list",
+	"TextListNumber":                     "orderedList",
+	"Links":                              "links",
+	"LinksSeparateWindow":                "links",
+	"RelatedLinks":                       "links",
+	"RelatedArticles":                    "links",
+	"AnchorLink":                         "anchor",
+	"Pdf":                                "file",
+	"OneColumnImage":                     "images",
+	"TwoColumnImage":                     "images",
+	"ImageOneColText":                    "images",
+	"ImageTwoColText":                    "images",
+	"ImageThreeColText":                  "images",
+	"ImageFourColText":                   "images",
+	"LeftImageRightTextWrapped":          "images",
+	"Video":                              "video",
+	"VideoExternalLink":                  "video",
+	"VideoWithEditor":                    "video",
+	"QrCode":                             "qrCode",
+	"Margin":                             "margin",
+	"Divider":                            "divider",
+	"TabContent":                         "tabs",
+	"StickyHeader":                       "stickyHeader",
+}
+
+type ComponentRendererInterface interface {
+	RenderComponents(cacheKey string, components []*models.Component) (string, error)
+}
+
+type ComponentRenderer struct {
+	templates  *template.Template
+	htmlPolicy *bluemonday.Policy
+	cacheSize  int
+
+	mu         sync.RWMutex
+	cache      map[string]string
+	cacheOrder []string
+}
+
+// componentView is the data handed to a component template
+type componentView struct {
+	Type  string
+	ID    string
+	Props map[string]interface{}
+	Depth int
+}
+
+// NewComponentRenderer parses the built-in component templates, then any *.tmpl in the configured
+// templates directory so a {{define}} there replaces the built-in template of the same name
+func NewComponentRenderer(cfg *config.Config) (*ComponentRenderer, error) {
+	htmlPolicy := bluemonday.UGCPolicy()
+	htmlPolicy.AllowAttrs("class").Globally()
+	htmlPolicy.AllowAttrs("target").Globally()
+
+	r := &ComponentRenderer{
+		htmlPolicy: htmlPolicy,
+		cacheSize:  cfg.Render.CacheSize,
+		cache:      make(map[string]string),
+	}
+
+	templates, err := template.New("components").Funcs(r.funcs()).ParseFS(componentTemplatesFS, "templates/components/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("parse component templates: %w", err)
+	}
+
+	if cfg.Render.TemplatesDir != "" {
+		overrides, err := filepath.Glob(filepath.Join(cfg.Render.TemplatesDir, "*.tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("list component template overrides: %w", err)
+		}
+		for _, path := range overrides {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read component template %s: %w", path, err)
+			}
+			if _, err := templates.New(filepath.Base(path)).Parse(string(source)); err != nil {
+				return nil, fmt.Errorf("parse component template %s: %w", path, err)
+			}
+		}
+	}
+
+	r.templates = templates
+	return r, nil
+}
+
+// RenderComponents renders the components in order, results are cached by cacheKey when it is not blank
+func (r *ComponentRenderer) RenderComponents(cacheKey string, components []*models.Component) (string, error) {
+	if cacheKey != "" {
+		if rendered, ok := r.cached(cacheKey); ok {
+			return rendered, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, component := range components {
+		if component == nil {
+			continue
+		}
+
+		props := map[string]interface{}{}
+		if len(component.Props) > 0 {
+			if err := json.Unmarshal(component.Props, &props); err != nil {
+				// Props that are not a JSON object render as an empty component
+				props = map[string]interface{}{}
+			}
+		}
+
+		view := componentView{Type: string(component.Type), ID: component.ID.String(), Props: props}
+		if err := r.renderComponent(&buf, view); err != nil {
+			return "", err
+		}
+	}
+
+	rendered := buf.String()
+	if cacheKey != "" {
+		r.store(cacheKey, rendered)
+	}
+	return rendered, nil
+}
+
+func (r *ComponentRenderer) renderComponent(buf *bytes.Buffer, view componentView) error {
+	fmt.Fprintf(buf, `<div class="cms-component cms-%s"`, template.HTMLEscapeString(view.Type))
+	if view.ID != "" {
+		fmt.Fprintf(buf, ` data-component-id="%s"`, template.HTMLEscapeString(view.ID))
+	}
+	buf.WriteString(">")
+
+	if err := r.templateFor(view.Type).Execute(buf, view); err != nil {
+		return fmt.Errorf("render component %s: %w", view.Type, err)
+	}
+
+	buf.WriteString("</div>")
+	return nil
+}
+
+// templateFor picks the template named after the type, then its group template, then the fallback
+func (r *ComponentRenderer) templateFor(componentType string) *template.Template {
+	if tmpl := r.templates.Lookup(componentType); tmpl != nil {
+		return tmpl
+	}
+	if group, ok := componentTemplateGroups[componentType]; ok {
+		if tmpl := r.templates.Lookup(group); tmpl != nil {
+			return tmpl
+		}
+	}
+	return r.templates.Lookup("fallback")
+}
+
+func (r *ComponentRenderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"prop": func(source interface{}, keys ...string) interface{} {
+			values, ok := source.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			for _, key := range keys {
+				if value, ok := values[key]; ok && value != nil && value != "" {
+					return value
+				}
+			}
+			return nil
+		},
+		"str": func(value interface{}) string {
+			switch v := value.(type) {
+			case nil:
+				return ""
+			case string:
+				return v
+			case float64, bool:
+				return fmt.Sprint(v)
+			default:
+				return ""
+			}
+		},
+		"rich": func(value interface{}) template.HTML {
+			str, _ := value.(string)
+			return template.HTML(r.htmlPolicy.Sanitize(str))
+		},
+		"truthy": func(value interface{}) bool {
+			switch v := value.(type) {
+			case bool:
+				return v
+			case string:
+				return v == "true" || v == "_blank"
+			default:
+				return false
+			}
+		},
+		"items": func(value interface{}) []interface{} {
+			list, _ := value.([]interface{})
+			return list
+		},
+		"children": r.renderChildren,
+	}
+}
+
+// renderChildren renders nested {type, props} objects found in a prop of the parent component
+func (r *ComponentRenderer) renderChildren(parent componentView, value interface{}) (template.HTML, error) {
+	list, ok := value.([]interface{})
+	if !ok || parent.Depth >= maxComponentDepth {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	for _, item := range list {
+		child, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		componentType, _ := child["type"].(string)
+		if componentType == "" {
+			continue
+		}
+		props, _ := child["props"].(map[string]interface{})
+		id, _ := child["id"].(string)
+
+		if err := r.renderComponent(&buf, componentView{Type: componentType, ID: id, Props: props, Depth: parent.Depth + 1}); err != nil {
+			return "", err
+		}
+	}
+
+	return template.HTML(buf.String()), nil
+}
+
+func (r *ComponentRenderer) cached(key string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rendered, ok := r.cache[key]
+	return rendered, ok
+}
+
+// store keeps the rendered html, dropping the oldest entries past the configured size
+func (r *ComponentRenderer) store(key, rendered string) {
+	if r.cacheSize <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cache[key]; ok {
+		return
+	}
+	for len(r.cacheOrder) >= r.cacheSize {
+		delete(r.cache, r.cacheOrder[0])
+		r.cacheOrder = r.cacheOrder[1:]
+	}
+	r.cache[key] = rendered
+	r.cacheOrder = append(r.cacheOrder, key)
+}
@@ -1,57 +1,270 @@
 package services
 
 import (
+	"errors"
 	"strings"
 
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/repositories"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AppFaqPageServiceInterface interface {
-	GetFaqPage(slug string, isAlias bool, selectParam string, language string) (*models.FaqPage, error)
-	GetFaqContentPreview(id uuid.UUID) (*models.FaqContent, error)
+	GetFaqPage(slug string, isAlias bool, selection dto.FieldSelection, language string) (*models.FaqPage, error)
+	GetFaqContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.FaqContent, error)
+	GetFaqCategoryTree(language string, typeCode string) ([]dto.FaqCategoryTypeNode, error)
+	GetFaqsGroupedByCategory(language string, typeCode string, perCategory int) ([]dto.FaqCategoryGroup, error)
+	GetFaqsByCategory(language string, categoryId uuid.UUID, page, limit int, fields []string) (*dto.FaqCategoryNode, []dto.FaqSummary, int64, error)
 }
 
 type AppFaqPageService struct {
-	repo repositories.AppFaqPageRepositoryInterface
+	repo            repositories.AppFaqPageRepositoryInterface
+	relationService CMSContentRelationServiceInterface
+	cfg             *config.Config
 }
 
-func NewAppFaqPageService(repo repositories.AppFaqPageRepositoryInterface) *AppFaqPageService {
-	return &AppFaqPageService{repo: repo}
+func NewAppFaqPageService(repo repositories.AppFaqPageRepositoryInterface, relationService CMSContentRelationServiceInterface, cfg *config.Config) *AppFaqPageService {
+	return &AppFaqPageService{
+		repo:            repo,
+		relationService: relationService,
+		cfg:             cfg,
+	}
 }
 
-func (s *AppFaqPageService) GetFaqPage(slug string, isAlias bool, selectParam string, language string) (*models.FaqPage, error) {
-	var preloads []string
+func (s *AppFaqPageService) GetFaqPage(slug string, isAlias bool, selection dto.FieldSelection, language string) (*models.FaqPage, error) {
+	selection, err := faqFieldSchema.resolveSelection(selection)
+	if err != nil {
+		return nil, err
+	}
 
-	var preloadMap = map[string]string{
-  // User input         // Actual Preload
-		"revision":           "Contents.Revision",
-		"categories":         "Contents.Categories",
-		"components":         "Contents.Components",
-		"metatag":            "Contents.MetaTag",
-	}		
+	result, err := s.repo.GetFaqPageBySlug(slug, faqFieldSchema.projection(selection), isAlias, language)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, item := range strings.Split(selectParam, ",") {
-		if preload, ok := preloadMap[strings.ToLower(strings.TrimSpace(item))]; ok {
-			preloads = append(preloads, preload)
+	// The published languages are looked up once for the hreflang links of all the contents of the page
+	var seoPaths map[enums.PageLanguage]string
+	if selection.Expands("meta_tag") && len(result.Contents) > 0 {
+		if seoPaths, err = s.publishedPaths(result.Contents[0].PageID); err != nil {
+			return nil, err
 		}
-	}	
+	}
+
+	for _, content := range result.Contents {
+		if err := s.completeContent(content, selection, seoPaths); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
 
-	result, err := s.repo.GetFaqPageBySlug(slug, preloads, isAlias, language)
+func (s *AppFaqPageService) GetFaqContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.FaqContent, error) {
+	selection, err := faqFieldSchema.resolveSelection(selection)
 	if err != nil {
 		return nil, err
-	}	
+	}
+
+	faqContent, err := s.repo.GetFaqContentPreview(id, faqFieldSchema.projection(selection))
+	if err != nil {
+		return nil, err
+	}
+
+	// Previews are never indexed, they go without canonical and hreflang links
+	if err := s.completeContent(faqContent, selection, nil); err != nil {
+		return nil, err
+	}
+
+	return faqContent, nil
+}
+
+// completeContent fills the computed fields the selection asks for, then drops the relations only loaded to compute them.
+// seoPaths are the published urls of the page by language, nil leaves out the canonical and hreflang links.
+func (s *AppFaqPageService) completeContent(content *models.FaqContent, selection dto.FieldSelection, seoPaths map[enums.PageLanguage]string) error {
+	if seoPaths != nil && selection.Expands("meta_tag") {
+		if err := attachSEOLinks(s.cfg.App.WebBaseURL, content.MetaTag, content.Language, content.URL, seoPaths); err != nil {
+			return err
+		}
+	}
+	if selection.Wants("json_ld") {
+		if err := s.attachStructuredData(content); err != nil {
+			return err
+		}
+	}
+	// Related articles and links components are filled from the relations too
+	if selection.Wants("related") || selection.Expands("components") {
+		if err := s.attachRelations(content); err != nil {
+			return err
+		}
+	}
 
-	return result, nil	
+	if !selection.Expands("meta_tag") {
+		content.MetaTag = nil
+	}
+	if !selection.Expands("components") {
+		content.Components = nil
+	}
+	return nil
 }
 
-func (s *AppFaqPageService) GetFaqContentPreview(id uuid.UUID) (*models.FaqContent, error) {
-	faqContent, err := s.repo.GetFaqContentPreview(id)
+// publishedPaths returns the url of the published content of the page in each language
+func (s *AppFaqPageService) publishedPaths(pageId uuid.UUID) (map[enums.PageLanguage]string, error) {
+	contents, err := s.repo.FindPublishedAlternates(pageId)
 	if err != nil {
 		return nil, err
 	}
 
-	return faqContent, nil
-}
\ No newline at end of file
+	paths := make(map[enums.PageLanguage]string, len(contents))
+	for _, content := range contents {
+		paths[content.Language] = content.URL
+	}
+	return paths, nil
+}
+
+// attachStructuredData generates the JSON-LD of the content, unless the editor overrode it in the meta tag
+func (s *AppFaqPageService) attachStructuredData(content *models.FaqContent) error {
+	pageURL, err := helpers.BuildContentURL(s.cfg.App.WebBaseURL, string(content.Language), content.URL)
+	if err != nil {
+		return err
+	}
+
+	jsonLD, err := helpers.BuildFaqStructuredData(content, pageURL)
+	if err != nil {
+		return err
+	}
+	content.JSONLD = jsonLD
+
+	return nil
+}
+
+// attachRelations resolves the related pages of the content and fills its related articles and links components with them
+func (s *AppFaqPageService) attachRelations(content *models.FaqContent) error {
+	related, err := s.relationService.ResolveRelations(content.ID, content.Language)
+	if err != nil {
+		return err
+	}
+	content.Related = related
+
+	return helpers.FillRelatedComponents(content.Components, related)
+}
+
+// GetFaqCategoryTree returns the category types with their published categories and faq counts
+func (s *AppFaqPageService) GetFaqCategoryTree(language string, typeCode string) ([]dto.FaqCategoryTypeNode, error) {
+	language, err := helpers.NormalizeLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.FindFaqCategoryCounts(language, strings.TrimSpace(typeCode))
+	if err != nil {
+		return nil, err
+	}
+
+	tree := []dto.FaqCategoryTypeNode{}
+	for _, count := range counts {
+		if len(tree) == 0 || tree[len(tree)-1].ID != count.CategoryTypeID {
+			tree = append(tree, dto.FaqCategoryTypeNode{
+				ID:         count.CategoryTypeID,
+				TypeCode:   count.TypeCode,
+				Name:       count.CategoryTypeName,
+				Categories: []dto.FaqCategoryNode{},
+			})
+		}
+		node := &tree[len(tree)-1]
+		node.Categories = append(node.Categories, faqCategoryNode(count))
+	}
+
+	return tree, nil
+}
+
+// GetFaqsGroupedByCategory returns the most helpful questions of every non-empty category of the type, in category weight order
+func (s *AppFaqPageService) GetFaqsGroupedByCategory(language string, typeCode string, perCategory int) ([]dto.FaqCategoryGroup, error) {
+	language, err := helpers.NormalizeLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	typeCode = strings.TrimSpace(typeCode)
+	if typeCode == "" {
+		return nil, errs.ErrCategoryTypeRequired
+	}
+
+	counts, err := s.repo.FindFaqCategoryCounts(language, typeCode)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []dto.FaqCategoryGroup{}
+	groupIndex := map[uuid.UUID]int{}
+	var categoryIds []uuid.UUID
+	for _, count := range counts {
+		if count.Count == 0 {
+			continue
+		}
+		groupIndex[count.CategoryID] = len(groups)
+		categoryIds = append(categoryIds, count.CategoryID)
+		groups = append(groups, dto.FaqCategoryGroup{
+			CategoryID: count.CategoryID,
+			Name:       count.Name,
+			Weight:     count.Weight,
+			TotalCount: count.Count,
+			Items:      []dto.FaqSummary{},
+		})
+	}
+
+	summaries, err := s.repo.FindTopFaqSummaries(categoryIds, language, perCategory)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		if index, ok := groupIndex[summary.CategoryID]; ok {
+			groups[index].Items = append(groups[index].Items, summary.FaqSummary)
+		}
+	}
+
+	return groups, nil
+}
+
+// GetFaqsByCategory returns the category with one page of its published questions, fields are the question fields the caller keeps
+func (s *AppFaqPageService) GetFaqsByCategory(language string, categoryId uuid.UUID, page, limit int, fields []string) (*dto.FaqCategoryNode, []dto.FaqSummary, int64, error) {
+	if err := validateItemFields(dto.FaqSummary{}, fields); err != nil {
+		return nil, nil, 0, err
+	}
+
+	language, err := helpers.NormalizeLanguage(language)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	count, err := s.repo.FindFaqCategoryCount(language, categoryId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, 0, errs.ErrNotFound
+		}
+		return nil, nil, 0, err
+	}
+	category := faqCategoryNode(*count)
+
+	summaries, totalCount, err := s.repo.FindFaqSummariesByCategory(categoryId, language, page, limit)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return &category, summaries, totalCount, nil
+}
+
+func faqCategoryNode(count dto.FaqCategoryCount) dto.FaqCategoryNode {
+	return dto.FaqCategoryNode{
+		ID:          count.CategoryID,
+		Name:        count.Name,
+		Description: count.Description,
+		Weight:      count.Weight,
+		Count:       count.Count,
+	}
+}
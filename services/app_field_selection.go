@@ -0,0 +1,211 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+)
+
+// includeSchema describes a relation the include param can expand
+type includeSchema struct {
+	preload  string   // Relation on the content, repositories prefix it when the contents hang off their page
+	fields   []string // Fields stored in a column of the same name
+	computed []string // Fields filled after loading
+	required []string // Columns the preload cannot do without
+}
+
+// contentFieldSchema lists what the fields and include params may name for a content type
+type contentFieldSchema struct {
+	table    string
+	fields   []string // Fields stored in a column of the same name
+	computed []string // Fields filled after loading
+	required []string // Columns the links, relations and render cache of every response are built from
+	includes map[string]includeSchema
+	// Relations the JSON-LD is generated from, loaded for it even when not included
+	structuredDataIncludes []string
+}
+
+var contentCommonFields = []string{
+	"id", "page_id", "title", "language", "authored_at", "html_input", "mode", "workflow_status", "publish_status",
+	"url_alias", "meta_tag_id", "publish_on", "unpublish_on", "authored_on", "expired_at", "created_at", "updated_at",
+}
+
+var contentComputedFields = []string{"json_ld", "related", "rendered_html"}
+
+var categoryIncludeFields = []string{"id", "category_type_id", "language_code", "name", "description", "weight", "publish_status", "created_at", "updated_at"}
+
+var metaTagIncludeSchema = includeSchema{
+	preload: "MetaTag",
+	fields: []string{"id", "title", "description", "cover_image", "robots", "canonical_url", "og_title", "og_description", "og_image", "og_type",
+		"twitter_card", "twitter_title", "twitter_description", "twitter_image", "custom_meta", "structured_data", "created_at", "updated_at"},
+	computed: []string{"alternates"},
+	required: []string{"id"},
+}
+
+func contentIncludes(foreignKey string) map[string]includeSchema {
+	return map[string]includeSchema{
+		"categories": {preload: "Categories", fields: categoryIncludeFields, required: []string{"id"}},
+		"components": {preload: "Components", fields: []string{"id", foreignKey, "type", "props", "created_at", "updated_at"}, required: []string{"id", foreignKey}},
+		"revision": {preload: "Revision", fields: []string{"id", foreignKey, "publish_status", "author", "message", "description", "created_at", "updated_at"},
+			required: []string{"id", foreignKey}},
+		"meta_tag": metaTagIncludeSchema,
+	}
+}
+
+var landingFieldSchema = func() contentFieldSchema {
+	includes := contentIncludes("landing_content_id")
+	includes["files"] = includeSchema{
+		preload:  "Files",
+		fields:   []string{"id", "landing_content_id", "name", "download_url", "file_type", "created_at", "updated_at"},
+		required: []string{"id", "landing_content_id"},
+	}
+	return contentFieldSchema{
+		table:                  "landing_contents",
+		fields:                 append(append([]string{}, contentCommonFields...), "approval_email"),
+		computed:               contentComputedFields,
+		required:               []string{"id", "page_id", "language", "url_alias", "meta_tag_id", "updated_at"},
+		includes:               includes,
+		structuredDataIncludes: []string{"meta_tag"},
+	}
+}()
+
+var partnerFieldSchema = contentFieldSchema{
+	table: "partner_contents",
+	fields: append(append([]string{}, contentCommonFields...), "approval_email", "url", "thumbnail_image", "thumbnail_alt_text", "company_logo",
+		"company_alt_text", "company_name", "company_detail", "lead_body", "challenges", "solutions", "results", "is_recommended"),
+	computed:               contentComputedFields,
+	required:               []string{"id", "page_id", "language", "url_alias", "url", "meta_tag_id", "updated_at"},
+	includes:               contentIncludes("partner_content_id"),
+	structuredDataIncludes: []string{"meta_tag"},
+}
+
+var faqFieldSchema = contentFieldSchema{
+	table:                  "faq_contents",
+	fields:                 append(append([]string{}, contentCommonFields...), "url"),
+	computed:               contentComputedFields,
+	required:               []string{"id", "page_id", "language", "url_alias", "url", "meta_tag_id", "updated_at"},
+	includes:               contentIncludes("faq_content_id"),
+	structuredDataIncludes: []string{"meta_tag", "components"},
+}
+
+// resolveSelection checks every name of the selection against the schema, a relation named in fields becomes an include
+func (schema contentFieldSchema) resolveSelection(selection dto.FieldSelection) (dto.FieldSelection, error) {
+	if selection.IsEmpty() {
+		return selection, nil
+	}
+
+	resolved := dto.FieldSelection{Includes: map[string][]string{}}
+	for _, field := range selection.Fields {
+		switch {
+		case containsField(schema.fields, field), containsField(schema.computed, field):
+			resolved.Fields = append(resolved.Fields, field)
+		case schema.includes[field].preload != "":
+			// Naming a relation in fields expands it whole
+			if _, ok := resolved.Includes[field]; !ok {
+				resolved.Includes[field] = nil
+			}
+		default:
+			return dto.FieldSelection{}, fmt.Errorf("%w: %s", errs.ErrUnknownField, field)
+		}
+	}
+
+	for name, fields := range selection.Includes {
+		include, ok := schema.includes[name]
+		if !ok {
+			return dto.FieldSelection{}, fmt.Errorf("%w: %s", errs.ErrUnknownField, name)
+		}
+		for _, field := range fields {
+			if !containsField(include.fields, field) && !containsField(include.computed, field) {
+				return dto.FieldSelection{}, fmt.Errorf("%w: %s.%s", errs.ErrUnknownField, name, field)
+			}
+		}
+		resolved.Includes[name] = append(resolved.Includes[name], fields...)
+	}
+
+	return resolved, nil
+}
+
+// projection turns a resolved selection into the columns and preloads to query, including what the computed fields need
+func (schema contentFieldSchema) projection(selection dto.FieldSelection) dto.ContentProjection {
+	if selection.IsEmpty() {
+		return dto.ContentProjection{}
+	}
+
+	projection := dto.ContentProjection{Preloads: map[string][]string{}}
+	wantsStructuredData := selection.Wants("json_ld")
+
+	// The JSON-LD reads most of the content, a trimmed column list would leave it half empty
+	if len(selection.Fields) > 0 && !wantsStructuredData {
+		columns := append([]string{}, schema.required...)
+		for _, field := range selection.Fields {
+			if containsField(schema.fields, field) && !containsField(columns, field) {
+				columns = append(columns, field)
+			}
+		}
+		for _, column := range columns {
+			projection.Columns = append(projection.Columns, schema.table+"."+column)
+		}
+	}
+
+	for name, fields := range selection.Includes {
+		include := schema.includes[name]
+		var columns []string
+		for _, field := range fields {
+			if containsField(include.fields, field) {
+				columns = append(columns, field)
+			}
+		}
+		// Only computed fields asked for still need the row
+		if len(columns) > 0 || len(fields) > 0 {
+			for _, column := range include.required {
+				if !containsField(columns, column) {
+					columns = append(columns, column)
+				}
+			}
+		}
+		projection.Preloads[include.preload] = columns
+	}
+
+	if wantsStructuredData {
+		for _, name := range schema.structuredDataIncludes {
+			include := schema.includes[name]
+			projection.Preloads[include.preload] = nil
+		}
+	}
+
+	return projection
+}
+
+// validateItemFields checks a fields param against the JSON fields of the listed items
+func validateItemFields(item interface{}, fields []string) error {
+	names := jsonFieldNames(reflect.TypeOf(item))
+	for _, field := range fields {
+		if !containsField(names, field) {
+			return fmt.Errorf("%w: %s", errs.ErrUnknownField, field)
+		}
+	}
+	return nil
+}
+
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
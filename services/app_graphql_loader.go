@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+)
+
+// batchLoader collects the keys asked for while a level of the query resolves and fetches them in one call.
+// Load hands back a thunk, the executor runs the thunks only after every field of the level queued its key,
+// so the first thunk to run fetches for all of them.
+type batchLoader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	results map[K]V
+	errors  map[K]error
+}
+
+func newBatchLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{
+		fetch:   fetch,
+		results: map[K]V{},
+		errors:  map[K]error{},
+	}
+}
+
+func (l *batchLoader[K, V]) Load(key K) func() (V, error) {
+	l.mu.Lock()
+	_, done := l.results[key]
+	_, failed := l.errors[key]
+	if !done && !failed {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			values, err := l.fetch(keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errors[k] = err
+					continue
+				}
+				l.results[k] = values[k]
+			}
+		}
+
+		return l.results[key], l.errors[key]
+	}
+}
+
+// contentKey identifies a content across the page types sharing the component and category tables
+type contentKey struct {
+	pageType  enums.PageType
+	contentId uuid.UUID
+}
+
+// graphQLLoaders live for one request, results are never shared between requests
+type graphQLLoaders struct {
+	components    *batchLoader[contentKey, []*models.Component]
+	categories    *batchLoader[contentKey, []*models.Category]
+	categoryTypes *batchLoader[uuid.UUID, *models.CategoryType]
+}
+
+type graphQLLoadersKey struct{}
+
+func newGraphQLLoaders(repo repositories.AppGraphQLRepositoryInterface) *graphQLLoaders {
+	return &graphQLLoaders{
+		components: newBatchLoader(func(keys []contentKey) (map[contentKey][]*models.Component, error) {
+			results := map[contentKey][]*models.Component{}
+			for pageType, contentIds := range groupContentKeys(keys) {
+				components, err := repo.FindComponentsByContentIDs(pageType, contentIds)
+				if err != nil {
+					return nil, err
+				}
+				for i := range components {
+					key := contentKey{pageType, componentContentID(&components[i], pageType)}
+					results[key] = append(results[key], &components[i])
+				}
+			}
+			return results, nil
+		}),
+		categories: newBatchLoader(func(keys []contentKey) (map[contentKey][]*models.Category, error) {
+			results := map[contentKey][]*models.Category{}
+			for pageType, contentIds := range groupContentKeys(keys) {
+				categories, err := repo.FindCategoriesByContentIDs(pageType, contentIds)
+				if err != nil {
+					return nil, err
+				}
+				for i := range categories {
+					key := contentKey{pageType, categories[i].ContentID}
+					results[key] = append(results[key], &categories[i].Category)
+				}
+			}
+			return results, nil
+		}),
+		categoryTypes: newBatchLoader(func(ids []uuid.UUID) (map[uuid.UUID]*models.CategoryType, error) {
+			categoryTypes, err := repo.FindCategoryTypesByIDs(ids)
+			if err != nil {
+				return nil, err
+			}
+			results := map[uuid.UUID]*models.CategoryType{}
+			for i := range categoryTypes {
+				results[categoryTypes[i].ID] = &categoryTypes[i]
+			}
+			return results, nil
+		}),
+	}
+}
+
+func loadersFromContext(ctx context.Context) *graphQLLoaders {
+	loaders, _ := ctx.Value(graphQLLoadersKey{}).(*graphQLLoaders)
+	return loaders
+}
+
+func groupContentKeys(keys []contentKey) map[enums.PageType][]uuid.UUID {
+	grouped := map[enums.PageType][]uuid.UUID{}
+	for _, key := range keys {
+		grouped[key.pageType] = append(grouped[key.pageType], key.contentId)
+	}
+	return grouped
+}
+
+func componentContentID(component *models.Component, pageType enums.PageType) uuid.UUID {
+	var contentId *uuid.UUID
+	switch pageType {
+	case enums.PageTypeLanding:
+		contentId = component.LandingContentID
+	case enums.PageTypePartner:
+		contentId = component.PartnerContentID
+	case enums.PageTypeFaq:
+		contentId = component.FaqContentID
+	}
+	if contentId == nil {
+		return uuid.Nil
+	}
+	return *contentId
+}
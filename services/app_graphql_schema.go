@@ -0,0 +1,466 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"gorm.io/datatypes"
+)
+
+// graphQLJSON passes component props, form field settings and meta tag JSON through untouched
+var graphQLJSON = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Arbitrary JSON, as stored by the CMS",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case datatypes.JSON:
+			if len(v) == 0 {
+				return nil
+			}
+			return json.RawMessage(v)
+		case datatypes.JSONMap:
+			if v == nil {
+				return nil
+			}
+			return map[string]interface{}(v)
+		default:
+			return v
+		}
+	},
+	ParseValue: func(value interface{}) interface{} {
+		return value
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return nil
+	},
+})
+
+// graphQLPageSelection loads the meta tag with the page, components and categories come from the loaders
+var graphQLPageSelection = dto.FieldSelection{Includes: map[string][]string{"meta_tag": nil}}
+
+// partnerListing is the result of the partners query
+type partnerListing struct {
+	Items      []dto.PartnerCard
+	TotalCount int64
+}
+
+// contentKeyOf tells which content a components or categories field hangs off
+func contentKeyOf(source interface{}) contentKey {
+	switch content := source.(type) {
+	case *models.LandingContent:
+		return contentKey{enums.PageTypeLanding, content.ID}
+	case *models.PartnerContent:
+		return contentKey{enums.PageTypePartner, content.ID}
+	case *models.FaqContent:
+		return contentKey{enums.PageTypeFaq, content.ID}
+	case dto.PartnerCard:
+		return contentKey{enums.PageTypePartner, content.ID}
+	}
+	return contentKey{}
+}
+
+// newAppGraphQLSchema builds the read-only schema, every root field only ever returns published content
+func newAppGraphQLSchema(s *AppGraphQLService) (graphql.Schema, error) {
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "CategoryType",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"typeCode": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":     &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	category := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description":  &graphql.Field{Type: graphql.String},
+			"weight":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"languageCode": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"categoryType": &graphql.Field{
+				Type: categoryType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					thunk := loadersFromContext(p.Context).categoryTypes.Load(p.Source.(*models.Category).CategoryTypeID)
+					return func() (interface{}, error) {
+						return thunk()
+					}, nil
+				},
+			},
+		},
+	})
+
+	component := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Component",
+		Fields: graphql.Fields{
+			"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"type":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"props": &graphql.Field{Type: graphQLJSON},
+		},
+	})
+
+	hrefLangAlternate := graphql.NewObject(graphql.ObjectConfig{
+		Name: "HrefLangAlternate",
+		Fields: graphql.Fields{
+			"hreflang": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"href":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	metaTag := graphql.NewObject(graphql.ObjectConfig{
+		Name: "MetaTag",
+		Fields: graphql.Fields{
+			"title":              &graphql.Field{Type: graphql.String},
+			"description":        &graphql.Field{Type: graphql.String},
+			"coverImage":         &graphql.Field{Type: graphql.String},
+			"robots":             &graphql.Field{Type: graphql.String},
+			"canonicalUrl":       &graphql.Field{Type: graphql.String},
+			"ogTitle":            &graphql.Field{Type: graphql.String},
+			"ogDescription":      &graphql.Field{Type: graphql.String},
+			"ogImage":            &graphql.Field{Type: graphql.String},
+			"ogType":             &graphql.Field{Type: graphql.String},
+			"twitterCard":        &graphql.Field{Type: graphql.String},
+			"twitterTitle":       &graphql.Field{Type: graphql.String},
+			"twitterDescription": &graphql.Field{Type: graphql.String},
+			"twitterImage":       &graphql.Field{Type: graphql.String},
+			"customMeta":         &graphql.Field{Type: graphQLJSON},
+			"structuredData":     &graphql.Field{Type: graphQLJSON},
+			"alternates":         &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(hrefLangAlternate))},
+		},
+	})
+
+	relatedPage := graphql.NewObject(graphql.ObjectConfig{
+		Name: "RelatedPage",
+		Fields: graphql.Fields{
+			"relationType": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"pageType":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"pageId":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"url":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"thumbnail":    &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	componentsField := &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(component))),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			thunk := loadersFromContext(p.Context).components.Load(contentKeyOf(p.Source))
+			return func() (interface{}, error) {
+				return thunk()
+			}, nil
+		},
+	}
+	categoriesField := &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(category))),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			thunk := loadersFromContext(p.Context).categories.Load(contentKeyOf(p.Source))
+			return func() (interface{}, error) {
+				return thunk()
+			}, nil
+		},
+	}
+
+	// Fields every page type has, the page specific ones are added on top
+	contentFields := func(extra graphql.Fields) graphql.Fields {
+		fields := graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"pageId":     &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"language":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"urlAlias":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"authoredAt": &graphql.Field{Type: graphql.DateTime},
+			"updatedAt":  &graphql.Field{Type: graphql.DateTime},
+			"metaTag":    &graphql.Field{Type: metaTag},
+			"jsonLd":     &graphql.Field{Type: graphQLJSON},
+			"related":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(relatedPage)))},
+			"components": componentsField,
+			"categories": categoriesField,
+		}
+		for name, field := range extra {
+			fields[name] = field
+		}
+		return fields
+	}
+
+	landingContent := graphql.NewObject(graphql.ObjectConfig{
+		Name: "LandingContent",
+		Fields: contentFields(graphql.Fields{
+			"publishOn": &graphql.Field{Type: graphql.DateTime},
+		}),
+	})
+
+	partnerContent := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PartnerContent",
+		Fields: contentFields(graphql.Fields{
+			"url":              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"publishOn":        &graphql.Field{Type: graphql.DateTime},
+			"thumbnailImage":   &graphql.Field{Type: graphql.String},
+			"thumbnailAltText": &graphql.Field{Type: graphql.String},
+			"companyLogo":      &graphql.Field{Type: graphql.String},
+			"companyAltText":   &graphql.Field{Type: graphql.String},
+			"companyName":      &graphql.Field{Type: graphql.String},
+			"companyDetail":    &graphql.Field{Type: graphql.String},
+			"leadBody":         &graphql.Field{Type: graphql.String},
+			"challenges":       &graphql.Field{Type: graphql.String},
+			"solutions":        &graphql.Field{Type: graphql.String},
+			"results":          &graphql.Field{Type: graphql.String},
+			"isRecommended":    &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		}),
+	})
+
+	faqContent := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FaqContent",
+		Fields: contentFields(graphql.Fields{
+			"url":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"publishOn": &graphql.Field{Type: graphql.DateTime},
+		}),
+	})
+
+	partnerCard := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PartnerCard",
+		Fields: graphql.Fields{
+			"id":               &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"thumbnailImage":   &graphql.Field{Type: graphql.String},
+			"thumbnailAltText": &graphql.Field{Type: graphql.String},
+			"companyLogo":      &graphql.Field{Type: graphql.String},
+			"companyAltText":   &graphql.Field{Type: graphql.String},
+			"companyName":      &graphql.Field{Type: graphql.String},
+			"leadBody":         &graphql.Field{Type: graphql.String},
+			"url":              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"urlAlias":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"isRecommended":    &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"publishOn":        &graphql.Field{Type: graphql.DateTime},
+			"categories":       categoriesField,
+		},
+	})
+
+	partnerListingType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PartnerListing",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(partnerCard)))},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	faqCategory := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FaqCategory",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.String},
+			"weight":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"count":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	faqCategoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FaqCategoryType",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"typeCode":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":       &graphql.Field{Type: graphql.String},
+			"categories": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(faqCategory)))},
+		},
+	})
+
+	formField := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FormField",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"label":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"fieldKey":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"fieldType":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"placeholder":  &graphql.Field{Type: graphql.String},
+			"isRequired":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"defaultValue": &graphql.Field{Type: graphql.String},
+			"properties":   &graphql.Field{Type: graphQLJSON},
+			"display":      &graphql.Field{Type: graphQLJSON},
+			"orderIndex":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	formSection := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FormSection",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title":       &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"orderIndex":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"fields":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(formField)))},
+		},
+	})
+
+	form := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Form",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"slug":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.String},
+			"language": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if language := p.Source.(*models.Form).Language; language != nil {
+						return string(*language), nil
+					}
+					return nil, nil
+				},
+			},
+			"sections": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(formSection)))},
+		},
+	})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"language": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+		"alias":    &graphql.ArgumentConfig{Type: graphql.String},
+		"url":      &graphql.ArgumentConfig{Type: graphql.String, Description: "Looked up when no alias is given"},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"landingPage": &graphql.Field{
+				Type: landingContent,
+				Args: graphql.FieldConfigArgument{
+					"language": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"alias":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					page, err := s.landingService.GetLandingPageByUrlAlias(p.Args["alias"].(string), graphQLPageSelection, p.Args["language"].(string))
+					if err != nil || len(page.Contents) == 0 {
+						return nil, notFoundAsNull(err)
+					}
+					return page.Contents[0], nil
+				},
+			},
+			"partnerPage": &graphql.Field{
+				Type: partnerContent,
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					slug, isAlias, err := pageSlugArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					page, err := s.partnerService.GetPartnerPage(slug, isAlias, graphQLPageSelection, p.Args["language"].(string))
+					if err != nil || len(page.Contents) == 0 {
+						return nil, notFoundAsNull(err)
+					}
+					return page.Contents[0], nil
+				},
+			},
+			"faqPage": &graphql.Field{
+				Type: faqContent,
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					slug, isAlias, err := pageSlugArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					page, err := s.faqService.GetFaqPage(slug, isAlias, graphQLPageSelection, p.Args["language"].(string))
+					if err != nil || len(page.Contents) == 0 {
+						return nil, notFoundAsNull(err)
+					}
+					return page.Contents[0], nil
+				},
+			},
+			"partners": &graphql.Field{
+				Type: graphql.NewNonNull(partnerListingType),
+				Args: graphql.FieldConfigArgument{
+					"language":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"categoryIds":   &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))},
+					"categoryMatch": &graphql.ArgumentConfig{Type: graphql.String, Description: "How category types are combined, all or any"},
+					"recommended":   &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"q":             &graphql.ArgumentConfig{Type: graphql.String},
+					"sort":          &graphql.ArgumentConfig{Type: graphql.String},
+					"page":          &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"limit":         &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: dto.PartnerListingDefaultLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					query := dto.PartnerListingQuery{
+						Language: enums.PageLanguage(p.Args["language"].(string)),
+						Page:     p.Args["page"].(int),
+						Limit:    p.Args["limit"].(int),
+					}
+					query.CategoryMatch, _ = p.Args["categoryMatch"].(string)
+					query.Q, _ = p.Args["q"].(string)
+					query.Sort, _ = p.Args["sort"].(string)
+					if recommended, ok := p.Args["recommended"].(bool); ok {
+						query.IsRecommended = &recommended
+					}
+					if categoryIds, ok := p.Args["categoryIds"].([]interface{}); ok {
+						for _, raw := range categoryIds {
+							id, err := uuid.Parse(raw.(string))
+							if err != nil {
+								return nil, errs.ErrInvalidUUIDFormat
+							}
+							query.CategoryIDs = append(query.CategoryIDs, id)
+						}
+					}
+
+					cards, totalCount, _, err := s.partnerService.FindPartnerListing(&query)
+					if err != nil {
+						return nil, err
+					}
+					return partnerListing{Items: cards, TotalCount: totalCount}, nil
+				},
+			},
+			"faqCategories": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(faqCategoryType))),
+				Args: graphql.FieldConfigArgument{
+					"language": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"typeCode": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					typeCode, _ := p.Args["typeCode"].(string)
+					return s.faqService.GetFaqCategoryTree(p.Args["language"].(string), typeCode)
+				},
+			},
+			"form": &graphql.Field{
+				Type: form,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					formId, err := uuid.Parse(p.Args["id"].(string))
+					if err != nil {
+						return nil, errs.ErrInvalidUUIDFormat
+					}
+					result, err := s.formRepo.GetFormStructure(formId)
+					if err != nil {
+						return nil, notFoundAsNull(err)
+					}
+					return result, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// notFoundAsNull lets a missing page resolve to null, the GraphQL way of saying 404
+func notFoundAsNull(err error) error {
+	if errors.Is(err, errs.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func pageSlugArgs(args map[string]interface{}) (string, bool, error) {
+	if alias, _ := args["alias"].(string); alias != "" {
+		return alias, true, nil
+	}
+	if url, _ := args["url"].(string); url != "" {
+		return url, false, nil
+	}
+	return "", false, errs.ErrBadRequest
+}
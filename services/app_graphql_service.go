@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type AppGraphQLServiceInterface interface {
+	Execute(ctx context.Context, request dto.GraphQLRequest) (*graphql.Result, error)
+}
+
+type AppGraphQLService struct {
+	landingService AppLandingPageServiceInterface
+	partnerService AppPartnerPageServiceInterface
+	faqService     AppFaqPageServiceInterface
+	formRepo       repositories.FormRepositoryInterface
+	repo           repositories.AppGraphQLRepositoryInterface
+	cfg            *config.Config
+	schema         graphql.Schema
+
+	mu               sync.Mutex
+	persistedQueries map[string]string // Query by its sha256, filled by clients sending query and hash together
+	persistedOrder   []string
+}
+
+func NewAppGraphQLService(
+	landingService AppLandingPageServiceInterface,
+	partnerService AppPartnerPageServiceInterface,
+	faqService AppFaqPageServiceInterface,
+	formRepo repositories.FormRepositoryInterface,
+	repo repositories.AppGraphQLRepositoryInterface,
+	cfg *config.Config,
+) (*AppGraphQLService, error) {
+	s := &AppGraphQLService{
+		landingService:   landingService,
+		partnerService:   partnerService,
+		faqService:       faqService,
+		formRepo:         formRepo,
+		repo:             repo,
+		cfg:              cfg,
+		persistedQueries: map[string]string{},
+	}
+
+	schema, err := newAppGraphQLSchema(s)
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+
+	return s, nil
+}
+
+// Execute runs a query against the published content schema.
+// Errors returned here concern the request as a whole, errors of single fields are part of the result.
+func (s *AppGraphQLService) Execute(ctx context.Context, request dto.GraphQLRequest) (*graphql.Result, error) {
+	query, hash, err := s.resolvePersistedQuery(request)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, nil
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, nil
+	}
+
+	if err := s.checkLimits(document, request); err != nil {
+		return nil, err
+	}
+
+	// Only queries that parse, validate and fit the limits are worth remembering
+	if hash != "" {
+		s.persistQuery(hash, query)
+	}
+
+	ctx = context.WithValue(ctx, graphQLLoadersKey{}, newGraphQLLoaders(s.repo))
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	}), nil
+}
+
+// resolvePersistedQuery returns the query to run and, when the client sent one, the hash to store it under
+func (s *AppGraphQLService) resolvePersistedQuery(request dto.GraphQLRequest) (string, string, error) {
+	if request.Extensions == nil || request.Extensions.PersistedQuery == nil || s.cfg.GraphQL.PersistedQuerySize <= 0 {
+		if request.Query == "" {
+			// A hash only request to a server not persisting queries, the client answers with the full query
+			if request.Extensions != nil && request.Extensions.PersistedQuery != nil {
+				return "", "", errs.ErrPersistedQueryNotFound
+			}
+			return "", "", errs.ErrMissingGraphQLQuery
+		}
+		return request.Query, "", nil
+	}
+
+	hash := strings.ToLower(request.Extensions.PersistedQuery.Sha256Hash)
+	if request.Query == "" {
+		s.mu.Lock()
+		query, ok := s.persistedQueries[hash]
+		s.mu.Unlock()
+		if !ok {
+			return "", "", errs.ErrPersistedQueryNotFound
+		}
+		return query, "", nil
+	}
+
+	sum := sha256.Sum256([]byte(request.Query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return "", "", errs.ErrPersistedQueryHashMismatch
+	}
+	return request.Query, hash, nil
+}
+
+// persistQuery keeps the PersistedQuerySize most recently added queries
+func (s *AppGraphQLService) persistQuery(hash string, query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.persistedQueries[hash]; ok {
+		return
+	}
+	if len(s.persistedOrder) >= s.cfg.GraphQL.PersistedQuerySize {
+		delete(s.persistedQueries, s.persistedOrder[0])
+		s.persistedOrder = s.persistedOrder[1:]
+	}
+	s.persistedQueries[hash] = query
+	s.persistedOrder = append(s.persistedOrder, hash)
+}
+
+// checkLimits refuses the operation about to run when it nests too deep or would resolve too many fields
+func (s *AppGraphQLService) checkLimits(document *ast.Document, request dto.GraphQLRequest) error {
+	analyzer := queryAnalyzer{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: request.Variables,
+		listSize:  s.cfg.GraphQL.DefaultListSize,
+		visiting:  map[string]bool{},
+		queryType: s.schema.QueryType(),
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			analyzer.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if request.OperationName == "" || (definition.Name != nil && definition.Name.Value == request.OperationName) {
+				if operation == nil {
+					operation = definition
+				}
+			}
+		}
+	}
+	if operation == nil {
+		// Execute reports the unknown operation name
+		return nil
+	}
+
+	depth, complexity := analyzer.selectionSet(operation.SelectionSet, s.schema.QueryType(), 0, 0)
+	if s.cfg.GraphQL.MaxDepth > 0 && depth > s.cfg.GraphQL.MaxDepth {
+		return errs.ErrQueryTooDeep
+	}
+	if s.cfg.GraphQL.MaxIntrospectionDepth > 0 && analyzer.introspectionDepth > s.cfg.GraphQL.MaxIntrospectionDepth {
+		return errs.ErrQueryTooDeep
+	}
+	if s.cfg.GraphQL.MaxComplexity > 0 && complexity > s.cfg.GraphQL.MaxComplexity {
+		return errs.ErrQueryTooComplex
+	}
+	return nil
+}
+
+// queryAnalyzer measures an operation before it runs.
+// Depth counts nested fields, fragments do not add a level. Complexity counts the fields that will resolve,
+// a list multiplies what is selected below it by the limit asked for, or by the default list size.
+// Introspection walks the fixed schema rather than the content, so under __schema and __type every field costs one
+// and the nesting is measured apart, against its own limit, as tooling asks for type references many levels deep.
+type queryAnalyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	listSize  int
+	visiting  map[string]bool
+	queryType *graphql.Object
+
+	introspecting      bool
+	introspectionDepth int // Deepest nesting below __schema or __type
+}
+
+// selectionSet returns the deepest level and the complexity of a selection on parent.
+// limit is the limit argument of the field above, it applies to the first list selected under it.
+func (a *queryAnalyzer) selectionSet(set *ast.SelectionSet, parent *graphql.Object, depth int, limit int) (int, int) {
+	if set == nil || parent == nil {
+		return depth, 0
+	}
+
+	maxDepth, complexity := depth, 0
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			field := a.fieldDefinition(parent, selection.Name.Value)
+			if field == nil {
+				continue
+			}
+
+			introspection := !a.introspecting && (field == graphql.SchemaMetaFieldDef || field == graphql.TypeMetaFieldDef)
+			if introspection {
+				a.introspecting = true
+			}
+
+			childLimit := limit
+			if fieldLimit, ok := a.limitArgument(selection); ok {
+				childLimit = fieldLimit
+			}
+
+			multiplier := 1
+			fieldType := field.Type
+			if nonNull, ok := fieldType.(*graphql.NonNull); ok {
+				fieldType = nonNull.OfType
+			}
+			if _, ok := fieldType.(*graphql.List); ok && !a.introspecting {
+				multiplier = a.listSize
+				if childLimit > 0 {
+					multiplier = childLimit
+				}
+				childLimit = 0
+			}
+
+			object, _ := graphql.GetNamed(field.Type).(*graphql.Object)
+			childDepth, childComplexity := a.selectionSet(selection.SelectionSet, object, depth+1, childLimit)
+			if introspection {
+				a.introspecting = false
+				if childDepth-depth > a.introspectionDepth {
+					a.introspectionDepth = childDepth - depth
+				}
+				complexity += 1 + childComplexity
+				continue
+			}
+			if childDepth > maxDepth {
+				maxDepth = childDepth
+			}
+			complexity += 1 + multiplier*childComplexity
+		case *ast.InlineFragment:
+			childDepth, childComplexity := a.selectionSet(selection.SelectionSet, parent, depth, limit)
+			if childDepth > maxDepth {
+				maxDepth = childDepth
+			}
+			complexity += childComplexity
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := a.fragments[name]
+			// Validation already refuses fragment cycles, this only keeps a bad document from recursing forever
+			if !ok || a.visiting[name] {
+				continue
+			}
+			a.visiting[name] = true
+			childDepth, childComplexity := a.selectionSet(fragment.SelectionSet, parent, depth, limit)
+			a.visiting[name] = false
+			if childDepth > maxDepth {
+				maxDepth = childDepth
+			}
+			complexity += childComplexity
+		}
+	}
+
+	return maxDepth, complexity
+}
+
+// fieldDefinition finds a field of parent, introspection included so a query walking the schema is measured like any other
+func (a *queryAnalyzer) fieldDefinition(parent *graphql.Object, name string) *graphql.FieldDefinition {
+	switch {
+	case name == graphql.TypeNameMetaFieldDef.Name:
+		return graphql.TypeNameMetaFieldDef
+	case name == graphql.SchemaMetaFieldDef.Name && parent == a.queryType:
+		return graphql.SchemaMetaFieldDef
+	case name == graphql.TypeMetaFieldDef.Name && parent == a.queryType:
+		return graphql.TypeMetaFieldDef
+	}
+	return parent.Fields()[name]
+}
+
+func (a *queryAnalyzer) limitArgument(field *ast.Field) (int, bool) {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			limit, err := strconv.Atoi(value.Value)
+			return limit, err == nil
+		case *ast.Variable:
+			switch limit := a.variables[value.Name.Value].(type) {
+			case float64:
+				return int(limit), true
+			case int:
+				return limit, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -1,60 +1,153 @@
 package services
 
 import (
-	"strings"
-
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/repositories"
 
 	"github.com/google/uuid"
 )
 
 type AppLandingPageServiceInterface interface {
-	GetLandingPageByUrlAlias(urlAlias string, selectParam string, language string) (*models.LandingPage, error)
-	GetLandingContentPreview(id uuid.UUID) (*models.LandingContent, error)
+	GetLandingPageByUrlAlias(urlAlias string, selection dto.FieldSelection, language string) (*models.LandingPage, error)
+	GetLandingContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.LandingContent, error)
 }
 
 type AppLandingPageService struct {
-	repo repositories.AppLandingPageRepositoryInterface
+	repo            repositories.AppLandingPageRepositoryInterface
+	relationService CMSContentRelationServiceInterface
+	cfg             *config.Config
 }
 
-func NewAppLandingPageService(repo repositories.AppLandingPageRepositoryInterface) *AppLandingPageService {
-	return &AppLandingPageService{repo: repo}
+func NewAppLandingPageService(repo repositories.AppLandingPageRepositoryInterface, relationService CMSContentRelationServiceInterface, cfg *config.Config) *AppLandingPageService {
+	return &AppLandingPageService{
+		repo:            repo,
+		relationService: relationService,
+		cfg:             cfg,
+	}
 }
 
-func (s *AppLandingPageService) GetLandingPageByUrlAlias(urlAlias string, selectParam string, language string) (*models.LandingPage, error) {
-	var preloads []string
+func (s *AppLandingPageService) GetLandingPageByUrlAlias(urlAlias string, selection dto.FieldSelection, language string) (*models.LandingPage, error) {
+	selection, err := landingFieldSchema.resolveSelection(selection)
+	if err != nil {
+		return nil, err
+	}
 
-	// Define a map of valid preloads
-	var preloadMap = map[string]string{
-  // User input         // Actual Preload
-		"files":         			"Contents.Files",
-		"revision":           "Contents.Revision",
-		"categories":         "Contents.Categories",
-		"components":         "Contents.Components",
-		"metatag":            "Contents.MetaTag",
+	result, err := s.repo.GetLandingPageByUrlAlias(urlAlias, landingFieldSchema.projection(selection), language)
+	if err != nil {
+		return nil, err
 	}
 
-	// Split the selectParam by comma and trim spaces and check if they exist in the preloadMap
-	// If they do, append them to the preloads slice
-	for _, item := range strings.Split(selectParam, ",") {
-		if preload, ok := preloadMap[strings.ToLower(strings.TrimSpace(item))]; ok {
-			preloads = append(preloads, preload)
+	// The published languages are looked up once for the hreflang links of all the contents of the page
+	var seoPaths map[enums.PageLanguage]string
+	if selection.Expands("meta_tag") && len(result.Contents) > 0 {
+		if seoPaths, err = s.publishedPaths(result.Contents[0].PageID); err != nil {
+			return nil, err
 		}
 	}
-	result, err := s.repo.GetLandingPageByUrlAlias(urlAlias, preloads, language)
-	if err != nil {
-		return nil, err
+
+	for _, content := range result.Contents {
+		if err := s.completeContent(content, selection, seoPaths); err != nil {
+			return nil, err
+		}
 	}
 
 	return result, nil
 }
 
-func (s *AppLandingPageService) GetLandingContentPreview(id uuid.UUID) (*models.LandingContent, error) {
-	landingContent, err := s.repo.GetLandingContentPreview(id)
+func (s *AppLandingPageService) GetLandingContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.LandingContent, error) {
+	selection, err := landingFieldSchema.resolveSelection(selection)
 	if err != nil {
 		return nil, err
 	}
 
+	landingContent, err := s.repo.GetLandingContentPreview(id, landingFieldSchema.projection(selection))
+	if err != nil {
+		return nil, err
+	}
+
+	// Previews are never indexed, they go without canonical and hreflang links
+	if err := s.completeContent(landingContent, selection, nil); err != nil {
+		return nil, err
+	}
+
 	return landingContent, nil
-}
\ No newline at end of file
+}
+
+// completeContent fills the computed fields the selection asks for, then drops the relations only loaded to compute them.
+// seoPaths are the published urls of the page by language, nil leaves out the canonical and hreflang links.
+func (s *AppLandingPageService) completeContent(content *models.LandingContent, selection dto.FieldSelection, seoPaths map[enums.PageLanguage]string) error {
+	if seoPaths != nil && selection.Expands("meta_tag") {
+		if err := attachSEOLinks(s.cfg.App.WebBaseURL, content.MetaTag, content.Language, content.UrlAlias, seoPaths); err != nil {
+			return err
+		}
+	}
+	if selection.Wants("json_ld") {
+		if err := s.attachStructuredData(content); err != nil {
+			return err
+		}
+	}
+	// Related articles and links components are filled from the relations too
+	if selection.Wants("related") || selection.Expands("components") {
+		if err := s.attachRelations(content); err != nil {
+			return err
+		}
+	}
+
+	if !selection.Expands("meta_tag") {
+		content.MetaTag = nil
+	}
+	if !selection.Expands("components") {
+		content.Components = nil
+	}
+	return nil
+}
+
+// publishedPaths returns the url of the published content of the page in each language
+func (s *AppLandingPageService) publishedPaths(pageId uuid.UUID) (map[enums.PageLanguage]string, error) {
+	contents, err := s.repo.FindPublishedAlternates(pageId)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[enums.PageLanguage]string, len(contents))
+	for _, content := range contents {
+		paths[content.Language] = content.UrlAlias
+	}
+	return paths, nil
+}
+
+// attachStructuredData generates the JSON-LD of the content, unless the editor overrode it in the meta tag
+func (s *AppLandingPageService) attachStructuredData(content *models.LandingContent) error {
+	homeURL, err := helpers.BuildContentURL(s.cfg.App.WebBaseURL, string(content.Language), "")
+	if err != nil {
+		return err
+	}
+
+	pageURL, err := helpers.BuildContentURL(s.cfg.App.WebBaseURL, string(content.Language), content.UrlAlias)
+	if err != nil {
+		return err
+	}
+
+	jsonLD, err := helpers.BuildLandingStructuredData(content, homeURL, pageURL)
+	if err != nil {
+		return err
+	}
+	content.JSONLD = jsonLD
+
+	return nil
+}
+
+// attachRelations resolves the related pages of the content and fills its related articles and links components with them
+func (s *AppLandingPageService) attachRelations(content *models.LandingContent) error {
+	related, err := s.relationService.ResolveRelations(content.ID, content.Language)
+	if err != nil {
+		return err
+	}
+	content.Related = related
+
+	return helpers.FillRelatedComponents(content.Components, related)
+}
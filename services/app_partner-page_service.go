@@ -3,57 +3,240 @@ package services
 import (
 	"strings"
 
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/repositories"
 
 	"github.com/google/uuid"
 )
 
 type AppPartnerPageServiceInterface interface {
-	GetPartnerPage(slug string, isAlias bool, selectParam string, language string) (*models.PartnerPage, error)
-	GetPartnerContentPreview(id uuid.UUID) (*models.PartnerContent, error)
+	GetPartnerPage(slug string, isAlias bool, selection dto.FieldSelection, language string) (*models.PartnerPage, error)
+	GetPartnerContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.PartnerContent, error)
+	FindPartnerListing(query *dto.PartnerListingQuery) ([]dto.PartnerCard, int64, *dto.PartnerListingFacets, error)
 }
 
 type AppPartnerPageService struct {
-	repo repositories.AppPartnerPageRepositoryInterface
+	repo            repositories.AppPartnerPageRepositoryInterface
+	relationService CMSContentRelationServiceInterface
+	cfg             *config.Config
 }
 
-func NewAppPartnerPageService(repo repositories.AppPartnerPageRepositoryInterface) *AppPartnerPageService {
-	return &AppPartnerPageService{repo: repo}
+func NewAppPartnerPageService(repo repositories.AppPartnerPageRepositoryInterface, relationService CMSContentRelationServiceInterface, cfg *config.Config) *AppPartnerPageService {
+	return &AppPartnerPageService{
+		repo:            repo,
+		relationService: relationService,
+		cfg:             cfg,
+	}
 }
 
-func (s *AppPartnerPageService) GetPartnerPage(slug string, isAlias bool, selectParam string, language string) (*models.PartnerPage, error) {
-	var preloads []string
+func (s *AppPartnerPageService) GetPartnerPage(slug string, isAlias bool, selection dto.FieldSelection, language string) (*models.PartnerPage, error) {
+	selection, err := partnerFieldSchema.resolveSelection(selection)
+	if err != nil {
+		return nil, err
+	}
 
-	var preloadMap = map[string]string{
-  // User input         // Actual Preload
-		"revision":           "Contents.Revision",
-		"categories":         "Contents.Categories",
-		"components":         "Contents.Components",
-		"metatag":            "Contents.MetaTag",
-	}	
+	result, err := s.repo.GetPartnerPageBySlug(slug, partnerFieldSchema.projection(selection), isAlias, language)
+	if err != nil {
+		return nil, err
+	}
 
-	// Split the selectParam by comma and trim spaces and check if they exist in the preloadMap
-	// If they do, append them to the preloads slice
-	for _, item := range strings.Split(selectParam, ",") {
-		if preload, ok := preloadMap[strings.ToLower(strings.TrimSpace(item))]; ok {
-			preloads = append(preloads, preload)
+	// The published languages are looked up once for the hreflang links of all the contents of the page
+	var seoPaths map[enums.PageLanguage]string
+	if selection.Expands("meta_tag") && len(result.Contents) > 0 {
+		if seoPaths, err = s.publishedPaths(result.Contents[0].PageID); err != nil {
+			return nil, err
 		}
-	}	
+	}
 
-	result, err := s.repo.GetPartnerPageBySlug(slug, preloads, isAlias, language)
-	if err != nil {
-		return nil, err
-	}	
+	for _, content := range result.Contents {
+		if err := s.completeContent(content, selection, seoPaths); err != nil {
+			return nil, err
+		}
+	}
 
 	return result, nil
 }
 
-func (s *AppPartnerPageService) GetPartnerContentPreview(id uuid.UUID) (*models.PartnerContent, error) {
-	partnerContent, err := s.repo.GetPartnerContentPreview(id)
+func (s *AppPartnerPageService) GetPartnerContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.PartnerContent, error) {
+	selection, err := partnerFieldSchema.resolveSelection(selection)
+	if err != nil {
+		return nil, err
+	}
+
+	partnerContent, err := s.repo.GetPartnerContentPreview(id, partnerFieldSchema.projection(selection))
 	if err != nil {
 		return nil, err
 	}
 
+	// Previews are never indexed, they go without canonical and hreflang links
+	if err := s.completeContent(partnerContent, selection, nil); err != nil {
+		return nil, err
+	}
+
 	return partnerContent, nil
-}
\ No newline at end of file
+}
+
+// completeContent fills the computed fields the selection asks for, then drops the relations only loaded to compute them.
+// seoPaths are the published urls of the page by language, nil leaves out the canonical and hreflang links.
+func (s *AppPartnerPageService) completeContent(content *models.PartnerContent, selection dto.FieldSelection, seoPaths map[enums.PageLanguage]string) error {
+	if seoPaths != nil && selection.Expands("meta_tag") {
+		if err := attachSEOLinks(s.cfg.App.WebBaseURL, content.MetaTag, content.Language, content.URL, seoPaths); err != nil {
+			return err
+		}
+	}
+	if selection.Wants("json_ld") {
+		if err := s.attachStructuredData(content); err != nil {
+			return err
+		}
+	}
+	// Related articles and links components are filled from the relations too
+	if selection.Wants("related") || selection.Expands("components") {
+		if err := s.attachRelations(content); err != nil {
+			return err
+		}
+	}
+
+	if !selection.Expands("meta_tag") {
+		content.MetaTag = nil
+	}
+	if !selection.Expands("components") {
+		content.Components = nil
+	}
+	return nil
+}
+
+// publishedPaths returns the url of the published content of the page in each language
+func (s *AppPartnerPageService) publishedPaths(pageId uuid.UUID) (map[enums.PageLanguage]string, error) {
+	contents, err := s.repo.FindPublishedAlternates(pageId)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[enums.PageLanguage]string, len(contents))
+	for _, content := range contents {
+		paths[content.Language] = content.URL
+	}
+	return paths, nil
+}
+
+// attachStructuredData generates the JSON-LD of the content, unless the editor overrode it in the meta tag
+func (s *AppPartnerPageService) attachStructuredData(content *models.PartnerContent) error {
+	pageURL, err := helpers.BuildContentURL(s.cfg.App.WebBaseURL, string(content.Language), content.URL)
+	if err != nil {
+		return err
+	}
+
+	jsonLD, err := helpers.BuildPartnerStructuredData(content, pageURL)
+	if err != nil {
+		return err
+	}
+	content.JSONLD = jsonLD
+
+	return nil
+}
+
+// attachRelations resolves the related pages of the content and fills its related articles and links components with them
+func (s *AppPartnerPageService) attachRelations(content *models.PartnerContent) error {
+	related, err := s.relationService.ResolveRelations(content.ID, content.Language)
+	if err != nil {
+		return err
+	}
+	content.Related = related
+
+	return helpers.FillRelatedComponents(content.Components, related)
+}
+
+// FindPartnerListing returns one page of published partner cards with the facet counts of the whole listing,
+// the query is normalized in place so callers can echo the page and limit actually used
+func (s *AppPartnerPageService) FindPartnerListing(query *dto.PartnerListingQuery) ([]dto.PartnerCard, int64, *dto.PartnerListingFacets, error) {
+	if err := normalizePartnerListingQuery(query); err != nil {
+		return nil, 0, nil, err
+	}
+
+	partnerContents, totalCount, err := s.repo.FindPartnerListing(*query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	facets, err := s.repo.FindPartnerListingFacets(*query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	cards := make([]dto.PartnerCard, 0, len(partnerContents))
+	for _, content := range partnerContents {
+		cards = append(cards, dto.PartnerCard{
+			ID:               content.ID,
+			Title:            content.Title,
+			ThumbnailImage:   content.ThumbnailImage,
+			ThumbnailAltText: content.ThumbnailAltText,
+			CompanyLogo:      content.CompanyLogo,
+			CompanyAltText:   content.CompanyAltText,
+			CompanyName:      content.CompanyName,
+			LeadBody:         content.LeadBody,
+			URL:              content.URL,
+			URLAlias:         content.URLAlias,
+			IsRecommended:    content.IsRecommended,
+			PublishOn:        content.PublishOn,
+		})
+	}
+
+	return cards, totalCount, facets, nil
+}
+
+func normalizePartnerListingQuery(query *dto.PartnerListingQuery) error {
+	if err := validateItemFields(dto.PartnerCard{}, query.Fields); err != nil {
+		return err
+	}
+
+	language, err := helpers.NormalizeLanguage(string(query.Language))
+	if err != nil {
+		return err
+	}
+	query.Language = enums.PageLanguage(language)
+
+	switch query.Sort = strings.ToLower(strings.TrimSpace(query.Sort)); query.Sort {
+	case "":
+		query.Sort = dto.PartnerSortRecommended
+	case dto.PartnerSortRecommended, dto.PartnerSortPublishDesc, dto.PartnerSortPublishAsc, dto.PartnerSortTitleAsc, dto.PartnerSortTitleDesc:
+	default:
+		return errs.ErrInvalidListingSort
+	}
+
+	switch query.CategoryMatch = strings.ToLower(strings.TrimSpace(query.CategoryMatch)); query.CategoryMatch {
+	case "":
+		query.CategoryMatch = dto.CategoryMatchAll
+	case dto.CategoryMatchAll, dto.CategoryMatchAny:
+	default:
+		return errs.ErrInvalidCategoryMatch
+	}
+
+	// Duplicated ids would look like unknown categories to the repository
+	seen := make(map[uuid.UUID]bool, len(query.CategoryIDs))
+	categoryIDs := make([]uuid.UUID, 0, len(query.CategoryIDs))
+	for _, id := range query.CategoryIDs {
+		if !seen[id] {
+			seen[id] = true
+			categoryIDs = append(categoryIDs, id)
+		}
+	}
+	query.CategoryIDs = categoryIDs
+
+	query.Q = strings.TrimSpace(query.Q)
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = dto.PartnerListingDefaultLimit
+	}
+	if query.Limit > dto.PartnerListingMaxLimit {
+		query.Limit = dto.PartnerListingMaxLimit
+	}
+
+	return nil
+}
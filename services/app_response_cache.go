@@ -0,0 +1,273 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// purgeTag is broadcast in place of a tag to drop every response
+const purgeTag = "*"
+
+type AppResponseCacheInterface interface {
+	Get(key string) (*dto.CachedResponse, bool)
+	Set(key string, response *dto.CachedResponse, tags dto.CacheTags)
+	InvalidatePage(pageType enums.PageType, pageId uuid.UUID)
+	InvalidateCategory(categoryId uuid.UUID)
+	InvalidateForm(formId uuid.UUID)
+	InvalidateMedia(downloadURL string)
+	Purge()
+	MaxAge() time.Duration
+}
+
+// AppResponseCache keeps the most recently used app responses, each indexed by the pages, categories, forms
+// and media files it was built from so a change drops exactly the responses showing it. Invalidations are
+// applied here and broadcast to the other instances through the repository, nil keeps them local.
+type AppResponseCache struct {
+	repo repositories.AppCacheRepositoryInterface
+	cfg  *config.Config
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List                     // Front is the most recently used
+	tagged  map[string]map[string]struct{} // Keys of the responses built from a tag
+	// Responses read before the latest invalidation may show what it dropped
+	invalidatedAt time.Time
+}
+
+type cacheEntry struct {
+	key      string
+	response *dto.CachedResponse
+	tags     []string
+	storedAt time.Time
+}
+
+func NewAppResponseCache(repo repositories.AppCacheRepositoryInterface, cfg *config.Config) *AppResponseCache {
+	return &AppResponseCache{
+		repo:    repo,
+		cfg:     cfg,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+		tagged:  map[string]map[string]struct{}{},
+	}
+}
+
+func (c *AppResponseCache) Get(key string) (*dto.CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if c.cfg.AppCache.TTL > 0 && time.Since(entry.storedAt) > c.cfg.AppCache.TTL {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.response, true
+}
+
+// Set stores the response, evicting the least recently used ones past the configured size
+func (c *AppResponseCache) Set(key string, response *dto.CachedResponse, tags dto.CacheTags) {
+	if c.cfg.AppCache.Size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !response.LoadedAt.After(c.invalidatedAt) {
+		return
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	for c.order.Len() >= c.cfg.AppCache.Size {
+		c.remove(c.order.Back())
+	}
+
+	entry := &cacheEntry{key: key, response: response, tags: cacheTagKeys(tags), storedAt: time.Now()}
+	c.entries[key] = c.order.PushFront(entry)
+	for _, tag := range entry.tags {
+		if c.tagged[tag] == nil {
+			c.tagged[tag] = map[string]struct{}{}
+		}
+		c.tagged[tag][key] = struct{}{}
+	}
+}
+
+func (c *AppResponseCache) InvalidatePage(pageType enums.PageType, pageId uuid.UUID) {
+	c.broadcast(pageTag(pageType, pageId))
+}
+
+func (c *AppResponseCache) InvalidateCategory(categoryId uuid.UUID) {
+	c.broadcast("category:" + categoryId.String())
+}
+
+func (c *AppResponseCache) InvalidateForm(formId uuid.UUID) {
+	c.broadcast("form:" + formId.String())
+}
+
+// InvalidateMedia drops the responses linking to a media file, whatever host or query the link was written with
+func (c *AppResponseCache) InvalidateMedia(downloadURL string) {
+	if tag := mediaTag(downloadURL); tag != "" {
+		c.broadcast(tag)
+	}
+}
+
+// HandleDomainEvent drops the responses showing a changed page or media file. The event is delivered to one
+// instance, the invalidation is broadcast from there to the others.
+func (c *AppResponseCache) HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error {
+	switch event.EventType {
+	case enums.DomainEventContentSaved, enums.DomainEventContentDeleted:
+		var data dto.ContentEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return err
+		}
+		c.InvalidatePage(data.PageType, data.PageID)
+	case enums.DomainEventMediaFileUploaded, enums.DomainEventMediaFileDeleted:
+		var data dto.MediaFileEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return err
+		}
+		// A new file is not linked from anything yet, only one taking the url of another can be cached
+		if event.EventType == enums.DomainEventMediaFileDeleted || data.Replaced {
+			c.InvalidateMedia(data.DownloadURL)
+		}
+	}
+	return nil
+}
+
+func (c *AppResponseCache) Purge() {
+	c.broadcast(purgeTag)
+}
+
+// Listen applies the invalidations broadcast by every instance until the context is cancelled. Notifications
+// sent while the connection was down are lost, so the whole cache is dropped when it comes back.
+func (c *AppResponseCache) Listen(ctx context.Context, dsn string) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("App cache listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(repositories.AppCacheChannel); err != nil {
+		log.Printf("App cache listener failed to listen, invalidations stay local: %v", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-listener.NotificationChannel():
+			// A nil notification follows a reconnect
+			if notification == nil {
+				c.apply(purgeTag)
+				continue
+			}
+			c.apply(notification.Extra)
+		}
+	}
+}
+
+func (c *AppResponseCache) MaxAge() time.Duration {
+	return c.cfg.AppCache.MaxAge
+}
+
+// broadcast applies the invalidation here at once, then sends it to the other instances. Applying it here is
+// not left to the notification, a request right after a change on this instance must not see the old response.
+func (c *AppResponseCache) broadcast(tag string) {
+	c.apply(tag)
+	if c.repo == nil {
+		return
+	}
+	if err := c.repo.NotifyInvalidation(tag); err != nil {
+		log.Printf("Failed to broadcast the app cache invalidation of %s: %v", tag, err)
+	}
+}
+
+// apply drops the responses built from the tag, or every response for the purge tag
+func (c *AppResponseCache) apply(tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidatedAt = time.Now()
+	if tag == purgeTag {
+		c.entries = map[string]*list.Element{}
+		c.order.Init()
+		c.tagged = map[string]map[string]struct{}{}
+		return
+	}
+	for key := range c.tagged[tag] {
+		if element, ok := c.entries[key]; ok {
+			c.remove(element)
+		}
+	}
+	delete(c.tagged, tag)
+}
+
+// remove drops an entry and its tag index, the caller holds the lock
+func (c *AppResponseCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*cacheEntry)
+	delete(c.entries, entry.key)
+	for _, tag := range entry.tags {
+		delete(c.tagged[tag], entry.key)
+		if len(c.tagged[tag]) == 0 {
+			delete(c.tagged, tag)
+		}
+	}
+}
+
+func cacheTagKeys(tags dto.CacheTags) []string {
+	var keys []string
+	for _, page := range tags.Pages {
+		keys = append(keys, pageTag(page.PageType, page.PageID))
+	}
+	for _, id := range tags.CategoryIDs {
+		keys = append(keys, "category:"+id.String())
+	}
+	for _, id := range tags.FormIDs {
+		keys = append(keys, "form:"+id.String())
+	}
+	for _, link := range tags.MediaURLs {
+		if tag := mediaTag(link); tag != "" {
+			keys = append(keys, tag)
+		}
+	}
+	return keys
+}
+
+// mediaTag keys a media file by the path of its url, the same file is linked by its full url or its path alone
+func mediaTag(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	if parsed, err := url.Parse(link); err == nil && parsed.Path != "" {
+		link = parsed.Path
+	}
+	return "media:" + link
+}
+
+func pageTag(pageType enums.PageType, pageId uuid.UUID) string {
+	return "page:" + string(pageType) + ":" + pageId.String()
+}
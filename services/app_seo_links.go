@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+)
+
+// attachSEOLinks fills the hreflang alternates and, when not overridden, the canonical url of a published content.
+// paths has the url of the published content of the page in each language. The content itself is always listed,
+// every page of an hreflang set has to link to itself too.
+func attachSEOLinks(baseURL string, metaTag *models.MetaTag, language enums.PageLanguage, urlPath string, paths map[enums.PageLanguage]string) error {
+	if metaTag == nil {
+		return nil
+	}
+
+	if metaTag.CanonicalURL == "" {
+		canonicalURL, err := helpers.BuildContentURL(baseURL, string(language), urlPath)
+		if err != nil {
+			return err
+		}
+		metaTag.CanonicalURL = canonicalURL
+	}
+
+	alternatePaths := make(map[enums.PageLanguage]string, len(paths)+1)
+	for alternateLanguage, alternatePath := range paths {
+		alternatePaths[alternateLanguage] = alternatePath
+	}
+	alternatePaths[language] = urlPath
+
+	alternates, err := helpers.BuildHrefLangAlternates(baseURL, alternatePaths)
+	if err != nil {
+		return err
+	}
+	metaTag.Alternates = alternates
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+)
+
+const (
+	analyticsMaxRange  = 366 * 24 * time.Hour
+	analyticsDay       = 24 * time.Hour
+	utmMaxLength       = 100
+	referrerMaxLength  = 255
+	pageReferrersLimit = 10
+)
+
+type CMSAnalyticsServiceInterface interface {
+	TrackPageView(request dto.PageViewRequest, clientIP, userAgent string) error
+	GetPageAnalytics(pageType enums.PageType, pageId uuid.UUID, query dto.AnalyticsQuery) (*dto.PageAnalytics, error)
+	GetTopPages(query dto.AnalyticsQuery, limit int) ([]dto.TopPage, error)
+	GetCampaignTraffic(query dto.AnalyticsQuery, limit int) ([]dto.CampaignTraffic, error)
+}
+
+type CMSAnalyticsService struct {
+	repo  repositories.CMSAnalyticsRepositoryInterface
+	cfg   *config.Config
+	queue chan *models.PageView
+}
+
+func NewCMSAnalyticsService(repo repositories.CMSAnalyticsRepositoryInterface, cfg *config.Config) *CMSAnalyticsService {
+	return &CMSAnalyticsService{
+		repo:  repo,
+		cfg:   cfg,
+		queue: make(chan *models.PageView, max(cfg.Analytics.BufferSize, 1)),
+	}
+}
+
+// TrackPageView validates the view and queues it for the batch writer, the client ip itself is never stored
+func (s *CMSAnalyticsService) TrackPageView(request dto.PageViewRequest, clientIP, userAgent string) error {
+	switch request.PageType {
+	case enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq:
+	case "":
+		return errs.ErrInvalidPageView
+	default:
+		return errs.ErrInvalidPageType
+	}
+	if request.PageID == "" || request.Language == "" {
+		return errs.ErrInvalidPageView
+	}
+	pageId, err := uuid.Parse(request.PageID)
+	if err != nil {
+		return errs.ErrInvalidUUIDFormat
+	}
+	language, err := helpers.NormalizeLanguage(string(request.Language))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	view := &models.PageView{
+		PageType:     request.PageType,
+		PageID:       pageId,
+		Language:     enums.PageLanguage(language),
+		VisitorHash:  s.visitorHash(now, clientIP, userAgent),
+		ReferrerHost: s.referrerHost(request.Referrer),
+		UTMSource:    normalizeUTM(request.UTMSource),
+		UTMMedium:    normalizeUTM(request.UTMMedium),
+		UTMCampaign:  normalizeUTM(request.UTMCampaign),
+		CreatedAt:    now,
+	}
+
+	select {
+	case s.queue <- view:
+	default:
+		// The writer is behind, a lost view is better than holding up the page
+	}
+
+	return nil
+}
+
+// visitorHash counts a client once per day, the day is part of the key so visits cannot be linked across days
+func (s *CMSAnalyticsService) visitorHash(now time.Time, clientIP, userAgent string) string {
+	return helpers.HashVisitor(s.cfg.SecretKey.HashSalt, now.Format("2006-01-02"), clientIP, userAgent)
+}
+
+// referrerHost keeps only the host of an external referrer, paths and queries may identify the visitor
+func (s *CMSAnalyticsService) referrerHost(referrer string) string {
+	parsed, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if site, err := url.Parse(s.cfg.App.WebBaseURL); err == nil && strings.EqualFold(site.Hostname(), host) {
+		return ""
+	}
+	return truncateRunes(host, referrerMaxLength)
+}
+
+func normalizeUTM(value string) string {
+	return truncateRunes(strings.ToLower(strings.TrimSpace(value)), utmMaxLength)
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return value
+}
+
+// StartBatchWriter writes the queued page views every BatchSize views or FlushInterval until the context is done,
+// then writes what is still queued
+func (s *CMSAnalyticsService) StartBatchWriter(ctx context.Context) {
+	batchSize := max(s.cfg.Analytics.BatchSize, 1)
+	interval := s.cfg.Analytics.FlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]*models.PageView, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.repo.CreatePageViews(batch); err != nil {
+			log.Printf("Failed to write %d page views: %v", len(batch), err)
+		}
+		batch = make([]*models.PageView, 0, batchSize)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case view := <-s.queue:
+					batch = append(batch, view)
+				default:
+					flush()
+					return
+				}
+			}
+		case view := <-s.queue:
+			batch = append(batch, view)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// RunRollup rolls the raw page views up per UTC day, from the last rolled up day (or yesterday when earlier) to today,
+// then deletes the raw views past the retention
+func (s *CMSAnalyticsService) RunRollup(now time.Time) error {
+	today := now.UTC().Truncate(analyticsDay)
+	yesterday := today.Add(-analyticsDay)
+
+	start, err := s.repo.FindLatestRollupDay()
+	if err != nil {
+		return err
+	}
+	if start == nil {
+		if start, err = s.repo.FindEarliestPageView(); err != nil {
+			return err
+		}
+		if start == nil {
+			return nil
+		}
+	}
+
+	// Views of the last minutes of yesterday may have been written after the last run
+	day := start.UTC().Truncate(analyticsDay)
+	if day.After(yesterday) {
+		day = yesterday
+	}
+	for ; !day.After(today); day = day.Add(analyticsDay) {
+		if err := s.repo.RollupDay(day); err != nil {
+			return err
+		}
+	}
+
+	if s.cfg.Analytics.RawRetention > 0 {
+		// Yesterday is rolled up again on the next run, so its raw views are always kept
+		cutoff := today.Add(-s.cfg.Analytics.RawRetention)
+		if cutoff.After(yesterday) {
+			cutoff = yesterday
+		}
+		if _, err := s.repo.DeletePageViewsBefore(cutoff); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartRollupScheduler runs the daily rollup every interval until the context is done
+func (s *CMSAnalyticsService) StartRollupScheduler(ctx context.Context) {
+	if s.cfg.Analytics.RollupInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Analytics.RollupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RunRollup(time.Now()); err != nil {
+				log.Printf("Scheduled page view rollup failed: %v", err)
+			}
+		}
+	}
+}
+
+// GetPageAnalytics returns the daily views of a page with its totals and top referrers
+func (s *CMSAnalyticsService) GetPageAnalytics(pageType enums.PageType, pageId uuid.UUID, query dto.AnalyticsQuery) (*dto.PageAnalytics, error) {
+	query.PageType = pageType
+	query.PageID = &pageId
+	if err := normalizeAnalyticsQuery(&query); err != nil {
+		return nil, err
+	}
+
+	series, err := s.repo.FindPageViewSeries(query)
+	if err != nil {
+		return nil, err
+	}
+	visitors, err := s.repo.CountVisitors(query)
+	if err != nil {
+		return nil, err
+	}
+	referrers, err := s.repo.FindTopReferrers(query, pageReferrersLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &dto.PageAnalytics{
+		PageType:  pageType,
+		PageID:    pageId,
+		Visitors:  visitors,
+		Series:    series,
+		Referrers: referrers,
+	}
+	for _, point := range series {
+		analytics.Views += point.Views
+	}
+
+	return analytics, nil
+}
+
+// GetTopPages returns the most viewed page languages in the range
+func (s *CMSAnalyticsService) GetTopPages(query dto.AnalyticsQuery, limit int) ([]dto.TopPage, error) {
+	if err := normalizeAnalyticsQuery(&query); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindTopPages(query, limit)
+}
+
+// GetCampaignTraffic returns the views brought by each UTM campaign in the range
+func (s *CMSAnalyticsService) GetCampaignTraffic(query dto.AnalyticsQuery, limit int) ([]dto.CampaignTraffic, error) {
+	if err := normalizeAnalyticsQuery(&query); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindCampaignTraffic(query, limit)
+}
+
+// normalizeAnalyticsQuery validates the filters, the rollups are per UTC day so the range is widened to whole days
+func normalizeAnalyticsQuery(query *dto.AnalyticsQuery) error {
+	if query.From.IsZero() || query.To.IsZero() || query.To.Before(query.From) || query.To.Sub(query.From) > analyticsMaxRange {
+		return errs.ErrInvalidDateRange
+	}
+	query.From = query.From.UTC().Truncate(analyticsDay)
+	query.To = query.To.UTC().Truncate(analyticsDay)
+
+	switch query.PageType {
+	case "", enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq:
+	default:
+		return errs.ErrInvalidPageType
+	}
+
+	if query.Language != "" {
+		language, err := helpers.NormalizeLanguage(string(query.Language))
+		if err != nil {
+			return err
+		}
+		query.Language = enums.PageLanguage(language)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// auditLogExportBatchSize is how many entries an export reads at a time
+const auditLogExportBatchSize = 500
+
+type CMSAuditLogServiceInterface interface {
+	Record(auditLog *models.AuditLog) error
+	Snapshot(table string, id uuid.UUID) (datatypes.JSON, error)
+	FindAuditLogs(query dto.AuditLogQuery, page, limit int) ([]models.AuditLog, int64, error)
+	FindAuditLogByID(id uuid.UUID) (*models.AuditLog, error)
+	ExportAuditLogs(query dto.AuditLogQuery, fn func(auditLogs []models.AuditLog) error) error
+}
+
+type CMSAuditLogService struct {
+	repo repositories.CMSAuditLogRepositoryInterface
+}
+
+func NewCMSAuditLogService(repo repositories.CMSAuditLogRepositoryInterface) *CMSAuditLogService {
+	return &CMSAuditLogService{repo: repo}
+}
+
+// Record writes the entry with the secrets of its snapshots redacted
+func (s *CMSAuditLogService) Record(auditLog *models.AuditLog) error {
+	auditLog.Before = helpers.RedactJSON(auditLog.Before)
+	auditLog.After = helpers.RedactJSON(auditLog.After)
+
+	return s.repo.CreateAuditLog(auditLog)
+}
+
+// Snapshot returns the stored row of an entity before it is changed, nil when it does not exist
+func (s *CMSAuditLogService) Snapshot(table string, id uuid.UUID) (datatypes.JSON, error) {
+	snapshot, err := s.repo.FindRowSnapshot(table, id)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	return helpers.RedactJSON(snapshot), nil
+}
+
+func validateAuditLogQuery(query dto.AuditLogQuery) error {
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return errs.ErrInvalidDateRange
+	}
+	return nil
+}
+
+func (s *CMSAuditLogService) FindAuditLogs(query dto.AuditLogQuery, page, limit int) ([]models.AuditLog, int64, error) {
+	if err := validateAuditLogQuery(query); err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.FindAuditLogs(query, page, limit)
+}
+
+func (s *CMSAuditLogService) FindAuditLogByID(id uuid.UUID) (*models.AuditLog, error) {
+	return s.repo.FindAuditLogByID(id)
+}
+
+// ExportAuditLogs hands the matching entries to fn oldest first, a batch at a time
+func (s *CMSAuditLogService) ExportAuditLogs(query dto.AuditLogQuery, fn func(auditLogs []models.AuditLog) error) error {
+	if err := validateAuditLogQuery(query); err != nil {
+		return err
+	}
+
+	return s.repo.FindAuditLogsInBatches(query, auditLogExportBatchSize, fn)
+}
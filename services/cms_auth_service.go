@@ -53,6 +53,10 @@ func (s *CMSAuthService) RegisterUser(user *models.User) (*models.User, error) {
 	// Set provider to normal
 	user.Provider = "normal"
 
+	// Roles are only given by an admin through AssignUserRole
+	user.RoleID = nil
+	user.Role = nil
+
 	return s.repo.RegisterUser(user)
 }
 
@@ -80,6 +84,9 @@ func (s *CMSAuthService) LoginUser(user *models.User) (*models.User, string, err
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
 	claims["user_id"] = selectedUser.ID
+	if selectedUser.Role != nil {
+		claims["role"] = selectedUser.Role.Name
+	}
 	claims["exp"] = time.Now().Add(time.Hour * 2).Unix()
 
 	t, err := token.SignedString([]byte(jwtSecretKey))
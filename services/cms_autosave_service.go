@@ -0,0 +1,246 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type CMSAutosaveServiceInterface interface {
+	GetAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) (*dto.AutosaveState, error)
+	SaveAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID, payload []byte) (*dto.AutosaveState, error)
+	DiscardAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) error
+	PromoteAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) (interface{}, error)
+	WithPageAccess(access *dto.PageAccess) CMSAutosaveServiceInterface
+}
+
+type CMSAutosaveService struct {
+	repo           repositories.CMSAutosaveRepositoryInterface
+	landingService CMSLandingPageServiceInterface
+	partnerService CMSPartnerPageServiceInterface
+	faqService     CMSFaqPageServiceInterface
+	access         *dto.PageAccess // Pages the user of the request may autosave, nil for any
+}
+
+func NewCMSAutosaveService(
+	repo repositories.CMSAutosaveRepositoryInterface,
+	landingService CMSLandingPageServiceInterface,
+	partnerService CMSPartnerPageServiceInterface,
+	faqService CMSFaqPageServiceInterface,
+) *CMSAutosaveService {
+	return &CMSAutosaveService{
+		repo:           repo,
+		landingService: landingService,
+		partnerService: partnerService,
+		faqService:     faqService,
+	}
+}
+
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSAutosaveService) WithPageAccess(access *dto.PageAccess) CMSAutosaveServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// findContentRef locates the content and fails with ErrPageAccessDenied unless the access allows the action on it
+func (s *CMSAutosaveService) findContentRef(pageType enums.PageType, contentId uuid.UUID, action enums.PermissionAction) (*repositories.ContentRef, error) {
+	ref, err := s.repo.FindContentRef(pageType, contentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPageAccess(s.access, pageType, pageContentSelector{contentId: contentId}, func() ([]dto.PageContentScope, error) {
+		return s.repo.FindContentScopes(pageType, ref.PageID)
+	}, action); err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+// GetAutosave loads the autosave of the user when the editor is opened on the content
+func (s *CMSAutosaveService) GetAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) (*dto.AutosaveState, error) {
+	ref, err := s.findContentRef(pageType, contentId, enums.PermissionActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	autosave, err := s.findAutosave(userId, pageType, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedAfter time.Time
+	if autosave != nil {
+		updatedAfter = autosave.UpdatedAt
+	}
+
+	return s.buildState(userId, pageType, contentId, ref, autosave, updatedAfter)
+}
+
+// SaveAutosave overwrites the autosave slot of the user, no content version or revision is created
+func (s *CMSAutosaveService) SaveAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID, payload []byte) (*dto.AutosaveState, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(payload, &object); err != nil || object == nil {
+		return nil, errs.ErrInvalidAutosavePayload
+	}
+
+	ref, err := s.findContentRef(pageType, contentId, enums.PermissionActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Warn about the autosaves the other users made since this user last autosaved
+	previous, err := s.findAutosave(userId, pageType, ref)
+	if err != nil {
+		return nil, err
+	}
+	var updatedAfter time.Time
+	if previous != nil {
+		updatedAfter = previous.UpdatedAt
+	}
+
+	autosave := &models.ContentAutosave{
+		PageType:      pageType,
+		PageID:        ref.PageID,
+		Language:      ref.Language,
+		UserID:        userId,
+		BaseContentID: contentId,
+		Payload:       datatypes.JSON(payload),
+		UpdatedAt:     time.Now(),
+	}
+	if err := s.repo.UpsertAutosave(autosave); err != nil {
+		return nil, err
+	}
+
+	return s.buildState(userId, pageType, contentId, ref, autosave, updatedAfter)
+}
+
+func (s *CMSAutosaveService) DiscardAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) error {
+	ref, err := s.findContentRef(pageType, contentId, enums.PermissionActionUpdate)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteAutosave(pageType, ref.PageID, ref.Language, userId)
+}
+
+// PromoteAutosave saves the autosave as a real content version with its revision, then clears the slot
+func (s *CMSAutosaveService) PromoteAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) (interface{}, error) {
+	ref, err := s.findContentRef(pageType, contentId, enums.PermissionActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	autosave, err := s.findAutosave(userId, pageType, ref)
+	if err != nil {
+		return nil, err
+	}
+	if autosave == nil {
+		return nil, errs.ErrAutosaveNotFound
+	}
+
+	// Saving on top of an archived version would leave two current versions
+	if autosave.BaseContentID != contentId || ref.Mode == enums.PageModeHistories {
+		return nil, errs.ErrAutosaveOutdated
+	}
+
+	var saved interface{}
+	switch pageType {
+	case enums.PageTypeLanding:
+		var content models.LandingContent
+		if err := json.Unmarshal(autosave.Payload, &content); err != nil {
+			return nil, errs.ErrInvalidAutosavePayload
+		}
+		helpers.SanitizeLandingContent(&content)
+		saved, err = s.landingService.WithPageAccess(s.access).UpdateLandingContent(&content, contentId)
+	case enums.PageTypePartner:
+		var content models.PartnerContent
+		if err := json.Unmarshal(autosave.Payload, &content); err != nil {
+			return nil, errs.ErrInvalidAutosavePayload
+		}
+		helpers.SanitizePartnerContent(&content)
+		saved, err = s.partnerService.WithPageAccess(s.access).UpdatePartnerContent(&content, contentId)
+	case enums.PageTypeFaq:
+		var content models.FaqContent
+		if err := json.Unmarshal(autosave.Payload, &content); err != nil {
+			return nil, errs.ErrInvalidAutosavePayload
+		}
+		helpers.SanitizeFaqContent(&content)
+		saved, err = s.faqService.WithPageAccess(s.access).UpdateFaqContent(&content, contentId)
+	default:
+		return nil, errs.ErrInvalidPageType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.DeleteAutosave(pageType, ref.PageID, ref.Language, userId); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (s *CMSAutosaveService) findAutosave(userId uuid.UUID, pageType enums.PageType, ref *repositories.ContentRef) (*models.ContentAutosave, error) {
+	autosave, err := s.repo.FindAutosave(pageType, ref.PageID, ref.Language, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return autosave, nil
+}
+
+func (s *CMSAutosaveService) buildState(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID, ref *repositories.ContentRef, autosave *models.ContentAutosave, updatedAfter time.Time) (*dto.AutosaveState, error) {
+	newerAutosaves, err := s.repo.FindNewerAutosaves(pageType, ref.PageID, ref.Language, userId, updatedAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &dto.AutosaveState{
+		NewerAutosaves: []dto.NewerAutosave{},
+		BaseOutdated:   ref.Mode == enums.PageModeHistories,
+	}
+
+	if autosave != nil {
+		state.Autosave = &dto.AutosaveResponse{
+			ID:            autosave.ID.String(),
+			PageType:      autosave.PageType,
+			PageID:        autosave.PageID.String(),
+			Language:      autosave.Language,
+			BaseContentID: autosave.BaseContentID.String(),
+			Payload:       json.RawMessage(autosave.Payload),
+			UpdatedAt:     autosave.UpdatedAt,
+		}
+		state.BaseOutdated = state.BaseOutdated || autosave.BaseContentID != contentId
+	}
+
+	for _, newer := range newerAutosaves {
+		item := dto.NewerAutosave{
+			UserID:        newer.UserID.String(),
+			BaseContentID: newer.BaseContentID.String(),
+			UpdatedAt:     newer.UpdatedAt,
+		}
+		if newer.User != nil && newer.User.Email != nil {
+			item.UserEmail = *newer.User.Email
+		}
+		state.NewerAutosaves = append(state.NewerAutosaves, item)
+	}
+
+	return state, nil
+}
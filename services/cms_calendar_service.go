@@ -0,0 +1,284 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	calendarMaxRange       = 366 * 24 * time.Hour
+	calendarFeedPastWindow = 30 * 24 * time.Hour  // Feeds show recent events too, calendar apps keep past ones
+	calendarFeedNextWindow = 180 * 24 * time.Hour // And the next six months of schedule
+)
+
+type CMSCalendarServiceInterface interface {
+	FindCalendarEvents(query dto.CalendarQuery) ([]dto.CalendarEvent, error)
+	IssueFeedToken(userId uuid.UUID) (*dto.CalendarFeedTokenResponse, error)
+	RevokeFeedToken(userId uuid.UUID) error
+	BuildFeed(token string, query dto.CalendarQuery) (string, error)
+	WithPageAccess(access *dto.PageAccess) CMSCalendarServiceInterface
+}
+
+type CMSCalendarService struct {
+	repo        repositories.CMSCalendarRepositoryInterface
+	authRepo    repositories.CMSAuthRepositoryInterface
+	roleService CMSRoleServiceInterface
+	cfg         *config.Config
+	access      *dto.PageAccess // Pages the user of the request may read, nil for any
+}
+
+func NewCMSCalendarService(
+	repo repositories.CMSCalendarRepositoryInterface,
+	authRepo repositories.CMSAuthRepositoryInterface,
+	roleService CMSRoleServiceInterface,
+	cfg *config.Config,
+) *CMSCalendarService {
+	return &CMSCalendarService{repo: repo, authRepo: authRepo, roleService: roleService, cfg: cfg}
+}
+
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSCalendarService) WithPageAccess(access *dto.PageAccess) CMSCalendarServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// FindCalendarEvents returns the publish, unpublish and expiry dates in the range across every page type, in time order
+func (s *CMSCalendarService) FindCalendarEvents(query dto.CalendarQuery) ([]dto.CalendarEvent, error) {
+	if err := normalizeCalendarQuery(&query); err != nil {
+		return nil, err
+	}
+	// A feed already carries the access of its owner
+	if query.Access == nil {
+		query.Access = s.access
+	}
+
+	events := []dto.CalendarEvent{}
+	addEvents := func(pageType enums.PageType, pageId, contentId uuid.UUID, language enums.PageLanguage, title string, status enums.WorkflowStatus, revision *models.Revision, dates map[enums.CalendarEventType]time.Time) {
+		author := ""
+		if revision != nil {
+			author = revision.Author
+		}
+		for eventType, at := range dates {
+			if at.Before(query.From) || at.After(query.To) {
+				continue
+			}
+			events = append(events, dto.CalendarEvent{
+				UID:            contentId.String() + "-" + string(eventType),
+				Type:           eventType,
+				At:             at,
+				PageType:       pageType,
+				PageID:         pageId.String(),
+				ContentID:      contentId.String(),
+				Language:       language,
+				Title:          title,
+				WorkflowStatus: status,
+				Author:         author,
+				EditURL:        helpers.BuildCMSEditURL(s.cfg.App.CMSBaseURL, pageType, pageId, contentId, language),
+			})
+		}
+	}
+
+	if query.PageType == "" || query.PageType == enums.PageTypeLanding {
+		landingContents, err := s.repo.FindCalendarLandingContents(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, content := range landingContents {
+			dates := map[enums.CalendarEventType]time.Time{enums.CalendarEventExpire: content.ExpiredAt}
+			if content.PublishOn != nil {
+				dates[enums.CalendarEventPublish] = *content.PublishOn
+			}
+			if content.UnpublishOn != nil {
+				dates[enums.CalendarEventUnpublish] = *content.UnpublishOn
+			}
+			addEvents(enums.PageTypeLanding, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus, content.Revision, dates)
+		}
+	}
+
+	if query.PageType == "" || query.PageType == enums.PageTypePartner {
+		partnerContents, err := s.repo.FindCalendarPartnerContents(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, content := range partnerContents {
+			addEvents(enums.PageTypePartner, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus, content.Revision, map[enums.CalendarEventType]time.Time{
+				enums.CalendarEventPublish:   content.PublishOn,
+				enums.CalendarEventUnpublish: content.UnpublishOn,
+				enums.CalendarEventExpire:    content.ExpiredAt,
+			})
+		}
+	}
+
+	if query.PageType == "" || query.PageType == enums.PageTypeFaq {
+		faqContents, err := s.repo.FindCalendarFaqContents(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, content := range faqContents {
+			addEvents(enums.PageTypeFaq, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus, content.Revision, map[enums.CalendarEventType]time.Time{
+				enums.CalendarEventPublish:   content.PublishOn,
+				enums.CalendarEventUnpublish: content.UnpublishOn,
+				enums.CalendarEventExpire:    content.ExpiredAt,
+			})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].At.Equal(events[j].At) {
+			return events[i].At.Before(events[j].At)
+		}
+		return events[i].UID < events[j].UID
+	})
+
+	return events, nil
+}
+
+// IssueFeedToken creates a new feed url for the user, the previous one stops working
+func (s *CMSCalendarService) IssueFeedToken(userId uuid.UUID) (*dto.CalendarFeedTokenResponse, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(secret)
+
+	feedToken := &models.CalendarFeedToken{
+		UserID:    userId,
+		TokenHash: hashFeedToken(token),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.UpsertFeedToken(feedToken); err != nil {
+		return nil, err
+	}
+
+	return &dto.CalendarFeedTokenResponse{
+		URL:       strings.TrimSuffix(s.cfg.App.APIBaseURL, "/") + "/api/v1/calendar/feeds/" + token,
+		CreatedAt: feedToken.CreatedAt,
+	}, nil
+}
+
+func (s *CMSCalendarService) RevokeFeedToken(userId uuid.UUID) error {
+	return s.repo.DeleteFeedToken(userId)
+}
+
+// BuildFeed renders the calendar of the token's owner as iCalendar, defaulting to a window around now
+func (s *CMSCalendarService) BuildFeed(token string, query dto.CalendarQuery) (string, error) {
+	feedToken, err := s.repo.FindFeedTokenByHash(hashFeedToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errs.ErrInvalidCalendarFeedToken
+		}
+		return "", err
+	}
+
+	access, err := s.feedOwnerAccess(feedToken)
+	if err != nil {
+		return "", err
+	}
+	query.Access = access
+
+	now := time.Now()
+	if query.From.IsZero() {
+		query.From = now.Add(-calendarFeedPastWindow)
+	}
+	if query.To.IsZero() {
+		query.To = now.Add(calendarFeedNextWindow)
+	}
+
+	events, err := s.FindCalendarEvents(query)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.TouchFeedToken(feedToken.ID, now); err != nil {
+		return "", err
+	}
+
+	uidDomain := "cms-api"
+	if apiURL, err := url.Parse(s.cfg.App.APIBaseURL); err == nil && apiURL.Hostname() != "" {
+		uidDomain = apiURL.Hostname()
+	}
+
+	return helpers.BuildICalendar("Editorial Calendar", uidDomain, events, now), nil
+}
+
+// feedOwnerAccess resolves the owner of the feed on every fetch, so the feed only has the pages they may read now.
+// The token of an owner who is gone or may no longer read pages is revoked.
+func (s *CMSCalendarService) feedOwnerAccess(feedToken *models.CalendarFeedToken) (*dto.PageAccess, error) {
+	user, err := s.authRepo.FindUserById(feedToken.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	canRead := false
+	if err == nil && user.Role != nil {
+		canRead, err = s.roleService.HasPermission(user.Role.Name, enums.PermissionResourcePages, enums.PermissionActionRead)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !canRead {
+		if err := s.repo.DeleteFeedToken(feedToken.UserID); err != nil {
+			return nil, err
+		}
+		return nil, errs.ErrInvalidCalendarFeedToken
+	}
+
+	return s.roleService.FindPageAccess(user.Role.Name, user.ID)
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeCalendarQuery(query *dto.CalendarQuery) error {
+	if query.From.IsZero() || query.To.IsZero() || query.To.Before(query.From) || query.To.Sub(query.From) > calendarMaxRange {
+		return errs.ErrInvalidCalendarRange
+	}
+
+	switch query.PageType {
+	case "", enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq:
+	default:
+		return errs.ErrInvalidPageType
+	}
+
+	if query.Language != "" {
+		language, err := helpers.NormalizeLanguage(string(query.Language))
+		if err != nil {
+			return err
+		}
+		query.Language = enums.PageLanguage(language)
+	}
+	if query.Status != "" {
+		status, err := helpers.NormalizeWorkflowStatus(string(query.Status))
+		if err != nil {
+			return err
+		}
+		query.Status = enums.WorkflowStatus(status)
+	}
+	if query.CategoryID != "" {
+		if _, err := uuid.Parse(query.CategoryID); err != nil {
+			return errs.ErrInvalidUUIDFormat
+		}
+	}
+
+	return nil
+}
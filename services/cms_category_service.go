@@ -17,8 +17,9 @@ type CMSCategoryServiceInterface interface {
 	CreateCategory(req dto.CategoryCreateRequest) (*dto.CategoryResponse, error)
 	GetCategoryByUUID(uuidStr string) (*dto.CategoryResponse, error)
 	ListAllCategories(filter dto.CategoryFilter) ([]dto.CategoryResponse, error)
+	ListCategoriesByCursor(filter dto.CategoryFilter, cursorQuery dto.CursorQuery) ([]dto.CategoryResponse, *dto.CursorPage, error)
 	UpdateCategoryByUUID(uuidStr string, req dto.CategoryUpdateRequest) (*dto.CategoryResponse, error)
-	DeleteCategoryByUUID(uuidStr string) error
+	DeleteCategoryByUUID(uuidStr string, force bool) error
 	MapCategoryModelToResponse(cat *models.Category) (*dto.CategoryResponse, error) // << เพิ่ม method นี้ใน Interface
 }
 
@@ -155,6 +156,19 @@ func (s *cmsCategoryService) ListAllCategories(filter dto.CategoryFilter) ([]dto
 		return nil, fmt.Errorf("failed to list categories from repository: %w", err)
 	}
 
+	return s.mapCategoryResponses(categories), nil
+}
+
+func (s *cmsCategoryService) ListCategoriesByCursor(filter dto.CategoryFilter, cursorQuery dto.CursorQuery) ([]dto.CategoryResponse, *dto.CursorPage, error) {
+	categories, page, err := s.categoryRepo.ListCategoriesByCursor(filter, cursorQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list categories from repository: %w", err)
+	}
+
+	return s.mapCategoryResponses(categories), page, nil
+}
+
+func (s *cmsCategoryService) mapCategoryResponses(categories []models.Category) []dto.CategoryResponse {
 	responses := make([]dto.CategoryResponse, 0, len(categories))
 	for _, cat := range categories {
 		resp, mapErr := s.MapCategoryModelToResponse(&cat)
@@ -166,7 +180,7 @@ func (s *cmsCategoryService) ListAllCategories(filter dto.CategoryFilter) ([]dto
 			responses = append(responses, *resp)
 		}
 	}
-	return responses, nil
+	return responses
 }
 
 func (s *cmsCategoryService) UpdateCategoryByUUID(uuidStr string, req dto.CategoryUpdateRequest) (*dto.CategoryResponse, error) {
@@ -208,7 +222,8 @@ func (s *cmsCategoryService) UpdateCategoryByUUID(uuidStr string, req dto.Catego
 	return s.MapCategoryModelToResponse(updatedModel)
 }
 
-func (s *cmsCategoryService) DeleteCategoryByUUID(uuidStr string) error {
+// DeleteCategoryByUUID deletes the category, unless forced it fails with ErrItemInUse while published content is tagged with it
+func (s *cmsCategoryService) DeleteCategoryByUUID(uuidStr string, force bool) error {
 	categoryID, err := uuid.Parse(uuidStr)
 	if err != nil {
 		return fmt.Errorf("invalid category UUID format for delete: %s", uuidStr)
@@ -221,7 +236,7 @@ func (s *cmsCategoryService) DeleteCategoryByUUID(uuidStr string) error {
 		return fmt.Errorf("failed to fetch category %s before delete: %w", uuidStr, getErr)
 	}
 
-	err = s.categoryRepo.DeleteCategory(categoryID)
+	err = s.categoryRepo.DeleteCategory(categoryID, force)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return fmt.Errorf("category with ID %s not found during delete operation by repository", uuidStr)
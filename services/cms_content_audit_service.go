@@ -0,0 +1,121 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+)
+
+type CMSContentAuditServiceInterface interface {
+	AuditContent(pageType string, contentId uuid.UUID) (*dto.ContentAuditReport, error)
+	WithPageAccess(access *dto.PageAccess) CMSContentAuditServiceInterface
+}
+
+type CMSContentAuditService struct {
+	repo   repositories.CMSContentAuditRepositoryInterface
+	access *dto.PageAccess // Pages the user of the request may audit, nil for any
+}
+
+func NewCMSContentAuditService(repo repositories.CMSContentAuditRepositoryInterface) *CMSContentAuditService {
+	return &CMSContentAuditService{
+		repo: repo,
+	}
+}
+
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSContentAuditService) WithPageAccess(access *dto.PageAccess) CMSContentAuditServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+func (s *CMSContentAuditService) AuditContent(pageType string, contentId uuid.UUID) (*dto.ContentAuditReport, error) {
+	var (
+		findings []dto.ContentAuditFinding
+		title    string
+		language enums.PageLanguage
+		pageId   uuid.UUID
+	)
+
+	switch enums.PageType(strings.ToLower(pageType)) {
+	case enums.PageTypeLanding:
+		content, err := s.repo.FindLandingContentById(contentId)
+		if err != nil {
+			return nil, err
+		}
+		findings = helpers.AuditLandingContent(content)
+		title, language, pageId = content.Title, content.Language, content.PageID
+	case enums.PageTypePartner:
+		content, err := s.repo.FindPartnerContentById(contentId)
+		if err != nil {
+			return nil, err
+		}
+		findings = helpers.AuditPartnerContent(content)
+		title, language, pageId = content.Title, content.Language, content.PageID
+	case enums.PageTypeFaq:
+		content, err := s.repo.FindFaqContentById(contentId)
+		if err != nil {
+			return nil, err
+		}
+		findings = helpers.AuditFaqContent(content)
+		title, language, pageId = content.Title, content.Language, content.PageID
+	default:
+		return nil, errs.ErrInvalidPageType
+	}
+
+	normalizedPageType := enums.PageType(strings.ToLower(pageType))
+	if err := checkPageAccess(s.access, normalizedPageType, pageContentSelector{contentId: contentId}, func() ([]dto.PageContentScope, error) {
+		return s.repo.FindContentScopes(normalizedPageType, pageId)
+	}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
+	// Duplicate titles need the database so they are only checked here, not in the publish gate
+	if strings.TrimSpace(title) != "" {
+		count, err := s.repo.CountDuplicateTitles(normalizedPageType, title, language, pageId)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			findings = append(findings, dto.ContentAuditFinding{
+				Code:     "title_duplicate",
+				Severity: enums.AuditSeverityWarning,
+				Field:    "title",
+				Message:  "Another page already uses this title",
+			})
+		}
+	}
+
+	return &dto.ContentAuditReport{
+		ContentID:   contentId.String(),
+		PageType:    normalizedPageType,
+		Score:       helpers.ScoreAuditFindings(findings),
+		HasCritical: helpers.HasCriticalAuditFinding(findings),
+		Findings:    findings,
+	}, nil
+}
+
+// publishAuditEnabled reports whether saving a Published content runs the audit
+func publishAuditEnabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.Audit.BlockPublishOnCritical
+}
+
+// checkPublishAudit rejects a transition to Published when the audit finds critical issues, if enabled
+func checkPublishAudit(cfg *config.Config, status enums.WorkflowStatus, audit func() []dto.ContentAuditFinding) error {
+	if !publishAuditEnabled(cfg) || status != enums.WorkflowPublished {
+		return nil
+	}
+
+	if helpers.HasCriticalAuditFinding(audit()) {
+		return errs.ErrCriticalAuditFindings
+	}
+
+	return nil
+}
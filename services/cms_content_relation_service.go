@@ -0,0 +1,231 @@
+package services
+
+import (
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+)
+
+// A content can list at most this many related pages of each relation type
+const maxRelationsPerType = 20
+
+type CMSContentRelationServiceInterface interface {
+	GetRelations(pageType enums.PageType, contentId uuid.UUID) ([]models.ContentRelation, error)
+	ReplaceRelations(pageType enums.PageType, contentId uuid.UUID, request dto.ReplaceContentRelationsRequest) ([]models.ContentRelation, error)
+	GetIncomingRelations(pageType enums.PageType, pageId uuid.UUID) ([]dto.IncomingRelation, error)
+	ResolveRelations(contentId uuid.UUID, language enums.PageLanguage) ([]models.RelatedPage, error)
+	WithPageAccess(access *dto.PageAccess) CMSContentRelationServiceInterface
+}
+
+type CMSContentRelationService struct {
+	repo   repositories.CMSContentRelationRepositoryInterface
+	cfg    *config.Config
+	access *dto.PageAccess // Pages the user of the request may touch the relations of, nil for any
+}
+
+func NewCMSContentRelationService(repo repositories.CMSContentRelationRepositoryInterface, cfg *config.Config) *CMSContentRelationService {
+	return &CMSContentRelationService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSContentRelationService) WithPageAccess(access *dto.PageAccess) CMSContentRelationServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// checkAccess fails with ErrPageAccessDenied unless the page access allows the actions on the contents of the page the selector picks
+func (s *CMSContentRelationService) checkAccess(pageType enums.PageType, pageId uuid.UUID, selector pageContentSelector, actions ...enums.PermissionAction) error {
+	return checkPageAccess(s.access, pageType, selector, func() ([]dto.PageContentScope, error) {
+		return s.repo.FindContentScopes(pageType, pageId)
+	}, actions...)
+}
+
+func validRelationPageType(pageType enums.PageType) bool {
+	switch pageType {
+	case enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *CMSContentRelationService) GetRelations(pageType enums.PageType, contentId uuid.UUID) ([]models.ContentRelation, error) {
+	if !validRelationPageType(pageType) {
+		return nil, errs.ErrInvalidPageType
+	}
+	source, err := s.repo.FindRelationSource(pageType, contentId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccess(pageType, source.PageID, pageContentSelector{contentId: contentId}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindRelations(contentId)
+}
+
+// ReplaceRelations stores the related pages of a content, positions follow the request order within each relation type.
+// Only a content that is not live yet can change, later versions of it copy the relations.
+func (s *CMSContentRelationService) ReplaceRelations(pageType enums.PageType, contentId uuid.UUID, request dto.ReplaceContentRelationsRequest) ([]models.ContentRelation, error) {
+	if !validRelationPageType(pageType) {
+		return nil, errs.ErrInvalidPageType
+	}
+
+	source, err := s.repo.FindRelationSource(pageType, contentId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccess(pageType, source.PageID, pageContentSelector{contentId: contentId}, enums.PermissionActionUpdate); err != nil {
+		return nil, err
+	}
+	switch {
+	case source.Mode == enums.PageModeHistories, source.Mode == enums.PageModePreview,
+		source.WorkflowStatus == enums.WorkflowPublished, source.WorkflowStatus == enums.WorkflowSchedule:
+		return nil, errs.ErrRelationsLocked
+	}
+
+	type relationKey struct {
+		relationType enums.RelationType
+		pageType     enums.PageType
+		pageId       uuid.UUID
+	}
+	seen := map[relationKey]bool{}
+	positions := map[enums.RelationType]int{}
+
+	relations := make([]*models.ContentRelation, 0, len(request.Relations))
+	for _, item := range request.Relations {
+		switch item.RelationType {
+		case enums.RelationRelatedArticle, enums.RelationRelatedLink:
+		default:
+			return nil, errs.ErrInvalidRelation
+		}
+		if !validRelationPageType(item.TargetPageType) {
+			return nil, errs.ErrInvalidRelation
+		}
+		targetId, err := uuid.Parse(item.TargetPageID)
+		if err != nil {
+			return nil, errs.ErrInvalidRelation
+		}
+		if item.TargetPageType == pageType && targetId == source.PageID {
+			return nil, errs.ErrSelfRelation
+		}
+
+		key := relationKey{item.RelationType, item.TargetPageType, targetId}
+		if seen[key] {
+			return nil, errs.ErrDuplicateRelation
+		}
+		seen[key] = true
+
+		if positions[item.RelationType] >= maxRelationsPerType {
+			return nil, errs.ErrTooManyRelations
+		}
+		relations = append(relations, &models.ContentRelation{
+			SourcePageType:  pageType,
+			SourcePageID:    source.PageID,
+			SourceContentID: contentId,
+			Language:        source.Language,
+			RelationType:    item.RelationType,
+			TargetPageType:  item.TargetPageType,
+			TargetPageID:    targetId,
+			Position:        positions[item.RelationType],
+		})
+		positions[item.RelationType]++
+	}
+
+	// Targets only need to exist, they are resolved against their published content on delivery
+	for _, relation := range relations {
+		exists, err := s.repo.PageExists(relation.TargetPageType, relation.TargetPageID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errs.ErrRelationTargetNotFound
+		}
+	}
+
+	if err := s.repo.ReplaceRelations(contentId, relations); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindRelations(contentId)
+}
+
+// GetIncomingRelations lists the pages relating to the page, so editors know what changes when it is unpublished
+func (s *CMSContentRelationService) GetIncomingRelations(pageType enums.PageType, pageId uuid.UUID) ([]dto.IncomingRelation, error) {
+	if !validRelationPageType(pageType) {
+		return nil, errs.ErrInvalidPageType
+	}
+	if err := s.checkAccess(pageType, pageId, pageContentSelector{}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindIncomingRelations(pageType, pageId)
+}
+
+// ResolveRelations returns the related pages of a content in its language with the current title, url and thumbnail
+// of their published content, relations whose target has no published content in the language are dropped
+func (s *CMSContentRelationService) ResolveRelations(contentId uuid.UUID, language enums.PageLanguage) ([]models.RelatedPage, error) {
+	relations, err := s.repo.FindRelations(contentId)
+	if err != nil {
+		return nil, err
+	}
+	if len(relations) == 0 {
+		return nil, nil
+	}
+
+	targetIds := map[enums.PageType][]uuid.UUID{}
+	for _, relation := range relations {
+		targetIds[relation.TargetPageType] = append(targetIds[relation.TargetPageType], relation.TargetPageID)
+	}
+
+	type targetKey struct {
+		pageType enums.PageType
+		pageId   uuid.UUID
+	}
+	targets := map[targetKey]dto.RelationTarget{}
+	for targetType, ids := range targetIds {
+		published, err := s.repo.FindPublishedTargets(targetType, language, ids)
+		if err != nil {
+			return nil, err
+		}
+		// Newest first, so the first content of a page wins
+		for _, target := range published {
+			key := targetKey{targetType, target.PageID}
+			if _, ok := targets[key]; !ok {
+				targets[key] = target
+			}
+		}
+	}
+
+	related := []models.RelatedPage{}
+	for _, relation := range relations {
+		target, ok := targets[targetKey{relation.TargetPageType, relation.TargetPageID}]
+		if !ok {
+			continue
+		}
+		url, err := helpers.BuildContentURL(s.cfg.App.WebBaseURL, string(language), target.Path)
+		if err != nil {
+			return nil, err
+		}
+		related = append(related, models.RelatedPage{
+			RelationType: relation.RelationType,
+			PageType:     relation.TargetPageType,
+			PageID:       relation.TargetPageID,
+			Title:        target.Title,
+			URL:          url,
+			Thumbnail:    target.Thumbnail,
+		})
+	}
+
+	return related, nil
+}
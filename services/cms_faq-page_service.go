@@ -2,7 +2,6 @@ package services
 
 import (
 	"encoding/json"
-	"strings"
 	"time"
 
 	"github.com/MadManJJ/cms-api/config"
@@ -20,9 +19,10 @@ import (
 type CMSFaqPageServiceInterface interface {
 	CreateFaqPage(faqPage *models.FaqPage) (*models.FaqPage, error)
 	FindFaqPages(rawQuery string, sort string, page, limit int, language string) ([]models.FaqPage, int64, error)
+	FindFaqPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.FaqPage, *dto.CursorPage, error)
 	FindFaqPageById(id uuid.UUID) (*models.FaqPage, error)
 	UpdateFaqContent(updatedFaqContent *models.FaqContent, prevContentId uuid.UUID) (*models.FaqContent, error)
-	DeleteFaqPage(id uuid.UUID) error
+	DeleteFaqPage(id uuid.UUID, force bool) error
 	FindContentByFaqPageId(pageId uuid.UUID, language string, mode string) (*models.FaqContent, error)
 	FindLatestContentByPageId(pageId uuid.UUID, language string) (*models.FaqContent, error)
 	DeleteContentByFaqPageId(pageId uuid.UUID, language, mode string) error
@@ -31,12 +31,14 @@ type CMSFaqPageServiceInterface interface {
 	RevertFaqContent(revisionId uuid.UUID, newRevision *models.Revision) (*models.FaqContent, error)
 	FindCategories(pageId uuid.UUID, categoryTypeCode, language, mode string) ([]models.Category, error)
 	FindRevisions(pageId uuid.UUID, language string) ([]models.Revision, error)
-	PreviewFaqContent(pageId uuid.UUID, faqContentPreview *models.FaqContent) (string, error)
+	PreviewFaqContent(pageId uuid.UUID, faqContentPreview *models.FaqContent) (*models.FaqContent, error)
+	WithPageAccess(access *dto.PageAccess) CMSFaqPageServiceInterface
 }
 
 type CMSFaqPageService struct {
 	repo repositories.CMSFaqPageRepositoryInterface
 	cfg *config.Config
+	access *dto.PageAccess // Pages the user of the request may touch, nil for any
 }
 
 func NewCMSFaqPageService(repo repositories.CMSFaqPageRepositoryInterface, cfg *config.Config) *CMSFaqPageService {
@@ -46,6 +48,36 @@ func NewCMSFaqPageService(repo repositories.CMSFaqPageRepositoryInterface, cfg *
 	}
 }
 
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSFaqPageService) WithPageAccess(access *dto.PageAccess) CMSFaqPageServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// checkAccess fails with ErrPageAccessDenied unless the page access allows the actions on the contents of the page the selector picks
+func (s *CMSFaqPageService) checkAccess(pageId uuid.UUID, selector pageContentSelector, actions ...enums.PermissionAction) error {
+	return checkPageAccess(s.access, enums.PageTypeFaq, selector, func() ([]dto.PageContentScope, error) {
+		return s.repo.FindContentScopes(pageId)
+	}, actions...)
+}
+
+// checkCopyPublishAudit runs the publish gate on a content that revert or duplicate copies along with its workflow status
+func (s *CMSFaqPageService) checkCopyPublishAudit(contentId uuid.UUID) error {
+	if !publishAuditEnabled(s.cfg) {
+		return nil
+	}
+
+	content, err := s.repo.FindContentById(contentId)
+	if err != nil {
+		return err
+	}
+
+	return checkPublishAudit(s.cfg, content.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditFaqContent(content)
+	})
+}
+
 // Always send only 1 content
 func (s *CMSFaqPageService) CreateFaqPage(faqPage *models.FaqPage) (*models.FaqPage, error) {
 	faqContents := faqPage.Contents
@@ -57,6 +89,11 @@ func (s *CMSFaqPageService) CreateFaqPage(faqPage *models.FaqPage) (*models.FaqP
 	// Only one content
 	faqContent := faqContents[0]
 
+	if err := checkNewContentAccess(s.access, enums.PageTypeFaq, faqContent.Language, faqContent.Categories,
+		pageSaveActions(enums.PermissionActionCreate, faqContent.WorkflowStatus)...); err != nil {
+		return nil, err
+	}
+
 	// Check if the URL is duplicate or not
 	isUrlDuplicate, err := s.repo.IsUrlDuplicate(faqContent.URL, uuid.Nil)
 	if err != nil {
@@ -87,6 +124,12 @@ func (s *CMSFaqPageService) CreateFaqPage(faqPage *models.FaqPage) (*models.FaqP
 		return nil, err
 	}
 
+	if err := checkPublishAudit(s.cfg, faqContent.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditFaqContent(faqContent)
+	}); err != nil {
+		return nil, err
+	}
+
 	return s.repo.CreateFaqPage(faqPage)
 }
 
@@ -97,11 +140,28 @@ func (s *CMSFaqPageService) FindFaqPages(rawQuery string, sort string, page, lim
 			return nil, 0, errs.ErrInvalidQuery
 		}
 	}
+	query.Access = s.access
 
 	return s.repo.FindAllFaqPage(query, sort, page, limit, language)
 }
 
+func (s *CMSFaqPageService) FindFaqPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.FaqPage, *dto.CursorPage, error) {
+	var query dto.FaqPageQuery
+	if rawQuery != "" {
+		if err := json.Unmarshal([]byte(rawQuery), &query); err != nil {
+			return nil, nil, errs.ErrInvalidQuery
+		}
+	}
+	query.Access = s.access
+
+	return s.repo.FindFaqPagesByCursor(query, sort, cursorQuery, language)
+}
+
 func (s *CMSFaqPageService) FindFaqPageById(id uuid.UUID) (*models.FaqPage, error) {
+	if err := s.checkAccess(id, pageContentSelector{}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return s.repo.FindFaqPageById(id)
 }
 
@@ -111,6 +171,16 @@ func (s *CMSFaqPageService) UpdateFaqContent(updatedFaqContent *models.FaqConten
 	if err != nil {
 		return nil, err
 	}
+
+	// The saved content has to stay within the pages of the user too
+	actions := pageSaveActions(enums.PermissionActionUpdate, updatedFaqContent.WorkflowStatus)
+	if err := s.checkAccess(faqPageId, pageContentSelector{contentId: prevContentId}, actions...); err != nil {
+		return nil, err
+	}
+	if err := checkNewContentAccess(s.access, enums.PageTypeFaq, updatedFaqContent.Language, updatedFaqContent.Categories, actions...); err != nil {
+		return nil, err
+	}
+
 	isDuplicate, err := s.repo.IsUrlDuplicate(updatedFaqContent.URL, faqPageId)
 	if err != nil {
 		return nil, err
@@ -139,11 +209,23 @@ func (s *CMSFaqPageService) UpdateFaqContent(updatedFaqContent *models.FaqConten
 	if updatedFaqContent.Revision == nil {
 		return nil, errs.ErrNoRevisionFound
 	}
+
+	if err := checkPublishAudit(s.cfg, updatedFaqContent.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditFaqContent(updatedFaqContent)
+	}); err != nil {
+		return nil, err
+	}
+
 	return s.repo.UpdateFaqContent(updatedFaqContent, prevContentId)
 }
 
-func (s *CMSFaqPageService) DeleteFaqPage(id uuid.UUID) error {
-	return s.repo.DeleteFaqPage(id)
+// DeleteFaqPage deletes the page, unless forced it fails with ErrItemInUse while published content refers to it
+func (s *CMSFaqPageService) DeleteFaqPage(id uuid.UUID, force bool) error {
+	if err := s.checkAccess(id, pageContentSelector{every: true}, enums.PermissionActionDelete); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteFaqPage(id, force)
 }
 
 func (s *CMSFaqPageService) FindContentByFaqPageId(pageId uuid.UUID, language string, mode string) (*models.FaqContent, error) {
@@ -157,6 +239,10 @@ func (s *CMSFaqPageService) FindContentByFaqPageId(pageId uuid.UUID, language st
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return s.repo.FindContentByFaqPageId(pageId, language, mode)
 }
 
@@ -166,6 +252,10 @@ func (r *CMSFaqPageService) FindLatestContentByPageId(pageId uuid.UUID, language
 		return nil, err
 	}
 
+	if err := r.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return r.repo.FindLatestContentByPageId(pageId, language)
 }
 
@@ -180,10 +270,19 @@ func (s *CMSFaqPageService) DeleteContentByFaqPageId(pageId uuid.UUID, language,
 		return err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language), every: true}, enums.PermissionActionDelete); err != nil {
+		return err
+	}
+
 	return s.repo.DeleteFaqContent(pageId, language, mode)
 }
 
 func (s *CMSFaqPageService) DuplicateFaqPage(pageId uuid.UUID) (*models.FaqPage, error) {
+	// The copy keeps the languages and categories of the page
+	if err := s.checkAccess(pageId, pageContentSelector{every: true}, enums.PermissionActionRead, enums.PermissionActionCreate); err != nil {
+		return nil, err
+	}
+
 	faqPage, err := s.repo.DuplicateFaqPage(pageId)
 
 	if err != nil {
@@ -200,10 +299,39 @@ func (s *CMSFaqPageService) DuplicateFaqContentToAnotherLanguage(contentId uuid.
 		return nil, err
 	}	
 
+	if !s.access.AllowsAll(enums.PageTypeFaq, enums.PermissionActionRead) || !s.access.AllowsAll(enums.PageTypeFaq, enums.PermissionActionCreate) {
+		pageId, err := s.repo.GetPageIdByContentId(contentId)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTranslationAccess(s.access, enums.PageTypeFaq, contentId, func() ([]dto.PageContentScope, error) {
+			return s.repo.FindContentScopes(pageId)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkCopyPublishAudit(contentId); err != nil {
+		return nil, err
+	}
+
 	return s.repo.DuplicateFaqContentToAnotherLanguage(contentId, newRevision)
 }
 
 func (s *CMSFaqPageService) RevertFaqContent(revisionId uuid.UUID, newRevision *models.Revision) (*models.FaqContent, error) {
+	if !s.access.AllowsAll(enums.PageTypeFaq, enums.PermissionActionUpdate) || publishAuditEnabled(s.cfg) {
+		pageId, contentId, err := s.repo.GetContentRefByRevisionId(revisionId)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkAccess(pageId, pageContentSelector{contentId: contentId}, enums.PermissionActionUpdate); err != nil {
+			return nil, err
+		}
+		if err := s.checkCopyPublishAudit(contentId); err != nil {
+			return nil, err
+		}
+	}
+
 	err := helpers.NormalizeRevision(newRevision)
 
 	if err != nil {
@@ -223,6 +351,10 @@ func (s *CMSFaqPageService) FindCategories(pageId uuid.UUID, categoryTypeCode, l
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	categories, err := s.repo.GetCategory(pageId, categoryTypeCode, language, mode)
 	if err != nil {
 		return nil, err
@@ -237,6 +369,10 @@ func (s *CMSFaqPageService) FindRevisions(pageId uuid.UUID, language string) ([]
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	revisions, err := s.repo.GetRevisionByFaqPageId(pageId, language)
 	if err != nil {
 		return nil, err
@@ -245,38 +381,38 @@ func (s *CMSFaqPageService) FindRevisions(pageId uuid.UUID, language string) ([]
 	return revisions, nil
 }
 
-func (s *CMSFaqPageService) PreviewFaqContent(pageId uuid.UUID, faqContentPreview *models.FaqContent) (string, error) {
-	urls := s.cfg.App.FrontendURLS // "http://localhost:8000,http://localhost:3000"
-	parts := strings.Split(urls, ",")
-	appUrl := parts[1] // "http://localhost:3000"
+func (s *CMSFaqPageService) PreviewFaqContent(pageId uuid.UUID, faqContentPreview *models.FaqContent) (*models.FaqContent, error) {
+	if err := s.checkAccess(pageId, pageContentSelector{language: faqContentPreview.Language}, enums.PermissionActionUpdate); err != nil {
+		return nil, err
+	}
 
-	// Default value for preview content
+	// Default value for preview content, kept as long as the longest preview link can live
 	faqContentPreview.Mode = enums.PageModePreview
 	faqContentPreview.PublishStatus = enums.PublishStatusNotPublished
 	faqContentPreview.WorkflowStatus = enums.WorkflowUnPublished	
-	faqContentPreview.ExpiredAt = time.Now().Add(2 * time.Hour)	
+	faqContentPreview.ExpiredAt = time.Now().Add(s.cfg.Preview.MaxTTL)
 
 	if err := helpers.NormalizeFaqContent(faqContentPreview); err != nil {
-		return "", err
+		return nil, err
 	}
 	
 	// Check if the URL is duplicate or not
 	isUrlDuplicate, err := s.repo.IsUrlDuplicate(faqContentPreview.URL, pageId)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if isUrlDuplicate {
-		return "", errs.ErrDuplicateURL
+		return nil, errs.ErrDuplicateURL
 	}
 
 	// Check if the URL Alias is duplicate or not
 	if faqContentPreview.URLAlias != "" {
 		isUrlAliasDuplicate, err := s.repo.IsUrlAliasDuplicate(faqContentPreview.URLAlias, pageId)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if isUrlAliasDuplicate {
-			return "", errs.ErrDuplicateUrlAlias
+			return nil, errs.ErrDuplicateUrlAlias
 		}
 	}	
 
@@ -284,49 +420,17 @@ func (s *CMSFaqPageService) PreviewFaqContent(pageId uuid.UUID, faqContentPrevie
 
 	// Internal error
 	if err != nil && err != gorm.ErrRecordNotFound {
-		return "", err
+		return nil, err
 	}
 
 	// Record not found, so we create a new one
 	if err == gorm.ErrRecordNotFound {
 		faqContentPreview.PageID = pageId
-		createdFaqContentPreview, err := s.repo.CreateFaqContentPreview(faqContentPreview)
-		if err != nil {
-			return "", err
-		}
-
-		previewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(createdFaqContentPreview.Language),
-			"faq",
-			createdFaqContentPreview.ID,
-		)	
-
-		if err != nil {
-			return "", err
-		}		
-
-		return previewUrl, nil
+		return s.repo.CreateFaqContentPreview(faqContentPreview)
 	}
 
 	// Attach the old id, so we can save the new one in its place
 	faqContentPreview.ID = existingPreviewContent.ID
 	faqContentPreview.PageID = existingPreviewContent.PageID
-	updatedFaqContentPreview, err := s.repo.UpdateFaqContentPreview(faqContentPreview)
-	if err != nil {
-		return "", err
-	}
-
-	previewUrl, err := helpers.BuildPreviewURL(
-		appUrl,
-		string(updatedFaqContentPreview.Language),
-		"faq",
-		updatedFaqContentPreview.ID,
-	)	
-
-	if err != nil {
-		return "", err
-	}		
-
-	return previewUrl, nil
+	return s.repo.UpdateFaqContentPreview(faqContentPreview)
 }
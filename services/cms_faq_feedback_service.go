@@ -0,0 +1,130 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+)
+
+// Widest range the analytics endpoints aggregate over
+const feedbackMaxRange = 366 * 24 * time.Hour
+
+type CMSFaqFeedbackServiceInterface interface {
+	RecordFeedback(contentId uuid.UUID, request dto.FaqFeedbackRequest, clientIP, userAgent string) error
+	GetHelpfulnessSeries(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error)
+	GetLowestRated(query dto.FaqFeedbackQuery, minVotes, limit int) ([]dto.FaqHelpfulnessRank, error)
+	GetFeedbackComments(query dto.FaqFeedbackQuery, page, limit int) ([]dto.FaqFeedbackComment, int64, error)
+}
+
+type CMSFaqFeedbackService struct {
+	repo repositories.CMSFaqFeedbackRepositoryInterface
+	cfg  *config.Config
+}
+
+func NewCMSFaqFeedbackService(repo repositories.CMSFaqFeedbackRepositoryInterface, cfg *config.Config) *CMSFaqFeedbackService {
+	return &CMSFaqFeedbackService{repo: repo, cfg: cfg}
+}
+
+// RecordFeedback stores the client's vote on the page language of a published faq content, replacing an earlier one
+func (s *CMSFaqFeedbackService) RecordFeedback(contentId uuid.UUID, request dto.FaqFeedbackRequest, clientIP, userAgent string) error {
+	if request.Helpful == nil {
+		return errs.ErrInvalidFeedback
+	}
+
+	// Stored as written, every output escapes it for its own format
+	feedback := strings.TrimSpace(strings.ReplaceAll(request.Feedback, "\x00", ""))
+	if utf8.RuneCountInString(feedback) > s.cfg.Feedback.MaxLength {
+		return errs.ErrFeedbackTooLong
+	}
+
+	faqContent, err := s.repo.FindPublishedFaqContent(contentId)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.UpsertFeedback(&models.FaqFeedback{
+		PageID:       faqContent.PageID,
+		Language:     faqContent.Language,
+		ClientHash:   s.clientHash(clientIP, userAgent),
+		FaqContentID: faqContent.ID,
+		Helpful:      *request.Helpful,
+		Feedback:     feedback,
+	})
+}
+
+// clientHash identifies a client for deduplication without storing its ip, keyed so it cannot be looked up
+func (s *CMSFaqFeedbackService) clientHash(clientIP, userAgent string) string {
+	return helpers.HashVisitor(s.cfg.SecretKey.HashSalt, clientIP, userAgent)
+}
+
+// GetHelpfulnessSeries returns the votes and helpful ratio per day, week or month
+func (s *CMSFaqFeedbackService) GetHelpfulnessSeries(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error) {
+	if err := normalizeFeedbackQuery(&query); err != nil {
+		return nil, err
+	}
+
+	switch interval = strings.ToLower(strings.TrimSpace(interval)); interval {
+	case "":
+		interval = dto.FeedbackIntervalDay
+	case dto.FeedbackIntervalDay, dto.FeedbackIntervalWeek, dto.FeedbackIntervalMonth:
+	default:
+		return nil, errs.ErrInvalidInterval
+	}
+
+	points, err := s.repo.FindHelpfulnessSeries(query, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range points {
+		if total := points[i].Helpful + points[i].NotHelpful; total > 0 {
+			points[i].Ratio = float64(points[i].Helpful) / float64(total)
+		}
+	}
+
+	return points, nil
+}
+
+// GetLowestRated returns the faq page languages with the lowest helpful ratio among those with enough votes
+func (s *CMSFaqFeedbackService) GetLowestRated(query dto.FaqFeedbackQuery, minVotes, limit int) ([]dto.FaqHelpfulnessRank, error) {
+	if err := normalizeFeedbackQuery(&query); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindLowestRated(query, minVotes, limit)
+}
+
+// GetFeedbackComments returns one page of the free-text feedback, newest first
+func (s *CMSFaqFeedbackService) GetFeedbackComments(query dto.FaqFeedbackQuery, page, limit int) ([]dto.FaqFeedbackComment, int64, error) {
+	if err := normalizeFeedbackQuery(&query); err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.FindFeedbackComments(query, page, limit)
+}
+
+func normalizeFeedbackQuery(query *dto.FaqFeedbackQuery) error {
+	if query.From.IsZero() || query.To.IsZero() || query.To.Before(query.From) || query.To.Sub(query.From) > feedbackMaxRange {
+		return errs.ErrInvalidDateRange
+	}
+
+	if query.Language != "" {
+		language, err := helpers.NormalizeLanguage(string(query.Language))
+		if err != nil {
+			return err
+		}
+		query.Language = enums.PageLanguage(language)
+	}
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"strings"
 	"time"
@@ -10,11 +14,13 @@ import (
 	"github.com/MadManJJ/cms-api/repositories"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CMSFormSubmissionServiceInterface interface {
 	CreateFormSubmission(formId uuid.UUID, formSubmission *models.FormSubmission) (*models.FormSubmission, error)
 	GetFormSubmissions(formId uuid.UUID, sort string, page, limit int) ([]*models.FormSubmission, int64, error)
+	GetFormSubmissionsByCursor(formId uuid.UUID, sort string, cursorQuery dto.CursorQuery) ([]*models.FormSubmission, *dto.CursorPage, error)
 	GetFormSubmission(submissionId uuid.UUID) (*models.FormSubmission, error)
 }
 
@@ -23,10 +29,11 @@ type CMSFormSubmissionService struct {
 	emailSendingService EmailSendingServiceInterface
 }
 
-func NewCMSFormSubmissionService(repo repositories.FormSubmissionRepositoryInterface, emailSendingService EmailSendingServiceInterface) CMSFormSubmissionServiceInterface {
+func NewCMSFormSubmissionService(repo repositories.FormSubmissionRepositoryInterface, emailSendingService EmailSendingServiceInterface) *CMSFormSubmissionService {
 	return &CMSFormSubmissionService{repo: repo, emailSendingService: emailSendingService}
 }
 
+// CreateFormSubmission saves the submission, its emails are sent when the form_submission.created event is handled
 func (s *CMSFormSubmissionService) CreateFormSubmission(formId uuid.UUID, formSubmission *models.FormSubmission) (*models.FormSubmission, error) {
 
 	formSubmission.FormID = formId
@@ -38,16 +45,36 @@ func (s *CMSFormSubmissionService) CreateFormSubmission(formId uuid.UUID, formSu
 	}
 	log.Printf("INFO: Submission %s created successfully.", createdFormSubmission.ID)
 
+	return createdFormSubmission, nil
+}
+
+// HandleDomainEvent sends the emails of a new submission. Each email is keyed on the event and its email content,
+// so handling the event again does not send it twice.
+func (s *CMSFormSubmissionService) HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error {
+	var data dto.FormSubmissionEventData
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", event.EventType, err)
+	}
+
+	createdFormSubmission, err := s.repo.GetFormSubmission(data.SubmissionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	formId := createdFormSubmission.FormID
+
 	if createdFormSubmission.Form == nil || createdFormSubmission.Form.EmailCategoryID == nil {
 		log.Printf("INFO: No email will be sent for submission %s because the form is not linked to an EmailCategory.", createdFormSubmission.ID)
-		return createdFormSubmission, nil
+		return nil
 	}
 	log.Printf("DEBUG: Form %s is linked to EmailCategory %s. Proceeding to send emails.", createdFormSubmission.Form.ID, createdFormSubmission.Form.EmailCategoryID)
 
 	emailContents, err := s.repo.GetEmailContentsFormFormId(formId)
 	if err != nil {
-		log.Printf("WARNING: Could not get email contents for form %s. Emails will not be sent. Error: %v", formId, err)
-		return createdFormSubmission, nil
+		log.Printf("WARNING: Could not get email contents for form %s. Error: %v", formId, err)
+		return err
 	}
 
 	for _, emailContent := range emailContents {
@@ -108,26 +135,31 @@ func (s *CMSFormSubmissionService) CreateFormSubmission(formId uuid.UUID, formSu
 			Language:               emailContent.Language,
 			ToRecipientEmails:      recipients,
 			Data:                   data,
+			IdempotencyKey:         event.ID.String() + ":" + emailContent.ID.String(),
 		}
 
 		log.Printf("INFO: Queuing email for label '%s' to recipients: %v", req.EmailContentLabel, req.ToRecipientEmails)
-		go func(req dto.SendEmailRequest, isUserMail bool) {
-			if err := s.emailSendingService.SendEmail(req); err != nil {
-				if isUserMail {
-					log.Printf("ERROR: Failed to send user confirmation email. Label: '%s'. Error: %v", req.EmailContentLabel, err)
-				} else {
-					log.Printf("ERROR: Failed to send admin notification email. Label: '%s'. Error: %v", req.EmailContentLabel, err)
-				}
+		if err := s.emailSendingService.SendEmail(req); err != nil {
+			if isUserEmail {
+				log.Printf("ERROR: Failed to send user confirmation email. Label: '%s'. Error: %v", req.EmailContentLabel, err)
+			} else {
+				log.Printf("ERROR: Failed to send admin notification email. Label: '%s'. Error: %v", req.EmailContentLabel, err)
 			}
-		}(req, isUserEmail)
+			return err
+		}
 	}
 
-	return createdFormSubmission, nil
+	return nil
 }
+
 func (s *CMSFormSubmissionService) GetFormSubmissions(formId uuid.UUID, sort string, page, limit int) ([]*models.FormSubmission, int64, error) {
 	return s.repo.GetFormSubmissions(formId, sort, page, limit)
 }
 
+func (s *CMSFormSubmissionService) GetFormSubmissionsByCursor(formId uuid.UUID, sort string, cursorQuery dto.CursorQuery) ([]*models.FormSubmission, *dto.CursorPage, error) {
+	return s.repo.GetFormSubmissionsByCursor(formId, sort, cursorQuery)
+}
+
 func (s *CMSFormSubmissionService) GetFormSubmission(submissionId uuid.UUID) (*models.FormSubmission, error) {
 	return s.repo.GetFormSubmission(submissionId)
 }
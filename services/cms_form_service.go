@@ -24,6 +24,7 @@ type CMSFormServiceInterface interface {
 	GetFormDetails(formID uuid.UUID) (*dto.FormResponse, error)
 	GetFormStructure(formID uuid.UUID) (*dto.FormResponse, error)
 	GetAllForms(filter dto.FormListFilter) (*dto.PaginatedFormListResponse, error)
+	GetFormsByCursor(filter dto.FormListFilter, cursorQuery dto.CursorQuery) ([]dto.FormListItemResponse, *dto.CursorPage, error)
 	UpdateExistingForm(formID uuid.UUID, req dto.UpdateFormRequest) (*dto.FormResponse, error)
 	DeleteExistingForm(formID uuid.UUID) error
 }
@@ -187,6 +188,19 @@ func (s *cmsFormService) GetAllForms(filter dto.FormListFilter) (*dto.PaginatedF
 	}, nil
 }
 
+func (s *cmsFormService) GetFormsByCursor(filter dto.FormListFilter, cursorQuery dto.CursorQuery) ([]dto.FormListItemResponse, *dto.CursorPage, error) {
+	if err := s.validate.Struct(filter); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid list filter parameters: %v", errs.ErrBadRequest, err)
+	}
+
+	forms, page, err := s.formRepo.ListFormsByCursor(filter, cursorQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service: failed to get forms list from repository: %w", err)
+	}
+
+	return s.mapFormModelsToListItemResponses(forms), page, nil
+}
+
 func (s *cmsFormService) UpdateExistingForm(formID uuid.UUID, req dto.UpdateFormRequest) (*dto.FormResponse, error) {
 	// 1. Validate field key uniqueness in the request DTO
 	fieldKeysInRequest := make(map[string]bool)
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -24,9 +25,10 @@ import (
 type CMSLandingPageServiceInterface interface {
 	CreateLandingPage(LandingPage *models.LandingPage) (*models.LandingPage, error)
 	FindLandingPages(rawQuery string, sort string, page, limit int, language string) ([]models.LandingPage, int64, error)
+	FindLandingPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.LandingPage, *dto.CursorPage, error)
 	FindLandingPageById(id uuid.UUID) (*models.LandingPage, error)
 	UpdateLandingContent(updatedLandingContent *models.LandingContent, prevContentId uuid.UUID) (*models.LandingContent, error)
-	DeleteLandingPage(id uuid.UUID) error
+	DeleteLandingPage(id uuid.UUID, force bool) error
 	FindContentByLandingPageId(pageId uuid.UUID, language string, mode string) (*models.LandingContent, error)
 	FindLatestContentByPageId(pageId uuid.UUID, language string) (*models.LandingContent, error)
 	DeleteContentByLandingPageId(pageId uuid.UUID, language, mode string) error
@@ -35,7 +37,8 @@ type CMSLandingPageServiceInterface interface {
 	RevertLandingContent(revisionId uuid.UUID, newRevision *models.Revision) (*models.LandingContent, error)
 	GetCategory(pageId uuid.UUID, categoryTypeCode, language, mode string) ([]models.Category, error)
 	FindRevisions(pageId uuid.UUID, language string) ([]models.Revision, error)
-	PreviewLandingContent(pageId uuid.UUID, landingContentPreview *models.LandingContent) (string, error)
+	PreviewLandingContent(pageId uuid.UUID, landingContentPreview *models.LandingContent) (*models.LandingContent, error)
+	WithPageAccess(access *dto.PageAccess) CMSLandingPageServiceInterface
 }
 
 type CMSLandingPageService struct {
@@ -44,6 +47,7 @@ type CMSLandingPageService struct {
 	emailContentRepo    repositories.EmailContentRepositoryInterface
 	emailCategoryRepo   repositories.EmailCategoryRepositoryInterface
 	cfg                 *config.Config
+	access              *dto.PageAccess // Pages the user of the request may touch, nil for any
 }
 
 func NewCMSLandingPageService(
@@ -62,6 +66,36 @@ func NewCMSLandingPageService(
 	}
 }
 
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSLandingPageService) WithPageAccess(access *dto.PageAccess) CMSLandingPageServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// checkAccess fails with ErrPageAccessDenied unless the page access allows the actions on the contents of the page the selector picks
+func (s *CMSLandingPageService) checkAccess(pageId uuid.UUID, selector pageContentSelector, actions ...enums.PermissionAction) error {
+	return checkPageAccess(s.access, enums.PageTypeLanding, selector, func() ([]dto.PageContentScope, error) {
+		return s.repo.FindContentScopes(pageId)
+	}, actions...)
+}
+
+// checkCopyPublishAudit runs the publish gate on a content that revert or duplicate copies along with its workflow status
+func (s *CMSLandingPageService) checkCopyPublishAudit(contentId uuid.UUID) error {
+	if !publishAuditEnabled(s.cfg) {
+		return nil
+	}
+
+	content, err := s.repo.FindContentById(contentId)
+	if err != nil {
+		return err
+	}
+
+	return checkPublishAudit(s.cfg, content.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditLandingContent(content)
+	})
+}
+
 // Always send only 1 content
 func (s *CMSLandingPageService) CreateLandingPage(LandingPage *models.LandingPage) (*models.LandingPage, error) {
 	LandingContents := LandingPage.Contents
@@ -73,6 +107,11 @@ func (s *CMSLandingPageService) CreateLandingPage(LandingPage *models.LandingPag
 	// Only one content
 	LandingContent := LandingContents[0]
 
+	if err := checkNewContentAccess(s.access, enums.PageTypeLanding, LandingContent.Language, LandingContent.Categories,
+		pageSaveActions(enums.PermissionActionCreate, LandingContent.WorkflowStatus)...); err != nil {
+		return nil, err
+	}
+
 	// Always need to have a revision
 	if LandingContent.Revision == nil {
 		return nil, errs.ErrNoRevisionFound
@@ -97,6 +136,12 @@ func (s *CMSLandingPageService) CreateLandingPage(LandingPage *models.LandingPag
 		return nil, err
 	}
 
+	if err := checkPublishAudit(s.cfg, LandingContent.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditLandingContent(LandingContent)
+	}); err != nil {
+		return nil, err
+	}
+
 	// Normalize categories if present
 	// for _, category := range LandingContent.Categories {
 	// 	if err := helpers.NormalizeCategory(category); err != nil {
@@ -114,11 +159,28 @@ func (s *CMSLandingPageService) FindLandingPages(rawQuery string, sort string, p
 			return nil, 0, errs.ErrInvalidQuery
 		}
 	}
+	query.Access = s.access
 
 	return s.repo.FindAllLandingPage(query, sort, page, limit, language)
 }
 
+func (s *CMSLandingPageService) FindLandingPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.LandingPage, *dto.CursorPage, error) {
+	var query dto.LandingPageQuery
+	if rawQuery != "" {
+		if err := json.Unmarshal([]byte(rawQuery), &query); err != nil {
+			return nil, nil, errs.ErrInvalidQuery
+		}
+	}
+	query.Access = s.access
+
+	return s.repo.FindLandingPagesByCursor(query, sort, cursorQuery, language)
+}
+
 func (s *CMSLandingPageService) FindLandingPageById(id uuid.UUID) (*models.LandingPage, error) {
+	if err := s.checkAccess(id, pageContentSelector{}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return s.repo.FindLandingPageById(id)
 }
 
@@ -129,6 +191,15 @@ func (s *CMSLandingPageService) UpdateLandingContent(updatedLandingContent *mode
 		return nil, err
 	}
 
+	// The saved content has to stay within the pages of the user too
+	actions := pageSaveActions(enums.PermissionActionUpdate, updatedLandingContent.WorkflowStatus)
+	if err := s.checkAccess(landingContentId, pageContentSelector{contentId: prevContentId}, actions...); err != nil {
+		return nil, err
+	}
+	if err := checkNewContentAccess(s.access, enums.PageTypeLanding, updatedLandingContent.Language, updatedLandingContent.Categories, actions...); err != nil {
+		return nil, err
+	}
+
 	isUrlAliasDuplicate, err := s.repo.IsUrlAliasDuplicate(updatedLandingContent.UrlAlias, landingContentId)
 	if err != nil {
 		return nil, err
@@ -142,6 +213,12 @@ func (s *CMSLandingPageService) UpdateLandingContent(updatedLandingContent *mode
 		return nil, errs.ErrNoRevisionFound
 	}
 
+	if err := checkPublishAudit(s.cfg, updatedLandingContent.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditLandingContent(updatedLandingContent)
+	}); err != nil {
+		return nil, err
+	}
+
 	log.Printf("[SERVICE-IN] Language from Request: '%s'", updatedLandingContent.Language)
 
 	savedContent, err := s.repo.UpdateLandingContent(updatedLandingContent, prevContentId)
@@ -149,29 +226,46 @@ func (s *CMSLandingPageService) UpdateLandingContent(updatedLandingContent *mode
 		return nil, err
 	}
 
-	// Email sending logic
-	if savedContent.WorkflowStatus == enums.WorkflowWaitingDesign && len(savedContent.ApprovalEmail) > 0 {
-
-		go s.triggerApprovalNotifications(savedContent)
-	}
-
 	log.Printf("[SERVICE-OUT] Language from Repo: '%s'", savedContent.Language)
 
 	return savedContent, nil
 }
 
+// HandleDomainEvent sends the approval notifications of a content saved as waiting for design
+func (s *CMSLandingPageService) HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error {
+	var data dto.ContentEventData
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", event.EventType, err)
+	}
+	if data.PageType != enums.PageTypeLanding || data.ContentID == nil ||
+		data.WorkflowStatus != enums.WorkflowWaitingDesign || len(data.ApprovalEmail) == 0 {
+		return nil
+	}
+
+	content := &models.LandingContent{
+		ID:            *data.ContentID,
+		PageID:        data.PageID,
+		Language:      data.Language,
+		Title:         data.Title,
+		ApprovalEmail: data.ApprovalEmail,
+		Revision:      &models.Revision{Author: data.Author},
+	}
+	return s.triggerApprovalNotifications(event.ID.String(), content)
+}
+
 // triggerApprovalNotifications sends approval notifications to the relevant recipients.
-func (s *CMSLandingPageService) triggerApprovalNotifications(content *models.LandingContent) {
+// Each email is keyed on the event and its template, so handling the event again does not send it twice.
+func (s *CMSLandingPageService) triggerApprovalNotifications(eventId string, content *models.LandingContent) error {
 	// --- KEY CHANGE IS HERE ---
 	const approvalCategoryTitle = "Approve"
 	category, err := s.emailCategoryRepo.FindByTitle(approvalCategoryTitle)
 	if err != nil {
 		log.Printf("CRITICAL: Failed to query for Email Category '%s': %v. Notifications will not be sent.", approvalCategoryTitle, err)
-		return
+		return err
 	}
 	if category == nil {
 		log.Printf("CRITICAL: Email Category with title '%s' not found. Please create it in the CMS. Notifications will not be sent.", approvalCategoryTitle)
-		return
+		return nil
 	}
 	// --- END KEY CHANGE ---
 
@@ -184,12 +278,12 @@ func (s *CMSLandingPageService) triggerApprovalNotifications(content *models.Lan
 	templates, err := s.emailContentRepo.ListByFilters(filter)
 	if err != nil {
 		log.Printf("Error fetching email templates for Category '%s' (ID: %s): %v", approvalCategoryTitle, emailCategoryIDStr, err)
-		return
+		return err
 	}
 
 	if len(templates) == 0 {
 		log.Printf("No email templates found for Category '%s' and language '%s'. No notifications sent.", approvalCategoryTitle, content.Language)
-		return
+		return nil
 	}
 
 	previewURL, cmsEditURL := s.buildNotificationURLs("landing", content)
@@ -236,16 +330,17 @@ func (s *CMSLandingPageService) triggerApprovalNotifications(content *models.Lan
 			Language:               template.Language,
 			ToRecipientEmails:      recipients,
 			Data:                   emailData,
+			IdempotencyKey:         eventId + ":" + template.ID.String(),
 		}
 
-		go func(req dto.SendEmailRequest) {
-			if err := s.emailSendingService.SendEmail(req); err != nil {
-				log.Printf("Error sending email using template '%s': %v", req.EmailContentLabel, err)
-			} else {
-				log.Printf("Successfully queued email using template '%s' to: %v", req.EmailContentLabel, req.ToRecipientEmails)
-			}
-		}(emailReq)
+		if err := s.emailSendingService.SendEmail(emailReq); err != nil {
+			log.Printf("Error sending email using template '%s': %v", emailReq.EmailContentLabel, err)
+			return err
+		}
+		log.Printf("Successfully queued email using template '%s' to: %v", emailReq.EmailContentLabel, emailReq.ToRecipientEmails)
 	}
+
+	return nil
 }
 
 func (s *CMSLandingPageService) buildNotificationURLs(pageType string, content *models.LandingContent) (previewUrl, cmsEditUrl string) {
@@ -318,8 +413,13 @@ func (s *CMSLandingPageService) getAuthorInfo(content *models.LandingContent) (n
 	return
 }
 
-func (s *CMSLandingPageService) DeleteLandingPage(id uuid.UUID) error {
-	return s.repo.DeleteLandingPage(id)
+// DeleteLandingPage deletes the page, unless forced it fails with ErrItemInUse while published content refers to it
+func (s *CMSLandingPageService) DeleteLandingPage(id uuid.UUID, force bool) error {
+	if err := s.checkAccess(id, pageContentSelector{every: true}, enums.PermissionActionDelete); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteLandingPage(id, force)
 }
 
 func (s *CMSLandingPageService) FindContentByLandingPageId(pageId uuid.UUID, language string, mode string) (*models.LandingContent, error) {
@@ -333,6 +433,10 @@ func (s *CMSLandingPageService) FindContentByLandingPageId(pageId uuid.UUID, lan
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return s.repo.FindContentByLandingPageId(pageId, language, mode)
 }
 
@@ -342,6 +446,10 @@ func (r *CMSLandingPageService) FindLatestContentByPageId(pageId uuid.UUID, lang
 		return nil, err
 	}
 
+	if err := r.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return r.repo.FindLatestContentByPageId(pageId, language)
 }
 
@@ -356,10 +464,19 @@ func (s *CMSLandingPageService) DeleteContentByLandingPageId(pageId uuid.UUID, l
 		return err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language), every: true}, enums.PermissionActionDelete); err != nil {
+		return err
+	}
+
 	return s.repo.DeleteLandingContent(pageId, language, mode)
 }
 
 func (s *CMSLandingPageService) DuplicateLandingPage(pageId uuid.UUID) (*models.LandingPage, error) {
+	// The copy keeps the languages and categories of the page
+	if err := s.checkAccess(pageId, pageContentSelector{every: true}, enums.PermissionActionRead, enums.PermissionActionCreate); err != nil {
+		return nil, err
+	}
+
 	landingPage, err := s.repo.DuplicateLandingPage(pageId)
 
 	if err != nil {
@@ -376,10 +493,39 @@ func (s *CMSLandingPageService) DuplicateLandingContentToAnotherLanguage(content
 		return nil, err
 	}
 
+	if !s.access.AllowsAll(enums.PageTypeLanding, enums.PermissionActionRead) || !s.access.AllowsAll(enums.PageTypeLanding, enums.PermissionActionCreate) {
+		pageId, err := s.repo.GetPageIdByContentId(contentId)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTranslationAccess(s.access, enums.PageTypeLanding, contentId, func() ([]dto.PageContentScope, error) {
+			return s.repo.FindContentScopes(pageId)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkCopyPublishAudit(contentId); err != nil {
+		return nil, err
+	}
+
 	return s.repo.DuplicateLandingContentToAnotherLanguage(contentId, newRevision)
 }
 
 func (s *CMSLandingPageService) RevertLandingContent(revisionId uuid.UUID, newRevision *models.Revision) (*models.LandingContent, error) {
+	if !s.access.AllowsAll(enums.PageTypeLanding, enums.PermissionActionUpdate) || publishAuditEnabled(s.cfg) {
+		pageId, contentId, err := s.repo.GetContentRefByRevisionId(revisionId)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkAccess(pageId, pageContentSelector{contentId: contentId}, enums.PermissionActionUpdate); err != nil {
+			return nil, err
+		}
+		if err := s.checkCopyPublishAudit(contentId); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.repo.RevertLandingContent(revisionId, newRevision)
 }
 
@@ -393,6 +539,10 @@ func (s *CMSLandingPageService) GetCategory(pageId uuid.UUID, categoryTypeCode,
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	categories, err := s.repo.GetCategory(pageId, categoryTypeCode, language, mode)
 	if err != nil {
 		return nil, err
@@ -407,6 +557,10 @@ func (s *CMSLandingPageService) FindRevisions(pageId uuid.UUID, language string)
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	revisions, err := s.repo.GetRevisionByLandingPageId(pageId, language)
 	if err != nil {
 		return nil, err
@@ -415,77 +569,44 @@ func (s *CMSLandingPageService) FindRevisions(pageId uuid.UUID, language string)
 	return revisions, nil
 }
 
-func (s *CMSLandingPageService) PreviewLandingContent(pageId uuid.UUID, landingContentPreview *models.LandingContent) (string, error) {
-	urls := s.cfg.App.FrontendURLS // "http://localhost:8000,http://localhost:3000"
-	parts := strings.Split(urls, ",")
-	appUrl := parts[1] // "http://localhost:3000"
+func (s *CMSLandingPageService) PreviewLandingContent(pageId uuid.UUID, landingContentPreview *models.LandingContent) (*models.LandingContent, error) {
+	if err := s.checkAccess(pageId, pageContentSelector{language: landingContentPreview.Language}, enums.PermissionActionUpdate); err != nil {
+		return nil, err
+	}
 
-	// Default value for preview content
+	// Default value for preview content, kept as long as the longest preview link can live
 	landingContentPreview.Mode = enums.PageModePreview
 	landingContentPreview.PublishStatus = enums.PublishStatusNotPublished
 	landingContentPreview.WorkflowStatus = enums.WorkflowUnPublished
-	landingContentPreview.ExpiredAt = time.Now().Add(2 * time.Hour)
-
+	landingContentPreview.ExpiredAt = time.Now().Add(s.cfg.Preview.MaxTTL)
 	if err := helpers.NormalizeLandingContent(landingContentPreview); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Check if the URL Alias is duplicate or not
 	isUrlAliasDuplicate, err := s.repo.IsUrlAliasDuplicate(landingContentPreview.UrlAlias, pageId)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if isUrlAliasDuplicate {
-		return "", errs.ErrDuplicateUrlAlias
+		return nil, errs.ErrDuplicateUrlAlias
 	}
 
 	existingPreviewContent, err := s.repo.FindLandingContentPreviewById(pageId, string(landingContentPreview.Language))
 
 	// Internal error
 	if err != nil && err != gorm.ErrRecordNotFound {
-		return "", err
+		return nil, err
 	}
 
 	// Record not found, so we create a new one
 	if err == gorm.ErrRecordNotFound {
 		landingContentPreview.PageID = pageId
-		createdLandingContentPreview, err := s.repo.CreateLandingContentPreview(landingContentPreview)
-		if err != nil {
-			return "", err
-		}
-
-		previewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(createdLandingContentPreview.Language),
-			"landing",
-			createdLandingContentPreview.ID,
-		)
-
-		if err != nil {
-			return "", err
-		}
-
-		return previewUrl, nil
+		return s.repo.CreateLandingContentPreview(landingContentPreview)
 	}
 
 	// Attach the old id, so we can save the new one in its place
 	landingContentPreview.ID = existingPreviewContent.ID
 	landingContentPreview.PageID = existingPreviewContent.PageID
-	updatedLandingContentPreview, err := s.repo.UpdateLandingContentPreview(landingContentPreview)
-	if err != nil {
-		return "", err
-	}
-
-	previewUrl, err := helpers.BuildPreviewURL(
-		appUrl,
-		string(updatedLandingContentPreview.Language),
-		"landing",
-		updatedLandingContentPreview.ID,
-	)
-
-	if err != nil {
-		return "", err
-	}
-
-	return previewUrl, nil
+	return s.repo.UpdateLandingContentPreview(landingContentPreview)
 }
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// A variant beats the control when the two-proportion z-test p-value is below this
+const experimentSignificanceLevel = 0.05
+
+type CMSLandingExperimentServiceInterface interface {
+	CreateExperiment(request dto.CreateLandingExperimentRequest) (*models.LandingExperiment, error)
+	FindExperiments(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error)
+	GetExperimentResults(id uuid.UUID) (*dto.ExperimentResults, error)
+	StopExperiment(id uuid.UUID) error
+	PromoteWinner(id uuid.UUID, request dto.PromoteExperimentRequest) (*models.LandingContent, error)
+	AssignVariant(content *models.LandingContent, assignmentKey string) (*dto.ExperimentAssignment, error)
+	RecordConversion(experimentId uuid.UUID, assignmentKey string) error
+	WithPageAccess(access *dto.PageAccess) CMSLandingExperimentServiceInterface
+}
+
+type CMSLandingExperimentService struct {
+	repo           repositories.CMSLandingExperimentRepositoryInterface
+	landingService CMSLandingPageServiceInterface
+	cfg            *config.Config
+	access         *dto.PageAccess // Pages the user of the request may run experiments on, nil for any
+}
+
+func NewCMSLandingExperimentService(repo repositories.CMSLandingExperimentRepositoryInterface, landingService CMSLandingPageServiceInterface, cfg *config.Config) *CMSLandingExperimentService {
+	return &CMSLandingExperimentService{
+		repo:           repo,
+		landingService: landingService,
+		cfg:            cfg,
+	}
+}
+
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSLandingExperimentService) WithPageAccess(access *dto.PageAccess) CMSLandingExperimentServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// checkAccess fails with ErrPageAccessDenied unless the page access allows the actions on the page language under experiment
+func (s *CMSLandingExperimentService) checkAccess(pageId uuid.UUID, language enums.PageLanguage, actions ...enums.PermissionAction) error {
+	return checkPageAccess(s.access, enums.PageTypeLanding, pageContentSelector{language: language}, func() ([]dto.PageContentScope, error) {
+		return s.repo.FindContentScopes(pageId)
+	}, actions...)
+}
+
+// CreateExperiment starts splitting the traffic of a published landing page language between the variants
+func (s *CMSLandingExperimentService) CreateExperiment(request dto.CreateLandingExperimentRequest) (*models.LandingExperiment, error) {
+	pageId, err := uuid.Parse(request.PageID)
+	if err != nil {
+		return nil, errs.ErrInvalidExperiment
+	}
+	language, err := helpers.NormalizeLanguage(request.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	experiment := &models.LandingExperiment{
+		PageID:    pageId,
+		Language:  enums.PageLanguage(language),
+		Name:      strings.TrimSpace(request.Name),
+		Status:    enums.ExperimentStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if experiment.Name == "" || len(request.Variants) < 2 {
+		return nil, errs.ErrInvalidExperiment
+	}
+
+	controls := 0
+	names := map[string]bool{}
+	for _, variant := range request.Variants {
+		name := strings.TrimSpace(variant.Name)
+		if name == "" || names[name] || variant.Weight <= 0 {
+			return nil, errs.ErrInvalidExperiment
+		}
+		names[name] = true
+
+		created := &models.LandingExperimentVariant{Name: name, Weight: variant.Weight, IsControl: variant.IsControl}
+		if variant.IsControl {
+			controls++
+		} else {
+			if variant.Content == nil {
+				return nil, errs.ErrInvalidExperiment
+			}
+			content := models.LandingContent{Title: variant.Content.Title, HTMLInput: variant.Content.HTMLInput}
+			helpers.SanitizeLandingContent(&content)
+			payload, err := json.Marshal(dto.LandingVariantContent{
+				Title:      content.Title,
+				HTMLInput:  content.HTMLInput,
+				Components: variant.Content.Components,
+			})
+			if err != nil {
+				return nil, err
+			}
+			created.Content = datatypes.JSON(payload)
+		}
+		experiment.Variants = append(experiment.Variants, created)
+	}
+	if controls != 1 {
+		return nil, errs.ErrInvalidExperiment
+	}
+
+	if err := s.checkAccess(experiment.PageID, experiment.Language, enums.PermissionActionUpdate); err != nil {
+		return nil, err
+	}
+
+	// Variants are served on top of the published content, so there has to be one
+	if _, err := s.repo.FindPublishedContent(experiment.PageID, experiment.Language); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.FindRunningExperiment(experiment.PageID, experiment.Language); err == nil {
+		return nil, errs.ErrExperimentRunning
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.repo.CreateExperiment(experiment); err != nil {
+		return nil, err
+	}
+
+	return experiment, nil
+}
+
+func (s *CMSLandingExperimentService) FindExperiments(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error) {
+	switch status {
+	case "", enums.ExperimentStatusRunning, enums.ExperimentStatusCompleted:
+	default:
+		return nil, errs.ErrInvalidExperimentStatus
+	}
+
+	experiments, err := s.repo.FindExperiments(pageId, status)
+	if err != nil {
+		return nil, err
+	}
+	if s.access.AllowsAll(enums.PageTypeLanding, enums.PermissionActionRead) {
+		return experiments, nil
+	}
+
+	// The list leaves out the experiments on pages the user may not read
+	readable := []models.LandingExperiment{}
+	for _, experiment := range experiments {
+		err := s.checkAccess(experiment.PageID, experiment.Language, enums.PermissionActionRead)
+		if errors.Is(err, errs.ErrPageAccessDenied) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		readable = append(readable, experiment)
+	}
+
+	return readable, nil
+}
+
+// GetExperimentResults compares the conversion rate of every variant with the control's
+func (s *CMSLandingExperimentService) GetExperimentResults(id uuid.UUID) (*dto.ExperimentResults, error) {
+	experiment, err := s.repo.FindExperimentById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccess(experiment.PageID, experiment.Language, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.CountEvents(id)
+	if err != nil {
+		return nil, err
+	}
+	exposures := map[uuid.UUID]int64{}
+	conversions := map[uuid.UUID]int64{}
+	for _, count := range counts {
+		switch count.Type {
+		case enums.ExperimentEventExposure:
+			exposures[count.VariantID] = count.Visitors
+		case enums.ExperimentEventConversion:
+			conversions[count.VariantID] = count.Visitors
+		}
+	}
+
+	results := &dto.ExperimentResults{
+		ExperimentID:      experiment.ID,
+		Name:              experiment.Name,
+		Status:            experiment.Status,
+		WinnerVariantID:   experiment.WinnerVariantID,
+		SignificanceLevel: experimentSignificanceLevel,
+		Variants:          []dto.ExperimentVariantResult{},
+	}
+
+	controlIndex := -1
+	for _, variant := range experiment.Variants {
+		result := dto.ExperimentVariantResult{
+			VariantID:   variant.ID,
+			Name:        variant.Name,
+			IsControl:   variant.IsControl,
+			Weight:      variant.Weight,
+			Exposures:   exposures[variant.ID],
+			Conversions: conversions[variant.ID],
+		}
+		if result.Exposures > 0 {
+			result.ConversionRate = float64(result.Conversions) / float64(result.Exposures)
+		}
+		results.Variants = append(results.Variants, result)
+		if variant.IsControl {
+			controlIndex = len(results.Variants) - 1
+		}
+	}
+	if controlIndex < 0 {
+		return results, nil
+	}
+	control := results.Variants[controlIndex]
+
+	for i := range results.Variants {
+		result := &results.Variants[i]
+		if result.IsControl {
+			continue
+		}
+		if control.ConversionRate > 0 {
+			lift := (result.ConversionRate - control.ConversionRate) / control.ConversionRate
+			result.Lift = &lift
+		}
+		if pValue, ok := twoProportionPValue(control.Conversions, control.Exposures, result.Conversions, result.Exposures); ok {
+			result.PValue = &pValue
+			result.Significant = pValue < experimentSignificanceLevel
+		}
+	}
+
+	return results, nil
+}
+
+// twoProportionPValue is the two-sided p-value of the pooled two-proportion z-test, false when it is undefined
+func twoProportionPValue(conversionsA, exposuresA, conversionsB, exposuresB int64) (float64, bool) {
+	if exposuresA == 0 || exposuresB == 0 {
+		return 0, false
+	}
+
+	rateA := float64(conversionsA) / float64(exposuresA)
+	rateB := float64(conversionsB) / float64(exposuresB)
+	pooled := float64(conversionsA+conversionsB) / float64(exposuresA+exposuresB)
+	standardError := math.Sqrt(pooled * (1 - pooled) * (1/float64(exposuresA) + 1/float64(exposuresB)))
+	if standardError == 0 {
+		return 0, false
+	}
+
+	z := (rateB - rateA) / standardError
+	return math.Erfc(math.Abs(z) / math.Sqrt2), true
+}
+
+// StopExperiment serves the published content to everyone again, without a winner
+func (s *CMSLandingExperimentService) StopExperiment(id uuid.UUID) error {
+	experiment, err := s.repo.FindExperimentById(id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkAccess(experiment.PageID, experiment.Language, enums.PermissionActionUpdate); err != nil {
+		return err
+	}
+
+	updated, err := s.repo.CompleteExperiment(id, nil, time.Now())
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return errs.ErrExperimentNotRunning
+	}
+
+	return nil
+}
+
+// PromoteWinner saves the winning variant as a new version of the published content and ends the experiment.
+// Promoting the control keeps the published content as it is.
+func (s *CMSLandingExperimentService) PromoteWinner(id uuid.UUID, request dto.PromoteExperimentRequest) (*models.LandingContent, error) {
+	variantId, err := uuid.Parse(request.VariantID)
+	if err != nil {
+		return nil, errs.ErrInvalidExperimentVariant
+	}
+
+	experiment, err := s.repo.FindExperimentById(id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.WinnerVariantID != nil {
+		return nil, errs.ErrExperimentCompleted
+	}
+
+	var winner *models.LandingExperimentVariant
+	for _, variant := range experiment.Variants {
+		if variant.ID == variantId {
+			winner = variant
+		}
+	}
+	if winner == nil {
+		return nil, errs.ErrInvalidExperimentVariant
+	}
+	if err := s.checkAccess(experiment.PageID, experiment.Language, enums.PermissionActionUpdate, enums.PermissionActionPublish); err != nil {
+		return nil, err
+	}
+
+	content, err := s.repo.FindPublishedContent(experiment.PageID, experiment.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	if !winner.IsControl {
+		if err := applyVariantContent(content, winner); err != nil {
+			return nil, err
+		}
+
+		revision := &models.Revision{
+			PublishStatus: content.PublishStatus,
+			Author:        request.Revision.Author,
+			Message:       request.Revision.Message,
+			Description:   request.Revision.Description,
+		}
+		if revision.Message == "" {
+			revision.Message = fmt.Sprintf("Promoted the winner of experiment %s: %s", experiment.Name, winner.Name)
+		}
+		content.Revision = revision
+		helpers.SanitizeLandingContent(content)
+
+		content, err = s.landingService.UpdateLandingContent(content, content.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.CompleteExperiment(id, &winner.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, errs.ErrExperimentCompleted
+	}
+
+	return content, nil
+}
+
+// AssignVariant swaps the content for the variant of the visitor when its page language is under experiment.
+// It returns nil when there is no running experiment.
+func (s *CMSLandingExperimentService) AssignVariant(content *models.LandingContent, assignmentKey string) (*dto.ExperimentAssignment, error) {
+	if assignmentKey == "" {
+		return nil, errs.ErrAssignmentKeyRequired
+	}
+
+	experiment, err := s.repo.FindRunningExperiment(content.PageID, content.Language)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	assignmentHash := s.assignmentHash(assignmentKey)
+	variant := pickVariant(experiment, assignmentHash)
+	if variant == nil {
+		return nil, nil
+	}
+
+	if err := applyVariantContent(content, variant); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateEvent(&models.LandingExperimentEvent{
+		ExperimentID:   experiment.ID,
+		VariantID:      variant.ID,
+		AssignmentHash: assignmentHash,
+		Type:           enums.ExperimentEventExposure,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &dto.ExperimentAssignment{
+		ExperimentID: experiment.ID,
+		VariantID:    variant.ID,
+		VariantName:  variant.Name,
+	}, nil
+}
+
+// RecordConversion credits the variant the visitor was served, converting twice counts once
+func (s *CMSLandingExperimentService) RecordConversion(experimentId uuid.UUID, assignmentKey string) error {
+	if assignmentKey == "" {
+		return errs.ErrAssignmentKeyRequired
+	}
+
+	experiment, err := s.repo.FindExperimentById(experimentId)
+	if err != nil {
+		return err
+	}
+	if experiment.Status != enums.ExperimentStatusRunning {
+		return errs.ErrExperimentNotRunning
+	}
+
+	assignmentHash := s.assignmentHash(assignmentKey)
+	exposure, err := s.repo.FindExposure(experimentId, assignmentHash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrExperimentNotExposed
+		}
+		return err
+	}
+
+	return s.repo.CreateEvent(&models.LandingExperimentEvent{
+		ExperimentID:   experimentId,
+		VariantID:      exposure.VariantID,
+		AssignmentHash: assignmentHash,
+		Type:           enums.ExperimentEventConversion,
+	})
+}
+
+func (s *CMSLandingExperimentService) assignmentHash(assignmentKey string) string {
+	return helpers.HashVisitor(s.cfg.SecretKey.HashSalt, assignmentKey)
+}
+
+// pickVariant buckets the visitor by the hash, so the same visitor always gets the same variant of an experiment
+func pickVariant(experiment *models.LandingExperiment, assignmentHash string) *models.LandingExperimentVariant {
+	total := 0
+	for _, variant := range experiment.Variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(experiment.ID.String() + "\n" + assignmentHash))
+	bucket := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for _, variant := range experiment.Variants {
+		if bucket < variant.Weight {
+			return variant
+		}
+		bucket -= variant.Weight
+	}
+
+	return nil
+}
+
+func applyVariantContent(content *models.LandingContent, variant *models.LandingExperimentVariant) error {
+	if len(variant.Content) == 0 {
+		return nil
+	}
+
+	var override dto.LandingVariantContent
+	if err := json.Unmarshal(variant.Content, &override); err != nil {
+		return err
+	}
+	if override.Title != "" {
+		content.Title = override.Title
+	}
+	if override.HTMLInput != "" {
+		content.HTMLInput = override.HTMLInput
+	}
+	if override.Components != nil {
+		for _, component := range override.Components {
+			if component != nil {
+				component.LandingContentID = &content.ID
+			}
+		}
+		content.Components = override.Components
+	}
+
+	return nil
+}
@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+)
+
+type CMSLinkCheckServiceInterface interface {
+	RunLinkCheck(ctx context.Context) (*dto.LinkCheckRunSummary, error)
+	CheckRunAccess() error
+	IsLinkCheckRunning() bool
+	FindLinkChecks(query dto.LinkCheckQuery, page, limit int) ([]dto.LinkCheckResponse, int64, error)
+	WithPageAccess(access *dto.PageAccess) CMSLinkCheckServiceInterface
+}
+
+type CMSLinkCheckService struct {
+	repo       repositories.CMSLinkCheckRepositoryInterface
+	cfg        *config.Config
+	httpClient *http.Client
+	running    *atomic.Bool    // Shared by the copies of the service, only one run at a time
+	access     *dto.PageAccess // Pages the user of the request may see the links of, nil for any
+}
+
+func NewCMSLinkCheckService(repo repositories.CMSLinkCheckRepositoryInterface, cfg *config.Config) *CMSLinkCheckService {
+	return &CMSLinkCheckService{
+		repo:       repo,
+		cfg:        cfg,
+		httpClient: newLinkCheckHTTPClient(cfg.LinkCheck),
+		running:    &atomic.Bool{},
+	}
+}
+
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSLinkCheckService) WithPageAccess(access *dto.PageAccess) CMSLinkCheckServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// CheckRunAccess fails with ErrPageAccessDenied unless the access reads every page, a run checks the links of all of them
+func (s *CMSLinkCheckService) CheckRunAccess() error {
+	for _, pageType := range []enums.PageType{enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq} {
+		if !s.access.AllowsAll(pageType, enums.PermissionActionRead) {
+			return errs.ErrPageAccessDenied
+		}
+	}
+	return nil
+}
+
+// errBlockedLinkAddress keeps the checker from requesting the network it runs in
+var errBlockedLinkAddress = errors.New("link resolves to a loopback, private or link-local address")
+
+const maxLinkRedirects = 10
+
+func newLinkCheckHTTPClient(cfg config.LinkCheckConfig) *http.Client {
+	return newGuardedHTTPClient(cfg.Timeout, cfg.AllowPrivateHosts)
+}
+
+// newGuardedHTTPClient returns a client that refuses to connect to blocked addresses, on the first request and on every redirect.
+// The check runs on the address actually dialed, so a host cannot resolve to a public address first and a private one later.
+func newGuardedHTTPClient(timeout time.Duration, allowPrivateHosts bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateHosts {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isBlockedLinkIP(ip) {
+				return errBlockedLinkAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be the address dialed instead of the link
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxLinkRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxLinkRedirects)
+			}
+			if allowPrivateHosts {
+				return nil
+			}
+			return checkLinkHost(req.Context(), req.URL.Hostname())
+		},
+	}
+}
+
+// isBlockedHost reports whether the host of a url is a blocked address or a name for the local machine, without resolving it
+func isBlockedHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && isBlockedLinkIP(ip)
+}
+
+func isBlockedLinkIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// checkLinkHost resolves the host and fails when any of its addresses is blocked
+func checkLinkHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if isBlockedLinkIP(addr.IP) {
+			return errBlockedLinkAddress
+		}
+	}
+	return nil
+}
+
+// foundLink is one link of one content field, before it is checked
+type foundLink struct {
+	pageType  enums.PageType
+	pageId    uuid.UUID
+	contentId uuid.UUID
+	language  enums.PageLanguage
+	field     string
+	url       string
+}
+
+type linkResult struct {
+	isInternal   bool
+	status       enums.LinkStatus
+	statusCode   int
+	errorMessage string
+}
+
+func (s *CMSLinkCheckService) IsLinkCheckRunning() bool {
+	return s.running.Load()
+}
+
+// RunLinkCheck checks every link of every published content and stores the results
+func (s *CMSLinkCheckService) RunLinkCheck(ctx context.Context) (*dto.LinkCheckRunSummary, error) {
+	if err := s.CheckRunAccess(); err != nil {
+		return nil, err
+	}
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, errs.ErrLinkCheckInProgress
+	}
+	defer s.running.Store(false)
+
+	summary := &dto.LinkCheckRunSummary{StartedAt: time.Now()}
+
+	links, contentsScanned, err := s.collectLinks()
+	if err != nil {
+		return nil, err
+	}
+	summary.ContentsScanned = contentsScanned
+
+	// Internal links are resolved against the database, the distinct external ones over http
+	results := map[string]linkResult{}
+	externalTargets := map[string]string{}
+	externalLinks := []string{}
+	for _, link := range links {
+		if _, ok := results[link.url]; ok {
+			continue
+		}
+		if _, ok := externalTargets[link.url]; ok {
+			continue
+		}
+
+		target, isInternal := s.resolveLink(link.url)
+		if !isInternal {
+			externalTargets[link.url] = target
+			externalLinks = append(externalLinks, target)
+			continue
+		}
+
+		result, err := s.checkInternalLink(target)
+		if err != nil {
+			return nil, err
+		}
+		results[link.url] = result
+	}
+
+	externalResults := s.checkExternalLinks(ctx, externalLinks)
+	for link, target := range externalTargets {
+		results[link] = externalResults[target]
+	}
+
+	checkedAt := time.Now()
+	linkChecks := make([]models.LinkCheck, 0, len(links))
+	for _, link := range links {
+		result := results[link.url]
+		linkChecks = append(linkChecks, models.LinkCheck{
+			PageType:      link.pageType,
+			PageID:        link.pageId,
+			ContentID:     link.contentId,
+			Language:      link.language,
+			Field:         link.field,
+			URL:           link.url,
+			IsInternal:    result.isInternal,
+			Status:        result.status,
+			StatusCode:    result.statusCode,
+			ErrorMessage:  result.errorMessage,
+			LastCheckedAt: checkedAt,
+		})
+
+		switch result.status {
+		case enums.LinkStatusBroken:
+			summary.Broken++
+		case enums.LinkStatusError:
+			summary.Errors++
+		}
+	}
+	summary.LinksChecked = len(linkChecks)
+
+	if err := s.repo.UpsertLinkChecks(linkChecks); err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteLinkChecksCheckedBefore(summary.StartedAt); err != nil {
+		return nil, err
+	}
+
+	summary.FinishedAt = time.Now()
+	return summary, nil
+}
+
+func (s *CMSLinkCheckService) FindLinkChecks(query dto.LinkCheckQuery, page, limit int) ([]dto.LinkCheckResponse, int64, error) {
+	if query.PageID != "" {
+		if _, err := uuid.Parse(query.PageID); err != nil {
+			return nil, 0, errs.ErrInvalidUUIDFormat
+		}
+	}
+	if query.PageType != "" {
+		switch query.PageType {
+		case enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq:
+		default:
+			return nil, 0, errs.ErrInvalidPageType
+		}
+	}
+	if query.Status != "" {
+		switch query.Status {
+		case enums.LinkStatusOK, enums.LinkStatusBroken, enums.LinkStatusError:
+		default:
+			return nil, 0, errs.ErrInvalidLinkStatus
+		}
+	}
+
+	query.Access = s.access
+	linkChecks, totalCount, err := s.repo.FindLinkChecks(query, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.LinkCheckResponse, 0, len(linkChecks))
+	for _, linkCheck := range linkChecks {
+		responses = append(responses, dto.LinkCheckResponse{
+			ID:            linkCheck.ID.String(),
+			PageType:      linkCheck.PageType,
+			PageID:        linkCheck.PageID.String(),
+			ContentID:     linkCheck.ContentID.String(),
+			Language:      linkCheck.Language,
+			Field:         linkCheck.Field,
+			URL:           linkCheck.URL,
+			IsInternal:    linkCheck.IsInternal,
+			Status:        linkCheck.Status,
+			StatusCode:    linkCheck.StatusCode,
+			ErrorMessage:  linkCheck.ErrorMessage,
+			LastCheckedAt: linkCheck.LastCheckedAt,
+		})
+	}
+
+	return responses, totalCount, nil
+}
+
+// StartScheduler checks all links at startup, then again every configured interval until the context is cancelled
+func (s *CMSLinkCheckService) StartScheduler(ctx context.Context) {
+	if s.cfg.LinkCheck.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.LinkCheck.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runScheduledLinkCheck(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *CMSLinkCheckService) runScheduledLinkCheck(ctx context.Context) {
+	summary, err := s.RunLinkCheck(ctx)
+	if err != nil {
+		log.Printf("Scheduled link check failed: %v", err)
+		return
+	}
+	log.Printf("Scheduled link check done: %d links in %d contents, %d broken, %d errors",
+		summary.LinksChecked, summary.ContentsScanned, summary.Broken, summary.Errors)
+}
+
+func (s *CMSLinkCheckService) collectLinks() ([]foundLink, int, error) {
+	links := []foundLink{}
+	contentsScanned := 0
+
+	add := func(pageType enums.PageType, pageId, contentId uuid.UUID, language enums.PageLanguage, field string, urls []string) {
+		for _, link := range urls {
+			links = append(links, foundLink{pageType, pageId, contentId, language, field, link})
+		}
+	}
+	addComponents := func(pageType enums.PageType, pageId, contentId uuid.UUID, language enums.PageLanguage, components []*models.Component) {
+		for i, component := range components {
+			add(pageType, pageId, contentId, language, fmt.Sprintf("components[%d]", i), helpers.ExtractComponentLinks(component.Props))
+		}
+	}
+	single := func(link string) []string {
+		if !helpers.IsCheckableLink(strings.TrimSpace(link)) {
+			return nil
+		}
+		return []string{strings.TrimSpace(link)}
+	}
+
+	landingContents, err := s.repo.FindPublishedLandingContents()
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, content := range landingContents {
+		add(enums.PageTypeLanding, content.PageID, content.ID, content.Language, "html_input", helpers.ExtractHTMLLinks(content.HTMLInput))
+		addComponents(enums.PageTypeLanding, content.PageID, content.ID, content.Language, content.Components)
+	}
+	contentsScanned += len(landingContents)
+
+	partnerContents, err := s.repo.FindPublishedPartnerContents()
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, content := range partnerContents {
+		add(enums.PageTypePartner, content.PageID, content.ID, content.Language, "html_input", helpers.ExtractHTMLLinks(content.HTMLInput))
+		add(enums.PageTypePartner, content.PageID, content.ID, content.Language, "company_detail", helpers.ExtractHTMLLinks(content.CompanyDetail))
+		add(enums.PageTypePartner, content.PageID, content.ID, content.Language, "lead_body", helpers.ExtractHTMLLinks(content.LeadBody))
+		add(enums.PageTypePartner, content.PageID, content.ID, content.Language, "challenges", helpers.ExtractHTMLLinks(content.Challenges))
+		add(enums.PageTypePartner, content.PageID, content.ID, content.Language, "solutions", helpers.ExtractHTMLLinks(content.Solutions))
+		add(enums.PageTypePartner, content.PageID, content.ID, content.Language, "results", helpers.ExtractHTMLLinks(content.Results))
+		add(enums.PageTypePartner, content.PageID, content.ID, content.Language, "thumbnail_image", single(content.ThumbnailImage))
+		add(enums.PageTypePartner, content.PageID, content.ID, content.Language, "company_logo", single(content.CompanyLogo))
+		addComponents(enums.PageTypePartner, content.PageID, content.ID, content.Language, content.Components)
+	}
+	contentsScanned += len(partnerContents)
+
+	faqContents, err := s.repo.FindPublishedFaqContents()
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, content := range faqContents {
+		add(enums.PageTypeFaq, content.PageID, content.ID, content.Language, "html_input", helpers.ExtractHTMLLinks(content.HTMLInput))
+		addComponents(enums.PageTypeFaq, content.PageID, content.ID, content.Language, content.Components)
+	}
+	contentsScanned += len(faqContents)
+
+	return links, contentsScanned, nil
+}
+
+// resolveLink returns the path for internal links, or the absolute url for external ones
+func (s *CMSLinkCheckService) resolveLink(link string) (string, bool) {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		// Let the http check report it
+		return link, false
+	}
+
+	if parsed.Host == "" {
+		return parsed.Path, true
+	}
+
+	for _, base := range []string{s.cfg.App.WebBaseURL, s.cfg.App.APIBaseURL} {
+		if baseURL, err := url.Parse(base); err == nil && baseURL.Host != "" && strings.EqualFold(baseURL.Host, parsed.Host) {
+			return parsed.Path, true
+		}
+	}
+
+	// Protocol-relative links
+	if parsed.Scheme == "" {
+		parsed.Scheme = "https"
+	}
+	return parsed.String(), false
+}
+
+func (s *CMSLinkCheckService) checkInternalLink(urlPath string) (linkResult, error) {
+	result := linkResult{isInternal: true, status: enums.LinkStatusOK}
+
+	var registered bool
+	var err error
+	if prefix := s.cfg.App.StaticFilePrefix; prefix != "" && strings.HasPrefix(urlPath, strings.TrimSuffix(prefix, "/")+"/") {
+		registered, err = s.repo.IsMediaFileRegistered(strings.TrimSuffix(s.cfg.App.APIBaseURL, "/") + urlPath)
+	} else {
+		// Public pages live under /{language}/{url}
+		segments := strings.SplitN(strings.Trim(urlPath, "/"), "/", 2)
+		if _, langErr := helpers.NormalizeLanguage(segments[0]); langErr == nil {
+			segments = segments[1:]
+		}
+		contentPath := strings.Join(segments, "/")
+		if contentPath == "" {
+			return result, nil
+		}
+		registered, err = s.repo.IsContentPathRegistered(contentPath)
+	}
+	if err != nil {
+		return linkResult{}, err
+	}
+
+	if !registered {
+		result.status = enums.LinkStatusBroken
+		result.errorMessage = "no published content or media file at this path"
+	}
+	return result, nil
+}
+
+// checkExternalLinks requests the links with at most LinkCheck.Concurrency requests in flight
+func (s *CMSLinkCheckService) checkExternalLinks(ctx context.Context, links []string) map[string]linkResult {
+	results := make(map[string]linkResult, len(links))
+	concurrency := s.cfg.LinkCheck.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	for _, link := range links {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(link string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			result := s.checkExternalLink(ctx, link)
+			mu.Lock()
+			results[link] = result
+			mu.Unlock()
+		}(link)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (s *CMSLinkCheckService) checkExternalLink(ctx context.Context, link string) linkResult {
+	if !s.cfg.LinkCheck.AllowPrivateHosts {
+		if parsed, err := url.Parse(link); err == nil {
+			if err := checkLinkHost(ctx, parsed.Hostname()); err != nil {
+				return linkResult{status: enums.LinkStatusError, errorMessage: err.Error()}
+			}
+		}
+	}
+
+	statusCode, err := s.requestLink(ctx, http.MethodHead, link)
+	// Some servers refuse HEAD, retry those with GET
+	if err == nil && (statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusNotImplemented || statusCode == http.StatusForbidden) {
+		statusCode, err = s.requestLink(ctx, http.MethodGet, link)
+	}
+
+	if err != nil {
+		return linkResult{status: enums.LinkStatusError, errorMessage: err.Error()}
+	}
+	if statusCode >= http.StatusBadRequest {
+		return linkResult{status: enums.LinkStatusBroken, statusCode: statusCode}
+	}
+	return linkResult{status: enums.LinkStatusOK, statusCode: statusCode}
+}
+
+func (s *CMSLinkCheckService) requestLink(ctx context.Context, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", s.cfg.LinkCheck.UserAgent)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a little so the connection can be reused, never the whole body
+	_, _ = io.CopyN(io.Discard, resp.Body, 4096)
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+)
+
+type CMSMaintenanceServiceInterface interface {
+	RunMaintenance(ctx context.Context, dryRun bool) (*dto.MaintenanceReport, error)
+	IsMaintenanceRunning() bool
+	GetMaintenanceMetrics() dto.MaintenanceMetrics
+}
+
+type CMSMaintenanceService struct {
+	repo    repositories.CMSMaintenanceRepositoryInterface
+	cfg     *config.Config
+	running atomic.Bool
+
+	mu      sync.Mutex
+	metrics dto.MaintenanceMetrics
+}
+
+func NewCMSMaintenanceService(repo repositories.CMSMaintenanceRepositoryInterface, cfg *config.Config) *CMSMaintenanceService {
+	return &CMSMaintenanceService{
+		repo:    repo,
+		cfg:     cfg,
+		metrics: dto.MaintenanceMetrics{TotalRemoved: map[string]int64{}},
+	}
+}
+
+var maintenancePageTypes = []enums.PageType{enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq}
+
+func (s *CMSMaintenanceService) IsMaintenanceRunning() bool {
+	return s.running.Load()
+}
+
+// RunMaintenance purges expired previews, history versions beyond the retention and orphaned rows
+func (s *CMSMaintenanceService) RunMaintenance(ctx context.Context, dryRun bool) (*dto.MaintenanceReport, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, errs.ErrMaintenanceInProgress
+	}
+	defer s.running.Store(false)
+
+	report := &dto.MaintenanceReport{
+		DryRun:           dryRun,
+		StartedAt:        time.Now(),
+		HistoryRetention: s.cfg.Maintenance.HistoryRetention,
+		ExpiredPreviews:  map[string]int{},
+		PrunedHistories:  map[string]int{},
+		Removed:          map[string]int64{},
+	}
+
+	addRemoved := func(removed map[string]int64) {
+		for table, count := range removed {
+			report.Removed[table] += count
+		}
+	}
+
+	for _, pageType := range maintenancePageTypes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		expiredIds, err := s.repo.FindExpiredPreviewContentIds(pageType, report.StartedAt)
+		if err != nil {
+			return nil, err
+		}
+		historyIds, err := s.repo.FindExcessHistoryContentIds(pageType, s.cfg.Maintenance.HistoryRetention)
+		if err != nil {
+			return nil, err
+		}
+		report.ExpiredPreviews[string(pageType)] = len(expiredIds)
+		report.PrunedHistories[string(pageType)] = len(historyIds)
+
+		removed, err := s.repo.PurgeContents(pageType, append(expiredIds, historyIds...), dryRun)
+		if err != nil {
+			return nil, err
+		}
+		addRemoved(removed)
+	}
+
+	// Rows younger than the grace period may belong to a content that is still being saved
+	removed, err := s.repo.PurgeOrphans(report.StartedAt.Add(-s.cfg.Maintenance.OrphanGrace), dryRun)
+	if err != nil {
+		return nil, err
+	}
+	addRemoved(removed)
+
+	report.FinishedAt = time.Now()
+	s.recordRun(report)
+
+	return report, nil
+}
+
+func (s *CMSMaintenanceService) recordRun(report *dto.MaintenanceReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metrics.Runs++
+	if report.DryRun {
+		s.metrics.DryRuns++
+	} else {
+		for table, count := range report.Removed {
+			s.metrics.TotalRemoved[table] += count
+		}
+	}
+	s.metrics.LastRun = report
+}
+
+func (s *CMSMaintenanceService) GetMaintenanceMetrics() dto.MaintenanceMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics := s.metrics
+	metrics.TotalRemoved = make(map[string]int64, len(s.metrics.TotalRemoved))
+	for table, count := range s.metrics.TotalRemoved {
+		metrics.TotalRemoved[table] = count
+	}
+
+	return metrics
+}
+
+// StartScheduler runs the maintenance cleanup every interval until the context is done
+func (s *CMSMaintenanceService) StartScheduler(ctx context.Context) {
+	if s.cfg.Maintenance.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Maintenance.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.RunMaintenance(ctx, s.cfg.Maintenance.DryRun)
+			if err != nil {
+				log.Printf("Scheduled maintenance cleanup failed: %v", err)
+				continue
+			}
+			log.Printf("Scheduled maintenance cleanup done (dry run: %t): %v", report.DryRun, report.Removed)
+		}
+	}
+}
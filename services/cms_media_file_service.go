@@ -25,7 +25,8 @@ type MediaFileServiceInterface interface {
 	UploadMediaFile(originalFilename string, mimeType string, fileData []byte, customPath *string, replace *bool, userID uuid.UUID) (*dto.MediaFileResponse, error)
 	GetMediaFileByID(idStr string) (*dto.MediaFileResponse, error)
 	ListMediaFiles(filter dto.MediaFileListFilter) (*dto.MediaFilesListResponse, error)
-	DeleteMediaFile(idStr string, userID uuid.UUID) error
+	ListMediaFilesByCursor(filter dto.MediaFileListFilter, cursorQuery dto.CursorQuery) ([]dto.MediaFileListItemResponse, *dto.CursorPage, error)
+	DeleteMediaFile(idStr string, userID uuid.UUID, force bool) error
 }
 
 type mediaFileService struct {
@@ -207,18 +208,17 @@ func (s *mediaFileService) UploadMediaFile(
 	// For now, assuming name must be unique globally or we generate unique names
 	// If your model adds a 'Path' field, adjust repo.FindByNameAndPath
 	existingFile, _ := s.repo.FindByNameAndPath(finalFilename, actualSubPath)
+	var replacedFile *models.MediaFile
 
 	if existingFile != nil {
 		if shouldReplace {
-			// Delete old file from disk and DB before saving new one
+			// Delete old file from disk before saving new one, its record is swapped when the new one is saved
 			oldFilePath := filepath.Join(uploadRoot, actualSubPath, existingFile.Name)
 			if err := os.Remove(oldFilePath); err != nil && !os.IsNotExist(err) {
 				log.Printf("Warning: failed to remove old file from disk '%s': %v", oldFilePath, err)
 				// Decide if this is a critical error or if we can proceed
 			}
-			if err := s.repo.Delete(existingFile.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-				log.Printf("Warning: failed to delete old file record from DB '%s': %v", existingFile.ID, err)
-			}
+			replacedFile = existingFile
 		} else {
 			// Generate a new unique name
 			counter := 1
@@ -265,7 +265,12 @@ func (s *mediaFileService) UploadMediaFile(
 		DownloadURL: downloadURL,
 	}
 
-	createdFile, err := s.repo.Create(mediaFileModel)
+	var createdFile *models.MediaFile
+	if replacedFile != nil {
+		createdFile, err = s.repo.Replace(replacedFile.ID, mediaFileModel)
+	} else {
+		createdFile, err = s.repo.Create(mediaFileModel)
+	}
 	if err != nil {
 		// Attempt to remove the physically saved file if DB record creation fails
 		if removeErr := os.Remove(fullDiskPath); removeErr != nil {
@@ -321,7 +326,26 @@ func (s *mediaFileService) ListMediaFiles(filter dto.MediaFileListFilter) (*dto.
 	}, nil
 }
 
-func (s *mediaFileService) DeleteMediaFile(idStr string, userID uuid.UUID) error {
+func (s *mediaFileService) ListMediaFilesByCursor(filter dto.MediaFileListFilter, cursorQuery dto.CursorQuery) ([]dto.MediaFileListItemResponse, *dto.CursorPage, error) {
+	if cursorQuery.Limit == 0 {
+		cursorQuery.Limit = 20 // Same default page size as ListMediaFiles
+	}
+
+	files, page, err := s.repo.ListByCursor(filter, cursorQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list media files: %w", err)
+	}
+
+	responses := make([]dto.MediaFileListItemResponse, len(files))
+	for i, file := range files {
+		responses[i] = s.mapModelToListItemResponse(&file)
+	}
+
+	return responses, page, nil
+}
+
+// DeleteMediaFile deletes the record and then the file on disk, unless forced it fails with ErrItemInUse while published content links to it
+func (s *mediaFileService) DeleteMediaFile(idStr string, userID uuid.UUID, force bool) error {
 	uid, err := uuid.Parse(idStr)
 	if err != nil {
 		return errors.New("invalid ID format")
@@ -335,6 +359,11 @@ func (s *mediaFileService) DeleteMediaFile(idStr string, userID uuid.UUID) error
 		return fmt.Errorf("failed to find media file for deletion: %w", err)
 	}
 
+	// Delete record from DB first, a file still in use stays on disk
+	if err := s.repo.Delete(uid, force); err != nil {
+		return fmt.Errorf("failed to delete media file record: %w", err)
+	}
+
 	// Let's assume `file.DownloadURL` can be parsed to get the relative path
 	parsedURL, err := url.Parse(file.DownloadURL)
 	if err != nil {
@@ -347,7 +376,7 @@ func (s *mediaFileService) DeleteMediaFile(idStr string, userID uuid.UUID) error
 
 		// Delete file from disk
 		if err := os.Remove(fullDiskPath); err != nil {
-			// Log error but don't necessarily fail the DB delete if file is already gone
+			// Log error but don't fail, the record is already gone
 			log.Printf("Warning: failed to delete file from disk '%s': %v", fullDiskPath, err)
 			if !os.IsNotExist(err) {
 				// If it's not a "file not exist" error, it might be more serious (e.g., permissions)
@@ -356,11 +385,6 @@ func (s *mediaFileService) DeleteMediaFile(idStr string, userID uuid.UUID) error
 		}
 	}
 
-	// Delete record from DB
-	if err := s.repo.Delete(uid); err != nil {
-		return fmt.Errorf("failed to delete media file record: %w", err)
-	}
-
 	log.Printf("User %s deleted media file %s (ID: %s)", userID, file.Name, idStr)
 	return nil
 }
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+)
+
+// Handled events are cleaned up at most this often
+const outboxCleanupInterval = time.Hour
+
+// DomainEventHandler reacts to one event. It may run more than once for the same event,
+// after a failure or when its lease ran out, so it has to be safe to repeat.
+type DomainEventHandler func(ctx context.Context, event *models.OutboxEvent) error
+
+// DomainEventPublisher records events that are not part of another change
+type DomainEventPublisher interface {
+	Publish(events ...*models.OutboxEvent) error
+}
+
+type CMSOutboxServiceInterface interface {
+	DomainEventPublisher
+	FindDeliveries(query dto.OutboxDeliveryQuery, page, limit int) ([]models.OutboxDelivery, int64, error)
+	FindDeliveryByID(id uuid.UUID) (*models.OutboxDelivery, error)
+	RetryDelivery(id uuid.UUID) (*models.OutboxDelivery, error)
+	DispatchDueDeliveries(ctx context.Context) (int, error)
+}
+
+type outboxSubscription struct {
+	handler    DomainEventHandler
+	eventTypes map[enums.DomainEventType]bool
+}
+
+type CMSOutboxService struct {
+	repo          repositories.OutboxRepositoryInterface
+	cfg           *config.Config
+	mu            sync.RWMutex
+	subscriptions map[string]outboxSubscription
+	dispatching   atomic.Bool
+	lastCleanup   time.Time
+}
+
+func NewCMSOutboxService(repo repositories.OutboxRepositoryInterface, cfg *config.Config) *CMSOutboxService {
+	return &CMSOutboxService{
+		repo:          repo,
+		cfg:           cfg,
+		subscriptions: make(map[string]outboxSubscription),
+	}
+}
+
+// Subscribe registers a handler for the given event types under a name. The name is stored with every delivery,
+// so it must stay the same across releases; renaming a subscriber dead-letters its pending deliveries.
+func (s *CMSOutboxService) Subscribe(name string, handler DomainEventHandler, eventTypes ...enums.DomainEventType) {
+	types := make(map[enums.DomainEventType]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		types[eventType] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[name] = outboxSubscription{handler: handler, eventTypes: types}
+}
+
+// subscribersOf lists the names of the handlers subscribed to the event type
+func (s *CMSOutboxService) subscribersOf(eventType enums.DomainEventType) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var names []string
+	for name, subscription := range s.subscriptions {
+		if subscription.eventTypes[eventType] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (s *CMSOutboxService) handlerOf(name string) (DomainEventHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscription, ok := s.subscriptions[name]
+	return subscription.handler, ok
+}
+
+func (s *CMSOutboxService) Publish(events ...*models.OutboxEvent) error {
+	return s.repo.CreateEvents(events)
+}
+
+func (s *CMSOutboxService) FindDeliveries(query dto.OutboxDeliveryQuery, page, limit int) ([]models.OutboxDelivery, int64, error) {
+	if query.EventType != "" && !isDomainEventType(query.EventType) {
+		return nil, 0, errs.ErrInvalidDomainEventType
+	}
+	if query.Status != "" {
+		switch query.Status {
+		case enums.OutboxDeliveryPending, enums.OutboxDeliverySucceeded, enums.OutboxDeliveryDead:
+		default:
+			return nil, 0, errs.ErrInvalidOutboxDeliveryStatus
+		}
+	}
+
+	return s.repo.FindDeliveries(query, page, limit)
+}
+
+func (s *CMSOutboxService) FindDeliveryByID(id uuid.UUID) (*models.OutboxDelivery, error) {
+	return s.repo.FindDeliveryByID(id)
+}
+
+// RetryDelivery queues a dead or pending delivery for the next round with a fresh set of attempts
+func (s *CMSOutboxService) RetryDelivery(id uuid.UUID) (*models.OutboxDelivery, error) {
+	delivery, err := s.repo.FindDeliveryByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == enums.OutboxDeliverySucceeded {
+		return nil, errs.ErrOutboxDeliverySucceeded
+	}
+
+	delivery.Status = enums.OutboxDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// DispatchDueDeliveries queues the new events for their subscribers, then handles the pending deliveries that are due,
+// at most Outbox.BatchSize of each, and returns how many deliveries were handled. A call while another one is running does nothing.
+func (s *CMSOutboxService) DispatchDueDeliveries(ctx context.Context) (int, error) {
+	if !s.dispatching.CompareAndSwap(false, true) {
+		return 0, nil
+	}
+	defer s.dispatching.Store(false)
+
+	batchSize := s.cfg.Outbox.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	concurrency := s.cfg.Outbox.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	if _, err := s.repo.FanOutEvents(batchSize, s.subscribersOf); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	deliveries, err := s.repo.ClaimDueDeliveries(now, batchSize, now.Add(s.cfg.Outbox.Lease))
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	for i := range deliveries {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(delivery *models.OutboxDelivery) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := s.handle(ctx, delivery); err != nil {
+				log.Printf("Failed to record outbox delivery %s: %v", delivery.ID, err)
+			}
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	s.cleanup()
+
+	return len(deliveries), nil
+}
+
+// StartDispatcher dispatches events every configured interval until the context is cancelled
+func (s *CMSOutboxService) StartDispatcher(ctx context.Context) {
+	if s.cfg.Outbox.PollInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Outbox.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A full batch means more may be due, keep going until the queue is drained
+			for {
+				handled, err := s.DispatchDueDeliveries(ctx)
+				if err != nil {
+					log.Printf("Outbox dispatch failed: %v", err)
+				}
+				if err != nil || handled < s.cfg.Outbox.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// handle runs the subscriber of the delivery once and saves the outcome, scheduling the retry when it failed
+func (s *CMSOutboxService) handle(ctx context.Context, delivery *models.OutboxDelivery) error {
+	delivery.Attempts++
+
+	handler, ok := s.handlerOf(delivery.Subscriber)
+	switch {
+	case delivery.Event == nil:
+		// Cleaned up since the delivery was claimed, its deliveries went with it
+		return nil
+	case !ok:
+		delivery.Status = enums.OutboxDeliveryDead
+		delivery.LastError = fmt.Sprintf("no subscriber named %s", delivery.Subscriber)
+		return s.repo.UpdateDelivery(delivery)
+	}
+
+	err := runDomainEventHandler(ctx, handler, delivery.Event)
+	switch {
+	case err == nil:
+		processedAt := time.Now()
+		delivery.Status = enums.OutboxDeliverySucceeded
+		delivery.LastError = ""
+		delivery.ProcessedAt = &processedAt
+	case delivery.Attempts >= s.cfg.Outbox.MaxAttempts:
+		log.Printf("Outbox delivery %s of %s to %s is dead after %d attempts: %v", delivery.ID, delivery.Event.EventType, delivery.Subscriber, delivery.Attempts, err)
+		delivery.Status = enums.OutboxDeliveryDead
+		delivery.LastError = err.Error()
+	default:
+		delivery.Status = enums.OutboxDeliveryPending
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = time.Now().Add(s.backoff(delivery.Attempts))
+	}
+
+	return s.repo.UpdateDelivery(delivery)
+}
+
+// runDomainEventHandler turns a panic of the handler into a failed attempt
+func runDomainEventHandler(ctx context.Context, handler DomainEventHandler, event *models.OutboxEvent) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler panicked: %v", recovered)
+		}
+	}()
+
+	return handler(ctx, event)
+}
+
+// cleanup removes the handled events older than Outbox.Retention, at most once per outboxCleanupInterval
+func (s *CMSOutboxService) cleanup() {
+	if s.cfg.Outbox.Retention <= 0 || time.Since(s.lastCleanup) < outboxCleanupInterval {
+		return
+	}
+	s.lastCleanup = time.Now()
+
+	if _, err := s.repo.DeleteHandledEventsBefore(time.Now().Add(-s.cfg.Outbox.Retention)); err != nil {
+		log.Printf("Failed to clean up outbox events: %v", err)
+	}
+}
+
+// backoff is the wait after the given number of failed attempts, doubling from Outbox.BaseBackoff up to Outbox.MaxBackoff
+func (s *CMSOutboxService) backoff(attempts int) time.Duration {
+	wait := s.cfg.Outbox.BaseBackoff
+	for i := 1; i < attempts && wait < s.cfg.Outbox.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if s.cfg.Outbox.MaxBackoff > 0 && wait > s.cfg.Outbox.MaxBackoff {
+		wait = s.cfg.Outbox.MaxBackoff
+	}
+	return wait
+}
+
+func isDomainEventType(eventType enums.DomainEventType) bool {
+	switch eventType {
+	case enums.DomainEventContentSaved, enums.DomainEventContentDeleted, enums.DomainEventFormSubmissionCreated,
+		enums.DomainEventMediaFileUploaded, enums.DomainEventMediaFileDeleted, enums.DomainEventEmailRequested:
+		return true
+	}
+	return false
+}
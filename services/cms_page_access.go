@@ -0,0 +1,140 @@
+package services
+
+import (
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// pageSaveActions are the actions of saving a content, saving it as published also publishes it
+func pageSaveActions(action enums.PermissionAction, status enums.WorkflowStatus) []enums.PermissionAction {
+	if status == enums.WorkflowPublished {
+		return []enums.PermissionAction{action, enums.PermissionActionPublish}
+	}
+	return []enums.PermissionAction{action}
+}
+
+// requestPageAccess is the access a service is scoped to for a request, a request without one may touch no page
+func requestPageAccess(access *dto.PageAccess) *dto.PageAccess {
+	if access == nil {
+		return dto.NoPageAccess()
+	}
+	return access
+}
+
+// pageContentSelector picks the contents of a page an action is about: one content, the current contents in one language,
+// or every current content of the page when it is empty.
+// A selector with every set needs the action on all the picked contents, as whole page actions do, instead of on one of them.
+type pageContentSelector struct {
+	contentId uuid.UUID
+	language  enums.PageLanguage
+	every     bool
+}
+
+func (s pageContentSelector) picks(scope dto.PageContentScope) bool {
+	if s.contentId != uuid.Nil {
+		return scope.ContentID == s.contentId
+	}
+	if scope.Mode == enums.PageModeHistories || scope.Mode == enums.PageModePreview {
+		return false
+	}
+	return s.language == "" || scope.Language == s.language
+}
+
+// allows reports whether the access allows the action on one of the picked contents, or on all of them when every is set
+func (s pageContentSelector) allows(access *dto.PageAccess, pageType enums.PageType, action enums.PermissionAction, scopes []dto.PageContentScope) bool {
+	picked := false
+	for _, scope := range scopes {
+		if !s.picks(scope) {
+			continue
+		}
+		picked = true
+		allowed := access.Allows(pageType, action, scope)
+		if allowed && !s.every {
+			return true
+		}
+		if !allowed && s.every {
+			return false
+		}
+	}
+	if picked && s.every {
+		return true
+	}
+
+	// No content in the language yet, so there are no categories to match either
+	return !picked && s.contentId == uuid.Nil && access.Allows(pageType, action, dto.PageContentScope{Language: s.language})
+}
+
+// checkPageAccess fails with ErrPageAccessDenied unless the access allows every action on the contents the selector picks.
+// The contents are only looked up when the access does not allow an action on every page.
+func checkPageAccess(access *dto.PageAccess, pageType enums.PageType, selector pageContentSelector, findScopes func() ([]dto.PageContentScope, error), actions ...enums.PermissionAction) error {
+	var scopes []dto.PageContentScope
+	loaded := false
+	for _, action := range actions {
+		if access.AllowsAll(pageType, action) {
+			continue
+		}
+		if !loaded {
+			var err error
+			if scopes, err = findScopes(); err != nil {
+				return err
+			}
+			loaded = true
+		}
+		if !selector.allows(access, pageType, action, scopes) {
+			return errs.ErrPageAccessDenied
+		}
+	}
+
+	return nil
+}
+
+// checkNewContentAccess fails with ErrPageAccessDenied unless the access allows every action on a content saved with the language and categories
+func checkNewContentAccess(access *dto.PageAccess, pageType enums.PageType, language enums.PageLanguage, categories []*models.Category, actions ...enums.PermissionAction) error {
+	scope := dto.PageContentScope{Language: language}
+	for _, category := range categories {
+		if category != nil {
+			scope.CategoryIDs = append(scope.CategoryIDs, category.ID)
+		}
+	}
+
+	for _, action := range actions {
+		if !access.Allows(pageType, action, scope) {
+			return errs.ErrPageAccessDenied
+		}
+	}
+
+	return nil
+}
+
+// checkTranslationAccess fails with ErrPageAccessDenied unless the access allows reading the content
+// and creating its copy in the other language, with the same categories
+func checkTranslationAccess(access *dto.PageAccess, pageType enums.PageType, contentId uuid.UUID, findScopes func() ([]dto.PageContentScope, error)) error {
+	if access.AllowsAll(pageType, enums.PermissionActionRead) && access.AllowsAll(pageType, enums.PermissionActionCreate) {
+		return nil
+	}
+
+	scopes, err := findScopes()
+	if err != nil {
+		return err
+	}
+	for _, scope := range scopes {
+		if scope.ContentID != contentId {
+			continue
+		}
+		translation := scope
+		if scope.Language == enums.PageLanguageTH {
+			translation.Language = enums.PageLanguageEN
+		} else {
+			translation.Language = enums.PageLanguageTH
+		}
+		if access.Allows(pageType, enums.PermissionActionRead, scope) && access.Allows(pageType, enums.PermissionActionCreate, translation) {
+			return nil
+		}
+	}
+
+	return errs.ErrPageAccessDenied
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/url"
@@ -24,9 +25,10 @@ import (
 type CMSPartnerPageServiceInterface interface {
 	CreatePartnerPage(PartnerPage *models.PartnerPage) (*models.PartnerPage, error)
 	FindPartnerPages(rawQuery string, sort string, page, limit int, language string) ([]models.PartnerPage, int64, error)
+	FindPartnerPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.PartnerPage, *dto.CursorPage, error)
 	FindPartnerPageById(id uuid.UUID) (*models.PartnerPage, error)
 	UpdatePartnerContent(updatedPartnerContent *models.PartnerContent, prevContentId uuid.UUID) (*models.PartnerContent, error)
-	DeletePartnerPage(id uuid.UUID) error
+	DeletePartnerPage(id uuid.UUID, force bool) error
 	FindContentByPartnerPageId(pageId uuid.UUID, language string, mode string) (*models.PartnerContent, error)
 	FindLatestContentByPageId(pageId uuid.UUID, language string) (*models.PartnerContent, error)
 	DeleteContentByPartnerPageId(pageId uuid.UUID, language, mode string) error
@@ -35,7 +37,8 @@ type CMSPartnerPageServiceInterface interface {
 	RevertPartnerContent(revisionId uuid.UUID, newRevision *models.Revision) (*models.PartnerContent, error)
 	GetCategory(pageId uuid.UUID, categoryTypeCode, language, mode string) ([]models.Category, error)
 	FindRevisions(pageId uuid.UUID, language string) ([]models.Revision, error)
-	PreviewPartnerContent(pageId uuid.UUID, partnerContentPreview *models.PartnerContent) (string, error)
+	PreviewPartnerContent(pageId uuid.UUID, partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error)
+	WithPageAccess(access *dto.PageAccess) CMSPartnerPageServiceInterface
 }
 
 type CMSPartnerPageService struct {
@@ -44,6 +47,7 @@ type CMSPartnerPageService struct {
 	emailContentRepo    repositories.EmailContentRepositoryInterface
 	emailCategoryRepo   repositories.EmailCategoryRepositoryInterface
 	cfg                 *config.Config
+	access              *dto.PageAccess // Pages the user of the request may touch, nil for any
 }
 
 func NewCMSPartnerPageService(
@@ -62,6 +66,36 @@ func NewCMSPartnerPageService(
 	}
 }
 
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSPartnerPageService) WithPageAccess(access *dto.PageAccess) CMSPartnerPageServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// checkAccess fails with ErrPageAccessDenied unless the page access allows the actions on the contents of the page the selector picks
+func (s *CMSPartnerPageService) checkAccess(pageId uuid.UUID, selector pageContentSelector, actions ...enums.PermissionAction) error {
+	return checkPageAccess(s.access, enums.PageTypePartner, selector, func() ([]dto.PageContentScope, error) {
+		return s.repo.FindContentScopes(pageId)
+	}, actions...)
+}
+
+// checkCopyPublishAudit runs the publish gate on a content that revert or duplicate copies along with its workflow status
+func (s *CMSPartnerPageService) checkCopyPublishAudit(contentId uuid.UUID) error {
+	if !publishAuditEnabled(s.cfg) {
+		return nil
+	}
+
+	content, err := s.repo.FindContentById(contentId)
+	if err != nil {
+		return err
+	}
+
+	return checkPublishAudit(s.cfg, content.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditPartnerContent(content)
+	})
+}
+
 // Always send only 1 content
 func (s *CMSPartnerPageService) CreatePartnerPage(PartnerPage *models.PartnerPage) (*models.PartnerPage, error) {
 	PartnerContents := PartnerPage.Contents
@@ -73,6 +107,11 @@ func (s *CMSPartnerPageService) CreatePartnerPage(PartnerPage *models.PartnerPag
 	// Only one content
 	PartnerContent := PartnerContents[0]
 
+	if err := checkNewContentAccess(s.access, enums.PageTypePartner, PartnerContent.Language, PartnerContent.Categories,
+		pageSaveActions(enums.PermissionActionCreate, PartnerContent.WorkflowStatus)...); err != nil {
+		return nil, err
+	}
+
 	// Check if the URL is duplicate or not
 	isDuplicate, err := s.repo.IsUrlDuplicate(PartnerContent.URL, uuid.Nil)
 	if err != nil {
@@ -108,6 +147,12 @@ func (s *CMSPartnerPageService) CreatePartnerPage(PartnerPage *models.PartnerPag
 		return nil, err
 	}
 
+	if err := checkPublishAudit(s.cfg, PartnerContent.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditPartnerContent(PartnerContent)
+	}); err != nil {
+		return nil, err
+	}
+
 	// Normalize categories if present
 	// for _, category := range PartnerContent.Categories {
 	// 	if err := helpers.NormalizeCategory(category); err != nil {
@@ -125,11 +170,28 @@ func (s *CMSPartnerPageService) FindPartnerPages(rawQuery string, sort string, p
 			return nil, 0, errs.ErrInvalidQuery
 		}
 	}
+	query.Access = s.access
 
 	return s.repo.FindAllPartnerPage(query, sort, page, limit, language)
 }
 
+func (s *CMSPartnerPageService) FindPartnerPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.PartnerPage, *dto.CursorPage, error) {
+	var query dto.PartnerPageQuery
+	if rawQuery != "" {
+		if err := json.Unmarshal([]byte(rawQuery), &query); err != nil {
+			return nil, nil, errs.ErrInvalidQuery
+		}
+	}
+	query.Access = s.access
+
+	return s.repo.FindPartnerPagesByCursor(query, sort, cursorQuery, language)
+}
+
 func (s *CMSPartnerPageService) FindPartnerPageById(id uuid.UUID) (*models.PartnerPage, error) {
+	if err := s.checkAccess(id, pageContentSelector{}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return s.repo.FindPartnerPageById(id)
 }
 
@@ -145,6 +207,15 @@ func (s *CMSPartnerPageService) UpdatePartnerContent(updatedPartnerContent *mode
 		return nil, err
 	}
 
+	// The saved content has to stay within the pages of the user too
+	actions := pageSaveActions(enums.PermissionActionUpdate, updatedPartnerContent.WorkflowStatus)
+	if err := s.checkAccess(partnerContentId, pageContentSelector{contentId: prevContentId}, actions...); err != nil {
+		return nil, err
+	}
+	if err := checkNewContentAccess(s.access, enums.PageTypePartner, updatedPartnerContent.Language, updatedPartnerContent.Categories, actions...); err != nil {
+		return nil, err
+	}
+
 	isURLDuplicate, err := s.repo.IsUrlDuplicate(updatedPartnerContent.URL, partnerContentId)
 	if err != nil {
 		return nil, err
@@ -166,6 +237,12 @@ func (s *CMSPartnerPageService) UpdatePartnerContent(updatedPartnerContent *mode
 		return nil, errs.ErrNoRevisionFound
 	}
 
+	if err := checkPublishAudit(s.cfg, updatedPartnerContent.WorkflowStatus, func() []dto.ContentAuditFinding {
+		return helpers.AuditPartnerContent(updatedPartnerContent)
+	}); err != nil {
+		return nil, err
+	}
+
 	log.Printf("[SERVICE-IN] Language from Request: '%s'", updatedPartnerContent.Language)
 
 	savedContent, err := s.repo.UpdatePartnerContent(updatedPartnerContent, prevContentId)
@@ -173,28 +250,46 @@ func (s *CMSPartnerPageService) UpdatePartnerContent(updatedPartnerContent *mode
 		return nil, err
 	}
 
-	if savedContent.WorkflowStatus == enums.WorkflowWaitingDesign && len(savedContent.ApprovalEmail) > 0 {
-		go s.triggerApprovalNotifications(savedContent)
-
-	}
-
 	log.Printf("[SERVICE-OUT] Language from Repo: '%s'", savedContent.Language)
 
 	return savedContent, nil
 }
 
+// HandleDomainEvent sends the approval notifications of a content saved as waiting for design
+func (s *CMSPartnerPageService) HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error {
+	var data dto.ContentEventData
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", event.EventType, err)
+	}
+	if data.PageType != enums.PageTypePartner || data.ContentID == nil ||
+		data.WorkflowStatus != enums.WorkflowWaitingDesign || len(data.ApprovalEmail) == 0 {
+		return nil
+	}
+
+	content := &models.PartnerContent{
+		ID:            *data.ContentID,
+		PageID:        data.PageID,
+		Language:      data.Language,
+		Title:         data.Title,
+		ApprovalEmail: data.ApprovalEmail,
+		Revision:      &models.Revision{Author: data.Author},
+	}
+	return s.triggerApprovalNotifications(event.ID.String(), content)
+}
+
 // triggerApprovalNotifications sends approval notifications to the relevant recipients.
-func (s *CMSPartnerPageService) triggerApprovalNotifications(content *models.PartnerContent) {
+// Each email is keyed on the event and its template, so handling the event again does not send it twice.
+func (s *CMSPartnerPageService) triggerApprovalNotifications(eventId string, content *models.PartnerContent) error {
 	// --- KEY CHANGE IS HERE ---
 	const approvalCategoryTitle = "Approve"
 	category, err := s.emailCategoryRepo.FindByTitle(approvalCategoryTitle)
 	if err != nil {
 		log.Printf("CRITICAL: Failed to query for Email Category '%s': %v. Notifications will not be sent.", approvalCategoryTitle, err)
-		return
+		return err
 	}
 	if category == nil {
 		log.Printf("CRITICAL: Email Category with title '%s' not found. Please create it in the CMS. Notifications will not be sent.", approvalCategoryTitle)
-		return
+		return nil
 	}
 	// --- END KEY CHANGE ---
 
@@ -207,12 +302,12 @@ func (s *CMSPartnerPageService) triggerApprovalNotifications(content *models.Par
 	templates, err := s.emailContentRepo.ListByFilters(filter)
 	if err != nil {
 		log.Printf("Error fetching email templates for Category '%s' (ID: %s): %v", approvalCategoryTitle, emailCategoryIDStr, err)
-		return
+		return err
 	}
 
 	if len(templates) == 0 {
 		log.Printf("No email templates found for Category '%s' and language '%s'. No notifications sent.", approvalCategoryTitle, content.Language)
-		return
+		return nil
 	}
 
 	previewURL, cmsEditURL := s.buildNotificationURLs("partner", content)
@@ -259,16 +354,17 @@ func (s *CMSPartnerPageService) triggerApprovalNotifications(content *models.Par
 			Language:               template.Language,
 			ToRecipientEmails:      recipients,
 			Data:                   emailData,
+			IdempotencyKey:         eventId + ":" + template.ID.String(),
 		}
 
-		go func(req dto.SendEmailRequest) {
-			if err := s.emailSendingService.SendEmail(req); err != nil {
-				log.Printf("Error sending email using template '%s': %v", req.EmailContentLabel, err)
-			} else {
-				log.Printf("Successfully queued email using template '%s' to: %v", req.EmailContentLabel, req.ToRecipientEmails)
-			}
-		}(emailReq)
+		if err := s.emailSendingService.SendEmail(emailReq); err != nil {
+			log.Printf("Error sending email using template '%s': %v", emailReq.EmailContentLabel, err)
+			return err
+		}
+		log.Printf("Successfully queued email using template '%s' to: %v", emailReq.EmailContentLabel, emailReq.ToRecipientEmails)
 	}
+
+	return nil
 }
 
 func (s *CMSPartnerPageService) buildNotificationURLs(pageType string, content *models.PartnerContent) (previewUrl, cmsEditUrl string) {
@@ -343,8 +439,13 @@ func (s *CMSPartnerPageService) getAuthorInfo(content *models.PartnerContent) (n
 	return
 }
 
-func (s *CMSPartnerPageService) DeletePartnerPage(id uuid.UUID) error {
-	return s.repo.DeletePartnerPage(id)
+// DeletePartnerPage deletes the page, unless forced it fails with ErrItemInUse while published content refers to it
+func (s *CMSPartnerPageService) DeletePartnerPage(id uuid.UUID, force bool) error {
+	if err := s.checkAccess(id, pageContentSelector{every: true}, enums.PermissionActionDelete); err != nil {
+		return err
+	}
+
+	return s.repo.DeletePartnerPage(id, force)
 }
 
 func (s *CMSPartnerPageService) FindContentByPartnerPageId(pageId uuid.UUID, language string, mode string) (*models.PartnerContent, error) {
@@ -358,6 +459,10 @@ func (s *CMSPartnerPageService) FindContentByPartnerPageId(pageId uuid.UUID, lan
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return s.repo.FindContentByPartnerPageId(pageId, language, mode)
 }
 
@@ -367,6 +472,10 @@ func (r *CMSPartnerPageService) FindLatestContentByPageId(pageId uuid.UUID, lang
 		return nil, err
 	}
 
+	if err := r.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	return r.repo.FindLatestContentByPageId(pageId, language)
 }
 
@@ -381,10 +490,19 @@ func (s *CMSPartnerPageService) DeleteContentByPartnerPageId(pageId uuid.UUID, l
 		return err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language), every: true}, enums.PermissionActionDelete); err != nil {
+		return err
+	}
+
 	return s.repo.DeletePartnerContent(pageId, language, mode)
 }
 
 func (s *CMSPartnerPageService) DuplicatePartnerPage(pageId uuid.UUID) (*models.PartnerPage, error) {
+	// The copy keeps the languages and categories of the page
+	if err := s.checkAccess(pageId, pageContentSelector{every: true}, enums.PermissionActionRead, enums.PermissionActionCreate); err != nil {
+		return nil, err
+	}
+
 	PartnerPage, err := s.repo.DuplicatePartnerPage(pageId)
 
 	if err != nil {
@@ -401,10 +519,39 @@ func (s *CMSPartnerPageService) DuplicatePartnerContentToAnotherLanguage(content
 		return nil, err
 	}
 
+	if !s.access.AllowsAll(enums.PageTypePartner, enums.PermissionActionRead) || !s.access.AllowsAll(enums.PageTypePartner, enums.PermissionActionCreate) {
+		pageId, err := s.repo.GetPageIdByContentId(contentId)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTranslationAccess(s.access, enums.PageTypePartner, contentId, func() ([]dto.PageContentScope, error) {
+			return s.repo.FindContentScopes(pageId)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkCopyPublishAudit(contentId); err != nil {
+		return nil, err
+	}
+
 	return s.repo.DuplicatePartnerContentToAnotherLanguage(contentId, newRevision)
 }
 
 func (r *CMSPartnerPageService) RevertPartnerContent(revisionId uuid.UUID, newRevision *models.Revision) (*models.PartnerContent, error) {
+	if !r.access.AllowsAll(enums.PageTypePartner, enums.PermissionActionUpdate) || publishAuditEnabled(r.cfg) {
+		pageId, contentId, err := r.repo.GetContentRefByRevisionId(revisionId)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.checkAccess(pageId, pageContentSelector{contentId: contentId}, enums.PermissionActionUpdate); err != nil {
+			return nil, err
+		}
+		if err := r.checkCopyPublishAudit(contentId); err != nil {
+			return nil, err
+		}
+	}
+
 	return r.repo.RevertPartnerContent(revisionId, newRevision)
 }
 
@@ -418,6 +565,10 @@ func (s *CMSPartnerPageService) GetCategory(pageId uuid.UUID, categoryTypeCode,
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	categories, err := s.repo.GetCategory(pageId, categoryTypeCode, language, mode)
 	if err != nil {
 		return nil, err
@@ -432,6 +583,10 @@ func (s *CMSPartnerPageService) FindRevisions(pageId uuid.UUID, language string)
 		return nil, err
 	}
 
+	if err := s.checkAccess(pageId, pageContentSelector{language: enums.PageLanguage(language)}, enums.PermissionActionRead); err != nil {
+		return nil, err
+	}
+
 	revisions, err := s.repo.GetRevisionByPartnerPageId(pageId, language)
 	if err != nil {
 		return nil, err
@@ -440,38 +595,37 @@ func (s *CMSPartnerPageService) FindRevisions(pageId uuid.UUID, language string)
 	return revisions, nil
 }
 
-func (s *CMSPartnerPageService) PreviewPartnerContent(pageId uuid.UUID, partnerContentPreview *models.PartnerContent) (string, error) {
-	urls := s.cfg.App.FrontendURLS // "http://localhost:8000,http://localhost:3000"
-	parts := strings.Split(urls, ",")
-	appUrl := parts[1] // "http://localhost:3000"
+func (s *CMSPartnerPageService) PreviewPartnerContent(pageId uuid.UUID, partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error) {
+	if err := s.checkAccess(pageId, pageContentSelector{language: partnerContentPreview.Language}, enums.PermissionActionUpdate); err != nil {
+		return nil, err
+	}
 
-	// Default value for preview content
+	// Default value for preview content, kept as long as the longest preview link can live
 	partnerContentPreview.Mode = enums.PageModePreview
 	partnerContentPreview.PublishStatus = enums.PublishStatusNotPublished
 	partnerContentPreview.WorkflowStatus = enums.WorkflowUnPublished
-	partnerContentPreview.ExpiredAt = time.Now().Add(2 * time.Hour)
-
+	partnerContentPreview.ExpiredAt = time.Now().Add(s.cfg.Preview.MaxTTL)
 	if err := helpers.NormalizePartnerContent(partnerContentPreview); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Check if the URL is duplicate or not
 	isUrlDuplicate, err := s.repo.IsUrlDuplicate(partnerContentPreview.URL, pageId)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if isUrlDuplicate {
-		return "", errs.ErrDuplicateURL
+		return nil, errs.ErrDuplicateURL
 	}
 
 	// Check if the URL Alias is duplicate or not
 	if partnerContentPreview.URLAlias != "" {
 		isUrlAliasDuplicate, err := s.repo.IsUrlAliasDuplicate(partnerContentPreview.URLAlias, pageId)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if isUrlAliasDuplicate {
-			return "", errs.ErrDuplicateUrlAlias
+			return nil, errs.ErrDuplicateUrlAlias
 		}
 	}
 
@@ -479,49 +633,17 @@ func (s *CMSPartnerPageService) PreviewPartnerContent(pageId uuid.UUID, partnerC
 
 	// Internal error
 	if err != nil && err != gorm.ErrRecordNotFound {
-		return "", err
+		return nil, err
 	}
 
 	// Record not found, so we create a new one
 	if err == gorm.ErrRecordNotFound {
 		partnerContentPreview.PageID = pageId
-		createdPartnerContentPreview, err := s.repo.CreatePartnerContentPreview(partnerContentPreview)
-		if err != nil {
-			return "", err
-		}
-
-		previewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(createdPartnerContentPreview.Language),
-			"partner",
-			createdPartnerContentPreview.ID,
-		)
-
-		if err != nil {
-			return "", err
-		}
-
-		return previewUrl, nil
+		return s.repo.CreatePartnerContentPreview(partnerContentPreview)
 	}
 
 	// Attach the old id, so we can save the new one in its place
 	partnerContentPreview.ID = existingPreviewContent.ID
 	partnerContentPreview.PageID = existingPreviewContent.PageID
-	updatedPartnerContentPreview, err := s.repo.UpdatePartnerContentPreview(partnerContentPreview)
-	if err != nil {
-		return "", err
-	}
-
-	previewUrl, err := helpers.BuildPreviewURL(
-		appUrl,
-		string(updatedPartnerContentPreview.Language),
-		"partner",
-		updatedPartnerContentPreview.ID,
-	)
-
-	if err != nil {
-		return "", err
-	}
-
-	return previewUrl, nil
+	return s.repo.UpdatePartnerContentPreview(partnerContentPreview)
 }
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type CMSPreviewLinkServiceInterface interface {
+	ParsePreviewTTL(raw string) (time.Duration, error)
+	IssuePreviewLink(request dto.IssuePreviewLinkRequest) (*dto.PreviewLinkResponse, error)
+	FindPreviewLinks(query dto.PreviewLinkQuery, page, limit int) ([]dto.PreviewLinkResponse, int64, error)
+	RevokePreviewLink(id uuid.UUID) error
+	ResolvePreviewToken(token, password string, pageType enums.PageType) (*models.PreviewLink, error)
+	WithPageAccess(access *dto.PageAccess) CMSPreviewLinkServiceInterface
+}
+
+type CMSPreviewLinkService struct {
+	repo     repositories.CMSPreviewLinkRepositoryInterface
+	cfg      *config.Config
+	access   *dto.PageAccess          // Pages the user of the request may see and revoke the links of, nil for any
+	attempts *previewPasswordAttempts // Shared by the scoped copies, a link locks whichever request guessed
+}
+
+// previewPasswordAttempts counts the wrong passwords sent to each link since its last lockout or right password
+type previewPasswordAttempts struct {
+	mu    sync.Mutex
+	links map[uuid.UUID]*previewPasswordFailures
+}
+
+type previewPasswordFailures struct {
+	count       int
+	lockedUntil time.Time
+}
+
+func NewCMSPreviewLinkService(repo repositories.CMSPreviewLinkRepositoryInterface, cfg *config.Config) *CMSPreviewLinkService {
+	return &CMSPreviewLinkService{
+		repo:     repo,
+		cfg:      cfg,
+		attempts: &previewPasswordAttempts{links: map[uuid.UUID]*previewPasswordFailures{}},
+	}
+}
+
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSPreviewLinkService) WithPageAccess(access *dto.PageAccess) CMSPreviewLinkServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+// ParsePreviewTTL reads a duration such as "30m" or "48h", blank uses the configured default
+func (s *CMSPreviewLinkService) ParsePreviewTTL(raw string) (time.Duration, error) {
+	if raw == "" {
+		return s.cfg.Preview.DefaultTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl <= 0 || ttl > s.cfg.Preview.MaxTTL {
+		return 0, errs.ErrInvalidPreviewTTL
+	}
+
+	return ttl, nil
+}
+
+// IssuePreviewLink stores a new preview link for the content and returns it with its signed url
+func (s *CMSPreviewLinkService) IssuePreviewLink(request dto.IssuePreviewLinkRequest) (*dto.PreviewLinkResponse, error) {
+	ttl := request.TTL
+	if ttl == 0 {
+		ttl = s.cfg.Preview.DefaultTTL
+	}
+	if ttl < 0 || ttl > s.cfg.Preview.MaxTTL {
+		return nil, errs.ErrInvalidPreviewTTL
+	}
+
+	// Whole seconds, so the token can be signed again identically from the stored link
+	now := time.Unix(time.Now().Unix(), 0)
+	previewLink := &models.PreviewLink{
+		ID:        uuid.New(),
+		PageType:  request.PageType,
+		PageID:    request.PageID,
+		ContentID: request.ContentID,
+		Language:  request.Language,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if request.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		previewLink.PasswordHash = string(hashedPassword)
+	}
+
+	// Build the url before saving, a misconfigured frontend should not leave unusable links behind
+	previewURL, err := s.buildPreviewURL(previewLink)
+	if err != nil {
+		return nil, err
+	}
+
+	createdPreviewLink, err := s.repo.CreatePreviewLink(previewLink)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toPreviewLinkResponse(createdPreviewLink)
+	response.URL = previewURL
+	return &response, nil
+}
+
+// FindPreviewLinks lists the issued links, the url is only returned for links that still work
+func (s *CMSPreviewLinkService) FindPreviewLinks(query dto.PreviewLinkQuery, page, limit int) ([]dto.PreviewLinkResponse, int64, error) {
+	if query.PageID != "" {
+		if _, err := uuid.Parse(query.PageID); err != nil {
+			return nil, 0, errs.ErrInvalidUUIDFormat
+		}
+	}
+	if query.PageType != "" {
+		switch query.PageType {
+		case enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq:
+		default:
+			return nil, 0, errs.ErrInvalidPageType
+		}
+	}
+
+	query.Access = s.access
+	previewLinks, totalCount, err := s.repo.FindPreviewLinks(query, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	responses := make([]dto.PreviewLinkResponse, 0, len(previewLinks))
+	for i := range previewLinks {
+		response := toPreviewLinkResponse(&previewLinks[i])
+		if previewLinks[i].RevokedAt == nil && previewLinks[i].ExpiresAt.After(now) {
+			previewURL, err := s.buildPreviewURL(&previewLinks[i])
+			if err != nil {
+				return nil, 0, err
+			}
+			response.URL = previewURL
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, totalCount, nil
+}
+
+func (s *CMSPreviewLinkService) RevokePreviewLink(id uuid.UUID) error {
+	// Revoking changes who can see the content, so it takes updating it
+	if s.access != nil {
+		previewLink, err := s.repo.FindPreviewLinkById(id)
+		if err != nil {
+			return err
+		}
+		if err := checkPageAccess(s.access, previewLink.PageType, pageContentSelector{contentId: previewLink.ContentID}, func() ([]dto.PageContentScope, error) {
+			return s.repo.FindContentScopes(previewLink.PageType, previewLink.PageID)
+		}, enums.PermissionActionUpdate); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RevokePreviewLink(id, time.Now())
+}
+
+// ResolvePreviewToken checks the token signature, expiry, revocation and password and returns the link it belongs to
+func (s *CMSPreviewLinkService) ResolvePreviewToken(token, password string, pageType enums.PageType) (*models.PreviewLink, error) {
+	claims, err := helpers.ParsePreviewToken(token, s.cfg.Preview.SecretKey)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errs.ErrPreviewLinkExpired
+		}
+		return nil, errs.ErrInvalidPreviewToken
+	}
+	if claims.PageType != string(pageType) {
+		return nil, errs.ErrInvalidPreviewToken
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, errs.ErrInvalidPreviewToken
+	}
+
+	previewLink, err := s.repo.FindPreviewLinkById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrInvalidPreviewToken
+		}
+		return nil, err
+	}
+	if previewLink.ContentID.String() != claims.ContentID {
+		return nil, errs.ErrInvalidPreviewToken
+	}
+	if previewLink.RevokedAt != nil {
+		return nil, errs.ErrPreviewLinkRevoked
+	}
+	if !previewLink.ExpiresAt.After(time.Now()) {
+		return nil, errs.ErrPreviewLinkExpired
+	}
+
+	if previewLink.PasswordHash != "" {
+		if password == "" {
+			return nil, errs.ErrPreviewPasswordRequired
+		}
+		// A locked link refuses even the right password, so guessing gets no answer until it unlocks
+		if s.passwordLocked(previewLink.ID) {
+			return nil, errs.ErrPreviewLinkLocked
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(previewLink.PasswordHash), []byte(password)); err != nil {
+			s.recordPasswordFailure(previewLink.ID)
+			return nil, errs.ErrInvalidPreviewPassword
+		}
+		s.resetPasswordFailures(previewLink.ID)
+	}
+
+	return previewLink, nil
+}
+
+func (s *CMSPreviewLinkService) passwordLocked(id uuid.UUID) bool {
+	if s.cfg.Preview.MaxPasswordAttempts <= 0 {
+		return false
+	}
+
+	s.attempts.mu.Lock()
+	defer s.attempts.mu.Unlock()
+
+	failures, ok := s.attempts.links[id]
+	if !ok {
+		return false
+	}
+	if time.Now().Before(failures.lockedUntil) {
+		return true
+	}
+	// Only links still being guessed stay in memory
+	if failures.count == 0 {
+		delete(s.attempts.links, id)
+	}
+	return false
+}
+
+// recordPasswordFailure counts a wrong password, the last one allowed locks the link and starts the count over
+func (s *CMSPreviewLinkService) recordPasswordFailure(id uuid.UUID) {
+	if s.cfg.Preview.MaxPasswordAttempts <= 0 {
+		return
+	}
+
+	s.attempts.mu.Lock()
+	defer s.attempts.mu.Unlock()
+
+	failures, ok := s.attempts.links[id]
+	if !ok {
+		failures = &previewPasswordFailures{}
+		s.attempts.links[id] = failures
+	}
+	failures.count++
+	if failures.count >= s.cfg.Preview.MaxPasswordAttempts {
+		failures.count = 0
+		failures.lockedUntil = time.Now().Add(s.cfg.Preview.PasswordLockout)
+	}
+}
+
+func (s *CMSPreviewLinkService) resetPasswordFailures(id uuid.UUID) {
+	s.attempts.mu.Lock()
+	defer s.attempts.mu.Unlock()
+	delete(s.attempts.links, id)
+}
+
+func (s *CMSPreviewLinkService) buildPreviewURL(previewLink *models.PreviewLink) (string, error) {
+	appUrl, err := helpers.PreviewFrontendURL(s.cfg.Preview.FrontendURL, s.cfg.App.FrontendURLS)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := helpers.SignPreviewToken(helpers.PreviewTokenClaims{
+		PageType:  string(previewLink.PageType),
+		ContentID: previewLink.ContentID.String(),
+		Language:  string(previewLink.Language),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        previewLink.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(previewLink.CreatedAt),
+			ExpiresAt: jwt.NewNumericDate(previewLink.ExpiresAt),
+		},
+	}, s.cfg.Preview.SecretKey)
+	if err != nil {
+		return "", err
+	}
+
+	return helpers.BuildPreviewURL(appUrl, string(previewLink.Language), string(previewLink.PageType), token)
+}
+
+func toPreviewLinkResponse(previewLink *models.PreviewLink) dto.PreviewLinkResponse {
+	return dto.PreviewLinkResponse{
+		ID:                previewLink.ID.String(),
+		PageType:          previewLink.PageType,
+		PageID:            previewLink.PageID.String(),
+		ContentID:         previewLink.ContentID.String(),
+		Language:          previewLink.Language,
+		PasswordProtected: previewLink.PasswordHash != "",
+		ExpiresAt:         previewLink.ExpiresAt,
+		RevokedAt:         previewLink.RevokedAt,
+		CreatedAt:         previewLink.CreatedAt,
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CMSRoleServiceInterface interface {
+	FindRoles() ([]models.Role, error)
+	AssignUserRole(userId uuid.UUID, roleName string) (*models.User, error)
+	HasPermission(roleName string, resource enums.PermissionResource, action enums.PermissionAction) (bool, error)
+	FindPageAccess(roleName string, userId uuid.UUID) (*dto.PageAccess, error)
+	FindPageGrants(userId uuid.UUID) ([]models.PageGrant, error)
+	CreatePageGrant(userId uuid.UUID, request dto.CreatePageGrantRequest) (*models.PageGrant, error)
+	DeletePageGrant(userId, grantId uuid.UUID) error
+}
+
+type CMSRoleService struct {
+	repo     repositories.CMSRoleRepositoryInterface
+	authRepo repositories.CMSAuthRepositoryInterface
+	cfg      *config.Config
+
+	mu       sync.RWMutex
+	roles    map[string]*models.Role // By name, every request checks a permission so they are kept in memory
+	loadedAt time.Time
+}
+
+func NewCMSRoleService(repo repositories.CMSRoleRepositoryInterface, authRepo repositories.CMSAuthRepositoryInterface, cfg *config.Config) *CMSRoleService {
+	return &CMSRoleService{
+		repo:     repo,
+		authRepo: authRepo,
+		cfg:      cfg,
+	}
+}
+
+func (s *CMSRoleService) FindRoles() ([]models.Role, error) {
+	return s.repo.FindRoles()
+}
+
+// AssignUserRole gives the user the role, an empty role name takes their role away
+func (s *CMSRoleService) AssignUserRole(userId uuid.UUID, roleName string) (*models.User, error) {
+	var roleId *uuid.UUID
+	if roleName != "" {
+		role, err := s.repo.FindRoleByName(roleName)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrInvalidRole
+		}
+		if err != nil {
+			return nil, err
+		}
+		roleId = &role.ID
+	}
+
+	if err := s.repo.UpdateUserRole(userId, roleId); err != nil {
+		return nil, err
+	}
+
+	user, err := s.authRepo.FindUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = nil
+
+	return user, nil
+}
+
+// rolesByName returns the cached roles, reloading them once they are older than the cache TTL
+func (s *CMSRoleService) rolesByName() (map[string]*models.Role, error) {
+	s.mu.RLock()
+	roles, loadedAt := s.roles, s.loadedAt
+	s.mu.RUnlock()
+	if roles != nil && time.Since(loadedAt) < s.cfg.Permission.CacheTTL {
+		return roles, nil
+	}
+
+	found, err := s.repo.FindRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	roles = make(map[string]*models.Role, len(found))
+	for i := range found {
+		roles[found[i].Name] = &found[i]
+	}
+
+	s.mu.Lock()
+	s.roles, s.loadedAt = roles, time.Now()
+	s.mu.Unlock()
+
+	return roles, nil
+}
+
+// HasPermission reports whether the role may do the action on the resource, an unknown role may do nothing
+func (s *CMSRoleService) HasPermission(roleName string, resource enums.PermissionResource, action enums.PermissionAction) (bool, error) {
+	if roleName == "" {
+		return false, nil
+	}
+
+	roles, err := s.rolesByName()
+	if err != nil {
+		return false, err
+	}
+
+	role, ok := roles[roleName]
+	return ok && role.Allows(resource, action), nil
+}
+
+// FindPageAccess returns what the user may do to pages beyond the permissions of their role:
+// publishing needs the publish permission, and their page grants limit which pages they reach
+func (s *CMSRoleService) FindPageAccess(roleName string, userId uuid.UUID) (*dto.PageAccess, error) {
+	canPublish, err := s.HasPermission(roleName, enums.PermissionResourcePages, enums.PermissionActionPublish)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.repo.FindPageGrantsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PageAccess{CanPublish: canPublish, Grants: grants}, nil
+}
+
+func (s *CMSRoleService) FindPageGrants(userId uuid.UUID) ([]models.PageGrant, error) {
+	if _, err := s.authRepo.FindUserById(userId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindPageGrantsByUserId(userId)
+}
+
+// CreatePageGrant gives the user a page grant, their first one limits them to the pages their grants match
+func (s *CMSRoleService) CreatePageGrant(userId uuid.UUID, request dto.CreatePageGrantRequest) (*models.PageGrant, error) {
+	grant := &models.PageGrant{
+		UserID:   userId,
+		PageType: enums.PageType(request.PageType),
+		Action:   enums.PermissionAction(request.Action),
+	}
+
+	switch grant.PageType {
+	case enums.PageTypeLanding, enums.PageTypePartner, enums.PageTypeFaq:
+	default:
+		return nil, errs.ErrInvalidPageGrant
+	}
+	switch grant.Action {
+	case enums.PermissionActionAll, enums.PermissionActionCreate, enums.PermissionActionRead,
+		enums.PermissionActionUpdate, enums.PermissionActionPublish, enums.PermissionActionDelete:
+	default:
+		return nil, errs.ErrInvalidPageGrant
+	}
+	if request.Language != "" {
+		language, err := helpers.NormalizeLanguage(request.Language)
+		if err != nil {
+			return nil, errs.ErrInvalidPageGrant
+		}
+		pageLanguage := enums.PageLanguage(language)
+		grant.Language = &pageLanguage
+	}
+	if request.CategoryID != nil && *request.CategoryID != "" {
+		categoryId, err := uuid.Parse(*request.CategoryID)
+		if err != nil {
+			return nil, errs.ErrInvalidPageGrant
+		}
+		grant.CategoryID = &categoryId
+	}
+
+	if _, err := s.authRepo.FindUserById(userId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreatePageGrant(grant)
+}
+
+// DeletePageGrant takes the grant away, a user without grants reaches every page their role allows again
+func (s *CMSRoleService) DeletePageGrant(userId, grantId uuid.UUID) error {
+	return s.repo.DeletePageGrant(userId, grantId)
+}
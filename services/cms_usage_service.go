@@ -0,0 +1,681 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+)
+
+type CMSUsageServiceInterface interface {
+	RebuildUsageIndex(ctx context.Context) (*dto.UsageIndexSummary, error)
+	GetUsages(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error)
+	GetDeleteImpact(itemType enums.UsageItemType, itemId uuid.UUID) (*dto.UsageImpact, error)
+	CheckDelete(itemType enums.UsageItemType, itemId uuid.UUID, force bool) (*dto.UsageImpact, error)
+	WithPageAccess(access *dto.PageAccess) CMSUsageServiceInterface
+}
+
+type CMSUsageService struct {
+	repo   repositories.CMSUsageRepositoryInterface
+	cfg    *config.Config
+	index  *usageIndexState // Shared with the copies WithPageAccess makes
+	access *dto.PageAccess  // Pages the user of the request may see the usages of and by, nil for any
+}
+
+// usageIndexState is when the index was last rebuilt
+type usageIndexState struct {
+	// mu serializes the rebuilds and the updates from events, a delete check waits for the running one instead of reading a stale index
+	mu        sync.Mutex
+	indexedAt time.Time
+}
+
+func NewCMSUsageService(repo repositories.CMSUsageRepositoryInterface, cfg *config.Config) *CMSUsageService {
+	return &CMSUsageService{repo: repo, cfg: cfg, index: &usageIndexState{}}
+}
+
+// WithPageAccess returns a copy of the service limited to the pages the access allows, a nil access allows none
+func (s *CMSUsageService) WithPageAccess(access *dto.PageAccess) CMSUsageServiceInterface {
+	scoped := *s
+	scoped.access = requestPageAccess(access)
+	return &scoped
+}
+
+var usageItemTypeByPageType = map[enums.PageType]enums.UsageItemType{
+	enums.PageTypeLanding: enums.UsageItemLandingPage,
+	enums.PageTypePartner: enums.UsageItemPartnerPage,
+	enums.PageTypeFaq:     enums.UsageItemFaqPage,
+}
+
+var pageTypeByUsageItemType = map[enums.UsageItemType]enums.PageType{
+	enums.UsageItemLandingPage: enums.PageTypeLanding,
+	enums.UsageItemPartnerPage: enums.PageTypePartner,
+	enums.UsageItemFaqPage:     enums.PageTypeFaq,
+}
+
+// usageItem is an item the links of a referrer were resolved to
+type usageItem struct {
+	itemType enums.UsageItemType
+	itemId   uuid.UUID
+}
+
+// usageReferrer describes the referrer the found references are indexed under
+type usageReferrer struct {
+	referrerType enums.UsageReferrerType
+	referrerId   uuid.UUID
+	pageType     enums.PageType
+	pageId       *uuid.UUID
+	language     enums.PageLanguage
+	label        string
+	published    bool
+}
+
+// usageIndexer collects the references of one rebuild, keyed so a field referring to an item twice is indexed once
+type usageIndexer struct {
+	cfg        *config.Config
+	mediaByURL map[string]uuid.UUID
+	pagesByURL map[string][]usageItem
+	seen       map[string]bool
+	references []models.UsageReference
+	indexedAt  time.Time
+}
+
+func (s *CMSUsageService) RebuildUsageIndex(ctx context.Context) (*dto.UsageIndexSummary, error) {
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	return s.rebuild(ctx)
+}
+
+// GetUsages lists where the item is used, from an index no older than the configured max age
+func (s *CMSUsageService) GetUsages(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error) {
+	if _, err := s.freshIndex(itemType); err != nil {
+		return nil, err
+	}
+	if err := s.checkItemAccess(itemType, itemId); err != nil {
+		return nil, err
+	}
+
+	references, err := s.repo.FindUsages(itemType, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.readableUsages(references)
+}
+
+// GetDeleteImpact reports the references a delete of the item would break, the published ones block it
+func (s *CMSUsageService) GetDeleteImpact(itemType enums.UsageItemType, itemId uuid.UUID) (*dto.UsageImpact, error) {
+	indexedAt, err := s.freshIndex(itemType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkItemAccess(itemType, itemId); err != nil {
+		return nil, err
+	}
+
+	references, err := s.repo.FindUsages(itemType, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &dto.UsageImpact{
+		ItemType:   itemType,
+		ItemID:     itemId,
+		IndexedAt:  indexedAt,
+		References: len(references),
+	}
+	for _, reference := range references {
+		if reference.Published {
+			impact.PublishedReferences++
+		}
+	}
+	impact.Blocked = impact.PublishedReferences > 0
+
+	// The counts take every reference, so a delete blocked by pages the user may not read still shows as blocked
+	if impact.Usages, err = s.readableUsages(references); err != nil {
+		return nil, err
+	}
+
+	return impact, nil
+}
+
+// checkItemAccess fails with ErrPageAccessDenied when the item is a page the access may not read
+func (s *CMSUsageService) checkItemAccess(itemType enums.UsageItemType, itemId uuid.UUID) error {
+	pageType, ok := pageTypeByUsageItemType[itemType]
+	if !ok {
+		return nil
+	}
+
+	return checkPageAccess(s.access, pageType, pageContentSelector{}, func() ([]dto.PageContentScope, error) {
+		return s.repo.FindContentScopes(pageType, itemId)
+	}, enums.PermissionActionRead)
+}
+
+// readableUsages leaves out the references by contents and relations of pages the access may not read
+func (s *CMSUsageService) readableUsages(references []models.UsageReference) ([]models.UsageReference, error) {
+	if s.access == nil {
+		return references, nil
+	}
+
+	scopesByPage := map[uuid.UUID][]dto.PageContentScope{}
+	readable := make([]models.UsageReference, 0, len(references))
+	for _, reference := range references {
+		if reference.PageID == nil {
+			readable = append(readable, reference)
+			continue
+		}
+
+		pageId := *reference.PageID
+		selector := pageContentSelector{language: reference.Language}
+		if reference.ReferrerType == enums.UsageReferrerContent {
+			selector = pageContentSelector{contentId: reference.ReferrerID}
+		}
+		err := checkPageAccess(s.access, reference.PageType, selector, func() ([]dto.PageContentScope, error) {
+			if scopes, ok := scopesByPage[pageId]; ok {
+				return scopes, nil
+			}
+			scopes, err := s.repo.FindContentScopes(reference.PageType, pageId)
+			scopesByPage[pageId] = scopes
+			return scopes, err
+		}, enums.PermissionActionRead)
+		if errors.Is(err, errs.ErrPageAccessDenied) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		readable = append(readable, reference)
+	}
+
+	return readable, nil
+}
+
+// CheckDelete returns ErrItemInUse along with the impact when published content still refers to the item, unless forced
+func (s *CMSUsageService) CheckDelete(itemType enums.UsageItemType, itemId uuid.UUID, force bool) (*dto.UsageImpact, error) {
+	impact, err := s.GetDeleteImpact(itemType, itemId)
+	if err != nil {
+		return nil, err
+	}
+	if impact.Blocked && !force {
+		return impact, errs.ErrItemInUse
+	}
+
+	return impact, nil
+}
+
+// StartScheduler rebuilds the usage index every configured interval until the context is cancelled
+func (s *CMSUsageService) StartScheduler(ctx context.Context) {
+	if s.cfg.Usage.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Usage.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			summary, err := s.RebuildUsageIndex(ctx)
+			if err != nil {
+				log.Printf("Scheduled usage index rebuild failed: %v", err)
+				continue
+			}
+			log.Printf("Scheduled usage index rebuild done: %d references from %d referrers",
+				summary.References, summary.ReferrersScanned)
+		}
+	}
+}
+
+// HandleDomainEvent updates the part of the index the event is about: the referrers of a saved or deleted page,
+// or the references to a deleted or replaced media file. Links elsewhere to a page whose url changed are resolved
+// again by the next rebuild, which the max age of the index bounds.
+func (s *CMSUsageService) HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error {
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	// Without an index the next lookup builds a whole one
+	if s.index.indexedAt.IsZero() {
+		return nil
+	}
+
+	switch event.EventType {
+	case enums.DomainEventContentSaved, enums.DomainEventContentDeleted:
+		var data dto.ContentEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return err
+		}
+		return s.reindexPage(dto.UsagePageScope{PageType: data.PageType, PageID: data.PageID, Language: data.Language})
+	case enums.DomainEventMediaFileUploaded, enums.DomainEventMediaFileDeleted:
+		var data dto.MediaFileEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return err
+		}
+		if event.EventType == enums.DomainEventMediaFileDeleted {
+			return s.repo.DeleteItemUsages(enums.UsageItemMediaFile, data.MediaFileID)
+		}
+		// A new file is not linked from anything yet, one taking the url of another takes over its references
+		if data.ReplacedID != nil {
+			return s.repo.MoveItemUsages(enums.UsageItemMediaFile, *data.ReplacedID, data.MediaFileID)
+		}
+	}
+	return nil
+}
+
+// reindexPage indexes the current contents and relations of the scoped page again, the caller holds mu
+func (s *CMSUsageService) reindexPage(scope dto.UsagePageScope) error {
+	if _, ok := usageItemTypeByPageType[scope.PageType]; !ok {
+		return nil
+	}
+
+	mediaFiles, err := s.repo.FindMediaFiles()
+	if err != nil {
+		return err
+	}
+	pagePaths, err := s.repo.FindCurrentPagePaths()
+	if err != nil {
+		return err
+	}
+
+	var landingContents []models.LandingContent
+	var partnerContents []models.PartnerContent
+	var faqContents []models.FaqContent
+	switch scope.PageType {
+	case enums.PageTypeLanding:
+		landingContents, err = s.repo.FindCurrentLandingContents(&scope)
+	case enums.PageTypePartner:
+		partnerContents, err = s.repo.FindCurrentPartnerContents(&scope)
+	case enums.PageTypeFaq:
+		faqContents, err = s.repo.FindCurrentFaqContents(&scope)
+	}
+	if err != nil {
+		return err
+	}
+	relations, err := s.repo.FindContentRelations(&scope)
+	if err != nil {
+		return err
+	}
+
+	indexer := newUsageIndexer(s.cfg, mediaFiles, time.Now())
+	for _, pagePath := range pagePaths {
+		indexer.addPagePath(pagePath.Path, usageItem{usageItemTypeByPageType[pagePath.PageType], pagePath.PageID})
+	}
+	indexer.addPages(landingContents, partnerContents, faqContents, relations)
+
+	return s.repo.ReplacePageUsages(scope, indexer.references)
+}
+
+func (s *CMSUsageService) freshIndex(itemType enums.UsageItemType) (time.Time, error) {
+	switch itemType {
+	case enums.UsageItemCategory, enums.UsageItemMediaFile, enums.UsageItemLandingPage, enums.UsageItemPartnerPage, enums.UsageItemFaqPage:
+	default:
+		return time.Time{}, errs.ErrInvalidUsageItemType
+	}
+
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	if s.index.indexedAt.IsZero() || time.Since(s.index.indexedAt) > s.cfg.Usage.MaxAge {
+		if _, err := s.rebuild(context.Background()); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return s.index.indexedAt, nil
+}
+
+// rebuild scans every referrer and replaces the index, the caller holds mu
+func (s *CMSUsageService) rebuild(ctx context.Context) (*dto.UsageIndexSummary, error) {
+	summary := &dto.UsageIndexSummary{StartedAt: time.Now()}
+
+	landingContents, err := s.repo.FindCurrentLandingContents(nil)
+	if err != nil {
+		return nil, err
+	}
+	partnerContents, err := s.repo.FindCurrentPartnerContents(nil)
+	if err != nil {
+		return nil, err
+	}
+	faqContents, err := s.repo.FindCurrentFaqContents(nil)
+	if err != nil {
+		return nil, err
+	}
+	relations, err := s.repo.FindContentRelations(nil)
+	if err != nil {
+		return nil, err
+	}
+	mediaFiles, err := s.repo.FindMediaFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	indexer := newUsageIndexer(s.cfg, mediaFiles, summary.StartedAt)
+	for _, content := range landingContents {
+		indexer.addPagePath(content.UrlAlias, usageItem{enums.UsageItemLandingPage, content.PageID})
+	}
+	for _, content := range partnerContents {
+		indexer.addPagePath(content.URL, usageItem{enums.UsageItemPartnerPage, content.PageID})
+		indexer.addPagePath(content.URLAlias, usageItem{enums.UsageItemPartnerPage, content.PageID})
+	}
+	for _, content := range faqContents {
+		indexer.addPagePath(content.URL, usageItem{enums.UsageItemFaqPage, content.PageID})
+		indexer.addPagePath(content.URLAlias, usageItem{enums.UsageItemFaqPage, content.PageID})
+	}
+	summary.ReferrersScanned += indexer.addPages(landingContents, partnerContents, faqContents, relations)
+
+	categories, err := s.repo.FindCategories()
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		referrer := usageReferrer{
+			referrerType: enums.UsageReferrerCategory,
+			referrerId:   category.ID,
+			language:     category.LanguageCode,
+			label:        category.Name,
+			published:    category.PublishStatus == enums.PublishStatusPublished,
+		}
+		if category.Description != nil {
+			indexer.addLinks(referrer, "description", helpers.ExtractHTMLLinks(*category.Description))
+		}
+	}
+	summary.ReferrersScanned += len(categories)
+
+	emailContents, err := s.repo.FindEmailContents()
+	if err != nil {
+		return nil, err
+	}
+	for _, emailContent := range emailContents {
+		label := emailContent.Label
+		if label == "" {
+			label = emailContent.Subject
+		}
+		// Templates in use are sent as they are, there is no draft of an email
+		referrer := usageReferrer{
+			referrerType: enums.UsageReferrerEmailContent,
+			referrerId:   emailContent.ID,
+			language:     emailContent.Language,
+			label:        label,
+			published:    true,
+		}
+		indexer.addLinks(referrer, "top_img_link", []string{emailContent.TopImgLink})
+		indexer.addLinks(referrer, "header", helpers.ExtractHTMLLinks(emailContent.Header))
+		indexer.addLinks(referrer, "paragraph", helpers.ExtractHTMLLinks(emailContent.Paragraph))
+		indexer.addLinks(referrer, "footer", helpers.ExtractHTMLLinks(emailContent.Footer))
+		indexer.addLinks(referrer, "footer_image_link", []string{emailContent.FooterImageLink})
+	}
+	summary.ReferrersScanned += len(emailContents)
+
+	forms, err := s.repo.FindForms()
+	if err != nil {
+		return nil, err
+	}
+	for _, form := range forms {
+		referrer := usageReferrer{
+			referrerType: enums.UsageReferrerForm,
+			referrerId:   form.ID,
+			label:        form.Name,
+			published:    true,
+		}
+		if form.Language != nil {
+			referrer.language = *form.Language
+		}
+		if form.Description != nil {
+			indexer.addLinks(referrer, "description", helpers.ExtractHTMLLinks(*form.Description))
+		}
+		for i, section := range form.Sections {
+			if section.Description != nil {
+				indexer.addLinks(referrer, fmt.Sprintf("sections[%d].description", i), helpers.ExtractHTMLLinks(*section.Description))
+			}
+			for j, field := range section.Fields {
+				indexer.addLinks(referrer, fmt.Sprintf("sections[%d].fields[%d].properties", i, j), helpers.ExtractComponentLinks(field.Properties))
+				indexer.addLinks(referrer, fmt.Sprintf("sections[%d].fields[%d].display", i, j), helpers.ExtractComponentLinks(field.Display))
+			}
+		}
+	}
+	summary.ReferrersScanned += len(forms)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceUsageIndex(indexer.references); err != nil {
+		return nil, err
+	}
+
+	s.index.indexedAt = summary.StartedAt
+	summary.References = len(indexer.references)
+	summary.FinishedAt = time.Now()
+	return summary, nil
+}
+
+func pageLanguageKey(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage) string {
+	return string(pageType) + "/" + pageId.String() + "/" + string(language)
+}
+
+func contentReferrer(pageType enums.PageType, pageId, contentId uuid.UUID, language enums.PageLanguage, title string, workflowStatus enums.WorkflowStatus) usageReferrer {
+	return usageReferrer{
+		referrerType: enums.UsageReferrerContent,
+		referrerId:   contentId,
+		pageType:     pageType,
+		pageId:       &pageId,
+		language:     language,
+		label:        title,
+		published:    workflowStatus == enums.WorkflowPublished,
+	}
+}
+
+func newUsageIndexer(cfg *config.Config, mediaFiles []models.MediaFile, indexedAt time.Time) *usageIndexer {
+	indexer := &usageIndexer{
+		cfg:        cfg,
+		mediaByURL: map[string]uuid.UUID{},
+		pagesByURL: map[string][]usageItem{},
+		seen:       map[string]bool{},
+		indexedAt:  indexedAt,
+	}
+	for _, mediaFile := range mediaFiles {
+		indexer.mediaByURL[mediaFile.DownloadURL] = mediaFile.ID
+		if parsed, err := url.Parse(mediaFile.DownloadURL); err == nil && parsed.Path != "" {
+			indexer.mediaByURL[parsed.Path] = mediaFile.ID
+		}
+	}
+	return indexer
+}
+
+// addPages indexes the contents and their relations, the page paths have to be added first. Returns the referrers scanned.
+func (i *usageIndexer) addPages(landingContents []models.LandingContent, partnerContents []models.PartnerContent, faqContents []models.FaqContent, relations []models.ContentRelation) int {
+	// Relations need every content first, they are labelled with the page and live when their content is
+	pageLabels := map[string]string{}
+	publishedContents := map[uuid.UUID]bool{}
+	register := func(pageType enums.PageType, pageId, contentId uuid.UUID, language enums.PageLanguage, title string, published bool) {
+		key := pageLanguageKey(pageType, pageId, language)
+		if published {
+			publishedContents[contentId] = true
+			pageLabels[key] = title
+		} else if _, ok := pageLabels[key]; !ok {
+			pageLabels[key] = title
+		}
+	}
+	for _, content := range landingContents {
+		register(enums.PageTypeLanding, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus == enums.WorkflowPublished)
+	}
+	for _, content := range partnerContents {
+		register(enums.PageTypePartner, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus == enums.WorkflowPublished)
+	}
+	for _, content := range faqContents {
+		register(enums.PageTypeFaq, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus == enums.WorkflowPublished)
+	}
+
+	for _, content := range landingContents {
+		referrer := contentReferrer(enums.PageTypeLanding, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus)
+		i.addCategories(referrer, content.Categories)
+		i.addLinks(referrer, "html_input", helpers.ExtractHTMLLinks(content.HTMLInput))
+		for index, file := range content.Files {
+			i.addLinks(referrer, fmt.Sprintf("files[%d]", index), []string{file.DownloadURL})
+		}
+		i.addMetaTag(referrer, content.MetaTag)
+		i.addComponents(referrer, content.Components)
+	}
+	for _, content := range partnerContents {
+		referrer := contentReferrer(enums.PageTypePartner, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus)
+		i.addCategories(referrer, content.Categories)
+		i.addLinks(referrer, "html_input", helpers.ExtractHTMLLinks(content.HTMLInput))
+		i.addLinks(referrer, "company_detail", helpers.ExtractHTMLLinks(content.CompanyDetail))
+		i.addLinks(referrer, "lead_body", helpers.ExtractHTMLLinks(content.LeadBody))
+		i.addLinks(referrer, "challenges", helpers.ExtractHTMLLinks(content.Challenges))
+		i.addLinks(referrer, "solutions", helpers.ExtractHTMLLinks(content.Solutions))
+		i.addLinks(referrer, "results", helpers.ExtractHTMLLinks(content.Results))
+		i.addLinks(referrer, "thumbnail_image", []string{content.ThumbnailImage})
+		i.addLinks(referrer, "company_logo", []string{content.CompanyLogo})
+		i.addMetaTag(referrer, content.MetaTag)
+		i.addComponents(referrer, content.Components)
+	}
+	for _, content := range faqContents {
+		referrer := contentReferrer(enums.PageTypeFaq, content.PageID, content.ID, content.Language, content.Title, content.WorkflowStatus)
+		i.addCategories(referrer, content.Categories)
+		i.addLinks(referrer, "html_input", helpers.ExtractHTMLLinks(content.HTMLInput))
+		i.addMetaTag(referrer, content.MetaTag)
+		i.addComponents(referrer, content.Components)
+	}
+	for _, relation := range relations {
+		sourcePageId := relation.SourcePageID
+		key := pageLanguageKey(relation.SourcePageType, relation.SourcePageID, relation.Language)
+		i.add(usageReferrer{
+			referrerType: enums.UsageReferrerRelation,
+			referrerId:   relation.ID,
+			pageType:     relation.SourcePageType,
+			pageId:       &sourcePageId,
+			language:     relation.Language,
+			label:        pageLabels[key],
+			published:    publishedContents[relation.SourceContentID],
+		}, string(relation.RelationType), usageItem{usageItemTypeByPageType[relation.TargetPageType], relation.TargetPageID})
+	}
+
+	return len(landingContents) + len(partnerContents) + len(faqContents) + len(relations)
+}
+
+func (i *usageIndexer) add(referrer usageReferrer, field string, item usageItem) {
+	// A page linking to itself does not keep it alive
+	if referrer.pageId != nil && *referrer.pageId == item.itemId {
+		return
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s/%s", item.itemType, item.itemId, referrer.referrerType, referrer.referrerId, field)
+	if i.seen[key] {
+		return
+	}
+	i.seen[key] = true
+
+	i.references = append(i.references, models.UsageReference{
+		ItemType:     item.itemType,
+		ItemID:       item.itemId,
+		ReferrerType: referrer.referrerType,
+		ReferrerID:   referrer.referrerId,
+		PageType:     referrer.pageType,
+		PageID:       referrer.pageId,
+		Language:     referrer.language,
+		Label:        referrer.label,
+		Field:        field,
+		Published:    referrer.published,
+		IndexedAt:    i.indexedAt,
+	})
+}
+
+func (i *usageIndexer) addPagePath(path string, item usageItem) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return
+	}
+	for _, existing := range i.pagesByURL[path] {
+		if existing == item {
+			return
+		}
+	}
+	i.pagesByURL[path] = append(i.pagesByURL[path], item)
+}
+
+func (i *usageIndexer) addCategories(referrer usageReferrer, categories []*models.Category) {
+	for _, category := range categories {
+		if category != nil {
+			i.add(referrer, "categories", usageItem{enums.UsageItemCategory, category.ID})
+		}
+	}
+}
+
+func (i *usageIndexer) addMetaTag(referrer usageReferrer, metaTag *models.MetaTag) {
+	if metaTag == nil {
+		return
+	}
+	i.addLinks(referrer, "meta_tag.cover_image", []string{metaTag.CoverImage})
+	i.addLinks(referrer, "meta_tag.og_image", []string{metaTag.OGImage})
+	i.addLinks(referrer, "meta_tag.twitter_image", []string{metaTag.TwitterImage})
+}
+
+func (i *usageIndexer) addComponents(referrer usageReferrer, components []*models.Component) {
+	for index, component := range components {
+		if component != nil {
+			i.addLinks(referrer, fmt.Sprintf("components[%d]", index), helpers.ExtractComponentLinks(component.Props))
+		}
+	}
+}
+
+func (i *usageIndexer) addLinks(referrer usageReferrer, field string, links []string) {
+	for _, link := range links {
+		link = strings.TrimSpace(link)
+		if !helpers.IsCheckableLink(link) {
+			continue
+		}
+		for _, item := range i.resolve(link) {
+			i.add(referrer, field, item)
+		}
+	}
+}
+
+// resolve maps a link to the media files and pages it points to, links to other sites point to nothing
+func (i *usageIndexer) resolve(link string) []usageItem {
+	if mediaId, ok := i.mediaByURL[link]; ok {
+		return []usageItem{{enums.UsageItemMediaFile, mediaId}}
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return nil
+	}
+	if parsed.Host != "" {
+		internal := false
+		for _, base := range []string{i.cfg.App.WebBaseURL, i.cfg.App.APIBaseURL} {
+			if baseURL, err := url.Parse(base); err == nil && baseURL.Host != "" && strings.EqualFold(baseURL.Host, parsed.Host) {
+				internal = true
+			}
+		}
+		if !internal {
+			return nil
+		}
+	}
+
+	if mediaId, ok := i.mediaByURL[parsed.Path]; ok && parsed.Path != "" {
+		return []usageItem{{enums.UsageItemMediaFile, mediaId}}
+	}
+
+	// Public pages live under /{language}/{url}
+	segments := strings.SplitN(strings.Trim(parsed.Path, "/"), "/", 2)
+	if _, langErr := helpers.NormalizeLanguage(segments[0]); langErr == nil {
+		segments = segments[1:]
+	}
+	return i.pagesByURL[strings.Join(segments, "/")]
+}
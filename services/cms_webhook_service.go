@@ -0,0 +1,509 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+// Response bodies are logged for debugging, the start is enough
+const webhookResponseBodyLimit = 1024
+
+type CMSWebhookServiceInterface interface {
+	CreateEndpoint(request dto.WebhookEndpointRequest) (*dto.WebhookEndpointSecretResponse, error)
+	FindEndpoints() ([]models.WebhookEndpoint, error)
+	FindEndpointByID(id uuid.UUID) (*models.WebhookEndpoint, error)
+	UpdateEndpoint(id uuid.UUID, request dto.WebhookEndpointRequest) (*models.WebhookEndpoint, error)
+	RotateEndpointSecret(id uuid.UUID) (*dto.WebhookEndpointSecretResponse, error)
+	DeleteEndpoint(id uuid.UUID) error
+	HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error
+	FindDeliveries(query dto.WebhookDeliveryQuery, page, limit int) ([]models.WebhookDelivery, int64, error)
+	FindDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error)
+	ReplayDelivery(id uuid.UUID) (*models.WebhookDelivery, error)
+	DispatchDueDeliveries(ctx context.Context) (int, error)
+}
+
+type CMSWebhookService struct {
+	repo        repositories.CMSWebhookRepositoryInterface
+	cfg         *config.Config
+	httpClient  *http.Client
+	dispatching atomic.Bool
+}
+
+func NewCMSWebhookService(repo repositories.CMSWebhookRepositoryInterface, cfg *config.Config) *CMSWebhookService {
+	return &CMSWebhookService{
+		repo:       repo,
+		cfg:        cfg,
+		httpClient: newGuardedHTTPClient(cfg.Webhook.Timeout, cfg.Webhook.AllowPrivateHosts),
+	}
+}
+
+func (s *CMSWebhookService) CreateEndpoint(request dto.WebhookEndpointRequest) (*dto.WebhookEndpointSecretResponse, error) {
+	endpoint := &models.WebhookEndpoint{IsActive: true}
+	if err := s.applyEndpointRequest(endpoint, request); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+
+	if err := s.repo.CreateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	return &dto.WebhookEndpointSecretResponse{WebhookEndpoint: *endpoint, Secret: secret}, nil
+}
+
+func (s *CMSWebhookService) FindEndpoints() ([]models.WebhookEndpoint, error) {
+	return s.repo.FindEndpoints()
+}
+
+func (s *CMSWebhookService) FindEndpointByID(id uuid.UUID) (*models.WebhookEndpoint, error) {
+	return s.repo.FindEndpointByID(id)
+}
+
+func (s *CMSWebhookService) UpdateEndpoint(id uuid.UUID, request dto.WebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.repo.FindEndpointByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyEndpointRequest(endpoint, request); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// RotateEndpointSecret replaces the signing secret, deliveries sent from now on are signed with the new one
+func (s *CMSWebhookService) RotateEndpointSecret(id uuid.UUID) (*dto.WebhookEndpointSecretResponse, error) {
+	endpoint, err := s.repo.FindEndpointByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+
+	if err := s.repo.UpdateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	return &dto.WebhookEndpointSecretResponse{WebhookEndpoint: *endpoint, Secret: secret}, nil
+}
+
+func (s *CMSWebhookService) DeleteEndpoint(id uuid.UUID) error {
+	return s.repo.DeleteEndpoint(id)
+}
+
+// HandleDomainEvent queues the webhook event matching a domain event for every active endpoint subscribed to it.
+// The domain event id is the webhook event id, so handling it again queues nothing new.
+func (s *CMSWebhookService) HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error {
+	var eventType enums.WebhookEventType
+	var data interface{}
+
+	switch event.EventType {
+	case enums.DomainEventContentSaved, enums.DomainEventContentDeleted:
+		var content dto.ContentEventData
+		if err := json.Unmarshal(event.Payload, &content); err != nil {
+			return err
+		}
+		eventType = contentWebhookEventType(event.EventType, content)
+		if eventType == "" {
+			return nil
+		}
+		data = s.contentWebhookData(eventType, content)
+	case enums.DomainEventFormSubmissionCreated:
+		var submission dto.FormSubmissionEventData
+		if err := json.Unmarshal(event.Payload, &submission); err != nil {
+			return err
+		}
+		eventType = enums.WebhookEventFormSubmitted
+		data = dto.WebhookFormSubmittedData{
+			FormID:        submission.FormID,
+			SubmissionID:  submission.SubmissionID,
+			SubmittedAt:   submission.SubmittedAt,
+			SubmittedData: submission.SubmittedData,
+		}
+	case enums.DomainEventMediaFileUploaded:
+		var file dto.MediaFileEventData
+		if err := json.Unmarshal(event.Payload, &file); err != nil {
+			return err
+		}
+		eventType = enums.WebhookEventMediaUploaded
+		data = dto.WebhookMediaUploadedData{
+			MediaFileID: file.MediaFileID.String(),
+			Name:        file.Name,
+			DownloadURL: file.DownloadURL,
+			Replaced:    file.Replaced,
+		}
+	default:
+		return nil
+	}
+
+	return s.enqueue(dto.WebhookEvent{ID: event.ID, Type: eventType, OccurredAt: event.CreatedAt.UTC(), Data: data})
+}
+
+// contentWebhookEventType reports a saved content as content.published or content.unpublished from its workflow status,
+// and a deleted page or published content as content.deleted. Drafts and history versions are not live, so they are not reported.
+func contentWebhookEventType(eventType enums.DomainEventType, content dto.ContentEventData) enums.WebhookEventType {
+	if eventType == enums.DomainEventContentDeleted {
+		if content.ContentID == nil || content.Mode == enums.PageModePublished {
+			return enums.WebhookEventContentDeleted
+		}
+		return ""
+	}
+
+	switch content.WorkflowStatus {
+	case enums.WorkflowPublished:
+		return enums.WebhookEventContentPublished
+	case enums.WorkflowUnPublished:
+		return enums.WebhookEventContentUnpublished
+	}
+	return ""
+}
+
+func (s *CMSWebhookService) contentWebhookData(eventType enums.WebhookEventType, content dto.ContentEventData) dto.WebhookContentData {
+	if eventType == enums.WebhookEventContentDeleted {
+		// Only the page and the language are left to report
+		return dto.WebhookContentData{PageType: content.PageType, PageID: content.PageID, Language: content.Language}
+	}
+
+	data := dto.WebhookContentData{
+		PageType:       content.PageType,
+		PageID:         content.PageID,
+		ContentID:      content.ContentID,
+		Language:       content.Language,
+		Title:          content.Title,
+		Path:           content.Path,
+		WorkflowStatus: content.WorkflowStatus,
+	}
+	if data.Language != "" && s.cfg.App.WebBaseURL != "" {
+		if pageURL, err := helpers.BuildContentURL(s.cfg.App.WebBaseURL, string(data.Language), data.Path); err == nil {
+			data.URL = pageURL
+		}
+	}
+	return data
+}
+
+func (s *CMSWebhookService) enqueue(event dto.WebhookEvent) error {
+	endpoints, err := s.repo.FindSubscribedEndpoints(event.Type)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       datatypes.JSON(payload),
+			Status:        enums.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	return s.repo.CreateDeliveries(deliveries)
+}
+
+func (s *CMSWebhookService) FindDeliveries(query dto.WebhookDeliveryQuery, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	if query.EndpointID != "" {
+		if _, err := uuid.Parse(query.EndpointID); err != nil {
+			return nil, 0, errs.ErrInvalidUUIDFormat
+		}
+	}
+	if query.EventType != "" && !isWebhookEventType(query.EventType) {
+		return nil, 0, errs.ErrInvalidWebhookEventType
+	}
+	if query.Status != "" {
+		switch query.Status {
+		case enums.WebhookDeliveryPending, enums.WebhookDeliverySucceeded, enums.WebhookDeliveryFailed:
+		default:
+			return nil, 0, errs.ErrInvalidWebhookDeliveryStatus
+		}
+	}
+
+	return s.repo.FindDeliveries(query, page, limit)
+}
+
+func (s *CMSWebhookService) FindDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error) {
+	return s.repo.FindDeliveryByID(id)
+}
+
+// ReplayDelivery queues the payload of a delivery again as a new delivery, with the same event id so
+// receivers can tell it apart from a new event
+func (s *CMSWebhookService) ReplayDelivery(id uuid.UUID) (*models.WebhookDelivery, error) {
+	original, err := s.repo.FindDeliveryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := []models.WebhookDelivery{{
+		EndpointID:    original.EndpointID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        enums.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+		ReplayOfID:    &original.ID,
+	}}
+	if err := s.repo.CreateDeliveries(replay); err != nil {
+		return nil, err
+	}
+
+	return &replay[0], nil
+}
+
+// DispatchDueDeliveries sends the pending deliveries that are due, at most Webhook.BatchSize of them,
+// and returns how many were attempted. A call while another one is sending does nothing.
+func (s *CMSWebhookService) DispatchDueDeliveries(ctx context.Context) (int, error) {
+	if !s.dispatching.CompareAndSwap(false, true) {
+		return 0, nil
+	}
+	defer s.dispatching.Store(false)
+
+	batchSize := s.cfg.Webhook.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	concurrency := s.cfg.Webhook.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	now := time.Now()
+	// Long enough for every request of the batch to time out before another instance may claim them
+	rounds := (batchSize + concurrency - 1) / concurrency
+	leaseUntil := now.Add(s.cfg.Webhook.Timeout*time.Duration(rounds) + time.Minute)
+
+	deliveries, err := s.repo.ClaimDueDeliveries(now, batchSize, leaseUntil)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	for i := range deliveries {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := s.deliver(ctx, delivery); err != nil {
+				log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+			}
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// StartDispatcher sends due deliveries every configured interval until the context is cancelled
+func (s *CMSWebhookService) StartDispatcher(ctx context.Context) {
+	if s.cfg.Webhook.PollInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Webhook.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A full batch means more may be due, keep going until the queue is drained
+			for {
+				sent, err := s.DispatchDueDeliveries(ctx)
+				if err != nil {
+					log.Printf("Webhook dispatch failed: %v", err)
+				}
+				if err != nil || sent < s.cfg.Webhook.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// deliver makes one attempt of the delivery and saves the outcome, scheduling the retry when it failed
+func (s *CMSWebhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	attempt := &models.WebhookDeliveryAttempt{
+		DeliveryID:  delivery.ID,
+		Attempt:     delivery.Attempts + 1,
+		AttemptedAt: time.Now(),
+	}
+
+	switch {
+	case delivery.Endpoint == nil:
+		// Deleted since the delivery was claimed, its deliveries went with it
+		return nil
+	case !delivery.Endpoint.IsActive:
+		attempt.Error = "webhook is disabled"
+	default:
+		attempt.StatusCode, attempt.ResponseBody, attempt.Error = s.post(ctx, delivery)
+	}
+	attempt.DurationMs = time.Since(attempt.AttemptedAt).Milliseconds()
+
+	delivery.Attempts = attempt.Attempt
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	switch {
+	case attempt.Error == "" && attempt.StatusCode >= 200 && attempt.StatusCode < 300:
+		deliveredAt := time.Now()
+		delivery.Status = enums.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &deliveredAt
+	case !delivery.Endpoint.IsActive || delivery.Attempts >= s.cfg.Webhook.MaxAttempts:
+		delivery.Status = enums.WebhookDeliveryFailed
+	default:
+		delivery.Status = enums.WebhookDeliveryPending
+		delivery.NextAttemptAt = time.Now().Add(s.backoff(delivery.Attempts))
+	}
+	if delivery.Status != enums.WebhookDeliverySucceeded && delivery.LastError == "" {
+		delivery.LastError = fmt.Sprintf("endpoint answered %d", attempt.StatusCode)
+	}
+
+	return s.repo.RecordAttempt(delivery, attempt)
+}
+
+// post sends the signed payload and returns the status code and the start of the body, or the error
+func (s *CMSWebhookService) post(ctx context.Context, delivery *models.WebhookDelivery) (int, string, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err.Error()
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.cfg.Webhook.UserAgent)
+	req.Header.Set("X-Webhook-Id", delivery.EventID.String())
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.String())
+	req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", helpers.SignWebhook(delivery.Endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	defer resp.Body.Close()
+
+	// The body of a redirect target is not the endpoint's answer, it is never stored
+	if resp.Request.URL.String() != req.URL.String() {
+		_, _ = io.CopyN(io.Discard, resp.Body, 4096)
+		return resp.StatusCode, "", fmt.Sprintf("endpoint redirected to %s", resp.Request.URL.Redacted())
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	// Drain a little more so the connection can be reused, never the whole body
+	_, _ = io.CopyN(io.Discard, resp.Body, 4096)
+
+	return resp.StatusCode, string(body), ""
+}
+
+// backoff is the wait after the given number of failed attempts, doubling from Webhook.BaseBackoff up to Webhook.MaxBackoff
+func (s *CMSWebhookService) backoff(attempts int) time.Duration {
+	wait := s.cfg.Webhook.BaseBackoff
+	for i := 1; i < attempts && wait < s.cfg.Webhook.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if s.cfg.Webhook.MaxBackoff > 0 && wait > s.cfg.Webhook.MaxBackoff {
+		wait = s.cfg.Webhook.MaxBackoff
+	}
+	return wait
+}
+
+func (s *CMSWebhookService) applyEndpointRequest(endpoint *models.WebhookEndpoint, request dto.WebhookEndpointRequest) error {
+	name := strings.TrimSpace(request.Name)
+	endpointURL := strings.TrimSpace(request.URL)
+	parsed, err := url.Parse(endpointURL)
+	if name == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" || parsed.User != nil || len(request.EventTypes) == 0 {
+		return errs.ErrInvalidWebhookEndpoint
+	}
+	// Hosts resolving to a blocked address are refused again when the delivery dials them
+	if !s.cfg.Webhook.AllowPrivateHosts && isBlockedHost(parsed.Hostname()) {
+		return errs.ErrInvalidWebhookEndpoint
+	}
+
+	eventTypes := pq.StringArray{}
+	seen := map[enums.WebhookEventType]bool{}
+	for _, eventType := range request.EventTypes {
+		if !isWebhookEventType(eventType) {
+			return errs.ErrInvalidWebhookEventType
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, string(eventType))
+		}
+	}
+
+	endpoint.Name = name
+	endpoint.URL = endpointURL
+	endpoint.EventTypes = eventTypes
+	if request.IsActive != nil {
+		endpoint.IsActive = *request.IsActive
+	}
+	return nil
+}
+
+func isWebhookEventType(eventType enums.WebhookEventType) bool {
+	switch eventType {
+	case enums.WebhookEventContentPublished, enums.WebhookEventContentUnpublished, enums.WebhookEventContentDeleted,
+		enums.WebhookEventFormSubmitted, enums.WebhookEventMediaUploaded:
+		return true
+	}
+	return false
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
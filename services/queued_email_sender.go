@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/google/uuid"
+)
+
+// QueuedEmailSender queues emails as email.requested events instead of sending them right away,
+// so a failed send is retried by the outbox and a request queued twice under one idempotency key is sent once
+type QueuedEmailSender struct {
+	publisher DomainEventPublisher
+	sender    EmailSendingServiceInterface
+}
+
+func NewQueuedEmailSender(publisher DomainEventPublisher, sender EmailSendingServiceInterface) *QueuedEmailSender {
+	return &QueuedEmailSender{
+		publisher: publisher,
+		sender:    sender,
+	}
+}
+
+func (s *QueuedEmailSender) SendEmail(req dto.SendEmailRequest) error {
+	event, err := helpers.NewOutboxEvent(enums.DomainEventEmailRequested, uuid.Nil, req.IdempotencyKey, req)
+	if err != nil {
+		return err
+	}
+
+	return s.publisher.Publish(event)
+}
+
+// HandleDomainEvent sends a queued email
+func (s *QueuedEmailSender) HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error {
+	var req dto.SendEmailRequest
+	if err := json.Unmarshal(event.Payload, &req); err != nil {
+		return fmt.Errorf("invalid email.requested payload: %w", err)
+	}
+
+	return s.sender.SendEmail(req)
+}
@@ -173,12 +173,16 @@ func TestCategoryLifecycle(t *testing.T) {
 		mock.ExpectQuery(`SELECT \* FROM "category_types"`).WithArgs(createdCategoryTypeID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(createdCategoryTypeID))
 
 		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemCategory, createdCategoryID, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "categories" WHERE "categories"."id" = $1`)).
 			WithArgs(createdCategoryID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
-		err := categoryService.DeleteCategoryByUUID(createdCategoryID.String())
+		err := categoryService.DeleteCategoryByUUID(createdCategoryID.String(), false)
 
 		require.NoError(t, err)
 		require.NoError(t, mock.ExpectationsWereMet())
@@ -1,9 +1,7 @@
 package integration
 
 import (
-	"fmt"
 	"regexp"
-	"strings"
 	"testing"
 	"time"
 
@@ -37,6 +35,9 @@ func setupFaqPageTest(t *testing.T) (
 			APIBaseURL:   "http://localhost:8080",
 			UploadPath:   t.TempDir(),
 		},
+		Preview: config.PreviewConfig{
+			MaxTTL: 7 * 24 * time.Hour,
+		},
 	}
 
 	service := services.NewCMSFaqPageService(repo, testCfg)
@@ -79,6 +80,8 @@ func TestFaqPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "faq_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"faq_content_id", "category_id"}).AddRow(uuid.New(), uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		resp, err := service.CreateFaqPage(mockFaqPage)
@@ -149,9 +152,14 @@ func TestFaqPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "faq_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"faq_content_id", "category_id"}).AddRow(uuid.New(), uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "faq_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WithArgs(sqlmock.AnyArg(), createdPageID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		resp, err := service.UpdateFaqContent(updatedContent, createdContentID)
@@ -260,6 +268,11 @@ func TestFaqPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "faq_contents"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(duplicatedContentID))
 		mock.ExpectQuery(`INSERT INTO "revisions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		_, err := service.DuplicateFaqContentToAnotherLanguage(createdContentID, newRev)
@@ -317,6 +330,8 @@ func TestFaqPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "revisions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newDuplicatedRevisionID))
 		mock.ExpectQuery(`INSERT INTO "components"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newDuplicatedComponentID))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 
 		// --- Act ---
@@ -356,6 +371,9 @@ func TestFaqPageLifecycle(t *testing.T) {
 			WithArgs(duplicatedContentID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		err := service.DeleteContentByFaqPageId(createdPageID, "th", "Draft")
@@ -372,6 +390,10 @@ func TestFaqPageLifecycle(t *testing.T) {
 			WithArgs(createdPageID, 1).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(createdPageID))
 
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemFaqPage, createdPageID, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "faq_contents" WHERE page_id = $1`)).
 			WithArgs(createdPageID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id"}).AddRow(createdContentID, createdPageID))
@@ -396,9 +418,12 @@ func TestFaqPageLifecycle(t *testing.T) {
 			WithArgs(createdPageID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
-		err := service.DeleteFaqPage(createdPageID)
+		err := service.DeleteFaqPage(createdPageID, false)
 		require.NoError(t, err)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -448,6 +473,8 @@ func TestFaqPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newCategoryTypeID))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
 		mock.ExpectQuery(`INSERT INTO "faq_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"faq_content_id", "category_id"}).AddRow(contentV1ID, categoryID))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		// --- Act ---
@@ -491,8 +518,13 @@ func TestFaqPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "faq_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"faq_content_id"}))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "faq_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		_, err := service.UpdateFaqContent(updatedContent, contentV1ID)
@@ -565,6 +597,11 @@ func TestFaqPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "faq_content_categories"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"faq_content_id", "category_id"}))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		// --- Act ---
@@ -642,6 +679,8 @@ func TestFaqPagePreviewFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "faq_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"faq_content_id"}))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		_, err := service.CreateFaqPage(mockFaqPage)
@@ -664,19 +703,20 @@ func TestFaqPagePreviewFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "meta_tags"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "faq_contents"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(previewContentID))
 		mock.ExpectQuery(`INSERT INTO "components"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(previewContentID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 
 		// --- Act ---
-		previewURL, err := service.PreviewFaqContent(pageID, previewContent)
+		savedPreview, err := service.PreviewFaqContent(pageID, previewContent)
 
 		// --- Assert ---
 		require.NoError(t, err)
-
-		frontendURLs := strings.Split(cfg.App.FrontendURLS, ",")
-		appURL := frontendURLs[1]
-		expectedURL := fmt.Sprintf("%s/preview/%s/%s?id=%s", appURL, string(previewContent.Language), "faq", previewContentID.String())
-
-		assert.Equal(t, expectedURL, previewURL)
+		assert.Equal(t, previewContentID, savedPreview.ID)
+		assert.Equal(t, enums.PageModePreview, savedPreview.Mode)
+		assert.WithinDuration(t, time.Now().Add(cfg.Preview.MaxTTL), savedPreview.ExpiredAt, time.Minute)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -711,19 +751,19 @@ func TestFaqPagePreviewFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "components"`).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(previewContentID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 
 		// --- Act ---
-		previewURL, err := service.PreviewFaqContent(pageID, updatedPreviewContent)
+		savedPreview, err := service.PreviewFaqContent(pageID, updatedPreviewContent)
 
 		// --- Assert ---
 		require.NoError(t, err)
-
-		frontendURLs := strings.Split(cfg.App.FrontendURLS, ",")
-		appURL := frontendURLs[1]
-		expectedURL := fmt.Sprintf("%s/preview/%s/%s?id=%s", appURL, "en", "faq", previewContentID.String())
-
-		assert.Equal(t, expectedURL, previewURL)
+		assert.Equal(t, previewContentID, savedPreview.ID)
+		assert.Equal(t, enums.PageModePreview, savedPreview.Mode)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -773,6 +813,8 @@ func TestFaqPageCategoryFlow(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"faq_content_id", "category_id"}).
 				AddRow(contentID, faqCategoryID).AddRow(contentID, keywordCategoryID))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		// --- Act ---
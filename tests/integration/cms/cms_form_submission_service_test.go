@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"regexp"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/repositories"
 	"github.com/MadManJJ/cms-api/services"
 
@@ -83,6 +85,8 @@ func TestFormSubmissionLifecycle(t *testing.T) {
 				sqlmock.AnyArg(), // updated_at
 			).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(tempSubmissionID))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		// Mock Preload("Form")
@@ -94,6 +98,24 @@ func TestFormSubmissionLifecycle(t *testing.T) {
 			WithArgs(testFormID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "email_category_id"}).AddRow(testFormID, emailCatID))
 
+		// --- Act ---
+		createdSubmission, err := submissionService.CreateFormSubmission(testFormID, submissionModel)
+
+		// --- Assert ---
+		require.NoError(t, err)
+		require.NotNil(t, createdSubmission)
+		createdSubmissionID = createdSubmission.ID
+		require.NoError(t, mock.ExpectationsWereMet())
+
+		// The emails are sent once the created event is dispatched
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "form_submissions" WHERE id = $1 ORDER BY "form_submissions"."id" LIMIT $2`)).
+			WithArgs(createdSubmissionID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "form_id", "submitted_email"}).AddRow(createdSubmissionID, testFormID, submittedEmail))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "forms" WHERE "forms"."id" = $1`)).
+			WithArgs(testFormID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email_category_id"}).AddRow(testFormID, emailCatID))
+
 		// Mock GetEmailContentsFormFormId
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "email_category_id" FROM "forms" WHERE id = $1 AND "forms"."deleted_at" IS NULL ORDER BY "forms"."id" LIMIT $2`)).
 			WithArgs(testFormID, 1).
@@ -107,13 +129,13 @@ func TestFormSubmissionLifecycle(t *testing.T) {
 			WithArgs(emailCatID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(emailCatID, "Test Category"))
 
-		// --- Act ---
-		createdSubmission, err := submissionService.CreateFormSubmission(testFormID, submissionModel)
-
-		// --- Assert ---
+		event, err := helpers.NewOutboxEvent(enums.DomainEventFormSubmissionCreated, createdSubmissionID, "", dto.FormSubmissionEventData{
+			FormID:       testFormID,
+			SubmissionID: createdSubmissionID,
+			SubmittedAt:  createdSubmission.SubmittedAt,
+		})
 		require.NoError(t, err)
-		require.NotNil(t, createdSubmission)
-		createdSubmissionID = createdSubmission.ID
+		require.NoError(t, submissionService.(*services.CMSFormSubmissionService).HandleDomainEvent(context.Background(), event))
 
 		select {
 		case <-mockEmailService.done:
@@ -153,7 +175,7 @@ func TestFormSubmissionLifecycle(t *testing.T) {
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "form_submissions"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "form_submissions" WHERE form_id = $1 ORDER BY created_at DESC LIMIT $2`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "form_submissions" WHERE form_id = $1 ORDER BY created_at DESC,id LIMIT $2`)).
 			WithArgs(testFormID, 10).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "form_id"}).
 				AddRow(createdSubmissionID, testFormID))
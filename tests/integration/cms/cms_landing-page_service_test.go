@@ -1,8 +1,8 @@
 package integration
 
 import (
+	"context"
 	"regexp"
-	"strings"
 	"testing"
 	"time"
 
@@ -69,6 +69,9 @@ func setupLandingPageTest(t *testing.T) (
 			APIBaseURL:   "http://localhost:8080",
 			UploadPath:   t.TempDir(),
 		},
+		Preview: config.PreviewConfig{
+			MaxTTL: 7 * 24 * time.Hour,
+		},
 	}
 
 	service := services.NewCMSLandingPageService(
@@ -116,6 +119,8 @@ func TestLandingPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "landing_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}).AddRow(uuid.New(), uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		resp, err := service.CreateLandingPage(mockLandingPage)
@@ -159,8 +164,13 @@ func TestLandingPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "landing_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}).AddRow(uuid.New(), uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "landing_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		resp, err := service.UpdateLandingContent(updatedContent, createdContentID)
@@ -257,6 +267,11 @@ func TestLandingPageLifecycle(t *testing.T) {
 
 		mock.ExpectQuery(`INSERT INTO "revisions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		_, err := service.DuplicateLandingContentToAnotherLanguage(createdContentID, newRev)
@@ -314,6 +329,8 @@ func TestLandingPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "revisions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newDuplicatedRevisionID))
 		mock.ExpectQuery(`INSERT INTO "components"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newDuplicatedComponentID))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 
 		// --- Act ---
@@ -353,6 +370,9 @@ func TestLandingPageLifecycle(t *testing.T) {
 			WithArgs(duplicatedContentID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		err := service.DeleteContentByLandingPageId(createdPageID, "th", "Draft")
@@ -369,6 +389,10 @@ func TestLandingPageLifecycle(t *testing.T) {
 			WithArgs(createdPageID, 1).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(createdPageID))
 
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemLandingPage, createdPageID, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_contents" WHERE page_id = $1`)).
 			WithArgs(createdPageID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id"}).AddRow(createdContentID, createdPageID))
@@ -396,9 +420,12 @@ func TestLandingPageLifecycle(t *testing.T) {
 			WithArgs(createdPageID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
-		err := service.DeleteLandingPage(createdPageID)
+		err := service.DeleteLandingPage(createdPageID, false)
 		require.NoError(t, err)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -441,6 +468,8 @@ func TestLandingPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newCategoryTypeID))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
 		mock.ExpectQuery(`INSERT INTO "landing_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}).AddRow(contentV1ID, categoryID))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		// --- Act ---
@@ -482,8 +511,13 @@ func TestLandingPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "landing_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"landing_content_id"}))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "landing_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		_, err := service.UpdateLandingContent(updatedContent, contentV1ID)
@@ -556,6 +590,11 @@ func TestLandingPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "landing_content_categories"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		// --- Act ---
@@ -636,6 +675,8 @@ func TestLandingPagePreviewFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "landing_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"landing_content_id"}))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		_, err := service.CreateLandingPage(mockLandingPage)
@@ -660,22 +701,20 @@ func TestLandingPagePreviewFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "meta_tags"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "landing_contents"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(previewContentID))
 		mock.ExpectQuery(`INSERT INTO "components"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(previewContentID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 
 		// --- Act ---
-		previewURL, err := service.PreviewLandingContent(pageID, previewContent)
+		savedPreview, err := service.PreviewLandingContent(pageID, previewContent)
 
 		// --- Assert ---
 		require.NoError(t, err)
-		frontendURLs := strings.Split(testCfg.App.FrontendURLS, ",")
-		appURL := frontendURLs[1]
-		expectedURL, err := helpers.BuildPreviewURL(
-			appURL,
-			string(previewContent.Language),
-			"landing",
-			previewContentID,
-		)
-		assert.Equal(t, expectedURL, previewURL)
+		assert.Equal(t, previewContentID, savedPreview.ID)
+		assert.Equal(t, enums.PageModePreview, savedPreview.Mode)
+		assert.WithinDuration(t, time.Now().Add(testCfg.Preview.MaxTTL), savedPreview.ExpiredAt, time.Minute)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -709,21 +748,18 @@ func TestLandingPagePreviewFlow(t *testing.T) {
 
 		mock.ExpectQuery(`INSERT INTO "components"`).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(previewContentID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 		// --- Act ---
-		previewURL, err := service.PreviewLandingContent(pageID, updatedPreviewContent)
+		savedPreview, err := service.PreviewLandingContent(pageID, updatedPreviewContent)
 
 		// --- Assert ---
 		require.NoError(t, err)
-		frontendURLs := strings.Split(testCfg.App.FrontendURLS, ",")
-		appURL := frontendURLs[1]
-		expectedURL, err := helpers.BuildPreviewURL(
-			appURL,
-			"en",
-			"landing",
-			previewContentID,
-		)
-		assert.Equal(t, expectedURL, previewURL)
+		assert.Equal(t, previewContentID, savedPreview.ID)
+		assert.Equal(t, enums.PageModePreview, savedPreview.Mode)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -766,6 +802,8 @@ func TestLandingPageCategoryFlow(t *testing.T) {
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "categories"`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(keywordCategoryID))
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "landing_content_categories"`)).WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		_, err := service.CreateLandingPage(mockLandingPage)
@@ -829,6 +867,8 @@ func TestLandingPagEmailLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "landing_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}).AddRow(uuid.New(), uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		resp, err := service.CreateLandingPage(mockLandingPage)
@@ -872,9 +912,14 @@ func TestLandingPagEmailLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "landing_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"landing_content_id"}))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "landing_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WithArgs(sqlmock.AnyArg(), createdPageID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		// --- Mock a final preload ---
@@ -905,6 +950,19 @@ func TestLandingPagEmailLifecycle(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "Updated Common Title", resp.Title)
 
+		// The approval email is sent once the saved event is dispatched
+		event, err := helpers.NewOutboxEvent(enums.DomainEventContentSaved, createdPageID, "", dto.ContentEventData{
+			PageType:       enums.PageTypeLanding,
+			PageID:         createdPageID,
+			ContentID:      &resp.ID,
+			Language:       resp.Language,
+			Title:          resp.Title,
+			WorkflowStatus: resp.WorkflowStatus,
+			ApprovalEmail:  resp.ApprovalEmail,
+		})
+		require.NoError(t, err)
+		require.NoError(t, service.(*services.CMSLandingPageService).HandleDomainEvent(context.Background(), event))
+
 		select {
 		case <-mockEmailService.done:
 		case <-time.After(2 * time.Second): // Timeout
@@ -10,6 +10,7 @@ import (
 	"github.com/MadManJJ/cms-api/config"
 	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/repositories"
 	"github.com/MadManJJ/cms-api/services"
 
@@ -69,6 +70,8 @@ func TestMediaFileLifecycle(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "media_files"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		resp, err := service.UploadMediaFile(filename, mimeType, fileData, customPath, &replace, testUserID)
@@ -110,6 +113,8 @@ func TestMediaFileLifecycle(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "media_files"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		resp, err := service.UploadMediaFile(filename, mimeType, fileData, customPath, &replace, testUserID)
@@ -141,11 +146,10 @@ func TestMediaFileLifecycle(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "media_files" WHERE id = $1`)).
 			WithArgs(createdFileID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
-		mock.ExpectCommit()
-
-		mock.ExpectBegin()
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "media_files"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(createdFileID))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		resp, err := service.UploadMediaFile(filename, mimeType, fileData, customPath, &replace, testUserID)
@@ -194,12 +198,20 @@ func TestMediaFileLifecycle(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "download_url"}).AddRow(createdFileID, createdFilename, createdDownloadURL))
 
 		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "media_files" WHERE id = \$1 ORDER BY "media_files"\."id" LIMIT \$2`).
+			WithArgs(createdFileID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "download_url"}).AddRow(createdFileID, createdFilename, createdDownloadURL))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemMediaFile, createdFileID, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "media_files" WHERE id = $1`)).
 			WithArgs(createdFileID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
-		err := service.DeleteMediaFile(createdFileID.String(), testUserID)
+		err := service.DeleteMediaFile(createdFileID.String(), testUserID, false)
 
 		require.NoError(t, err)
 
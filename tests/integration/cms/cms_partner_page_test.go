@@ -1,8 +1,8 @@
 package integration
 
 import (
+	"context"
 	"regexp"
-	"strings"
 	"testing"
 	"time"
 
@@ -68,6 +68,9 @@ func setupPartnerPageTest(t *testing.T) (
 			APIBaseURL:   "http://localhost:8080",
 			UploadPath:   t.TempDir(),
 		},
+		Preview: config.PreviewConfig{
+			MaxTTL: 7 * 24 * time.Hour,
+		},
 	}
 
 	// Create the service, injecting the manual mock which satisfies the interface
@@ -113,6 +116,8 @@ func TestPartnerPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "partner_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}).AddRow(uuid.New(), uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		_, err := service.CreatePartnerPage(mockPartnerPage)
@@ -155,9 +160,14 @@ func TestPartnerPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "partner_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}).AddRow(newContentID, uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "partner_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WithArgs(sqlmock.AnyArg(), createdPageID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "partner_contents" WHERE id = $1 AND "partner_contents"."id" = $2 ORDER BY "partner_contents"."id" LIMIT $3`)).
@@ -238,6 +248,11 @@ func TestPartnerPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "meta_tags"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "partner_contents"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(duplicatedContentID))
 		mock.ExpectQuery(`INSERT INTO "revisions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		_, err := service.DuplicatePartnerContentToAnotherLanguage(createdContentID, newRev)
@@ -270,6 +285,8 @@ func TestPartnerPageLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "partner_contents"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newDuplicatedContentID))
 		mock.ExpectQuery(`INSERT INTO "revisions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newDuplicatedRevisionID))
 		mock.ExpectQuery(`INSERT INTO "components"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newDuplicatedComponentID))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 
 		_, err := service.DuplicatePartnerPage(createdPageID)
@@ -287,6 +304,9 @@ func TestPartnerPageLifecycle(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "revisions"`)).WithArgs(duplicatedContentID).WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "partner_content_categories"`)).WithArgs(duplicatedContentID).WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "partner_contents" WHERE "partner_contents"."id" = $1`)).WithArgs(duplicatedContentID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		err := service.DeleteContentByPartnerPageId(createdPageID, "th", "Draft")
@@ -300,6 +320,10 @@ func TestPartnerPageLifecycle(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "partner_pages" WHERE id = $1 ORDER BY "partner_pages"."id" LIMIT $2`)).
 			WithArgs(createdPageID, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(createdPageID))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemPartnerPage, createdPageID, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "partner_contents" WHERE page_id = $1`)).
 			WithArgs(createdPageID).WillReturnRows(sqlmock.NewRows([]string{"id", "page_id"}).AddRow(createdContentID, createdPageID))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "components" WHERE partner_content_id IN ($1)`)).
@@ -312,9 +336,12 @@ func TestPartnerPageLifecycle(t *testing.T) {
 			WithArgs(createdPageID).WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "partner_pages" WHERE id = $1`)).
 			WithArgs(createdPageID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
-		err := service.DeletePartnerPage(createdPageID)
+		err := service.DeletePartnerPage(createdPageID, false)
 		require.NoError(t, err)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -361,6 +388,8 @@ func TestPartnerPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newCategoryTypeID))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
 		mock.ExpectQuery(`INSERT INTO "partner_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}).AddRow(contentV1ID, categoryID))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		// --- Act ---
@@ -405,8 +434,13 @@ func TestPartnerPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "partner_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"partner_content_id"}))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "partner_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		_, err := service.UpdatePartnerContent(updatedContent, contentV1ID)
@@ -479,6 +513,11 @@ func TestPartnerPageRevertFlow(t *testing.T) {
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "partner_content_categories"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		// --- Act ---
@@ -563,6 +602,8 @@ func TestPartnerPagePreviewFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "partner_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"partner_content_id"}))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		_, err := service.CreatePartnerPage(mockPartnerPage)
@@ -592,22 +633,20 @@ func TestPartnerPagePreviewFlow(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "meta_tags"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "partner_contents"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(previewContentID))
 		mock.ExpectQuery(`INSERT INTO "components"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(previewContentID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 
 		// --- Act ---
-		previewURL, err := service.PreviewPartnerContent(pageID, previewContent)
+		savedPreview, err := service.PreviewPartnerContent(pageID, previewContent)
 
 		// --- Assert ---
 		require.NoError(t, err)
-		frontendURLs := strings.Split(testCfg.App.FrontendURLS, ",")
-		appURL := frontendURLs[1]
-		expectedURL, err := helpers.BuildPreviewURL(
-			appURL,
-			string(previewContent.Language), // หรือ "en"
-			"partner",
-			previewContentID,
-		)
-		assert.Equal(t, expectedURL, previewURL)
+		assert.Equal(t, previewContentID, savedPreview.ID)
+		assert.Equal(t, enums.PageModePreview, savedPreview.Mode)
+		assert.WithinDuration(t, time.Now().Add(testCfg.Preview.MaxTTL), savedPreview.ExpiredAt, time.Minute)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -644,21 +683,18 @@ func TestPartnerPagePreviewFlow(t *testing.T) {
 
 		mock.ExpectQuery(`INSERT INTO "components"`).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(previewContentID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 		// --- Act ---
-		previewURL, err := service.PreviewPartnerContent(pageID, updatedPreviewContent)
+		savedPreview, err := service.PreviewPartnerContent(pageID, updatedPreviewContent)
 
 		// --- Assert ---
 		require.NoError(t, err)
-		frontendURLs := strings.Split(testCfg.App.FrontendURLS, ",")
-		appURL := frontendURLs[1]
-		expectedURL, err := helpers.BuildPreviewURL(
-			appURL,
-			"en",
-			"partner",
-			previewContentID,
-		)
-		assert.Equal(t, expectedURL, previewURL)
+		assert.Equal(t, previewContentID, savedPreview.ID)
+		assert.Equal(t, enums.PageModePreview, savedPreview.Mode)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -703,6 +739,8 @@ func TestPartnerPageCategoryFlow(t *testing.T) {
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "categories"`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(keywordCategoryID))
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "partner_content_categories"`)).WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		_, err := service.CreatePartnerPage(mockPartnerPage)
@@ -769,6 +807,8 @@ func TestPartnerPagEmailLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "partner_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}).AddRow(uuid.New(), uuid.New()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		resp, err := service.CreatePartnerPage(mockPartnerPage)
@@ -816,9 +856,14 @@ func TestPartnerPagEmailLifecycle(t *testing.T) {
 		mock.ExpectQuery(`INSERT INTO "category_types"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 		mock.ExpectQuery(`INSERT INTO "partner_content_categories"`).WillReturnRows(sqlmock.NewRows([]string{"partner_content_id"}))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "partner_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WithArgs(sqlmock.AnyArg(), createdPageID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		// --- Mock a final preload ---
@@ -847,6 +892,20 @@ func TestPartnerPagEmailLifecycle(t *testing.T) {
 		// --- Assert ---
 		require.NoError(t, err)
 		assert.Equal(t, "Updated Common Title", resp.Title)
+
+		// The approval email is sent once the saved event is dispatched
+		event, err := helpers.NewOutboxEvent(enums.DomainEventContentSaved, createdPageID, "", dto.ContentEventData{
+			PageType:       enums.PageTypePartner,
+			PageID:         createdPageID,
+			ContentID:      &resp.ID,
+			Language:       resp.Language,
+			Title:          resp.Title,
+			WorkflowStatus: resp.WorkflowStatus,
+			ApprovalEmail:  resp.ApprovalEmail,
+		})
+		require.NoError(t, err)
+		require.NoError(t, service.(*services.CMSPartnerPageService).HandleDomainEvent(context.Background(), event))
+
 		select {
 		case <-mockEmailService.done:
 		case <-time.After(2 * time.Second): // Timeout
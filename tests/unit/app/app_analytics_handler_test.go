@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	appHandler "github.com/MadManJJ/cms-api/handlers/app"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAppAnalyticsService struct {
+	mock.Mock
+}
+
+func (m *MockAppAnalyticsService) TrackPageView(request dto.PageViewRequest, clientIP, userAgent string) error {
+	args := m.Called(request, clientIP, userAgent)
+	return args.Error(0)
+}
+
+func (m *MockAppAnalyticsService) GetPageAnalytics(pageType enums.PageType, pageId uuid.UUID, query dto.AnalyticsQuery) (*dto.PageAnalytics, error) {
+	args := m.Called(pageType, pageId, query)
+	return nil, args.Error(1)
+}
+
+func (m *MockAppAnalyticsService) GetTopPages(query dto.AnalyticsQuery, limit int) ([]dto.TopPage, error) {
+	args := m.Called(query, limit)
+	return nil, args.Error(1)
+}
+
+func (m *MockAppAnalyticsService) GetCampaignTraffic(query dto.AnalyticsQuery, limit int) ([]dto.CampaignTraffic, error) {
+	args := m.Called(query, limit)
+	return nil, args.Error(1)
+}
+
+func TestAppAnalyticsHandler(t *testing.T) {
+	mockService := &MockAppAnalyticsService{}
+	handler := appHandler.NewAppAnalyticsHandler(mockService)
+
+	app := fiber.New()
+	app.Post("/app/analytics/views", handler.HandleTrackPageView)
+
+	pageId := uuid.New()
+	body := `{"page_type":"landing","page_id":"` + pageId.String() + `","language":"en","utm_campaign":"summer-sale"}`
+
+	postView := func(contentType string, headers map[string]string) int {
+		req := httptest.NewRequest("POST", "/app/analytics/views", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("User-Agent", "Mozilla")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	t.Run("POST /app/analytics/views HandleTrackPageView", func(t *testing.T) {
+		t.Run("successfully track a beacon sent as text/plain", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			request := dto.PageViewRequest{PageType: enums.PageTypeLanding, PageID: pageId.String(), Language: enums.PageLanguageEN, UTMCampaign: "summer-sale"}
+			mockService.On("TrackPageView", request, "0.0.0.0", "Mozilla").Return(nil)
+
+			assert.Equal(t, fiber.StatusAccepted, postView("text/plain;charset=UTF-8", nil))
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("successfully skip tracking when the client opts out", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			assert.Equal(t, fiber.StatusAccepted, postView("application/json", map[string]string{"DNT": "1"}))
+			assert.Equal(t, fiber.StatusAccepted, postView("application/json", map[string]string{"Sec-GPC": "1"}))
+			mockService.AssertNotCalled(t, "TrackPageView", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		t.Run("failed to track page view: invalid page view", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("TrackPageView", mock.Anything, mock.Anything, mock.Anything).Return(errs.ErrInvalidPageType)
+
+			assert.Equal(t, fiber.StatusBadRequest, postView("application/json", nil))
+		})
+	})
+}
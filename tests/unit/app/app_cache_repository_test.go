@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppRepo_NotifyInvalidation(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	appCacheRepo := repo.NewAppCacheRepository(gormDB)
+
+	t.Run("successfully notify the invalidation on the cache channel", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_notify($1, $2)`)).
+			WithArgs(repo.AppCacheChannel, "form:1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := appCacheRepo.NotifyInvalidation("form:1")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to notify the invalidation", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_notify($1, $2)`)).
+			WillReturnError(errs.ErrInternalServerError)
+
+		err := appCacheRepo.NotifyInvalidation("*")
+		assert.Error(t, err)
+	})
+}
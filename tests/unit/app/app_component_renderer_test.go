@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func newRenderComponent(componentType enums.ComponentType, props string) *models.Component {
+	return &models.Component{ID: uuid.New(), Type: componentType, Props: datatypes.JSON(props)}
+}
+
+func TestComponentRenderer(t *testing.T) {
+	cfg := &config.Config{Render: config.RenderConfig{CacheSize: 2}}
+	renderer, err := services.NewComponentRenderer(cfg)
+	require.NoError(t, err)
+
+	t.Run("RenderComponents", func(t *testing.T) {
+		t.Run("successfully render grouped templates in order", func(t *testing.T) {
+			heading := newRenderComponent(enums.ComponentH21, `{"text":"Welcome"}`)
+			button := newRenderComponent(enums.ComponentLargeGreenLinkButton, `{"label":"Apply","url":"https://example.com/apply"}`)
+
+			html, err := renderer.RenderComponents("", []*models.Component{heading, button})
+			assert.NoError(t, err)
+			assert.Contains(t, html, `<div class="cms-component cms-H21" data-component-id="`+heading.ID.String()+`"><h2>Welcome</h2></div>`)
+			assert.Contains(t, html, `<a class="cms-button" href="https://example.com/apply">Apply</a>`)
+			assert.Less(t, strings.Index(html, "Welcome"), strings.Index(html, "Apply"))
+		})
+
+		t.Run("successfully escape plain text and sanitize rich text", func(t *testing.T) {
+			heading := newRenderComponent(enums.ComponentH31, `{"text":"<script>alert(1)</script>"}`)
+			text := newRenderComponent(enums.ComponentNormalText, `{"html":"<p class=\"lead\">Hi<script>alert(1)</script></p>"}`)
+			link := newRenderComponent(enums.ComponentLargeWhiteLinkButton, `{"label":"Go","url":"javascript:alert(1)"}`)
+
+			html, err := renderer.RenderComponents("", []*models.Component{heading, text, link})
+			assert.NoError(t, err)
+			assert.NotContains(t, html, "<script>")
+			assert.Contains(t, html, "&lt;script&gt;")
+			assert.Contains(t, html, `<p class="lead">Hi</p>`)
+			assert.NotContains(t, html, "javascript:")
+		})
+
+		t.Run("successfully render unknown types and nested children with the fallback", func(t *testing.T) {
+			component := newRenderComponent(enums.ComponentX0201, `{"title":"Card","components":[{"type":"H22","props":{"text":"Inner"}}]}`)
+
+			html, err := renderer.RenderComponents("", []*models.Component{component})
+			assert.NoError(t, err)
+			assert.Contains(t, html, "<h3>Card</h3>")
+			assert.Contains(t, html, `<div class="cms-component cms-H22"><h2>Inner</h2></div>`)
+		})
+
+		t.Run("successfully serve repeated renders from the cache", func(t *testing.T) {
+			first := []*models.Component{newRenderComponent(enums.ComponentH21, `{"text":"First"}`)}
+			second := []*models.Component{newRenderComponent(enums.ComponentH21, `{"text":"Second"}`)}
+
+			html, err := renderer.RenderComponents("content:1", first)
+			assert.NoError(t, err)
+			assert.Contains(t, html, "First")
+
+			html, err = renderer.RenderComponents("content:1", second)
+			assert.NoError(t, err)
+			assert.Contains(t, html, "First")
+
+			html, err = renderer.RenderComponents("content:2", second)
+			assert.NoError(t, err)
+			assert.Contains(t, html, "Second")
+		})
+	})
+
+	t.Run("NewComponentRenderer", func(t *testing.T) {
+		t.Run("successfully override a template from the templates directory", func(t *testing.T) {
+			dir := t.TempDir()
+			override := `{{define "heading2"}}<h2 class="brand">{{str (prop .Props "text")}}</h2>{{end}}{{define "X0201"}}<section>{{str (prop .Props "title")}}</section>{{end}}`
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "brand.tmpl"), []byte(override), 0o644))
+
+			overridden, err := services.NewComponentRenderer(&config.Config{Render: config.RenderConfig{TemplatesDir: dir}})
+			require.NoError(t, err)
+
+			html, err := overridden.RenderComponents("", []*models.Component{
+				newRenderComponent(enums.ComponentH23, `{"text":"Brand"}`),
+				newRenderComponent(enums.ComponentX0201, `{"title":"Own"}`),
+			})
+			assert.NoError(t, err)
+			assert.Contains(t, html, `<h2 class="brand">Brand</h2>`)
+			assert.Contains(t, html, "<section>Own</section>")
+		})
+
+		t.Run("failed to parse an invalid override", func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.tmpl"), []byte(`{{define "heading2"}}{{.Props`), 0o644))
+
+			_, err := services.NewComponentRenderer(&config.Config{Render: config.RenderConfig{TemplatesDir: dir}})
+			assert.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	appHandler "github.com/MadManJJ/cms-api/handlers/app"
+	"github.com/MadManJJ/cms-api/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockAppFaqFeedbackService struct {
+	mock.Mock
+}
+
+func (m *MockAppFaqFeedbackService) RecordFeedback(contentId uuid.UUID, request dto.FaqFeedbackRequest, clientIP, userAgent string) error {
+	args := m.Called(contentId, request, clientIP, userAgent)
+	return args.Error(0)
+}
+
+func (m *MockAppFaqFeedbackService) GetHelpfulnessSeries(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error) {
+	args := m.Called(query, interval)
+	return nil, args.Error(1)
+}
+
+func (m *MockAppFaqFeedbackService) GetLowestRated(query dto.FaqFeedbackQuery, minVotes, limit int) ([]dto.FaqHelpfulnessRank, error) {
+	args := m.Called(query, minVotes, limit)
+	return nil, args.Error(1)
+}
+
+func (m *MockAppFaqFeedbackService) GetFeedbackComments(query dto.FaqFeedbackQuery, page, limit int) ([]dto.FaqFeedbackComment, int64, error) {
+	args := m.Called(query, page, limit)
+	return nil, 0, args.Error(2)
+}
+
+func postFaqFeedback(app *fiber.App, path, body string) int {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mozilla")
+	resp, _ := app.Test(req)
+	return resp.StatusCode
+}
+
+func TestAppFaqFeedbackHandler(t *testing.T) {
+	mockService := &MockAppFaqFeedbackService{}
+	handler := appHandler.NewAppFaqFeedbackHandler(mockService)
+
+	app := fiber.New()
+	app.Post("/app/faqpages/feedback/:contentId", handler.HandleCreateFaqFeedback)
+
+	contentId := uuid.New()
+	url := "/app/faqpages/feedback/" + contentId.String()
+
+	t.Run("POST /app/faqpages/feedback/:contentId HandleCreateFaqFeedback", func(t *testing.T) {
+		t.Run("successfully record feedback", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			helpful := true
+			mockService.On("RecordFeedback", contentId, dto.FaqFeedbackRequest{Helpful: &helpful, Feedback: "Clear"}, "0.0.0.0", "Mozilla").Return(nil)
+
+			assert.Equal(t, fiber.StatusCreated, postFaqFeedback(app, url, `{"helpful":true,"feedback":"Clear"}`))
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to record feedback: invalid contentId", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			assert.Equal(t, fiber.StatusBadRequest, postFaqFeedback(app, "/app/faqpages/feedback/abc", `{"helpful":true}`))
+			mockService.AssertNotCalled(t, "RecordFeedback", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		t.Run("failed to record feedback: invalid feedback", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RecordFeedback", contentId, mock.Anything, mock.Anything, mock.Anything).Return(errs.ErrFeedbackTooLong)
+
+			assert.Equal(t, fiber.StatusBadRequest, postFaqFeedback(app, url, `{"helpful":false,"feedback":"Too long"}`))
+		})
+
+		t.Run("failed to record feedback: content not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RecordFeedback", contentId, mock.Anything, mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+
+			assert.Equal(t, fiber.StatusNotFound, postFaqFeedback(app, url, `{"helpful":false}`))
+		})
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		t.Run("failed to record feedback: too many votes from the client", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			mockService.On("RecordFeedback", contentId, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			limited := fiber.New()
+			limited.Post("/app/faqpages/feedback/:contentId", middleware.RateLimit(2, time.Minute), handler.HandleCreateFaqFeedback)
+
+			assert.Equal(t, fiber.StatusCreated, postFaqFeedback(limited, url, `{"helpful":true}`))
+			assert.Equal(t, fiber.StatusCreated, postFaqFeedback(limited, url, `{"helpful":true}`))
+			assert.Equal(t, fiber.StatusTooManyRequests, postFaqFeedback(limited, url, `{"helpful":true}`))
+			mockService.AssertNumberOfCalls(t, "RecordFeedback", 2)
+		})
+
+		t.Run("successfully pass through when disabled", func(t *testing.T) {
+			unlimited := fiber.New()
+			unlimited.Post("/app/faqpages/feedback/:contentId", middleware.RateLimit(0, time.Minute), handler.HandleCreateFaqFeedback)
+
+			for i := 0; i < 3; i++ {
+				assert.Equal(t, fiber.StatusCreated, postFaqFeedback(unlimited, url, `{"helpful":true}`))
+			}
+		})
+	})
+}
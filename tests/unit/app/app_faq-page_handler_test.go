@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	appHandler "github.com/MadManJJ/cms-api/handlers/app"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -21,40 +25,112 @@ type MockAppFaqPageService struct {
 	mock.Mock
 }
 
-func (m *MockAppFaqPageService) GetFaqPage(slug string, isAlias bool, selectParam string, language string) (*models.FaqPage, error) {
-	args := m.Called(slug, isAlias, selectParam, language)
+func (m *MockAppFaqPageService) GetFaqPage(slug string, isAlias bool, selection dto.FieldSelection, language string) (*models.FaqPage, error) {
+	args := m.Called(slug, isAlias, selection, language)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.FaqPage), args.Error(1)	
 }
 
-func (m *MockAppFaqPageService) GetFaqContentPreview(id uuid.UUID) (*models.FaqContent, error) {
-	args := m.Called(id)
+func (m *MockAppFaqPageService) GetFaqContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.FaqContent, error) {
+	args := m.Called(id, selection)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.FaqContent), args.Error(1)	
 }
 
+func (m *MockAppFaqPageService) GetFaqCategoryTree(language string, typeCode string) ([]dto.FaqCategoryTypeNode, error) {
+	args := m.Called(language, typeCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.FaqCategoryTypeNode), args.Error(1)
+}
+
+func (m *MockAppFaqPageService) GetFaqsGroupedByCategory(language string, typeCode string, perCategory int) ([]dto.FaqCategoryGroup, error) {
+	args := m.Called(language, typeCode, perCategory)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.FaqCategoryGroup), args.Error(1)
+}
+
+func (m *MockAppFaqPageService) GetFaqsByCategory(language string, categoryId uuid.UUID, page, limit int, fields []string) (*dto.FaqCategoryNode, []dto.FaqSummary, int64, error) {
+	args := m.Called(language, categoryId, page, limit, fields)
+	if args.Get(0) == nil {
+		return nil, nil, 0, args.Error(3)
+	}
+	return args.Get(0).(*dto.FaqCategoryNode), args.Get(1).([]dto.FaqSummary), args.Get(2).(int64), args.Error(3)
+}
+
+// MockPreviewLinkService resolves preview tokens for the app preview handlers
+type MockPreviewLinkService struct {
+	mock.Mock
+}
+
+func (m *MockPreviewLinkService) ParsePreviewTTL(raw string) (time.Duration, error) {
+	args := m.Called(raw)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockPreviewLinkService) IssuePreviewLink(request dto.IssuePreviewLinkRequest) (*dto.PreviewLinkResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PreviewLinkResponse), args.Error(1)
+}
+
+func (m *MockPreviewLinkService) FindPreviewLinks(query dto.PreviewLinkQuery, page, limit int) ([]dto.PreviewLinkResponse, int64, error) {
+	args := m.Called(query, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]dto.PreviewLinkResponse), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockPreviewLinkService) RevokePreviewLink(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPreviewLinkService) ResolvePreviewToken(token, password string, pageType enums.PageType) (*models.PreviewLink, error) {
+	args := m.Called(token, password, pageType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PreviewLink), args.Error(1)
+}
+
+func (m *MockPreviewLinkService) WithPageAccess(access *dto.PageAccess) services.CMSPreviewLinkServiceInterface {
+	return m
+}
+
 func TestAppFaqHandler(t *testing.T) {
 	mockService := &MockAppFaqPageService{}
-	handler := appHandler.NewAppFaqPageHandler(mockService)
+	mockPreviewLinkService := &MockPreviewLinkService{}
+	handler := appHandler.NewAppFaqPageHandler(mockService, mockPreviewLinkService, &MockComponentRenderer{}, services.NewAppResponseCache(nil, &config.Config{}))
 
 	app := fiber.New()
 	app.Get("/app/faqpages/:languageCode/by-alias", handler.HandleGetFaqPageByAlias)
 	app.Get("/app/faqpages/:languageCode/by-url", handler.HandleGetFaqPageByUrl)
-	app.Get("/app/faqpages/previews/:id", handler.HandleGetFaqContentPreview)
+	app.Get("/app/faqpages/previews/:token", handler.HandleGetFaqContentPreview)
+	app.Get("/app/faqpages/:languageCode/categories", handler.HandleGetFaqCategoryTree)
+	app.Get("/app/faqpages/:languageCode/categories/:categoryId", handler.HandleGetFaqsByCategory)
+	app.Get("/app/faqpages/:languageCode/grouped", handler.HandleGetFaqsGroupedByCategory)
 
 	t.Run("GET /app/faqpages/:languageCode/by-alias HandleGetFaqPageByAlias", func(t *testing.T) {
 		mockFaqPage := helpers.InitializeMockFaqPage()
 		slug := "about/us"
 		selectParam := "revision,categories,components,metatag"
+		selection := dto.FieldSelection{Includes: map[string][]string{"revision": nil, "categories": nil, "components": nil, "meta_tag": nil}}
 		language := string(enums.PageLanguageEN)
 
 		t.Run("successfully get faq page (url_alias)", func(t *testing.T) {
 			isAlias := true
-			mockService.On("GetFaqPage", slug, isAlias, selectParam, language).Return(mockFaqPage, nil)		
+			mockService.On("GetFaqPage", slug, isAlias, selection, language).Return(mockFaqPage, nil)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/%s/by-alias?url_alias=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -67,7 +143,7 @@ func TestAppFaqHandler(t *testing.T) {
 		t.Run("failed to get faq page: internal server error (url_alias)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := true
-			mockService.On("GetFaqPage", slug, isAlias, selectParam, language).Return(nil, errs.ErrInternalServerError)		
+			mockService.On("GetFaqPage", slug, isAlias, selection, language).Return(nil, errs.ErrInternalServerError)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/%s/by-alias?url_alias=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -80,7 +156,7 @@ func TestAppFaqHandler(t *testing.T) {
 		t.Run("failed to get faq page: not found (url_alias)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := true
-			mockService.On("GetFaqPage", slug, isAlias, selectParam, language).Return(nil, errs.ErrNotFound)		
+			mockService.On("GetFaqPage", slug, isAlias, selection, language).Return(nil, errs.ErrNotFound)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/%s/by-alias?url_alias=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -95,12 +171,13 @@ func TestAppFaqHandler(t *testing.T) {
 		mockFaqPage := helpers.InitializeMockFaqPage()
 		slug := "about/us"
 		selectParam := "revision,categories,components,metatag"
+		selection := dto.FieldSelection{Includes: map[string][]string{"revision": nil, "categories": nil, "components": nil, "meta_tag": nil}}
 		language := string(enums.PageLanguageEN)
 				
 		t.Run("successfully get faq page (url)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := false
-			mockService.On("GetFaqPage", slug, isAlias, selectParam, language).Return(mockFaqPage, nil)		
+			mockService.On("GetFaqPage", slug, isAlias, selection, language).Return(mockFaqPage, nil)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/%s/by-url?url=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -113,7 +190,7 @@ func TestAppFaqHandler(t *testing.T) {
 		t.Run("failed get faq page: internal server error (url)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := false
-			mockService.On("GetFaqPage", slug, isAlias, selectParam, language).Return(nil, errs.ErrInternalServerError)		
+			mockService.On("GetFaqPage", slug, isAlias, selection, language).Return(nil, errs.ErrInternalServerError)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/%s/by-url?url=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -126,7 +203,7 @@ func TestAppFaqHandler(t *testing.T) {
 		t.Run("failed get faq page: not found (url)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := false
-			mockService.On("GetFaqPage", slug, isAlias, selectParam, language).Return(nil, errs.ErrNotFound)		
+			mockService.On("GetFaqPage", slug, isAlias, selection, language).Return(nil, errs.ErrNotFound)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/%s/by-url?url=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -137,33 +214,159 @@ func TestAppFaqHandler(t *testing.T) {
 		})	
 	})
 
-	t.Run("GET /app/faqpages/previews/:id HandleGetFaqContentPreview", func(t *testing.T) {
+	t.Run("GET /app/faqpages/previews/:token HandleGetFaqContentPreview", func(t *testing.T) {
 		mockFaqPage := helpers.InitializeMockFaqPage()
 		mockFaqContent := mockFaqPage.Contents[0]
 		contentId := mockFaqContent.ID
+		token := "signed-token"
+		previewLink := &models.PreviewLink{ID: uuid.New(), PageType: enums.PageTypeFaq, ContentID: contentId}
 
 		t.Run("successfully get faq content preview", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("GetFaqContentPreview", contentId).Return(mockFaqContent, nil)		
+			mockPreviewLinkService.ExpectedCalls = nil
+			mockPreviewLinkService.On("ResolvePreviewToken", token, "secret", enums.PageTypeFaq).Return(previewLink, nil)
+			mockService.On("GetFaqContentPreview", contentId, dto.FieldSelection{}).Return(mockFaqContent, nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/previews/%s", token), nil)
+			req.Header.Set("X-Preview-Password", "secret")
 
-			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/previews/%s", contentId), nil)
-			
 			resp, err := app.Test(req)
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
 			mockService.AssertExpectations(t)
-		})		
+			mockPreviewLinkService.AssertExpectations(t)
+		})
+
+		t.Run("failed get faq content preview: rejected token", func(t *testing.T) {
+			cases := []struct {
+				err    error
+				status int
+			}{
+				{errs.ErrInvalidPreviewToken, fiber.StatusNotFound},
+				{errs.ErrPreviewLinkExpired, fiber.StatusGone},
+				{errs.ErrPreviewLinkRevoked, fiber.StatusGone},
+				{errs.ErrPreviewPasswordRequired, fiber.StatusUnauthorized},
+				{errs.ErrInvalidPreviewPassword, fiber.StatusUnauthorized},
+				{errs.ErrPreviewLinkLocked, fiber.StatusTooManyRequests},
+			}
+
+			for _, tc := range cases {
+				mockService.ExpectedCalls = nil
+				mockService.Calls = nil
+				mockPreviewLinkService.ExpectedCalls = nil
+				mockPreviewLinkService.On("ResolvePreviewToken", token, "", enums.PageTypeFaq).Return(nil, tc.err)
+
+				req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/previews/%s", token), nil)
+
+				resp, err := app.Test(req)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.status, resp.StatusCode, tc.err.Error())
+				mockService.AssertNotCalled(t, "GetFaqContentPreview", mock.Anything, mock.Anything)
+			}
+		})
 
 		t.Run("failed get faq content preview: internal server error", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("GetFaqContentPreview", contentId).Return(nil, errs.ErrInternalServerError)		
+			mockPreviewLinkService.ExpectedCalls = nil
+			mockPreviewLinkService.On("ResolvePreviewToken", token, "", enums.PageTypeFaq).Return(previewLink, nil)
+			mockService.On("GetFaqContentPreview", contentId, dto.FieldSelection{}).Return(nil, errs.ErrInternalServerError)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/previews/%s", token), nil)
 
-			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/previews/%s", contentId), nil)
-			
 			resp, err := app.Test(req)
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
 			mockService.AssertExpectations(t)
-		})		
+		})
 	})
-}
\ No newline at end of file
+
+	t.Run("GET /app/faqpages/:languageCode/categories HandleGetFaqCategoryTree", func(t *testing.T) {
+		t.Run("successfully get faq category tree", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetFaqCategoryTree", "en", "category-faq").Return([]dto.FaqCategoryTypeNode{}, nil)
+
+			req := httptest.NewRequest("GET", "/app/faqpages/en/categories?type=category-faq", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get faq category tree: invalid language", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetFaqCategoryTree", "fr", "").Return(nil, errs.ErrInvalidLanguageCode)
+
+			req := httptest.NewRequest("GET", "/app/faqpages/fr/categories", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /app/faqpages/:languageCode/grouped HandleGetFaqsGroupedByCategory", func(t *testing.T) {
+		t.Run("successfully get faqs grouped by category with a capped limit", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetFaqsGroupedByCategory", "en", "category-faq", dto.FaqGroupMaxLimit).Return([]dto.FaqCategoryGroup{}, nil)
+
+			req := httptest.NewRequest("GET", "/app/faqpages/en/grouped?type=category-faq&limit=1000", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get faqs grouped by category: missing type", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetFaqsGroupedByCategory", "en", "", dto.FaqGroupDefaultLimit).Return(nil, errs.ErrCategoryTypeRequired)
+
+			req := httptest.NewRequest("GET", "/app/faqpages/en/grouped", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /app/faqpages/:languageCode/categories/:categoryId HandleGetFaqsByCategory", func(t *testing.T) {
+		categoryId := uuid.New()
+
+		t.Run("successfully get faqs of a category", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			category := &dto.FaqCategoryNode{ID: categoryId, Name: "Account", Count: 1}
+			mockService.On("GetFaqsByCategory", "en", categoryId, 2, 10, []string(nil)).Return(category, []dto.FaqSummary{{ID: uuid.New(), Title: "How?"}}, int64(11), nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/en/categories/%s?page=2&limit=10", categoryId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get faqs of a category: invalid category id", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/app/faqpages/en/categories/not-a-uuid", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetFaqsByCategory", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		t.Run("failed to get faqs of a category: not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetFaqsByCategory", "en", categoryId, 1, dto.FaqCategoryDefaultLimit, []string(nil)).Return(nil, nil, int64(0), errs.ErrNotFound)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/faqpages/en/categories/%s", categoryId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+	})
+}
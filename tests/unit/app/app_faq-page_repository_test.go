@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models/enums"
@@ -12,6 +13,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestAppRepo_GetFaqPageBySlug(t *testing.T) {
@@ -24,7 +26,7 @@ func TestAppRepo_GetFaqPageBySlug(t *testing.T) {
 	language := string(enums.PageLanguageEN)
 
 	t.Run("successfully get faq page by slug: no preload (url alias)", func(t *testing.T) {
-		emptyPreload := []string{}
+		emptyPreload := dto.ContentProjection{}
 		isAlias := true
 
 		pageId := uuid.New()
@@ -71,7 +73,7 @@ func TestAppRepo_GetFaqPageBySlug(t *testing.T) {
 	})	
 
 	t.Run("successfully get faq page by slug: no preload (url)", func(t *testing.T) {
-		emptyPreload := []string{}
+		emptyPreload := dto.ContentProjection{}
 		isAlias := false
 
 		pageId := uuid.New()
@@ -118,7 +120,7 @@ func TestAppRepo_GetFaqPageBySlug(t *testing.T) {
 	})		
 
 	t.Run("successfully get faq page by slug: preloads all (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories", "Contents.Components", "Contents.MetaTag"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil, "Components": nil, "MetaTag": nil}}
 		isAlias := true
 		
 		pageId := uuid.New()
@@ -165,7 +167,7 @@ func TestAppRepo_GetFaqPageBySlug(t *testing.T) {
 	})
 
 	t.Run("successfully get faq page by slug: preloads all (url)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories", "Contents.Components", "Contents.MetaTag"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil, "Components": nil, "MetaTag": nil}}
 		isAlias := false
 		
 		pageId := uuid.New()
@@ -212,7 +214,7 @@ func TestAppRepo_GetFaqPageBySlug(t *testing.T) {
 	})	
 
 	t.Run("successfully get faq page by slug: partial preloads (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil}}
 		isAlias := true		
 
 		pageId := uuid.New()
@@ -250,7 +252,7 @@ func TestAppRepo_GetFaqPageBySlug(t *testing.T) {
 	})	
 
 	t.Run("successfully get faq page by slug: partial preloads (url)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil}}
 		isAlias := false		
 
 		pageId := uuid.New()
@@ -288,7 +290,7 @@ func TestAppRepo_GetFaqPageBySlug(t *testing.T) {
 	})		
 
 	t.Run("failed to get faq page by slug (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories", "Contents.Components", "Contents.MetaTag"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil, "Components": nil, "MetaTag": nil}}
 		isAlias := true
 
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "faq_pages"."id","faq_pages"."created_at","faq_pages"."updated_at" FROM "faq_pages" JOIN faq_contents ON faq_contents.page_id = faq_pages.id WHERE faq_contents.url_alias = $1 ORDER BY "faq_pages"."id" LIMIT $2`)).
@@ -325,7 +327,7 @@ func TestAppRepo_GetFaqContentPreview(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).
 				AddRow(metaTagId))		
 		
-		actualFaqContent, err := appFaqPageRepo.GetFaqContentPreview(pageId)
+		actualFaqContent, err := appFaqPageRepo.GetFaqContentPreview(pageId, dto.ContentProjection{})
 		assert.NoError(t, err)
 		assert.NotNil(t, actualFaqContent)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -335,9 +337,130 @@ func TestAppRepo_GetFaqContentPreview(t *testing.T) {
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "faq_contents"`)).
 			WillReturnError(errs.ErrInternalServerError)
 
-		actualFaqContent, err := appFaqPageRepo.GetFaqContentPreview(contentId)
+		actualFaqContent, err := appFaqPageRepo.GetFaqContentPreview(contentId, dto.ContentProjection{})
 		assert.Error(t, err)
 		assert.Nil(t, actualFaqContent)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})	
-}
\ No newline at end of file
+}
+func TestAppRepo_FindPublishedAlternates(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	appFaqPageRepo := repo.NewAppFaqPageRepository(gormDB)
+
+	pageId := uuid.New()
+
+	t.Run("successfully find published alternates", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "language","url","url_alias" FROM "faq_contents" WHERE page_id = $1 AND workflow_status = $2 AND mode != $3`)).
+			WithArgs(pageId, enums.WorkflowPublished, "Histories").
+			WillReturnRows(sqlmock.NewRows([]string{"language", "url", "url_alias"}).
+				AddRow(enums.PageLanguageEN, "/faq/en-title", "en-title").
+				AddRow(enums.PageLanguageTH, "/faq/th-title", "th-title"))
+
+		alternates, err := appFaqPageRepo.FindPublishedAlternates(pageId)
+		assert.NoError(t, err)
+		assert.Len(t, alternates, 2)
+		assert.Equal(t, enums.PageLanguageTH, alternates[1].Language)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to find published alternates", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "language","url","url_alias" FROM "faq_contents"`)).
+			WillReturnError(errs.ErrInternalServerError)
+
+		alternates, err := appFaqPageRepo.FindPublishedAlternates(pageId)
+		assert.Error(t, err)
+		assert.Nil(t, alternates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAppRepo_BrowseFaqs(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	appFaqPageRepo := repo.NewAppFaqPageRepository(gormDB)
+	language := string(enums.PageLanguageEN)
+
+	t.Run("successfully count published faqs per category of a type", func(t *testing.T) {
+		typeId, categoryId := uuid.New(), uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT category_types.id AS category_type_id, category_types.type_code, category_types.name AS category_type_name, categories.id AS category_id, categories.name, categories.description, categories.weight, COUNT(DISTINCT faq_contents.id) AS count FROM "categories" JOIN category_types ON category_types.id = categories.category_type_id LEFT JOIN faq_content_categories ON faq_content_categories.category_id = categories.id LEFT JOIN faq_contents ON faq_contents.id = faq_content_categories.faq_content_id AND faq_contents.language = $1 AND faq_contents.workflow_status = $2 AND faq_contents.mode NOT IN ($3,$4) WHERE (categories.language_code = $5 AND categories.publish_status = $6 AND category_types.is_active = $7) AND EXISTS (SELECT 1 FROM faq_content_categories
+	JOIN categories AS faq_categories ON faq_categories.id = faq_content_categories.category_id
+	WHERE faq_categories.category_type_id = category_types.id) AND category_types.type_code = $8 GROUP BY`)).
+			WithArgs(language, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, language, enums.PublishStatusPublished, true, "category-faq").
+			WillReturnRows(sqlmock.NewRows([]string{"category_type_id", "type_code", "category_type_name", "category_id", "name", "weight", "count"}).
+				AddRow(typeId, "category-faq", "FAQ", categoryId, "Account", 1, 4))
+
+		counts, err := appFaqPageRepo.FindFaqCategoryCounts(language, "category-faq")
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.FaqCategoryCount{{CategoryTypeID: typeId, TypeCode: "category-faq", CategoryTypeName: "FAQ", CategoryID: categoryId, Name: "Account", Weight: 1, Count: 4}}, counts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully get one faq category", func(t *testing.T) {
+		typeId, categoryId := uuid.New(), uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE faq_categories.category_type_id = category_types.id) AND categories.id = $8 GROUP BY`)).
+			WithArgs(language, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, language, enums.PublishStatusPublished, true, categoryId).
+			WillReturnRows(sqlmock.NewRows([]string{"category_type_id", "type_code", "category_id", "name", "count"}).
+				AddRow(typeId, "category-faq", categoryId, "Account", 4))
+
+		count, err := appFaqPageRepo.FindFaqCategoryCount(language, categoryId)
+		assert.NoError(t, err)
+		assert.Equal(t, "Account", count.Name)
+		assert.Equal(t, int64(4), count.Count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to get one faq category: not a published faq category", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`AND categories.id = $8 GROUP BY`)).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}))
+
+		count, err := appFaqPageRepo.FindFaqCategoryCount(language, uuid.New())
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully rank the most helpful faqs of each category", func(t *testing.T) {
+		categoryId, contentId := uuid.New(), uuid.New()
+
+		mock.ExpectQuery(`ORDER BY COALESCE\(feedback_scores.score, 0\) DESC, faq_contents.publish_on DESC, faq_contents.title ASC\) AS position .+ FROM faq_feedbacks WHERE language = \$1 GROUP BY page_id .+ WHERE position <= \$7 ORDER BY category_id, position`).
+			WithArgs(language, categoryId, language, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id", "id", "title", "url"}).
+				AddRow(categoryId, contentId, "How?", "/faq/how"))
+
+		summaries, err := appFaqPageRepo.FindTopFaqSummaries([]uuid.UUID{categoryId}, language, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.FaqCategorySummary{{CategoryID: categoryId, FaqSummary: dto.FaqSummary{ID: contentId, Title: "How?", URL: "/faq/how"}}}, summaries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully skip ranking without categories", func(t *testing.T) {
+		summaries, err := appFaqPageRepo.FindTopFaqSummaries(nil, language, 3)
+		assert.NoError(t, err)
+		assert.Empty(t, summaries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully page the faqs of a category", func(t *testing.T) {
+		categoryId, contentId := uuid.New(), uuid.New()
+		where := `FROM "faq_contents" JOIN faq_content_categories ON faq_content_categories.faq_content_id = faq_contents.id WHERE faq_content_categories.category_id = $1 AND (faq_contents.language = $2 AND faq_contents.workflow_status = $3 AND faq_contents.mode NOT IN ($4,$5))`
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT("faq_contents"."id")) ` + where)).
+			WithArgs(categoryId, language, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT faq_contents.id,faq_contents.title,faq_contents.url,faq_contents.url_alias,faq_contents.publish_on ` + where + ` ORDER BY faq_contents.publish_on DESC, faq_contents.title ASC LIMIT $6 OFFSET $7`)).
+			WithArgs(categoryId, language, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, 10, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(contentId, "How?"))
+
+		summaries, totalCount, err := appFaqPageRepo.FindFaqSummariesByCategory(categoryId, language, 2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(21), totalCount)
+		assert.Equal(t, []dto.FaqSummary{{ID: contentId, Title: "How?"}}, summaries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
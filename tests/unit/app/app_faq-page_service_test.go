@@ -3,6 +3,8 @@ package tests
 import (
 	"testing"
 
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
@@ -11,59 +13,118 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 type MockAppFaqPageRepo struct {
-	getFaqPageBySlug func(slug string, preloads []string, isAlias bool, language string) (*models.FaqPage, error)
-	getFaqContentPreview func(id uuid.UUID) (*models.FaqContent, error)
+	getFaqPageBySlug func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.FaqPage, error)
+	getFaqContentPreview func(id uuid.UUID, projection dto.ContentProjection) (*models.FaqContent, error)
+	findPublishedAlternates func(pageId uuid.UUID) ([]models.FaqContent, error)
+	findFaqCategoryCounts func(language string, typeCode string) ([]dto.FaqCategoryCount, error)
+	findFaqCategoryCount func(language string, categoryId uuid.UUID) (*dto.FaqCategoryCount, error)
+	findTopFaqSummaries func(categoryIds []uuid.UUID, language string, perCategory int) ([]dto.FaqCategorySummary, error)
+	findFaqSummariesByCategory func(categoryId uuid.UUID, language string, page, limit int) ([]dto.FaqSummary, int64, error)
 }
 
-func (m *MockAppFaqPageRepo) GetFaqPageBySlug(slug string, preloads []string, isAlias bool, language string) (*models.FaqPage, error) {
-	return m.getFaqPageBySlug(slug, preloads, isAlias, language)
+func (m *MockAppFaqPageRepo) GetFaqPageBySlug(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.FaqPage, error) {
+	return m.getFaqPageBySlug(slug, projection, isAlias, language)
 }
 
-func (m *MockAppFaqPageRepo) GetFaqContentPreview(id uuid.UUID) (*models.FaqContent, error) {
-	return m.getFaqContentPreview(id)
+func (m *MockAppFaqPageRepo) GetFaqContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.FaqContent, error) {
+	return m.getFaqContentPreview(id, projection)
+}
+
+func (m *MockAppFaqPageRepo) FindPublishedAlternates(pageId uuid.UUID) ([]models.FaqContent, error) {
+	return m.findPublishedAlternates(pageId)
+}
+
+func (m *MockAppFaqPageRepo) FindFaqCategoryCounts(language string, typeCode string) ([]dto.FaqCategoryCount, error) {
+	return m.findFaqCategoryCounts(language, typeCode)
+}
+
+func (m *MockAppFaqPageRepo) FindFaqCategoryCount(language string, categoryId uuid.UUID) (*dto.FaqCategoryCount, error) {
+	return m.findFaqCategoryCount(language, categoryId)
+}
+
+func (m *MockAppFaqPageRepo) FindTopFaqSummaries(categoryIds []uuid.UUID, language string, perCategory int) ([]dto.FaqCategorySummary, error) {
+	return m.findTopFaqSummaries(categoryIds, language, perCategory)
+}
+
+func (m *MockAppFaqPageRepo) FindFaqSummariesByCategory(categoryId uuid.UUID, language string, page, limit int) ([]dto.FaqSummary, int64, error) {
+	return m.findFaqSummariesByCategory(categoryId, language, page, limit)
 }
 
 func TestAppService_GetFaqPage(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{WebBaseURL: "https://example.com"}}
 	slug := "about/us"
 	language := string(enums.PageLanguageEN)
-	selectParam := "revision,categories,components,metatag"
+	selection := dto.FieldSelection{Includes: map[string][]string{"revision": nil, "categories": nil, "components": nil, "meta_tag": nil}}
 	isAlias := true
 
 	t.Run("successfully get faq page", func(t *testing.T) {
 		mockFaqPage := helpers.InitializeMockFaqPage()
 
 		repo := &MockAppFaqPageRepo{
-			getFaqPageBySlug: func(slug string, preloads []string, isAlias bool, language string) (*models.FaqPage, error) {
+			getFaqPageBySlug: func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.FaqPage, error) {
 				return mockFaqPage, nil
 			},
+			findPublishedAlternates: func(pageId uuid.UUID) ([]models.FaqContent, error) {
+				return []models.FaqContent{}, nil
+			},
 		}
 
-		service := services.NewAppFaqPageService(repo)
+		service := services.NewAppFaqPageService(repo, &MockContentRelationService{}, cfg)
 
-		actualFaqPage, err := service.GetFaqPage(slug, isAlias, selectParam, language)
+		actualFaqPage, err := service.GetFaqPage(slug, isAlias, selection, language)
 		assert.NoError(t, err)
 		assert.Equal(t, mockFaqPage, actualFaqPage)
 	})	
 
+	t.Run("successfully attach canonical and hreflang alternates", func(t *testing.T) {
+		mockFaqPage := helpers.InitializeMockFaqPage()
+
+		repo := &MockAppFaqPageRepo{
+			getFaqPageBySlug: func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.FaqPage, error) {
+				return mockFaqPage, nil
+			},
+			findPublishedAlternates: func(pageId uuid.UUID) ([]models.FaqContent, error) {
+				return []models.FaqContent{
+					{Language: enums.PageLanguageTH, URL: "/faq/mock-faq-title-th"},
+				}, nil
+			},
+		}
+
+		service := services.NewAppFaqPageService(repo, &MockContentRelationService{}, cfg)
+
+		actualFaqPage, err := service.GetFaqPage(slug, isAlias, selection, language)
+		assert.NoError(t, err)
+
+		metaTag := actualFaqPage.Contents[0].MetaTag
+		assert.Equal(t, "https://example.com/en/faq/mock-faq-title", metaTag.CanonicalURL)
+		assert.Equal(t, []models.HrefLangAlternate{
+			{HrefLang: "en", Href: "https://example.com/en/faq/mock-faq-title"},
+			{HrefLang: "th", Href: "https://example.com/th/faq/mock-faq-title-th"},
+		}, metaTag.Alternates)
+		assert.NotEmpty(t, actualFaqPage.Contents[0].JSONLD)
+	})
+
 	t.Run("failed to get faq page", func(t *testing.T) {
 		repo := &MockAppFaqPageRepo{
-			getFaqPageBySlug: func(slug string, preloads []string, isAlias bool, language string) (*models.FaqPage, error) {
+			getFaqPageBySlug: func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.FaqPage, error) {
 				return nil, errs.ErrInternalServerError
 			},
 		}
 
-		service := services.NewAppFaqPageService(repo)
+		service := services.NewAppFaqPageService(repo, &MockContentRelationService{}, cfg)
 
-		actualFaqPage, err := service.GetFaqPage(slug, isAlias, selectParam, language)
+		actualFaqPage, err := service.GetFaqPage(slug, isAlias, selection, language)
 		assert.Error(t, err)
 		assert.Nil(t, actualFaqPage)
 	})	
 }
 
 func TestAppService_GetFaqContentPreview(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{WebBaseURL: "https://example.com"}}
 	contentId := uuid.New()
 
 	t.Run("successfully get faq content preview", func(t *testing.T) {
@@ -71,29 +132,136 @@ func TestAppService_GetFaqContentPreview(t *testing.T) {
 		mockFaqContent := mockFaqPage.Contents[0]
 
 		repo := &MockAppFaqPageRepo{
-			getFaqContentPreview: func(id uuid.UUID) (*models.FaqContent, error) {
+			getFaqContentPreview: func(id uuid.UUID, projection dto.ContentProjection) (*models.FaqContent, error) {
 				return mockFaqContent, nil
 			},
 		}
 
-		service := services.NewAppFaqPageService(repo)
+		service := services.NewAppFaqPageService(repo, &MockContentRelationService{}, cfg)
 
-		actualFaqContent, err := service.GetFaqContentPreview(contentId)
+		actualFaqContent, err := service.GetFaqContentPreview(contentId, dto.FieldSelection{})
 		assert.NoError(t, err)
 		assert.Equal(t, mockFaqContent, actualFaqContent)
 	})	
 
 	t.Run("failed to get faq content preview", func(t *testing.T) {
 		repo := &MockAppFaqPageRepo{
-			getFaqContentPreview: func(id uuid.UUID) (*models.FaqContent, error) {
+			getFaqContentPreview: func(id uuid.UUID, projection dto.ContentProjection) (*models.FaqContent, error) {
 				return nil, errs.ErrInternalServerError
 			},
 		}
 
-		service := services.NewAppFaqPageService(repo)
+		service := services.NewAppFaqPageService(repo, &MockContentRelationService{}, cfg)
 
-		actualFaqContent, err := service.GetFaqContentPreview(contentId)
+		actualFaqContent, err := service.GetFaqContentPreview(contentId, dto.FieldSelection{})
 		assert.Error(t, err)
 		assert.Nil(t, actualFaqContent)
 	})	
-}
\ No newline at end of file
+}
+
+func TestAppService_BrowseFaqs(t *testing.T) {
+	cfg := &config.Config{}
+	faqTypeId, topicTypeId := uuid.New(), uuid.New()
+	accountId, billingId, emptyId, topicId := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	counts := []dto.FaqCategoryCount{
+		{CategoryTypeID: faqTypeId, TypeCode: "category-faq", CategoryTypeName: "FAQ", CategoryID: accountId, Name: "Account", Weight: 0, Count: 2},
+		{CategoryTypeID: faqTypeId, TypeCode: "category-faq", CategoryTypeName: "FAQ", CategoryID: billingId, Name: "Billing", Weight: 1, Count: 1},
+		{CategoryTypeID: faqTypeId, TypeCode: "category-faq", CategoryTypeName: "FAQ", CategoryID: emptyId, Name: "Empty", Weight: 2, Count: 0},
+		{CategoryTypeID: topicTypeId, TypeCode: "category-topic", CategoryTypeName: "Topic", CategoryID: topicId, Name: "Topic", Count: 1},
+	}
+
+	t.Run("GetFaqCategoryTree", func(t *testing.T) {
+		t.Run("successfully nest categories under their type", func(t *testing.T) {
+			repo := &MockAppFaqPageRepo{
+				findFaqCategoryCounts: func(language string, typeCode string) ([]dto.FaqCategoryCount, error) {
+					assert.Equal(t, "en", language)
+					return counts, nil
+				},
+			}
+			service := services.NewAppFaqPageService(repo, &MockContentRelationService{}, cfg)
+
+			tree, err := service.GetFaqCategoryTree("EN", "")
+			assert.NoError(t, err)
+			assert.Len(t, tree, 2)
+			assert.Equal(t, "category-faq", tree[0].TypeCode)
+			assert.Len(t, tree[0].Categories, 3)
+			assert.Equal(t, int64(2), tree[0].Categories[0].Count)
+			assert.Equal(t, topicId, tree[1].Categories[0].ID)
+		})
+
+		t.Run("failed to get tree: invalid language", func(t *testing.T) {
+			service := services.NewAppFaqPageService(&MockAppFaqPageRepo{}, &MockContentRelationService{}, cfg)
+
+			_, err := service.GetFaqCategoryTree("fr", "")
+			assert.ErrorIs(t, err, errs.ErrInvalidLanguageCode)
+		})
+	})
+
+	t.Run("GetFaqsGroupedByCategory", func(t *testing.T) {
+		t.Run("successfully group questions skipping empty categories", func(t *testing.T) {
+			firstId, secondId, thirdId := uuid.New(), uuid.New(), uuid.New()
+			repo := &MockAppFaqPageRepo{
+				findFaqCategoryCounts: func(language string, typeCode string) ([]dto.FaqCategoryCount, error) {
+					assert.Equal(t, "category-faq", typeCode)
+					return counts[:3], nil
+				},
+				findTopFaqSummaries: func(categoryIds []uuid.UUID, language string, perCategory int) ([]dto.FaqCategorySummary, error) {
+					assert.Equal(t, []uuid.UUID{accountId, billingId}, categoryIds)
+					assert.Equal(t, 5, perCategory)
+					return []dto.FaqCategorySummary{
+						{CategoryID: accountId, FaqSummary: dto.FaqSummary{ID: firstId, Title: "First"}},
+						{CategoryID: accountId, FaqSummary: dto.FaqSummary{ID: secondId, Title: "Second"}},
+						{CategoryID: billingId, FaqSummary: dto.FaqSummary{ID: thirdId, Title: "Third"}},
+					}, nil
+				},
+			}
+			service := services.NewAppFaqPageService(repo, &MockContentRelationService{}, cfg)
+
+			groups, err := service.GetFaqsGroupedByCategory("en", "category-faq", 5)
+			assert.NoError(t, err)
+			assert.Len(t, groups, 2)
+			assert.Equal(t, "Account", groups[0].Name)
+			assert.Equal(t, int64(2), groups[0].TotalCount)
+			assert.Equal(t, []dto.FaqSummary{{ID: firstId, Title: "First"}, {ID: secondId, Title: "Second"}}, groups[0].Items)
+			assert.Equal(t, thirdId, groups[1].Items[0].ID)
+		})
+
+		t.Run("failed to group questions: missing category type", func(t *testing.T) {
+			service := services.NewAppFaqPageService(&MockAppFaqPageRepo{}, &MockContentRelationService{}, cfg)
+
+			_, err := service.GetFaqsGroupedByCategory("en", " ", 5)
+			assert.ErrorIs(t, err, errs.ErrCategoryTypeRequired)
+		})
+	})
+
+	t.Run("GetFaqsByCategory", func(t *testing.T) {
+		repo := &MockAppFaqPageRepo{
+			findFaqCategoryCount: func(language string, categoryId uuid.UUID) (*dto.FaqCategoryCount, error) {
+				for _, count := range counts {
+					if count.CategoryID == categoryId {
+						return &count, nil
+					}
+				}
+				return nil, gorm.ErrRecordNotFound
+			},
+			findFaqSummariesByCategory: func(categoryId uuid.UUID, language string, page, limit int) ([]dto.FaqSummary, int64, error) {
+				return []dto.FaqSummary{{Title: "Question"}}, 21, nil
+			},
+		}
+		service := services.NewAppFaqPageService(repo, &MockContentRelationService{}, cfg)
+
+		t.Run("successfully get questions of a category", func(t *testing.T) {
+			category, summaries, totalCount, err := service.GetFaqsByCategory("en", billingId, 2, 20, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, "Billing", category.Name)
+			assert.Len(t, summaries, 1)
+			assert.Equal(t, int64(21), totalCount)
+		})
+
+		t.Run("failed to get questions: unknown category", func(t *testing.T) {
+			_, _, _, err := service.GetFaqsByCategory("en", uuid.New(), 1, 20, nil)
+			assert.ErrorIs(t, err, errs.ErrNotFound)
+		})
+	})
+}
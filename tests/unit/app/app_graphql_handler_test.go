@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	appHandler "github.com/MadManJJ/cms-api/handlers/app"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAppGraphQLService struct {
+	mock.Mock
+}
+
+func (m *MockAppGraphQLService) Execute(ctx context.Context, request dto.GraphQLRequest) (*graphql.Result, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*graphql.Result), args.Error(1)
+}
+
+func TestAppGraphQLHandler(t *testing.T) {
+	mockService := &MockAppGraphQLService{}
+	handler := appHandler.NewAppGraphQLHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/api/v1/app/graphql", handler.HandleGraphQL)
+	app.Post("/api/v1/app/graphql", handler.HandleGraphQL)
+
+	query := `{ landingPage(alias: "a", language: "en") { title } }`
+
+	t.Run("POST /api/v1/app/graphql successfully run a query", func(t *testing.T) {
+		mockService.On("Execute", dto.GraphQLRequest{Query: query}).
+			Return(&graphql.Result{Data: map[string]interface{}{"landingPage": map[string]interface{}{"title": "A"}}}, nil).Once()
+
+		body, _ := json.Marshal(dto.GraphQLRequest{Query: query})
+		req := httptest.NewRequest("POST", "/api/v1/app/graphql", strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		respBody, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"data":{"landingPage":{"title":"A"}}}`, string(respBody))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET /api/v1/app/graphql successfully run a persisted query with variables", func(t *testing.T) {
+		expected := dto.GraphQLRequest{
+			Variables:  map[string]interface{}{"alias": "a"},
+			Extensions: &dto.GraphQLExtensions{PersistedQuery: &dto.GraphQLPersistedQuery{Version: 1, Sha256Hash: "abc"}},
+		}
+		mockService.On("Execute", expected).Return(&graphql.Result{Data: map[string]interface{}{}}, nil).Once()
+
+		params := url.Values{}
+		params.Set("variables", `{"alias":"a"}`)
+		params.Set("extensions", `{"persistedQuery":{"version":1,"sha256Hash":"abc"}}`)
+		req := httptest.NewRequest("GET", "/api/v1/app/graphql?"+params.Encode(), nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET /api/v1/app/graphql answer a persisted query miss with 200", func(t *testing.T) {
+		mockService.On("Execute", mock.Anything).Return(nil, errs.ErrPersistedQueryNotFound).Once()
+
+		req := httptest.NewRequest("GET", "/api/v1/app/graphql?extensions="+url.QueryEscape(`{"persistedQuery":{"version":1,"sha256Hash":"abc"}}`), nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		respBody, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"errors":[{"message":"PersistedQueryNotFound"}]}`, string(respBody))
+	})
+
+	t.Run("POST /api/v1/app/graphql fail to run a query above the limits", func(t *testing.T) {
+		mockService.On("Execute", mock.Anything).Return(nil, errs.ErrQueryTooDeep).Once()
+
+		req := httptest.NewRequest("POST", "/api/v1/app/graphql", strings.NewReader(`{"query":"{ a }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("GET /api/v1/app/graphql fail to parse the variables", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.Calls = nil
+
+		req := httptest.NewRequest("GET", "/api/v1/app/graphql?query=%7B+a+%7D&variables=not-json", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockService.AssertNotCalled(t, "Execute", mock.Anything)
+	})
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppRepo_GraphQLBatches(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	appGraphQLRepo := repo.NewAppGraphQLRepository(gormDB)
+
+	contentIds := []uuid.UUID{uuid.New(), uuid.New()}
+
+	t.Run("successfully find the components of many contents", func(t *testing.T) {
+		componentId := uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "components" WHERE partner_content_id IN ($1,$2) ORDER BY created_at, id`)).
+			WithArgs(contentIds[0], contentIds[1]).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "partner_content_id", "type"}).
+				AddRow(componentId, contentIds[1], "NormalText"))
+
+		components, err := appGraphQLRepo.FindComponentsByContentIDs(enums.PageTypePartner, contentIds)
+
+		assert.NoError(t, err)
+		assert.Len(t, components, 1)
+		assert.Equal(t, contentIds[1], *components[0].PartnerContentID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully find the published categories of many contents", func(t *testing.T) {
+		categoryId := uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT categories.*, landing_content_categories.landing_content_id AS content_id FROM "categories" JOIN landing_content_categories ON landing_content_categories.category_id = categories.id WHERE landing_content_categories.landing_content_id IN ($1,$2) AND categories.publish_status = $3 ORDER BY categories.weight, categories.name`)).
+			WithArgs(contentIds[0], contentIds[1], enums.PublishStatusPublished).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "content_id"}).
+				AddRow(categoryId, "Retail", contentIds[0]).
+				AddRow(categoryId, "Retail", contentIds[1]))
+
+		categories, err := appGraphQLRepo.FindCategoriesByContentIDs(enums.PageTypeLanding, contentIds)
+
+		assert.NoError(t, err)
+		assert.Len(t, categories, 2)
+		assert.Equal(t, categoryId, categories[0].ID)
+		assert.Equal(t, contentIds[1], categories[1].ContentID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("fail to find the components of an unknown page type", func(t *testing.T) {
+		components, err := appGraphQLRepo.FindComponentsByContentIDs(enums.PageType("blog"), contentIds)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+		assert.Nil(t, components)
+	})
+}
@@ -0,0 +1,275 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/datatypes"
+)
+
+type MockAppGraphQLRepo struct {
+	findComponentsByContentIDs func(pageType enums.PageType, contentIds []uuid.UUID) ([]models.Component, error)
+	findCategoriesByContentIDs func(pageType enums.PageType, contentIds []uuid.UUID) ([]dto.ContentCategory, error)
+	findCategoryTypesByIDs     func(ids []uuid.UUID) ([]models.CategoryType, error)
+}
+
+func (m *MockAppGraphQLRepo) FindComponentsByContentIDs(pageType enums.PageType, contentIds []uuid.UUID) ([]models.Component, error) {
+	return m.findComponentsByContentIDs(pageType, contentIds)
+}
+
+func (m *MockAppGraphQLRepo) FindCategoriesByContentIDs(pageType enums.PageType, contentIds []uuid.UUID) ([]dto.ContentCategory, error) {
+	return m.findCategoriesByContentIDs(pageType, contentIds)
+}
+
+func (m *MockAppGraphQLRepo) FindCategoryTypesByIDs(ids []uuid.UUID) ([]models.CategoryType, error) {
+	return m.findCategoryTypesByIDs(ids)
+}
+
+func graphQLConfig() *config.Config {
+	return &config.Config{GraphQL: config.GraphQLConfig{
+		MaxDepth:              8,
+		MaxComplexity:         1000,
+		MaxIntrospectionDepth: 13,
+		DefaultListSize:       10,
+		PersistedQuerySize:    2,
+	}}
+}
+
+func graphQLData(t *testing.T, data interface{}) map[string]interface{} {
+	raw, err := json.Marshal(data)
+	assert.NoError(t, err)
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(raw, &decoded))
+	return decoded
+}
+
+// metaTagSelection is what the page resolvers ask the page services for
+var metaTagSelection = dto.FieldSelection{Includes: map[string][]string{"meta_tag": nil}}
+
+func TestAppGraphQLService_Execute(t *testing.T) {
+	language := string(enums.PageLanguageEN)
+
+	t.Run("successfully resolve a landing page with its components", func(t *testing.T) {
+		contentId := uuid.New()
+		landingService := &MockAppLandingPageService{}
+		landingService.On("GetLandingPageByUrlAlias", "summer-sale", metaTagSelection, language).Return(&models.LandingPage{
+			Contents: []*models.LandingContent{{
+				ID:       contentId,
+				Title:    "Summer sale",
+				UrlAlias: "summer-sale",
+				MetaTag:  &models.MetaTag{Title: "Sale", CanonicalURL: "https://example.com/en/summer-sale"},
+			}},
+		}, nil)
+		repo := &MockAppGraphQLRepo{
+			findComponentsByContentIDs: func(pageType enums.PageType, contentIds []uuid.UUID) ([]models.Component, error) {
+				assert.Equal(t, enums.PageTypeLanding, pageType)
+				assert.Equal(t, []uuid.UUID{contentId}, contentIds)
+				return []models.Component{{ID: uuid.New(), LandingContentID: &contentId, Type: "NormalText", Props: datatypes.JSON(`{"text":"hi"}`)}}, nil
+			},
+		}
+
+		service, err := services.NewAppGraphQLService(landingService, nil, nil, nil, repo, graphQLConfig())
+		assert.NoError(t, err)
+
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{
+			Query: `{ landingPage(alias: "summer-sale", language: "en") { title metaTag { canonicalUrl } components { type props } } }`,
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		page := graphQLData(t, result.Data)["landingPage"].(map[string]interface{})
+		assert.Equal(t, "Summer sale", page["title"])
+		assert.Equal(t, "https://example.com/en/summer-sale", page["metaTag"].(map[string]interface{})["canonicalUrl"])
+		components := page["components"].([]interface{})
+		assert.Len(t, components, 1)
+		assert.Equal(t, map[string]interface{}{"text": "hi"}, components[0].(map[string]interface{})["props"])
+	})
+
+	t.Run("successfully resolve a missing page to null", func(t *testing.T) {
+		landingService := &MockAppLandingPageService{}
+		landingService.On("GetLandingPageByUrlAlias", "gone", metaTagSelection, language).Return(nil, errs.ErrNotFound)
+
+		service, _ := services.NewAppGraphQLService(landingService, nil, nil, nil, &MockAppGraphQLRepo{}, graphQLConfig())
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{
+			Query: `{ landingPage(alias: "gone", language: "en") { title } }`,
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Nil(t, graphQLData(t, result.Data)["landingPage"])
+	})
+
+	t.Run("successfully batch the categories of every listed partner into one query", func(t *testing.T) {
+		cards := []dto.PartnerCard{{ID: uuid.New(), Title: "A"}, {ID: uuid.New(), Title: "B"}, {ID: uuid.New(), Title: "C"}}
+		typeId := uuid.New()
+		partnerService := &MockAppPartnerPageService{}
+		partnerService.On("FindPartnerListing", mock.MatchedBy(func(query *dto.PartnerListingQuery) bool {
+			return query.Limit == 3 && query.Page == 1
+		})).Return(cards, int64(3), &dto.PartnerListingFacets{}, nil)
+
+		categoryCalls, categoryTypeCalls := 0, 0
+		repo := &MockAppGraphQLRepo{
+			findCategoriesByContentIDs: func(pageType enums.PageType, contentIds []uuid.UUID) ([]dto.ContentCategory, error) {
+				categoryCalls++
+				assert.Equal(t, enums.PageTypePartner, pageType)
+				assert.ElementsMatch(t, []uuid.UUID{cards[0].ID, cards[1].ID, cards[2].ID}, contentIds)
+				return []dto.ContentCategory{
+					{Category: models.Category{ID: uuid.New(), Name: "Retail", CategoryTypeID: typeId}, ContentID: cards[0].ID},
+					{Category: models.Category{ID: uuid.New(), Name: "Food", CategoryTypeID: typeId}, ContentID: cards[2].ID},
+				}, nil
+			},
+			findCategoryTypesByIDs: func(ids []uuid.UUID) ([]models.CategoryType, error) {
+				categoryTypeCalls++
+				return []models.CategoryType{{ID: typeId, TypeCode: "Partner"}}, nil
+			},
+		}
+
+		service, _ := services.NewAppGraphQLService(nil, partnerService, nil, nil, repo, graphQLConfig())
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{
+			Query: `{ partners(language: "en", limit: 3) { totalCount items { title categories { name categoryType { typeCode } } } } }`,
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, 1, categoryCalls)
+		assert.Equal(t, 1, categoryTypeCalls)
+		items := graphQLData(t, result.Data)["partners"].(map[string]interface{})["items"].([]interface{})
+		assert.Len(t, items, 3)
+		assert.Len(t, items[0].(map[string]interface{})["categories"], 1)
+		assert.Len(t, items[1].(map[string]interface{})["categories"], 0)
+	})
+
+	t.Run("fail to run a query nested deeper than the limit", func(t *testing.T) {
+		cfg := graphQLConfig()
+		cfg.GraphQL.MaxDepth = 3
+
+		service, _ := services.NewAppGraphQLService(nil, nil, nil, nil, &MockAppGraphQLRepo{}, cfg)
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{
+			Query: `query { ...page } fragment page on Query { landingPage(alias: "a", language: "en") { metaTag { alternates { href } } } }`,
+		})
+
+		assert.ErrorIs(t, err, errs.ErrQueryTooDeep)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail to run a query above the complexity limit, limits from variables included", func(t *testing.T) {
+		cfg := graphQLConfig()
+		cfg.GraphQL.MaxComplexity = 100
+
+		service, _ := services.NewAppGraphQLService(nil, &MockAppPartnerPageService{}, nil, nil, &MockAppGraphQLRepo{}, cfg)
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{
+			Query:     `query Listing($limit: Int) { partners(language: "en", limit: $limit) { items { id title } } }`,
+			Variables: map[string]interface{}{"limit": float64(50)},
+		})
+
+		assert.ErrorIs(t, err, errs.ErrQueryTooComplex)
+		assert.Nil(t, result)
+	})
+
+	t.Run("successfully run the introspection query tooling sends", func(t *testing.T) {
+		service, _ := services.NewAppGraphQLService(nil, nil, nil, nil, &MockAppGraphQLRepo{}, graphQLConfig())
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{Query: testutil.IntrospectionQuery})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.NotNil(t, graphQLData(t, result.Data)["__schema"])
+	})
+
+	t.Run("fail to run an introspection query nested deeper than its limit", func(t *testing.T) {
+		service, _ := services.NewAppGraphQLService(nil, nil, nil, nil, &MockAppGraphQLRepo{}, graphQLConfig())
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{
+			Query: `{ __schema { types { fields { type { fields { type { fields { type { fields { type { fields { type { fields { name } } } } } } } } } } } } } }`,
+		})
+
+		assert.ErrorIs(t, err, errs.ErrQueryTooDeep)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail to run a query above the complexity limit, introspection fields included", func(t *testing.T) {
+		cfg := graphQLConfig()
+		cfg.GraphQL.MaxComplexity = 5
+
+		service, _ := services.NewAppGraphQLService(nil, nil, nil, nil, &MockAppGraphQLRepo{}, cfg)
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{
+			Query: `{ __typename a: __typename b: __typename __type(name: "Query") { name fields { name } } }`,
+		})
+
+		assert.ErrorIs(t, err, errs.ErrQueryTooComplex)
+		assert.Nil(t, result)
+	})
+
+	t.Run("successfully report an invalid query in the result", func(t *testing.T) {
+		service, _ := services.NewAppGraphQLService(nil, nil, nil, nil, &MockAppGraphQLRepo{}, graphQLConfig())
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{Query: `{ landingPage { secret } }`})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Errors)
+	})
+
+	t.Run("fail without a query", func(t *testing.T) {
+		service, _ := services.NewAppGraphQLService(nil, nil, nil, nil, &MockAppGraphQLRepo{}, graphQLConfig())
+		_, err := service.Execute(context.Background(), dto.GraphQLRequest{})
+
+		assert.ErrorIs(t, err, errs.ErrMissingGraphQLQuery)
+	})
+}
+
+func TestAppGraphQLService_PersistedQueries(t *testing.T) {
+	query := `{ faqCategories(language: "en") { typeCode } }`
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+	persisted := func(hash string) *dto.GraphQLExtensions {
+		return &dto.GraphQLExtensions{PersistedQuery: &dto.GraphQLPersistedQuery{Version: 1, Sha256Hash: hash}}
+	}
+
+	faqService := &MockAppFaqPageService{}
+	faqService.On("GetFaqCategoryTree", "en", "").Return([]dto.FaqCategoryTypeNode{{ID: uuid.New(), TypeCode: "category-faq"}}, nil)
+	service, _ := services.NewAppGraphQLService(nil, nil, faqService, nil, &MockAppGraphQLRepo{}, graphQLConfig())
+
+	t.Run("fail to run an unknown hash", func(t *testing.T) {
+		_, err := service.Execute(context.Background(), dto.GraphQLRequest{Extensions: persisted(hash)})
+		assert.ErrorIs(t, err, errs.ErrPersistedQueryNotFound)
+	})
+
+	t.Run("fail to persist a query under another hash", func(t *testing.T) {
+		_, err := service.Execute(context.Background(), dto.GraphQLRequest{Query: query, Extensions: persisted("deadbeef")})
+		assert.ErrorIs(t, err, errs.ErrPersistedQueryHashMismatch)
+	})
+
+	t.Run("successfully run a hash after its query was sent once", func(t *testing.T) {
+		result, err := service.Execute(context.Background(), dto.GraphQLRequest{Query: query, Extensions: persisted(hash)})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+
+		result, err = service.Execute(context.Background(), dto.GraphQLRequest{Extensions: persisted(hash)})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		types := graphQLData(t, result.Data)["faqCategories"].([]interface{})
+		assert.Equal(t, "category-faq", types[0].(map[string]interface{})["typeCode"])
+	})
+
+	t.Run("successfully evict the oldest query once the store is full", func(t *testing.T) {
+		for _, other := range []string{`{ a: faqCategories(language: "en") { id } }`, `{ b: faqCategories(language: "en") { id } }`} {
+			otherSum := sha256.Sum256([]byte(other))
+			_, err := service.Execute(context.Background(), dto.GraphQLRequest{Query: other, Extensions: persisted(hex.EncodeToString(otherSum[:]))})
+			assert.NoError(t, err)
+		}
+
+		_, err := service.Execute(context.Background(), dto.GraphQLRequest{Extensions: persisted(hash)})
+		assert.ErrorIs(t, err, errs.ErrPersistedQueryNotFound)
+	})
+}
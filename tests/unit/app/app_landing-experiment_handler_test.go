@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	appHandler "github.com/MadManJJ/cms-api/handlers/app"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAppLandingExperimentService struct {
+	mock.Mock
+}
+
+func (m *MockAppLandingExperimentService) CreateExperiment(request dto.CreateLandingExperimentRequest) (*models.LandingExperiment, error) {
+	args := m.Called(request)
+	return nil, args.Error(1)
+}
+
+func (m *MockAppLandingExperimentService) FindExperiments(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error) {
+	args := m.Called(pageId, status)
+	return nil, args.Error(1)
+}
+
+func (m *MockAppLandingExperimentService) GetExperimentResults(id uuid.UUID) (*dto.ExperimentResults, error) {
+	args := m.Called(id)
+	return nil, args.Error(1)
+}
+
+func (m *MockAppLandingExperimentService) StopExperiment(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAppLandingExperimentService) PromoteWinner(id uuid.UUID, request dto.PromoteExperimentRequest) (*models.LandingContent, error) {
+	args := m.Called(id, request)
+	return nil, args.Error(1)
+}
+
+func (m *MockAppLandingExperimentService) AssignVariant(content *models.LandingContent, assignmentKey string) (*dto.ExperimentAssignment, error) {
+	args := m.Called(content, assignmentKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExperimentAssignment), args.Error(1)
+}
+
+func (m *MockAppLandingExperimentService) RecordConversion(experimentId uuid.UUID, assignmentKey string) error {
+	args := m.Called(experimentId, assignmentKey)
+	return args.Error(0)
+}
+
+func (m *MockAppLandingExperimentService) WithPageAccess(access *dto.PageAccess) services.CMSLandingExperimentServiceInterface {
+	return m
+}
+
+func TestAppLandingExperimentHandler(t *testing.T) {
+	mockService := &MockAppLandingExperimentService{}
+	handler := appHandler.NewAppLandingExperimentHandler(mockService)
+
+	app := fiber.New()
+	app.Post("/app/landingpages/experiments/:experimentId/conversions", handler.HandleRecordConversion)
+
+	experimentId := uuid.New()
+	cookie := uuid.NewString()
+
+	postConversion := func(id string, headers map[string]string) int {
+		req := httptest.NewRequest("POST", "/app/landingpages/experiments/"+id+"/conversions", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	t.Run("POST /app/landingpages/experiments/:experimentId/conversions HandleRecordConversion", func(t *testing.T) {
+		t.Run("successfully record a conversion of the cookie visitor", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RecordConversion", experimentId, "cookie:"+cookie).Return(nil)
+
+			assert.Equal(t, fiber.StatusAccepted, postConversion(experimentId.String(), map[string]string{"Cookie": "ab_key=" + cookie}))
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("successfully record a conversion of the LINE user", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RecordConversion", experimentId, "line:U1234").Return(nil)
+
+			assert.Equal(t, fiber.StatusAccepted, postConversion(experimentId.String(), map[string]string{"X-Line-User-Id": "U1234", "Cookie": "ab_key=" + cookie}))
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to record conversion: visitor without assignment key", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RecordConversion", experimentId, "").Return(errs.ErrAssignmentKeyRequired)
+
+			assert.Equal(t, fiber.StatusBadRequest, postConversion(experimentId.String(), nil))
+		})
+
+		t.Run("failed to record conversion: no variant was served", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RecordConversion", experimentId, mock.Anything).Return(errs.ErrExperimentNotExposed)
+
+			assert.Equal(t, fiber.StatusNotFound, postConversion(experimentId.String(), map[string]string{"Cookie": "ab_key=" + cookie}))
+		})
+
+		t.Run("failed to record conversion: experiment is not running", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RecordConversion", experimentId, mock.Anything).Return(errs.ErrExperimentNotRunning)
+
+			assert.Equal(t, fiber.StatusConflict, postConversion(experimentId.String(), map[string]string{"Cookie": "ab_key=" + cookie}))
+		})
+
+		t.Run("failed to record conversion: invalid experimentId", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			assert.Equal(t, fiber.StatusBadRequest, postConversion("abc", nil))
+			mockService.AssertNotCalled(t, "RecordConversion", mock.Anything, mock.Anything)
+		})
+	})
+}
@@ -1,15 +1,22 @@
 package tests
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	appHandler "github.com/MadManJJ/cms-api/handlers/app"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -21,25 +28,38 @@ type MockAppLandingPageService struct {
 	mock.Mock
 }
 
-func (m *MockAppLandingPageService) GetLandingPageByUrlAlias(urlAlias string, selectParam string, language string) (*models.LandingPage, error) {
-	args := m.Called(urlAlias, selectParam, language)
+func (m *MockAppLandingPageService) GetLandingPageByUrlAlias(urlAlias string, selection dto.FieldSelection, language string) (*models.LandingPage, error) {
+	args := m.Called(urlAlias, selection, language)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.LandingPage), args.Error(1)	
 }
 
-func (m *MockAppLandingPageService) GetLandingContentPreview(id uuid.UUID) (*models.LandingContent, error) {
-	args := m.Called(id)
+func (m *MockAppLandingPageService) GetLandingContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.LandingContent, error) {
+	args := m.Called(id, selection)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.LandingContent), args.Error(1)	
 }
 
+type MockComponentRenderer struct {
+	mock.Mock
+}
+
+func (m *MockComponentRenderer) RenderComponents(cacheKey string, components []*models.Component) (string, error) {
+	args := m.Called(cacheKey, components)
+	return args.String(0), args.Error(1)
+}
+
 func TestAppLandingHandler(t *testing.T) {
 	mockService := &MockAppLandingPageService{}
-	handler := appHandler.NewAppLandingPageHandler(mockService)
+	mockPreviewLinkService := &MockPreviewLinkService{}
+	mockRenderer := &MockComponentRenderer{}
+	mockExperimentService := &MockAppLandingExperimentService{}
+	mockExperimentService.On("AssignVariant", mock.Anything, mock.Anything).Return(nil, nil)
+	handler := appHandler.NewAppLandingPageHandler(mockService, mockPreviewLinkService, mockRenderer, mockExperimentService, services.NewAppResponseCache(nil, &config.Config{}))
 
 	app := fiber.New()
 	app.Get("/app/landingpages/:languageCode/by-alias", handler.HandleGetLandingPageByUrlAlias)
@@ -48,10 +68,11 @@ func TestAppLandingHandler(t *testing.T) {
 		mockLandingPage := helpers.InitializeMockLandingPage()
 		urlAlias := "about/us"
 		selectParam := "revision,categories,components,metatag"
+		selection := dto.FieldSelection{Includes: map[string][]string{"revision": nil, "categories": nil, "components": nil, "meta_tag": nil}}
 		language := string(enums.PageLanguageEN)
 
 		t.Run("successfully get landing page (url_alias)", func(t *testing.T) {
-			mockService.On("GetLandingPageByUrlAlias", urlAlias, selectParam, language).Return(mockLandingPage, nil)		
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, selection, language).Return(mockLandingPage, nil)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&select=%s", language, urlAlias, selectParam), nil)
 			
@@ -63,7 +84,7 @@ func TestAppLandingHandler(t *testing.T) {
 
 		t.Run("failed to get landing page: internal server error (url_alias)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("GetLandingPageByUrlAlias", urlAlias, selectParam, language).Return(nil, errs.ErrInternalServerError)		
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, selection, language).Return(nil, errs.ErrInternalServerError)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&select=%s", language, urlAlias, selectParam), nil)
 			
@@ -75,7 +96,7 @@ func TestAppLandingHandler(t *testing.T) {
 
 		t.Run("failed to get landing page: not found (url_alias)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("GetLandingPageByUrlAlias", urlAlias, selectParam, language).Return(nil, errs.ErrNotFound)		
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, selection, language).Return(nil, errs.ErrNotFound)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&select=%s", language, urlAlias, selectParam), nil)
 			
@@ -85,4 +106,376 @@ func TestAppLandingHandler(t *testing.T) {
 			mockService.AssertExpectations(t)
 		})					
 	})
-}
\ No newline at end of file
+	t.Run("GET /app/landingpages/:languageCode/by-alias?render HandleGetLandingPageByAlias", func(t *testing.T) {
+		urlAlias := "about/us"
+		language := string(enums.PageLanguageEN)
+
+		t.Run("successfully render components as html", func(t *testing.T) {
+			mockLandingPage := helpers.InitializeMockLandingPage()
+			mockService.ExpectedCalls = nil
+			mockRenderer.ExpectedCalls = nil
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, dto.FieldSelection{Includes: map[string][]string{"meta_tag": nil, "components": nil}}, language).Return(mockLandingPage, nil)
+			mockRenderer.On("RenderComponents", mock.Anything, mock.Anything).Return("<h2>Title</h2>", nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&select=metatag&render=html", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			for _, content := range mockLandingPage.Contents {
+				assert.Equal(t, "<h2>Title</h2>", content.RenderedHTML)
+				assert.NotNil(t, content.Components)
+			}
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("successfully render components as html only", func(t *testing.T) {
+			mockLandingPage := helpers.InitializeMockLandingPage()
+			mockService.ExpectedCalls = nil
+			mockRenderer.ExpectedCalls = nil
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, dto.FieldSelection{}, language).Return(mockLandingPage, nil)
+			mockRenderer.On("RenderComponents", mock.Anything, mock.Anything).Return("<h2>Title</h2>", nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&render=html-only", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			for _, content := range mockLandingPage.Contents {
+				assert.Equal(t, "<h2>Title</h2>", content.RenderedHTML)
+				assert.Nil(t, content.Components)
+			}
+		})
+
+		t.Run("successfully render whole components for a projected request and reuse the render for a full one", func(t *testing.T) {
+			renderer, err := services.NewComponentRenderer(&config.Config{Render: config.RenderConfig{CacheSize: 10}})
+			assert.NoError(t, err)
+			renderApp := fiber.New()
+			renderApp.Get("/app/landingpages/:languageCode/by-alias", appHandler.NewAppLandingPageHandler(mockService, mockPreviewLinkService, renderer, mockExperimentService, services.NewAppResponseCache(nil, &config.Config{})).HandleGetLandingPageByUrlAlias)
+
+			contentId, updatedAt := uuid.New(), time.Now()
+			newPage := func() *models.LandingPage {
+				return &models.LandingPage{ID: uuid.New(), Contents: []*models.LandingContent{{
+					ID:         contentId,
+					UpdatedAt:  updatedAt,
+					Components: []*models.Component{{ID: uuid.New(), Type: enums.ComponentH21, Props: []byte(`{"text":"Welcome"}`)}},
+				}}}
+			}
+			mockService.ExpectedCalls = nil
+			mockExperimentService.ExpectedCalls = nil
+			mockExperimentService.On("AssignVariant", mock.Anything, mock.Anything).Return(nil, nil)
+			// The projected request still loads whole components, only its response is trimmed
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, dto.FieldSelection{Includes: map[string][]string{"components": nil}}, language).Return(newPage(), nil).Once()
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, dto.FieldSelection{}, language).Return(newPage(), nil).Once()
+
+			resp, err := renderApp.Test(httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&fields=components.type&render=html", language, urlAlias), nil))
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			body, _ := io.ReadAll(resp.Body)
+			assert.NotContains(t, string(body), `"props"`)
+
+			resp, err = renderApp.Test(httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&render=html", language, urlAlias), nil))
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			var full struct {
+				Data models.LandingPage `json:"data"`
+			}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&full))
+			if assert.Len(t, full.Data.Contents, 1) {
+				assert.Contains(t, full.Data.Contents[0].RenderedHTML, "<h2>Welcome</h2>")
+			}
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to render: invalid render mode", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&render=pdf", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetLandingPageByUrlAlias", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+	t.Run("GET /app/landingpages/:languageCode/by-alias?fields HandleGetLandingPageByAlias", func(t *testing.T) {
+		urlAlias := "about/us"
+		language := string(enums.PageLanguageEN)
+
+		t.Run("successfully trim the contents to the requested fields", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			selection := dto.FieldSelection{Fields: []string{"title"}, Includes: map[string][]string{"components": {"props"}}}
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, selection, language).Return(helpers.InitializeMockLandingPage(), nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&fields=title,components.props", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var body struct {
+				Data struct {
+					ID       string                   `json:"id"`
+					Contents []map[string]interface{} `json:"contents"`
+				} `json:"data"`
+			}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.NotEmpty(t, body.Data.ID)
+			content := body.Data.Contents[0]
+			assert.Contains(t, content, "id")
+			assert.Contains(t, content, "title")
+			assert.NotContains(t, content, "html_input")
+			assert.NotContains(t, content, "meta_tag")
+			for _, component := range content["components"].([]interface{}) {
+				assert.Contains(t, component, "props")
+				assert.NotContains(t, component, "type")
+			}
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get landing page: unknown field", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			selection := dto.FieldSelection{Fields: []string{"colour"}}
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, selection, language).Return(nil, fmt.Errorf("%w: colour", errs.ErrUnknownField))
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&fields=colour", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			assert.Contains(t, string(body), "unknown field: colour")
+		})
+
+		t.Run("failed to get landing page: field nested too deep", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&fields=components.props.title", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetLandingPageByUrlAlias", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+	t.Run("GET /app/landingpages/:languageCode/by-alias experiment HandleGetLandingPageByAlias", func(t *testing.T) {
+		urlAlias := "about/us"
+		language := string(enums.PageLanguageEN)
+		assignment := &dto.ExperimentAssignment{ExperimentID: uuid.New(), VariantID: uuid.New(), VariantName: "Split hero"}
+
+		t.Run("successfully serve the variant of a LINE user", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockExperimentService.ExpectedCalls = nil
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, dto.FieldSelection{}, language).Return(helpers.InitializeMockLandingPage(), nil)
+			mockExperimentService.On("AssignVariant", mock.Anything, "line:U1234").Return(assignment, nil).Once()
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s", language, urlAlias), nil)
+			req.Header.Set("X-Line-User-Id", "U1234")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, "Split hero", resp.Header.Get("X-Experiment-Variant"))
+			assert.Equal(t, "private, no-store", resp.Header.Get(fiber.HeaderCacheControl))
+			assert.Empty(t, resp.Header.Get(fiber.HeaderSetCookie))
+			assert.Contains(t, string(body), `"variant_name":"Split hero"`)
+			mockExperimentService.AssertExpectations(t)
+		})
+
+		t.Run("successfully issue the assignment cookie when a variant is served", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockExperimentService.ExpectedCalls = nil
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, dto.FieldSelection{}, language).Return(helpers.InitializeMockLandingPage(), nil)
+			mockExperimentService.On("AssignVariant", mock.Anything, mock.MatchedBy(func(key string) bool {
+				return strings.HasPrefix(key, "cookie:")
+			})).Return(assignment, nil).Once()
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, resp.Header.Get(fiber.HeaderSetCookie), "ab_key=")
+		})
+
+		t.Run("successfully render the variant under its own cache key", func(t *testing.T) {
+			mockLandingPage := helpers.InitializeMockLandingPage()
+			mockService.ExpectedCalls = nil
+			mockRenderer.ExpectedCalls = nil
+			mockExperimentService.ExpectedCalls = nil
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, dto.FieldSelection{}, language).Return(mockLandingPage, nil)
+			mockExperimentService.On("AssignVariant", mock.Anything, mock.Anything).Return(assignment, nil).Once()
+			mockRenderer.On("RenderComponents", mock.MatchedBy(func(key string) bool {
+				return strings.HasSuffix(key, ":"+assignment.VariantID.String())
+			}), mock.Anything).Return("<h2>Split hero</h2>", nil).Once()
+			mockRenderer.On("RenderComponents", mock.Anything, mock.Anything).Return("<h2>Title</h2>", nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&render=html", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, "<h2>Split hero</h2>", mockLandingPage.Contents[0].RenderedHTML)
+			mockRenderer.AssertExpectations(t)
+		})
+
+		t.Run("successfully skip the cookie when no experiment is running", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockExperimentService.ExpectedCalls = nil
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, dto.FieldSelection{}, language).Return(helpers.InitializeMockLandingPage(), nil)
+			mockExperimentService.On("AssignVariant", mock.Anything, mock.Anything).Return(nil, nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s", language, urlAlias), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Empty(t, resp.Header.Get(fiber.HeaderSetCookie))
+			assert.Empty(t, resp.Header.Get("X-Experiment-Variant"))
+		})
+	})
+
+	t.Run("GET /app/landingpages/:languageCode/by-alias caching HandleGetLandingPageByAlias", func(t *testing.T) {
+		cache := services.NewAppResponseCache(nil, &config.Config{AppCache: config.AppCacheConfig{Size: 10, MaxAge: time.Minute}})
+		cachedApp := fiber.New()
+		cachedApp.Get("/app/landingpages/:languageCode/by-alias", appHandler.NewAppLandingPageHandler(mockService, mockPreviewLinkService, mockRenderer, mockExperimentService, cache).HandleGetLandingPageByUrlAlias)
+
+		mockLandingPage := helpers.InitializeMockLandingPage()
+		urlAlias := "pricing"
+		language := string(enums.PageLanguageEN)
+		path := fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&fields=title,url_alias", language, urlAlias)
+		selection := dto.FieldSelection{Fields: []string{"title", "url_alias"}}
+		var etag, lastModified string
+		var firstBody []byte
+
+		t.Run("successfully send the caching headers", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockExperimentService.ExpectedCalls = nil
+			mockService.On("GetLandingPageByUrlAlias", urlAlias, selection, language).Return(mockLandingPage, nil).Once()
+			mockExperimentService.On("AssignVariant", mock.Anything, mock.Anything).Return(nil, nil)
+
+			resp, err := cachedApp.Test(httptest.NewRequest("GET", path, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			etag = resp.Header.Get(fiber.HeaderETag)
+			lastModified = resp.Header.Get(fiber.HeaderLastModified)
+			firstBody, _ = io.ReadAll(resp.Body)
+			assert.NotEmpty(t, etag)
+			assert.NotEmpty(t, lastModified)
+			assert.Equal(t, "public, max-age=60", resp.Header.Get(fiber.HeaderCacheControl))
+			assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("successfully serve the cached page without loading it, fields in any order", func(t *testing.T) {
+			mockService.Calls = nil
+
+			reordered := fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=%s&fields=url_alias,title", language, urlAlias)
+			resp, err := cachedApp.Test(httptest.NewRequest("GET", reordered, nil))
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, firstBody, body)
+			assert.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+			mockService.AssertNotCalled(t, "GetLandingPageByUrlAlias", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		t.Run("successfully answer a matching If-None-Match with 304", func(t *testing.T) {
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set(fiber.HeaderIfNoneMatch, `"other", `+etag)
+
+			resp, err := cachedApp.Test(req)
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+			assert.Empty(t, body)
+			assert.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+		})
+
+		t.Run("successfully answer an unchanged If-Modified-Since with 304", func(t *testing.T) {
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set(fiber.HeaderIfModifiedSince, lastModified)
+
+			resp, err := cachedApp.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+		})
+
+		t.Run("successfully send the page for a stale If-None-Match", func(t *testing.T) {
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set(fiber.HeaderIfNoneMatch, `"stale"`)
+			req.Header.Set(fiber.HeaderIfModifiedSince, lastModified)
+
+			resp, err := cachedApp.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("successfully reload the page once a related page changes", func(t *testing.T) {
+			relatedPageId := uuid.New()
+			related := helpers.InitializeMockLandingPage()
+			related.Contents[0].Related = []models.RelatedPage{{PageType: enums.PageTypePartner, PageID: relatedPageId, Title: "Case study"}}
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			mockService.On("GetLandingPageByUrlAlias", "related", dto.FieldSelection{}, language).Return(related, nil)
+
+			relatedPath := fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=related", language)
+			for i := 0; i < 2; i++ {
+				resp, err := cachedApp.Test(httptest.NewRequest("GET", relatedPath, nil))
+				assert.NoError(t, err)
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			}
+			mockService.AssertNumberOfCalls(t, "GetLandingPageByUrlAlias", 1)
+
+			cache.InvalidatePage(enums.PageTypePartner, relatedPageId)
+
+			resp, err := cachedApp.Test(httptest.NewRequest("GET", relatedPath, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertNumberOfCalls(t, "GetLandingPageByUrlAlias", 2)
+		})
+
+		t.Run("successfully reload the page once a media file it shows is replaced", func(t *testing.T) {
+			withMedia := helpers.InitializeMockLandingPage()
+			withMedia.Contents[0].HTMLInput = `<img src="https://api.example.com/files/banner.png">`
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			mockService.On("GetLandingPageByUrlAlias", "media", dto.FieldSelection{}, language).Return(withMedia, nil)
+
+			mediaPath := fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=media", language)
+			for i := 0; i < 2; i++ {
+				resp, err := cachedApp.Test(httptest.NewRequest("GET", mediaPath, nil))
+				assert.NoError(t, err)
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			}
+			mockService.AssertNumberOfCalls(t, "GetLandingPageByUrlAlias", 1)
+
+			cache.InvalidateMedia("/files/banner.png")
+
+			resp, err := cachedApp.Test(httptest.NewRequest("GET", mediaPath, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertNumberOfCalls(t, "GetLandingPageByUrlAlias", 2)
+		})
+
+		t.Run("successfully skip the cache while an experiment is running", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			mockExperimentService.ExpectedCalls = nil
+			mockService.On("GetLandingPageByUrlAlias", "experiment", dto.FieldSelection{}, language).Return(helpers.InitializeMockLandingPage(), nil)
+			mockExperimentService.On("AssignVariant", mock.Anything, mock.Anything).Return(&dto.ExperimentAssignment{VariantName: "B"}, nil)
+
+			experimentPath := fmt.Sprintf("/app/landingpages/%s/by-alias?url_alias=experiment", language)
+			for i := 0; i < 2; i++ {
+				resp, err := cachedApp.Test(httptest.NewRequest("GET", experimentPath, nil))
+				assert.NoError(t, err)
+				assert.Equal(t, "private, no-store", resp.Header.Get(fiber.HeaderCacheControl))
+				assert.Empty(t, resp.Header.Get(fiber.HeaderETag))
+			}
+			mockService.AssertNumberOfCalls(t, "GetLandingPageByUrlAlias", 2)
+		})
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models/enums"
@@ -24,7 +25,7 @@ func TestAppRepo_GetLandingPageBySlug(t *testing.T) {
 	language := string(enums.PageLanguageEN)
 
 	t.Run("successfully get landing page by slug: no preload (url alias)", func(t *testing.T) {
-		emptyPreload := []string{}
+		emptyPreload := dto.ContentProjection{}
 
 		pageId := uuid.New()
 		contentId := uuid.New()
@@ -75,7 +76,7 @@ func TestAppRepo_GetLandingPageBySlug(t *testing.T) {
 	})
 
 	t.Run("successfully get landing page by slug: preloads all (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories", "Contents.Components", "Contents.MetaTag"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil, "Components": nil, "MetaTag": nil}}
 		
 		pageId := uuid.New()
 		contentId := uuid.New()
@@ -121,7 +122,7 @@ func TestAppRepo_GetLandingPageBySlug(t *testing.T) {
 	})
 
 	t.Run("successfully get landing page by slug: partial preloads (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil}}
 
 		pageId := uuid.New()
 		contentId := uuid.New()
@@ -157,8 +158,38 @@ func TestAppRepo_GetLandingPageBySlug(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("successfully get landing page by slug: projected columns (url alias)", func(t *testing.T) {
+		projection := dto.ContentProjection{
+			Columns:  []string{"landing_contents.id", "landing_contents.page_id", "landing_contents.title"},
+			Preloads: map[string][]string{"Components": {"props", "id", "landing_content_id"}},
+		}
+
+		pageId := uuid.New()
+		contentId := uuid.New()
+		componentId := uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "landing_pages"."id","landing_pages"."created_at","landing_pages"."updated_at" FROM "landing_pages" JOIN landing_contents ON landing_contents.page_id = landing_pages.id WHERE landing_contents.url_alias = $1 ORDER BY "landing_pages"."id" LIMIT $2`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(pageId))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT landing_contents.id,landing_contents.page_id,landing_contents.title FROM "landing_contents"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "title"}).
+				AddRow(contentId, pageId, "Summer sale"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "props","id","landing_content_id" FROM "components"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"props", "id", "landing_content_id"}).
+				AddRow([]byte(`{}`), componentId, contentId))
+
+		landingPage, err := appLandingPageRepo.GetLandingPageByUrlAlias(slug, projection, language)
+		assert.NoError(t, err)
+		assert.Equal(t, "Summer sale", landingPage.Contents[0].Title)
+		assert.Len(t, landingPage.Contents[0].Components, 1)
+		assert.Nil(t, landingPage.Contents[0].MetaTag)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("failed to get landing page by slug (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories", "Contents.Components", "Contents.MetaTag"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil, "Components": nil, "MetaTag": nil}}
 
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "landing_pages"."id","landing_pages"."created_at","landing_pages"."updated_at" FROM "landing_pages" JOIN landing_contents ON landing_contents.page_id = landing_pages.id WHERE landing_contents.url_alias = $1 ORDER BY "landing_pages"."id" LIMIT $2`)).
 			WillReturnError(errs.ErrInternalServerError)
@@ -3,6 +3,8 @@ package tests
 import (
 	"testing"
 
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
@@ -14,49 +16,156 @@ import (
 )
 
 type MockAppLandingPageRepo struct {
-	getLandingPageByUrlAlias func(urlAlias string, preloads []string, language string) (*models.LandingPage, error)
-	getLandingContentPreview func(id uuid.UUID) (*models.LandingContent, error)
+	getLandingPageByUrlAlias func(urlAlias string, projection dto.ContentProjection, language string) (*models.LandingPage, error)
+	getLandingContentPreview func(id uuid.UUID, projection dto.ContentProjection) (*models.LandingContent, error)
+	findPublishedAlternates func(pageId uuid.UUID) ([]models.LandingContent, error)
 }
 
-func (m *MockAppLandingPageRepo) GetLandingPageByUrlAlias(urlAlias string, preloads []string, language string) (*models.LandingPage, error) {
-	return m.getLandingPageByUrlAlias(urlAlias, preloads, language)
+func (m *MockAppLandingPageRepo) GetLandingPageByUrlAlias(urlAlias string, projection dto.ContentProjection, language string) (*models.LandingPage, error) {
+	return m.getLandingPageByUrlAlias(urlAlias, projection, language)
 }
 
-func (m *MockAppLandingPageRepo) GetLandingContentPreview(id uuid.UUID) (*models.LandingContent, error) {
-	return m.getLandingContentPreview(id)
+func (m *MockAppLandingPageRepo) GetLandingContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.LandingContent, error) {
+	return m.getLandingContentPreview(id, projection)
+}
+
+func (m *MockAppLandingPageRepo) FindPublishedAlternates(pageId uuid.UUID) ([]models.LandingContent, error) {
+	return m.findPublishedAlternates(pageId)
+}
+
+type MockContentRelationService struct {
+	resolveRelations func(contentId uuid.UUID, language enums.PageLanguage) ([]models.RelatedPage, error)
+}
+
+func (m *MockContentRelationService) GetRelations(pageType enums.PageType, contentId uuid.UUID) ([]models.ContentRelation, error) {
+	return nil, nil
+}
+
+func (m *MockContentRelationService) ReplaceRelations(pageType enums.PageType, contentId uuid.UUID, request dto.ReplaceContentRelationsRequest) ([]models.ContentRelation, error) {
+	return nil, nil
+}
+
+func (m *MockContentRelationService) GetIncomingRelations(pageType enums.PageType, pageId uuid.UUID) ([]dto.IncomingRelation, error) {
+	return nil, nil
+}
+
+func (m *MockContentRelationService) ResolveRelations(contentId uuid.UUID, language enums.PageLanguage) ([]models.RelatedPage, error) {
+	if m.resolveRelations == nil {
+		return nil, nil
+	}
+	return m.resolveRelations(contentId, language)
+}
+
+func (m *MockContentRelationService) WithPageAccess(access *dto.PageAccess) services.CMSContentRelationServiceInterface {
+	return m
 }
 
 func TestAppService_GetLandingPage(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{WebBaseURL: "https://example.com"}}
 	urlAlias := "about/us"
 	language := string(enums.PageLanguageEN)
-	selectParam := "revision,categories,components,metatag"
+	selection := dto.FieldSelection{Includes: map[string][]string{"revision": nil, "categories": nil, "components": nil, "meta_tag": nil}}
 	
 	t.Run("successfully get landing page", func(t *testing.T) {
 		mockLandingPage := helpers.InitializeMockLandingPage()
 
 		repo := &MockAppLandingPageRepo{
-			getLandingPageByUrlAlias: func(urlAlias string, preloads []string, language string) (*models.LandingPage, error) {
+			getLandingPageByUrlAlias: func(urlAlias string, projection dto.ContentProjection, language string) (*models.LandingPage, error) {
 				return mockLandingPage, nil
 			},
+			findPublishedAlternates: func(pageId uuid.UUID) ([]models.LandingContent, error) {
+				return []models.LandingContent{}, nil
+			},
 		}
 
-		service := services.NewAppLandingPageService(repo)
+		service := services.NewAppLandingPageService(repo, &MockContentRelationService{}, cfg)
 
-		actualLandingPage, err := service.GetLandingPageByUrlAlias(urlAlias, selectParam, language)
+		actualLandingPage, err := service.GetLandingPageByUrlAlias(urlAlias, selection, language)
 		assert.NoError(t, err)
 		assert.Equal(t, mockLandingPage, actualLandingPage)
 	})	
 
+	t.Run("successfully attach canonical and hreflang alternates", func(t *testing.T) {
+		mockLandingPage := helpers.InitializeMockLandingPage()
+
+		repo := &MockAppLandingPageRepo{
+			getLandingPageByUrlAlias: func(urlAlias string, projection dto.ContentProjection, language string) (*models.LandingPage, error) {
+				return mockLandingPage, nil
+			},
+			findPublishedAlternates: func(pageId uuid.UUID) ([]models.LandingContent, error) {
+				return []models.LandingContent{
+					{Language: enums.PageLanguageTH, UrlAlias: "mock-landing-title-th"},
+				}, nil
+			},
+		}
+
+		service := services.NewAppLandingPageService(repo, &MockContentRelationService{}, cfg)
+
+		actualLandingPage, err := service.GetLandingPageByUrlAlias(urlAlias, selection, language)
+		assert.NoError(t, err)
+
+		metaTag := actualLandingPage.Contents[0].MetaTag
+		assert.Equal(t, "https://example.com/en/mock-landing-title", metaTag.CanonicalURL)
+		assert.Equal(t, []models.HrefLangAlternate{
+			{HrefLang: "en", Href: "https://example.com/en/mock-landing-title"},
+			{HrefLang: "th", Href: "https://example.com/th/mock-landing-title-th"},
+		}, metaTag.Alternates)
+		assert.NotEmpty(t, actualLandingPage.Contents[0].JSONLD)
+	})
+
+	t.Run("successfully fill the related articles component with the published related pages", func(t *testing.T) {
+		mockLandingPage := helpers.InitializeMockLandingPage()
+		content := mockLandingPage.Contents[0]
+		content.Components = append(content.Components, &models.Component{
+			Type:  enums.ComponentRelatedArticles,
+			Props: []byte(`{"title":"Read next","articles":[{"title":"Old title","url":"https://example.com/en/old"}]}`),
+		})
+		targetId := uuid.New()
+
+		repo := &MockAppLandingPageRepo{
+			getLandingPageByUrlAlias: func(urlAlias string, projection dto.ContentProjection, language string) (*models.LandingPage, error) {
+				return mockLandingPage, nil
+			},
+			findPublishedAlternates: func(pageId uuid.UUID) ([]models.LandingContent, error) {
+				return []models.LandingContent{}, nil
+			},
+		}
+		relationService := &MockContentRelationService{
+			resolveRelations: func(contentId uuid.UUID, language enums.PageLanguage) ([]models.RelatedPage, error) {
+				assert.Equal(t, content.ID, contentId)
+				return []models.RelatedPage{{
+					RelationType: enums.RelationRelatedArticle,
+					PageType:     enums.PageTypePartner,
+					PageID:       targetId,
+					Title:        "New title",
+					URL:          "https://example.com/en/partners/new",
+				}}, nil
+			},
+		}
+
+		service := services.NewAppLandingPageService(repo, relationService, cfg)
+
+		actualLandingPage, err := service.GetLandingPageByUrlAlias(urlAlias, selection, language)
+		assert.NoError(t, err)
+
+		actualContent := actualLandingPage.Contents[0]
+		assert.Len(t, actualContent.Related, 1)
+		props := string(actualContent.Components[len(actualContent.Components)-1].Props)
+		assert.Contains(t, props, `"title":"New title"`)
+		assert.Contains(t, props, `"title":"Read next"`)
+		assert.NotContains(t, props, "Old title")
+	})
+
 	t.Run("failed to get landing page", func(t *testing.T) {
 		repo := &MockAppLandingPageRepo{
-			getLandingPageByUrlAlias: func(urlAlias string, preloads []string, language string) (*models.LandingPage, error) {
+			getLandingPageByUrlAlias: func(urlAlias string, projection dto.ContentProjection, language string) (*models.LandingPage, error) {
 				return nil, errs.ErrInternalServerError
 			},
 		}
 
-		service := services.NewAppLandingPageService(repo)
+		service := services.NewAppLandingPageService(repo, &MockContentRelationService{}, cfg)
 
-		actualLandingPage, err := service.GetLandingPageByUrlAlias(urlAlias, selectParam, language)
+		actualLandingPage, err := service.GetLandingPageByUrlAlias(urlAlias, selection, language)
 		assert.Error(t, err)
 		assert.Nil(t, actualLandingPage)
 	})	
@@ -2,14 +2,18 @@ package tests
 
 import (
 	"fmt"
+	"io"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	appHandler "github.com/MadManJJ/cms-api/handlers/app"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -21,39 +25,50 @@ type MockAppPartnerPageService struct {
 	mock.Mock
 }
 
-func (m *MockAppPartnerPageService) GetPartnerPage(slug string, isAlias bool, selectParam string, language string) (*models.PartnerPage, error) {
-	args := m.Called(slug, isAlias, selectParam, language)
+func (m *MockAppPartnerPageService) GetPartnerPage(slug string, isAlias bool, selection dto.FieldSelection, language string) (*models.PartnerPage, error) {
+	args := m.Called(slug, isAlias, selection, language)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.PartnerPage), args.Error(1)	
 }	
 
-func (m *MockAppPartnerPageService) GetPartnerContentPreview(id uuid.UUID) (*models.PartnerContent, error) {
-	args := m.Called(id)
+func (m *MockAppPartnerPageService) GetPartnerContentPreview(id uuid.UUID, selection dto.FieldSelection) (*models.PartnerContent, error) {
+	args := m.Called(id, selection)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.PartnerContent), args.Error(1)	
 }
 
+func (m *MockAppPartnerPageService) FindPartnerListing(query *dto.PartnerListingQuery) ([]dto.PartnerCard, int64, *dto.PartnerListingFacets, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, 0, nil, args.Error(3)
+	}
+	return args.Get(0).([]dto.PartnerCard), args.Get(1).(int64), args.Get(2).(*dto.PartnerListingFacets), args.Error(3)
+}
+
 func TestAppHandlerPartnerHandler(t *testing.T) {
 	mockService := &MockAppPartnerPageService{}
-	handler := appHandler.NewAppPartnerPageHandler(mockService)
+	mockPreviewLinkService := &MockPreviewLinkService{}
+	handler := appHandler.NewAppPartnerPageHandler(mockService, mockPreviewLinkService, &MockComponentRenderer{}, services.NewAppResponseCache(nil, &config.Config{}))
 
 	app := fiber.New()
 	app.Get("/app/partnerpages/:languageCode/by-alias", handler.HandleGetPartnerPageByAlias)
 	app.Get("/app/partnerpages/:languageCode/by-url", handler.HandleGetPartnerPageByUrl)
+	app.Get("/app/partnerpages/:languageCode", handler.HandleGetPartnerListing)
 
 	t.Run("GET /app/partnerpages/:languageCode/by-alias HandleGetPartnerPageByAlias", func(t *testing.T) {
 		mockPartnerPage := helpers.InitializeMockPartnerPage()
 		slug := "about/us"
 		selectParam := "revision,categories,components,metatag"
+		selection := dto.FieldSelection{Includes: map[string][]string{"revision": nil, "categories": nil, "components": nil, "meta_tag": nil}}
 		language := string(enums.PageLanguageEN)
 
 		t.Run("successfully get partner page (url_alias)", func(t *testing.T) {
 			isAlias := true
-			mockService.On("GetPartnerPage", slug, isAlias, selectParam, language).Return(mockPartnerPage, nil)		
+			mockService.On("GetPartnerPage", slug, isAlias, selection, language).Return(mockPartnerPage, nil)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/partnerpages/%s/by-alias?url_alias=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -66,7 +81,7 @@ func TestAppHandlerPartnerHandler(t *testing.T) {
 		t.Run("failed to get partner page: internal server error (url_alias)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := true
-			mockService.On("GetPartnerPage", slug, isAlias, selectParam, language).Return(nil, errs.ErrInternalServerError)		
+			mockService.On("GetPartnerPage", slug, isAlias, selection, language).Return(nil, errs.ErrInternalServerError)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/partnerpages/%s/by-alias?url_alias=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -79,7 +94,7 @@ func TestAppHandlerPartnerHandler(t *testing.T) {
 		t.Run("failed to get partner page: not found (url_alias)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := true
-			mockService.On("GetPartnerPage", slug, isAlias, selectParam, language).Return(nil, errs.ErrNotFound)		
+			mockService.On("GetPartnerPage", slug, isAlias, selection, language).Return(nil, errs.ErrNotFound)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/partnerpages/%s/by-alias?url_alias=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -94,12 +109,13 @@ func TestAppHandlerPartnerHandler(t *testing.T) {
 		mockPartnerPage := helpers.InitializeMockPartnerPage()
 		slug := "about/us"
 		selectParam := "revision,categories,components,metatag"
+		selection := dto.FieldSelection{Includes: map[string][]string{"revision": nil, "categories": nil, "components": nil, "meta_tag": nil}}
 		language := string(enums.PageLanguageEN)
 				
 		t.Run("successfully get partner page (url)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := false
-			mockService.On("GetPartnerPage", slug, isAlias, selectParam, language).Return(mockPartnerPage, nil)		
+			mockService.On("GetPartnerPage", slug, isAlias, selection, language).Return(mockPartnerPage, nil)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/partnerpages/%s/by-url?url=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -112,7 +128,7 @@ func TestAppHandlerPartnerHandler(t *testing.T) {
 		t.Run("failed get partner page: internal server error (url)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := false
-			mockService.On("GetPartnerPage", slug, isAlias, selectParam, language).Return(nil, errs.ErrInternalServerError)		
+			mockService.On("GetPartnerPage", slug, isAlias, selection, language).Return(nil, errs.ErrInternalServerError)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/partnerpages/%s/by-url?url=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -125,7 +141,7 @@ func TestAppHandlerPartnerHandler(t *testing.T) {
 		t.Run("failed get partner page: not found (url)", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 			isAlias := false
-			mockService.On("GetPartnerPage", slug, isAlias, selectParam, language).Return(nil, errs.ErrNotFound)		
+			mockService.On("GetPartnerPage", slug, isAlias, selection, language).Return(nil, errs.ErrNotFound)		
 
 			req := httptest.NewRequest("GET", fmt.Sprintf("/app/partnerpages/%s/by-url?url=%s&select=%s", language, slug, selectParam), nil)
 			
@@ -135,4 +151,91 @@ func TestAppHandlerPartnerHandler(t *testing.T) {
 			mockService.AssertExpectations(t)
 		})	
 	})
-}
\ No newline at end of file
+
+	t.Run("GET /app/partnerpages/:languageCode HandleGetPartnerListing", func(t *testing.T) {
+		categoryId := uuid.New()
+
+		t.Run("successfully list partner pages", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			cards := []dto.PartnerCard{{ID: uuid.New(), Title: "Acme", IsRecommended: true}}
+			facets := &dto.PartnerListingFacets{Categories: []dto.PartnerCategoryFacet{}, Recommended: 1}
+			mockService.On("FindPartnerListing", mock.MatchedBy(func(query *dto.PartnerListingQuery) bool {
+				return query.Language == enums.PageLanguageEN &&
+					len(query.CategoryIDs) == 1 && query.CategoryIDs[0] == categoryId &&
+					query.IsRecommended != nil && *query.IsRecommended &&
+					query.Q == "acme" && query.Sort == "title:asc" && query.Page == 2 && query.Limit == 5
+			})).Return(cards, int64(6), facets, nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/app/partnerpages/en?categories=%s&recommended=true&q=acme&sort=title:asc&page=2&limit=5", categoryId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("successfully list only the requested card fields", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			cards := []dto.PartnerCard{{ID: uuid.New(), Title: "Acme", CompanyName: "Acme"}}
+			mockService.On("FindPartnerListing", mock.MatchedBy(func(query *dto.PartnerListingQuery) bool {
+				return len(query.Fields) == 2 && query.Fields[0] == "title" && query.Fields[1] == "url_alias"
+			})).Return(cards, int64(1), &dto.PartnerListingFacets{}, nil)
+
+			req := httptest.NewRequest("GET", "/app/partnerpages/en?fields=title,url_alias", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"title":"Acme"`)
+			assert.NotContains(t, string(body), "company_name")
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to list partner pages: include on cards", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/app/partnerpages/en?include=components", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "FindPartnerListing", mock.Anything)
+		})
+
+		t.Run("failed to list partner pages: invalid category id", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/app/partnerpages/en?categories=not-a-uuid", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "FindPartnerListing", mock.Anything)
+		})
+
+		t.Run("failed to list partner pages: invalid sort", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindPartnerListing", mock.Anything).Return(nil, int64(0), nil, errs.ErrInvalidListingSort)
+
+			req := httptest.NewRequest("GET", "/app/partnerpages/en?sort=views", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("failed to list partner pages: internal server error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindPartnerListing", mock.Anything).Return(nil, int64(0), nil, errs.ErrInternalServerError)
+
+			req := httptest.NewRequest("GET", "/app/partnerpages/en", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		})
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models/enums"
@@ -24,7 +25,7 @@ func TestAppRepo_GetPartnerPageBySlug(t *testing.T) {
 	language := string(enums.PageLanguageEN)
 
 	t.Run("successfully get partner page by slug: no preload (url alias)", func(t *testing.T) {
-		emptyPreload := []string{}
+		emptyPreload := dto.ContentProjection{}
 		isAlias := true
 
 		pageId := uuid.New()
@@ -71,7 +72,7 @@ func TestAppRepo_GetPartnerPageBySlug(t *testing.T) {
 	})
 
 	t.Run("successfully get partner page by slug: no preload (url)", func(t *testing.T) {
-		emptyPreload := []string{}
+		emptyPreload := dto.ContentProjection{}
 		isAlias := false
 
 		pageId := uuid.New()
@@ -118,7 +119,7 @@ func TestAppRepo_GetPartnerPageBySlug(t *testing.T) {
 	})
 
 	t.Run("successfully get partner page by slug: preloads all (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories", "Contents.Components", "Contents.MetaTag"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil, "Components": nil, "MetaTag": nil}}
 		isAlias := true
 
 		pageId := uuid.New()
@@ -165,7 +166,7 @@ func TestAppRepo_GetPartnerPageBySlug(t *testing.T) {
 	})
 
 	t.Run("successfully get partner page by slug: preloads all (url)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories", "Contents.Components", "Contents.MetaTag"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil, "Components": nil, "MetaTag": nil}}
 		isAlias := false
 
 		pageId := uuid.New()
@@ -212,7 +213,7 @@ func TestAppRepo_GetPartnerPageBySlug(t *testing.T) {
 	})
 
 	t.Run("successfully get partner page by slug: partial preloads (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil}}
 		isAlias := true
 
 		pageId := uuid.New()
@@ -250,7 +251,7 @@ func TestAppRepo_GetPartnerPageBySlug(t *testing.T) {
 	})
 
 	t.Run("successfully get partner page by slug: partial preloads (url)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil}}
 		isAlias := false
 
 		pageId := uuid.New()
@@ -288,7 +289,7 @@ func TestAppRepo_GetPartnerPageBySlug(t *testing.T) {
 	})
 
 	t.Run("failed to get partner page by slug (url alias)", func(t *testing.T) {
-		preloads := []string{"Contents.Revision", "Contents.Categories", "Contents.Components", "Contents.MetaTag"}
+		preloads := dto.ContentProjection{Preloads: map[string][]string{"Revision": nil, "Categories": nil, "Components": nil, "MetaTag": nil}}
 		isAlias := true
 
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "partner_pages"."id","partner_pages"."created_at","partner_pages"."updated_at" FROM "partner_pages" JOIN partner_contents ON partner_contents.page_id = partner_pages.id WHERE partner_contents.url_alias = $1 ORDER BY "partner_pages"."id" LIMIT $2`)).
@@ -299,4 +300,129 @@ func TestAppRepo_GetPartnerPageBySlug(t *testing.T) {
 		assert.Nil(t, partnerPage)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
-}
\ No newline at end of file
+}
+
+func TestAppRepo_FindPartnerListing(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	appPartnerPageRepo := repo.NewAppPartnerPageRepository(gormDB)
+
+	t.Run("successfully list partner contents ORing categories of the same type", func(t *testing.T) {
+		industryTypeId := uuid.New()
+		scaleTypeId := uuid.New()
+		retailId, foodId, smallId := uuid.New(), uuid.New(), uuid.New()
+		contentId := uuid.New()
+		isRecommended := true
+
+		query := dto.PartnerListingQuery{
+			Language:      enums.PageLanguageEN,
+			CategoryIDs:   []uuid.UUID{retailId, foodId, smallId},
+			CategoryMatch: dto.CategoryMatchAll,
+			IsRecommended: &isRecommended,
+			Q:             "acme",
+			Sort:          dto.PartnerSortTitleAsc,
+			Page:          2,
+			Limit:         5,
+		}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","category_type_id" FROM "categories" WHERE id IN ($1,$2,$3)`)).
+			WithArgs(retailId, foodId, smallId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "category_type_id"}).
+				AddRow(retailId, industryTypeId).
+				AddRow(foodId, industryTypeId).
+				AddRow(smallId, scaleTypeId))
+
+		hasCategory := `(EXISTS (SELECT 1 FROM partner_content_categories WHERE partner_content_categories.partner_content_id = partner_contents.id AND partner_content_categories.category_id IN `
+		where := `WHERE (partner_contents.language = $1 AND partner_contents.workflow_status = $2 AND partner_contents.mode NOT IN ($3,$4)) AND (partner_contents.title ILIKE $5 ESCAPE '\' OR partner_contents.company_name ILIKE $6 ESCAPE '\' OR partner_contents.lead_body ILIKE $7 ESCAPE '\') AND partner_contents.is_recommended = $8 AND ` +
+			hasCategory + `($9,$10))) AND ` + hasCategory + `($11)))`
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "partner_contents" ` + where)).
+			WithArgs(enums.PageLanguageEN, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, "%acme%", "%acme%", "%acme%", true, retailId, foodId, smallId).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT partner_contents.id,partner_contents.title,`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "is_recommended"}).
+				AddRow(contentId, "Acme", true))
+
+		partnerContents, totalCount, err := appPartnerPageRepo.FindPartnerListing(query)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(6), totalCount)
+		assert.Len(t, partnerContents, 1)
+		assert.Equal(t, contentId, partnerContents[0].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully match the wildcards of the text query literally", func(t *testing.T) {
+		query := dto.PartnerListingQuery{Language: enums.PageLanguageEN, Q: `50%_off\`, Sort: dto.PartnerSortRecommended, Page: 1, Limit: 12}
+		pattern := `%50\%\_off\\%`
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "partner_contents" WHERE (partner_contents.language = $1 AND partner_contents.workflow_status = $2 AND partner_contents.mode NOT IN ($3,$4)) AND (partner_contents.title ILIKE $5 ESCAPE '\' OR partner_contents.company_name ILIKE $6 ESCAPE '\' OR partner_contents.lead_body ILIKE $7 ESCAPE '\')`)).
+			WithArgs(enums.PageLanguageEN, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, pattern, pattern, pattern).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT partner_contents.id,partner_contents.title,`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		partnerContents, totalCount, err := appPartnerPageRepo.FindPartnerListing(query)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), totalCount)
+		assert.Empty(t, partnerContents)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully list nothing for an unknown category", func(t *testing.T) {
+		unknownId := uuid.New()
+		query := dto.PartnerListingQuery{Language: enums.PageLanguageEN, CategoryIDs: []uuid.UUID{unknownId}, CategoryMatch: dto.CategoryMatchAll, Sort: dto.PartnerSortRecommended, Page: 1, Limit: 12}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","category_type_id" FROM "categories"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "category_type_id"}))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "partner_contents" WHERE (partner_contents.language = $1 AND partner_contents.workflow_status = $2 AND partner_contents.mode NOT IN ($3,$4)) AND 1 = 0`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY partner_contents.is_recommended DESC, partner_contents.publish_on DESC, partner_contents.title ASC LIMIT $5`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		partnerContents, totalCount, err := appPartnerPageRepo.FindPartnerListing(query)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), totalCount)
+		assert.Empty(t, partnerContents)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAppRepo_FindPartnerListingFacets(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	appPartnerPageRepo := repo.NewAppPartnerPageRepository(gormDB)
+
+	t.Run("successfully count categories without the category filter and recommended without its own filter", func(t *testing.T) {
+		categoryId := uuid.New()
+		typeId := uuid.New()
+		isRecommended := false
+
+		query := dto.PartnerListingQuery{
+			Language:      enums.PageLanguageTH,
+			CategoryIDs:   []uuid.UUID{categoryId},
+			CategoryMatch: dto.CategoryMatchAny,
+			IsRecommended: &isRecommended,
+		}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT categories.id AS category_id, categories.name, categories.category_type_id, category_types.type_code, COUNT(DISTINCT partner_contents.id) AS count FROM "partner_contents" JOIN partner_content_categories ON partner_content_categories.partner_content_id = partner_contents.id JOIN categories ON categories.id = partner_content_categories.category_id JOIN category_types ON category_types.id = categories.category_type_id WHERE categories.publish_status = $1 AND (partner_contents.language = $2 AND partner_contents.workflow_status = $3 AND partner_contents.mode NOT IN ($4,$5)) AND partner_contents.is_recommended = $6 GROUP BY`)).
+			WithArgs(enums.PublishStatusPublished, enums.PageLanguageTH, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, false).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id", "name", "category_type_id", "type_code", "count"}).
+				AddRow(categoryId, "Retail", typeId, "category-industry", 3))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "partner_contents" WHERE partner_contents.is_recommended = $1 AND (partner_contents.language = $2 AND partner_contents.workflow_status = $3 AND partner_contents.mode NOT IN ($4,$5)) AND (EXISTS (SELECT 1 FROM partner_content_categories WHERE partner_content_categories.partner_content_id = partner_contents.id AND partner_content_categories.category_id IN ($6)))`)).
+			WithArgs(true, enums.PageLanguageTH, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, categoryId).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		facets, err := appPartnerPageRepo.FindPartnerListingFacets(query)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.PartnerCategoryFacet{{CategoryID: categoryId, Name: "Retail", CategoryTypeID: typeId, TypeCode: "category-industry", Count: 3}}, facets.Categories)
+		assert.Equal(t, int64(2), facets.Recommended)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
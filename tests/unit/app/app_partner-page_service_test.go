@@ -3,6 +3,8 @@ package tests
 import (
 	"testing"
 
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
@@ -14,51 +16,194 @@ import (
 )
 
 type MockAppPartnerPageRepo struct {
-	getPartnerPageBySlug func(slug string, preloads []string, isAlias bool, language string) (*models.PartnerPage, error)
-	getPartnerContentPreview func(id uuid.UUID) (*models.PartnerContent, error)
+	getPartnerPageBySlug func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.PartnerPage, error)
+	getPartnerContentPreview func(id uuid.UUID, projection dto.ContentProjection) (*models.PartnerContent, error)
+	findPublishedAlternates func(pageId uuid.UUID) ([]models.PartnerContent, error)
+	findPartnerListing func(query dto.PartnerListingQuery) ([]models.PartnerContent, int64, error)
+	findPartnerListingFacets func(query dto.PartnerListingQuery) (*dto.PartnerListingFacets, error)
 }
 
-func (m *MockAppPartnerPageRepo) GetPartnerPageBySlug(slug string, preloads []string, isAlias bool, language string) (*models.PartnerPage, error) {
-	return m.getPartnerPageBySlug(slug, preloads, isAlias, language)
+func (m *MockAppPartnerPageRepo) GetPartnerPageBySlug(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.PartnerPage, error) {
+	return m.getPartnerPageBySlug(slug, projection, isAlias, language)
 }
 
-func (m *MockAppPartnerPageRepo) GetPartnerContentPreview(id uuid.UUID) (*models.PartnerContent, error) {
-	return m.getPartnerContentPreview(id)
+func (m *MockAppPartnerPageRepo) GetPartnerContentPreview(id uuid.UUID, projection dto.ContentProjection) (*models.PartnerContent, error) {
+	return m.getPartnerContentPreview(id, projection)
+}
+
+func (m *MockAppPartnerPageRepo) FindPublishedAlternates(pageId uuid.UUID) ([]models.PartnerContent, error) {
+	return m.findPublishedAlternates(pageId)
+}
+
+func (m *MockAppPartnerPageRepo) FindPartnerListing(query dto.PartnerListingQuery) ([]models.PartnerContent, int64, error) {
+	return m.findPartnerListing(query)
+}
+
+func (m *MockAppPartnerPageRepo) FindPartnerListingFacets(query dto.PartnerListingQuery) (*dto.PartnerListingFacets, error) {
+	return m.findPartnerListingFacets(query)
 }
 
 func TestAppService_GetPartnerPage(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{WebBaseURL: "https://example.com"}}
 	slug := "about/us"
 	language := string(enums.PageLanguageEN)
-	selectParam := "revision,categories,components,metatag"
+	selection := dto.FieldSelection{Includes: map[string][]string{"revision": nil, "categories": nil, "components": nil, "meta_tag": nil}}
 	isAlias := true
 
 	t.Run("successfully get partner page", func(t *testing.T) {
 		mockPartnerPage := helpers.InitializeMockPartnerPage()
 
 		repo := &MockAppPartnerPageRepo{
-			getPartnerPageBySlug: func(slug string, preloads []string, isAlias bool, language string) (*models.PartnerPage, error) {
+			getPartnerPageBySlug: func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.PartnerPage, error) {
 				return mockPartnerPage, nil
 			},
+			findPublishedAlternates: func(pageId uuid.UUID) ([]models.PartnerContent, error) {
+				return []models.PartnerContent{}, nil
+			},
 		}
 
-		service := services.NewAppPartnerPageService(repo)
+		service := services.NewAppPartnerPageService(repo, &MockContentRelationService{}, cfg)
 
-		actualPartnerPage, err := service.GetPartnerPage(slug, isAlias, selectParam, language)
+		actualPartnerPage, err := service.GetPartnerPage(slug, isAlias, selection, language)
 		assert.NoError(t, err)
 		assert.Equal(t, mockPartnerPage, actualPartnerPage)
 	})	
 
+	t.Run("successfully attach canonical and hreflang alternates", func(t *testing.T) {
+		mockPartnerPage := helpers.InitializeMockPartnerPage()
+
+		repo := &MockAppPartnerPageRepo{
+			getPartnerPageBySlug: func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.PartnerPage, error) {
+				return mockPartnerPage, nil
+			},
+			findPublishedAlternates: func(pageId uuid.UUID) ([]models.PartnerContent, error) {
+				return []models.PartnerContent{
+					{Language: enums.PageLanguageTH, URL: "/partners/mock-partner-title-th"},
+				}, nil
+			},
+		}
+
+		service := services.NewAppPartnerPageService(repo, &MockContentRelationService{}, cfg)
+
+		actualPartnerPage, err := service.GetPartnerPage(slug, isAlias, selection, language)
+		assert.NoError(t, err)
+
+		metaTag := actualPartnerPage.Contents[0].MetaTag
+		assert.Equal(t, "https://example.com/en/partners/mock-partner-title", metaTag.CanonicalURL)
+		assert.Equal(t, []models.HrefLangAlternate{
+			{HrefLang: "en", Href: "https://example.com/en/partners/mock-partner-title"},
+			{HrefLang: "th", Href: "https://example.com/th/partners/mock-partner-title-th"},
+		}, metaTag.Alternates)
+		assert.NotEmpty(t, actualPartnerPage.Contents[0].JSONLD)
+	})
+
+	t.Run("successfully project the requested fields", func(t *testing.T) {
+		mockPartnerPage := helpers.InitializeMockPartnerPage()
+		var projected dto.ContentProjection
+
+		repo := &MockAppPartnerPageRepo{
+			getPartnerPageBySlug: func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.PartnerPage, error) {
+				projected = projection
+				return mockPartnerPage, nil
+			},
+		}
+
+		service := services.NewAppPartnerPageService(repo, &MockContentRelationService{}, cfg)
+
+		fieldSelection := dto.FieldSelection{Fields: []string{"title"}, Includes: map[string][]string{"components": {"props"}}}
+		actualPartnerPage, err := service.GetPartnerPage(slug, isAlias, fieldSelection, language)
+		assert.NoError(t, err)
+		assert.Contains(t, projected.Columns, "partner_contents.title")
+		assert.NotContains(t, projected.Columns, "partner_contents.lead_body")
+		assert.Equal(t, map[string][]string{"Components": {"props", "id", "partner_content_id"}}, projected.Preloads)
+		assert.Nil(t, actualPartnerPage.Contents[0].MetaTag)
+		assert.Empty(t, actualPartnerPage.Contents[0].JSONLD)
+	})
+
+	t.Run("failed to get partner page: unknown field", func(t *testing.T) {
+		repo := &MockAppPartnerPageRepo{}
+
+		service := services.NewAppPartnerPageService(repo, &MockContentRelationService{}, cfg)
+
+		fieldSelection := dto.FieldSelection{Includes: map[string][]string{"components": {"colour"}}}
+		actualPartnerPage, err := service.GetPartnerPage(slug, isAlias, fieldSelection, language)
+		assert.ErrorIs(t, err, errs.ErrUnknownField)
+		assert.EqualError(t, err, "unknown field: components.colour")
+		assert.Nil(t, actualPartnerPage)
+	})
+
 	t.Run("failed to get partner page", func(t *testing.T) {
 		repo := &MockAppPartnerPageRepo{
-			getPartnerPageBySlug: func(slug string, preloads []string, isAlias bool, language string) (*models.PartnerPage, error) {
+			getPartnerPageBySlug: func(slug string, projection dto.ContentProjection, isAlias bool, language string) (*models.PartnerPage, error) {
 				return nil, errs.ErrInternalServerError
 			},
 		}
 
-		service := services.NewAppPartnerPageService(repo)
+		service := services.NewAppPartnerPageService(repo, &MockContentRelationService{}, cfg)
 
-		actualPartnerPage, err := service.GetPartnerPage(slug, isAlias, selectParam, language)
+		actualPartnerPage, err := service.GetPartnerPage(slug, isAlias, selection, language)
 		assert.Error(t, err)
 		assert.Nil(t, actualPartnerPage)
 	})	
-}
\ No newline at end of file
+}
+
+func TestAppService_FindPartnerListing(t *testing.T) {
+	cfg := &config.Config{}
+
+	t.Run("successfully list partner cards with normalized query", func(t *testing.T) {
+		categoryId := uuid.New()
+		contentId := uuid.New()
+		var listedQuery dto.PartnerListingQuery
+
+		repo := &MockAppPartnerPageRepo{
+			findPartnerListing: func(query dto.PartnerListingQuery) ([]models.PartnerContent, int64, error) {
+				listedQuery = query
+				return []models.PartnerContent{{ID: contentId, Title: "Acme", CompanyName: "Acme Co", URL: "/partners/acme", IsRecommended: true}}, 1, nil
+			},
+			findPartnerListingFacets: func(query dto.PartnerListingQuery) (*dto.PartnerListingFacets, error) {
+				return &dto.PartnerListingFacets{Recommended: 1}, nil
+			},
+		}
+		service := services.NewAppPartnerPageService(repo, &MockContentRelationService{}, cfg)
+
+		query := &dto.PartnerListingQuery{Language: "EN", CategoryIDs: []uuid.UUID{categoryId, categoryId}, Q: "  acme ", Limit: 500}
+		cards, totalCount, facets, err := service.FindPartnerListing(query)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), totalCount)
+		assert.Equal(t, int64(1), facets.Recommended)
+		assert.Equal(t, []dto.PartnerCard{{ID: contentId, Title: "Acme", CompanyName: "Acme Co", URL: "/partners/acme", IsRecommended: true}}, cards)
+
+		assert.Equal(t, enums.PageLanguageEN, listedQuery.Language)
+		assert.Equal(t, []uuid.UUID{categoryId}, listedQuery.CategoryIDs)
+		assert.Equal(t, dto.CategoryMatchAll, listedQuery.CategoryMatch)
+		assert.Equal(t, dto.PartnerSortRecommended, listedQuery.Sort)
+		assert.Equal(t, "acme", listedQuery.Q)
+		assert.Equal(t, 1, query.Page)
+		assert.Equal(t, dto.PartnerListingMaxLimit, query.Limit)
+	})
+
+	t.Run("failed to list partner cards: invalid query", func(t *testing.T) {
+		service := services.NewAppPartnerPageService(&MockAppPartnerPageRepo{}, &MockContentRelationService{}, cfg)
+
+		_, _, _, err := service.FindPartnerListing(&dto.PartnerListingQuery{Language: "fr"})
+		assert.ErrorIs(t, err, errs.ErrInvalidLanguageCode)
+
+		_, _, _, err = service.FindPartnerListing(&dto.PartnerListingQuery{Language: "en", Sort: "views"})
+		assert.ErrorIs(t, err, errs.ErrInvalidListingSort)
+
+		_, _, _, err = service.FindPartnerListing(&dto.PartnerListingQuery{Language: "en", CategoryMatch: "none"})
+		assert.ErrorIs(t, err, errs.ErrInvalidCategoryMatch)
+	})
+
+	t.Run("failed to list partner cards: repository error", func(t *testing.T) {
+		repo := &MockAppPartnerPageRepo{
+			findPartnerListing: func(query dto.PartnerListingQuery) ([]models.PartnerContent, int64, error) {
+				return nil, 0, errs.ErrInternalServerError
+			},
+		}
+		service := services.NewAppPartnerPageService(repo, &MockContentRelationService{}, cfg)
+
+		_, _, _, err := service.FindPartnerListing(&dto.PartnerListingQuery{Language: "en"})
+		assert.ErrorIs(t, err, errs.ErrInternalServerError)
+	})
+}
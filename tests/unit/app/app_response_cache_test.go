@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCachedResponse(body string) *dto.CachedResponse {
+	return &dto.CachedResponse{Body: []byte(body), ETag: `"` + body + `"`, LoadedAt: time.Now()}
+}
+
+type MockAppCacheRepo struct {
+	notified []string
+	err      error
+}
+
+func (m *MockAppCacheRepo) NotifyInvalidation(tag string) error {
+	m.notified = append(m.notified, tag)
+	return m.err
+}
+
+func TestAppResponseCache(t *testing.T) {
+	newCache := func(size int, ttl time.Duration) *services.AppResponseCache {
+		return services.NewAppResponseCache(nil, &config.Config{AppCache: config.AppCacheConfig{Size: size, TTL: ttl}})
+	}
+
+	t.Run("successfully evict the least recently used response", func(t *testing.T) {
+		cache := newCache(2, 0)
+		cache.Set("a", newCachedResponse("a"), dto.CacheTags{})
+		cache.Set("b", newCachedResponse("b"), dto.CacheTags{})
+		_, _ = cache.Get("a")
+		cache.Set("c", newCachedResponse("c"), dto.CacheTags{})
+
+		_, ok := cache.Get("b")
+		assert.False(t, ok)
+		_, ok = cache.Get("a")
+		assert.True(t, ok)
+		_, ok = cache.Get("c")
+		assert.True(t, ok)
+	})
+
+	t.Run("successfully drop only the responses built from a page, category or form", func(t *testing.T) {
+		cache := newCache(10, 0)
+		pageId, relatedPageId, categoryId, formId := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		cache.Set("page", newCachedResponse("page"), dto.CacheTags{
+			Pages:       []dto.CachedPage{{PageType: enums.PageTypeLanding, PageID: pageId}, {PageType: enums.PageTypeFaq, PageID: relatedPageId}},
+			CategoryIDs: []uuid.UUID{categoryId},
+		})
+		cache.Set("other", newCachedResponse("other"), dto.CacheTags{Pages: []dto.CachedPage{{PageType: enums.PageTypeLanding, PageID: uuid.New()}}})
+		cache.Set("form", newCachedResponse("form"), dto.CacheTags{FormIDs: []uuid.UUID{formId}})
+
+		// The same id under another page type is a different page
+		cache.InvalidatePage(enums.PageTypePartner, relatedPageId)
+		_, ok := cache.Get("page")
+		assert.True(t, ok)
+
+		cache.InvalidatePage(enums.PageTypeFaq, relatedPageId)
+		_, ok = cache.Get("page")
+		assert.False(t, ok)
+		_, ok = cache.Get("other")
+		assert.True(t, ok)
+
+		cache.Set("page", newCachedResponse("page"), dto.CacheTags{CategoryIDs: []uuid.UUID{categoryId}})
+		cache.InvalidateCategory(categoryId)
+		_, ok = cache.Get("page")
+		assert.False(t, ok)
+
+		cache.InvalidateForm(formId)
+		_, ok = cache.Get("form")
+		assert.False(t, ok)
+		_, ok = cache.Get("other")
+		assert.True(t, ok)
+	})
+
+	t.Run("successfully drop the responses linking to a media file", func(t *testing.T) {
+		cache := newCache(10, 0)
+		downloadURL := "https://cdn.example.com/media/banner.png?w=1&h=2"
+		cache.Set("full", newCachedResponse("full"), dto.CacheTags{MediaURLs: []string{downloadURL}})
+		cache.Set("path", newCachedResponse("path"), dto.CacheTags{MediaURLs: []string{"/media/banner.png"}})
+		cache.Set("unrelated", newCachedResponse("unrelated"), dto.CacheTags{MediaURLs: []string{"https://cdn.example.com/media/logo.png"}})
+		// Only the tags are looked at, not the body
+		cache.Set("untagged", newCachedResponse(`{"image":"`+downloadURL+`"}`), dto.CacheTags{})
+
+		cache.InvalidateMedia(downloadURL)
+
+		_, ok := cache.Get("full")
+		assert.False(t, ok)
+		_, ok = cache.Get("path")
+		assert.False(t, ok)
+		_, ok = cache.Get("unrelated")
+		assert.True(t, ok)
+		_, ok = cache.Get("untagged")
+		assert.True(t, ok)
+	})
+
+	t.Run("successfully expire a response after the TTL", func(t *testing.T) {
+		cache := newCache(10, time.Millisecond)
+		cache.Set("a", newCachedResponse("a"), dto.CacheTags{})
+		time.Sleep(5 * time.Millisecond)
+
+		_, ok := cache.Get("a")
+		assert.False(t, ok)
+	})
+
+	t.Run("successfully skip a response read before an invalidation", func(t *testing.T) {
+		cache := newCache(10, 0)
+		stale := newCachedResponse("stale")
+		cache.InvalidatePage(enums.PageTypeLanding, uuid.New())
+
+		cache.Set("stale", stale, dto.CacheTags{})
+		_, ok := cache.Get("stale")
+		assert.False(t, ok)
+	})
+
+	t.Run("successfully store nothing when the size is 0", func(t *testing.T) {
+		cache := newCache(0, 0)
+		cache.Set("a", newCachedResponse("a"), dto.CacheTags{})
+
+		_, ok := cache.Get("a")
+		assert.False(t, ok)
+	})
+
+	t.Run("successfully drop the responses of a saved page and a replaced media file when their events are handled", func(t *testing.T) {
+		cache := newCache(10, 0)
+		pageId := uuid.New()
+		downloadURL := "https://cdn.example.com/media/banner.png"
+		cache.Set("page", newCachedResponse("page"), dto.CacheTags{Pages: []dto.CachedPage{{PageType: enums.PageTypeLanding, PageID: pageId}}})
+		cache.Set("media", newCachedResponse("media"), dto.CacheTags{MediaURLs: []string{downloadURL}})
+
+		saved, err := helpers.NewOutboxEvent(enums.DomainEventContentSaved, pageId, "", dto.ContentEventData{PageType: enums.PageTypeLanding, PageID: pageId})
+		require.NoError(t, err)
+		require.NoError(t, cache.HandleDomainEvent(context.Background(), saved))
+		_, ok := cache.Get("page")
+		assert.False(t, ok)
+
+		// A new file is not linked from anything yet
+		uploaded, err := helpers.NewOutboxEvent(enums.DomainEventMediaFileUploaded, uuid.New(), "", dto.MediaFileEventData{DownloadURL: downloadURL})
+		require.NoError(t, err)
+		require.NoError(t, cache.HandleDomainEvent(context.Background(), uploaded))
+		_, ok = cache.Get("media")
+		assert.True(t, ok)
+
+		replaced, err := helpers.NewOutboxEvent(enums.DomainEventMediaFileUploaded, uuid.New(), "", dto.MediaFileEventData{DownloadURL: downloadURL, Replaced: true})
+		require.NoError(t, err)
+		require.NoError(t, cache.HandleDomainEvent(context.Background(), replaced))
+		_, ok = cache.Get("media")
+		assert.False(t, ok)
+	})
+
+	t.Run("successfully purge every response", func(t *testing.T) {
+		cache := newCache(10, 0)
+		cache.Set("a", newCachedResponse("a"), dto.CacheTags{})
+		cache.Purge()
+
+		_, ok := cache.Get("a")
+		assert.False(t, ok)
+	})
+
+	t.Run("successfully broadcast the invalidations to the other instances", func(t *testing.T) {
+		repo := &MockAppCacheRepo{}
+		cache := services.NewAppResponseCache(repo, &config.Config{AppCache: config.AppCacheConfig{Size: 10}})
+		pageId, categoryId := uuid.New(), uuid.New()
+		cache.Set("page", newCachedResponse("page"), dto.CacheTags{Pages: []dto.CachedPage{{PageType: enums.PageTypeLanding, PageID: pageId}}})
+
+		cache.InvalidatePage(enums.PageTypeLanding, pageId)
+		cache.InvalidateCategory(categoryId)
+		cache.InvalidateMedia("https://cdn.example.com/media/banner.png")
+		cache.Purge()
+
+		// Applied here without waiting for the notification
+		_, ok := cache.Get("page")
+		assert.False(t, ok)
+		assert.Equal(t, []string{
+			"page:landing:" + pageId.String(),
+			"category:" + categoryId.String(),
+			"media:/media/banner.png",
+			"*",
+		}, repo.notified)
+	})
+
+	t.Run("successfully apply an invalidation locally when the broadcast fails", func(t *testing.T) {
+		repo := &MockAppCacheRepo{err: errors.New("connection refused")}
+		cache := services.NewAppResponseCache(repo, &config.Config{AppCache: config.AppCacheConfig{Size: 10}})
+		formId := uuid.New()
+		cache.Set("form", newCachedResponse("form"), dto.CacheTags{FormIDs: []uuid.UUID{formId}})
+
+		cache.InvalidateForm(formId)
+
+		_, ok := cache.Get("form")
+		assert.False(t, ok)
+	})
+}
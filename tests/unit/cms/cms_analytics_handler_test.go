@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCMSAnalyticsService struct {
+	mock.Mock
+}
+
+func (m *MockCMSAnalyticsService) TrackPageView(request dto.PageViewRequest, clientIP, userAgent string) error {
+	args := m.Called(request, clientIP, userAgent)
+	return args.Error(0)
+}
+
+func (m *MockCMSAnalyticsService) GetPageAnalytics(pageType enums.PageType, pageId uuid.UUID, query dto.AnalyticsQuery) (*dto.PageAnalytics, error) {
+	args := m.Called(pageType, pageId, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PageAnalytics), args.Error(1)
+}
+
+func (m *MockCMSAnalyticsService) GetTopPages(query dto.AnalyticsQuery, limit int) ([]dto.TopPage, error) {
+	args := m.Called(query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.TopPage), args.Error(1)
+}
+
+func (m *MockCMSAnalyticsService) GetCampaignTraffic(query dto.AnalyticsQuery, limit int) ([]dto.CampaignTraffic, error) {
+	args := m.Called(query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.CampaignTraffic), args.Error(1)
+}
+
+func TestCMSAnalyticsHandler(t *testing.T) {
+	mockService := &MockCMSAnalyticsService{}
+	handler := cmsHandler.NewCMSAnalyticsHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/analytics/pages/:pageType/:pageId", handler.HandleGetPageAnalytics)
+	app.Get("/cms/analytics/top", handler.HandleGetTopPages)
+	app.Get("/cms/analytics/campaigns", handler.HandleGetCampaignTraffic)
+
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 23, 59, 59, 999999999, time.UTC)
+	pageId := uuid.New()
+
+	t.Run("GET /cms/analytics/pages/:pageType/:pageId HandleGetPageAnalytics", func(t *testing.T) {
+		t.Run("successfully get page analytics", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			query := dto.AnalyticsQuery{From: from, To: to, Language: enums.PageLanguageEN}
+			mockService.On("GetPageAnalytics", enums.PageTypeLanding, pageId, query).Return(&dto.PageAnalytics{PageID: pageId, Views: 15}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/analytics/pages/landing/"+pageId.String()+"?from=2025-07-01&to=2025-07-31&language=en", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"views":15`)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get page analytics: invalid pageId", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/cms/analytics/pages/landing/abc", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetPageAnalytics", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		t.Run("failed to get page analytics: invalid page type", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetPageAnalytics", enums.PageType("blog"), pageId, mock.Anything).Return(nil, errs.ErrInvalidPageType)
+
+			req := httptest.NewRequest("GET", "/cms/analytics/pages/blog/"+pageId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/analytics/top HandleGetTopPages", func(t *testing.T) {
+		t.Run("successfully get top pages", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetTopPages", mock.MatchedBy(func(query dto.AnalyticsQuery) bool {
+				return query.PageType == enums.PageTypeFaq && query.To.Sub(query.From) == 30*24*time.Hour
+			}), 5).Return([]dto.TopPage{{PageID: pageId, Title: "Reset password", Views: 40}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/analytics/top?pageType=faq&limit=5", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), "Reset password")
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get top pages: invalid date", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/cms/analytics/top?to=soon", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetTopPages", mock.Anything, mock.Anything)
+		})
+	})
+
+	t.Run("GET /cms/analytics/campaigns HandleGetCampaignTraffic", func(t *testing.T) {
+		t.Run("successfully get campaign traffic of a page", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetCampaignTraffic", mock.MatchedBy(func(query dto.AnalyticsQuery) bool {
+				return query.PageID != nil && *query.PageID == pageId
+			}), 20).Return([]dto.CampaignTraffic{{UTMCampaign: "summer-sale", Views: 30}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/analytics/campaigns?pageId="+pageId.String(), nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), "summer-sale")
+		})
+
+		t.Run("failed to get campaign traffic: database error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetCampaignTraffic", mock.Anything, 20).Return(nil, assert.AnError)
+
+			req := httptest.NewRequest("GET", "/cms/analytics/campaigns", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		})
+	})
+}
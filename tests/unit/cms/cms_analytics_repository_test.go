@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCMSRepo_Analytics(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAnalyticsRepo := repo.NewCMSAnalyticsRepository(gormDB)
+
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+	pageId := uuid.New()
+
+	t.Run("successfully write page views in one insert", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "page_views" ("page_type","page_id","language","visitor_hash","referrer_host","utm_source","utm_medium","utm_campaign","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9),($10,$11,$12,$13,$14,$15,$16,$17,$18) RETURNING "id"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()).AddRow(uuid.New()))
+		mock.ExpectCommit()
+
+		err := cmsAnalyticsRepo.CreatePageViews([]*models.PageView{
+			{PageType: enums.PageTypeLanding, PageID: pageId, Language: enums.PageLanguageEN, VisitorHash: "a", CreatedAt: day},
+			{PageType: enums.PageTypeLanding, PageID: pageId, Language: enums.PageLanguageEN, VisitorHash: "b", CreatedAt: day},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully find the latest rollup day", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT MAX(day) FROM "page_view_dailies"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(day))
+
+		latest, err := cmsAnalyticsRepo.FindLatestRollupDay()
+		assert.NoError(t, err)
+		assert.Equal(t, day, *latest)
+	})
+
+	t.Run("successfully find no earliest page view", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT MIN(created_at) FROM "page_views"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(nil))
+
+		earliest, err := cmsAnalyticsRepo.FindEarliestPageView()
+		assert.NoError(t, err)
+		assert.Nil(t, earliest)
+	})
+
+	t.Run("successfully replace the rollup of a day", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "page_view_dailies" WHERE day = $1`)).
+			WithArgs(day).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "page_visitor_dailies" WHERE day = $1`)).
+			WithArgs(day).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO page_view_dailies (day, page_type, page_id, language, referrer_host, utm_source, utm_medium, utm_campaign, views) SELECT CAST($1 AS date), page_type, page_id, language, referrer_host, utm_source, utm_medium, utm_campaign, COUNT(*) FROM page_views WHERE created_at >= $2 AND created_at < $3 GROUP BY`)).
+			WithArgs(day, day, day.Add(24*time.Hour)).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO page_visitor_dailies (day, page_type, page_id, language, utm_source, utm_medium, utm_campaign, visitor_hash) SELECT DISTINCT CAST($1 AS date), page_type, page_id, language, utm_source, utm_medium, utm_campaign, visitor_hash FROM page_views WHERE created_at >= $2 AND created_at < $3`)).
+			WithArgs(day, day, day.Add(24*time.Hour)).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectCommit()
+
+		assert.NoError(t, cmsAnalyticsRepo.RollupDay(day))
+	})
+
+	t.Run("successfully delete old page views", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "page_views" WHERE created_at < $1`)).
+			WithArgs(day).
+			WillReturnResult(sqlmock.NewResult(0, 12))
+		mock.ExpectCommit()
+
+		deleted, err := cmsAnalyticsRepo.DeletePageViewsBefore(day)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(12), deleted)
+	})
+
+	t.Run("successfully find the daily views of a page", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT page_view_dailies.day, SUM(page_view_dailies.views) AS views, COALESCE(MAX(page_visitors.visitors), 0) AS visitors FROM "page_view_dailies" `+
+			`LEFT JOIN (SELECT day, COUNT(DISTINCT visitor_hash) AS visitors FROM "page_visitor_dailies" WHERE (page_visitor_dailies.day >= $1 AND page_visitor_dailies.day <= $2) AND page_visitor_dailies.page_type = $3 AND page_visitor_dailies.page_id = $4 GROUP BY "day") AS page_visitors ON page_visitors.day = page_view_dailies.day `+
+			`WHERE (page_view_dailies.day >= $5 AND page_view_dailies.day <= $6) AND page_view_dailies.page_type = $7 AND page_view_dailies.page_id = $8 GROUP BY "page_view_dailies"."day" ORDER BY page_view_dailies.day`)).
+			WithArgs(day, to, enums.PageTypePartner, pageId, day, to, enums.PageTypePartner, pageId).
+			WillReturnRows(sqlmock.NewRows([]string{"day", "views", "visitors"}).AddRow(day, 10, 8))
+
+		points, err := cmsAnalyticsRepo.FindPageViewSeries(dto.AnalyticsQuery{From: day, To: to, PageType: enums.PageTypePartner, PageID: &pageId})
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.PageViewPoint{{Day: day, Views: 10, Visitors: 8}}, points)
+	})
+
+	t.Run("successfully count the distinct visitors of a page", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT page_visitor_dailies.visitor_hash) FROM "page_visitor_dailies" WHERE (page_visitor_dailies.day >= $1 AND page_visitor_dailies.day <= $2) AND page_visitor_dailies.page_id = $3`)).
+			WithArgs(day, to, pageId).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+		visitors, err := cmsAnalyticsRepo.CountVisitors(dto.AnalyticsQuery{From: day, To: to, PageID: &pageId})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), visitors)
+	})
+
+	t.Run("successfully rank the top pages with their titles", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`CASE page_view_dailies.page_type WHEN $1 THEN (SELECT landing_contents.title FROM landing_contents`)+`.*`+
+			regexp.QuoteMeta(`WHEN $4 THEN (SELECT partner_contents.title`)+`.*`+regexp.QuoteMeta(`WHEN $7 THEN (SELECT faq_contents.title`)+`.*`+
+			regexp.QuoteMeta(`COALESCE(MAX(page_visitors.visitors), 0) AS visitors FROM "page_view_dailies" `+
+				`LEFT JOIN (SELECT page_type, page_id, language, COUNT(DISTINCT visitor_hash) AS visitors FROM "page_visitor_dailies" WHERE (page_visitor_dailies.day >= $10 AND page_visitor_dailies.day <= $11) AND page_visitor_dailies.language = $12 GROUP BY page_type, page_id, language) AS page_visitors `+
+				`ON page_visitors.page_type = page_view_dailies.page_type AND page_visitors.page_id = page_view_dailies.page_id AND page_visitors.language = page_view_dailies.language `+
+				`WHERE (page_view_dailies.day >= $13 AND page_view_dailies.day <= $14) AND page_view_dailies.language = $15 GROUP BY page_view_dailies.page_type, page_view_dailies.page_id, page_view_dailies.language ORDER BY views DESC LIMIT $16`)).
+			WithArgs(enums.PageTypeLanding, enums.PageModeHistories, enums.PageModePreview,
+				enums.PageTypePartner, enums.PageModeHistories, enums.PageModePreview,
+				enums.PageTypeFaq, enums.PageModeHistories, enums.PageModePreview,
+				day, to, enums.PageLanguageTH, day, to, enums.PageLanguageTH, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"page_type", "page_id", "language", "title", "views", "visitors"}).
+				AddRow(enums.PageTypeFaq, pageId, enums.PageLanguageTH, "Reset password", 40, 30))
+
+		pages, err := cmsAnalyticsRepo.FindTopPages(dto.AnalyticsQuery{From: day, To: to, Language: enums.PageLanguageTH}, 5)
+		assert.NoError(t, err)
+		assert.Len(t, pages, 1)
+		assert.Equal(t, "Reset password", pages[0].Title)
+	})
+
+	t.Run("successfully find the traffic of each campaign", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`COALESCE(MAX(page_visitors.visitors), 0) AS visitors, COUNT(DISTINCT page_view_dailies.page_id) AS pages FROM "page_view_dailies" `+
+			`LEFT JOIN (SELECT utm_source, utm_medium, utm_campaign, COUNT(DISTINCT visitor_hash) AS visitors FROM "page_visitor_dailies" WHERE page_visitor_dailies.day >= $1 AND page_visitor_dailies.day <= $2 GROUP BY utm_source, utm_medium, utm_campaign) AS page_visitors `+
+			`ON page_visitors.utm_source = page_view_dailies.utm_source AND page_visitors.utm_medium = page_view_dailies.utm_medium AND page_visitors.utm_campaign = page_view_dailies.utm_campaign `+
+			`WHERE page_view_dailies.utm_campaign <> '' AND (page_view_dailies.day >= $3 AND page_view_dailies.day <= $4) GROUP BY page_view_dailies.utm_source, page_view_dailies.utm_medium, page_view_dailies.utm_campaign ORDER BY views DESC LIMIT $5`)).
+			WithArgs(day, to, day, to, 20).
+			WillReturnRows(sqlmock.NewRows([]string{"utm_source", "utm_medium", "utm_campaign", "views", "visitors", "pages"}).
+				AddRow("newsletter", "email", "summer-sale", 30, 25, 2))
+
+		campaigns, err := cmsAnalyticsRepo.FindCampaignTraffic(dto.AnalyticsQuery{From: day, To: to}, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.CampaignTraffic{{UTMSource: "newsletter", UTMMedium: "email", UTMCampaign: "summer-sale", Views: 30, Visitors: 25, Pages: 2}}, campaigns)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
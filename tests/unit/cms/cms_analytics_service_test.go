@@ -0,0 +1,292 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCMSAnalyticsRepo struct {
+	createPageViews       func(views []*models.PageView) error
+	findEarliestPageView  func() (*time.Time, error)
+	findLatestRollupDay   func() (*time.Time, error)
+	rollupDay             func(day time.Time) error
+	deletePageViewsBefore func(before time.Time) (int64, error)
+	findPageViewSeries    func(query dto.AnalyticsQuery) ([]dto.PageViewPoint, error)
+	countVisitors         func(query dto.AnalyticsQuery) (int64, error)
+	findTopReferrers      func(query dto.AnalyticsQuery, limit int) ([]dto.ReferrerTraffic, error)
+	findTopPages          func(query dto.AnalyticsQuery, limit int) ([]dto.TopPage, error)
+	findCampaignTraffic   func(query dto.AnalyticsQuery, limit int) ([]dto.CampaignTraffic, error)
+}
+
+func (m *MockCMSAnalyticsRepo) CreatePageViews(views []*models.PageView) error {
+	return m.createPageViews(views)
+}
+
+func (m *MockCMSAnalyticsRepo) FindEarliestPageView() (*time.Time, error) {
+	return m.findEarliestPageView()
+}
+
+func (m *MockCMSAnalyticsRepo) FindLatestRollupDay() (*time.Time, error) {
+	return m.findLatestRollupDay()
+}
+
+func (m *MockCMSAnalyticsRepo) RollupDay(day time.Time) error {
+	return m.rollupDay(day)
+}
+
+func (m *MockCMSAnalyticsRepo) DeletePageViewsBefore(before time.Time) (int64, error) {
+	return m.deletePageViewsBefore(before)
+}
+
+func (m *MockCMSAnalyticsRepo) FindPageViewSeries(query dto.AnalyticsQuery) ([]dto.PageViewPoint, error) {
+	return m.findPageViewSeries(query)
+}
+
+func (m *MockCMSAnalyticsRepo) CountVisitors(query dto.AnalyticsQuery) (int64, error) {
+	return m.countVisitors(query)
+}
+
+func (m *MockCMSAnalyticsRepo) FindTopReferrers(query dto.AnalyticsQuery, limit int) ([]dto.ReferrerTraffic, error) {
+	return m.findTopReferrers(query, limit)
+}
+
+func (m *MockCMSAnalyticsRepo) FindTopPages(query dto.AnalyticsQuery, limit int) ([]dto.TopPage, error) {
+	return m.findTopPages(query, limit)
+}
+
+func (m *MockCMSAnalyticsRepo) FindCampaignTraffic(query dto.AnalyticsQuery, limit int) ([]dto.CampaignTraffic, error) {
+	return m.findCampaignTraffic(query, limit)
+}
+
+func newAnalyticsConfig() *config.Config {
+	cfg := config.New()
+	cfg.SecretKey.HashSalt = "salt"
+	cfg.App.WebBaseURL = "https://www.example.com"
+	cfg.Analytics.BatchSize = 2
+	cfg.Analytics.FlushInterval = time.Hour
+	cfg.Analytics.RawRetention = 7 * 24 * time.Hour
+	return cfg
+}
+
+// writtenViews collects the batches the writer hands to the repository
+type writtenViews struct {
+	mu      sync.Mutex
+	batches [][]*models.PageView
+}
+
+func (w *writtenViews) create(views []*models.PageView) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches = append(w.batches, views)
+	return nil
+}
+
+func (w *writtenViews) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.batches)
+}
+
+func TestCMSService_TrackPageView(t *testing.T) {
+	pageId := uuid.New()
+
+	t.Run("successfully write tracked views in batches and flush the rest on stop", func(t *testing.T) {
+		written := &writtenViews{}
+		service := services.NewCMSAnalyticsService(&MockCMSAnalyticsRepo{createPageViews: written.create}, newAnalyticsConfig())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			service.StartBatchWriter(ctx)
+			close(done)
+		}()
+
+		request := dto.PageViewRequest{
+			PageType:    enums.PageTypeLanding,
+			PageID:      pageId.String(),
+			Language:    "EN",
+			Referrer:    "https://News.example.org/article?id=42",
+			UTMSource:   " Newsletter ",
+			UTMCampaign: "Summer-Sale",
+		}
+		require.NoError(t, service.TrackPageView(request, "10.0.0.1", "Mozilla"))
+		require.NoError(t, service.TrackPageView(request, "10.0.0.1", "Mozilla"))
+		require.NoError(t, service.TrackPageView(dto.PageViewRequest{
+			PageType: enums.PageTypeFaq,
+			PageID:   pageId.String(),
+			Language: "th",
+			Referrer: "https://www.example.com/faq",
+		}, "10.0.0.2", "Mozilla"))
+
+		assert.Eventually(t, func() bool { return written.count() == 1 }, time.Second, 5*time.Millisecond)
+		cancel()
+		<-done
+
+		require.Len(t, written.batches, 2)
+		assert.Len(t, written.batches[0], 2)
+		assert.Len(t, written.batches[1], 1)
+
+		view := written.batches[0][0]
+		assert.Equal(t, pageId, view.PageID)
+		assert.Equal(t, enums.PageLanguageEN, view.Language)
+		assert.Equal(t, "news.example.org", view.ReferrerHost)
+		assert.Equal(t, "newsletter", view.UTMSource)
+		assert.Equal(t, "summer-sale", view.UTMCampaign)
+		assert.Len(t, view.VisitorHash, 64)
+		assert.NotContains(t, view.VisitorHash, "10.0.0.1")
+		assert.Equal(t, view.VisitorHash, written.batches[0][1].VisitorHash)
+		// Keyed by the hash salt, not by a signing key that may be rotated
+		assert.Equal(t, helpers.HashVisitor("salt", view.CreatedAt.Format("2006-01-02"), "10.0.0.1", "Mozilla"), view.VisitorHash)
+
+		internal := written.batches[1][0]
+		assert.Empty(t, internal.ReferrerHost)
+		assert.NotEqual(t, view.VisitorHash, internal.VisitorHash)
+	})
+
+	t.Run("successfully drop views while the buffer is full", func(t *testing.T) {
+		cfg := newAnalyticsConfig()
+		cfg.Analytics.BufferSize = 1
+		service := services.NewCMSAnalyticsService(&MockCMSAnalyticsRepo{}, cfg)
+
+		request := dto.PageViewRequest{PageType: enums.PageTypePartner, PageID: pageId.String(), Language: "en"}
+		assert.NoError(t, service.TrackPageView(request, "10.0.0.1", "Mozilla"))
+		assert.NoError(t, service.TrackPageView(request, "10.0.0.1", "Mozilla"))
+	})
+
+	t.Run("failed to track page view: invalid request", func(t *testing.T) {
+		service := services.NewCMSAnalyticsService(&MockCMSAnalyticsRepo{}, newAnalyticsConfig())
+
+		err := service.TrackPageView(dto.PageViewRequest{PageID: pageId.String(), Language: "en"}, "10.0.0.1", "Mozilla")
+		assert.ErrorIs(t, err, errs.ErrInvalidPageView)
+
+		err = service.TrackPageView(dto.PageViewRequest{PageType: "blog", PageID: pageId.String(), Language: "en"}, "10.0.0.1", "Mozilla")
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+
+		err = service.TrackPageView(dto.PageViewRequest{PageType: enums.PageTypeLanding, PageID: "abc", Language: "en"}, "10.0.0.1", "Mozilla")
+		assert.ErrorIs(t, err, errs.ErrInvalidUUIDFormat)
+
+		err = service.TrackPageView(dto.PageViewRequest{PageType: enums.PageTypeLanding, PageID: pageId.String(), Language: "fr"}, "10.0.0.1", "Mozilla")
+		assert.ErrorIs(t, err, errs.ErrInvalidLanguageCode)
+	})
+}
+
+func TestCMSService_RunRollup(t *testing.T) {
+	now := time.Date(2025, 7, 10, 15, 30, 0, 0, time.UTC)
+	today := time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("successfully roll up from the first page view and delete old raw views", func(t *testing.T) {
+		earliest := time.Date(2025, 7, 8, 9, 0, 0, 0, time.UTC)
+		var rolled []time.Time
+		var deletedBefore time.Time
+		mockRepo := &MockCMSAnalyticsRepo{
+			findLatestRollupDay:  func() (*time.Time, error) { return nil, nil },
+			findEarliestPageView: func() (*time.Time, error) { return &earliest, nil },
+			rollupDay: func(day time.Time) error {
+				rolled = append(rolled, day)
+				return nil
+			},
+			deletePageViewsBefore: func(before time.Time) (int64, error) {
+				deletedBefore = before
+				return 0, nil
+			},
+		}
+		service := services.NewCMSAnalyticsService(mockRepo, newAnalyticsConfig())
+
+		assert.NoError(t, service.RunRollup(now))
+		assert.Equal(t, []time.Time{today.AddDate(0, 0, -2), today.AddDate(0, 0, -1), today}, rolled)
+		assert.Equal(t, today.AddDate(0, 0, -7), deletedBefore)
+	})
+
+	t.Run("successfully roll up yesterday again and keep its raw views", func(t *testing.T) {
+		var rolled []time.Time
+		var deletedBefore time.Time
+		mockRepo := &MockCMSAnalyticsRepo{
+			findLatestRollupDay: func() (*time.Time, error) { return &today, nil },
+			rollupDay: func(day time.Time) error {
+				rolled = append(rolled, day)
+				return nil
+			},
+			deletePageViewsBefore: func(before time.Time) (int64, error) {
+				deletedBefore = before
+				return 0, nil
+			},
+		}
+		cfg := newAnalyticsConfig()
+		cfg.Analytics.RawRetention = time.Hour
+		service := services.NewCMSAnalyticsService(mockRepo, cfg)
+
+		assert.NoError(t, service.RunRollup(now))
+		assert.Equal(t, []time.Time{today.AddDate(0, 0, -1), today}, rolled)
+		assert.Equal(t, today.AddDate(0, 0, -1), deletedBefore)
+	})
+
+	t.Run("successfully skip the rollup without page views", func(t *testing.T) {
+		mockRepo := &MockCMSAnalyticsRepo{
+			findLatestRollupDay:  func() (*time.Time, error) { return nil, nil },
+			findEarliestPageView: func() (*time.Time, error) { return nil, nil },
+		}
+		service := services.NewCMSAnalyticsService(mockRepo, newAnalyticsConfig())
+
+		assert.NoError(t, service.RunRollup(now))
+	})
+}
+
+func TestCMSService_GetPageAnalytics(t *testing.T) {
+	pageId := uuid.New()
+	from := time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("successfully total the daily views of a page", func(t *testing.T) {
+		mockRepo := &MockCMSAnalyticsRepo{
+			findPageViewSeries: func(query dto.AnalyticsQuery) ([]dto.PageViewPoint, error) {
+				assert.Equal(t, enums.PageTypeLanding, query.PageType)
+				assert.Equal(t, pageId, *query.PageID)
+				assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), query.From)
+				assert.Equal(t, time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC), query.To)
+				return []dto.PageViewPoint{{Views: 10, Visitors: 8}, {Views: 5, Visitors: 4}}, nil
+			},
+			countVisitors: func(query dto.AnalyticsQuery) (int64, error) {
+				assert.Equal(t, pageId, *query.PageID)
+				return 12, nil
+			},
+			findTopReferrers: func(query dto.AnalyticsQuery, limit int) ([]dto.ReferrerTraffic, error) {
+				return []dto.ReferrerTraffic{{ReferrerHost: "www.google.com", Views: 6}}, nil
+			},
+		}
+		service := services.NewCMSAnalyticsService(mockRepo, newAnalyticsConfig())
+
+		analytics, err := service.GetPageAnalytics(enums.PageTypeLanding, pageId, dto.AnalyticsQuery{From: from, To: to})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(15), analytics.Views)
+		assert.Equal(t, int64(12), analytics.Visitors)
+		assert.Len(t, analytics.Referrers, 1)
+	})
+
+	t.Run("failed to get page analytics: invalid page type", func(t *testing.T) {
+		service := services.NewCMSAnalyticsService(&MockCMSAnalyticsRepo{}, newAnalyticsConfig())
+
+		_, err := service.GetPageAnalytics("blog", pageId, dto.AnalyticsQuery{From: from, To: to})
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+	})
+
+	t.Run("failed to get top pages: invalid date range", func(t *testing.T) {
+		service := services.NewCMSAnalyticsService(&MockCMSAnalyticsRepo{}, newAnalyticsConfig())
+
+		_, err := service.GetTopPages(dto.AnalyticsQuery{From: to, To: from}, 20)
+		assert.ErrorIs(t, err, errs.ErrInvalidDateRange)
+	})
+}
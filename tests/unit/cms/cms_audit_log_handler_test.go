@@ -0,0 +1,314 @@
+package tests
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/middleware"
+	"github.com/MadManJJ/cms-api/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type MockCMSAuditLogService struct {
+	mock.Mock
+}
+
+func (m *MockCMSAuditLogService) Record(auditLog *models.AuditLog) error {
+	return m.Called(auditLog).Error(0)
+}
+
+func (m *MockCMSAuditLogService) Snapshot(table string, id uuid.UUID) (datatypes.JSON, error) {
+	args := m.Called(table, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(datatypes.JSON), args.Error(1)
+}
+
+func (m *MockCMSAuditLogService) FindAuditLogs(query dto.AuditLogQuery, page, limit int) ([]models.AuditLog, int64, error) {
+	args := m.Called(query, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.AuditLog), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCMSAuditLogService) FindAuditLogByID(id uuid.UUID) (*models.AuditLog, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AuditLog), args.Error(1)
+}
+
+func (m *MockCMSAuditLogService) ExportAuditLogs(query dto.AuditLogQuery, fn func(auditLogs []models.AuditLog) error) error {
+	args := m.Called(query, fn)
+	if auditLogs, ok := args.Get(0).([]models.AuditLog); ok {
+		if err := fn(auditLogs); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func TestCMSAuditLogHandler(t *testing.T) {
+	mockService := &MockCMSAuditLogService{}
+	handler := cmsHandler.NewCMSAuditLogHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/audit-logs", handler.HandleGetAuditLogs)
+	app.Get("/cms/audit-logs/export", handler.HandleExportAuditLogs)
+	app.Get("/cms/audit-logs/:auditLogId", handler.HandleGetAuditLog)
+
+	auditLogId := uuid.New()
+	userId := uuid.New()
+
+	t.Run("GET /cms/audit-logs HandleGetAuditLogs", func(t *testing.T) {
+		t.Run("successfully get the audit logs of a user", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindAuditLogs", mock.MatchedBy(func(query dto.AuditLogQuery) bool {
+				return query.UserID != nil && *query.UserID == userId && query.EntityType == "categories" && !query.From.IsZero()
+			}), 1, 10).Return([]models.AuditLog{{ID: auditLogId, UserID: &userId}}, int64(1), nil)
+
+			req := httptest.NewRequest("GET", "/cms/audit-logs?userId="+userId.String()+"&entityType=categories&from=2025-07-01", nil)
+			resp, _ := app.Test(req)
+			respBody, _ := io.ReadAll(resp.Body)
+
+			var body struct {
+				TotalCount int64             `json:"totalCount"`
+				Items      []models.AuditLog `json:"items"`
+			}
+			require.NoError(t, json.Unmarshal(respBody, &body))
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, int64(1), body.TotalCount)
+			require.Len(t, body.Items, 1)
+			assert.Equal(t, auditLogId, body.Items[0].ID)
+		})
+
+		t.Run("failed to get audit logs: invalid user id", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest("GET", "/cms/audit-logs?userId=me", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("failed to get audit logs: invalid date range", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindAuditLogs", mock.Anything, 1, 10).Return(nil, int64(0), errs.ErrInvalidDateRange)
+
+			req := httptest.NewRequest("GET", "/cms/audit-logs?from=2025-07-02&to=2025-07-01", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/audit-logs/export HandleExportAuditLogs", func(t *testing.T) {
+		t.Run("successfully export the audit logs as csv", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("ExportAuditLogs", mock.Anything, mock.Anything).Return([]models.AuditLog{{
+				ID:         auditLogId,
+				UserID:     &userId,
+				Action:     "delete",
+				Method:     "DELETE",
+				EntityType: "categories",
+				StatusCode: 200,
+				UserAgent:  "=HYPERLINK()",
+				Before:     datatypes.JSON(`{"name":"News"}`),
+			}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/audit-logs/export?entityType=categories", nil)
+			resp, _ := app.Test(req)
+			respBody, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, resp.Header.Get(fiber.HeaderContentType), "text/csv")
+			rows, err := csv.NewReader(strings.NewReader(string(respBody))).ReadAll()
+			require.NoError(t, err)
+			require.Len(t, rows, 2)
+			assert.Equal(t, "id", rows[0][0])
+			assert.Equal(t, auditLogId.String(), rows[1][0])
+			assert.Equal(t, userId.String(), rows[1][2])
+			assert.Equal(t, "delete", rows[1][3])
+			assert.Equal(t, "'=HYPERLINK()", rows[1][10])
+			assert.Equal(t, `{"name":"News"}`, rows[1][11])
+		})
+	})
+
+	t.Run("GET /cms/audit-logs/:auditLogId HandleGetAuditLog", func(t *testing.T) {
+		t.Run("successfully get audit log", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindAuditLogByID", auditLogId).Return(&models.AuditLog{ID: auditLogId}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/audit-logs/"+auditLogId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("failed to get audit log: not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindAuditLogByID", auditLogId).Return(nil, gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("GET", "/cms/audit-logs/"+auditLogId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+	})
+}
+
+func TestAuditLogMiddleware(t *testing.T) {
+	const normalKey = "normal-secret"
+
+	mockService := &MockCMSAuditLogService{}
+	app := fiber.New()
+	cms := app.Group("/api/v1/cms", middleware.AuditLog(mockService, "line-secret", normalKey))
+	cms.Get("/categories/:categoryUuid", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"id": c.Params("categoryUuid")})
+	})
+	cms.Post("/categories", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": "7c1d1f5e-7d1f-4c1a-9a40-0e7f5c3b9a11", "name": "News"})
+	})
+	cms.Patch("/categories/:categoryUuid", middleware.AuditSnapshot(mockService, "categories", "categoryUuid"), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"message": "updated", "item": fiber.Map{"name": "World"}})
+	})
+	cms.Post("/webhooks/:webhookId/secret", middleware.AuditAction("rotate_secret"), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"item": fiber.Map{"secret": "new"}})
+	})
+	cms.Post("/faqpages/duplicate/:pageId/pages", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	})
+	emails := app.Group("/api/v1/emails", middleware.AuditLog(mockService, "line-secret", normalKey), middleware.AuditEntity("emails"))
+	emails.Post("/send", middleware.AuditAction("send"), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"message": "Email sent successfully"})
+	})
+
+	var recorded *models.AuditLog
+	record := func() {
+		recorded = nil
+		mockService.ExpectedCalls = nil
+		mockService.On("Record", mock.Anything).Run(func(args mock.Arguments) {
+			recorded = args.Get(0).(*models.AuditLog)
+		}).Return(nil)
+	}
+
+	categoryId := uuid.New()
+	userId := uuid.New()
+
+	t.Run("successfully skip reads", func(t *testing.T) {
+		record()
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/api/v1/cms/categories/"+categoryId.String(), nil))
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Nil(t, recorded)
+	})
+
+	t.Run("successfully record a create with the user from the token and the created id", func(t *testing.T) {
+		record()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userId.String()}).SignedString([]byte(normalKey))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("POST", "/api/v1/cms/categories", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", "cms-frontend")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		require.NotNil(t, recorded)
+		require.NotNil(t, recorded.UserID)
+		assert.Equal(t, userId, *recorded.UserID)
+		assert.Equal(t, "create", recorded.Action)
+		assert.Equal(t, "categories", recorded.EntityType)
+		require.NotNil(t, recorded.EntityID)
+		assert.Equal(t, "7c1d1f5e-7d1f-4c1a-9a40-0e7f5c3b9a11", recorded.EntityID.String())
+		assert.Equal(t, "/api/v1/cms/categories", recorded.Path)
+		assert.Equal(t, "cms-frontend", recorded.UserAgent)
+		assert.JSONEq(t, `{"id":"7c1d1f5e-7d1f-4c1a-9a40-0e7f5c3b9a11","name":"News"}`, string(recorded.After))
+	})
+
+	t.Run("successfully record an update with its before snapshot and returned item", func(t *testing.T) {
+		record()
+		mockService.On("Snapshot", "categories", categoryId).Return(datatypes.JSON(`{"name":"News"}`), nil)
+
+		resp, _ := app.Test(httptest.NewRequest("PATCH", "/api/v1/cms/categories/"+categoryId.String(), nil))
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.NotNil(t, recorded)
+		assert.Nil(t, recorded.UserID)
+		assert.Equal(t, "update", recorded.Action)
+		assert.Equal(t, "/api/v1/cms/categories/:categoryUuid", recorded.Path)
+		require.NotNil(t, recorded.EntityID)
+		assert.Equal(t, categoryId, *recorded.EntityID)
+		assert.JSONEq(t, `{"name":"News"}`, string(recorded.Before))
+		assert.JSONEq(t, `{"name":"World"}`, string(recorded.After))
+	})
+
+	t.Run("successfully record the named action of a route", func(t *testing.T) {
+		record()
+
+		resp, _ := app.Test(httptest.NewRequest("POST", "/api/v1/cms/webhooks/"+uuid.NewString()+"/secret", nil))
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.NotNil(t, recorded)
+		assert.Equal(t, "rotate_secret", recorded.Action)
+		assert.Equal(t, "webhooks", recorded.EntityType)
+	})
+
+	t.Run("successfully record a failed request without an after snapshot", func(t *testing.T) {
+		record()
+
+		resp, _ := app.Test(httptest.NewRequest("POST", "/api/v1/cms/faqpages/duplicate/"+categoryId.String()+"/pages", nil))
+
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		require.NotNil(t, recorded)
+		assert.Equal(t, "duplicate.pages", recorded.Action)
+		assert.Equal(t, fiber.StatusNotFound, recorded.StatusCode)
+		assert.Nil(t, recorded.After)
+	})
+
+	t.Run("successfully record a mail sent outside the cms group", func(t *testing.T) {
+		record()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userId.String()}).SignedString([]byte(normalKey))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("POST", "/api/v1/emails/send", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.NotNil(t, recorded)
+		require.NotNil(t, recorded.UserID)
+		assert.Equal(t, userId, *recorded.UserID)
+		assert.Equal(t, "emails", recorded.EntityType)
+		assert.Equal(t, "send", recorded.Action)
+		assert.Equal(t, "/api/v1/emails/send", recorded.Path)
+	})
+
+	t.Run("successfully skip requests matching no route", func(t *testing.T) {
+		record()
+
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/api/v1/cms/unknown", nil))
+
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.Nil(t, recorded)
+	})
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCMSRepo_CreateAuditLog(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAuditLogRepo := repo.NewCMSAuditLogRepository(gormDB)
+
+	t.Run("successfully create audit log", func(t *testing.T) {
+		userId := uuid.New()
+		entityId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "audit_logs"`)).
+			// Empty snapshots are written as NULL
+			WithArgs(&userId, "delete", "DELETE", "/api/v1/cms/categories/:categoryUuid", "categories", &entityId, 200, "127.0.0.1", "curl", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectCommit()
+
+		err := cmsAuditLogRepo.CreateAuditLog(&models.AuditLog{
+			UserID:     &userId,
+			Action:     "delete",
+			Method:     "DELETE",
+			Path:       "/api/v1/cms/categories/:categoryUuid",
+			EntityType: "categories",
+			EntityID:   &entityId,
+			StatusCode: 200,
+			IP:         "127.0.0.1",
+			UserAgent:  "curl",
+		})
+		assert.NoError(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FindAuditLogs(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAuditLogRepo := repo.NewCMSAuditLogRepository(gormDB)
+
+	t.Run("successfully find the filtered audit logs, newest first", func(t *testing.T) {
+		userId := uuid.New()
+		from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+		query := dto.AuditLogQuery{UserID: &userId, Action: "delete", EntityType: "categories", From: from}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "audit_logs" WHERE user_id = $1 AND action = $2 AND entity_type = $3 AND created_at >= $4`)).
+			WithArgs(userId, "delete", "categories", from).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_logs" WHERE user_id = $1 AND action = $2 AND entity_type = $3 AND created_at >= $4 ORDER BY created_at DESC, id LIMIT $5 OFFSET $6`)).
+			WithArgs(userId, "delete", "categories", from, 10, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "action"}).AddRow(uuid.New(), "delete"))
+
+		auditLogs, totalCount, err := cmsAuditLogRepo.FindAuditLogs(query, 2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), totalCount)
+		assert.Len(t, auditLogs, 1)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FindAuditLogsInBatches(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAuditLogRepo := repo.NewCMSAuditLogRepository(gormDB)
+
+	t.Run("successfully walk the audit logs with keyset paging", func(t *testing.T) {
+		createdAt := time.Now()
+		firstId, secondId, thirdId := uuid.New(), uuid.New(), uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_logs" WHERE entity_type = $1 ORDER BY created_at ASC, id ASC LIMIT $2`)).
+			WithArgs("forms", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(firstId, createdAt).AddRow(secondId, createdAt))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_logs" WHERE entity_type = $1 AND (created_at, id) > ($2, $3) ORDER BY created_at ASC, id ASC LIMIT $4`)).
+			WithArgs("forms", createdAt, secondId, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(thirdId, createdAt))
+
+		var ids []uuid.UUID
+		err := cmsAuditLogRepo.FindAuditLogsInBatches(dto.AuditLogQuery{EntityType: "forms"}, 2, func(auditLogs []models.AuditLog) error {
+			for _, auditLog := range auditLogs {
+				ids = append(ids, auditLog.ID)
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{firstId, secondId, thirdId}, ids)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FindRowSnapshot(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAuditLogRepo := repo.NewCMSAuditLogRepository(gormDB)
+	id := uuid.New()
+
+	t.Run("successfully snapshot a row as json", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT row_to_json(t) FROM "categories" t WHERE t.id = $1`)).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow([]byte(`{"id":"` + id.String() + `","name":"News"}`)))
+
+		snapshot, err := cmsAuditLogRepo.FindRowSnapshot("categories", id)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"`+id.String()+`","name":"News"}`, string(snapshot))
+	})
+
+	t.Run("successfully snapshot nothing for a missing row", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT row_to_json(t) FROM "categories" t WHERE t.id = $1`)).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}))
+
+		snapshot, err := cmsAuditLogRepo.FindRowSnapshot("categories", id)
+		assert.NoError(t, err)
+		assert.Nil(t, snapshot)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
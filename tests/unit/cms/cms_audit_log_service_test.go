@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+type MockCMSAuditLogRepo struct {
+	createAuditLog         func(auditLog *models.AuditLog) error
+	findAuditLogs          func(query dto.AuditLogQuery, page, limit int) ([]models.AuditLog, int64, error)
+	findAuditLogByID       func(id uuid.UUID) (*models.AuditLog, error)
+	findAuditLogsInBatches func(query dto.AuditLogQuery, batchSize int, fn func(auditLogs []models.AuditLog) error) error
+	findRowSnapshot        func(table string, id uuid.UUID) (datatypes.JSON, error)
+}
+
+func (m *MockCMSAuditLogRepo) CreateAuditLog(auditLog *models.AuditLog) error {
+	return m.createAuditLog(auditLog)
+}
+
+func (m *MockCMSAuditLogRepo) FindAuditLogs(query dto.AuditLogQuery, page, limit int) ([]models.AuditLog, int64, error) {
+	return m.findAuditLogs(query, page, limit)
+}
+
+func (m *MockCMSAuditLogRepo) FindAuditLogByID(id uuid.UUID) (*models.AuditLog, error) {
+	return m.findAuditLogByID(id)
+}
+
+func (m *MockCMSAuditLogRepo) FindAuditLogsInBatches(query dto.AuditLogQuery, batchSize int, fn func(auditLogs []models.AuditLog) error) error {
+	return m.findAuditLogsInBatches(query, batchSize, fn)
+}
+
+func (m *MockCMSAuditLogRepo) FindRowSnapshot(table string, id uuid.UUID) (datatypes.JSON, error) {
+	return m.findRowSnapshot(table, id)
+}
+
+func TestCMSService_RecordAuditLog(t *testing.T) {
+	t.Run("successfully record with the snapshots redacted", func(t *testing.T) {
+		var recorded *models.AuditLog
+		mockRepo := &MockCMSAuditLogRepo{
+			createAuditLog: func(auditLog *models.AuditLog) error {
+				recorded = auditLog
+				return nil
+			},
+		}
+		service := services.NewCMSAuditLogService(mockRepo)
+
+		err := service.Record(&models.AuditLog{
+			Action: "update",
+			Before: datatypes.JSON(`{"id":"1","secret":"old"}`),
+			After:  datatypes.JSON(`{"id":"1","secret":"new"}`),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, recorded)
+		assert.JSONEq(t, `{"id":"1","secret":"[REDACTED]"}`, string(recorded.Before))
+		assert.JSONEq(t, `{"id":"1","secret":"[REDACTED]"}`, string(recorded.After))
+	})
+
+	t.Run("failed to record: repository error", func(t *testing.T) {
+		mockRepo := &MockCMSAuditLogRepo{
+			createAuditLog: func(auditLog *models.AuditLog) error { return errors.New("db error") },
+		}
+		service := services.NewCMSAuditLogService(mockRepo)
+
+		assert.Error(t, service.Record(&models.AuditLog{Action: "delete"}))
+	})
+}
+
+func TestCMSService_SnapshotAuditLog(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("successfully snapshot a row with its secrets redacted", func(t *testing.T) {
+		mockRepo := &MockCMSAuditLogRepo{
+			findRowSnapshot: func(table string, rowId uuid.UUID) (datatypes.JSON, error) {
+				assert.Equal(t, "webhook_endpoints", table)
+				assert.Equal(t, id, rowId)
+				return datatypes.JSON(`{"url":"https://example.com","secret":"s3cr3t"}`), nil
+			},
+		}
+		service := services.NewCMSAuditLogService(mockRepo)
+
+		snapshot, err := service.Snapshot("webhook_endpoints", id)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"url":"https://example.com","secret":"`+helpers.RedactedValue+`"}`, string(snapshot))
+	})
+
+	t.Run("successfully snapshot nothing for a missing row", func(t *testing.T) {
+		mockRepo := &MockCMSAuditLogRepo{
+			findRowSnapshot: func(table string, rowId uuid.UUID) (datatypes.JSON, error) { return nil, nil },
+		}
+		service := services.NewCMSAuditLogService(mockRepo)
+
+		snapshot, err := service.Snapshot("categories", id)
+		assert.NoError(t, err)
+		assert.Nil(t, snapshot)
+	})
+}
+
+func TestCMSService_FindAuditLogs(t *testing.T) {
+	t.Run("successfully find audit logs", func(t *testing.T) {
+		userId := uuid.New()
+		mockRepo := &MockCMSAuditLogRepo{
+			findAuditLogs: func(query dto.AuditLogQuery, page, limit int) ([]models.AuditLog, int64, error) {
+				assert.Equal(t, &userId, query.UserID)
+				assert.Equal(t, 2, page)
+				assert.Equal(t, 20, limit)
+				return []models.AuditLog{{UserID: &userId}}, 21, nil
+			},
+		}
+		service := services.NewCMSAuditLogService(mockRepo)
+
+		auditLogs, totalCount, err := service.FindAuditLogs(dto.AuditLogQuery{UserID: &userId}, 2, 20)
+		assert.NoError(t, err)
+		assert.Len(t, auditLogs, 1)
+		assert.Equal(t, int64(21), totalCount)
+	})
+
+	t.Run("failed to find audit logs: to before from", func(t *testing.T) {
+		service := services.NewCMSAuditLogService(&MockCMSAuditLogRepo{})
+
+		now := time.Now()
+		_, _, err := service.FindAuditLogs(dto.AuditLogQuery{From: now, To: now.Add(-time.Hour)}, 1, 10)
+		assert.ErrorIs(t, err, errs.ErrInvalidDateRange)
+	})
+}
+
+func TestCMSService_ExportAuditLogs(t *testing.T) {
+	t.Run("successfully hand every batch to the writer", func(t *testing.T) {
+		mockRepo := &MockCMSAuditLogRepo{
+			findAuditLogsInBatches: func(query dto.AuditLogQuery, batchSize int, fn func(auditLogs []models.AuditLog) error) error {
+				assert.Equal(t, "categories", query.EntityType)
+				if err := fn([]models.AuditLog{{Action: "create"}, {Action: "update"}}); err != nil {
+					return err
+				}
+				return fn([]models.AuditLog{{Action: "delete"}})
+			},
+		}
+		service := services.NewCMSAuditLogService(mockRepo)
+
+		var actions []string
+		err := service.ExportAuditLogs(dto.AuditLogQuery{EntityType: "categories"}, func(auditLogs []models.AuditLog) error {
+			for _, auditLog := range auditLogs {
+				actions = append(actions, auditLog.Action)
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"create", "update", "delete"}, actions)
+	})
+}
@@ -83,6 +83,25 @@ func TestCMSService_LoginUser(t *testing.T) {
 		assert.NotNil(t, token)
 	})
 
+	t.Run("successfully login with the role in the token", func(t *testing.T) {
+		t.Setenv("JWT_SECRET_KEY", "secret")
+		userWithRole := helpers.InitializeMockUserWithHashedPassword()
+		userWithRole.Role = &models.Role{Name: "editor"}
+		repo := &MockCMSAuthRepo{
+			findUserByEmail: func(email string) (*models.User, error) {
+				return userWithRole, nil
+			},
+		}
+
+		service := services.NewCMSAuthService(repo)
+
+		_, token, err := service.LoginUser(helpers.InitializeMockUser())
+		assert.NoError(t, err)
+		claims, err := helpers.ParseJWTWithKey(token, "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "editor", claims["role"])
+	})
+
 	t.Run("failed to login: incorrect password", func(t *testing.T) {
 		repo := &MockCMSAuthRepo{
 			findUserByEmail: func(email string) (*models.User, error) {
@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockCMSAutosaveService struct {
+	mock.Mock
+}
+
+func (m *MockCMSAutosaveService) GetAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) (*dto.AutosaveState, error) {
+	args := m.Called(userId, pageType, contentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AutosaveState), args.Error(1)
+}
+
+func (m *MockCMSAutosaveService) SaveAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID, payload []byte) (*dto.AutosaveState, error) {
+	args := m.Called(userId, pageType, contentId, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AutosaveState), args.Error(1)
+}
+
+func (m *MockCMSAutosaveService) DiscardAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) error {
+	args := m.Called(userId, pageType, contentId)
+	return args.Error(0)
+}
+
+func (m *MockCMSAutosaveService) PromoteAutosave(userId uuid.UUID, pageType enums.PageType, contentId uuid.UUID) (interface{}, error) {
+	args := m.Called(userId, pageType, contentId)
+	return args.Get(0), args.Error(1)
+}
+
+func (m *MockCMSAutosaveService) WithPageAccess(access *dto.PageAccess) services.CMSAutosaveServiceInterface {
+	return m
+}
+
+func TestCMSAutosaveHandler(t *testing.T) {
+	mockService := &MockCMSAutosaveService{}
+	handler := cmsHandler.NewCMSAutosaveHandler(mockService)
+
+	userId := uuid.New()
+	contentId := uuid.New()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get("Authorization") != "" {
+			c.Locals("user", jwt.MapClaims{"user_id": userId.String()})
+		}
+		return c.Next()
+	})
+	app.Get("/cms/autosaves/:pageType/:contentId", handler.HandleGetAutosave)
+	app.Put("/cms/autosaves/:pageType/:contentId", handler.HandleSaveAutosave)
+	app.Delete("/cms/autosaves/:pageType/:contentId", handler.HandleDiscardAutosave)
+	app.Post("/cms/autosaves/:pageType/:contentId/promote", handler.HandlePromoteAutosave)
+
+	url := "/cms/autosaves/landing/" + contentId.String()
+
+	t.Run("GET /cms/autosaves/:pageType/:contentId HandleGetAutosave", func(t *testing.T) {
+		t.Run("successfully get autosave", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetAutosave", userId, enums.PageTypeLanding, contentId).Return(&dto.AutosaveState{NewerAutosaves: []dto.NewerAutosave{}}, nil)
+
+			req := httptest.NewRequest("GET", url, nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get autosave: no user", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", url, nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetAutosave", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		t.Run("failed to get autosave: content not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetAutosave", userId, enums.PageTypeLanding, contentId).Return(nil, gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("GET", url, nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("PUT /cms/autosaves/:pageType/:contentId HandleSaveAutosave", func(t *testing.T) {
+		t.Run("successfully save autosave", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			body := `{"title":"Draft"}`
+			mockService.On("SaveAutosave", userId, enums.PageTypeLanding, contentId, []byte(body)).Return(&dto.AutosaveState{}, nil)
+
+			req := httptest.NewRequest("PUT", url, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to save autosave: invalid payload", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("SaveAutosave", userId, enums.PageTypeLanding, contentId, mock.Anything).Return(nil, errs.ErrInvalidAutosavePayload)
+
+			req := httptest.NewRequest("PUT", url, strings.NewReader(`[]`))
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("DELETE /cms/autosaves/:pageType/:contentId HandleDiscardAutosave", func(t *testing.T) {
+		t.Run("successfully discard autosave", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("DiscardAutosave", userId, enums.PageTypeLanding, contentId).Return(nil)
+
+			req := httptest.NewRequest("DELETE", url, nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /cms/autosaves/:pageType/:contentId/promote HandlePromoteAutosave", func(t *testing.T) {
+		t.Run("successfully promote autosave", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("PromoteAutosave", userId, enums.PageTypeLanding, contentId).Return(&models.LandingContent{ID: uuid.New()}, nil)
+
+			req := httptest.NewRequest("POST", url+"/promote", nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("failed to promote autosave: outdated", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("PromoteAutosave", userId, enums.PageTypeLanding, contentId).Return(nil, errs.ErrAutosaveOutdated)
+
+			req := httptest.NewRequest("POST", url+"/promote", nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		})
+
+		t.Run("failed to promote autosave: critical audit findings", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("PromoteAutosave", userId, enums.PageTypeLanding, contentId).Return(nil, errs.ErrCriticalAuditFindings)
+
+			req := httptest.NewRequest("POST", url+"/promote", nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+		})
+	})
+}
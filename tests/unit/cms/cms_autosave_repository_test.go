@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestCMSRepo_FindContentRef(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAutosaveRepo := repo.NewCMSAutosaveRepository(gormDB)
+	contentId := uuid.New()
+
+	t.Run("successfully find content ref", func(t *testing.T) {
+		pageId := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT page_id, language, mode FROM "partner_contents" WHERE id = $1 LIMIT $2`)).
+			WithArgs(contentId, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"page_id", "language", "mode"}).AddRow(pageId, "en", "Draft"))
+
+		ref, err := cmsAutosaveRepo.FindContentRef(enums.PageTypePartner, contentId)
+		assert.NoError(t, err)
+		assert.Equal(t, pageId, ref.PageID)
+		assert.Equal(t, enums.PageLanguageEN, ref.Language)
+		assert.Equal(t, enums.PageModeDraft, ref.Mode)
+	})
+
+	t.Run("failed to find content ref: not found", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT page_id, language, mode FROM "faq_contents"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"page_id", "language", "mode"}))
+
+		ref, err := cmsAutosaveRepo.FindContentRef(enums.PageTypeFaq, contentId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, ref)
+	})
+
+	t.Run("failed to find content ref: invalid page type", func(t *testing.T) {
+		ref, err := cmsAutosaveRepo.FindContentRef("unknown", contentId)
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+		assert.Nil(t, ref)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_UpsertAutosave(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAutosaveRepo := repo.NewCMSAutosaveRepository(gormDB)
+
+	t.Run("successfully overwrite the autosave slot", func(t *testing.T) {
+		autosave := &models.ContentAutosave{
+			PageType:      enums.PageTypeLanding,
+			PageID:        uuid.New(),
+			Language:      enums.PageLanguageEN,
+			UserID:        uuid.New(),
+			BaseContentID: uuid.New(),
+			Payload:       datatypes.JSON(`{"title":"Draft"}`),
+			UpdatedAt:     time.Now(),
+		}
+		autosaveId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "content_autosaves"`) + `.*` +
+			regexp.QuoteMeta(`ON CONFLICT ("page_type","page_id","language","user_id") DO UPDATE SET "base_content_id"="excluded"."base_content_id","payload"="excluded"."payload","updated_at"="excluded"."updated_at"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(autosaveId))
+		mock.ExpectCommit()
+
+		err := cmsAutosaveRepo.UpsertAutosave(autosave)
+		assert.NoError(t, err)
+		assert.Equal(t, autosaveId, autosave.ID)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FindNewerAutosaves(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAutosaveRepo := repo.NewCMSAutosaveRepository(gormDB)
+
+	t.Run("successfully find the newer autosaves of the other users", func(t *testing.T) {
+		pageId := uuid.New()
+		userId := uuid.New()
+		otherUserId := uuid.New()
+		updatedAfter := time.Now().Add(-time.Minute)
+		email := "editor@example.com"
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "content_autosaves" WHERE page_type = $1 AND page_id = $2 AND language = $3 AND user_id != $4 AND updated_at > $5 ORDER BY updated_at DESC`)).
+			WithArgs(enums.PageTypeFaq, pageId, enums.PageLanguageTH, userId, updatedAfter).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "updated_at"}).AddRow(uuid.New(), otherUserId, time.Now()))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+			WithArgs(otherUserId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(otherUserId, email))
+
+		autosaves, err := cmsAutosaveRepo.FindNewerAutosaves(enums.PageTypeFaq, pageId, enums.PageLanguageTH, userId, updatedAfter)
+		assert.NoError(t, err)
+		assert.Len(t, autosaves, 1)
+		assert.Equal(t, email, *autosaves[0].User.Email)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_DeleteAutosave(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsAutosaveRepo := repo.NewCMSAutosaveRepository(gormDB)
+
+	t.Run("successfully delete autosave", func(t *testing.T) {
+		pageId := uuid.New()
+		userId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_autosaves" WHERE page_type = $1 AND page_id = $2 AND language = $3 AND user_id = $4`)).
+			WithArgs(enums.PageTypeLanding, pageId, enums.PageLanguageEN, userId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := cmsAutosaveRepo.DeleteAutosave(enums.PageTypeLanding, pageId, enums.PageLanguageEN, userId)
+		assert.NoError(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
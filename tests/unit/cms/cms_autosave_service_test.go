@@ -0,0 +1,293 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/repositories"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type MockCMSAutosaveRepo struct {
+	findContentRef     func(pageType enums.PageType, contentId uuid.UUID) (*repositories.ContentRef, error)
+	findContentScopes  func(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+	findAutosave       func(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID) (*models.ContentAutosave, error)
+	findNewerAutosaves func(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID, updatedAfter time.Time) ([]models.ContentAutosave, error)
+	upsertAutosave     func(autosave *models.ContentAutosave) error
+	deleteAutosave     func(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID) error
+}
+
+func (m *MockCMSAutosaveRepo) FindContentRef(pageType enums.PageType, contentId uuid.UUID) (*repositories.ContentRef, error) {
+	return m.findContentRef(pageType, contentId)
+}
+
+func (m *MockCMSAutosaveRepo) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageType, pageId)
+}
+
+func (m *MockCMSAutosaveRepo) FindAutosave(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID) (*models.ContentAutosave, error) {
+	return m.findAutosave(pageType, pageId, language, userId)
+}
+
+func (m *MockCMSAutosaveRepo) FindNewerAutosaves(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID, updatedAfter time.Time) ([]models.ContentAutosave, error) {
+	return m.findNewerAutosaves(pageType, pageId, language, userId, updatedAfter)
+}
+
+func (m *MockCMSAutosaveRepo) UpsertAutosave(autosave *models.ContentAutosave) error {
+	return m.upsertAutosave(autosave)
+}
+
+func (m *MockCMSAutosaveRepo) DeleteAutosave(pageType enums.PageType, pageId uuid.UUID, language enums.PageLanguage, userId uuid.UUID) error {
+	return m.deleteAutosave(pageType, pageId, language, userId)
+}
+
+func TestCMSService_SaveAutosave(t *testing.T) {
+	userId := uuid.New()
+	pageId := uuid.New()
+	contentId := uuid.New()
+	ref := &repositories.ContentRef{PageID: pageId, Language: enums.PageLanguageEN, Mode: enums.PageModeDraft}
+
+	t.Run("successfully save autosave and warn about a newer one", func(t *testing.T) {
+		previousUpdatedAt := time.Now().Add(-time.Minute)
+		email := "other@example.com"
+
+		var upserted *models.ContentAutosave
+		repo := &MockCMSAutosaveRepo{
+			findContentRef: func(pageType enums.PageType, id uuid.UUID) (*repositories.ContentRef, error) {
+				assert.Equal(t, enums.PageTypeLanding, pageType)
+				assert.Equal(t, contentId, id)
+				return ref, nil
+			},
+			findAutosave: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID) (*models.ContentAutosave, error) {
+				return &models.ContentAutosave{UpdatedAt: previousUpdatedAt}, nil
+			},
+			upsertAutosave: func(autosave *models.ContentAutosave) error {
+				upserted = autosave
+				return nil
+			},
+			findNewerAutosaves: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID, updatedAfter time.Time) ([]models.ContentAutosave, error) {
+				assert.Equal(t, previousUpdatedAt, updatedAfter)
+				return []models.ContentAutosave{{UserID: uuid.New(), User: &models.User{Email: &email}, UpdatedAt: time.Now()}}, nil
+			},
+		}
+
+		service := services.NewCMSAutosaveService(repo, nil, nil, nil)
+
+		state, err := service.SaveAutosave(userId, enums.PageTypeLanding, contentId, []byte(`{"title":"Draft"}`))
+		require.NoError(t, err)
+		assert.Equal(t, pageId, upserted.PageID)
+		assert.Equal(t, enums.PageLanguageEN, upserted.Language)
+		assert.Equal(t, contentId, upserted.BaseContentID)
+		assert.JSONEq(t, `{"title":"Draft"}`, string(state.Autosave.Payload))
+		assert.False(t, state.BaseOutdated)
+		assert.Len(t, state.NewerAutosaves, 1)
+		assert.Equal(t, email, state.NewerAutosaves[0].UserEmail)
+	})
+
+	t.Run("failed to save autosave: payload is not an object", func(t *testing.T) {
+		service := services.NewCMSAutosaveService(&MockCMSAutosaveRepo{}, nil, nil, nil)
+
+		for _, payload := range []string{``, `[1,2]`, `null`, `{"title":`} {
+			state, err := service.SaveAutosave(userId, enums.PageTypeLanding, contentId, []byte(payload))
+			assert.ErrorIs(t, err, errs.ErrInvalidAutosavePayload)
+			assert.Nil(t, state)
+		}
+	})
+}
+
+func TestCMSService_GetAutosave(t *testing.T) {
+	userId := uuid.New()
+	contentId := uuid.New()
+
+	t.Run("successfully get no autosave", func(t *testing.T) {
+		repo := &MockCMSAutosaveRepo{
+			findContentRef: func(pageType enums.PageType, id uuid.UUID) (*repositories.ContentRef, error) {
+				return &repositories.ContentRef{PageID: uuid.New(), Language: enums.PageLanguageTH, Mode: enums.PageModeDraft}, nil
+			},
+			findAutosave: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID) (*models.ContentAutosave, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			findNewerAutosaves: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID, updatedAfter time.Time) ([]models.ContentAutosave, error) {
+				assert.True(t, updatedAfter.IsZero())
+				return nil, nil
+			},
+		}
+
+		service := services.NewCMSAutosaveService(repo, nil, nil, nil)
+
+		state, err := service.GetAutosave(userId, enums.PageTypeFaq, contentId)
+		require.NoError(t, err)
+		assert.Nil(t, state.Autosave)
+		assert.Empty(t, state.NewerAutosaves)
+	})
+
+	t.Run("successfully flag an autosave of an older version", func(t *testing.T) {
+		repo := &MockCMSAutosaveRepo{
+			findContentRef: func(pageType enums.PageType, id uuid.UUID) (*repositories.ContentRef, error) {
+				return &repositories.ContentRef{PageID: uuid.New(), Language: enums.PageLanguageTH, Mode: enums.PageModeDraft}, nil
+			},
+			findAutosave: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID) (*models.ContentAutosave, error) {
+				return &models.ContentAutosave{BaseContentID: uuid.New(), Payload: datatypes.JSON(`{}`)}, nil
+			},
+			findNewerAutosaves: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID, updatedAfter time.Time) ([]models.ContentAutosave, error) {
+				return nil, nil
+			},
+		}
+
+		service := services.NewCMSAutosaveService(repo, nil, nil, nil)
+
+		state, err := service.GetAutosave(userId, enums.PageTypeFaq, contentId)
+		require.NoError(t, err)
+		assert.NotNil(t, state.Autosave)
+		assert.True(t, state.BaseOutdated)
+	})
+}
+
+func TestCMSService_PromoteAutosave(t *testing.T) {
+	userId := uuid.New()
+	pageId := uuid.New()
+	contentId := uuid.New()
+
+	newRepo := func(mode enums.PageMode, baseContentId uuid.UUID, deleted *bool) *MockCMSAutosaveRepo {
+		return &MockCMSAutosaveRepo{
+			findContentRef: func(pageType enums.PageType, id uuid.UUID) (*repositories.ContentRef, error) {
+				return &repositories.ContentRef{PageID: pageId, Language: enums.PageLanguageEN, Mode: mode}, nil
+			},
+			findAutosave: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID) (*models.ContentAutosave, error) {
+				return &models.ContentAutosave{
+					BaseContentID: baseContentId,
+					Payload:       datatypes.JSON(`{"title":"<b>Autosaved</b> title","revision":{"message":"From autosave"}}`),
+				}, nil
+			},
+			deleteAutosave: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID) error {
+				assert.Equal(t, pageId, id)
+				assert.Equal(t, userId, user)
+				*deleted = true
+				return nil
+			},
+		}
+	}
+
+	t.Run("successfully promote autosave to a new version", func(t *testing.T) {
+		var deleted bool
+		faqService := new(MockCMSFaqPageService)
+		savedContent := &models.FaqContent{ID: uuid.New(), Title: "Autosaved title"}
+		faqService.On("UpdateFaqContent", mock.MatchedBy(func(content *models.FaqContent) bool {
+			return content.Title == "Autosaved title" && content.Revision != nil
+		}), contentId).Return(savedContent, nil)
+
+		service := services.NewCMSAutosaveService(newRepo(enums.PageModeDraft, contentId, &deleted), nil, nil, faqService)
+
+		saved, err := service.PromoteAutosave(userId, enums.PageTypeFaq, contentId)
+		require.NoError(t, err)
+		assert.Equal(t, savedContent, saved)
+		assert.True(t, deleted)
+		faqService.AssertExpectations(t)
+	})
+
+	t.Run("failed to promote autosave: content saved again since", func(t *testing.T) {
+		var deleted bool
+		faqService := new(MockCMSFaqPageService)
+		service := services.NewCMSAutosaveService(newRepo(enums.PageModeHistories, contentId, &deleted), nil, nil, faqService)
+
+		saved, err := service.PromoteAutosave(userId, enums.PageTypeFaq, contentId)
+		assert.ErrorIs(t, err, errs.ErrAutosaveOutdated)
+		assert.Nil(t, saved)
+		assert.False(t, deleted)
+		faqService.AssertNotCalled(t, "UpdateFaqContent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed to promote autosave: keep the slot when saving fails", func(t *testing.T) {
+		var deleted bool
+		faqService := new(MockCMSFaqPageService)
+		faqService.On("UpdateFaqContent", mock.Anything, contentId).Return(nil, errs.ErrCriticalAuditFindings)
+
+		service := services.NewCMSAutosaveService(newRepo(enums.PageModeDraft, contentId, &deleted), nil, nil, faqService)
+
+		saved, err := service.PromoteAutosave(userId, enums.PageTypeFaq, contentId)
+		assert.ErrorIs(t, err, errs.ErrCriticalAuditFindings)
+		assert.Nil(t, saved)
+		assert.False(t, deleted)
+	})
+}
+
+func TestCMSService_AutosavePageAccess(t *testing.T) {
+	userId := uuid.New()
+	pageId := uuid.New()
+	contentId := uuid.New()
+	english := enums.PageLanguageEN
+
+	// The user may only read english landing pages
+	access := &dto.PageAccess{Grants: []models.PageGrant{
+		{PageType: enums.PageTypeLanding, Action: enums.PermissionActionRead, Language: &english},
+	}}
+	newRepo := func(language enums.PageLanguage) *MockCMSAutosaveRepo {
+		return &MockCMSAutosaveRepo{
+			findContentRef: func(pageType enums.PageType, id uuid.UUID) (*repositories.ContentRef, error) {
+				return &repositories.ContentRef{PageID: pageId, Language: language, Mode: enums.PageModeDraft}, nil
+			},
+			findContentScopes: func(pageType enums.PageType, id uuid.UUID) ([]dto.PageContentScope, error) {
+				assert.Equal(t, pageId, id)
+				return []dto.PageContentScope{{ContentID: contentId, Language: language, Mode: enums.PageModeDraft}}, nil
+			},
+			findAutosave: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID) (*models.ContentAutosave, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			findNewerAutosaves: func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID, updatedAfter time.Time) ([]models.ContentAutosave, error) {
+				return nil, nil
+			},
+		}
+	}
+
+	t.Run("successfully get the autosave of a granted content", func(t *testing.T) {
+		service := services.NewCMSAutosaveService(newRepo(enums.PageLanguageEN), nil, nil, nil).WithPageAccess(access)
+
+		state, err := service.GetAutosave(userId, enums.PageTypeLanding, contentId)
+		require.NoError(t, err)
+		assert.Nil(t, state.Autosave)
+	})
+
+	t.Run("failed to get autosave: outside the granted language", func(t *testing.T) {
+		service := services.NewCMSAutosaveService(newRepo(enums.PageLanguageTH), nil, nil, nil).WithPageAccess(access)
+
+		state, err := service.GetAutosave(userId, enums.PageTypeLanding, contentId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, state)
+	})
+
+	t.Run("failed to save autosave: no update grant", func(t *testing.T) {
+		repo := newRepo(enums.PageLanguageEN)
+		repo.upsertAutosave = func(autosave *models.ContentAutosave) error {
+			t.Fatal("the autosave must not be saved")
+			return nil
+		}
+		service := services.NewCMSAutosaveService(repo, nil, nil, nil).WithPageAccess(access)
+
+		state, err := service.SaveAutosave(userId, enums.PageTypeLanding, contentId, []byte(`{"title":"Draft"}`))
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, state)
+	})
+
+	t.Run("failed to discard autosave: no update grant", func(t *testing.T) {
+		repo := newRepo(enums.PageLanguageEN)
+		repo.deleteAutosave = func(pageType enums.PageType, id uuid.UUID, language enums.PageLanguage, user uuid.UUID) error {
+			t.Fatal("the autosave must not be deleted")
+			return nil
+		}
+		service := services.NewCMSAutosaveService(repo, nil, nil, nil).WithPageAccess(access)
+
+		err := service.DiscardAutosave(userId, enums.PageTypeLanding, contentId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCMSCalendarService struct {
+	mock.Mock
+}
+
+func (m *MockCMSCalendarService) FindCalendarEvents(query dto.CalendarQuery) ([]dto.CalendarEvent, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.CalendarEvent), args.Error(1)
+}
+
+func (m *MockCMSCalendarService) IssueFeedToken(userId uuid.UUID) (*dto.CalendarFeedTokenResponse, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CalendarFeedTokenResponse), args.Error(1)
+}
+
+func (m *MockCMSCalendarService) RevokeFeedToken(userId uuid.UUID) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+func (m *MockCMSCalendarService) BuildFeed(token string, query dto.CalendarQuery) (string, error) {
+	args := m.Called(token, query)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCMSCalendarService) WithPageAccess(access *dto.PageAccess) services.CMSCalendarServiceInterface {
+	return m
+}
+
+func TestCMSCalendarHandler(t *testing.T) {
+	mockService := &MockCMSCalendarService{}
+	handler := cmsHandler.NewCMSCalendarHandler(mockService)
+
+	userId := uuid.New()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get("Authorization") != "" {
+			c.Locals("user", jwt.MapClaims{"user_id": userId.String()})
+		}
+		return c.Next()
+	})
+	app.Get("/cms/calendar", handler.HandleGetCalendarEvents)
+	app.Post("/cms/calendar/feed", handler.HandleIssueFeedToken)
+	app.Delete("/cms/calendar/feed", handler.HandleRevokeFeedToken)
+	app.Get("/calendar/feeds/:token", handler.HandleGetCalendarFeed)
+
+	t.Run("GET /cms/calendar HandleGetCalendarEvents", func(t *testing.T) {
+		t.Run("successfully get calendar events", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			query := dto.CalendarQuery{
+				From:     time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+				To:       time.Date(2025, 7, 31, 23, 59, 59, 999999999, time.UTC),
+				PageType: enums.PageTypeLanding,
+				Author:   "jane",
+			}
+			mockService.On("FindCalendarEvents", query).Return([]dto.CalendarEvent{{Type: enums.CalendarEventPublish}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/calendar?from=2025-07-01&to=2025-07-31&pageType=landing&author=jane", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get calendar events: invalid date", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/cms/calendar?from=yesterday", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "FindCalendarEvents", mock.Anything)
+		})
+
+		t.Run("failed to get calendar events: invalid filter", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindCalendarEvents", mock.Anything).Return(nil, errs.ErrInvalidWorkflowStatus)
+
+			req := httptest.NewRequest("GET", "/cms/calendar?status=unknown", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /cms/calendar/feed HandleIssueFeedToken", func(t *testing.T) {
+		t.Run("successfully issue calendar feed", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("IssueFeedToken", userId).Return(&dto.CalendarFeedTokenResponse{URL: "https://api.example.com/api/v1/calendar/feeds/abc"}, nil)
+
+			req := httptest.NewRequest("POST", "/cms/calendar/feed", nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		})
+
+		t.Run("failed to issue calendar feed: no user", func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/cms/calendar/feed", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /calendar/feeds/:token HandleGetCalendarFeed", func(t *testing.T) {
+		t.Run("successfully get calendar feed", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("BuildFeed", "abc", dto.CalendarQuery{Language: enums.PageLanguageTH}).Return("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", nil)
+
+			req := httptest.NewRequest("GET", "/calendar/feeds/abc?language=th", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", string(body))
+		})
+
+		t.Run("failed to get calendar feed: unknown token", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("BuildFeed", "unknown", dto.CalendarQuery{}).Return("", errs.ErrInvalidCalendarFeedToken)
+
+			req := httptest.NewRequest("GET", "/calendar/feeds/unknown", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCMSRepo_FindCalendarPartnerContents(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsCalendarRepo := repo.NewCMSCalendarRepository(gormDB)
+
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("successfully find scheduled partner contents with filters", func(t *testing.T) {
+		contentId := uuid.New()
+		categoryId := uuid.New().String()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "partner_contents" WHERE mode NOT IN ($1,$2) AND ((publish_on BETWEEN $3 AND $4) OR (unpublish_on BETWEEN $5 AND $6) OR (expired_at BETWEEN $7 AND $8)) AND language = $9 AND workflow_status = $10 AND (EXISTS (SELECT 1 FROM partner_content_categories WHERE partner_content_categories.partner_content_id = partner_contents.id AND partner_content_categories.category_id = $11)) AND (EXISTS (SELECT 1 FROM revisions WHERE revisions.partner_content_id = partner_contents.id AND revisions.author ILIKE $12))`)).
+			WithArgs(enums.PageModeHistories, enums.PageModePreview, from, to, from, to, from, to, enums.PageLanguageEN, enums.WorkflowSchedule, categoryId, "%jane%").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(contentId, "Partner"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "revisions" WHERE "revisions"."partner_content_id" = $1`)).
+			WithArgs(contentId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "partner_content_id", "author"}).AddRow(uuid.New(), contentId, "Jane"))
+
+		partnerContents, err := cmsCalendarRepo.FindCalendarPartnerContents(dto.CalendarQuery{
+			From:       from,
+			To:         to,
+			Language:   enums.PageLanguageEN,
+			Status:     enums.WorkflowSchedule,
+			CategoryID: categoryId,
+			Author:     "jane",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, partnerContents, 1)
+		assert.Equal(t, "Jane", partnerContents[0].Revision.Author)
+	})
+
+	t.Run("successfully find only the partner contents the page grants allow", func(t *testing.T) {
+		language := enums.PageLanguageTH
+		access := &dto.PageAccess{Grants: []models.PageGrant{{PageType: enums.PageTypePartner, Language: &language, Action: enums.PermissionActionRead}}}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "partner_contents" WHERE mode NOT IN ($1,$2) AND ((publish_on BETWEEN $3 AND $4) OR (unpublish_on BETWEEN $5 AND $6) OR (expired_at BETWEEN $7 AND $8)) AND ((partner_contents.language = $9))`)).
+			WithArgs(enums.PageModeHistories, enums.PageModePreview, from, to, from, to, from, to, enums.PageLanguageTH).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title"}))
+
+		partnerContents, err := cmsCalendarRepo.FindCalendarPartnerContents(dto.CalendarQuery{From: from, To: to, Access: access})
+		assert.NoError(t, err)
+		assert.Empty(t, partnerContents)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FeedTokens(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsCalendarRepo := repo.NewCMSCalendarRepository(gormDB)
+	userId := uuid.New()
+
+	t.Run("successfully replace the feed token of the user", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "calendar_feed_tokens"`) + `.*` +
+			regexp.QuoteMeta(`ON CONFLICT ("user_id") DO UPDATE SET "token_hash"="excluded"."token_hash"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectCommit()
+
+		err := cmsCalendarRepo.UpsertFeedToken(&models.CalendarFeedToken{UserID: userId, TokenHash: "hash", CreatedAt: time.Now()})
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully find feed token by hash", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "calendar_feed_tokens" WHERE token_hash = $1`)).
+			WithArgs("hash", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash"}).AddRow(uuid.New(), userId, "hash"))
+
+		feedToken, err := cmsCalendarRepo.FindFeedTokenByHash("hash")
+		assert.NoError(t, err)
+		assert.Equal(t, userId, feedToken.UserID)
+	})
+
+	t.Run("failed to delete feed token: not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "calendar_feed_tokens" WHERE user_id = $1`)).
+			WithArgs(userId).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := cmsCalendarRepo.DeleteFeedToken(userId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
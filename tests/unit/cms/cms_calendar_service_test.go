@@ -0,0 +1,290 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockCMSCalendarRepo struct {
+	findCalendarLandingContents func(query dto.CalendarQuery) ([]models.LandingContent, error)
+	findCalendarPartnerContents func(query dto.CalendarQuery) ([]models.PartnerContent, error)
+	findCalendarFaqContents     func(query dto.CalendarQuery) ([]models.FaqContent, error)
+	upsertFeedToken             func(feedToken *models.CalendarFeedToken) error
+	findFeedTokenByHash         func(tokenHash string) (*models.CalendarFeedToken, error)
+	touchFeedToken              func(id uuid.UUID, usedAt time.Time) error
+	deleteFeedToken             func(userId uuid.UUID) error
+}
+
+func (m *MockCMSCalendarRepo) FindCalendarLandingContents(query dto.CalendarQuery) ([]models.LandingContent, error) {
+	return m.findCalendarLandingContents(query)
+}
+
+func (m *MockCMSCalendarRepo) FindCalendarPartnerContents(query dto.CalendarQuery) ([]models.PartnerContent, error) {
+	return m.findCalendarPartnerContents(query)
+}
+
+func (m *MockCMSCalendarRepo) FindCalendarFaqContents(query dto.CalendarQuery) ([]models.FaqContent, error) {
+	return m.findCalendarFaqContents(query)
+}
+
+func (m *MockCMSCalendarRepo) UpsertFeedToken(feedToken *models.CalendarFeedToken) error {
+	return m.upsertFeedToken(feedToken)
+}
+
+func (m *MockCMSCalendarRepo) FindFeedTokenByHash(tokenHash string) (*models.CalendarFeedToken, error) {
+	return m.findFeedTokenByHash(tokenHash)
+}
+
+func (m *MockCMSCalendarRepo) TouchFeedToken(id uuid.UUID, usedAt time.Time) error {
+	return m.touchFeedToken(id, usedAt)
+}
+
+func (m *MockCMSCalendarRepo) DeleteFeedToken(userId uuid.UUID) error {
+	return m.deleteFeedToken(userId)
+}
+
+func newCalendarConfig() *config.Config {
+	cfg := config.New()
+	cfg.App.CMSBaseURL = "https://cms.example.com"
+	cfg.App.APIBaseURL = "https://api.example.com"
+	return cfg
+}
+
+func TestCMSService_FindCalendarEvents(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("successfully merge events of every page type in time order", func(t *testing.T) {
+		landingPublish := time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)
+		partnerPublish := time.Date(2025, 7, 5, 0, 0, 0, 0, time.UTC)
+		partnerUnpublish := time.Date(2025, 7, 20, 0, 0, 0, 0, time.UTC)
+		landingContent := models.LandingContent{
+			ID:             uuid.New(),
+			PageID:         uuid.New(),
+			Title:          "Landing",
+			Language:       enums.PageLanguageEN,
+			WorkflowStatus: enums.WorkflowSchedule,
+			PublishOn:      &landingPublish,
+			ExpiredAt:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), // Outside the range
+			Revision:       &models.Revision{Author: "Jane"},
+		}
+		partnerContent := models.PartnerContent{
+			ID:             uuid.New(),
+			PageID:         uuid.New(),
+			Title:          "Partner",
+			Language:       enums.PageLanguageTH,
+			WorkflowStatus: enums.WorkflowPublished,
+			PublishOn:      partnerPublish,
+			UnpublishOn:    partnerUnpublish,
+		}
+
+		repo := &MockCMSCalendarRepo{
+			findCalendarLandingContents: func(query dto.CalendarQuery) ([]models.LandingContent, error) {
+				assert.Equal(t, enums.WorkflowSchedule, query.Status)
+				return []models.LandingContent{landingContent}, nil
+			},
+			findCalendarPartnerContents: func(query dto.CalendarQuery) ([]models.PartnerContent, error) {
+				return []models.PartnerContent{partnerContent}, nil
+			},
+			findCalendarFaqContents: func(query dto.CalendarQuery) ([]models.FaqContent, error) {
+				return nil, nil
+			},
+		}
+
+		service := services.NewCMSCalendarService(repo, &MockCMSAuthRepo{}, new(MockCMSRoleService), newCalendarConfig())
+
+		events, err := service.FindCalendarEvents(dto.CalendarQuery{From: from, To: to, Status: "schedule"})
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+
+		assert.Equal(t, enums.CalendarEventPublish, events[0].Type)
+		assert.Equal(t, "Partner", events[0].Title)
+		assert.Equal(t, enums.CalendarEventPublish, events[1].Type)
+		assert.Equal(t, "Landing", events[1].Title)
+		assert.Equal(t, "Jane", events[1].Author)
+		assert.Equal(t, "https://cms.example.com/landing-pages/"+landingContent.PageID.String()+"/content/"+landingContent.ID.String()+"/edit?lang=en", events[1].EditURL)
+		assert.Equal(t, enums.CalendarEventUnpublish, events[2].Type)
+	})
+
+	t.Run("successfully query only the asked page type", func(t *testing.T) {
+		repo := &MockCMSCalendarRepo{
+			findCalendarFaqContents: func(query dto.CalendarQuery) ([]models.FaqContent, error) {
+				return nil, nil
+			},
+		}
+
+		service := services.NewCMSCalendarService(repo, &MockCMSAuthRepo{}, new(MockCMSRoleService), newCalendarConfig())
+
+		events, err := service.FindCalendarEvents(dto.CalendarQuery{From: from, To: to, PageType: enums.PageTypeFaq})
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("successfully limit the events to the page access of the request", func(t *testing.T) {
+		english := enums.PageLanguageEN
+		access := &dto.PageAccess{Grants: []models.PageGrant{{PageType: enums.PageTypeFaq, Action: enums.PermissionActionRead, Language: &english}}}
+		repo := &MockCMSCalendarRepo{
+			findCalendarFaqContents: func(query dto.CalendarQuery) ([]models.FaqContent, error) {
+				assert.Equal(t, access, query.Access)
+				return nil, nil
+			},
+		}
+
+		service := services.NewCMSCalendarService(repo, &MockCMSAuthRepo{}, new(MockCMSRoleService), newCalendarConfig()).WithPageAccess(access)
+
+		_, err := service.FindCalendarEvents(dto.CalendarQuery{From: from, To: to, PageType: enums.PageTypeFaq})
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed to find calendar events: invalid filters", func(t *testing.T) {
+		service := services.NewCMSCalendarService(&MockCMSCalendarRepo{}, &MockCMSAuthRepo{}, new(MockCMSRoleService), newCalendarConfig())
+
+		_, err := service.FindCalendarEvents(dto.CalendarQuery{From: to, To: from})
+		assert.ErrorIs(t, err, errs.ErrInvalidCalendarRange)
+
+		_, err = service.FindCalendarEvents(dto.CalendarQuery{From: from, To: from.AddDate(2, 0, 0)})
+		assert.ErrorIs(t, err, errs.ErrInvalidCalendarRange)
+
+		_, err = service.FindCalendarEvents(dto.CalendarQuery{From: from, To: to, Language: "fr"})
+		assert.ErrorIs(t, err, errs.ErrInvalidLanguageCode)
+
+		_, err = service.FindCalendarEvents(dto.CalendarQuery{From: from, To: to, CategoryID: "invalid"})
+		assert.ErrorIs(t, err, errs.ErrInvalidUUIDFormat)
+	})
+}
+
+func TestCMSService_CalendarFeed(t *testing.T) {
+	userId := uuid.New()
+	owner := &models.User{ID: userId, Role: &models.Role{Name: "editor"}}
+	authRepo := &MockCMSAuthRepo{
+		findUserById: func(id uuid.UUID) (*models.User, error) {
+			assert.Equal(t, userId, id)
+			return owner, nil
+		},
+	}
+	language := enums.PageLanguageEN
+	access := &dto.PageAccess{Grants: []models.PageGrant{{PageType: enums.PageTypeLanding, Language: &language, Action: enums.PermissionActionRead}}}
+
+	t.Run("successfully issue a feed url and read the feed with it", func(t *testing.T) {
+		var stored *models.CalendarFeedToken
+		var touched bool
+		repo := &MockCMSCalendarRepo{
+			upsertFeedToken: func(feedToken *models.CalendarFeedToken) error {
+				stored = feedToken
+				return nil
+			},
+			findFeedTokenByHash: func(tokenHash string) (*models.CalendarFeedToken, error) {
+				if stored == nil || tokenHash != stored.TokenHash {
+					return nil, gorm.ErrRecordNotFound
+				}
+				return stored, nil
+			},
+			touchFeedToken: func(id uuid.UUID, usedAt time.Time) error {
+				touched = true
+				return nil
+			},
+			findCalendarLandingContents: func(query dto.CalendarQuery) ([]models.LandingContent, error) {
+				assert.Same(t, access, query.Access)
+				assert.WithinDuration(t, time.Now().AddDate(0, 0, -30), query.From, time.Minute)
+				assert.WithinDuration(t, time.Now().AddDate(0, 0, 180), query.To, time.Minute)
+				return nil, nil
+			},
+			findCalendarPartnerContents: func(query dto.CalendarQuery) ([]models.PartnerContent, error) {
+				return nil, nil
+			},
+			findCalendarFaqContents: func(query dto.CalendarQuery) ([]models.FaqContent, error) {
+				return nil, nil
+			},
+		}
+
+		roleService := new(MockCMSRoleService)
+		roleService.On("HasPermission", "editor", enums.PermissionResourcePages, enums.PermissionActionRead).Return(true, nil)
+		roleService.On("FindPageAccess", "editor", userId).Return(access, nil)
+
+		service := services.NewCMSCalendarService(repo, authRepo, roleService, newCalendarConfig())
+
+		feed, err := service.IssueFeedToken(userId)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(feed.URL, "https://api.example.com/api/v1/calendar/feeds/"))
+		token := strings.TrimPrefix(feed.URL, "https://api.example.com/api/v1/calendar/feeds/")
+		assert.NotContains(t, stored.TokenHash, token)
+
+		calendar, err := service.BuildFeed(token, dto.CalendarQuery{})
+		require.NoError(t, err)
+		assert.Contains(t, calendar, "BEGIN:VCALENDAR")
+		assert.True(t, touched)
+	})
+
+	t.Run("failed to read feed: unknown token", func(t *testing.T) {
+		repo := &MockCMSCalendarRepo{
+			findFeedTokenByHash: func(tokenHash string) (*models.CalendarFeedToken, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+
+		service := services.NewCMSCalendarService(repo, &MockCMSAuthRepo{}, new(MockCMSRoleService), newCalendarConfig())
+
+		calendar, err := service.BuildFeed("unknown", dto.CalendarQuery{})
+		assert.ErrorIs(t, err, errs.ErrInvalidCalendarFeedToken)
+		assert.Empty(t, calendar)
+	})
+
+	t.Run("failed to read feed: the owner lost their role, the token is revoked", func(t *testing.T) {
+		var revoked uuid.UUID
+		repo := &MockCMSCalendarRepo{
+			findFeedTokenByHash: func(tokenHash string) (*models.CalendarFeedToken, error) {
+				return &models.CalendarFeedToken{ID: uuid.New(), UserID: userId}, nil
+			},
+			deleteFeedToken: func(userId uuid.UUID) error {
+				revoked = userId
+				return nil
+			},
+		}
+		authRepo := &MockCMSAuthRepo{
+			findUserById: func(id uuid.UUID) (*models.User, error) {
+				return &models.User{ID: userId}, nil
+			},
+		}
+
+		service := services.NewCMSCalendarService(repo, authRepo, new(MockCMSRoleService), newCalendarConfig())
+
+		calendar, err := service.BuildFeed("token", dto.CalendarQuery{})
+		assert.ErrorIs(t, err, errs.ErrInvalidCalendarFeedToken)
+		assert.Empty(t, calendar)
+		assert.Equal(t, userId, revoked)
+	})
+
+	t.Run("failed to read feed: the owner may no longer read pages, the token is revoked", func(t *testing.T) {
+		revoked := false
+		repo := &MockCMSCalendarRepo{
+			findFeedTokenByHash: func(tokenHash string) (*models.CalendarFeedToken, error) {
+				return &models.CalendarFeedToken{ID: uuid.New(), UserID: userId}, nil
+			},
+			deleteFeedToken: func(userId uuid.UUID) error {
+				revoked = true
+				return nil
+			},
+		}
+		roleService := new(MockCMSRoleService)
+		roleService.On("HasPermission", "editor", enums.PermissionResourcePages, enums.PermissionActionRead).Return(false, nil)
+
+		service := services.NewCMSCalendarService(repo, authRepo, roleService, newCalendarConfig())
+
+		_, err := service.BuildFeed("token", dto.CalendarQuery{})
+		assert.ErrorIs(t, err, errs.ErrInvalidCalendarFeedToken)
+		assert.True(t, revoked)
+	})
+}
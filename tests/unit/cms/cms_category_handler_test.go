@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http/httptest"
 	"testing"
 
@@ -12,6 +13,7 @@ import (
 	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -47,6 +49,14 @@ func (m *MockCMSCategoryService) ListAllCategories(filter dto.CategoryFilter) ([
 	return args.Get(0).([]dto.CategoryResponse), args.Error(1)
 }
 
+func (m *MockCMSCategoryService) ListCategoriesByCursor(filter dto.CategoryFilter, cursorQuery dto.CursorQuery) ([]dto.CategoryResponse, *dto.CursorPage, error) {
+	args := m.Called(filter, cursorQuery)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]dto.CategoryResponse), args.Get(1).(*dto.CursorPage), args.Error(2)
+}
+
 func (m *MockCMSCategoryService) UpdateCategoryByUUID(uuidStr string, req dto.CategoryUpdateRequest) (*dto.CategoryResponse, error) {
 	args := m.Called(uuidStr, req)
 	if args.Get(0) == nil {
@@ -55,8 +65,8 @@ func (m *MockCMSCategoryService) UpdateCategoryByUUID(uuidStr string, req dto.Ca
 	return args.Get(0).(*dto.CategoryResponse), args.Error(1)
 }
 
-func (m *MockCMSCategoryService) DeleteCategoryByUUID(uuidStr string) error {
-	args := m.Called(uuidStr)
+func (m *MockCMSCategoryService) DeleteCategoryByUUID(uuidStr string, force bool) error {
+	args := m.Called(uuidStr, force)
 	return args.Error(0)
 }
 
@@ -70,7 +80,10 @@ func (m *MockCMSCategoryService) MapCategoryModelToResponse(cat *models.Category
 
 func TestCMSCategoryHandler(t *testing.T) {
 	mockService := &MockCMSCategoryService{}
-	handler := cmsHandler.NewCMSCategoryHandler(mockService)
+	mockUsageService := &MockCMSUsageService{}
+	mockUsageService.On("CheckDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	mockCache := newMockAppResponseCache()
+	handler := cmsHandler.NewCMSCategoryHandler(mockService, mockUsageService, mockCache)
 
 	app := fiber.New()
 	app.Post("/cms/categories", handler.HandleCreateCategory)
@@ -233,6 +246,7 @@ func TestCMSCategoryHandler(t *testing.T) {
 			resp, err := app.Test(req)
 			require.NoError(t, err)
 			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockCache.AssertCalled(t, "InvalidateCategory", mockCategory.ID)
 		})
 
 		t.Run("failed to update category", func(t *testing.T) {
@@ -255,7 +269,7 @@ func TestCMSCategoryHandler(t *testing.T) {
 		require.NoError(t, err)
 
 		t.Run("successfully delete category", func(t *testing.T) {
-			mockService.On("DeleteCategoryByUUID", mock.AnythingOfType("string")).Return(nil)
+			mockService.On("DeleteCategoryByUUID", mock.AnythingOfType("string"), false).Return(nil)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/categories/%s", mockCategory.ID.String()), bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
@@ -267,7 +281,7 @@ func TestCMSCategoryHandler(t *testing.T) {
 
 		t.Run("failed to delete category", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("DeleteCategoryByUUID", mock.AnythingOfType("string")).Return(errs.ErrInternalServerError)
+			mockService.On("DeleteCategoryByUUID", mock.AnythingOfType("string"), false).Return(errs.ErrInternalServerError)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/categories/%s", mockCategory.ID.String()), bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
@@ -276,5 +290,53 @@ func TestCMSCategoryHandler(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
 		})	
+
+		t.Run("failed to delete category: still tagged on published content", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			mockUsageService.ExpectedCalls = nil
+			mockUsageService.On("CheckDelete", enums.UsageItemCategory, mockCategory.ID, false).
+				Return(&dto.UsageImpact{ItemID: mockCategory.ID, References: 2, PublishedReferences: 1, Blocked: true}, errs.ErrItemInUse)
+
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/categories/%s", mockCategory.ID.String()), nil)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+			respBody, _ := io.ReadAll(resp.Body)
+			require.Contains(t, string(respBody), `"published_references":1`)
+			mockService.AssertNotCalled(t, "DeleteCategoryByUUID", mock.Anything)
+		})
+
+		t.Run("successfully force delete a category in use", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("DeleteCategoryByUUID", mockCategory.ID.String(), true).Return(nil)
+			mockUsageService.ExpectedCalls = nil
+			mockUsageService.On("CheckDelete", enums.UsageItemCategory, mockCategory.ID, true).
+				Return(&dto.UsageImpact{ItemID: mockCategory.ID, PublishedReferences: 1, Blocked: true}, nil)
+
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/categories/%s?force=true", mockCategory.ID.String()), nil)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to delete a category published while it was checked", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("DeleteCategoryByUUID", mockCategory.ID.String(), false).Return(errs.ErrItemInUse)
+			mockUsageService.ExpectedCalls = nil
+			mockUsageService.On("CheckDelete", enums.UsageItemCategory, mockCategory.ID, false).
+				Return(&dto.UsageImpact{ItemID: mockCategory.ID}, nil)
+			mockUsageService.On("GetDeleteImpact", enums.UsageItemCategory, mockCategory.ID).
+				Return(&dto.UsageImpact{ItemID: mockCategory.ID, PublishedReferences: 1, Blocked: true}, nil)
+
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/categories/%s", mockCategory.ID.String()), nil)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+			respBody, _ := io.ReadAll(resp.Body)
+			require.Contains(t, string(respBody), `"published_references":1`)
+			mockService.AssertExpectations(t)
+		})
 	})
 }
\ No newline at end of file
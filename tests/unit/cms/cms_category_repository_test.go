@@ -3,6 +3,7 @@ package tests
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
@@ -169,6 +170,44 @@ func TestCMSRepo_ListCategoriesByFilter(t *testing.T) {
 	})
 }
 
+func TestCMSRepo_ListCategoriesByCursor(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsCategoryRepo := repo.NewCMSCategoryRepository(gormDB)
+
+	categoryId := uuid.New()
+	categoryTypeId := uuid.New()
+	createdAt := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("successfully list the categories after a cursor by weight then creation", func(t *testing.T) {
+		cursor := helpers.EncodeCursor(helpers.Cursor{Sort: "weight:asc", Values: []string{"2", createdAt.Format(time.RFC3339Nano)}, ID: uuid.New()})
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "categories" WHERE language_code = $1 AND (weight, created_at, id) > ($2, $3, $4) ORDER BY weight ASC,created_at ASC,id ASC LIMIT $5`)).
+			WithArgs(enums.PageLanguageEN, 2, createdAt, sqlmock.AnyArg(), 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "category_type_id", "weight", "created_at"}).
+				AddRow(categoryId, categoryTypeId, 3, createdAt))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "category_types"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryTypeId))
+
+		language := enums.PageLanguageEN
+		categories, page, err := cmsCategoryRepo.ListCategoriesByCursor(dto.CategoryFilter{LanguageCode: &language}, dto.CursorQuery{Cursor: cursor})
+		assert.NoError(t, err)
+		assert.Len(t, categories, 1)
+		assert.Empty(t, page.Next)
+		assert.NotEmpty(t, page.Prev)
+		assert.Equal(t, 10, page.Limit)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to list categories: malformed cursor", func(t *testing.T) {
+		categories, page, err := cmsCategoryRepo.ListCategoriesByCursor(dto.CategoryFilter{}, dto.CursorQuery{Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, errs.ErrInvalidCursor)
+		assert.Nil(t, categories)
+		assert.Nil(t, page)
+	})
+}
+
 func TestCMSRepo_DeleteCategory(t *testing.T) {
 	gormDB, mock, cleanup := helpers.SetupTestDB(t)
 	defer cleanup()
@@ -180,37 +219,73 @@ func TestCMSRepo_DeleteCategory(t *testing.T) {
 	t.Run("successfully delete category", func(t *testing.T) {
 		mock.ExpectBegin()
 
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemCategory, categoryId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "categories"`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectCommit()
 
-		err := cmsCategoryRepo.DeleteCategory(categoryId)
+		err := cmsCategoryRepo.DeleteCategory(categoryId, false)
 		assert.NoError(t, err)
 	})
 
 	t.Run("failed to delete category - not found", func(t *testing.T) {
 		mock.ExpectBegin()
 
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemCategory, categoryId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "categories"`)).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
-		err := cmsCategoryRepo.DeleteCategory(categoryId)
+		err := cmsCategoryRepo.DeleteCategory(categoryId, false)
 		assert.Error(t, err)
 		assert.Equal(t, gorm.ErrRecordNotFound, err)
 	})
 
+	t.Run("failed to delete category: tagged on published content", func(t *testing.T) {
+		mock.ExpectBegin()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemCategory, categoryId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		mock.ExpectRollback()
+
+		err := cmsCategoryRepo.DeleteCategory(categoryId, false)
+		assert.ErrorIs(t, err, errs.ErrItemInUse)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully force delete a category in use", func(t *testing.T) {
+		mock.ExpectBegin()
+
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "categories"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectCommit()
+
+		err := cmsCategoryRepo.DeleteCategory(categoryId, true)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("failed to delete category: internal server error", func(t *testing.T) {
 		mock.ExpectBegin()
 
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemCategory, categoryId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "categories"`)).
 			WillReturnError(errs.ErrInternalServerError)
 
 		mock.ExpectRollback()
 
-		err := cmsCategoryRepo.DeleteCategory(categoryId)
+		err := cmsCategoryRepo.DeleteCategory(categoryId, false)
 		assert.Error(t, err)
 		assert.NotNil(t, err)
 	})	
@@ -18,9 +18,10 @@ type MockCMSCategoryRepo struct {
 	createCategory                   func(category *models.Category) (*models.Category, error)
 	getCategoryByID                  func(categoryID uuid.UUID) (*models.Category, error)
 	updateCategory                   func(category *models.Category) (*models.Category, error)
-	deleteCategory                   func(categoryID uuid.UUID) error
+	deleteCategory                   func(categoryID uuid.UUID, force bool) error
 	countCategoriesByTypeAndLanguage func(categoryTypeID uuid.UUID) (map[string]int, error)
 	listCategoriesByFilter           func(filters dto.CategoryFilter) ([]models.Category, error)
+	listCategoriesByCursor           func(filters dto.CategoryFilter, cursorQuery dto.CursorQuery) ([]models.Category, *dto.CursorPage, error)
 }
 
 func (m *MockCMSCategoryRepo) CreateCategory(category *models.Category) (*models.Category, error) {
@@ -35,8 +36,8 @@ func (m *MockCMSCategoryRepo) UpdateCategory(category *models.Category) (*models
 	return m.updateCategory(category)
 }
 
-func (m *MockCMSCategoryRepo) DeleteCategory(categoryID uuid.UUID) error {
-	return m.deleteCategory(categoryID)
+func (m *MockCMSCategoryRepo) DeleteCategory(categoryID uuid.UUID, force bool) error {
+	return m.deleteCategory(categoryID, force)
 }
 
 func (m *MockCMSCategoryRepo) CountCategoriesByTypeAndLanguage(categoryTypeID uuid.UUID) (map[string]int, error) {
@@ -47,6 +48,10 @@ func (m *MockCMSCategoryRepo) ListCategoriesByFilter(filters dto.CategoryFilter)
 	return m.listCategoriesByFilter(filters)
 }
 
+func (m *MockCMSCategoryRepo) ListCategoriesByCursor(filters dto.CategoryFilter, cursorQuery dto.CursorQuery) ([]models.Category, *dto.CursorPage, error) {
+	return m.listCategoriesByCursor(filters, cursorQuery)
+}
+
 func TestCMSService_MapCategoryModelToResponse(t *testing.T) {
 
 	t.Run("successfully map category to response: CategoryType is not nil", func(t *testing.T) {
@@ -325,14 +330,14 @@ func TestCMSService_DeleteCategoryByUUID(t *testing.T) {
 			getCategoryByID: func(categoryID uuid.UUID) (*models.Category, error) {
 				return mockCategory, nil
 			},
-			deleteCategory: func(categoryID uuid.UUID) error {
+			deleteCategory: func(categoryID uuid.UUID, force bool) error {
 				return nil
 			},
 		}
 		mockCategoryTypeRepo := &MockCMSCategoryTypeRepo{}
 		service := services.NewCMSCategoryService(mockCategoryRepo, mockCategoryTypeRepo)		
 		
-		err := service.DeleteCategoryByUUID(mockCategory.ID.String())
+		err := service.DeleteCategoryByUUID(mockCategory.ID.String(), false)
 		assert.NoError(t, err)
 	})
 
@@ -344,14 +349,14 @@ func TestCMSService_DeleteCategoryByUUID(t *testing.T) {
 			getCategoryByID: func(categoryID uuid.UUID) (*models.Category, error) {
 				return mockCategory, nil
 			},
-			deleteCategory: func(categoryID uuid.UUID) error {
+			deleteCategory: func(categoryID uuid.UUID, force bool) error {
 				return errs.ErrInternalServerError
 			},
 		}
 		mockCategoryTypeRepo := &MockCMSCategoryTypeRepo{}
 		service := services.NewCMSCategoryService(mockCategoryRepo, mockCategoryTypeRepo)		
 		
-		err := service.DeleteCategoryByUUID(mockCategory.ID.String())
+		err := service.DeleteCategoryByUUID(mockCategory.ID.String(), false)
 		assert.Error(t, err)
 	})
 
@@ -367,7 +372,7 @@ func TestCMSService_DeleteCategoryByUUID(t *testing.T) {
 		mockCategoryTypeRepo := &MockCMSCategoryTypeRepo{}
 		service := services.NewCMSCategoryService(mockCategoryRepo, mockCategoryTypeRepo)		
 		
-		err := service.DeleteCategoryByUUID(mockCategory.ID.String())
+		err := service.DeleteCategoryByUUID(mockCategory.ID.String(), false)
 		assert.Error(t, err)
 	})	
 }
\ No newline at end of file
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockCMSContentAuditService struct {
+	mock.Mock
+}
+
+func (m *MockCMSContentAuditService) AuditContent(pageType string, contentId uuid.UUID) (*dto.ContentAuditReport, error) {
+	args := m.Called(pageType, contentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ContentAuditReport), args.Error(1)
+}
+
+func (m *MockCMSContentAuditService) WithPageAccess(access *dto.PageAccess) services.CMSContentAuditServiceInterface {
+	return m
+}
+
+func TestCMSContentAuditHandler(t *testing.T) {
+	mockService := &MockCMSContentAuditService{}
+	handler := cmsHandler.NewCMSContentAuditHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/audits/:pageType/:contentId", handler.HandleAuditContent)
+
+	t.Run("GET /cms/audits/:pageType/:contentId HandleAuditContent", func(t *testing.T) {
+		contentId := uuid.New()
+		report := &dto.ContentAuditReport{
+			ContentID: contentId.String(),
+			PageType:  enums.PageTypeFaq,
+			Score:     100,
+			Findings:  []dto.ContentAuditFinding{},
+		}
+
+		t.Run("successfully audit content", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("AuditContent", "faq", contentId).Return(report, nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/cms/audits/faq/%s", contentId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to audit content: invalid content id", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest("GET", "/cms/audits/faq/invalid-id", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("failed to audit content: invalid page type", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("AuditContent", "unknown", contentId).Return(nil, errs.ErrInvalidPageType)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/cms/audits/unknown/%s", contentId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to audit content: content not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("AuditContent", "landing", contentId).Return(nil, gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/cms/audits/landing/%s", contentId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to audit content: internal server error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("AuditContent", "partner", contentId).Return(nil, errs.ErrInternalServerError)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/cms/audits/partner/%s", contentId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCMSRepo_FindFaqContentByIdForAudit(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsContentAuditRepo := repo.NewCMSContentAuditRepository(gormDB)
+
+	contentId := uuid.New()
+
+	t.Run("successfully find faq content", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "faq_contents" WHERE id = $1`)).
+			WillReturnRows(
+				sqlmock.NewRows([]string{"id", "title"}).
+					AddRow(contentId, "Mock FAQ Title"),
+			)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "components"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		actualContent, err := cmsContentAuditRepo.FindFaqContentById(contentId)
+		assert.NoError(t, err)
+		assert.Equal(t, contentId, actualContent.ID)
+	})
+
+	t.Run("failed to find faq content", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "faq_contents" WHERE id = $1`)).
+			WillReturnError(errs.ErrInternalServerError)
+
+		actualContent, err := cmsContentAuditRepo.FindFaqContentById(contentId)
+		assert.Error(t, err)
+		assert.Nil(t, actualContent)
+	})
+}
+
+func TestCMSRepo_CountDuplicateTitles(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsContentAuditRepo := repo.NewCMSContentAuditRepository(gormDB)
+
+	pageId := uuid.New()
+
+	t.Run("successfully count duplicate titles", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT("page_id")) FROM "landing_contents" WHERE LOWER(title) = LOWER($1) AND language = $2 AND page_id != $3 AND mode != $4`)).
+			WithArgs("Mock Title", enums.PageLanguageEN, pageId, enums.PageModeHistories).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		count, err := cmsContentAuditRepo.CountDuplicateTitles(enums.PageTypeLanding, "Mock Title", enums.PageLanguageEN, pageId)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("failed to count duplicate titles", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT("page_id")) FROM "partner_contents"`)).
+			WillReturnError(errs.ErrInternalServerError)
+
+		count, err := cmsContentAuditRepo.CountDuplicateTitles(enums.PageTypePartner, "Mock Title", enums.PageLanguageEN, pageId)
+		assert.Error(t, err)
+		assert.Zero(t, count)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
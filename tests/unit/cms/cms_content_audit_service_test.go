@@ -0,0 +1,309 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type MockCMSContentAuditRepo struct {
+	findLandingContentById func(contentId uuid.UUID) (*models.LandingContent, error)
+	findPartnerContentById func(contentId uuid.UUID) (*models.PartnerContent, error)
+	findFaqContentById     func(contentId uuid.UUID) (*models.FaqContent, error)
+	countDuplicateTitles   func(pageType enums.PageType, title string, language enums.PageLanguage, pageId uuid.UUID) (int64, error)
+	findContentScopes      func(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+func (m *MockCMSContentAuditRepo) FindLandingContentById(contentId uuid.UUID) (*models.LandingContent, error) {
+	return m.findLandingContentById(contentId)
+}
+
+func (m *MockCMSContentAuditRepo) FindPartnerContentById(contentId uuid.UUID) (*models.PartnerContent, error) {
+	return m.findPartnerContentById(contentId)
+}
+
+func (m *MockCMSContentAuditRepo) FindFaqContentById(contentId uuid.UUID) (*models.FaqContent, error) {
+	return m.findFaqContentById(contentId)
+}
+
+func (m *MockCMSContentAuditRepo) CountDuplicateTitles(pageType enums.PageType, title string, language enums.PageLanguage, pageId uuid.UUID) (int64, error) {
+	return m.countDuplicateTitles(pageType, title, language, pageId)
+}
+
+func (m *MockCMSContentAuditRepo) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageType, pageId)
+}
+
+func findingCodes(findings []dto.ContentAuditFinding) []string {
+	codes := []string{}
+	for _, finding := range findings {
+		codes = append(codes, finding.Code)
+	}
+	return codes
+}
+
+func TestCMSService_AuditContent(t *testing.T) {
+	contentId := uuid.New()
+
+	t.Run("successfully audit faq content", func(t *testing.T) {
+		faqContent := &models.FaqContent{
+			ID:        contentId,
+			PageID:    uuid.New(),
+			Title:     "Mock FAQ Title",
+			Language:  enums.PageLanguageEN,
+			URLAlias:  "mock-faq",
+			HTMLInput: "<h1>Title</h1><p>" + strings.Repeat("a", 400) + "</p>",
+			MetaTag: &models.MetaTag{
+				Title:       "Mock FAQ Title",
+				Description: "Mock FAQ description",
+				CoverImage:  "https://example.com/cover.png",
+			},
+		}
+
+		repo := &MockCMSContentAuditRepo{
+			findFaqContentById: func(id uuid.UUID) (*models.FaqContent, error) {
+				return faqContent, nil
+			},
+			countDuplicateTitles: func(pageType enums.PageType, title string, language enums.PageLanguage, pageId uuid.UUID) (int64, error) {
+				assert.Equal(t, enums.PageTypeFaq, pageType)
+				return 0, nil
+			},
+		}
+
+		service := services.NewCMSContentAuditService(repo)
+
+		report, err := service.AuditContent("faq", contentId)
+		assert.NoError(t, err)
+		assert.Equal(t, 100, report.Score)
+		assert.False(t, report.HasCritical)
+		assert.Empty(t, report.Findings)
+	})
+
+	t.Run("successfully audit landing content with findings", func(t *testing.T) {
+		landingContent := &models.LandingContent{
+			ID:        contentId,
+			PageID:    uuid.New(),
+			Title:     "Mock Landing Title",
+			Language:  enums.PageLanguageEN,
+			HTMLInput: `<img src="hero.png">`,
+			MetaTag:   &models.MetaTag{},
+		}
+
+		repo := &MockCMSContentAuditRepo{
+			findLandingContentById: func(id uuid.UUID) (*models.LandingContent, error) {
+				return landingContent, nil
+			},
+			countDuplicateTitles: func(pageType enums.PageType, title string, language enums.PageLanguage, pageId uuid.UUID) (int64, error) {
+				return 1, nil
+			},
+		}
+
+		service := services.NewCMSContentAuditService(repo)
+
+		report, err := service.AuditContent("landing", contentId)
+		assert.NoError(t, err)
+		assert.True(t, report.HasCritical)
+		assert.ElementsMatch(t, []string{
+			"meta_title_missing",
+			"meta_description_missing",
+			"cover_image_missing",
+			"image_alt_missing",
+			"content_too_short",
+			"url_alias_missing",
+			"title_duplicate",
+		}, findingCodes(report.Findings))
+		assert.Equal(t, 0, report.Score)
+	})
+
+	t.Run("failed to audit content: invalid page type", func(t *testing.T) {
+		service := services.NewCMSContentAuditService(&MockCMSContentAuditRepo{})
+
+		report, err := service.AuditContent("unknown", contentId)
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+		assert.Nil(t, report)
+	})
+
+	t.Run("failed to audit content: content not found", func(t *testing.T) {
+		repo := &MockCMSContentAuditRepo{
+			findPartnerContentById: func(id uuid.UUID) (*models.PartnerContent, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+
+		service := services.NewCMSContentAuditService(repo)
+
+		report, err := service.AuditContent("partner", contentId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, report)
+	})
+}
+
+func TestCMSService_AuditContentPageAccess(t *testing.T) {
+	contentId := uuid.New()
+	pageId := uuid.New()
+	english := enums.PageLanguageEN
+
+	// The user may only read english faq pages
+	access := &dto.PageAccess{Grants: []models.PageGrant{
+		{PageType: enums.PageTypeFaq, Action: enums.PermissionActionRead, Language: &english},
+	}}
+	newRepo := func(language enums.PageLanguage) *MockCMSContentAuditRepo {
+		return &MockCMSContentAuditRepo{
+			findFaqContentById: func(id uuid.UUID) (*models.FaqContent, error) {
+				return &models.FaqContent{ID: contentId, PageID: pageId, Language: language}, nil
+			},
+			findContentScopes: func(pageType enums.PageType, id uuid.UUID) ([]dto.PageContentScope, error) {
+				assert.Equal(t, enums.PageTypeFaq, pageType)
+				assert.Equal(t, pageId, id)
+				return []dto.PageContentScope{{ContentID: contentId, Language: language, Mode: enums.PageModeDraft}}, nil
+			},
+		}
+	}
+
+	t.Run("successfully audit content in the granted language", func(t *testing.T) {
+		report, err := services.NewCMSContentAuditService(newRepo(enums.PageLanguageEN)).WithPageAccess(access).AuditContent("faq", contentId)
+		assert.NoError(t, err)
+		assert.NotNil(t, report)
+	})
+
+	t.Run("failed to audit content: outside the granted language", func(t *testing.T) {
+		report, err := services.NewCMSContentAuditService(newRepo(enums.PageLanguageTH)).WithPageAccess(access).AuditContent("faq", contentId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, report)
+	})
+
+	t.Run("failed to audit content: request without a page access", func(t *testing.T) {
+		report, err := services.NewCMSContentAuditService(newRepo(enums.PageLanguageEN)).WithPageAccess(nil).AuditContent("faq", contentId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, report)
+	})
+}
+
+func TestCMSService_PublishBlockedByCriticalAudit(t *testing.T) {
+	cfg := config.New()
+	cfg.Audit.BlockPublishOnCritical = true
+
+	t.Run("failed to create faq page: critical audit findings", func(t *testing.T) {
+		mockFaqPage := helpers.InitializeMockFaqPage()
+		mockFaqPage.Contents[0].WorkflowStatus = enums.WorkflowPublished
+		mockFaqPage.Contents[0].MetaTag.Title = ""
+
+		repo := &MockCMSFaqPageRepo{
+			isUrlDuplicate: func(url string, pageId uuid.UUID) (bool, error) {
+				return false, nil
+			},
+			isUrlAliasDuplicate: func(urlAlias string, pageId uuid.UUID) (bool, error) {
+				return false, nil
+			},
+		}
+
+		service := services.NewCMSFaqPageService(repo, cfg)
+
+		actualFaqPage, err := service.CreateFaqPage(mockFaqPage)
+		assert.ErrorIs(t, err, errs.ErrCriticalAuditFindings)
+		assert.Nil(t, actualFaqPage)
+	})
+
+	t.Run("successfully create faq page: draft is not audited", func(t *testing.T) {
+		mockFaqPage := helpers.InitializeMockFaqPage()
+		mockFaqPage.Contents[0].MetaTag.Title = ""
+
+		repo := &MockCMSFaqPageRepo{
+			isUrlDuplicate: func(url string, pageId uuid.UUID) (bool, error) {
+				return false, nil
+			},
+			isUrlAliasDuplicate: func(urlAlias string, pageId uuid.UUID) (bool, error) {
+				return false, nil
+			},
+			createFaqPage: func(faqPage *models.FaqPage) (*models.FaqPage, error) {
+				return faqPage, nil
+			},
+		}
+
+		service := services.NewCMSFaqPageService(repo, cfg)
+
+		actualFaqPage, err := service.CreateFaqPage(mockFaqPage)
+		assert.NoError(t, err)
+		assert.NotNil(t, actualFaqPage)
+	})
+}
+
+func TestCMSService_CopyBlockedByCriticalAudit(t *testing.T) {
+	cfg := config.New()
+	cfg.Audit.BlockPublishOnCritical = true
+
+	pageId := uuid.New()
+	contentId := uuid.New()
+	revisionId := uuid.New()
+
+	newContent := func(status enums.WorkflowStatus) *models.FaqContent {
+		content := helpers.InitializeMockFaqPage().Contents[0]
+		content.ID = contentId
+		content.WorkflowStatus = status
+		content.MetaTag.Title = ""
+		return content
+	}
+
+	t.Run("failed to revert faq content: critical audit findings", func(t *testing.T) {
+		repo := &MockCMSFaqPageRepo{
+			getContentRefByRevisionId: func(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+				return pageId, contentId, nil
+			},
+			findContentById: func(id uuid.UUID) (*models.FaqContent, error) {
+				assert.Equal(t, contentId, id)
+				return newContent(enums.WorkflowPublished), nil
+			},
+		}
+
+		service := services.NewCMSFaqPageService(repo, cfg)
+
+		actualContent, err := service.RevertFaqContent(revisionId, &models.Revision{PublishStatus: enums.PublishStatusNotPublished})
+		assert.ErrorIs(t, err, errs.ErrCriticalAuditFindings)
+		assert.Nil(t, actualContent)
+	})
+
+	t.Run("failed to duplicate faq content to another language: critical audit findings", func(t *testing.T) {
+		repo := &MockCMSFaqPageRepo{
+			findContentById: func(id uuid.UUID) (*models.FaqContent, error) {
+				return newContent(enums.WorkflowPublished), nil
+			},
+		}
+
+		service := services.NewCMSFaqPageService(repo, cfg)
+
+		actualContent, err := service.DuplicateFaqContentToAnotherLanguage(contentId, &models.Revision{PublishStatus: enums.PublishStatusNotPublished})
+		assert.ErrorIs(t, err, errs.ErrCriticalAuditFindings)
+		assert.Nil(t, actualContent)
+	})
+
+	t.Run("successfully revert faq content: draft is not audited", func(t *testing.T) {
+		repo := &MockCMSFaqPageRepo{
+			getContentRefByRevisionId: func(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+				return pageId, contentId, nil
+			},
+			findContentById: func(id uuid.UUID) (*models.FaqContent, error) {
+				return newContent(enums.WorkflowDraft), nil
+			},
+			revertFaqContent: func(revisionId uuid.UUID, newRevision *models.Revision) (*models.FaqContent, error) {
+				return newContent(enums.WorkflowDraft), nil
+			},
+		}
+
+		service := services.NewCMSFaqPageService(repo, cfg)
+
+		actualContent, err := service.RevertFaqContent(revisionId, &models.Revision{PublishStatus: enums.PublishStatusNotPublished})
+		assert.NoError(t, err)
+		assert.NotNil(t, actualContent)
+	})
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockCMSContentRelationService struct {
+	mock.Mock
+}
+
+func (m *MockCMSContentRelationService) GetRelations(pageType enums.PageType, contentId uuid.UUID) ([]models.ContentRelation, error) {
+	args := m.Called(pageType, contentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ContentRelation), args.Error(1)
+}
+
+func (m *MockCMSContentRelationService) ReplaceRelations(pageType enums.PageType, contentId uuid.UUID, request dto.ReplaceContentRelationsRequest) ([]models.ContentRelation, error) {
+	args := m.Called(pageType, contentId, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ContentRelation), args.Error(1)
+}
+
+func (m *MockCMSContentRelationService) GetIncomingRelations(pageType enums.PageType, pageId uuid.UUID) ([]dto.IncomingRelation, error) {
+	args := m.Called(pageType, pageId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.IncomingRelation), args.Error(1)
+}
+
+func (m *MockCMSContentRelationService) ResolveRelations(contentId uuid.UUID, language enums.PageLanguage) ([]models.RelatedPage, error) {
+	args := m.Called(contentId, language)
+	return nil, args.Error(1)
+}
+
+func (m *MockCMSContentRelationService) WithPageAccess(access *dto.PageAccess) services.CMSContentRelationServiceInterface {
+	return m
+}
+
+func TestCMSContentRelationHandler(t *testing.T) {
+	mockService := &MockCMSContentRelationService{}
+	handler := cmsHandler.NewCMSContentRelationHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/relations/incoming/:pageType/:pageId", handler.HandleGetIncomingRelations)
+	app.Get("/cms/relations/:pageType/contents/:contentId", handler.HandleGetRelations)
+	app.Put("/cms/relations/:pageType/contents/:contentId", handler.HandleReplaceRelations)
+
+	pageId := uuid.New()
+	contentId := uuid.New()
+	targetId := uuid.New()
+
+	t.Run("GET /cms/relations/:pageType/contents/:contentId HandleGetRelations", func(t *testing.T) {
+		t.Run("successfully get relations", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetRelations", enums.PageTypeLanding, contentId).Return([]models.ContentRelation{{TargetPageID: targetId}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/relations/landing/contents/"+contentId.String(), nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), targetId.String())
+		})
+
+		t.Run("failed to get relations: invalid page type", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetRelations", enums.PageType("blog"), contentId).Return(nil, errs.ErrInvalidPageType)
+
+			req := httptest.NewRequest("GET", "/cms/relations/blog/contents/"+contentId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("PUT /cms/relations/:pageType/contents/:contentId HandleReplaceRelations", func(t *testing.T) {
+		body := `{"relations":[{"relation_type":"related_article","target_page_type":"partner","target_page_id":"` + targetId.String() + `"}]}`
+
+		t.Run("successfully replace relations", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			request := dto.ReplaceContentRelationsRequest{Relations: []dto.ContentRelationRequest{
+				{RelationType: enums.RelationRelatedArticle, TargetPageType: enums.PageTypePartner, TargetPageID: targetId.String()},
+			}}
+			mockService.On("ReplaceRelations", enums.PageTypeLanding, contentId, request).Return([]models.ContentRelation{{TargetPageID: targetId}}, nil)
+
+			req := httptest.NewRequest("PUT", "/cms/relations/landing/contents/"+contentId.String(), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to replace relations: target page not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("ReplaceRelations", enums.PageTypeLanding, contentId, mock.Anything).Return(nil, errs.ErrRelationTargetNotFound)
+
+			req := httptest.NewRequest("PUT", "/cms/relations/landing/contents/"+contentId.String(), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("failed to replace relations: published content", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("ReplaceRelations", enums.PageTypeLanding, contentId, mock.Anything).Return(nil, errs.ErrRelationsLocked)
+
+			req := httptest.NewRequest("PUT", "/cms/relations/landing/contents/"+contentId.String(), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		})
+
+		t.Run("failed to replace relations: content not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("ReplaceRelations", enums.PageTypeLanding, contentId, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("PUT", "/cms/relations/landing/contents/"+contentId.String(), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("failed to replace relations: invalid contentId", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("PUT", "/cms/relations/landing/contents/abc", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "ReplaceRelations", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	t.Run("GET /cms/relations/incoming/:pageType/:pageId HandleGetIncomingRelations", func(t *testing.T) {
+		t.Run("successfully get incoming relations", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetIncomingRelations", enums.PageTypePartner, targetId).Return([]dto.IncomingRelation{
+				{ContentRelation: models.ContentRelation{SourcePageID: pageId}, SourceTitle: "Summer Sale"},
+			}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/relations/incoming/partner/"+targetId.String(), nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"source_title":"Summer Sale"`)
+		})
+
+		t.Run("failed to get incoming relations: database error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetIncomingRelations", enums.PageTypePartner, targetId).Return(nil, errs.ErrInternalServerError)
+
+			req := httptest.NewRequest("GET", "/cms/relations/incoming/partner/"+targetId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		})
+	})
+}
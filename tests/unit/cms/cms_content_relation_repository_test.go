@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCMSRepo_ContentRelation(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsContentRelationRepo := repo.NewCMSContentRelationRepository(gormDB)
+
+	pageId := uuid.New()
+	contentId := uuid.New()
+	targetId := uuid.New()
+
+	t.Run("successfully find the source of the relations", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT page_id, language, mode, workflow_status FROM "landing_contents" WHERE id = $1 LIMIT $2`)).
+			WithArgs(contentId, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"page_id", "language", "mode", "workflow_status"}).
+				AddRow(pageId, enums.PageLanguageEN, enums.PageModeDraft, enums.WorkflowDraft))
+
+		source, err := cmsContentRelationRepo.FindRelationSource(enums.PageTypeLanding, contentId)
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.RelationSource{PageID: pageId, Language: enums.PageLanguageEN, Mode: enums.PageModeDraft, WorkflowStatus: enums.WorkflowDraft}, source)
+	})
+
+	t.Run("successfully find the relations of a content in order", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "content_relations" WHERE source_content_id = $1 ORDER BY relation_type, position`)).
+			WithArgs(contentId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "source_page_type", "source_page_id", "source_content_id", "language", "relation_type", "target_page_type", "target_page_id", "position"}).
+				AddRow(uuid.New(), enums.PageTypeLanding, pageId, contentId, enums.PageLanguageEN, enums.RelationRelatedArticle, enums.PageTypePartner, targetId, 0))
+
+		relations, err := cmsContentRelationRepo.FindRelations(contentId)
+		assert.NoError(t, err)
+		assert.Len(t, relations, 1)
+		assert.Equal(t, targetId, relations[0].TargetPageID)
+	})
+
+	t.Run("successfully replace the relations of a content", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(contentId).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "content_relations" ("source_page_type","source_page_id","source_content_id","language","relation_type","target_page_type","target_page_id","position","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "id"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectCommit()
+
+		err := cmsContentRelationRepo.ReplaceRelations(contentId, []*models.ContentRelation{{
+			SourcePageType:  enums.PageTypeLanding,
+			SourcePageID:    pageId,
+			SourceContentID: contentId,
+			Language:        enums.PageLanguageEN,
+			RelationType:    enums.RelationRelatedArticle,
+			TargetPageType:  enums.PageTypePartner,
+			TargetPageID:    targetId,
+		}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully clear the relations of a content", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations"`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := cmsContentRelationRepo.ReplaceRelations(contentId, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully find the incoming relations with their source titles", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT content_relations.*, CASE content_relations.source_page_type WHEN $1 THEN (SELECT landing_contents.title FROM landing_contents WHERE landing_contents.page_id = content_relations.source_page_id AND landing_contents.language = content_relations.language`) + `.*` +
+			regexp.QuoteMeta(`WHERE (content_relations.target_page_type = $10 AND content_relations.target_page_id = $11) AND (((content_relations.source_page_type = $12 AND EXISTS (SELECT 1 FROM landing_contents WHERE landing_contents.id = content_relations.source_content_id AND landing_contents.mode NOT IN ($13,$14)))`) + `.*` +
+			regexp.QuoteMeta(`EXISTS (SELECT 1 FROM faq_contents WHERE faq_contents.id = content_relations.source_content_id AND faq_contents.mode NOT IN ($19,$20)))))`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "source_page_type", "source_page_id", "language", "relation_type", "target_page_type", "target_page_id", "source_title"}).
+				AddRow(uuid.New(), enums.PageTypeLanding, pageId, enums.PageLanguageEN, enums.RelationRelatedArticle, enums.PageTypePartner, targetId, "Summer Sale"))
+
+		relations, err := cmsContentRelationRepo.FindIncomingRelations(enums.PageTypePartner, targetId)
+		assert.NoError(t, err)
+		assert.Len(t, relations, 1)
+		assert.Equal(t, "Summer Sale", relations[0].SourceTitle)
+		assert.Equal(t, pageId, relations[0].SourcePageID)
+	})
+
+	t.Run("successfully check a page exists", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "partner_pages" WHERE id = $1`)).
+			WithArgs(targetId).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		exists, err := cmsContentRelationRepo.PageExists(enums.PageTypePartner, targetId)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("successfully find the published targets with the partner thumbnail", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT partner_contents.page_id, partner_contents.title, partner_contents.url AS path, COALESCE(COALESCE(NULLIF(partner_contents.thumbnail_image, ''), meta_tags.cover_image), '') AS thumbnail FROM "partner_contents" LEFT JOIN meta_tags ON meta_tags.id = partner_contents.meta_tag_id WHERE partner_contents.page_id IN ($1) AND partner_contents.language = $2 AND partner_contents.workflow_status = $3 AND partner_contents.mode NOT IN ($4,$5) ORDER BY partner_contents.updated_at DESC`)).
+			WithArgs(targetId, enums.PageLanguageEN, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview).
+			WillReturnRows(sqlmock.NewRows([]string{"page_id", "title", "path", "thumbnail"}).
+				AddRow(targetId, "Acme", "partners/acme", "acme.png"))
+
+		targets, err := cmsContentRelationRepo.FindPublishedTargets(enums.PageTypePartner, enums.PageLanguageEN, []uuid.UUID{targetId})
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.RelationTarget{{PageID: targetId, Title: "Acme", Path: "partners/acme", Thumbnail: "acme.png"}}, targets)
+	})
+
+	t.Run("failed to find published targets: invalid page type", func(t *testing.T) {
+		targets, err := cmsContentRelationRepo.FindPublishedTargets(enums.PageType("blog"), enums.PageLanguageEN, []uuid.UUID{targetId})
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+		assert.Nil(t, targets)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
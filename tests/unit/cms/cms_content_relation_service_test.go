@@ -0,0 +1,316 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type MockCMSContentRelationRepo struct {
+	findRelationSource    func(pageType enums.PageType, contentId uuid.UUID) (*dto.RelationSource, error)
+	findRelations         func(contentId uuid.UUID) ([]models.ContentRelation, error)
+	replaceRelations      func(contentId uuid.UUID, relations []*models.ContentRelation) error
+	findIncomingRelations func(pageType enums.PageType, pageId uuid.UUID) ([]dto.IncomingRelation, error)
+	pageExists            func(pageType enums.PageType, pageId uuid.UUID) (bool, error)
+	findPublishedTargets  func(pageType enums.PageType, language enums.PageLanguage, pageIds []uuid.UUID) ([]dto.RelationTarget, error)
+	findContentScopes     func(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+func (m *MockCMSContentRelationRepo) FindRelationSource(pageType enums.PageType, contentId uuid.UUID) (*dto.RelationSource, error) {
+	return m.findRelationSource(pageType, contentId)
+}
+
+func (m *MockCMSContentRelationRepo) FindRelations(contentId uuid.UUID) ([]models.ContentRelation, error) {
+	return m.findRelations(contentId)
+}
+
+func (m *MockCMSContentRelationRepo) ReplaceRelations(contentId uuid.UUID, relations []*models.ContentRelation) error {
+	return m.replaceRelations(contentId, relations)
+}
+
+func (m *MockCMSContentRelationRepo) FindIncomingRelations(pageType enums.PageType, pageId uuid.UUID) ([]dto.IncomingRelation, error) {
+	return m.findIncomingRelations(pageType, pageId)
+}
+
+func (m *MockCMSContentRelationRepo) PageExists(pageType enums.PageType, pageId uuid.UUID) (bool, error) {
+	return m.pageExists(pageType, pageId)
+}
+
+func (m *MockCMSContentRelationRepo) FindPublishedTargets(pageType enums.PageType, language enums.PageLanguage, pageIds []uuid.UUID) ([]dto.RelationTarget, error) {
+	return m.findPublishedTargets(pageType, language, pageIds)
+}
+
+func (m *MockCMSContentRelationRepo) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageType, pageId)
+}
+
+func TestCMSContentRelationService_ReplaceRelations(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{WebBaseURL: "https://example.com"}}
+	pageId := uuid.New()
+	contentId := uuid.New()
+	partnerId := uuid.New()
+	faqId := uuid.New()
+
+	draftSource := func(pageType enums.PageType, id uuid.UUID) (*dto.RelationSource, error) {
+		assert.Equal(t, contentId, id)
+		return &dto.RelationSource{PageID: pageId, Language: enums.PageLanguageEN, Mode: enums.PageModeDraft, WorkflowStatus: enums.WorkflowDraft}, nil
+	}
+
+	request := dto.ReplaceContentRelationsRequest{Relations: []dto.ContentRelationRequest{
+		{RelationType: enums.RelationRelatedArticle, TargetPageType: enums.PageTypePartner, TargetPageID: partnerId.String()},
+		{RelationType: enums.RelationRelatedLink, TargetPageType: enums.PageTypeFaq, TargetPageID: faqId.String()},
+		{RelationType: enums.RelationRelatedArticle, TargetPageType: enums.PageTypeFaq, TargetPageID: faqId.String()},
+	}}
+
+	t.Run("successfully replace relations with positions per relation type", func(t *testing.T) {
+		var replaced []*models.ContentRelation
+		repo := &MockCMSContentRelationRepo{
+			findRelationSource: draftSource,
+			pageExists: func(pageType enums.PageType, id uuid.UUID) (bool, error) {
+				return true, nil
+			},
+			replaceRelations: func(id uuid.UUID, relations []*models.ContentRelation) error {
+				assert.Equal(t, contentId, id)
+				replaced = relations
+				return nil
+			},
+			findRelations: func(id uuid.UUID) ([]models.ContentRelation, error) {
+				return []models.ContentRelation{}, nil
+			},
+		}
+		service := services.NewCMSContentRelationService(repo, cfg)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, request)
+		assert.NoError(t, err)
+		assert.Len(t, replaced, 3)
+		assert.Equal(t, 0, replaced[0].Position)
+		assert.Equal(t, 0, replaced[1].Position)
+		assert.Equal(t, 1, replaced[2].Position)
+		assert.Equal(t, enums.PageTypeLanding, replaced[2].SourcePageType)
+		assert.Equal(t, pageId, replaced[2].SourcePageID)
+		assert.Equal(t, contentId, replaced[2].SourceContentID)
+		assert.Equal(t, enums.PageLanguageEN, replaced[2].Language)
+	})
+
+	t.Run("failed to replace relations: page relates to itself", func(t *testing.T) {
+		service := services.NewCMSContentRelationService(&MockCMSContentRelationRepo{findRelationSource: draftSource}, cfg)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, dto.ReplaceContentRelationsRequest{Relations: []dto.ContentRelationRequest{
+			{RelationType: enums.RelationRelatedArticle, TargetPageType: enums.PageTypeLanding, TargetPageID: pageId.String()},
+		}})
+		assert.ErrorIs(t, err, errs.ErrSelfRelation)
+	})
+
+	t.Run("failed to replace relations: page listed twice", func(t *testing.T) {
+		service := services.NewCMSContentRelationService(&MockCMSContentRelationRepo{findRelationSource: draftSource}, cfg)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, dto.ReplaceContentRelationsRequest{Relations: []dto.ContentRelationRequest{
+			request.Relations[0], request.Relations[0],
+		}})
+		assert.ErrorIs(t, err, errs.ErrDuplicateRelation)
+	})
+
+	t.Run("failed to replace relations: invalid relation type", func(t *testing.T) {
+		service := services.NewCMSContentRelationService(&MockCMSContentRelationRepo{findRelationSource: draftSource}, cfg)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, dto.ReplaceContentRelationsRequest{Relations: []dto.ContentRelationRequest{
+			{RelationType: "sibling", TargetPageType: enums.PageTypePartner, TargetPageID: partnerId.String()},
+		}})
+		assert.ErrorIs(t, err, errs.ErrInvalidRelation)
+	})
+
+	t.Run("failed to replace relations: too many of one relation type", func(t *testing.T) {
+		service := services.NewCMSContentRelationService(&MockCMSContentRelationRepo{findRelationSource: draftSource}, cfg)
+
+		many := dto.ReplaceContentRelationsRequest{}
+		for i := 0; i < 21; i++ {
+			many.Relations = append(many.Relations, dto.ContentRelationRequest{RelationType: enums.RelationRelatedLink, TargetPageType: enums.PageTypeFaq, TargetPageID: uuid.NewString()})
+		}
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, many)
+		assert.ErrorIs(t, err, errs.ErrTooManyRelations)
+	})
+
+	t.Run("failed to replace relations: content not found", func(t *testing.T) {
+		repo := &MockCMSContentRelationRepo{
+			findRelationSource: func(pageType enums.PageType, id uuid.UUID) (*dto.RelationSource, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+		service := services.NewCMSContentRelationService(repo, cfg)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, request)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("failed to replace relations: published content", func(t *testing.T) {
+		repo := &MockCMSContentRelationRepo{
+			findRelationSource: func(pageType enums.PageType, id uuid.UUID) (*dto.RelationSource, error) {
+				return &dto.RelationSource{PageID: pageId, Language: enums.PageLanguageEN, Mode: enums.PageModePublished, WorkflowStatus: enums.WorkflowPublished}, nil
+			},
+		}
+		service := services.NewCMSContentRelationService(repo, cfg)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, request)
+		assert.ErrorIs(t, err, errs.ErrRelationsLocked)
+	})
+
+	t.Run("failed to replace relations: history content", func(t *testing.T) {
+		repo := &MockCMSContentRelationRepo{
+			findRelationSource: func(pageType enums.PageType, id uuid.UUID) (*dto.RelationSource, error) {
+				return &dto.RelationSource{PageID: pageId, Language: enums.PageLanguageEN, Mode: enums.PageModeHistories, WorkflowStatus: enums.WorkflowDraft}, nil
+			},
+		}
+		service := services.NewCMSContentRelationService(repo, cfg)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, request)
+		assert.ErrorIs(t, err, errs.ErrRelationsLocked)
+	})
+
+	t.Run("failed to replace relations: target page not found", func(t *testing.T) {
+		repo := &MockCMSContentRelationRepo{
+			findRelationSource: draftSource,
+			pageExists: func(pageType enums.PageType, id uuid.UUID) (bool, error) {
+				return id == faqId, nil
+			},
+		}
+		service := services.NewCMSContentRelationService(repo, cfg)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, contentId, request)
+		assert.ErrorIs(t, err, errs.ErrRelationTargetNotFound)
+	})
+
+	t.Run("failed to replace relations: invalid page type", func(t *testing.T) {
+		service := services.NewCMSContentRelationService(&MockCMSContentRelationRepo{}, cfg)
+
+		_, err := service.ReplaceRelations("blog", contentId, request)
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+	})
+}
+
+func TestCMSContentRelationService_PageAccess(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{WebBaseURL: "https://example.com"}}
+	pageId := uuid.New()
+	englishId := uuid.New()
+	thaiId := uuid.New()
+	english := enums.PageLanguageEN
+
+	// The user may only touch english landing pages
+	access := &dto.PageAccess{Grants: []models.PageGrant{
+		{PageType: enums.PageTypeLanding, Action: enums.PermissionActionAll, Language: &english},
+	}}
+	newRepo := func() *MockCMSContentRelationRepo {
+		return &MockCMSContentRelationRepo{
+			findRelationSource: func(pageType enums.PageType, contentId uuid.UUID) (*dto.RelationSource, error) {
+				return &dto.RelationSource{PageID: pageId, Mode: enums.PageModeDraft, WorkflowStatus: enums.WorkflowDraft}, nil
+			},
+			findContentScopes: func(pageType enums.PageType, id uuid.UUID) ([]dto.PageContentScope, error) {
+				assert.Equal(t, enums.PageTypeLanding, pageType)
+				assert.Equal(t, pageId, id)
+				return []dto.PageContentScope{
+					{ContentID: englishId, Language: enums.PageLanguageEN, Mode: enums.PageModeDraft},
+					{ContentID: thaiId, Language: enums.PageLanguageTH, Mode: enums.PageModeDraft},
+				}, nil
+			},
+			findRelations: func(contentId uuid.UUID) ([]models.ContentRelation, error) {
+				return []models.ContentRelation{}, nil
+			},
+		}
+	}
+
+	t.Run("successfully get relations of a content the grants match", func(t *testing.T) {
+		service := services.NewCMSContentRelationService(newRepo(), cfg).WithPageAccess(access)
+
+		_, err := service.GetRelations(enums.PageTypeLanding, englishId)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed to replace relations of a content outside the grants: access denied", func(t *testing.T) {
+		service := services.NewCMSContentRelationService(newRepo(), cfg).WithPageAccess(access)
+
+		_, err := service.ReplaceRelations(enums.PageTypeLanding, thaiId, dto.ReplaceContentRelationsRequest{})
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+
+	t.Run("failed to get incoming relations without a page access: access denied", func(t *testing.T) {
+		service := services.NewCMSContentRelationService(newRepo(), cfg).WithPageAccess(nil)
+
+		_, err := service.GetIncomingRelations(enums.PageTypeLanding, pageId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+}
+
+func TestCMSContentRelationService_ResolveRelations(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{WebBaseURL: "https://example.com"}}
+	contentId := uuid.New()
+	partnerId := uuid.New()
+	unpublishedId := uuid.New()
+	faqId := uuid.New()
+
+	t.Run("successfully resolve the published targets in order and drop the unpublished ones", func(t *testing.T) {
+		repo := &MockCMSContentRelationRepo{
+			findRelations: func(id uuid.UUID) ([]models.ContentRelation, error) {
+				return []models.ContentRelation{
+					{RelationType: enums.RelationRelatedArticle, TargetPageType: enums.PageTypePartner, TargetPageID: partnerId, Position: 0},
+					{RelationType: enums.RelationRelatedArticle, TargetPageType: enums.PageTypePartner, TargetPageID: unpublishedId, Position: 1},
+					{RelationType: enums.RelationRelatedLink, TargetPageType: enums.PageTypeFaq, TargetPageID: faqId, Position: 0},
+				}, nil
+			},
+			findPublishedTargets: func(pageType enums.PageType, language enums.PageLanguage, pageIds []uuid.UUID) ([]dto.RelationTarget, error) {
+				assert.Equal(t, enums.PageLanguageTH, language)
+				switch pageType {
+				case enums.PageTypePartner:
+					assert.ElementsMatch(t, []uuid.UUID{partnerId, unpublishedId}, pageIds)
+					return []dto.RelationTarget{
+						{PageID: partnerId, Title: "Acme renamed", Path: "partners/acme", Thumbnail: "acme.png"},
+						{PageID: partnerId, Title: "Acme", Path: "partners/acme-old"},
+					}, nil
+				default:
+					return []dto.RelationTarget{{PageID: faqId, Title: "How to apply", Path: "faq/apply"}}, nil
+				}
+			},
+		}
+		service := services.NewCMSContentRelationService(repo, cfg)
+
+		related, err := service.ResolveRelations(contentId, enums.PageLanguageTH)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.RelatedPage{
+			{RelationType: enums.RelationRelatedArticle, PageType: enums.PageTypePartner, PageID: partnerId, Title: "Acme renamed", URL: "https://example.com/th/partners/acme", Thumbnail: "acme.png"},
+			{RelationType: enums.RelationRelatedLink, PageType: enums.PageTypeFaq, PageID: faqId, Title: "How to apply", URL: "https://example.com/th/faq/apply"},
+		}, related)
+	})
+
+	t.Run("successfully resolve a page without relations", func(t *testing.T) {
+		repo := &MockCMSContentRelationRepo{
+			findRelations: func(id uuid.UUID) ([]models.ContentRelation, error) {
+				return []models.ContentRelation{}, nil
+			},
+		}
+		service := services.NewCMSContentRelationService(repo, cfg)
+
+		related, err := service.ResolveRelations(contentId, enums.PageLanguageEN)
+		assert.NoError(t, err)
+		assert.Empty(t, related)
+	})
+
+	t.Run("failed to resolve relations: database error", func(t *testing.T) {
+		repo := &MockCMSContentRelationRepo{
+			findRelations: func(id uuid.UUID) ([]models.ContentRelation, error) {
+				return nil, errs.ErrInternalServerError
+			},
+		}
+		service := services.NewCMSContentRelationService(repo, cfg)
+
+		related, err := service.ResolveRelations(contentId, enums.PageLanguageEN)
+		assert.ErrorIs(t, err, errs.ErrInternalServerError)
+		assert.Nil(t, related)
+	})
+}
@@ -7,12 +7,15 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -41,6 +44,14 @@ func (m *MockCMSFaqPageService) FindFaqPages(rawQuery string, sort string, page,
 	return args.Get(0).([]models.FaqPage), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockCMSFaqPageService) FindFaqPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.FaqPage, *dto.CursorPage, error) {
+	args := m.Called(rawQuery, sort, cursorQuery, language)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]models.FaqPage), args.Get(1).(*dto.CursorPage), args.Error(2)
+}
+
 func (m *MockCMSFaqPageService) FindFaqPageById(id uuid.UUID) (*models.FaqPage, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -57,8 +68,8 @@ func (m *MockCMSFaqPageService) UpdateFaqContent(updatedFaqContent *models.FaqCo
 	return args.Get(0).(*models.FaqContent), args.Error(1)
 }
 
-func (m *MockCMSFaqPageService) DeleteFaqPage(id uuid.UUID) error {
-	args := m.Called(id)	
+func (m *MockCMSFaqPageService) DeleteFaqPage(id uuid.UUID, force bool) error {
+	args := m.Called(id, force)	
 	return args.Error(0)
 }
 
@@ -123,17 +134,24 @@ func (m *MockCMSFaqPageService) FindRevisions(pageId uuid.UUID, language string)
 	return args.Get(0).([]models.Revision), args.Error(1)
 }
 
-func (m *MockCMSFaqPageService) PreviewFaqContent(pageId uuid.UUID, faqContentPreview *models.FaqContent) (string, error) {
+func (m *MockCMSFaqPageService) PreviewFaqContent(pageId uuid.UUID, faqContentPreview *models.FaqContent) (*models.FaqContent, error) {
 	args := m.Called(pageId, faqContentPreview)
 	if args.Get(0) == nil {
-		return "", args.Error(1)
-	}	
-	return args.Get(0).(string), args.Error(1)
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FaqContent), args.Error(1)
+}
+
+func (m *MockCMSFaqPageService) WithPageAccess(access *dto.PageAccess) services.CMSFaqPageServiceInterface {
+	return m
 }
 
 func TestCMSFaqHandler(t *testing.T) {
 	mockService := &MockCMSFaqPageService{}
-	handler := cmsHandler.NewCMSFaqPageHandler(mockService)
+	mockPreviewLinkService := &MockCMSPreviewLinkService{}
+	mockUsageService := &MockCMSUsageService{}
+	mockUsageService.On("CheckDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	handler := cmsHandler.NewCMSFaqPageHandler(mockService, mockPreviewLinkService, mockUsageService)
 
 	app := fiber.New()
 	app.Post("/cms/faqpages", handler.HandleCreateFaqPage)
@@ -195,6 +213,19 @@ func TestCMSFaqHandler(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
 			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to create faq page: critical audit findings", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CreateFaqPage", mock.AnythingOfType("*models.FaqPage")).Return(nil, errs.ErrCriticalAuditFindings)
+
+			req := httptest.NewRequest("POST", "/cms/faqpages", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+			mockService.AssertExpectations(t)
 		})			
 	})
 
@@ -304,7 +335,7 @@ func TestCMSFaqHandler(t *testing.T) {
 		pageId := uuid.New()
 
 		t.Run("successfully delete faq page", func(t *testing.T)	{
-			mockService.On("DeleteFaqPage", pageId).Return(nil)
+			mockService.On("DeleteFaqPage", pageId, false).Return(nil)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/faqpages/%s", pageId), nil)
 			
@@ -316,7 +347,7 @@ func TestCMSFaqHandler(t *testing.T) {
 		
 		t.Run("failed to delete faq page: invalid pageId", func(t *testing.T)	{
 			mockService.ExpectedCalls = nil
-			mockService.On("DeleteFaqPage", pageId).Return(nil)
+			mockService.On("DeleteFaqPage", pageId, false).Return(nil)
 			invalidPageId := "1"
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/faqpages/%s", invalidPageId), nil)
@@ -329,7 +360,7 @@ func TestCMSFaqHandler(t *testing.T) {
 		
 		t.Run("failed to delete faq page: internal server error", func(t *testing.T)	{
 			mockService.ExpectedCalls = nil
-			mockService.On("DeleteFaqPage", pageId).Return(errs.ErrInternalServerError)
+			mockService.On("DeleteFaqPage", pageId, false).Return(errs.ErrInternalServerError)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/faqpages/%s", pageId), nil)
 			
@@ -806,65 +837,119 @@ func TestCMSFaqHandler(t *testing.T) {
 		mockFaqPage := helpers.InitializeMockFaqPage()
 		mockContent := mockFaqPage.Contents[0]
 		pageId := uuid.New()
-		url := "fake url"
+		savedContent := &models.FaqContent{ID: uuid.New(), Language: enums.PageLanguageEN}
+		previewLink := &dto.PreviewLinkResponse{ID: uuid.New().String(), URL: "https://www.example.com/preview/en/faq?token=signed"}
 
 		body, err := json.Marshal(mockContent)
-		require.NoError(t, err)				
-		
-		t.Run("successfully preview faq content", func(t *testing.T)	{
-			mockService.On("PreviewFaqContent", pageId, mock.AnythingOfType("*models.FaqContent")).Return(url, nil)				
+		require.NoError(t, err)
 
-			req := httptest.NewRequest("POST", fmt.Sprintf("/cms/faqpages/previews/%s", pageId), bytes.NewReader(body))
+		t.Run("successfully preview faq content", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockPreviewLinkService.ExpectedCalls = nil
+			mockPreviewLinkService.On("ParsePreviewTTL", "30m").Return(30*time.Minute, nil)
+			mockService.On("PreviewFaqContent", pageId, mock.AnythingOfType("*models.FaqContent")).Return(savedContent, nil)
+			mockPreviewLinkService.On("IssuePreviewLink", dto.IssuePreviewLinkRequest{
+				PageType:  enums.PageTypeFaq,
+				PageID:    pageId,
+				ContentID: savedContent.ID,
+				Language:  enums.PageLanguageEN,
+				TTL:       30 * time.Minute,
+				Password:  "secret",
+			}).Return(previewLink, nil)
+
+			req := httptest.NewRequest("POST", fmt.Sprintf("/cms/faqpages/previews/%s?ttl=30m", pageId), bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
-			
+			req.Header.Set("X-Preview-Password", "secret")
+
 			resp, err := app.Test(req)
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
-			mockService.AssertExpectations(t)				
-		})	
-		
-		t.Run("failed to preview faq content: invalid pageId", func(t *testing.T)	{
+
+			var respBody map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+			assert.Equal(t, previewLink.URL, respBody["url"])
+			mockService.AssertExpectations(t)
+			mockPreviewLinkService.AssertExpectations(t)
+		})
+
+		t.Run("failed to preview faq content: invalid pageId", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("PreviewFaqContent", pageId, mock.AnythingOfType("*models.FaqContent")).Return("", errs.ErrInternalServerError)		
-			
-			invalidPageId := "1"	
+			mockService.Calls = nil
+			mockPreviewLinkService.ExpectedCalls = nil
+
+			invalidPageId := "1"
 
 			req := httptest.NewRequest("POST", fmt.Sprintf("/cms/faqpages/previews/%s", invalidPageId), bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
-			
+
 			resp, err := app.Test(req)
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
-			mockService.AssertExpectations(t)				
-		})			
+			mockService.AssertNotCalled(t, "PreviewFaqContent", mock.Anything, mock.Anything)
+		})
 
-		t.Run("failed to preview faq content: invalid body", func(t *testing.T)	{
+		t.Run("failed to preview faq content: invalid ttl", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("PreviewFaqContent", pageId, mock.AnythingOfType("*models.FaqContent")).Return("", errs.ErrInternalServerError)		
-			
+			mockService.Calls = nil
+			mockPreviewLinkService.ExpectedCalls = nil
+			mockPreviewLinkService.On("ParsePreviewTTL", "forever").Return(time.Duration(0), errs.ErrInvalidPreviewTTL)
+
+			req := httptest.NewRequest("POST", fmt.Sprintf("/cms/faqpages/previews/%s?ttl=forever", pageId), bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "PreviewFaqContent", mock.Anything, mock.Anything)
+		})
+
+		t.Run("failed to preview faq content: invalid body", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			mockPreviewLinkService.ExpectedCalls = nil
+			mockPreviewLinkService.On("ParsePreviewTTL", "").Return(2*time.Hour, nil)
+
 			body, err := json.Marshal("invalid body")
-			require.NoError(t, err)				
+			require.NoError(t, err)
 
 			req := httptest.NewRequest("POST", fmt.Sprintf("/cms/faqpages/previews/%s", pageId), bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
-			
+
 			resp, err := app.Test(req)
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
-			mockService.AssertExpectations(t)				
-		})			
+			mockService.AssertNotCalled(t, "PreviewFaqContent", mock.Anything, mock.Anything)
+		})
 
-		t.Run("failed to preview faq content: internal server error", func(t *testing.T)	{
+		t.Run("failed to preview faq content: internal server error", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("PreviewFaqContent", pageId, mock.AnythingOfType("*models.FaqContent")).Return("", errs.ErrInternalServerError)				
+			mockPreviewLinkService.ExpectedCalls = nil
+			mockPreviewLinkService.On("ParsePreviewTTL", "").Return(2*time.Hour, nil)
+			mockService.On("PreviewFaqContent", pageId, mock.AnythingOfType("*models.FaqContent")).Return(nil, errs.ErrInternalServerError)
 
 			req := httptest.NewRequest("POST", fmt.Sprintf("/cms/faqpages/previews/%s", pageId), bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
-			
+
 			resp, err := app.Test(req)
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
-			mockService.AssertExpectations(t)				
-		})		
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to preview faq content: preview link not issued", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockPreviewLinkService.ExpectedCalls = nil
+			mockPreviewLinkService.On("ParsePreviewTTL", "").Return(2*time.Hour, nil)
+			mockService.On("PreviewFaqContent", pageId, mock.AnythingOfType("*models.FaqContent")).Return(savedContent, nil)
+			mockPreviewLinkService.On("IssuePreviewLink", mock.AnythingOfType("dto.IssuePreviewLinkRequest")).Return(nil, errs.ErrPreviewFrontendURLMissing)
+
+			req := httptest.NewRequest("POST", fmt.Sprintf("/cms/faqpages/previews/%s", pageId), bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+			mockPreviewLinkService.AssertExpectations(t)
+		})
 	})
-}
\ No newline at end of file
+}
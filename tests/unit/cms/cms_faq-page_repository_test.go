@@ -85,6 +85,8 @@ func TestCMSRepo_CreateFaqPage(t *testing.T) {
 					AddRow(uuid.New(), uuid.New()), // match what's RETURNED
 			)
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		faqPage, err := cmsFaqPageRepo.CreateFaqPage(mockFaqPage)
@@ -404,10 +406,16 @@ func TestCMSRepo_UpdateFaqContent(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"faq_content_id", "category_id"}).
 				AddRow(faqContentId, categoryId))			
 				
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "faq_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))						
 
 		// Expect commit
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()		
 
 		updatedFaqContent, err := cmsFaqPageRepo.UpdateFaqContent(mockFaqContent, prevContentId)
@@ -458,6 +466,10 @@ func TestCMSRepo_DeleteFaqPage(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 				AddRow(pageId, now, now))
 
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemFaqPage, pageId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "faq_contents"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "title"}).
 				AddRow(contentId, pageId, contentTitle))		
@@ -477,9 +489,12 @@ func TestCMSRepo_DeleteFaqPage(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "faq_pages"`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))					
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
-		err := cmsFaqPageRepo.DeleteFaqPage(pageId)
+		err := cmsFaqPageRepo.DeleteFaqPage(pageId, false)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -494,7 +509,7 @@ func TestCMSRepo_DeleteFaqPage(t *testing.T) {
 
 		mock.ExpectRollback()
 
-		err := cmsFaqPageRepo.DeleteFaqPage(pageId)
+		err := cmsFaqPageRepo.DeleteFaqPage(pageId, false)
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})	
@@ -644,6 +659,9 @@ func TestCMSRepo_DeleteFaqContent(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "faq_contents"`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))			
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		err := cmsFaqPageRepo.DeleteFaqContent(pageId, language, mode)
@@ -791,6 +809,9 @@ func TestCMSRepo_DuplicateFaqPage(t *testing.T) {
 					AddRow(newContentId, categoryId),
 			)				
 			
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		mock.ExpectCommit()
 
 		faqPage, err := cmsFaqPageRepo.DuplicateFaqPage(pageId)
@@ -883,6 +904,12 @@ func TestCMSRepo_DuplicateFaqContentToAnotherLanguage(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"faq_content_id", "category_id"}).
 				AddRow(newContentId, newCategoryId))						
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		faqContent, err := cmsFaqPageRepo.DuplicateFaqContentToAnotherLanguage(oldContentId, newRevision)
@@ -989,6 +1016,12 @@ func TestCMSRepo_RevertFaqContent(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"faq_content_id", "category_id"}).
 				AddRow(newContentId, newCategoryId))					
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		faqContent, err := cmsFaqPageRepo.RevertFaqContent(oldRevisionId, newRevision)
@@ -1306,6 +1339,13 @@ func TestCMSRepo_CreateFaqContentPreview(t *testing.T) {
 					AddRow(componentId), 
 			)				
 
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(contentId).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		mock.ExpectCommit()
 
 		actualFaqContent, err := cmsFaqPageRepo.CreateFaqContentPreview(mockFaqContent)
@@ -1368,6 +1408,13 @@ func TestCMSRepo_UpdateFaqContentPreview(t *testing.T) {
 					AddRow(componentId), 
 			)			
 
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "content_relations" WHERE source_content_id = $1`)).
+			WithArgs(contentId).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		mock.ExpectCommit()
 
 		actualFaqContent, err := cmsFaqPageRepo.UpdateFaqContentPreview(mockFaqContent)
@@ -1,8 +1,8 @@
 package tests
 
 import (
-	"strings"
 	"testing"
+	"time"
 
 	"github.com/MadManJJ/cms-api/config"
 	"github.com/MadManJJ/cms-api/dto"
@@ -20,9 +20,10 @@ import (
 type MockCMSFaqPageRepo struct {
 	createFaqPage                         func(faqPage *models.FaqPage) (*models.FaqPage, error)
 	findAllFaqPage                        func(query dto.FaqPageQuery, sort string, page, limit int, language string) ([]models.FaqPage, int64, error)
+	findFaqPagesByCursor                  func(query dto.FaqPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.FaqPage, *dto.CursorPage, error)
 	findFaqPageById                       func(id uuid.UUID) (*models.FaqPage, error)
 	updateFaqContent                      func(updateFaqContent *models.FaqContent, prevContentId uuid.UUID) (*models.FaqContent, error)
-	deleteFaqPage                         func(id uuid.UUID) error
+	deleteFaqPage                         func(id uuid.UUID, force bool) error
 	findContentByFaqPageId                func(pageId uuid.UUID, language string, mode string) (*models.FaqContent, error)
 	findLatestContentByPageId             func(pageId uuid.UUID, language string) (*models.FaqContent, error)
 	createContentForFaqPage               func(faqContent *models.FaqContent, lang string, mode string) (*models.FaqContent, error) // Deprecated
@@ -35,6 +36,9 @@ type MockCMSFaqPageRepo struct {
 	isUrlDuplicate                        func(url string, pageId uuid.UUID) (bool, error)
 	isUrlAliasDuplicate                   func(urlAlias string, pageId uuid.UUID) (bool, error)
 	getPageIdByContentId                  func(contentId uuid.UUID) (uuid.UUID, error)
+	getContentRefByRevisionId             func(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error)
+	findContentById func(contentId uuid.UUID) (*models.FaqContent, error)
+	findContentScopes                     func(pageId uuid.UUID) ([]dto.PageContentScope, error)
 	createFaqContentPreview               func(faqContentPreview *models.FaqContent) (*models.FaqContent, error)
 	updateFaqContentPreview               func(faqContentPreview *models.FaqContent) (*models.FaqContent, error)
 	findFaqContentPreviewById             func(pageId uuid.UUID, language string) (*models.FaqContent, error)
@@ -49,6 +53,10 @@ func (m *MockCMSFaqPageRepo) FindAllFaqPage(query dto.FaqPageQuery, sort string,
 	return m.findAllFaqPage(query, sort, page, limit, language)
 }
 
+func (m *MockCMSFaqPageRepo) FindFaqPagesByCursor(query dto.FaqPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.FaqPage, *dto.CursorPage, error) {
+	return m.findFaqPagesByCursor(query, sort, cursorQuery, language)
+}
+
 func (m *MockCMSFaqPageRepo) FindFaqPageById(id uuid.UUID) (*models.FaqPage, error) {
 	return m.findFaqPageById(id)
 }
@@ -57,8 +65,8 @@ func (m *MockCMSFaqPageRepo) UpdateFaqContent(updateFaqContent *models.FaqConten
 	return m.updateFaqContent(updateFaqContent, prevContentId)
 }
 
-func (m *MockCMSFaqPageRepo) DeleteFaqPage(id uuid.UUID) error {
-	return m.deleteFaqPage(id)
+func (m *MockCMSFaqPageRepo) DeleteFaqPage(id uuid.UUID, force bool) error {
+	return m.deleteFaqPage(id, force)
 }
 
 func (m *MockCMSFaqPageRepo) FindContentByFaqPageId(pageId uuid.UUID, language string, mode string) (*models.FaqContent, error) {
@@ -109,6 +117,18 @@ func (m *MockCMSFaqPageRepo) GetPageIdByContentId(contentId uuid.UUID) (uuid.UUI
 	return m.getPageIdByContentId(contentId)
 }
 
+func (m *MockCMSFaqPageRepo) GetContentRefByRevisionId(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	return m.getContentRefByRevisionId(revisionId)
+}
+
+func (m *MockCMSFaqPageRepo) FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageId)
+}
+
+func (m *MockCMSFaqPageRepo) FindContentById(contentId uuid.UUID) (*models.FaqContent, error) {
+	return m.findContentById(contentId)
+}
+
 func (m *MockCMSFaqPageRepo) CreateFaqContentPreview(faqContentPreview *models.FaqContent) (*models.FaqContent, error) {
 	return m.createFaqContentPreview(faqContentPreview)
 }
@@ -433,14 +453,14 @@ func TestCMSService_DeleteFaqPage(t *testing.T) {
 		pageId := uuid.New()
 
 		repo := &MockCMSFaqPageRepo{
-			deleteFaqPage: func(id uuid.UUID) error {
+			deleteFaqPage: func(id uuid.UUID, force bool) error {
 				return nil
 			},
 		}
 
 		service := services.NewCMSFaqPageService(repo, cfg)
 
-		err := service.DeleteFaqPage(pageId)
+		err := service.DeleteFaqPage(pageId, false)
 		assert.NoError(t, err)
 	})
 	
@@ -449,14 +469,14 @@ func TestCMSService_DeleteFaqPage(t *testing.T) {
 		pageId := uuid.New()
 
 		repo := &MockCMSFaqPageRepo{
-			deleteFaqPage: func(id uuid.UUID) error {
+			deleteFaqPage: func(id uuid.UUID, force bool) error {
 				return errs.ErrInternalServerError
 			},
 		}
 
 		service := services.NewCMSFaqPageService(repo, cfg)
 
-		err := service.DeleteFaqPage(pageId)
+		err := service.DeleteFaqPage(pageId, false)
 		assert.Error(t, err)
 	})		
 }
@@ -821,10 +841,6 @@ func TestCMSService_FindFaqRevisions(t *testing.T) {
 func TestCMSService_PreviewFaqContent(t *testing.T) {
 	cfg := config.New()
 
-	urls := cfg.App.FrontendURLS
-	parts := strings.Split(urls, ",")
-	appUrl := parts[1]	
-
 	t.Run("successfully preview content: create new content", func(t *testing.T) {
 		pageId := uuid.New()
 
@@ -851,18 +867,11 @@ func TestCMSService_PreviewFaqContent(t *testing.T) {
 
 		service := services.NewCMSFaqPageService(repo, cfg)	
 
-		expectPreviewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(mockContent.Language),
-			"faq",
-			createdContent.ID,
-		)
-
-		assert.NoError(t, err)
-
-		previewUrl, err := service.PreviewFaqContent(pageId, mockContent)
+		savedContent, err := service.PreviewFaqContent(pageId, mockContent)
 		assert.NoError(t, err)
-		assert.Equal(t, expectPreviewUrl, previewUrl)
+		assert.Equal(t, createdContent.ID, savedContent.ID)
+		assert.Equal(t, enums.PageModePreview, mockContent.Mode)
+		assert.WithinDuration(t, time.Now().Add(cfg.Preview.MaxTTL), mockContent.ExpiredAt, time.Minute)
 	})	
 
 	t.Run("successfully preview content: update existing content", func(t *testing.T) {
@@ -898,17 +907,8 @@ func TestCMSService_PreviewFaqContent(t *testing.T) {
 
 		service := services.NewCMSFaqPageService(repo, cfg)	
 
-		expectPreviewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(updatedContent.Language),
-			"faq",
-			updatedContent.ID,
-		)
-
-		assert.NoError(t, err)
-
-		previewUrl, err := service.PreviewFaqContent(pageId, mockContent)
+		savedContent, err := service.PreviewFaqContent(pageId, mockContent)
 		assert.NoError(t, err)
-		assert.Equal(t, expectPreviewUrl, previewUrl)
+		assert.Equal(t, updatedContent.ID, savedContent.ID)
 	})		
 }
\ No newline at end of file
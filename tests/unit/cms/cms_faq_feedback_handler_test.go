@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCMSFaqFeedbackService struct {
+	mock.Mock
+}
+
+func (m *MockCMSFaqFeedbackService) RecordFeedback(contentId uuid.UUID, request dto.FaqFeedbackRequest, clientIP, userAgent string) error {
+	args := m.Called(contentId, request, clientIP, userAgent)
+	return args.Error(0)
+}
+
+func (m *MockCMSFaqFeedbackService) GetHelpfulnessSeries(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error) {
+	args := m.Called(query, interval)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.HelpfulnessPoint), args.Error(1)
+}
+
+func (m *MockCMSFaqFeedbackService) GetLowestRated(query dto.FaqFeedbackQuery, minVotes, limit int) ([]dto.FaqHelpfulnessRank, error) {
+	args := m.Called(query, minVotes, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.FaqHelpfulnessRank), args.Error(1)
+}
+
+func (m *MockCMSFaqFeedbackService) GetFeedbackComments(query dto.FaqFeedbackQuery, page, limit int) ([]dto.FaqFeedbackComment, int64, error) {
+	args := m.Called(query, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]dto.FaqFeedbackComment), args.Get(1).(int64), args.Error(2)
+}
+
+func TestCMSFaqFeedbackHandler(t *testing.T) {
+	mockService := &MockCMSFaqFeedbackService{}
+	handler := cmsHandler.NewCMSFaqFeedbackHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/faqfeedback/stats", handler.HandleGetHelpfulnessSeries)
+	app.Get("/cms/faqfeedback/lowest", handler.HandleGetLowestRated)
+	app.Get("/cms/faqfeedback/comments", handler.HandleGetFeedbackComments)
+
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 23, 59, 59, 999999999, time.UTC)
+	pageId := uuid.New()
+
+	t.Run("GET /cms/faqfeedback/stats HandleGetHelpfulnessSeries", func(t *testing.T) {
+		t.Run("successfully get helpfulness", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			query := dto.FaqFeedbackQuery{From: from, To: to, Language: enums.PageLanguageEN, PageID: &pageId}
+			mockService.On("GetHelpfulnessSeries", query, "week").Return([]dto.HelpfulnessPoint{{Period: from, Helpful: 3, NotHelpful: 1, Ratio: 0.75}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/stats?interval=week&from=2025-07-01&to=2025-07-31&language=en&pageId="+pageId.String(), nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"ratio":0.75`)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("successfully export helpfulness as csv", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetHelpfulnessSeries", mock.Anything, "").Return([]dto.HelpfulnessPoint{{Period: from, Helpful: 3, NotHelpful: 1, Ratio: 0.75}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/stats?format=csv", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+			assert.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), "attachment")
+			assert.Equal(t, "period,helpful,not_helpful,ratio\n2025-07-01T00:00:00Z,3,1,0.7500\n", string(body))
+		})
+
+		t.Run("failed to get helpfulness: invalid date", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/stats?from=yesterday", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetHelpfulnessSeries", mock.Anything, mock.Anything)
+		})
+
+		t.Run("failed to get helpfulness: invalid interval", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetHelpfulnessSeries", mock.Anything, "hour").Return(nil, errs.ErrInvalidInterval)
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/stats?interval=hour", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/faqfeedback/lowest HandleGetLowestRated", func(t *testing.T) {
+		t.Run("successfully get lowest rated faqs with defaults", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetLowestRated", mock.Anything, 5, 20).Return([]dto.FaqHelpfulnessRank{{PageID: pageId, Title: "Reset password", Ratio: 0.2}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/lowest", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), "Reset password")
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get lowest rated faqs: database error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetLowestRated", mock.Anything, 2, 50).Return(nil, assert.AnError)
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/lowest?minVotes=2&limit=50", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/faqfeedback/comments HandleGetFeedbackComments", func(t *testing.T) {
+		t.Run("successfully get one page of feedback", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetFeedbackComments", mock.Anything, 2, 10).Return([]dto.FaqFeedbackComment{{Feedback: "Outdated steps"}}, int64(11), nil)
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/comments?page=2&limit=10", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"totalCount":11`)
+			assert.Contains(t, string(body), "Outdated steps")
+		})
+
+		t.Run("successfully escape the feedback in json", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetFeedbackComments", mock.Anything, 1, 20).Return([]dto.FaqFeedbackComment{{Feedback: "<script>alert(1)</script>"}}, int64(1), nil)
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/comments", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.NotContains(t, string(body), "<script>")
+			assert.Contains(t, string(body), `\u003cscript\u003e`)
+		})
+
+		t.Run("successfully export feedback as csv without formulas", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetFeedbackComments", mock.Anything, 1, dto.FeedbackExportMaxRows).
+				Return([]dto.FaqFeedbackComment{{Title: "Reset password", Feedback: "=HYPERLINK(\"x\") & more", UpdatedAt: from}}, int64(1), nil)
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/comments?page=3&format=csv", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+			assert.Len(t, lines, 2)
+			assert.Contains(t, lines[1], `"'=HYPERLINK(""x"") & more"`)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get feedback: invalid pageId", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/cms/faqfeedback/comments?pageId=abc", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetFeedbackComments", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+}
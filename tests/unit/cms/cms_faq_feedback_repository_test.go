@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCMSRepo_FaqFeedback(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsFaqFeedbackRepo := repo.NewCMSFaqFeedbackRepository(gormDB)
+
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+	pageId := uuid.New()
+
+	t.Run("successfully find published faq content", func(t *testing.T) {
+		contentId := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","page_id","language" FROM "faq_contents" WHERE id = $1 AND workflow_status = $2 AND mode NOT IN ($3,$4)`)).
+			WithArgs(contentId, enums.WorkflowPublished, enums.PageModeHistories, enums.PageModePreview, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "language"}).AddRow(contentId, pageId, enums.PageLanguageEN))
+
+		faqContent, err := cmsFaqFeedbackRepo.FindPublishedFaqContent(contentId)
+		assert.NoError(t, err)
+		assert.Equal(t, pageId, faqContent.PageID)
+	})
+
+	t.Run("failed to find faq content: not published", func(t *testing.T) {
+		contentId := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","page_id","language" FROM "faq_contents"`)).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		faqContent, err := cmsFaqFeedbackRepo.FindPublishedFaqContent(contentId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, faqContent)
+	})
+
+	t.Run("successfully replace the vote of the client", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "faq_feedbacks"`) + `.*` +
+			regexp.QuoteMeta(`ON CONFLICT ("page_id","language","client_hash") DO UPDATE SET "faq_content_id"="excluded"."faq_content_id","helpful"="excluded"."helpful","feedback"="excluded"."feedback","updated_at"="excluded"."updated_at"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectCommit()
+
+		err := cmsFaqFeedbackRepo.UpsertFeedback(&models.FaqFeedback{PageID: pageId, Language: enums.PageLanguageEN, ClientHash: "hash", Helpful: true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully count votes per week", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT date_trunc($1, faq_feedbacks.updated_at) AS period, COUNT(*) FILTER (WHERE faq_feedbacks.helpful) AS helpful, COUNT(*) FILTER (WHERE NOT faq_feedbacks.helpful) AS not_helpful FROM "faq_feedbacks" WHERE (faq_feedbacks.updated_at >= $2 AND faq_feedbacks.updated_at <= $3) AND faq_feedbacks.language = $4 GROUP BY "period" ORDER BY period`)).
+			WithArgs(dto.FeedbackIntervalWeek, from, to, enums.PageLanguageTH).
+			WillReturnRows(sqlmock.NewRows([]string{"period", "helpful", "not_helpful"}).AddRow(from, 3, 1))
+
+		points, err := cmsFaqFeedbackRepo.FindHelpfulnessSeries(dto.FaqFeedbackQuery{From: from, To: to, Language: enums.PageLanguageTH}, dto.FeedbackIntervalWeek)
+		assert.NoError(t, err)
+		assert.Len(t, points, 1)
+		assert.Equal(t, int64(3), points[0].Helpful)
+		assert.Equal(t, int64(1), points[0].NotHelpful)
+	})
+
+	t.Run("successfully rank the lowest rated faqs", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`AS ratio FROM "faq_feedbacks" WHERE (faq_feedbacks.updated_at >= $3 AND faq_feedbacks.updated_at <= $4) AND faq_feedbacks.page_id = $5 GROUP BY faq_feedbacks.page_id, faq_feedbacks.language HAVING COUNT(*) >= $6 ORDER BY ratio ASC, COUNT(*) DESC LIMIT $7`)).
+			WithArgs(enums.PageModeHistories, enums.PageModePreview, from, to, pageId, 5, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"page_id", "language", "title", "helpful", "not_helpful", "ratio"}).
+				AddRow(pageId, enums.PageLanguageEN, "Reset password", 1, 4, 0.2))
+
+		ranks, err := cmsFaqFeedbackRepo.FindLowestRated(dto.FaqFeedbackQuery{From: from, To: to, PageID: &pageId}, 5, 10)
+		assert.NoError(t, err)
+		assert.Len(t, ranks, 1)
+		assert.Equal(t, "Reset password", ranks[0].Title)
+		assert.Equal(t, 0.2, ranks[0].Ratio)
+	})
+
+	t.Run("successfully find one page of feedback text", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "faq_feedbacks" WHERE faq_feedbacks.feedback <> '' AND (faq_feedbacks.updated_at >= $1 AND faq_feedbacks.updated_at <= $2)`)).
+			WithArgs(from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT faq_feedbacks.id, faq_feedbacks.page_id, faq_feedbacks.faq_content_id, faq_feedbacks.language, faq_contents.title, faq_feedbacks.helpful, faq_feedbacks.feedback, faq_feedbacks.updated_at FROM "faq_feedbacks" LEFT JOIN faq_contents ON faq_contents.id = faq_feedbacks.faq_content_id WHERE faq_feedbacks.feedback <> '' AND (faq_feedbacks.updated_at >= $1 AND faq_feedbacks.updated_at <= $2) ORDER BY faq_feedbacks.updated_at DESC LIMIT $3 OFFSET $4`)).
+			WithArgs(from, to, 20, 20).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "title", "helpful", "feedback"}).
+				AddRow(uuid.New(), pageId, "Reset password", false, "Outdated steps"))
+
+		comments, totalCount, err := cmsFaqFeedbackRepo.FindFeedbackComments(dto.FaqFeedbackQuery{From: from, To: to}, 2, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(21), totalCount)
+		assert.Len(t, comments, 1)
+		assert.Equal(t, "Outdated steps", comments[0].Feedback)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type MockCMSFaqFeedbackRepo struct {
+	findPublishedFaqContent func(id uuid.UUID) (*models.FaqContent, error)
+	upsertFeedback          func(feedback *models.FaqFeedback) error
+	findHelpfulnessSeries   func(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error)
+	findLowestRated         func(query dto.FaqFeedbackQuery, minVotes, limit int) ([]dto.FaqHelpfulnessRank, error)
+	findFeedbackComments    func(query dto.FaqFeedbackQuery, page, limit int) ([]dto.FaqFeedbackComment, int64, error)
+}
+
+func (m *MockCMSFaqFeedbackRepo) FindPublishedFaqContent(id uuid.UUID) (*models.FaqContent, error) {
+	return m.findPublishedFaqContent(id)
+}
+
+func (m *MockCMSFaqFeedbackRepo) UpsertFeedback(feedback *models.FaqFeedback) error {
+	return m.upsertFeedback(feedback)
+}
+
+func (m *MockCMSFaqFeedbackRepo) FindHelpfulnessSeries(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error) {
+	return m.findHelpfulnessSeries(query, interval)
+}
+
+func (m *MockCMSFaqFeedbackRepo) FindLowestRated(query dto.FaqFeedbackQuery, minVotes, limit int) ([]dto.FaqHelpfulnessRank, error) {
+	return m.findLowestRated(query, minVotes, limit)
+}
+
+func (m *MockCMSFaqFeedbackRepo) FindFeedbackComments(query dto.FaqFeedbackQuery, page, limit int) ([]dto.FaqFeedbackComment, int64, error) {
+	return m.findFeedbackComments(query, page, limit)
+}
+
+func newFeedbackConfig() *config.Config {
+	cfg := config.New()
+	cfg.SecretKey.HashSalt = "salt"
+	cfg.Feedback.MaxLength = 20
+	return cfg
+}
+
+func TestCMSService_RecordFeedback(t *testing.T) {
+	contentId := uuid.New()
+	pageId := uuid.New()
+	helpful := false
+	publishedContent := func(id uuid.UUID) (*models.FaqContent, error) {
+		return &models.FaqContent{ID: id, PageID: pageId, Language: enums.PageLanguageTH}, nil
+	}
+
+	t.Run("successfully record a vote as written with a hashed client", func(t *testing.T) {
+		var saved []*models.FaqFeedback
+		mockRepo := &MockCMSFaqFeedbackRepo{
+			findPublishedFaqContent: publishedContent,
+			upsertFeedback: func(feedback *models.FaqFeedback) error {
+				saved = append(saved, feedback)
+				return nil
+			},
+		}
+		service := services.NewCMSFaqFeedbackService(mockRepo, newFeedbackConfig())
+
+		request := dto.FaqFeedbackRequest{Helpful: &helpful, Feedback: "  Q&A <b>too short</b> "}
+		assert.NoError(t, service.RecordFeedback(contentId, request, "10.0.0.1", "Mozilla"))
+		assert.NoError(t, service.RecordFeedback(contentId, request, "10.0.0.1", "Mozilla"))
+		assert.NoError(t, service.RecordFeedback(contentId, request, "10.0.0.2", "Mozilla"))
+
+		assert.Len(t, saved, 3)
+		assert.Equal(t, pageId, saved[0].PageID)
+		assert.Equal(t, enums.PageLanguageTH, saved[0].Language)
+		assert.Equal(t, contentId, saved[0].FaqContentID)
+		assert.False(t, saved[0].Helpful)
+		assert.Equal(t, "Q&A <b>too short</b>", saved[0].Feedback)
+		assert.Len(t, saved[0].ClientHash, 64)
+		assert.NotContains(t, saved[0].ClientHash, "10.0.0.1")
+		assert.Equal(t, saved[0].ClientHash, saved[1].ClientHash)
+		assert.NotEqual(t, saved[0].ClientHash, saved[2].ClientHash)
+	})
+
+	t.Run("failed to record feedback: helpful is missing", func(t *testing.T) {
+		service := services.NewCMSFaqFeedbackService(&MockCMSFaqFeedbackRepo{}, newFeedbackConfig())
+
+		err := service.RecordFeedback(contentId, dto.FaqFeedbackRequest{Feedback: "Hi"}, "10.0.0.1", "Mozilla")
+		assert.ErrorIs(t, err, errs.ErrInvalidFeedback)
+	})
+
+	t.Run("failed to record feedback: feedback is too long", func(t *testing.T) {
+		service := services.NewCMSFaqFeedbackService(&MockCMSFaqFeedbackRepo{}, newFeedbackConfig())
+
+		request := dto.FaqFeedbackRequest{Helpful: &helpful, Feedback: strings.Repeat("ก", 21)}
+		err := service.RecordFeedback(contentId, request, "10.0.0.1", "Mozilla")
+		assert.ErrorIs(t, err, errs.ErrFeedbackTooLong)
+	})
+
+	t.Run("failed to record feedback: content is not published", func(t *testing.T) {
+		mockRepo := &MockCMSFaqFeedbackRepo{
+			findPublishedFaqContent: func(id uuid.UUID) (*models.FaqContent, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+		service := services.NewCMSFaqFeedbackService(mockRepo, newFeedbackConfig())
+
+		err := service.RecordFeedback(contentId, dto.FaqFeedbackRequest{Helpful: &helpful}, "10.0.0.1", "Mozilla")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestCMSService_GetHelpfulnessSeries(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("successfully compute the helpful ratio per period", func(t *testing.T) {
+		mockRepo := &MockCMSFaqFeedbackRepo{
+			findHelpfulnessSeries: func(query dto.FaqFeedbackQuery, interval string) ([]dto.HelpfulnessPoint, error) {
+				assert.Equal(t, dto.FeedbackIntervalDay, interval)
+				assert.Equal(t, enums.PageLanguageEN, query.Language)
+				return []dto.HelpfulnessPoint{{Period: from, Helpful: 3, NotHelpful: 1}, {Period: to}}, nil
+			},
+		}
+		service := services.NewCMSFaqFeedbackService(mockRepo, newFeedbackConfig())
+
+		points, err := service.GetHelpfulnessSeries(dto.FaqFeedbackQuery{From: from, To: to, Language: "EN"}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 0.75, points[0].Ratio)
+		assert.Equal(t, 0.0, points[1].Ratio)
+	})
+
+	t.Run("failed to get helpfulness: invalid interval", func(t *testing.T) {
+		service := services.NewCMSFaqFeedbackService(&MockCMSFaqFeedbackRepo{}, newFeedbackConfig())
+
+		_, err := service.GetHelpfulnessSeries(dto.FaqFeedbackQuery{From: from, To: to}, "hour")
+		assert.ErrorIs(t, err, errs.ErrInvalidInterval)
+	})
+
+	t.Run("failed to get helpfulness: invalid date range", func(t *testing.T) {
+		service := services.NewCMSFaqFeedbackService(&MockCMSFaqFeedbackRepo{}, newFeedbackConfig())
+
+		_, err := service.GetHelpfulnessSeries(dto.FaqFeedbackQuery{From: to, To: from}, dto.FeedbackIntervalWeek)
+		assert.ErrorIs(t, err, errs.ErrInvalidDateRange)
+
+		_, err = service.GetHelpfulnessSeries(dto.FaqFeedbackQuery{From: from, To: from.AddDate(2, 0, 0)}, dto.FeedbackIntervalMonth)
+		assert.ErrorIs(t, err, errs.ErrInvalidDateRange)
+	})
+
+	t.Run("failed to get helpfulness: invalid language", func(t *testing.T) {
+		service := services.NewCMSFaqFeedbackService(&MockCMSFaqFeedbackRepo{}, newFeedbackConfig())
+
+		_, err := service.GetHelpfulnessSeries(dto.FaqFeedbackQuery{From: from, To: to, Language: "fr"}, "")
+		assert.ErrorIs(t, err, errs.ErrInvalidLanguageCode)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
 	"github.com/MadManJJ/cms-api/helpers"
@@ -40,6 +41,14 @@ func (m *MockCMSFormSubmissionService) GetFormSubmissions(formId uuid.UUID, sort
 	return args.Get(0).([]*models.FormSubmission), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockCMSFormSubmissionService) GetFormSubmissionsByCursor(formId uuid.UUID, sort string, cursorQuery dto.CursorQuery) ([]*models.FormSubmission, *dto.CursorPage, error) {
+	args := m.Called(formId, sort, cursorQuery)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*models.FormSubmission), args.Get(1).(*dto.CursorPage), args.Error(2)
+}
+
 func (m *MockCMSFormSubmissionService) GetFormSubmission(submissionId uuid.UUID) (*models.FormSubmission, error) {
 	args := m.Called(submissionId)
 	if args.Get(0) == nil {
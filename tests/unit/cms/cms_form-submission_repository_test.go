@@ -32,6 +32,9 @@ func TestCMSRepo_CreateFormSubmission(t *testing.T) {
 					AddRow(uuid.New()), 
 			)
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "form_submissions" WHERE id = $1 AND "form_submissions"."id" = $2 ORDER BY "form_submissions"."id" LIMIT $3`)).
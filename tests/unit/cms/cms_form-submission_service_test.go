@@ -1,22 +1,26 @@
 package tests
 
 import (
+	"context"
 	"testing"
 
 	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
 	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockCMSFormSubmissionRepo struct {
 	createFormSubmission func(formSubmission *models.FormSubmission) (*models.FormSubmission, error)
 	getFormSubmissions   func(formId uuid.UUID, sort string, page, limit int) ([]*models.FormSubmission, int64, error)
+	getFormSubmissionsByCursor func(formId uuid.UUID, sort string, cursorQuery dto.CursorQuery) ([]*models.FormSubmission, *dto.CursorPage, error)
 	getFormSubmission    func(submissionId uuid.UUID) (*models.FormSubmission, error)
 	getEmailContentsFormFormId func(formId uuid.UUID) ([]*models.EmailContent, error)
 }
@@ -29,6 +33,10 @@ func (m *MockCMSFormSubmissionRepo) GetFormSubmissions(formId uuid.UUID, sort st
 	return m.getFormSubmissions(formId, sort, page, limit)
 }
 
+func (m *MockCMSFormSubmissionRepo) GetFormSubmissionsByCursor(formId uuid.UUID, sort string, cursorQuery dto.CursorQuery) ([]*models.FormSubmission, *dto.CursorPage, error) {
+	return m.getFormSubmissionsByCursor(formId, sort, cursorQuery)
+}
+
 func (m *MockCMSFormSubmissionRepo) GetFormSubmission(submissionId uuid.UUID) (*models.FormSubmission, error) {
 	return m.getFormSubmission(submissionId)
 }
@@ -154,4 +162,79 @@ func TestCMSService_GetFormSubmission(t *testing.T) {
 		assert.Error(t, err)	
 		assert.Nil(t, actualFormSubmission)
 	})
-}
\ No newline at end of file
+}
+func TestCMSService_HandleFormSubmissionDomainEvent(t *testing.T) {
+	formId := uuid.New()
+	submissionId := uuid.New()
+	categoryId := uuid.New()
+	submittedEmail := "jane@example.com"
+	event, err := helpers.NewOutboxEvent(enums.DomainEventFormSubmissionCreated, submissionId, "", dto.FormSubmissionEventData{
+		FormID:       formId,
+		SubmissionID: submissionId,
+	})
+	assert.NoError(t, err)
+
+	t.Run("successfully send the emails keyed on the event", func(t *testing.T) {
+		emailContent := &models.EmailContent{ID: uuid.New(), Label: "user-confirmation"}
+		repo := &MockCMSFormSubmissionRepo{
+			getFormSubmission: func(id uuid.UUID) (*models.FormSubmission, error) {
+				assert.Equal(t, submissionId, id)
+				return &models.FormSubmission{
+					ID:             submissionId,
+					FormID:         formId,
+					SubmittedEmail: &submittedEmail,
+					Form:           &models.Form{ID: formId, EmailCategoryID: &categoryId},
+				}, nil
+			},
+			getEmailContentsFormFormId: func(id uuid.UUID) ([]*models.EmailContent, error) {
+				return []*models.EmailContent{emailContent}, nil
+			},
+		}
+		emailSendingService := &MockEmailSendingService{}
+		emailSendingService.On("SendEmail", mock.MatchedBy(func(req dto.SendEmailRequest) bool {
+			return req.IdempotencyKey == event.ID.String()+":"+emailContent.ID.String() &&
+				req.ToRecipientEmails[0] == submittedEmail
+		})).Return(nil).Once()
+
+		service := services.NewCMSFormSubmissionService(repo, emailSendingService)
+
+		assert.NoError(t, service.HandleDomainEvent(context.Background(), event))
+		emailSendingService.AssertExpectations(t)
+	})
+
+	t.Run("return the send error so the event is retried", func(t *testing.T) {
+		repo := &MockCMSFormSubmissionRepo{
+			getFormSubmission: func(id uuid.UUID) (*models.FormSubmission, error) {
+				return &models.FormSubmission{
+					ID:             submissionId,
+					FormID:         formId,
+					SubmittedEmail: &submittedEmail,
+					Form:           &models.Form{ID: formId, EmailCategoryID: &categoryId},
+				}, nil
+			},
+			getEmailContentsFormFormId: func(id uuid.UUID) ([]*models.EmailContent, error) {
+				return []*models.EmailContent{{ID: uuid.New(), Label: "user-confirmation"}}, nil
+			},
+		}
+		emailSendingService := &MockEmailSendingService{}
+		emailSendingService.On("SendEmail", mock.AnythingOfType("dto.SendEmailRequest")).Return(errs.ErrInternalServerError)
+
+		service := services.NewCMSFormSubmissionService(repo, emailSendingService)
+
+		assert.ErrorIs(t, service.HandleDomainEvent(context.Background(), event), errs.ErrInternalServerError)
+	})
+
+	t.Run("skip a submission that no longer exists", func(t *testing.T) {
+		repo := &MockCMSFormSubmissionRepo{
+			getFormSubmission: func(id uuid.UUID) (*models.FormSubmission, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+		emailSendingService := &MockEmailSendingService{}
+
+		service := services.NewCMSFormSubmissionService(repo, emailSendingService)
+
+		assert.NoError(t, service.HandleDomainEvent(context.Background(), event))
+		emailSendingService.AssertNotCalled(t, "SendEmail", mock.Anything)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
@@ -56,6 +57,14 @@ func (m *MockCMSFormService) GetAllForms(filter dto.FormListFilter) (*dto.Pagina
 	return args.Get(0).(*dto.PaginatedFormListResponse), args.Error(1)
 }
 
+func (m *MockCMSFormService) GetFormsByCursor(filter dto.FormListFilter, cursorQuery dto.CursorQuery) ([]dto.FormListItemResponse, *dto.CursorPage, error) {
+	args := m.Called(filter, cursorQuery)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]dto.FormListItemResponse), args.Get(1).(*dto.CursorPage), args.Error(2)
+}
+
 func (m *MockCMSFormService) UpdateExistingForm(formID uuid.UUID, req dto.UpdateFormRequest) (*dto.FormResponse, error) {
 	args := m.Called(formID, req)
 	if args.Get(0) == nil {
@@ -71,7 +80,8 @@ func (m *MockCMSFormService) DeleteExistingForm(formID uuid.UUID) error {
 
 func TestFormHandler(t *testing.T) {
 	mockService := &MockCMSFormService{}
-	handler := cmsHandler.NewCMSFormHandler(mockService)
+	mockCache := newMockAppResponseCache()
+	handler := cmsHandler.NewCMSFormHandler(mockService, mockCache)
 	userId := uuid.New()
 
 	app := fiber.New()
@@ -215,7 +225,29 @@ func TestFormHandler(t *testing.T) {
 			resp, err := app.Test(req)
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.NotEmpty(t, resp.Header.Get(fiber.HeaderETag))
+			assert.Equal(t, "no-cache", resp.Header.Get(fiber.HeaderCacheControl))
 			mockService.AssertExpectations(t)
+			mockCache.AssertCalled(t, "Set", "form|"+formId.String(), mock.Anything, dto.CacheTags{FormIDs: []uuid.UUID{formId}})
+		})
+
+		t.Run("successfully answer a cached form structure with 304", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			cachedCache := &MockAppResponseCache{}
+			cachedCache.On("Get", "form|"+formId.String()).Return(&dto.CachedResponse{Body: []byte(`{"id":"cached"}`), ETag: `"cached"`}, true)
+			cachedCache.On("MaxAge").Return(time.Minute)
+			cachedApp := fiber.New()
+			cachedApp.Get("/api/v1/cms/forms/:formId/structure", cmsHandler.NewCMSFormHandler(mockService, cachedCache).HandleGetFormStructure)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/cms/forms/%s/structure", formId), nil)
+			req.Header.Set(fiber.HeaderIfNoneMatch, `W/"cached"`)
+
+			resp, err := cachedApp.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+			assert.Equal(t, "public, max-age=60", resp.Header.Get(fiber.HeaderCacheControl))
+			mockService.AssertNotCalled(t, "GetFormStructure", mock.Anything)
 		})
 
 		t.Run("failed to get form structure: invalid form id", func(t *testing.T) {
@@ -314,6 +346,7 @@ func TestFormHandler(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
 			mockService.AssertExpectations(t)
+			mockCache.AssertCalled(t, "InvalidateForm", formId)
 		})
 
 		t.Run("failed to update form: internal server error", func(t *testing.T) {
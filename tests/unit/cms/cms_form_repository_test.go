@@ -209,7 +209,7 @@ func TestCMSRepo_ListForms(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).
 				AddRow(totalItems))					
 
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "forms" WHERE name ILIKE $1 AND "forms"."deleted_at" IS NULL ORDER BY name ASC LIMIT $2`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "forms" WHERE name ILIKE $1 AND "forms"."deleted_at" IS NULL ORDER BY name ASC, id ASC LIMIT $2`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "created_by_user_id"}).
 				AddRow(formId, createdBy))				
 
@@ -226,7 +226,7 @@ func TestCMSRepo_ListForms(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).
 				AddRow(totalItems))					
 
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "forms" WHERE name ILIKE $1 AND "forms"."deleted_at" IS NULL ORDER BY name ASC LIMIT $2`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "forms" WHERE name ILIKE $1 AND "forms"."deleted_at" IS NULL ORDER BY name ASC, id ASC LIMIT $2`)).
 			WillReturnError(errs.ErrInternalServerError)				
 
 		forms, actualTotalItems, err := cmsFormRepo.ListForms(formFilter)
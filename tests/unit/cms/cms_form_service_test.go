@@ -21,6 +21,7 @@ type MockFormRepository struct {
 	getFormByID               func(formID uuid.UUID) (*models.Form, error)
 	getFormStructure          func(formID uuid.UUID) (*models.Form, error)
 	listForms                 func(filter dto.FormListFilter) ([]models.Form, int64, error)
+	listFormsByCursor         func(filter dto.FormListFilter, cursorQuery dto.CursorQuery) ([]models.Form, *dto.CursorPage, error)
 	updateForm                func(tx *gorm.DB, form *models.Form) (*models.Form, error)
 	deleteForm                func(tx *gorm.DB, formID uuid.UUID) error
 	checkFieldKeyExistsInForm func(formID uuid.UUID, fieldKey string, excludeFieldID *uuid.UUID) (bool, error)
@@ -43,6 +44,10 @@ func (m *MockFormRepository) ListForms(filter dto.FormListFilter) ([]models.Form
 	return m.listForms(filter)
 }
 
+func (m *MockFormRepository) ListFormsByCursor(filter dto.FormListFilter, cursorQuery dto.CursorQuery) ([]models.Form, *dto.CursorPage, error) {
+	return m.listFormsByCursor(filter, cursorQuery)
+}
+
 func (m *MockFormRepository) UpdateForm(tx *gorm.DB, form *models.Form) (*models.Form, error) {
 	return m.updateForm(tx, form)
 }
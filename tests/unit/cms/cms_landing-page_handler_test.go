@@ -4,15 +4,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -42,6 +46,14 @@ func (m *MockLandingService) FindLandingPages(rawQuery string, sort string, page
 	return args.Get(0).([]models.LandingPage), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockLandingService) FindLandingPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.LandingPage, *dto.CursorPage, error) {
+	args := m.Called(rawQuery, sort, cursorQuery, language)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]models.LandingPage), args.Get(1).(*dto.CursorPage), args.Error(2)
+}
+
 func (m *MockLandingService) FindLandingPageById(id uuid.UUID) (*models.LandingPage, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -66,8 +78,8 @@ func (m *MockLandingService) UpdateLandingContent(updatedLandingContent *models.
 	return args.Get(0).(*models.LandingContent), args.Error(1)
 }
 
-func (m *MockLandingService) DeleteLandingPage(id uuid.UUID) error {
-	args := m.Called(id)
+func (m *MockLandingService) DeleteLandingPage(id uuid.UUID, force bool) error {
+	args := m.Called(id, force)
 	return args.Error(0)
 }
 
@@ -132,17 +144,78 @@ func (m *MockLandingService) FindRevisions(pageId uuid.UUID, language string) ([
 	return args.Get(0).([]models.Revision), args.Error(1)
 }
 
-func (m *MockLandingService) PreviewLandingContent(pageId uuid.UUID, landingContentPreview *models.LandingContent) (string, error) {
+func (m *MockLandingService) PreviewLandingContent(pageId uuid.UUID, landingContentPreview *models.LandingContent) (*models.LandingContent, error) {
 	args := m.Called(pageId, landingContentPreview)
 	if args.Get(0) == nil {
-		return "", args.Error(1)
-	}	
-	return args.Get(0).(string), args.Error(1)
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LandingContent), args.Error(1)
+}
+
+func (m *MockLandingService) WithPageAccess(access *dto.PageAccess) services.CMSLandingPageServiceInterface {
+	return m
+}
+
+type MockAppResponseCache struct {
+	mock.Mock
+}
+
+// newMockAppResponseCache accepts every call, a test asserts the invalidations it cares about
+func newMockAppResponseCache() *MockAppResponseCache {
+	m := &MockAppResponseCache{}
+	m.On("Get", mock.Anything).Return(nil, false).Maybe()
+	m.On("Set", mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	m.On("InvalidatePage", mock.Anything, mock.Anything).Return().Maybe()
+	m.On("InvalidateCategory", mock.Anything).Return().Maybe()
+	m.On("InvalidateForm", mock.Anything).Return().Maybe()
+	m.On("InvalidateMedia", mock.Anything).Return().Maybe()
+	m.On("Purge").Return().Maybe()
+	m.On("MaxAge").Return(time.Duration(0)).Maybe()
+	return m
+}
+
+func (m *MockAppResponseCache) Get(key string) (*dto.CachedResponse, bool) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1)
+	}
+	return args.Get(0).(*dto.CachedResponse), args.Bool(1)
+}
+
+func (m *MockAppResponseCache) Set(key string, response *dto.CachedResponse, tags dto.CacheTags) {
+	m.Called(key, response, tags)
+}
+
+func (m *MockAppResponseCache) InvalidatePage(pageType enums.PageType, pageId uuid.UUID) {
+	m.Called(pageType, pageId)
+}
+
+func (m *MockAppResponseCache) InvalidateCategory(categoryId uuid.UUID) {
+	m.Called(categoryId)
+}
+
+func (m *MockAppResponseCache) InvalidateForm(formId uuid.UUID) {
+	m.Called(formId)
+}
+
+func (m *MockAppResponseCache) InvalidateMedia(downloadURL string) {
+	m.Called(downloadURL)
+}
+
+func (m *MockAppResponseCache) Purge() {
+	m.Called()
+}
+
+func (m *MockAppResponseCache) MaxAge() time.Duration {
+	return m.Called().Get(0).(time.Duration)
 }
 
 func TestCMSLandingHandler(t *testing.T) {
 	mockService := &MockLandingService{}
-	handler := cmsHandler.NewCMSLandingPageHandler(mockService)
+	mockPreviewLinkService := &MockCMSPreviewLinkService{}
+	mockUsageService := &MockCMSUsageService{}
+	mockUsageService.On("CheckDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	handler := cmsHandler.NewCMSLandingPageHandler(mockService, mockPreviewLinkService, mockUsageService)
 
 	app := fiber.New()
 	app.Post("/cms/landingpages", handler.HandleCreateLandingPage)
@@ -269,6 +342,36 @@ func TestCMSLandingHandler(t *testing.T) {
 			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
 			mockService.AssertExpectations(t)
 		})		
+
+		t.Run("successfully get the first page with a cursor", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			totalCount := int64(12)
+			mockService.On("FindLandingPagesByCursor", "", "created_at:desc", dto.CursorQuery{Limit: 2, WithTotal: true}, language).
+				Return(expectedLandingPages, &dto.CursorPage{Next: "next-cursor", Limit: 2, TotalCount: &totalCount}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/landingpages?cursor=&sort=created_at:desc&limit=2&withTotal=true&language=en", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"next":"next-cursor"`)
+			assert.Contains(t, string(body), `"totalCount":12`)
+			mockService.AssertNotCalled(t, "FindLandingPages", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		t.Run("failed to get landing page: invalid cursor", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindLandingPagesByCursor", "", "", dto.CursorQuery{Cursor: "garbage"}, "").Return(nil, nil, errs.ErrInvalidCursor)
+
+			req := httptest.NewRequest("GET", "/cms/landingpages?cursor=garbage", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
 	})	
 
 	t.Run("GET /cms/landingpages/:pageId HandleGetLandingPageById", func(t *testing.T) {
@@ -309,14 +412,26 @@ func TestCMSLandingHandler(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
 			mockService.AssertExpectations(t)		
-		})			
+		})
+
+		t.Run("failed get landing page by id: outside the page grants", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindLandingPageById", pageId).Return(nil, errs.ErrPageAccessDenied)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/cms/landingpages/%s", pageId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
 	})
 
 	t.Run("DELETE /cms/landingpages/:pageId HandleDeleteLandingPage", func(t *testing.T)	{
 		pageId := uuid.New()
 
 		t.Run("successfully delete landing page", func(t *testing.T)	{
-			mockService.On("DeleteLandingPage", pageId).Return(nil)
+			mockService.On("DeleteLandingPage", pageId, false).Return(nil)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/landingpages/%s", pageId), nil)
 			
@@ -328,7 +443,7 @@ func TestCMSLandingHandler(t *testing.T) {
 		
 		t.Run("failed to delete landing page: invalid pageId", func(t *testing.T)	{
 			mockService.ExpectedCalls = nil
-			mockService.On("DeleteLandingPage", pageId).Return(nil)
+			mockService.On("DeleteLandingPage", pageId, false).Return(nil)
 			invalidPageId := "1"
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/landingpages/%s", invalidPageId), nil)
@@ -341,7 +456,7 @@ func TestCMSLandingHandler(t *testing.T) {
 		
 		t.Run("failed to delete landing page: internal server error", func(t *testing.T)	{
 			mockService.ExpectedCalls = nil
-			mockService.On("DeleteLandingPage", pageId).Return(errs.ErrInternalServerError)
+			mockService.On("DeleteLandingPage", pageId, false).Return(errs.ErrInternalServerError)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/landingpages/%s", pageId), nil)
 			
@@ -350,6 +465,24 @@ func TestCMSLandingHandler(t *testing.T) {
 			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
 			mockService.AssertExpectations(t)				
 		})			
+
+		t.Run("failed to delete landing page: referenced by published content", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			mockUsageService.ExpectedCalls = nil
+			mockUsageService.On("CheckDelete", enums.UsageItemLandingPage, pageId, false).Return(nil, errs.ErrItemInUse)
+			defer func() {
+				mockUsageService.ExpectedCalls = nil
+				mockUsageService.On("CheckDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+			}()
+
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/landingpages/%s", pageId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+			mockService.AssertNotCalled(t, "DeleteLandingPage", mock.Anything)
+		})
 	})	
 
 	t.Run("GET /cms/landingpages/:pageId/contents/:languageCode HandleGetContentByLandingPageId", func(t *testing.T)	{
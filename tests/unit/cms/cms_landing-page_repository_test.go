@@ -8,6 +8,7 @@ import (
 	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
 	repo "github.com/MadManJJ/cms-api/repositories"
 
@@ -83,6 +84,8 @@ func TestCMSRepo_CreateLandingPage(t *testing.T) {
 					AddRow(uuid.New(), uuid.New()), // match what's RETURNED
 			)
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		landingPage, err := cmsLandingPageRepo.CreateLandingPage(mockLandingPage)
@@ -366,10 +369,16 @@ func TestCMSRepo_UpdateLandingContent(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}).
 				AddRow(landingContentId, categoryId))				
 				
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "landing_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))						
 
 		// Expect commit
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()		
 
 		updatedLandingContent, err := cmsLandingPageRepo.UpdateLandingContent(mockLandingContent, prevContentId)
@@ -420,6 +429,10 @@ func TestCMSRepo_DeleteLandingPage(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 				AddRow(pageId, now, now))
 
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemLandingPage, pageId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_contents"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "title"}).
 				AddRow(contentId, pageId, contentTitle))		
@@ -442,9 +455,12 @@ func TestCMSRepo_DeleteLandingPage(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "landing_pages"`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))					
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
-		err := cmsLandingPageRepo.DeleteLandingPage(pageId)
+		err := cmsLandingPageRepo.DeleteLandingPage(pageId, false)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})	
@@ -459,7 +475,7 @@ func TestCMSRepo_DeleteLandingPage(t *testing.T) {
 
 		mock.ExpectRollback()
 
-		err := cmsLandingPageRepo.DeleteLandingPage(pageId)
+		err := cmsLandingPageRepo.DeleteLandingPage(pageId, false)
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})	
@@ -574,6 +590,52 @@ func TestCMSRepo_FindLatestLandingContentByPageId(t *testing.T) {
 	})		
 }
 
+func TestCMSRepo_CreateContentForLandingPage(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsLandingPageRepo := repo.NewCMSLandingPageRepository(gormDB)
+
+	language := string(enums.PageLanguageTH)
+	mode := string(enums.PageModeDraft)
+
+	t.Run("successfully create the content of another language and record it", func(t *testing.T) {
+		pageId := uuid.New()
+		contentId := uuid.New()
+		content := &models.LandingContent{PageID: pageId, Title: "some title", Language: enums.PageLanguageTH, Mode: enums.PageModeDraft}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "landing_contents"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(contentId))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WithArgs(sqlmock.AnyArg(), enums.DomainEventContentSaved, pageId, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_contents" WHERE id = $1`)).
+			WithArgs(contentId, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "title"}).AddRow(contentId, pageId, "some title"))
+
+		createdContent, err := cmsLandingPageRepo.CreateContentForLandingPage(content, language, mode)
+		assert.NoError(t, err)
+		assert.Equal(t, contentId, createdContent.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to create the content: nothing is recorded", func(t *testing.T) {
+		content := &models.LandingContent{PageID: uuid.New(), Title: "some title", Language: enums.PageLanguageTH, Mode: enums.PageModeDraft}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "landing_contents"`)).
+			WillReturnError(errs.ErrInternalServerError)
+		mock.ExpectRollback()
+
+		createdContent, err := cmsLandingPageRepo.CreateContentForLandingPage(content, language, mode)
+		assert.Error(t, err)
+		assert.Nil(t, createdContent)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestCMSRepo_DeleteLandingContent(t *testing.T) {
 	gormDB, mock, cleanup := helpers.SetupTestDB(t)
 	defer cleanup()
@@ -605,6 +667,9 @@ func TestCMSRepo_DeleteLandingContent(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "landing_contents"`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))			
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		err := cmsLandingPageRepo.DeleteLandingContent(pageId, language, mode)
@@ -752,6 +817,9 @@ func TestCMSRepo_DuplicateLandingPage(t *testing.T) {
 					AddRow(newContentId, categoryId),
 			)				
 			
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		mock.ExpectCommit()
 
 		landingPage, err := cmsLandingPageRepo.DuplicateLandingPage(pageId)
@@ -844,6 +912,12 @@ func TestCMSRepo_DuplicateLandingContentToAnotherLanguage(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}).
 				AddRow(newContentId, newCategoryId))						
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		landingContent, err := cmsLandingPageRepo.DuplicateLandingContentToAnotherLanguage(oldContentId, newRevision)
@@ -949,6 +1023,12 @@ func TestCMSRepo_RevertLandingContent(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"landing_content_id", "category_id"}).
 				AddRow(newContentId, newCategoryId))					
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		landingContent, err := cmsLandingPageRepo.RevertLandingContent(oldRevisionId, newRevision)
@@ -1183,4 +1263,140 @@ func TestCMSRepo_GetLandingPageIdByContentId(t *testing.T) {
 		assert.Equal(t, actualPageId, uuid.Nil)
 		assert.NoError(t, mock.ExpectationsWereMet())		
 	})	
-}
\ No newline at end of file
+}
+func TestCMSRepo_FindLandingPagesByCursor(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsLandingPageRepo := repo.NewCMSLandingPageRepository(gormDB)
+
+	createdAt := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	firstId, secondId, thirdId := uuid.New(), uuid.New(), uuid.New()
+	var next string
+
+	t.Run("successfully find the first page with a next cursor", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "landing_pages" WHERE EXISTS (SELECT 1 FROM "landing_contents" WHERE landing_contents.page_id = landing_pages.id AND (landing_contents.mode != $1 AND landing_contents.mode != $2))`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY landing_pages.created_at DESC,landing_pages.id DESC LIMIT $3`)).
+			WithArgs("Histories", "Preview", 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+				AddRow(firstId, createdAt, createdAt).
+				AddRow(secondId, createdAt.Add(-time.Hour), createdAt).
+				AddRow(thirdId, createdAt.Add(-2*time.Hour), createdAt))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_contents"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id"}))
+
+		landingPages, page, err := cmsLandingPageRepo.FindLandingPagesByCursor(dto.LandingPageQuery{}, "", dto.CursorQuery{Limit: 2, WithTotal: true}, "")
+
+		assert.NoError(t, err)
+		assert.Len(t, landingPages, 2)
+		assert.Equal(t, secondId, landingPages[1].ID)
+		assert.NotEmpty(t, page.Next)
+		assert.Empty(t, page.Prev)
+		assert.Equal(t, int64(3), *page.TotalCount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		next = page.Next
+	})
+
+	t.Run("successfully find the last page after the next cursor", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`AND (landing_pages.created_at, landing_pages.id) < ($3, $4) ORDER BY landing_pages.created_at DESC,landing_pages.id DESC LIMIT $5`)).
+			WithArgs("Histories", "Preview", createdAt.Add(-time.Hour), secondId, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+				AddRow(thirdId, createdAt.Add(-2*time.Hour), createdAt))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_contents"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id"}))
+
+		landingPages, page, err := cmsLandingPageRepo.FindLandingPagesByCursor(dto.LandingPageQuery{}, "", dto.CursorQuery{Cursor: next, Limit: 2}, "")
+
+		assert.NoError(t, err)
+		assert.Len(t, landingPages, 1)
+		assert.Empty(t, page.Next)
+		assert.NotEmpty(t, page.Prev)
+		assert.Nil(t, page.TotalCount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to find landing pages: cursor of another sort", func(t *testing.T) {
+		landingPages, page, err := cmsLandingPageRepo.FindLandingPagesByCursor(dto.LandingPageQuery{}, "updated_at:desc", dto.CursorQuery{Cursor: next}, "")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidCursor)
+		assert.Nil(t, landingPages)
+		assert.Nil(t, page)
+	})
+
+	t.Run("failed to find landing pages: sort on a content column", func(t *testing.T) {
+		landingPages, page, err := cmsLandingPageRepo.FindLandingPagesByCursor(dto.LandingPageQuery{}, "title:asc", dto.CursorQuery{}, "")
+
+		assert.ErrorIs(t, err, errs.ErrUnsupportedCursorSort)
+		assert.Nil(t, landingPages)
+		assert.Nil(t, page)
+	})
+}
+
+func TestCMSRepo_FindAllLandingPageWithPageGrants(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsLandingPageRepo := repo.NewCMSLandingPageRepository(gormDB)
+
+	english := enums.PageLanguageEN
+	categoryId := uuid.New()
+
+	t.Run("successfully filter the pages by the read grants", func(t *testing.T) {
+		query := dto.LandingPageQuery{Access: &dto.PageAccess{Grants: []models.PageGrant{
+			{PageType: enums.PageTypeLanding, Action: enums.PermissionActionRead, Language: &english, CategoryID: &categoryId},
+			{PageType: enums.PageTypeFaq, Action: enums.PermissionActionAll},
+		}}}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`((landing_contents.language = $3 AND landing_contents.id IN (SELECT landing_content_id FROM landing_content_categories WHERE category_id = $4)))`)).
+			WithArgs("Histories", "Preview", "en", categoryId).
+			WillReturnError(errs.ErrInternalServerError)
+
+		_, _, err := cmsLandingPageRepo.FindAllLandingPage(query, "", 1, 10, "")
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("successfully find no pages without a read grant of the page type", func(t *testing.T) {
+		query := dto.LandingPageQuery{Access: &dto.PageAccess{Grants: []models.PageGrant{
+			{PageType: enums.PageTypeFaq, Action: enums.PermissionActionAll},
+		}}}
+
+		mock.ExpectQuery(`SELECT COUNT\(DISTINCT\("landing_pages"."id"\)\) FROM "landing_pages" .*FALSE`).
+			WillReturnError(errs.ErrInternalServerError)
+
+		_, _, err := cmsLandingPageRepo.FindAllLandingPage(query, "", 1, 10, "")
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCMSRepo_FindLandingContentScopes(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsLandingPageRepo := repo.NewCMSLandingPageRepository(gormDB)
+
+	t.Run("successfully group the categories by content", func(t *testing.T) {
+		pageId := uuid.New()
+		contentId := uuid.New()
+		translationId := uuid.New()
+		categoryId1 := uuid.New()
+		categoryId2 := uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT landing_contents.id, landing_contents.language, landing_contents.mode, landing_content_categories.category_id FROM "landing_contents" LEFT JOIN landing_content_categories ON landing_content_categories.landing_content_id = landing_contents.id WHERE landing_contents.page_id = $1 ORDER BY landing_contents.id`)).
+			WithArgs(pageId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "language", "mode", "category_id"}).
+				AddRow(contentId, "en", "Draft", categoryId1).
+				AddRow(contentId, "en", "Draft", categoryId2).
+				AddRow(translationId, "th", "Draft", nil))
+
+		scopes, err := cmsLandingPageRepo.FindContentScopes(pageId)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.PageContentScope{
+			{ContentID: contentId, Language: enums.PageLanguageEN, Mode: enums.PageModeDraft, CategoryIDs: []uuid.UUID{categoryId1, categoryId2}},
+			{ContentID: translationId, Language: enums.PageLanguageTH, Mode: enums.PageModeDraft},
+		}, scopes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,8 +1,8 @@
 package tests
 
 import (
-	"strings"
 	"testing"
+	"time"
 
 	"github.com/MadManJJ/cms-api/config"
 	"github.com/MadManJJ/cms-api/dto"
@@ -20,9 +20,10 @@ import (
 type MockCMSLandingPageRepo struct {
 	createLandingPage                        func(landingPage *models.LandingPage) (*models.LandingPage, error)
 	findAllLandingPage                       func(query dto.LandingPageQuery, sort string, page, limit int, language string) ([]models.LandingPage, int64, error)
+	findLandingPagesByCursor                 func(query dto.LandingPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.LandingPage, *dto.CursorPage, error)
 	findLandingPageById                      func(id uuid.UUID) (*models.LandingPage, error)
 	updateLandingContent                     func(updateLandingContent *models.LandingContent, prevContentId uuid.UUID) (*models.LandingContent, error)
-	deleteLandingPage                        func(id uuid.UUID) error
+	deleteLandingPage                        func(id uuid.UUID, force bool) error
 	findContentByLandingPageId               func(pageId uuid.UUID, language string, mode string) (*models.LandingContent, error)
 	findLatestContentByPageId                func(pageId uuid.UUID, language string) (*models.LandingContent, error)
 	createContentForLandingPage              func(landingContent *models.LandingContent, lang string, mode string) (*models.LandingContent, error)
@@ -34,6 +35,9 @@ type MockCMSLandingPageRepo struct {
 	getRevisionByLandingPageId               func(pageId uuid.UUID, language string) ([]models.Revision, error)
 	isUrlAliasDuplicate                      func(urlAlias string, pageId uuid.UUID) (bool, error)
 	getPageIdByContentId                     func(contentId uuid.UUID) (uuid.UUID, error)
+	getContentRefByRevisionId                func(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error)
+	findContentById func(contentId uuid.UUID) (*models.LandingContent, error)
+	findContentScopes                        func(pageId uuid.UUID) ([]dto.PageContentScope, error)
 	createLandingContentPreview               func(landingContentPreview *models.LandingContent) (*models.LandingContent, error)
 	updateLandingContentPreview               func(landingContentPreview *models.LandingContent) (*models.LandingContent, error)	
 	findLandingContentPreviewById             func(pageId uuid.UUID, language string) (*models.LandingContent, error)
@@ -47,6 +51,10 @@ func (m *MockCMSLandingPageRepo) FindAllLandingPage(query dto.LandingPageQuery,
 	return m.findAllLandingPage(query, sort, page, limit, language)
 }
 
+func (m *MockCMSLandingPageRepo) FindLandingPagesByCursor(query dto.LandingPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.LandingPage, *dto.CursorPage, error) {
+	return m.findLandingPagesByCursor(query, sort, cursorQuery, language)
+}
+
 func (m *MockCMSLandingPageRepo) FindLandingPageById(id uuid.UUID) (*models.LandingPage, error) {
 	return m.findLandingPageById(id)
 }
@@ -55,8 +63,8 @@ func (m *MockCMSLandingPageRepo) UpdateLandingContent(updateLandingContent *mode
 	return m.updateLandingContent(updateLandingContent, prevContentId)
 }
 
-func (m *MockCMSLandingPageRepo) DeleteLandingPage(id uuid.UUID) error {
-	return m.deleteLandingPage(id)
+func (m *MockCMSLandingPageRepo) DeleteLandingPage(id uuid.UUID, force bool) error {
+	return m.deleteLandingPage(id, force)
 }
 
 func (m *MockCMSLandingPageRepo) FindContentByLandingPageId(pageId uuid.UUID, language string, mode string) (*models.LandingContent, error) {
@@ -103,6 +111,18 @@ func (m *MockCMSLandingPageRepo) GetPageIdByContentId(contentId uuid.UUID) (uuid
 	return m.getPageIdByContentId(contentId)
 }
 
+func (m *MockCMSLandingPageRepo) GetContentRefByRevisionId(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	return m.getContentRefByRevisionId(revisionId)
+}
+
+func (m *MockCMSLandingPageRepo) FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageId)
+}
+
+func (m *MockCMSLandingPageRepo) FindContentById(contentId uuid.UUID) (*models.LandingContent, error) {
+	return m.findContentById(contentId)
+}
+
 func (m *MockCMSLandingPageRepo) CreateLandingContentPreview(landingContentPreview *models.LandingContent) (*models.LandingContent, error) {
 	return m.createLandingContentPreview(landingContentPreview)
 }
@@ -386,7 +406,7 @@ func TestCMSService_DeleteLandingPage(t *testing.T) {
 		pageId := uuid.New()
 
 		landingRepo := &MockCMSLandingPageRepo{
-			deleteLandingPage: func(id uuid.UUID) error {
+			deleteLandingPage: func(id uuid.UUID, force bool) error {
 				return nil
 			},
 		}
@@ -397,7 +417,7 @@ func TestCMSService_DeleteLandingPage(t *testing.T) {
 	
 		service := services.NewCMSLandingPageService(landingRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)	
 
-		err := service.DeleteLandingPage(pageId)
+		err := service.DeleteLandingPage(pageId, false)
 		assert.NoError(t, err)
 	})	
 
@@ -405,7 +425,7 @@ func TestCMSService_DeleteLandingPage(t *testing.T) {
 		pageId := uuid.New()
 
 		landingRepo := &MockCMSLandingPageRepo{
-			deleteLandingPage: func(id uuid.UUID) error {
+			deleteLandingPage: func(id uuid.UUID, force bool) error {
 				return errs.ErrInternalServerError
 			},
 		}
@@ -416,7 +436,7 @@ func TestCMSService_DeleteLandingPage(t *testing.T) {
 	
 		service := services.NewCMSLandingPageService(landingRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)	
 
-		err := service.DeleteLandingPage(pageId)
+		err := service.DeleteLandingPage(pageId, false)
 		assert.Error(t, err)
 	})		
 }
@@ -804,12 +824,6 @@ func TestCMSService_FindLandingRevisions(t *testing.T) {
 }
 
 func TestCMSService_PreviewLandingContent(t *testing.T) {
-	cfg := config.New()
-
-	urls := cfg.App.FrontendURLS
-	parts := strings.Split(urls, ",")
-	appUrl := parts[1]	
-
 	t.Run("successfully preview content: create new content", func(t *testing.T) {
 		pageId := uuid.New()
 
@@ -838,18 +852,11 @@ func TestCMSService_PreviewLandingContent(t *testing.T) {
 
 		service := services.NewCMSLandingPageService(landingRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)	
 
-		expectPreviewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(mockContent.Language),
-			"landing",
-			createdContent.ID,
-		)
-
+		savedContent, err := service.PreviewLandingContent(pageId, mockContent)
 		assert.NoError(t, err)
-
-		previewUrl, err := service.PreviewLandingContent(pageId, mockContent)
-		assert.NoError(t, err)
-		assert.Equal(t, expectPreviewUrl, previewUrl)
+		assert.Equal(t, createdContent.ID, savedContent.ID)
+		assert.Equal(t, enums.PageModePreview, mockContent.Mode)
+		assert.WithinDuration(t, time.Now().Add(cfg.Preview.MaxTTL), mockContent.ExpiredAt, time.Minute)
 	})	
 
 	t.Run("successfully preview content: update existing content", func(t *testing.T) {
@@ -886,17 +893,186 @@ func TestCMSService_PreviewLandingContent(t *testing.T) {
 
 		service := services.NewCMSLandingPageService(landingRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)	
 
-		expectPreviewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(updatedContent.Language),
-			"landing",
-			updatedContent.ID,
-		)
+		savedContent, err := service.PreviewLandingContent(pageId, mockContent)
+		assert.NoError(t, err)
+		assert.Equal(t, updatedContent.ID, savedContent.ID)
+	})		
+}
+func TestCMSService_LandingPageAccess(t *testing.T) {
+	pageId := uuid.New()
+	contentId := uuid.New()
+	partnerCategoryId := uuid.New()
+	english := enums.PageLanguageEN
+
+	// The user may only edit english landing pages in the partner category
+	access := &dto.PageAccess{Grants: []models.PageGrant{
+		{PageType: enums.PageTypeLanding, Action: enums.PermissionActionAll, Language: &english, CategoryID: &partnerCategoryId},
+	}}
+	newService := func(landingRepo *MockCMSLandingPageRepo) services.CMSLandingPageServiceInterface {
+		emailContentRepo := &MockCMSEmailContentRepo{}
+		emailCategoryRepo := &MockCMSEmailCategoryRepo{}
+		cfg := config.New()
+		emailSendingService := services.NewEmailSendingService(cfg, emailCategoryRepo, emailContentRepo)
+
+		return services.NewCMSLandingPageService(landingRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg).WithPageAccess(access)
+	}
+	scopes := func(categoryIds ...uuid.UUID) func(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+		return func(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+			return []dto.PageContentScope{{ContentID: contentId, Language: enums.PageLanguageEN, Mode: enums.PageModeDraft, CategoryIDs: categoryIds}}, nil
+		}
+	}
+
+	t.Run("successfully limit the list to the page grants", func(t *testing.T) {
+		landingRepo := &MockCMSLandingPageRepo{
+			findAllLandingPage: func(query dto.LandingPageQuery, sort string, page, limit int, language string) ([]models.LandingPage, int64, error) {
+				assert.Equal(t, access, query.Access)
+				return nil, 0, nil
+			},
+		}
 
+		_, _, err := newService(landingRepo).FindLandingPages("", "", 1, 10, "")
 		assert.NoError(t, err)
+	})
+
+	t.Run("successfully find landing page in the granted category", func(t *testing.T) {
+		mockLandingPage := helpers.InitializeMockLandingPage()
+		landingRepo := &MockCMSLandingPageRepo{
+			findContentScopes: scopes(uuid.New(), partnerCategoryId),
+			findLandingPageById: func(id uuid.UUID) (*models.LandingPage, error) {
+				return mockLandingPage, nil
+			},
+		}
 
-		previewUrl, err := service.PreviewLandingContent(pageId, mockContent)
+		actualLandingPage, err := newService(landingRepo).FindLandingPageById(pageId)
 		assert.NoError(t, err)
-		assert.Equal(t, expectPreviewUrl, previewUrl)
-	})		
-}
\ No newline at end of file
+		assert.Equal(t, mockLandingPage, actualLandingPage)
+	})
+
+	t.Run("failed to find landing page: outside the granted category", func(t *testing.T) {
+		landingRepo := &MockCMSLandingPageRepo{
+			findContentScopes: scopes(uuid.New()),
+		}
+
+		actualLandingPage, err := newService(landingRepo).FindLandingPageById(pageId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, actualLandingPage)
+	})
+
+	t.Run("failed to find landing page: request without a page access", func(t *testing.T) {
+		landingRepo := &MockCMSLandingPageRepo{
+			findContentScopes: scopes(partnerCategoryId),
+		}
+		cfg := config.New()
+		service := services.NewCMSLandingPageService(landingRepo, services.NewEmailSendingService(cfg, &MockCMSEmailCategoryRepo{}, &MockCMSEmailContentRepo{}), &MockCMSEmailContentRepo{}, &MockCMSEmailCategoryRepo{}, cfg).WithPageAccess(nil)
+
+		actualLandingPage, err := service.FindLandingPageById(pageId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, actualLandingPage)
+	})
+
+	t.Run("failed to delete landing page: outside the granted category", func(t *testing.T) {
+		landingRepo := &MockCMSLandingPageRepo{
+			findContentScopes: scopes(),
+		}
+
+		err := newService(landingRepo).DeleteLandingPage(pageId, false)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+
+	// The page has an english content in the granted category and a thai one the grant does not cover
+	mixedScopes := func(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+		return []dto.PageContentScope{
+			{ContentID: contentId, Language: enums.PageLanguageEN, Mode: enums.PageModeDraft, CategoryIDs: []uuid.UUID{partnerCategoryId}},
+			{ContentID: uuid.New(), Language: enums.PageLanguageTH, Mode: enums.PageModeDraft, CategoryIDs: []uuid.UUID{partnerCategoryId}},
+		}, nil
+	}
+
+	t.Run("successfully find landing page with one granted content", func(t *testing.T) {
+		mockLandingPage := helpers.InitializeMockLandingPage()
+		landingRepo := &MockCMSLandingPageRepo{
+			findContentScopes: mixedScopes,
+			findLandingPageById: func(id uuid.UUID) (*models.LandingPage, error) {
+				return mockLandingPage, nil
+			},
+		}
+
+		actualLandingPage, err := newService(landingRepo).FindLandingPageById(pageId)
+		assert.NoError(t, err)
+		assert.Equal(t, mockLandingPage, actualLandingPage)
+	})
+
+	t.Run("failed to delete landing page: one content outside the grants", func(t *testing.T) {
+		landingRepo := &MockCMSLandingPageRepo{
+			findContentScopes: mixedScopes,
+			deleteLandingPage: func(id uuid.UUID, force bool) error {
+				t.Fatal("the page must not be deleted")
+				return nil
+			},
+		}
+
+		err := newService(landingRepo).DeleteLandingPage(pageId, false)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+
+	t.Run("failed to duplicate landing page: one content outside the grants", func(t *testing.T) {
+		landingRepo := &MockCMSLandingPageRepo{
+			findContentScopes: mixedScopes,
+			duplicateLandingPage: func(pageId uuid.UUID) (*models.LandingPage, error) {
+				t.Fatal("the page must not be duplicated")
+				return nil, nil
+			},
+		}
+
+		actualLandingPage, err := newService(landingRepo).DuplicateLandingPage(pageId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, actualLandingPage)
+	})
+
+	t.Run("successfully delete landing page: every content granted", func(t *testing.T) {
+		landingRepo := &MockCMSLandingPageRepo{
+			findContentScopes: func(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+				return []dto.PageContentScope{
+					{ContentID: contentId, Language: enums.PageLanguageEN, Mode: enums.PageModeDraft, CategoryIDs: []uuid.UUID{partnerCategoryId}},
+					{ContentID: uuid.New(), Language: enums.PageLanguageEN, Mode: enums.PageModePublished, CategoryIDs: []uuid.UUID{partnerCategoryId}},
+				}, nil
+			},
+			deleteLandingPage: func(id uuid.UUID, force bool) error {
+				return nil
+			},
+		}
+
+		err := newService(landingRepo).DeleteLandingPage(pageId, false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed to update landing content: moved out of the granted category", func(t *testing.T) {
+		mockContent := helpers.InitializeMockLandingPage().Contents[0]
+		mockContent.Categories = nil
+		landingRepo := &MockCMSLandingPageRepo{
+			getPageIdByContentId: func(contentId uuid.UUID) (uuid.UUID, error) {
+				return pageId, nil
+			},
+			findContentScopes: scopes(partnerCategoryId),
+		}
+
+		actualContent, err := newService(landingRepo).UpdateLandingContent(mockContent, contentId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, actualContent)
+	})
+
+	t.Run("failed to publish landing content: no publish permission", func(t *testing.T) {
+		mockContent := helpers.InitializeMockLandingPage().Contents[0]
+		mockContent.WorkflowStatus = enums.WorkflowPublished
+		mockContent.Categories = []*models.Category{{ID: partnerCategoryId}}
+		landingRepo := &MockCMSLandingPageRepo{
+			getPageIdByContentId: func(contentId uuid.UUID) (uuid.UUID, error) {
+				return pageId, nil
+			},
+			findContentScopes: scopes(partnerCategoryId),
+		}
+
+		actualContent, err := newService(landingRepo).UpdateLandingContent(mockContent, contentId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, actualContent)
+	})
+}
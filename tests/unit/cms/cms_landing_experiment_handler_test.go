@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockCMSLandingExperimentService struct {
+	mock.Mock
+}
+
+func (m *MockCMSLandingExperimentService) CreateExperiment(request dto.CreateLandingExperimentRequest) (*models.LandingExperiment, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LandingExperiment), args.Error(1)
+}
+
+func (m *MockCMSLandingExperimentService) FindExperiments(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error) {
+	args := m.Called(pageId, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LandingExperiment), args.Error(1)
+}
+
+func (m *MockCMSLandingExperimentService) GetExperimentResults(id uuid.UUID) (*dto.ExperimentResults, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExperimentResults), args.Error(1)
+}
+
+func (m *MockCMSLandingExperimentService) StopExperiment(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCMSLandingExperimentService) PromoteWinner(id uuid.UUID, request dto.PromoteExperimentRequest) (*models.LandingContent, error) {
+	args := m.Called(id, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LandingContent), args.Error(1)
+}
+
+func (m *MockCMSLandingExperimentService) AssignVariant(content *models.LandingContent, assignmentKey string) (*dto.ExperimentAssignment, error) {
+	args := m.Called(content, assignmentKey)
+	return nil, args.Error(1)
+}
+
+func (m *MockCMSLandingExperimentService) RecordConversion(experimentId uuid.UUID, assignmentKey string) error {
+	args := m.Called(experimentId, assignmentKey)
+	return args.Error(0)
+}
+
+func (m *MockCMSLandingExperimentService) WithPageAccess(access *dto.PageAccess) services.CMSLandingExperimentServiceInterface {
+	return m
+}
+
+func TestCMSLandingExperimentHandler(t *testing.T) {
+	mockService := &MockCMSLandingExperimentService{}
+	handler := cmsHandler.NewCMSLandingExperimentHandler(mockService, newMockAppResponseCache())
+
+	app := fiber.New()
+	app.Post("/cms/experiments", handler.HandleCreateExperiment)
+	app.Get("/cms/experiments", handler.HandleGetExperiments)
+	app.Get("/cms/experiments/:experimentId/results", handler.HandleGetExperimentResults)
+	app.Post("/cms/experiments/:experimentId/stop", handler.HandleStopExperiment)
+	app.Post("/cms/experiments/:experimentId/promote", handler.HandlePromoteWinner)
+
+	experimentId := uuid.New()
+	pageId := uuid.New()
+
+	t.Run("POST /cms/experiments HandleCreateExperiment", func(t *testing.T) {
+		body := `{"page_id":"` + pageId.String() + `","language":"en","name":"Hero layout","variants":[{"name":"Control","weight":50,"is_control":true},{"name":"Split hero","weight":50,"content":{"title":"Summer Sale"}}]}`
+
+		t.Run("successfully create experiment", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CreateExperiment", mock.MatchedBy(func(request dto.CreateLandingExperimentRequest) bool {
+				return request.PageID == pageId.String() && len(request.Variants) == 2 && request.Variants[1].Content.Title == "Summer Sale"
+			})).Return(&models.LandingExperiment{ID: experimentId, Name: "Hero layout"}, nil)
+
+			req := httptest.NewRequest("POST", "/cms/experiments", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to create experiment: invalid variants", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CreateExperiment", mock.Anything).Return(nil, errs.ErrInvalidExperiment)
+
+			req := httptest.NewRequest("POST", "/cms/experiments", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("failed to create experiment: another one is running", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CreateExperiment", mock.Anything).Return(nil, errs.ErrExperimentRunning)
+
+			req := httptest.NewRequest("POST", "/cms/experiments", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/experiments HandleGetExperiments", func(t *testing.T) {
+		t.Run("successfully get the running experiments of a page", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindExperiments", &pageId, enums.ExperimentStatusRunning).Return([]models.LandingExperiment{{ID: experimentId, Name: "Hero layout"}}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/experiments?pageId="+pageId.String()+"&status=running", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), "Hero layout")
+		})
+
+		t.Run("failed to get experiments: invalid pageId", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/cms/experiments?pageId=abc", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "FindExperiments", mock.Anything, mock.Anything)
+		})
+	})
+
+	t.Run("GET /cms/experiments/:experimentId/results HandleGetExperimentResults", func(t *testing.T) {
+		t.Run("successfully get experiment results", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			pValue := 0.01
+			mockService.On("GetExperimentResults", experimentId).Return(&dto.ExperimentResults{
+				ExperimentID: experimentId,
+				Variants:     []dto.ExperimentVariantResult{{Name: "Split hero", ConversionRate: 0.08, PValue: &pValue, Significant: true}},
+			}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/experiments/"+experimentId.String()+"/results", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"significant":true`)
+		})
+
+		t.Run("failed to get experiment results: not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetExperimentResults", experimentId).Return(nil, gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("GET", "/cms/experiments/"+experimentId.String()+"/results", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /cms/experiments/:experimentId/stop HandleStopExperiment", func(t *testing.T) {
+		t.Run("successfully stop experiment", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("StopExperiment", experimentId).Return(nil)
+
+			req := httptest.NewRequest("POST", "/cms/experiments/"+experimentId.String()+"/stop", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("failed to stop experiment: not running", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("StopExperiment", experimentId).Return(errs.ErrExperimentNotRunning)
+
+			req := httptest.NewRequest("POST", "/cms/experiments/"+experimentId.String()+"/stop", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /cms/experiments/:experimentId/promote HandlePromoteWinner", func(t *testing.T) {
+		variantId := uuid.New()
+		body := `{"variant_id":"` + variantId.String() + `","revision":{"author":"editor","message":"Split hero won"}}`
+
+		t.Run("successfully promote the winner", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			request := dto.PromoteExperimentRequest{VariantID: variantId.String(), Revision: dto.CreateRevisionRequest{Author: "editor", Message: "Split hero won"}}
+			mockService.On("PromoteWinner", experimentId, request).Return(&models.LandingContent{Title: "Split hero"}, nil)
+
+			req := httptest.NewRequest("POST", "/cms/experiments/"+experimentId.String()+"/promote", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to promote the winner: already promoted", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("PromoteWinner", experimentId, mock.Anything).Return(nil, errs.ErrExperimentCompleted)
+
+			req := httptest.NewRequest("POST", "/cms/experiments/"+experimentId.String()+"/promote", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		})
+
+		t.Run("failed to promote the winner: critical audit findings", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("PromoteWinner", experimentId, mock.Anything).Return(nil, errs.ErrCriticalAuditFindings)
+
+			req := httptest.NewRequest("POST", "/cms/experiments/"+experimentId.String()+"/promote", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+		})
+	})
+}
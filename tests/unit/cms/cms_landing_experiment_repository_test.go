@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCMSRepo_LandingExperiment(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsLandingExperimentRepo := repo.NewCMSLandingExperimentRepository(gormDB)
+
+	pageId := uuid.New()
+	experimentId := uuid.New()
+	variantId := uuid.New()
+	now := time.Date(2025, 7, 18, 9, 0, 0, 0, time.UTC)
+
+	t.Run("successfully find the running experiment with its variants", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_experiments" WHERE page_id = $1 AND language = $2 AND status = $3 ORDER BY "landing_experiments"."id" LIMIT $4`)).
+			WithArgs(pageId, enums.PageLanguageEN, enums.ExperimentStatusRunning, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "language", "name", "status"}).
+				AddRow(experimentId, pageId, enums.PageLanguageEN, "Hero layout", enums.ExperimentStatusRunning))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_experiment_variants" WHERE "landing_experiment_variants"."experiment_id" = $1 ORDER BY landing_experiment_variants.is_control DESC, landing_experiment_variants.name`)).
+			WithArgs(experimentId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "experiment_id", "name", "weight", "is_control"}).
+				AddRow(uuid.New(), experimentId, "Control", 50, true).
+				AddRow(variantId, experimentId, "Split hero", 50, false))
+
+		experiment, err := cmsLandingExperimentRepo.FindRunningExperiment(pageId, enums.PageLanguageEN)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hero layout", experiment.Name)
+		assert.Len(t, experiment.Variants, 2)
+	})
+
+	t.Run("failed to find a running experiment: none", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_experiments" WHERE page_id = $1 AND language = $2 AND status = $3`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		experiment, err := cmsLandingExperimentRepo.FindRunningExperiment(pageId, enums.PageLanguageTH)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, experiment)
+	})
+
+	t.Run("successfully ignore a repeated event of the visitor", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "landing_experiment_events" ("experiment_id","variant_id","assignment_hash","type","created_at") VALUES ($1,$2,$3,$4,$5) ON CONFLICT DO NOTHING RETURNING "id"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+
+		err := cmsLandingExperimentRepo.CreateEvent(&models.LandingExperimentEvent{
+			ExperimentID:   experimentId,
+			VariantID:      variantId,
+			AssignmentHash: "hash",
+			Type:           enums.ExperimentEventExposure,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully count the visitors of each variant and event type", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT variant_id, type, COUNT(*) AS visitors FROM "landing_experiment_events" WHERE experiment_id = $1 GROUP BY variant_id, type`)).
+			WithArgs(experimentId).
+			WillReturnRows(sqlmock.NewRows([]string{"variant_id", "type", "visitors"}).
+				AddRow(variantId, enums.ExperimentEventExposure, 120).
+				AddRow(variantId, enums.ExperimentEventConversion, 9))
+
+		counts, err := cmsLandingExperimentRepo.CountEvents(experimentId)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.ExperimentEventCount{
+			{VariantID: variantId, Type: enums.ExperimentEventExposure, Visitors: 120},
+			{VariantID: variantId, Type: enums.ExperimentEventConversion, Visitors: 9},
+		}, counts)
+	})
+
+	t.Run("successfully record the winner of an experiment without one", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "landing_experiments" SET "ended_at"=COALESCE(ended_at, $1),"status"=$2,"winner_variant_id"=$3,"updated_at"=$4 WHERE id = $5 AND winner_variant_id IS NULL`)).
+			WithArgs(now, enums.ExperimentStatusCompleted, &variantId, sqlmock.AnyArg(), experimentId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		updated, err := cmsLandingExperimentRepo.CompleteExperiment(experimentId, &variantId, now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+	})
+
+	t.Run("successfully stop a running experiment", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`WHERE id = $5 AND status = $6`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		updated, err := cmsLandingExperimentRepo.CompleteExperiment(experimentId, nil, now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), updated)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,515 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type MockCMSLandingExperimentRepo struct {
+	createExperiment      func(experiment *models.LandingExperiment) error
+	findExperimentById    func(id uuid.UUID) (*models.LandingExperiment, error)
+	findExperiments       func(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error)
+	findRunningExperiment func(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error)
+	findPublishedContent  func(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error)
+	createEvent           func(event *models.LandingExperimentEvent) error
+	findExposure          func(experimentId uuid.UUID, assignmentHash string) (*models.LandingExperimentEvent, error)
+	countEvents           func(experimentId uuid.UUID) ([]dto.ExperimentEventCount, error)
+	completeExperiment    func(id uuid.UUID, winnerVariantId *uuid.UUID, endedAt time.Time) (int64, error)
+	findContentScopes     func(pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+func (m *MockCMSLandingExperimentRepo) CreateExperiment(experiment *models.LandingExperiment) error {
+	return m.createExperiment(experiment)
+}
+
+func (m *MockCMSLandingExperimentRepo) FindExperimentById(id uuid.UUID) (*models.LandingExperiment, error) {
+	return m.findExperimentById(id)
+}
+
+func (m *MockCMSLandingExperimentRepo) FindExperiments(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error) {
+	return m.findExperiments(pageId, status)
+}
+
+func (m *MockCMSLandingExperimentRepo) FindRunningExperiment(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error) {
+	return m.findRunningExperiment(pageId, language)
+}
+
+func (m *MockCMSLandingExperimentRepo) FindPublishedContent(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error) {
+	return m.findPublishedContent(pageId, language)
+}
+
+func (m *MockCMSLandingExperimentRepo) CreateEvent(event *models.LandingExperimentEvent) error {
+	return m.createEvent(event)
+}
+
+func (m *MockCMSLandingExperimentRepo) FindExposure(experimentId uuid.UUID, assignmentHash string) (*models.LandingExperimentEvent, error) {
+	return m.findExposure(experimentId, assignmentHash)
+}
+
+func (m *MockCMSLandingExperimentRepo) CountEvents(experimentId uuid.UUID) ([]dto.ExperimentEventCount, error) {
+	return m.countEvents(experimentId)
+}
+
+func (m *MockCMSLandingExperimentRepo) CompleteExperiment(id uuid.UUID, winnerVariantId *uuid.UUID, endedAt time.Time) (int64, error) {
+	return m.completeExperiment(id, winnerVariantId, endedAt)
+}
+
+func (m *MockCMSLandingExperimentRepo) FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageId)
+}
+
+func experimentTestConfig() *config.Config {
+	return &config.Config{SecretKey: config.SecretKeyConfig{HashSalt: "salt"}}
+}
+
+func newTestExperiment(controlWeight, variantWeight int) *models.LandingExperiment {
+	content, _ := json.Marshal(dto.LandingVariantContent{
+		Title:      "Split hero",
+		Components: []*models.Component{{Type: enums.ComponentBold}},
+	})
+	return &models.LandingExperiment{
+		ID:       uuid.New(),
+		PageID:   uuid.New(),
+		Language: enums.PageLanguageEN,
+		Name:     "Hero layout",
+		Status:   enums.ExperimentStatusRunning,
+		Variants: []*models.LandingExperimentVariant{
+			{ID: uuid.New(), Name: "Control", Weight: controlWeight, IsControl: true},
+			{ID: uuid.New(), Name: "Split hero", Weight: variantWeight, Content: datatypes.JSON(content)},
+		},
+	}
+}
+
+func TestCMSService_CreateLandingExperiment(t *testing.T) {
+	pageId := uuid.New()
+	request := dto.CreateLandingExperimentRequest{
+		PageID:   pageId.String(),
+		Language: "en",
+		Name:     "Hero layout",
+		Variants: []dto.CreateExperimentVariantRequest{
+			{Name: "Control", Weight: 50, IsControl: true},
+			{Name: "Split hero", Weight: 50, Content: &dto.LandingVariantContent{Title: "<b>Summer</b> Sale"}},
+		},
+	}
+
+	t.Run("successfully create an experiment with sanitized variant content", func(t *testing.T) {
+		var created *models.LandingExperiment
+		repo := &MockCMSLandingExperimentRepo{
+			findPublishedContent: func(id uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error) {
+				assert.Equal(t, pageId, id)
+				assert.Equal(t, enums.PageLanguageEN, language)
+				return &models.LandingContent{}, nil
+			},
+			findRunningExperiment: func(id uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			createExperiment: func(experiment *models.LandingExperiment) error {
+				created = experiment
+				return nil
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		experiment, err := service.CreateExperiment(request)
+		require.NoError(t, err)
+		assert.Equal(t, created, experiment)
+		assert.Equal(t, enums.ExperimentStatusRunning, experiment.Status)
+		assert.Len(t, experiment.Variants, 2)
+		assert.Nil(t, experiment.Variants[0].Content)
+		assert.JSONEq(t, `{"title":"Summer Sale"}`, string(experiment.Variants[1].Content))
+	})
+
+	t.Run("failed to create experiment: invalid variants", func(t *testing.T) {
+		service := services.NewCMSLandingExperimentService(&MockCMSLandingExperimentRepo{}, nil, experimentTestConfig())
+
+		invalid := [][]dto.CreateExperimentVariantRequest{
+			{{Name: "Control", Weight: 50, IsControl: true}},
+			{{Name: "A", Weight: 50, IsControl: true}, {Name: "B", Weight: 50, IsControl: true}},
+			{{Name: "Control", Weight: 50, IsControl: true}, {Name: "Split hero", Weight: 0, Content: &dto.LandingVariantContent{}}},
+			{{Name: "Control", Weight: 50, IsControl: true}, {Name: "Split hero", Weight: 50}},
+			{{Name: "Same", Weight: 50, IsControl: true}, {Name: "Same", Weight: 50, Content: &dto.LandingVariantContent{}}},
+		}
+		for _, variants := range invalid {
+			experiment, err := service.CreateExperiment(dto.CreateLandingExperimentRequest{PageID: pageId.String(), Language: "en", Name: "Hero", Variants: variants})
+			assert.ErrorIs(t, err, errs.ErrInvalidExperiment)
+			assert.Nil(t, experiment)
+		}
+	})
+
+	t.Run("failed to create experiment: another one is running", func(t *testing.T) {
+		repo := &MockCMSLandingExperimentRepo{
+			findPublishedContent: func(id uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error) {
+				return &models.LandingContent{}, nil
+			},
+			findRunningExperiment: func(id uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error) {
+				return &models.LandingExperiment{}, nil
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		_, err := service.CreateExperiment(request)
+		assert.ErrorIs(t, err, errs.ErrExperimentRunning)
+	})
+
+	t.Run("failed to create experiment: page language is not published", func(t *testing.T) {
+		repo := &MockCMSLandingExperimentRepo{
+			findPublishedContent: func(id uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		_, err := service.CreateExperiment(request)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestCMSService_AssignLandingVariant(t *testing.T) {
+	t.Run("successfully serve the same variant to the same visitor and record the exposure", func(t *testing.T) {
+		experiment := newTestExperiment(1, 1)
+		exposures := []*models.LandingExperimentEvent{}
+		repo := &MockCMSLandingExperimentRepo{
+			findRunningExperiment: func(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error) {
+				assert.Equal(t, experiment.PageID, pageId)
+				return experiment, nil
+			},
+			createEvent: func(event *models.LandingExperimentEvent) error {
+				exposures = append(exposures, event)
+				return nil
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		served := map[uuid.UUID]int{}
+		for i := 0; i < 50; i++ {
+			key := "cookie:" + uuid.NewString()
+			first, err := service.AssignVariant(&models.LandingContent{PageID: experiment.PageID, Language: enums.PageLanguageEN}, key)
+			require.NoError(t, err)
+			second, err := service.AssignVariant(&models.LandingContent{PageID: experiment.PageID, Language: enums.PageLanguageEN}, key)
+			require.NoError(t, err)
+			assert.Equal(t, first.VariantID, second.VariantID)
+			served[first.VariantID]++
+		}
+		assert.Len(t, served, 2)
+		assert.Len(t, exposures, 100)
+		assert.Equal(t, enums.ExperimentEventExposure, exposures[0].Type)
+		assert.Len(t, exposures[0].AssignmentHash, 64)
+	})
+
+	t.Run("successfully swap the content for the variant", func(t *testing.T) {
+		experiment := newTestExperiment(0, 1)
+		repo := &MockCMSLandingExperimentRepo{
+			findRunningExperiment: func(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error) {
+				return experiment, nil
+			},
+			createEvent: func(event *models.LandingExperimentEvent) error { return nil },
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		content := &models.LandingContent{ID: uuid.New(), Title: "Summer Sale", HTMLInput: "<p>Kept</p>"}
+		assignment, err := service.AssignVariant(content, "line:U1234")
+		require.NoError(t, err)
+		assert.Equal(t, "Split hero", assignment.VariantName)
+		assert.Equal(t, "Split hero", content.Title)
+		assert.Equal(t, "<p>Kept</p>", content.HTMLInput)
+		require.Len(t, content.Components, 1)
+		assert.Equal(t, content.ID, *content.Components[0].LandingContentID)
+	})
+
+	t.Run("successfully serve the content unchanged when no experiment is running", func(t *testing.T) {
+		repo := &MockCMSLandingExperimentRepo{
+			findRunningExperiment: func(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingExperiment, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		content := &models.LandingContent{Title: "Summer Sale"}
+		assignment, err := service.AssignVariant(content, "line:U1234")
+		assert.NoError(t, err)
+		assert.Nil(t, assignment)
+		assert.Equal(t, "Summer Sale", content.Title)
+	})
+}
+
+func TestCMSService_RecordLandingConversion(t *testing.T) {
+	experiment := newTestExperiment(1, 1)
+	variantId := experiment.Variants[1].ID
+
+	t.Run("successfully credit the variant the visitor was served", func(t *testing.T) {
+		var recorded *models.LandingExperimentEvent
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+			findExposure: func(experimentId uuid.UUID, assignmentHash string) (*models.LandingExperimentEvent, error) {
+				return &models.LandingExperimentEvent{VariantID: variantId, AssignmentHash: assignmentHash}, nil
+			},
+			createEvent: func(event *models.LandingExperimentEvent) error {
+				recorded = event
+				return nil
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		require.NoError(t, service.RecordConversion(experiment.ID, "line:U1234"))
+		assert.Equal(t, variantId, recorded.VariantID)
+		assert.Equal(t, enums.ExperimentEventConversion, recorded.Type)
+	})
+
+	t.Run("failed to record conversion: no variant was served", func(t *testing.T) {
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+			findExposure: func(experimentId uuid.UUID, assignmentHash string) (*models.LandingExperimentEvent, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		assert.ErrorIs(t, service.RecordConversion(experiment.ID, "line:U1234"), errs.ErrExperimentNotExposed)
+	})
+
+	t.Run("failed to record conversion: experiment is completed", func(t *testing.T) {
+		completed := newTestExperiment(1, 1)
+		completed.Status = enums.ExperimentStatusCompleted
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return completed, nil },
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		assert.ErrorIs(t, service.RecordConversion(completed.ID, "line:U1234"), errs.ErrExperimentNotRunning)
+	})
+}
+
+func TestCMSService_GetLandingExperimentResults(t *testing.T) {
+	experiment := newTestExperiment(1, 1)
+	controlId := experiment.Variants[0].ID
+	variantId := experiment.Variants[1].ID
+
+	t.Run("successfully flag a significant variant", func(t *testing.T) {
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+			countEvents: func(id uuid.UUID) ([]dto.ExperimentEventCount, error) {
+				return []dto.ExperimentEventCount{
+					{VariantID: controlId, Type: enums.ExperimentEventExposure, Visitors: 1000},
+					{VariantID: controlId, Type: enums.ExperimentEventConversion, Visitors: 50},
+					{VariantID: variantId, Type: enums.ExperimentEventExposure, Visitors: 1000},
+					{VariantID: variantId, Type: enums.ExperimentEventConversion, Visitors: 80},
+				}, nil
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		results, err := service.GetExperimentResults(experiment.ID)
+		require.NoError(t, err)
+		require.Len(t, results.Variants, 2)
+
+		control, variant := results.Variants[0], results.Variants[1]
+		assert.InDelta(t, 0.05, control.ConversionRate, 1e-9)
+		assert.Nil(t, control.PValue)
+		assert.InDelta(t, 0.08, variant.ConversionRate, 1e-9)
+		assert.InDelta(t, 0.6, *variant.Lift, 1e-9)
+		assert.InDelta(t, 0.0069, *variant.PValue, 0.0005)
+		assert.True(t, variant.Significant)
+	})
+
+	t.Run("successfully report a small difference as not significant", func(t *testing.T) {
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+			countEvents: func(id uuid.UUID) ([]dto.ExperimentEventCount, error) {
+				return []dto.ExperimentEventCount{
+					{VariantID: controlId, Type: enums.ExperimentEventExposure, Visitors: 100},
+					{VariantID: controlId, Type: enums.ExperimentEventConversion, Visitors: 5},
+					{VariantID: variantId, Type: enums.ExperimentEventExposure, Visitors: 100},
+					{VariantID: variantId, Type: enums.ExperimentEventConversion, Visitors: 6},
+				}, nil
+			},
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		results, err := service.GetExperimentResults(experiment.ID)
+		require.NoError(t, err)
+		assert.False(t, results.Variants[1].Significant)
+		assert.NotNil(t, results.Variants[1].PValue)
+	})
+
+	t.Run("successfully leave the p-value out without exposures", func(t *testing.T) {
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+			countEvents:        func(id uuid.UUID) ([]dto.ExperimentEventCount, error) { return nil, nil },
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		results, err := service.GetExperimentResults(experiment.ID)
+		require.NoError(t, err)
+		assert.Nil(t, results.Variants[1].PValue)
+		assert.Nil(t, results.Variants[1].Lift)
+		assert.False(t, results.Variants[1].Significant)
+	})
+}
+
+func TestCMSService_PromoteLandingExperimentWinner(t *testing.T) {
+	t.Run("successfully save the variant as a new content version and end the experiment", func(t *testing.T) {
+		experiment := newTestExperiment(1, 1)
+		winnerId := experiment.Variants[1].ID
+		published := &models.LandingContent{ID: uuid.New(), Title: "Summer Sale", PublishStatus: enums.PublishStatusPublished}
+
+		var completedWith *uuid.UUID
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+			findPublishedContent: func(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error) {
+				return published, nil
+			},
+			completeExperiment: func(id uuid.UUID, winnerVariantId *uuid.UUID, endedAt time.Time) (int64, error) {
+				completedWith = winnerVariantId
+				return 1, nil
+			},
+		}
+		landingService := new(MockLandingService)
+		landingService.On("UpdateLandingContent", mock.MatchedBy(func(content *models.LandingContent) bool {
+			return content.Title == "Split hero" && len(content.Components) == 1 &&
+				content.Revision.Message == "Promoted the winner of experiment Hero layout: Split hero" &&
+				content.Revision.PublishStatus == enums.PublishStatusPublished
+		}), published.ID).Return(&models.LandingContent{ID: uuid.New(), Title: "Split hero"}, nil)
+
+		service := services.NewCMSLandingExperimentService(repo, landingService, experimentTestConfig())
+
+		content, err := service.PromoteWinner(experiment.ID, dto.PromoteExperimentRequest{VariantID: winnerId.String(), Revision: dto.CreateRevisionRequest{Author: "editor"}})
+		require.NoError(t, err)
+		assert.Equal(t, "Split hero", content.Title)
+		assert.Equal(t, winnerId, *completedWith)
+		landingService.AssertExpectations(t)
+	})
+
+	t.Run("successfully promote the control without a new content version", func(t *testing.T) {
+		experiment := newTestExperiment(1, 1)
+		published := &models.LandingContent{ID: uuid.New()}
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+			findPublishedContent: func(pageId uuid.UUID, language enums.PageLanguage) (*models.LandingContent, error) {
+				return published, nil
+			},
+			completeExperiment: func(id uuid.UUID, winnerVariantId *uuid.UUID, endedAt time.Time) (int64, error) { return 1, nil },
+		}
+		landingService := new(MockLandingService)
+
+		service := services.NewCMSLandingExperimentService(repo, landingService, experimentTestConfig())
+
+		content, err := service.PromoteWinner(experiment.ID, dto.PromoteExperimentRequest{VariantID: experiment.Variants[0].ID.String()})
+		require.NoError(t, err)
+		assert.Equal(t, published, content)
+		landingService.AssertNotCalled(t, "UpdateLandingContent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed to promote: winner already promoted", func(t *testing.T) {
+		experiment := newTestExperiment(1, 1)
+		experiment.WinnerVariantID = &experiment.Variants[0].ID
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		_, err := service.PromoteWinner(experiment.ID, dto.PromoteExperimentRequest{VariantID: experiment.Variants[1].ID.String()})
+		assert.ErrorIs(t, err, errs.ErrExperimentCompleted)
+	})
+
+	t.Run("failed to promote without the publish permission: access denied", func(t *testing.T) {
+		experiment := newTestExperiment(1, 1)
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+			findContentScopes: func(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+				return []dto.PageContentScope{{ContentID: uuid.New(), Language: enums.PageLanguageEN, Mode: enums.PageModePublished}}, nil
+			},
+		}
+		landingService := new(MockLandingService)
+
+		service := services.NewCMSLandingExperimentService(repo, landingService, experimentTestConfig()).WithPageAccess(&dto.PageAccess{CanPublish: false})
+
+		_, err := service.PromoteWinner(experiment.ID, dto.PromoteExperimentRequest{VariantID: experiment.Variants[1].ID.String()})
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		landingService.AssertNotCalled(t, "UpdateLandingContent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed to promote: variant of another experiment", func(t *testing.T) {
+		experiment := newTestExperiment(1, 1)
+		repo := &MockCMSLandingExperimentRepo{
+			findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return experiment, nil },
+		}
+
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig())
+
+		_, err := service.PromoteWinner(experiment.ID, dto.PromoteExperimentRequest{VariantID: uuid.NewString()})
+		assert.ErrorIs(t, err, errs.ErrInvalidExperimentVariant)
+	})
+}
+
+func TestCMSService_LandingExperimentPageAccess(t *testing.T) {
+	english := enums.PageLanguageEN
+	// The user may only touch english landing pages
+	access := &dto.PageAccess{Grants: []models.PageGrant{
+		{PageType: enums.PageTypeLanding, Action: enums.PermissionActionAll, Language: &english},
+	}}
+	englishExperiment := newTestExperiment(1, 1)
+	thaiExperiment := newTestExperiment(1, 1)
+	thaiExperiment.Language = enums.PageLanguageTH
+	repo := &MockCMSLandingExperimentRepo{
+		findExperiments: func(pageId *uuid.UUID, status enums.ExperimentStatus) ([]models.LandingExperiment, error) {
+			return []models.LandingExperiment{*englishExperiment, *thaiExperiment}, nil
+		},
+		findExperimentById: func(id uuid.UUID) (*models.LandingExperiment, error) { return thaiExperiment, nil },
+		findContentScopes: func(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+			if pageId == thaiExperiment.PageID {
+				return []dto.PageContentScope{{ContentID: uuid.New(), Language: enums.PageLanguageTH, Mode: enums.PageModePublished}}, nil
+			}
+			return []dto.PageContentScope{{ContentID: uuid.New(), Language: enums.PageLanguageEN, Mode: enums.PageModePublished}}, nil
+		},
+	}
+
+	t.Run("successfully leave the experiments outside the grants out of the list", func(t *testing.T) {
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig()).WithPageAccess(access)
+
+		experiments, err := service.FindExperiments(nil, "")
+		require.NoError(t, err)
+		require.Len(t, experiments, 1)
+		assert.Equal(t, englishExperiment.ID, experiments[0].ID)
+	})
+
+	t.Run("failed to stop an experiment outside the grants: access denied", func(t *testing.T) {
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig()).WithPageAccess(access)
+
+		assert.ErrorIs(t, service.StopExperiment(thaiExperiment.ID), errs.ErrPageAccessDenied)
+	})
+
+	t.Run("failed to get results without a page access: access denied", func(t *testing.T) {
+		service := services.NewCMSLandingExperimentService(repo, nil, experimentTestConfig()).WithPageAccess(nil)
+
+		_, err := service.GetExperimentResults(englishExperiment.ID)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+}
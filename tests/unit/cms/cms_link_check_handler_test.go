@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCMSLinkCheckService struct {
+	mock.Mock
+}
+
+func (m *MockCMSLinkCheckService) RunLinkCheck(ctx context.Context) (*dto.LinkCheckRunSummary, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LinkCheckRunSummary), args.Error(1)
+}
+
+func (m *MockCMSLinkCheckService) IsLinkCheckRunning() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockCMSLinkCheckService) FindLinkChecks(query dto.LinkCheckQuery, page, limit int) ([]dto.LinkCheckResponse, int64, error) {
+	args := m.Called(query, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]dto.LinkCheckResponse), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCMSLinkCheckService) CheckRunAccess() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockCMSLinkCheckService) WithPageAccess(access *dto.PageAccess) services.CMSLinkCheckServiceInterface {
+	return m
+}
+
+func TestCMSLinkCheckHandler(t *testing.T) {
+	mockService := &MockCMSLinkCheckService{}
+	handler := cmsHandler.NewCMSLinkCheckHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/link-checks", handler.HandleGetLinkChecks)
+	app.Post("/cms/link-checks/run", handler.HandleRunLinkCheck)
+
+	t.Run("GET /cms/link-checks HandleGetLinkChecks", func(t *testing.T) {
+		t.Run("successfully get link checks", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			isInternal := false
+			query := dto.LinkCheckQuery{Status: enums.LinkStatusBroken, IsInternal: &isInternal}
+			mockService.On("FindLinkChecks", query, 2, 5).Return([]dto.LinkCheckResponse{{Status: enums.LinkStatusBroken}}, int64(6), nil)
+
+			req := httptest.NewRequest("GET", "/cms/link-checks?status=broken&isInternal=false&page=2&limit=5", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get link checks: invalid filter", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindLinkChecks", mock.Anything, 1, 10).Return(nil, int64(0), errs.ErrInvalidLinkStatus)
+
+			req := httptest.NewRequest("GET", "/cms/link-checks?status=unknown", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get link checks: internal server error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindLinkChecks", mock.Anything, 1, 10).Return(nil, int64(0), errs.ErrInternalServerError)
+
+			req := httptest.NewRequest("GET", "/cms/link-checks", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	})
+
+	t.Run("POST /cms/link-checks/run HandleRunLinkCheck", func(t *testing.T) {
+		t.Run("successfully start link check", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			done := make(chan struct{})
+			mockService.On("CheckRunAccess").Return(nil)
+			mockService.On("IsLinkCheckRunning").Return(false)
+			mockService.On("RunLinkCheck", mock.Anything).Run(func(args mock.Arguments) {
+				close(done)
+			}).Return(&dto.LinkCheckRunSummary{}, nil)
+
+			req := httptest.NewRequest("POST", "/cms/link-checks/run", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+			<-done
+		})
+
+		t.Run("failed to start link check: already in progress", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CheckRunAccess").Return(nil)
+			mockService.On("IsLinkCheckRunning").Return(true)
+
+			req := httptest.NewRequest("POST", "/cms/link-checks/run", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to start link check: page access does not cover every page", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+			mockService.On("CheckRunAccess").Return(errs.ErrPageAccessDenied)
+
+			req := httptest.NewRequest("POST", "/cms/link-checks/run", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+			mockService.AssertNotCalled(t, "RunLinkCheck", mock.Anything)
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCMSRepo_UpsertLinkChecks(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsLinkCheckRepo := repo.NewCMSLinkCheckRepository(gormDB)
+
+	linkChecks := []models.LinkCheck{{
+		PageType:      enums.PageTypePartner,
+		PageID:        uuid.New(),
+		ContentID:     uuid.New(),
+		Field:         "html_input",
+		URL:           "https://example.com",
+		Status:        enums.LinkStatusOK,
+		LastCheckedAt: time.Now(),
+	}}
+
+	t.Run("successfully upsert link checks", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "link_checks"`) + `.*` + regexp.QuoteMeta(`ON CONFLICT ("content_id","field","url") DO UPDATE`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectCommit()
+
+		err := cmsLinkCheckRepo.UpsertLinkChecks(linkChecks)
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully skip empty link checks", func(t *testing.T) {
+		err := cmsLinkCheckRepo.UpsertLinkChecks(nil)
+		assert.NoError(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_IsContentPathRegistered(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsLinkCheckRepo := repo.NewCMSLinkCheckRepository(gormDB)
+
+	t.Run("successfully find registered partner url", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "landing_contents" WHERE TRIM(BOTH '/' FROM url_alias) = $1`)).
+			WithArgs("partners/alive", enums.WorkflowPublished, enums.PageModeHistories).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "partner_contents" WHERE TRIM(BOTH '/' FROM url) = $1`)).
+			WithArgs("partners/alive", enums.WorkflowPublished, enums.PageModeHistories).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		registered, err := cmsLinkCheckRepo.IsContentPathRegistered("/partners/alive/")
+		assert.NoError(t, err)
+		assert.True(t, registered)
+	})
+
+	t.Run("failed to check content path", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "landing_contents"`)).
+			WillReturnError(errs.ErrInternalServerError)
+
+		registered, err := cmsLinkCheckRepo.IsContentPathRegistered("partners/alive")
+		assert.Error(t, err)
+		assert.False(t, registered)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FindLinkChecks(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsLinkCheckRepo := repo.NewCMSLinkCheckRepository(gormDB)
+
+	pageId := uuid.New()
+
+	t.Run("successfully find link checks by page", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "link_checks" WHERE page_id = $1 AND status = $2`)).
+			WithArgs(pageId.String(), enums.LinkStatusBroken).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "link_checks" WHERE page_id = $1 AND status = $2 ORDER BY last_checked_at DESC, url ASC LIMIT $3`)).
+			WithArgs(pageId.String(), enums.LinkStatusBroken, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "status"}).AddRow(uuid.New(), pageId, enums.LinkStatusBroken))
+
+		linkChecks, totalCount, err := cmsLinkCheckRepo.FindLinkChecks(dto.LinkCheckQuery{PageID: pageId.String(), Status: enums.LinkStatusBroken}, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), totalCount)
+		assert.Len(t, linkChecks, 1)
+	})
+
+	t.Run("successfully limit the link checks to the page grants", func(t *testing.T) {
+		english := enums.PageLanguageEN
+		access := &dto.PageAccess{Grants: []models.PageGrant{
+			{PageType: enums.PageTypeLanding, Action: enums.PermissionActionRead, Language: &english},
+		}}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "link_checks" WHERE (`+
+			`(link_checks.page_type = $1 AND link_checks.content_id IN (SELECT landing_contents.id FROM "landing_contents" WHERE ((landing_contents.language = $2)))) OR `+
+			`(link_checks.page_type = $3 AND link_checks.content_id IN (SELECT partner_contents.id FROM "partner_contents" WHERE FALSE)) OR `+
+			`(link_checks.page_type = $4 AND link_checks.content_id IN (SELECT faq_contents.id FROM "faq_contents" WHERE FALSE)))`)).
+			WithArgs(enums.PageTypeLanding, english, enums.PageTypePartner, enums.PageTypeFaq).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "link_checks" WHERE (`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err := cmsLinkCheckRepo.FindLinkChecks(dto.LinkCheckQuery{Access: access}, 1, 10)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed to find link checks", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "link_checks"`)).
+			WillReturnError(errs.ErrInternalServerError)
+
+		linkChecks, totalCount, err := cmsLinkCheckRepo.FindLinkChecks(dto.LinkCheckQuery{}, 1, 10)
+		assert.Error(t, err)
+		assert.Zero(t, totalCount)
+		assert.Nil(t, linkChecks)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
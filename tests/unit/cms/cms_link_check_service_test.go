@@ -0,0 +1,351 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCMSLinkCheckRepo struct {
+	findPublishedLandingContents  func() ([]models.LandingContent, error)
+	findPublishedPartnerContents  func() ([]models.PartnerContent, error)
+	findPublishedFaqContents      func() ([]models.FaqContent, error)
+	isContentPathRegistered       func(urlPath string) (bool, error)
+	isMediaFileRegistered         func(downloadURL string) (bool, error)
+	upsertLinkChecks              func(linkChecks []models.LinkCheck) error
+	deleteLinkChecksCheckedBefore func(checkedBefore time.Time) error
+	findLinkChecks                func(query dto.LinkCheckQuery, page, limit int) ([]models.LinkCheck, int64, error)
+}
+
+func (m *MockCMSLinkCheckRepo) FindPublishedLandingContents() ([]models.LandingContent, error) {
+	return m.findPublishedLandingContents()
+}
+
+func (m *MockCMSLinkCheckRepo) FindPublishedPartnerContents() ([]models.PartnerContent, error) {
+	return m.findPublishedPartnerContents()
+}
+
+func (m *MockCMSLinkCheckRepo) FindPublishedFaqContents() ([]models.FaqContent, error) {
+	return m.findPublishedFaqContents()
+}
+
+func (m *MockCMSLinkCheckRepo) IsContentPathRegistered(urlPath string) (bool, error) {
+	return m.isContentPathRegistered(urlPath)
+}
+
+func (m *MockCMSLinkCheckRepo) IsMediaFileRegistered(downloadURL string) (bool, error) {
+	return m.isMediaFileRegistered(downloadURL)
+}
+
+func (m *MockCMSLinkCheckRepo) UpsertLinkChecks(linkChecks []models.LinkCheck) error {
+	return m.upsertLinkChecks(linkChecks)
+}
+
+func (m *MockCMSLinkCheckRepo) DeleteLinkChecksCheckedBefore(checkedBefore time.Time) error {
+	return m.deleteLinkChecksCheckedBefore(checkedBefore)
+}
+
+func (m *MockCMSLinkCheckRepo) FindLinkChecks(query dto.LinkCheckQuery, page, limit int) ([]models.LinkCheck, int64, error) {
+	return m.findLinkChecks(query, page, limit)
+}
+
+// newLinkStandIn serves the external links used by the link checker tests
+func newLinkStandIn(t *testing.T, requests *int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newLinkCheckConfig() *config.Config {
+	cfg := config.New()
+	cfg.App.WebBaseURL = "https://www.example.com"
+	cfg.App.APIBaseURL = "https://api.example.com"
+	cfg.App.StaticFilePrefix = "/files"
+	cfg.LinkCheck.Timeout = 200 * time.Millisecond
+	cfg.LinkCheck.Concurrency = 2
+	// The stand-in listens on loopback
+	cfg.LinkCheck.AllowPrivateHosts = true
+	return cfg
+}
+
+func TestCMSService_RunLinkCheck(t *testing.T) {
+	t.Run("successfully check internal and external links", func(t *testing.T) {
+		var requests int32
+		server := newLinkStandIn(t, &requests)
+
+		pageId := uuid.New()
+		contentId := uuid.New()
+		partnerContent := models.PartnerContent{
+			ID:       contentId,
+			PageID:   pageId,
+			Language: enums.PageLanguageEN,
+			HTMLInput: `<a href="` + server.URL + `/ok">ok</a>
+				<a href="` + server.URL + `/gone">gone</a>
+				<a href="` + server.URL + `/get-only">get only</a>
+				<a href="` + server.URL + `/slow">slow</a>
+				<a href="/en/partners/alive">alive</a>
+				<a href="https://www.example.com/th/partners/dead">dead</a>
+				<img src="https://api.example.com/files/images/logo.png">
+				<a href="mailto:someone@example.com">mail</a>
+				<a href="#top">top</a>`,
+			Components: []*models.Component{
+				{Props: []byte(`{"link":"` + server.URL + `/ok","label":"Read more"}`)},
+			},
+		}
+
+		var upserted []models.LinkCheck
+		var deletedBefore time.Time
+		repo := &MockCMSLinkCheckRepo{
+			findPublishedLandingContents: func() ([]models.LandingContent, error) {
+				return nil, nil
+			},
+			findPublishedPartnerContents: func() ([]models.PartnerContent, error) {
+				return []models.PartnerContent{partnerContent}, nil
+			},
+			findPublishedFaqContents: func() ([]models.FaqContent, error) {
+				return nil, nil
+			},
+			isContentPathRegistered: func(urlPath string) (bool, error) {
+				return urlPath == "partners/alive", nil
+			},
+			isMediaFileRegistered: func(downloadURL string) (bool, error) {
+				assert.Equal(t, "https://api.example.com/files/images/logo.png", downloadURL)
+				return true, nil
+			},
+			upsertLinkChecks: func(linkChecks []models.LinkCheck) error {
+				upserted = linkChecks
+				return nil
+			},
+			deleteLinkChecksCheckedBefore: func(checkedBefore time.Time) error {
+				deletedBefore = checkedBefore
+				return nil
+			},
+		}
+
+		service := services.NewCMSLinkCheckService(repo, newLinkCheckConfig())
+
+		summary, err := service.RunLinkCheck(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.ContentsScanned)
+		assert.Equal(t, 8, summary.LinksChecked)
+		assert.Equal(t, 2, summary.Broken)
+		assert.Equal(t, 1, summary.Errors)
+		assert.Equal(t, summary.StartedAt, deletedBefore)
+
+		statuses := map[string]models.LinkCheck{}
+		for _, linkCheck := range upserted {
+			statuses[linkCheck.Field+" "+linkCheck.URL] = linkCheck
+			assert.Equal(t, pageId, linkCheck.PageID)
+			assert.Equal(t, enums.PageTypePartner, linkCheck.PageType)
+		}
+		assert.Equal(t, enums.LinkStatusOK, statuses["html_input "+server.URL+"/ok"].Status)
+		assert.Equal(t, enums.LinkStatusBroken, statuses["html_input "+server.URL+"/gone"].Status)
+		assert.Equal(t, http.StatusNotFound, statuses["html_input "+server.URL+"/gone"].StatusCode)
+		assert.Equal(t, enums.LinkStatusOK, statuses["html_input "+server.URL+"/get-only"].Status)
+		assert.Equal(t, enums.LinkStatusError, statuses["html_input "+server.URL+"/slow"].Status)
+		assert.True(t, statuses["html_input /en/partners/alive"].IsInternal)
+		assert.Equal(t, enums.LinkStatusOK, statuses["html_input /en/partners/alive"].Status)
+		assert.Equal(t, enums.LinkStatusBroken, statuses["html_input https://www.example.com/th/partners/dead"].Status)
+		assert.Equal(t, enums.LinkStatusOK, statuses["html_input https://api.example.com/files/images/logo.png"].Status)
+		assert.Equal(t, enums.LinkStatusOK, statuses["components[0] "+server.URL+"/ok"].Status)
+
+		// The /ok link appears twice but is only requested once, /get-only needs HEAD then GET
+		assert.Equal(t, int32(5), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("successfully run link check: private addresses are not requested", func(t *testing.T) {
+		var requests int32
+		server := newLinkStandIn(t, &requests)
+
+		faqContent := models.FaqContent{
+			ID:        uuid.New(),
+			PageID:    uuid.New(),
+			Language:  enums.PageLanguageEN,
+			HTMLInput: `<a href="` + server.URL + `/ok">ok</a><a href="http://169.254.169.254/latest/meta-data">metadata</a>`,
+		}
+
+		var upserted []models.LinkCheck
+		repo := &MockCMSLinkCheckRepo{
+			findPublishedLandingContents: func() ([]models.LandingContent, error) {
+				return nil, nil
+			},
+			findPublishedPartnerContents: func() ([]models.PartnerContent, error) {
+				return nil, nil
+			},
+			findPublishedFaqContents: func() ([]models.FaqContent, error) {
+				return []models.FaqContent{faqContent}, nil
+			},
+			upsertLinkChecks: func(linkChecks []models.LinkCheck) error {
+				upserted = linkChecks
+				return nil
+			},
+			deleteLinkChecksCheckedBefore: func(checkedBefore time.Time) error {
+				return nil
+			},
+		}
+
+		cfg := newLinkCheckConfig()
+		cfg.LinkCheck.AllowPrivateHosts = false
+		service := services.NewCMSLinkCheckService(repo, cfg)
+
+		summary, err := service.RunLinkCheck(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.Errors)
+		require.Len(t, upserted, 2)
+		for _, linkCheck := range upserted {
+			assert.Equal(t, enums.LinkStatusError, linkCheck.Status)
+			assert.Contains(t, linkCheck.ErrorMessage, "private")
+		}
+		assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("failed to run link check: repository error", func(t *testing.T) {
+		repo := &MockCMSLinkCheckRepo{
+			findPublishedLandingContents: func() ([]models.LandingContent, error) {
+				return nil, errs.ErrInternalServerError
+			},
+		}
+
+		service := services.NewCMSLinkCheckService(repo, newLinkCheckConfig())
+
+		summary, err := service.RunLinkCheck(context.Background())
+		assert.Error(t, err)
+		assert.Nil(t, summary)
+		assert.False(t, service.IsLinkCheckRunning())
+	})
+
+	t.Run("failed to run link check: already in progress", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		repo := &MockCMSLinkCheckRepo{
+			findPublishedLandingContents: func() ([]models.LandingContent, error) {
+				close(started)
+				<-release
+				return nil, errs.ErrInternalServerError
+			},
+		}
+
+		service := services.NewCMSLinkCheckService(repo, newLinkCheckConfig())
+
+		go service.RunLinkCheck(context.Background())
+		<-started
+
+		summary, err := service.RunLinkCheck(context.Background())
+		assert.ErrorIs(t, err, errs.ErrLinkCheckInProgress)
+		assert.Nil(t, summary)
+		close(release)
+	})
+}
+
+func TestCMSService_LinkCheckRunAccess(t *testing.T) {
+	english := enums.PageLanguageEN
+
+	t.Run("successfully allow a run with access to every page", func(t *testing.T) {
+		service := services.NewCMSLinkCheckService(&MockCMSLinkCheckRepo{}, newLinkCheckConfig())
+
+		assert.NoError(t, service.CheckRunAccess())
+		assert.NoError(t, service.WithPageAccess(&dto.PageAccess{}).CheckRunAccess())
+	})
+
+	t.Run("failed to run link check: access limited to some pages", func(t *testing.T) {
+		access := &dto.PageAccess{Grants: []models.PageGrant{
+			{PageType: enums.PageTypeLanding, Action: enums.PermissionActionAll, Language: &english},
+		}}
+		service := services.NewCMSLinkCheckService(&MockCMSLinkCheckRepo{}, newLinkCheckConfig()).WithPageAccess(access)
+
+		summary, err := service.RunLinkCheck(context.Background())
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+		assert.Nil(t, summary)
+	})
+}
+
+func TestCMSService_FindLinkChecks(t *testing.T) {
+	t.Run("successfully find link checks", func(t *testing.T) {
+		pageId := uuid.New()
+		repo := &MockCMSLinkCheckRepo{
+			findLinkChecks: func(query dto.LinkCheckQuery, page, limit int) ([]models.LinkCheck, int64, error) {
+				assert.Equal(t, pageId.String(), query.PageID)
+				assert.Equal(t, enums.LinkStatusBroken, query.Status)
+				return []models.LinkCheck{{ID: uuid.New(), PageID: pageId, Status: enums.LinkStatusBroken}}, 1, nil
+			},
+		}
+
+		service := services.NewCMSLinkCheckService(repo, newLinkCheckConfig())
+
+		results, totalCount, err := service.FindLinkChecks(dto.LinkCheckQuery{PageID: pageId.String(), Status: enums.LinkStatusBroken}, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), totalCount)
+		assert.Equal(t, pageId.String(), results[0].PageID)
+	})
+
+	t.Run("successfully limit the list to the page grants", func(t *testing.T) {
+		english := enums.PageLanguageEN
+		access := &dto.PageAccess{Grants: []models.PageGrant{
+			{PageType: enums.PageTypeLanding, Action: enums.PermissionActionRead, Language: &english},
+		}}
+		repo := &MockCMSLinkCheckRepo{
+			findLinkChecks: func(query dto.LinkCheckQuery, page, limit int) ([]models.LinkCheck, int64, error) {
+				assert.Equal(t, access, query.Access)
+				return nil, 0, nil
+			},
+		}
+
+		service := services.NewCMSLinkCheckService(repo, newLinkCheckConfig()).WithPageAccess(access)
+
+		_, _, err := service.FindLinkChecks(dto.LinkCheckQuery{}, 1, 10)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed to find link checks: invalid filters", func(t *testing.T) {
+		service := services.NewCMSLinkCheckService(&MockCMSLinkCheckRepo{}, newLinkCheckConfig())
+
+		_, _, err := service.FindLinkChecks(dto.LinkCheckQuery{PageID: "invalid"}, 1, 10)
+		assert.ErrorIs(t, err, errs.ErrInvalidUUIDFormat)
+
+		_, _, err = service.FindLinkChecks(dto.LinkCheckQuery{Status: "unknown"}, 1, 10)
+		assert.ErrorIs(t, err, errs.ErrInvalidLinkStatus)
+
+		_, _, err = service.FindLinkChecks(dto.LinkCheckQuery{PageType: "unknown"}, 1, 10)
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+	})
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCMSMaintenanceService struct {
+	mock.Mock
+}
+
+func (m *MockCMSMaintenanceService) RunMaintenance(ctx context.Context, dryRun bool) (*dto.MaintenanceReport, error) {
+	args := m.Called(ctx, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MaintenanceReport), args.Error(1)
+}
+
+func (m *MockCMSMaintenanceService) IsMaintenanceRunning() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockCMSMaintenanceService) GetMaintenanceMetrics() dto.MaintenanceMetrics {
+	args := m.Called()
+	return args.Get(0).(dto.MaintenanceMetrics)
+}
+
+func TestCMSMaintenanceHandler(t *testing.T) {
+	mockService := &MockCMSMaintenanceService{}
+	handler := cmsHandler.NewCMSMaintenanceHandler(mockService)
+
+	app := fiber.New()
+	app.Post("/cms/maintenance/cleanup", handler.HandleRunMaintenance)
+	app.Get("/cms/maintenance/metrics", handler.HandleGetMaintenanceMetrics)
+
+	t.Run("POST /cms/maintenance/cleanup HandleRunMaintenance", func(t *testing.T) {
+		t.Run("successfully run a dry run", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			report := &dto.MaintenanceReport{DryRun: true, Removed: map[string]int64{"meta_tags": 2}}
+			mockService.On("RunMaintenance", mock.Anything, true).Return(report, nil)
+
+			req := httptest.NewRequest("POST", "/cms/maintenance/cleanup?dryRun=true", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var body dto.CMSMaintenanceReportSuccessResponse200
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.True(t, body.Data.DryRun)
+			assert.Equal(t, int64(2), body.Data.Removed["meta_tags"])
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to run maintenance cleanup: already in progress", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RunMaintenance", mock.Anything, false).Return(nil, errs.ErrMaintenanceInProgress)
+
+			req := httptest.NewRequest("POST", "/cms/maintenance/cleanup", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		})
+
+		t.Run("failed to run maintenance cleanup: internal error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RunMaintenance", mock.Anything, false).Return(nil, errs.ErrInternalServerError)
+
+			req := httptest.NewRequest("POST", "/cms/maintenance/cleanup", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/maintenance/metrics HandleGetMaintenanceMetrics", func(t *testing.T) {
+		t.Run("successfully get maintenance metrics", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetMaintenanceMetrics").Return(dto.MaintenanceMetrics{Runs: 3, TotalRemoved: map[string]int64{"components": 9}})
+
+			req := httptest.NewRequest("GET", "/cms/maintenance/metrics", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var body dto.CMSMaintenanceMetricsSuccessResponse200
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, 3, body.Data.Runs)
+			assert.Equal(t, int64(9), body.Data.TotalRemoved["components"])
+		})
+	})
+}
@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCMSRepo_FindExpiredPreviewContentIds(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsMaintenanceRepo := repo.NewCMSMaintenanceRepository(gormDB)
+	now := time.Now()
+
+	t.Run("successfully find expired preview contents", func(t *testing.T) {
+		contentId := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "faq_contents" WHERE mode = $1 AND expired_at < $2`)).
+			WithArgs(enums.PageModePreview, now).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(contentId))
+
+		contentIds, err := cmsMaintenanceRepo.FindExpiredPreviewContentIds(enums.PageTypeFaq, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{contentId}, contentIds)
+	})
+
+	t.Run("failed to find expired preview contents: invalid page type", func(t *testing.T) {
+		contentIds, err := cmsMaintenanceRepo.FindExpiredPreviewContentIds("unknown", now)
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+		assert.Nil(t, contentIds)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FindExcessHistoryContentIds(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsMaintenanceRepo := repo.NewCMSMaintenanceRepository(gormDB)
+
+	t.Run("successfully find history versions beyond the retention", func(t *testing.T) {
+		contentId := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`PARTITION BY page_id, language ORDER BY created_at DESC) AS version
+		FROM partner_contents WHERE mode = $1`)).
+			WithArgs(enums.PageModeHistories, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(contentId))
+
+		contentIds, err := cmsMaintenanceRepo.FindExcessHistoryContentIds(enums.PageTypePartner, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{contentId}, contentIds)
+	})
+
+	t.Run("successfully keep every history version", func(t *testing.T) {
+		contentIds, err := cmsMaintenanceRepo.FindExcessHistoryContentIds(enums.PageTypePartner, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, contentIds)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_PurgeContents(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsMaintenanceRepo := repo.NewCMSMaintenanceRepository(gormDB)
+	contentId := uuid.New()
+	metaTagId := uuid.New()
+	pageId := uuid.New()
+
+	expectPurge := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "meta_tag_id" FROM "landing_contents" WHERE id IN ($1)`)).
+			WithArgs(contentId).
+			WillReturnRows(sqlmock.NewRows([]string{"meta_tag_id"}).AddRow(metaTagId))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT page_id,language FROM "landing_contents" WHERE id IN ($1)`)).
+			WithArgs(contentId).
+			WillReturnRows(sqlmock.NewRows([]string{"page_id", "language"}).AddRow(pageId, "en"))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM components WHERE landing_content_id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM landing_content_categories WHERE landing_content_id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM revisions WHERE landing_content_id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM landing_content_files WHERE landing_content_id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM preview_links WHERE content_id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM content_autosaves WHERE base_content_id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM link_checks WHERE content_id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM content_relations WHERE source_content_id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM landing_contents WHERE id IN ($1)`)).
+			WithArgs(contentId).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM meta_tags WHERE id IN ($1)`)).
+			WithArgs(metaTagId).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM page_views WHERE page_type = $1 AND (page_views.page_id, page_views.language) IN (($2,$3))`)).
+			WithArgs(enums.PageTypeLanding, pageId, enums.PageLanguageEN).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM page_view_dailies WHERE page_type = $1 AND (page_view_dailies.page_id, page_view_dailies.language) IN (($2,$3))`)).
+			WithArgs(enums.PageTypeLanding, pageId, enums.PageLanguageEN).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM page_visitor_dailies WHERE page_type = $1 AND (page_visitor_dailies.page_id, page_visitor_dailies.language) IN (($2,$3))`)).
+			WithArgs(enums.PageTypeLanding, pageId, enums.PageLanguageEN).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM landing_experiment_events WHERE experiment_id IN (SELECT id FROM landing_experiments WHERE (landing_experiments.page_id, landing_experiments.language) IN (($1,$2))`)).
+			WithArgs(pageId, enums.PageLanguageEN).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM landing_experiment_variants WHERE experiment_id IN (SELECT id FROM landing_experiments WHERE`)).
+			WithArgs(pageId, enums.PageLanguageEN).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM landing_experiments WHERE (landing_experiments.page_id, landing_experiments.language) IN (($1,$2))
+			AND NOT EXISTS (SELECT 1 FROM landing_contents WHERE landing_contents.page_id = landing_experiments.page_id`)).
+			WithArgs(pageId, enums.PageLanguageEN).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("successfully purge contents", func(t *testing.T) {
+		expectPurge()
+		mock.ExpectCommit()
+
+		removed, err := cmsMaintenanceRepo.PurgeContents(enums.PageTypeLanding, []uuid.UUID{contentId}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), removed["components"])
+		assert.Equal(t, int64(2), removed["landing_content_files"])
+		assert.Equal(t, int64(1), removed["landing_contents"])
+		assert.Equal(t, int64(1), removed["meta_tags"])
+		assert.Equal(t, int64(1), removed["content_autosaves"])
+		assert.Equal(t, int64(2), removed["link_checks"])
+		assert.Equal(t, int64(2), removed["content_relations"])
+	})
+
+	t.Run("successfully count contents in a dry run", func(t *testing.T) {
+		expectPurge()
+		mock.ExpectRollback()
+
+		removed, err := cmsMaintenanceRepo.PurgeContents(enums.PageTypeLanding, []uuid.UUID{contentId}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), removed["landing_contents"])
+	})
+
+	t.Run("failed to purge contents: database error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "meta_tag_id" FROM "landing_contents"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"meta_tag_id"}))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT page_id,language FROM "landing_contents"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"page_id", "language"}))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM components`)).
+			WillReturnError(errs.ErrInternalServerError)
+		mock.ExpectRollback()
+
+		removed, err := cmsMaintenanceRepo.PurgeContents(enums.PageTypeLanding, []uuid.UUID{contentId}, false)
+		assert.Error(t, err)
+		assert.Nil(t, removed)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_PurgeOrphans(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsMaintenanceRepo := repo.NewCMSMaintenanceRepository(gormDB)
+	createdBefore := time.Now().Add(-time.Hour)
+
+	t.Run("successfully purge orphaned rows", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM components WHERE created_at < $1 AND NOT EXISTS`)).
+			WithArgs(createdBefore).WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM revisions WHERE created_at < $1 AND NOT EXISTS`)).
+			WithArgs(createdBefore).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM meta_tags WHERE created_at < $1 AND NOT EXISTS`)).
+			WithArgs(createdBefore).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM content_relations WHERE created_at < $1 AND ((NOT EXISTS (SELECT 1 FROM landing_contents WHERE content_relations.source_page_type = 'landing' AND landing_contents.id = content_relations.source_content_id)`)).
+			WithArgs(createdBefore).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		removed, err := cmsMaintenanceRepo.PurgeOrphans(createdBefore, false)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"components": 4, "revisions": 0, "meta_tags": 2, "content_relations": 1}, removed)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
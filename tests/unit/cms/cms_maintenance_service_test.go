@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCMSMaintenanceRepo struct {
+	findExpiredPreviewContentIds func(pageType enums.PageType, now time.Time) ([]uuid.UUID, error)
+	findExcessHistoryContentIds  func(pageType enums.PageType, keep int) ([]uuid.UUID, error)
+	purgeContents                func(pageType enums.PageType, contentIds []uuid.UUID, dryRun bool) (map[string]int64, error)
+	purgeOrphans                 func(createdBefore time.Time, dryRun bool) (map[string]int64, error)
+}
+
+func (m *MockCMSMaintenanceRepo) FindExpiredPreviewContentIds(pageType enums.PageType, now time.Time) ([]uuid.UUID, error) {
+	return m.findExpiredPreviewContentIds(pageType, now)
+}
+
+func (m *MockCMSMaintenanceRepo) FindExcessHistoryContentIds(pageType enums.PageType, keep int) ([]uuid.UUID, error) {
+	return m.findExcessHistoryContentIds(pageType, keep)
+}
+
+func (m *MockCMSMaintenanceRepo) PurgeContents(pageType enums.PageType, contentIds []uuid.UUID, dryRun bool) (map[string]int64, error) {
+	return m.purgeContents(pageType, contentIds, dryRun)
+}
+
+func (m *MockCMSMaintenanceRepo) PurgeOrphans(createdBefore time.Time, dryRun bool) (map[string]int64, error) {
+	return m.purgeOrphans(createdBefore, dryRun)
+}
+
+func newMaintenanceConfig() *config.Config {
+	cfg := config.New()
+	cfg.Maintenance.HistoryRetention = 3
+	cfg.Maintenance.OrphanGrace = time.Hour
+	return cfg
+}
+
+func TestCMSService_RunMaintenance(t *testing.T) {
+	expiredId := uuid.New()
+	historyId := uuid.New()
+
+	newRepo := func(t *testing.T, wantDryRun bool) *MockCMSMaintenanceRepo {
+		return &MockCMSMaintenanceRepo{
+			findExpiredPreviewContentIds: func(pageType enums.PageType, now time.Time) ([]uuid.UUID, error) {
+				if pageType == enums.PageTypeLanding {
+					return []uuid.UUID{expiredId}, nil
+				}
+				return nil, nil
+			},
+			findExcessHistoryContentIds: func(pageType enums.PageType, keep int) ([]uuid.UUID, error) {
+				assert.Equal(t, 3, keep)
+				if pageType == enums.PageTypeFaq {
+					return []uuid.UUID{historyId}, nil
+				}
+				return nil, nil
+			},
+			purgeContents: func(pageType enums.PageType, contentIds []uuid.UUID, dryRun bool) (map[string]int64, error) {
+				assert.Equal(t, wantDryRun, dryRun)
+				switch pageType {
+				case enums.PageTypeLanding:
+					assert.Equal(t, []uuid.UUID{expiredId}, contentIds)
+					return map[string]int64{"landing_contents": 1, "components": 2}, nil
+				case enums.PageTypeFaq:
+					assert.Equal(t, []uuid.UUID{historyId}, contentIds)
+					return map[string]int64{"faq_contents": 1, "components": 1}, nil
+				}
+				assert.Empty(t, contentIds)
+				return map[string]int64{}, nil
+			},
+			purgeOrphans: func(createdBefore time.Time, dryRun bool) (map[string]int64, error) {
+				assert.Equal(t, wantDryRun, dryRun)
+				assert.WithinDuration(t, time.Now().Add(-time.Hour), createdBefore, time.Minute)
+				return map[string]int64{"meta_tags": 4}, nil
+			},
+		}
+	}
+
+	t.Run("successfully run maintenance cleanup", func(t *testing.T) {
+		service := services.NewCMSMaintenanceService(newRepo(t, false), newMaintenanceConfig())
+
+		report, err := service.RunMaintenance(context.Background(), false)
+		require.NoError(t, err)
+		assert.False(t, report.DryRun)
+		assert.Equal(t, 1, report.ExpiredPreviews["landing"])
+		assert.Equal(t, 1, report.PrunedHistories["faq"])
+		assert.Equal(t, map[string]int64{"landing_contents": 1, "faq_contents": 1, "components": 3, "meta_tags": 4}, report.Removed)
+
+		metrics := service.GetMaintenanceMetrics()
+		assert.Equal(t, 1, metrics.Runs)
+		assert.Equal(t, 0, metrics.DryRuns)
+		assert.Equal(t, int64(3), metrics.TotalRemoved["components"])
+		assert.Equal(t, report, metrics.LastRun)
+	})
+
+	t.Run("successfully report a dry run without counting it as removed", func(t *testing.T) {
+		service := services.NewCMSMaintenanceService(newRepo(t, true), newMaintenanceConfig())
+
+		report, err := service.RunMaintenance(context.Background(), true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, int64(4), report.Removed["meta_tags"])
+
+		metrics := service.GetMaintenanceMetrics()
+		assert.Equal(t, 1, metrics.Runs)
+		assert.Equal(t, 1, metrics.DryRuns)
+		assert.Empty(t, metrics.TotalRemoved)
+	})
+
+	t.Run("failed to run maintenance cleanup: repository error", func(t *testing.T) {
+		repo := &MockCMSMaintenanceRepo{
+			findExpiredPreviewContentIds: func(pageType enums.PageType, now time.Time) ([]uuid.UUID, error) {
+				return nil, errs.ErrInternalServerError
+			},
+		}
+		service := services.NewCMSMaintenanceService(repo, newMaintenanceConfig())
+
+		report, err := service.RunMaintenance(context.Background(), false)
+		assert.Error(t, err)
+		assert.Nil(t, report)
+		assert.False(t, service.IsMaintenanceRunning())
+		assert.Equal(t, 0, service.GetMaintenanceMetrics().Runs)
+	})
+
+	t.Run("failed to run maintenance cleanup: already in progress", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		repo := &MockCMSMaintenanceRepo{
+			findExpiredPreviewContentIds: func(pageType enums.PageType, now time.Time) ([]uuid.UUID, error) {
+				close(started)
+				<-release
+				return nil, errs.ErrInternalServerError
+			},
+		}
+		service := services.NewCMSMaintenanceService(repo, newMaintenanceConfig())
+
+		go service.RunMaintenance(context.Background(), false)
+		<-started
+
+		report, err := service.RunMaintenance(context.Background(), false)
+		assert.ErrorIs(t, err, errs.ErrMaintenanceInProgress)
+		assert.Nil(t, report)
+		close(release)
+	})
+}
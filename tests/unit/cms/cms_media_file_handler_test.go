@@ -48,14 +48,24 @@ func (m *MockMediaFileService) ListMediaFiles(filter dto.MediaFileListFilter) (*
 	return args.Get(0).(*dto.MediaFilesListResponse), args.Error(1)
 }
 
-func (m *MockMediaFileService) DeleteMediaFile(idStr string, userID uuid.UUID) error {
-	args := m.Called(idStr, userID)
+func (m *MockMediaFileService) ListMediaFilesByCursor(filter dto.MediaFileListFilter, cursorQuery dto.CursorQuery) ([]dto.MediaFileListItemResponse, *dto.CursorPage, error) {
+	args := m.Called(filter, cursorQuery)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]dto.MediaFileListItemResponse), args.Get(1).(*dto.CursorPage), args.Error(2)
+}
+
+func (m *MockMediaFileService) DeleteMediaFile(idStr string, userID uuid.UUID, force bool) error {
+	args := m.Called(idStr, userID, force)
 	return args.Error(0)
 }
 
 func TestCMSMediaFileHandler(t *testing.T) {
 	mockService := &MockMediaFileService{}
-	handler := cmsHandler.NewMediaFileHandler(mockService)
+	mockUsageService := &MockCMSUsageService{}
+	mockUsageService.On("CheckDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	handler := cmsHandler.NewMediaFileHandler(mockService, mockUsageService)
 
 	userId := uuid.New()
 
@@ -212,7 +222,7 @@ func TestCMSMediaFileHandler(t *testing.T) {
 		idStr := "test"
 		
 		t.Run("successfully delete media file by ID", func(t *testing.T) {
-			mockService.On("DeleteMediaFile", idStr, mock.AnythingOfType("uuid.UUID")).Return(nil)
+			mockService.On("DeleteMediaFile", idStr, mock.AnythingOfType("uuid.UUID"), false).Return(nil)
 			
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/mediafiles/%s", idStr), nil)
 			req.Header.Set("Content-Type", "application/json")	
@@ -224,7 +234,7 @@ func TestCMSMediaFileHandler(t *testing.T) {
 
 		t.Run("failed to delete media file by ID", func(t *testing.T) {
 			mockService.ExpectedCalls = nil
-			mockService.On("DeleteMediaFile", idStr, mock.AnythingOfType("uuid.UUID")).Return(errs.ErrInternalServerError)
+			mockService.On("DeleteMediaFile", idStr, mock.AnythingOfType("uuid.UUID"), false).Return(errs.ErrInternalServerError)
 			
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/mediafiles/%s", idStr), nil)
 			req.Header.Set("Content-Type", "application/json")	
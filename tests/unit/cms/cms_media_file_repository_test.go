@@ -7,6 +7,7 @@ import (
 	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
 	repo "github.com/MadManJJ/cms-api/repositories"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -28,6 +29,9 @@ func TestCMSRepo_CreateMediaFile(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).
 				AddRow(uuid.New()))
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		createdMediaFile, err := cmsMediaFileRepo.Create(mockMediaFile)
@@ -52,6 +56,57 @@ func TestCMSRepo_CreateMediaFile(t *testing.T) {
 	})
 }
 
+func TestCMSRepo_ReplaceMediaFile(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsMediaFileRepo := repo.NewMediaFileRepository(gormDB)
+
+	t.Run("successfully replace media file", func(t *testing.T) {
+		oldId := uuid.New()
+		mockMediaFile := helpers.InitializeMockMediaFile()
+		mock.ExpectBegin()
+
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "media_files" WHERE id = $1`)).
+			WithArgs(oldId).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "media_files"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(uuid.New()))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectCommit()
+
+		replacedMediaFile, err := cmsMediaFileRepo.Replace(oldId, mockMediaFile)
+		assert.NoError(t, err)
+		assert.Equal(t, mockMediaFile, replacedMediaFile)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("keeps the old media file when the new one cannot be saved", func(t *testing.T) {
+		oldId := uuid.New()
+		mockMediaFile := helpers.InitializeMockMediaFile()
+		mock.ExpectBegin()
+
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "media_files" WHERE id = $1`)).
+			WithArgs(oldId).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "media_files"`)).
+			WillReturnError(errs.ErrInternalServerError)
+
+		mock.ExpectRollback()
+
+		replacedMediaFile, err := cmsMediaFileRepo.Replace(oldId, mockMediaFile)
+		assert.Error(t, err)
+		assert.Nil(t, replacedMediaFile)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestCMSRepo_FindById(t *testing.T) {
 	gormDB, mock, cleanup := helpers.SetupTestDB(t)
 	defer cleanup()	
@@ -144,7 +199,7 @@ func TestCMSRepo_ListMediaFile(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).
 				AddRow(2))		
 		
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "media_files" WHERE name ILIKE $1 ORDER BY created_at DESC LIMIT $2`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "media_files" WHERE name ILIKE $1 ORDER BY created_at DESC,id LIMIT $2`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).
 				AddRow(mediaFileId).
 				AddRow(mediaFileId))
@@ -178,22 +233,36 @@ func TestCMSRepo_DeleteMediaFile(t *testing.T) {
 
 	t.Run("successfully delete media file", func(t *testing.T) {
 		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "media_files" WHERE id = $1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+				AddRow(mediaFileId, "image.png"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemMediaFile, mediaFileId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "media_files" WHERE id = $1`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
-		err := cmsMediaFileRepo.Delete(mediaFileId)
+		err := cmsMediaFileRepo.Delete(mediaFileId, false)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("failed to delete media file", func(t *testing.T) {
 		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "media_files" WHERE id = $1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+				AddRow(mediaFileId, "image.png"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemMediaFile, mediaFileId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "media_files" WHERE id = $1`)).
 			WillReturnError(errs.ErrInternalServerError)
 		mock.ExpectRollback()
 
-		err := cmsMediaFileRepo.Delete(mediaFileId)
+		err := cmsMediaFileRepo.Delete(mediaFileId, false)
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
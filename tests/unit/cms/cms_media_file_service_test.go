@@ -16,16 +16,22 @@ import (
 
 type MockMediaFileRepository struct {
 	create   func(file *models.MediaFile) (*models.MediaFile, error)
+	replace  func(oldId uuid.UUID, file *models.MediaFile) (*models.MediaFile, error)
 	findByID func(id uuid.UUID) (*models.MediaFile, error)
 	findByNameAndPath func(name string, path string) (*models.MediaFile, error)
 	list     func(filter dto.MediaFileListFilter) ([]models.MediaFile, int64, error)
-	delete   func(id uuid.UUID) error
+	listByCursor func(filter dto.MediaFileListFilter, cursorQuery dto.CursorQuery) ([]models.MediaFile, *dto.CursorPage, error)
+	delete   func(id uuid.UUID, force bool) error
 }
 
 func (m *MockMediaFileRepository) Create(file *models.MediaFile) (*models.MediaFile, error) {
 	return m.create(file)
 }
 
+func (m *MockMediaFileRepository) Replace(oldId uuid.UUID, file *models.MediaFile) (*models.MediaFile, error) {
+	return m.replace(oldId, file)
+}
+
 func (m *MockMediaFileRepository) FindByID(id uuid.UUID) (*models.MediaFile, error) {
 	return m.findByID(id)
 }
@@ -38,8 +44,12 @@ func (m *MockMediaFileRepository) List(filter dto.MediaFileListFilter) ([]models
 	return m.list(filter)
 }
 
-func (m *MockMediaFileRepository) Delete(id uuid.UUID) error {
-	return m.delete(id)
+func (m *MockMediaFileRepository) ListByCursor(filter dto.MediaFileListFilter, cursorQuery dto.CursorQuery) ([]models.MediaFile, *dto.CursorPage, error) {
+	return m.listByCursor(filter, cursorQuery)
+}
+
+func (m *MockMediaFileRepository) Delete(id uuid.UUID, force bool) error {
+	return m.delete(id, force)
 }
 
 func TestCMSService_UploadMediaFile(t *testing.T) {
@@ -71,7 +81,7 @@ func TestCMSService_UploadMediaFile(t *testing.T) {
 			findByNameAndPath: func(name string, path string) (*models.MediaFile, error) {
 				return nil, nil
 			},
-			delete: func(id uuid.UUID) error {
+			delete: func(id uuid.UUID, force bool) error {
 				return nil
 			},
 		}
@@ -83,6 +93,32 @@ func TestCMSService_UploadMediaFile(t *testing.T) {
 		assert.Equal(t, mediaFileResponse, actualMediaFile)
 	})
 
+	t.Run("successfully replace an existing media file", func(t *testing.T) {
+		existingFile := helpers.InitializeMockMediaFile()
+		existingFile.ID = uuid.New()
+		existingFile.Name = originalFilename
+		replace := true
+
+		var replacedId uuid.UUID
+		repo := &MockMediaFileRepository{
+			replace: func(oldId uuid.UUID, file *models.MediaFile) (*models.MediaFile, error) {
+				replacedId = oldId
+				file.ID = uuid.New()
+				return file, nil
+			},
+			findByNameAndPath: func(name string, path string) (*models.MediaFile, error) {
+				return existingFile, nil
+			},
+		}
+
+		service := services.NewMediaFileService(cfg, repo)
+
+		actualMediaFile, err := service.UploadMediaFile(originalFilename, mimeType, fileData, &customPathOpt, &replace, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, existingFile.ID, replacedId)
+		assert.Equal(t, originalFilename, actualMediaFile.Name)
+	})
+
 	t.Run("failed to upload media file", func(t *testing.T) {
 		repo := &MockMediaFileRepository{
 			create: func(file *models.MediaFile) (*models.MediaFile, error) {
@@ -91,7 +127,7 @@ func TestCMSService_UploadMediaFile(t *testing.T) {
 			findByNameAndPath: func(name string, path string) (*models.MediaFile, error) {
 				return nil, nil
 			},
-			delete: func(id uuid.UUID) error {
+			delete: func(id uuid.UUID, force bool) error {
 				return nil
 			},
 		}
@@ -196,14 +232,14 @@ func TestCMSService_DeleteMediaFile(t *testing.T) {
 			findByID: func(id uuid.UUID) (*models.MediaFile, error) {
 				return mockMediaFile, nil
 			},
-			delete: func(id uuid.UUID) error {
+			delete: func(id uuid.UUID, force bool) error {
 				return nil
 			},
 		}
 
 		service := services.NewMediaFileService(cfg, repo)
 
-		err := service.DeleteMediaFile(mediaFileId.String(), userId)
+		err := service.DeleteMediaFile(mediaFileId.String(), userId, false)
 		assert.NoError(t, err)
 	})
 
@@ -212,14 +248,14 @@ func TestCMSService_DeleteMediaFile(t *testing.T) {
 			findByID: func(id uuid.UUID) (*models.MediaFile, error) {
 				return mockMediaFile, nil
 			},
-			delete: func(id uuid.UUID) error {
+			delete: func(id uuid.UUID, force bool) error {
 				return errs.ErrInternalServerError
 			},
 		}
 
 		service := services.NewMediaFileService(cfg, repo)
 
-		err := service.DeleteMediaFile(mediaFileId.String(), userId)
+		err := service.DeleteMediaFile(mediaFileId.String(), userId, false)
 		assert.Error(t, err)
 	})
 }
\ No newline at end of file
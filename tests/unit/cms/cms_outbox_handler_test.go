@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockCMSOutboxService struct {
+	mock.Mock
+}
+
+func (m *MockCMSOutboxService) Publish(events ...*models.OutboxEvent) error {
+	return m.Called(events).Error(0)
+}
+
+func (m *MockCMSOutboxService) FindDeliveries(query dto.OutboxDeliveryQuery, page, limit int) ([]models.OutboxDelivery, int64, error) {
+	args := m.Called(query, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.OutboxDelivery), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCMSOutboxService) FindDeliveryByID(id uuid.UUID) (*models.OutboxDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OutboxDelivery), args.Error(1)
+}
+
+func (m *MockCMSOutboxService) RetryDelivery(id uuid.UUID) (*models.OutboxDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OutboxDelivery), args.Error(1)
+}
+
+func (m *MockCMSOutboxService) DispatchDueDeliveries(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestCMSOutboxHandler(t *testing.T) {
+	mockService := &MockCMSOutboxService{}
+	handler := cmsHandler.NewCMSOutboxHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/outbox/deliveries", handler.HandleGetOutboxDeliveries)
+	app.Get("/cms/outbox/deliveries/:deliveryId", handler.HandleGetOutboxDelivery)
+	app.Post("/cms/outbox/deliveries/:deliveryId/retry", handler.HandleRetryOutboxDelivery)
+
+	deliveryId := uuid.New()
+
+	t.Run("GET /cms/outbox/deliveries HandleGetOutboxDeliveries", func(t *testing.T) {
+		t.Run("successfully get dead deliveries of a subscriber", func(t *testing.T) {
+			query := dto.OutboxDeliveryQuery{Subscriber: "email", Status: enums.OutboxDeliveryDead}
+			mockService.ExpectedCalls = nil
+			mockService.On("FindDeliveries", query, 1, 10).Return([]models.OutboxDelivery{{ID: deliveryId, Subscriber: "email"}}, int64(1), nil)
+
+			req := httptest.NewRequest("GET", "/cms/outbox/deliveries?subscriber=email&status=dead", nil)
+			resp, _ := app.Test(req)
+			respBody, _ := io.ReadAll(resp.Body)
+
+			var body struct {
+				TotalCount int64                   `json:"totalCount"`
+				Items      []models.OutboxDelivery `json:"items"`
+			}
+			require.NoError(t, json.Unmarshal(respBody, &body))
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, int64(1), body.TotalCount)
+			require.Len(t, body.Items, 1)
+			assert.Equal(t, deliveryId, body.Items[0].ID)
+		})
+
+		t.Run("failed to get deliveries: invalid status", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindDeliveries", mock.Anything, 1, 10).Return(nil, int64(0), errs.ErrInvalidOutboxDeliveryStatus)
+
+			req := httptest.NewRequest("GET", "/cms/outbox/deliveries?status=lost", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/outbox/deliveries/:deliveryId HandleGetOutboxDelivery", func(t *testing.T) {
+		t.Run("successfully get delivery", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindDeliveryByID", deliveryId).Return(&models.OutboxDelivery{ID: deliveryId}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/outbox/deliveries/"+deliveryId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("failed to get delivery: not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindDeliveryByID", deliveryId).Return(nil, gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("GET", "/cms/outbox/deliveries/"+deliveryId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("failed to get delivery: invalid id", func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/cms/outbox/deliveries/not-a-uuid", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /cms/outbox/deliveries/:deliveryId/retry HandleRetryOutboxDelivery", func(t *testing.T) {
+		t.Run("successfully queue delivery", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RetryDelivery", deliveryId).Return(&models.OutboxDelivery{ID: deliveryId, Status: enums.OutboxDeliveryPending}, nil)
+
+			req := httptest.NewRequest("POST", "/cms/outbox/deliveries/"+deliveryId.String()+"/retry", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+		})
+
+		t.Run("failed to retry delivery: already succeeded", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RetryDelivery", deliveryId).Return(nil, errs.ErrOutboxDeliverySucceeded)
+
+			req := httptest.NewRequest("POST", "/cms/outbox/deliveries/"+deliveryId.String()+"/retry", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		})
+	})
+}
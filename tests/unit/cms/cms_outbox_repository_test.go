@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCMSRepo_CreateOutboxEvents(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsOutboxRepo := repo.NewOutboxRepository(gormDB)
+
+	t.Run("successfully create events, dropping known idempotency keys", func(t *testing.T) {
+		event, err := helpers.NewOutboxEvent(enums.DomainEventEmailRequested, uuid.Nil, "event-id:template-id", dto.SendEmailRequest{})
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events" ("id","event_type","aggregate_id","idempotency_key","payload","dispatched_at","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT ("idempotency_key") DO NOTHING`)).
+			WithArgs(event.ID, enums.DomainEventEmailRequested, uuid.Nil, "event-id:template-id", sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		assert.NoError(t, cmsOutboxRepo.CreateEvents([]*models.OutboxEvent{event}))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FanOutOutboxEvents(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsOutboxRepo := repo.NewOutboxRepository(gormDB)
+
+	t.Run("successfully queue a delivery per subscriber and mark the event dispatched", func(t *testing.T) {
+		eventId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox_events" WHERE dispatched_at IS NULL ORDER BY created_at ASC LIMIT $1 FOR UPDATE SKIP LOCKED`)).
+			WithArgs(10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "event_type"}).AddRow(eventId, enums.DomainEventContentSaved))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox_deliveries"`)).
+			WithArgs(
+				eventId, "cache", enums.OutboxDeliveryPending, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+				eventId, "webhooks", enums.OutboxDeliveryPending, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "attempts"}).AddRow(uuid.New(), 0).AddRow(uuid.New(), 0))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_events" SET "dispatched_at"=$1 WHERE id IN ($2)`)).
+			WithArgs(sqlmock.AnyArg(), eventId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		fannedOut, err := cmsOutboxRepo.FanOutEvents(10, func(eventType enums.DomainEventType) []string {
+			assert.Equal(t, enums.DomainEventContentSaved, eventType)
+			return []string{"cache", "webhooks"}
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, fannedOut)
+	})
+
+	t.Run("successfully fan out nothing when no event is new", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE SKIP LOCKED`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+
+		fannedOut, err := cmsOutboxRepo.FanOutEvents(10, func(eventType enums.DomainEventType) []string { return nil })
+		assert.NoError(t, err)
+		assert.Equal(t, 0, fannedOut)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_ClaimDueOutboxDeliveries(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsOutboxRepo := repo.NewOutboxRepository(gormDB)
+
+	t.Run("successfully lock, lease and load the due deliveries", func(t *testing.T) {
+		now := time.Now()
+		leaseUntil := now.Add(time.Minute)
+		deliveryId := uuid.New()
+		eventId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox_deliveries" WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at ASC LIMIT $3 FOR UPDATE SKIP LOCKED`)).
+			WithArgs(enums.OutboxDeliveryPending, now, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "subscriber", "status"}).AddRow(deliveryId, eventId, "cache", enums.OutboxDeliveryPending))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_deliveries" SET "next_attempt_at"=$1,"updated_at"=$2 WHERE id IN ($3)`)).
+			WithArgs(leaseUntil, sqlmock.AnyArg(), deliveryId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox_events" WHERE id IN ($1)`)).
+			WithArgs(eventId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "event_type"}).AddRow(eventId, enums.DomainEventContentSaved))
+
+		deliveries, err := cmsOutboxRepo.ClaimDueDeliveries(now, 10, leaseUntil)
+		assert.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.NotNil(t, deliveries[0].Event)
+		assert.Equal(t, eventId, deliveries[0].Event.ID)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_DeleteHandledOutboxEvents(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsOutboxRepo := repo.NewOutboxRepository(gormDB)
+
+	t.Run("successfully delete the old events whose deliveries all succeeded", func(t *testing.T) {
+		before := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_events" WHERE dispatched_at < $1 AND (NOT EXISTS (SELECT 1 FROM outbox_deliveries WHERE outbox_deliveries.event_id = outbox_events.id AND outbox_deliveries.status <> $2))`)).
+			WithArgs(before, enums.OutboxDeliverySucceeded).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectCommit()
+
+		deleted, err := cmsOutboxRepo.DeleteHandledEventsBefore(before)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), deleted)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
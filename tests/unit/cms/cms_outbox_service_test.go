@@ -0,0 +1,366 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockCMSOutboxRepo struct {
+	createEvents              func(events []*models.OutboxEvent) error
+	fanOutEvents              func(limit int, subscribers func(eventType enums.DomainEventType) []string) (int, error)
+	claimDueDeliveries        func(now time.Time, limit int, leaseUntil time.Time) ([]models.OutboxDelivery, error)
+	updateDelivery            func(delivery *models.OutboxDelivery) error
+	findDeliveries            func(query dto.OutboxDeliveryQuery, page, limit int) ([]models.OutboxDelivery, int64, error)
+	findDeliveryByID          func(id uuid.UUID) (*models.OutboxDelivery, error)
+	deleteHandledEventsBefore func(before time.Time) (int64, error)
+}
+
+func (m *MockCMSOutboxRepo) CreateEvents(events []*models.OutboxEvent) error {
+	return m.createEvents(events)
+}
+
+func (m *MockCMSOutboxRepo) FanOutEvents(limit int, subscribers func(eventType enums.DomainEventType) []string) (int, error) {
+	return m.fanOutEvents(limit, subscribers)
+}
+
+func (m *MockCMSOutboxRepo) ClaimDueDeliveries(now time.Time, limit int, leaseUntil time.Time) ([]models.OutboxDelivery, error) {
+	return m.claimDueDeliveries(now, limit, leaseUntil)
+}
+
+func (m *MockCMSOutboxRepo) UpdateDelivery(delivery *models.OutboxDelivery) error {
+	return m.updateDelivery(delivery)
+}
+
+func (m *MockCMSOutboxRepo) FindDeliveries(query dto.OutboxDeliveryQuery, page, limit int) ([]models.OutboxDelivery, int64, error) {
+	return m.findDeliveries(query, page, limit)
+}
+
+func (m *MockCMSOutboxRepo) FindDeliveryByID(id uuid.UUID) (*models.OutboxDelivery, error) {
+	return m.findDeliveryByID(id)
+}
+
+func (m *MockCMSOutboxRepo) DeleteHandledEventsBefore(before time.Time) (int64, error) {
+	return m.deleteHandledEventsBefore(before)
+}
+
+func newOutboxConfig() *config.Config {
+	cfg := config.New()
+	cfg.Outbox.BatchSize = 10
+	cfg.Outbox.Concurrency = 2
+	cfg.Outbox.Lease = time.Minute
+	cfg.Outbox.MaxAttempts = 3
+	cfg.Outbox.BaseBackoff = time.Minute
+	cfg.Outbox.MaxBackoff = time.Hour
+	cfg.Outbox.Retention = 0
+	return cfg
+}
+
+func newDueOutboxDelivery(t *testing.T, subscriber string, attempts int) models.OutboxDelivery {
+	event := newDomainEvent(t, enums.DomainEventContentSaved, dto.ContentEventData{PageType: enums.PageTypeLanding})
+	return models.OutboxDelivery{
+		ID:         uuid.New(),
+		EventID:    event.ID,
+		Subscriber: subscriber,
+		Status:     enums.OutboxDeliveryPending,
+		Attempts:   attempts,
+		Event:      event,
+	}
+}
+
+// handleOne claims the given delivery and returns the state it was saved with
+func handleOne(t *testing.T, service *services.CMSOutboxService, repo *MockCMSOutboxRepo, delivery models.OutboxDelivery) *models.OutboxDelivery {
+	var updated *models.OutboxDelivery
+	repo.fanOutEvents = func(limit int, subscribers func(eventType enums.DomainEventType) []string) (int, error) {
+		return 0, nil
+	}
+	repo.claimDueDeliveries = func(now time.Time, limit int, leaseUntil time.Time) ([]models.OutboxDelivery, error) {
+		assert.True(t, leaseUntil.After(now))
+		return []models.OutboxDelivery{delivery}, nil
+	}
+	repo.updateDelivery = func(d *models.OutboxDelivery) error {
+		updated = d
+		return nil
+	}
+
+	handled, err := service.DispatchDueDeliveries(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, handled)
+	require.NotNil(t, updated)
+	return updated
+}
+
+func TestCMSService_DispatchOutboxDeliveries(t *testing.T) {
+	t.Run("successfully fan out an event to its subscribers only", func(t *testing.T) {
+		repo := &MockCMSOutboxRepo{
+			claimDueDeliveries: func(now time.Time, limit int, leaseUntil time.Time) ([]models.OutboxDelivery, error) {
+				return nil, nil
+			},
+		}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+		noop := func(ctx context.Context, event *models.OutboxEvent) error { return nil }
+		service.Subscribe("cache", noop, enums.DomainEventContentSaved, enums.DomainEventMediaFileDeleted)
+		service.Subscribe("email", noop, enums.DomainEventEmailRequested)
+
+		var contentSubscribers, emailSubscribers []string
+		repo.fanOutEvents = func(limit int, subscribers func(eventType enums.DomainEventType) []string) (int, error) {
+			assert.Equal(t, 10, limit)
+			contentSubscribers = subscribers(enums.DomainEventContentSaved)
+			emailSubscribers = subscribers(enums.DomainEventEmailRequested)
+			return 2, nil
+		}
+
+		handled, err := service.DispatchDueDeliveries(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, handled)
+		assert.Equal(t, []string{"cache"}, contentSubscribers)
+		assert.Equal(t, []string{"email"}, emailSubscribers)
+	})
+
+	t.Run("successfully handle a delivery", func(t *testing.T) {
+		repo := &MockCMSOutboxRepo{}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+		var received *models.OutboxEvent
+		service.Subscribe("cache", func(ctx context.Context, event *models.OutboxEvent) error {
+			received = event
+			return nil
+		}, enums.DomainEventContentSaved)
+		delivery := newDueOutboxDelivery(t, "cache", 0)
+
+		updated := handleOne(t, service, repo, delivery)
+
+		assert.Equal(t, delivery.Event, received)
+		assert.Equal(t, enums.OutboxDeliverySucceeded, updated.Status)
+		assert.Equal(t, 1, updated.Attempts)
+		assert.NotNil(t, updated.ProcessedAt)
+	})
+
+	t.Run("schedule a retry with backoff when the handler fails", func(t *testing.T) {
+		repo := &MockCMSOutboxRepo{}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+		service.Subscribe("email", func(ctx context.Context, event *models.OutboxEvent) error {
+			return errors.New("smtp unavailable")
+		}, enums.DomainEventContentSaved)
+
+		updated := handleOne(t, service, repo, newDueOutboxDelivery(t, "email", 1))
+
+		assert.Equal(t, enums.OutboxDeliveryPending, updated.Status)
+		assert.Equal(t, 2, updated.Attempts)
+		assert.Equal(t, "smtp unavailable", updated.LastError)
+		// Second failure waits twice the base backoff
+		assert.WithinDuration(t, time.Now().Add(2*time.Minute), updated.NextAttemptAt, 5*time.Second)
+	})
+
+	t.Run("dead-letter the delivery after the last attempt", func(t *testing.T) {
+		repo := &MockCMSOutboxRepo{}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+		service.Subscribe("email", func(ctx context.Context, event *models.OutboxEvent) error {
+			return errors.New("smtp unavailable")
+		}, enums.DomainEventContentSaved)
+
+		updated := handleOne(t, service, repo, newDueOutboxDelivery(t, "email", 2))
+
+		assert.Equal(t, enums.OutboxDeliveryDead, updated.Status)
+		assert.Equal(t, 3, updated.Attempts)
+		assert.Nil(t, updated.ProcessedAt)
+	})
+
+	t.Run("count a panicking handler as a failed attempt", func(t *testing.T) {
+		repo := &MockCMSOutboxRepo{}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+		service.Subscribe("usage-index", func(ctx context.Context, event *models.OutboxEvent) error {
+			panic("index broken")
+		}, enums.DomainEventContentSaved)
+
+		updated := handleOne(t, service, repo, newDueOutboxDelivery(t, "usage-index", 0))
+
+		assert.Equal(t, enums.OutboxDeliveryPending, updated.Status)
+		assert.Equal(t, "handler panicked: index broken", updated.LastError)
+	})
+
+	t.Run("dead-letter a delivery whose subscriber is gone", func(t *testing.T) {
+		repo := &MockCMSOutboxRepo{}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+
+		updated := handleOne(t, service, repo, newDueOutboxDelivery(t, "renamed", 0))
+
+		assert.Equal(t, enums.OutboxDeliveryDead, updated.Status)
+		assert.Equal(t, "no subscriber named renamed", updated.LastError)
+	})
+
+	t.Run("handle every claimed delivery once", func(t *testing.T) {
+		var mu sync.Mutex
+		handledEvents := map[uuid.UUID]int{}
+		deliveries := []models.OutboxDelivery{
+			newDueOutboxDelivery(t, "cache", 0),
+			newDueOutboxDelivery(t, "cache", 0),
+			newDueOutboxDelivery(t, "cache", 0),
+		}
+		repo := &MockCMSOutboxRepo{
+			fanOutEvents: func(limit int, subscribers func(eventType enums.DomainEventType) []string) (int, error) {
+				return 0, nil
+			},
+			claimDueDeliveries: func(now time.Time, limit int, leaseUntil time.Time) ([]models.OutboxDelivery, error) {
+				return deliveries, nil
+			},
+			updateDelivery: func(delivery *models.OutboxDelivery) error { return nil },
+		}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+		service.Subscribe("cache", func(ctx context.Context, event *models.OutboxEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			handledEvents[event.ID]++
+			return nil
+		}, enums.DomainEventContentSaved)
+
+		handled, err := service.DispatchDueDeliveries(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, handled)
+		assert.Len(t, handledEvents, 3)
+		for _, count := range handledEvents {
+			assert.Equal(t, 1, count)
+		}
+	})
+
+	t.Run("failed to fan out events", func(t *testing.T) {
+		repo := &MockCMSOutboxRepo{
+			fanOutEvents: func(limit int, subscribers func(eventType enums.DomainEventType) []string) (int, error) {
+				return 0, errs.ErrInternalServerError
+			},
+		}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+
+		handled, err := service.DispatchDueDeliveries(context.Background())
+		assert.ErrorIs(t, err, errs.ErrInternalServerError)
+		assert.Equal(t, 0, handled)
+	})
+}
+
+func TestCMSService_RetryOutboxDelivery(t *testing.T) {
+	t.Run("successfully queue a dead delivery again", func(t *testing.T) {
+		delivery := newDueOutboxDelivery(t, "email", 3)
+		delivery.Status = enums.OutboxDeliveryDead
+		var updated *models.OutboxDelivery
+		repo := &MockCMSOutboxRepo{
+			findDeliveryByID: func(id uuid.UUID) (*models.OutboxDelivery, error) {
+				assert.Equal(t, delivery.ID, id)
+				return &delivery, nil
+			},
+			updateDelivery: func(d *models.OutboxDelivery) error {
+				updated = d
+				return nil
+			},
+		}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+
+		retried, err := service.RetryDelivery(delivery.ID)
+		require.NoError(t, err)
+		assert.Equal(t, updated, retried)
+		assert.Equal(t, enums.OutboxDeliveryPending, retried.Status)
+		assert.Equal(t, 0, retried.Attempts)
+		assert.WithinDuration(t, time.Now(), retried.NextAttemptAt, 5*time.Second)
+	})
+
+	t.Run("refuse to retry a succeeded delivery", func(t *testing.T) {
+		delivery := newDueOutboxDelivery(t, "email", 1)
+		delivery.Status = enums.OutboxDeliverySucceeded
+		repo := &MockCMSOutboxRepo{
+			findDeliveryByID: func(id uuid.UUID) (*models.OutboxDelivery, error) {
+				return &delivery, nil
+			},
+		}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+
+		retried, err := service.RetryDelivery(delivery.ID)
+		assert.ErrorIs(t, err, errs.ErrOutboxDeliverySucceeded)
+		assert.Nil(t, retried)
+	})
+
+	t.Run("failed to find the delivery", func(t *testing.T) {
+		repo := &MockCMSOutboxRepo{
+			findDeliveryByID: func(id uuid.UUID) (*models.OutboxDelivery, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+
+		retried, err := service.RetryDelivery(uuid.New())
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, retried)
+	})
+}
+
+func TestCMSService_FindOutboxDeliveries(t *testing.T) {
+	t.Run("successfully find deliveries", func(t *testing.T) {
+		query := dto.OutboxDeliveryQuery{Subscriber: "email", EventType: enums.DomainEventEmailRequested, Status: enums.OutboxDeliveryDead}
+		repo := &MockCMSOutboxRepo{
+			findDeliveries: func(q dto.OutboxDeliveryQuery, page, limit int) ([]models.OutboxDelivery, int64, error) {
+				assert.Equal(t, query, q)
+				return []models.OutboxDelivery{{ID: uuid.New()}}, 1, nil
+			},
+		}
+		service := services.NewCMSOutboxService(repo, newOutboxConfig())
+
+		deliveries, totalCount, err := service.FindDeliveries(query, 1, 10)
+		require.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, int64(1), totalCount)
+	})
+
+	t.Run("reject an unknown event type or status", func(t *testing.T) {
+		service := services.NewCMSOutboxService(&MockCMSOutboxRepo{}, newOutboxConfig())
+
+		_, _, err := service.FindDeliveries(dto.OutboxDeliveryQuery{EventType: "page.exploded"}, 1, 10)
+		assert.ErrorIs(t, err, errs.ErrInvalidDomainEventType)
+
+		_, _, err = service.FindDeliveries(dto.OutboxDeliveryQuery{Status: "lost"}, 1, 10)
+		assert.ErrorIs(t, err, errs.ErrInvalidOutboxDeliveryStatus)
+	})
+}
+
+func TestQueuedEmailSender(t *testing.T) {
+	t.Run("queue the email under its idempotency key and send it when handled", func(t *testing.T) {
+		var published []*models.OutboxEvent
+		repo := &MockCMSOutboxRepo{
+			createEvents: func(events []*models.OutboxEvent) error {
+				published = append(published, events...)
+				return nil
+			},
+		}
+		emailService := &MockEmailSendingService{}
+		sender := services.NewQueuedEmailSender(services.NewCMSOutboxService(repo, newOutboxConfig()), emailService)
+
+		req := dto.SendEmailRequest{
+			EmailContentLabel: "email_to_admin",
+			ToRecipientEmails: []string{"admin@example.com"},
+			IdempotencyKey:    "event-id:template-id",
+		}
+		require.NoError(t, sender.SendEmail(req))
+
+		require.Len(t, published, 1)
+		assert.Equal(t, enums.DomainEventEmailRequested, published[0].EventType)
+		assert.Equal(t, "event-id:template-id", published[0].IdempotencyKey)
+		emailService.AssertNotCalled(t, "SendEmail", mock.Anything)
+
+		emailService.On("SendEmail", mock.MatchedBy(func(sent dto.SendEmailRequest) bool {
+			return sent.EmailContentLabel == "email_to_admin" && sent.ToRecipientEmails[0] == "admin@example.com"
+		})).Return(nil).Once()
+		require.NoError(t, sender.HandleDomainEvent(context.Background(), published[0]))
+		emailService.AssertExpectations(t)
+	})
+}
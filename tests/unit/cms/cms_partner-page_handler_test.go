@@ -8,11 +8,13 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/MadManJJ/cms-api/dto"
 	"github.com/MadManJJ/cms-api/errs"
 	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -41,6 +43,14 @@ func (m *MockPartnerService) FindPartnerPages(rawQuery string, sort string, page
 	return args.Get(0).([]models.PartnerPage), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockPartnerService) FindPartnerPagesByCursor(rawQuery string, sort string, cursorQuery dto.CursorQuery, language string) ([]models.PartnerPage, *dto.CursorPage, error) {
+	args := m.Called(rawQuery, sort, cursorQuery, language)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]models.PartnerPage), args.Get(1).(*dto.CursorPage), args.Error(2)
+}
+
 func (m *MockPartnerService) FindPartnerPageById(id uuid.UUID) (*models.PartnerPage, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -57,8 +67,8 @@ func (m *MockPartnerService) UpdatePartnerContent(updatedPartnerContent *models.
 	return args.Get(0).(*models.PartnerContent), args.Error(1)
 }
 
-func (m *MockPartnerService) DeletePartnerPage(id uuid.UUID) error {
-	args := m.Called(id)
+func (m *MockPartnerService) DeletePartnerPage(id uuid.UUID, force bool) error {
+	args := m.Called(id, force)
 	return args.Error(0)
 }
 
@@ -123,17 +133,24 @@ func (m *MockPartnerService) FindRevisions(pageId uuid.UUID, language string) ([
 	return args.Get(0).([]models.Revision), args.Error(1)
 }
 
-func (m *MockPartnerService) PreviewPartnerContent(pageId uuid.UUID, partnerContentPreview *models.PartnerContent) (string, error) {
+func (m *MockPartnerService) PreviewPartnerContent(pageId uuid.UUID, partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error) {
 	args := m.Called(pageId, partnerContentPreview)
 	if args.Get(0) == nil {
-		return "", args.Error(1)
-	}	
-	return args.Get(0).(string), args.Error(1)
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PartnerContent), args.Error(1)
+}
+
+func (m *MockPartnerService) WithPageAccess(access *dto.PageAccess) services.CMSPartnerPageServiceInterface {
+	return m
 }
 
 func TestCMSPartnerHandler(t *testing.T) {
 	mockService := &MockPartnerService{}
-	handler := cmsHandler.NewCMSPartnerPageHandler(mockService)
+	mockPreviewLinkService := &MockCMSPreviewLinkService{}
+	mockUsageService := &MockCMSUsageService{}
+	mockUsageService.On("CheckDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	handler := cmsHandler.NewCMSPartnerPageHandler(mockService, mockPreviewLinkService, mockUsageService)
 
 	app := fiber.New()
 	app.Post("/cms/partnerpages", handler.HandleCreatePartnerPage)
@@ -312,7 +329,7 @@ func TestCMSPartnerHandler(t *testing.T) {
 		pageId := uuid.New()
 
 		t.Run("successfully delete partner page", func(t *testing.T)	{
-			mockService.On("DeletePartnerPage", pageId).Return(nil)
+			mockService.On("DeletePartnerPage", pageId, false).Return(nil)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/partnerpages/%s", pageId), nil)
 			
@@ -324,7 +341,7 @@ func TestCMSPartnerHandler(t *testing.T) {
 		
 		t.Run("failed to delete partner page: invalid pageId", func(t *testing.T)	{
 			mockService.ExpectedCalls = nil
-			mockService.On("DeletePartnerPage", pageId).Return(nil)
+			mockService.On("DeletePartnerPage", pageId, false).Return(nil)
 			invalidPageId := "1"
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/partnerpages/%s", invalidPageId), nil)
@@ -337,7 +354,7 @@ func TestCMSPartnerHandler(t *testing.T) {
 		
 		t.Run("failed to delete partner page: internal server error", func(t *testing.T)	{
 			mockService.ExpectedCalls = nil
-			mockService.On("DeletePartnerPage", pageId).Return(errs.ErrInternalServerError)
+			mockService.On("DeletePartnerPage", pageId, false).Return(errs.ErrInternalServerError)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/partnerpages/%s", pageId), nil)
 			
@@ -86,6 +86,8 @@ func TestCMSRepo_CreatePartnerPage(t *testing.T) {
 					AddRow(uuid.New(), uuid.New()), // match what's RETURNED
 			)
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		partnerPage, err := cmsPartnerPageRepo.CreatePartnerPage(mockPartnerPage)
@@ -369,10 +371,16 @@ func TestCMSRepo_UpdatePartnerContent(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}).
 				AddRow(partnerContentId, categoryId))	
 				
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "partner_pages" SET "updated_at"=$1 WHERE id = $2`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))				
 
 		// Expect commit
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()		
 
 		updatedPartnerContent, err := cmsPartnerPageRepo.UpdatePartnerContent(mockPartnerContent, prevContentId)
@@ -423,6 +431,10 @@ func TestCMSRepo_DeletePartnerPage(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 				AddRow(pageId, now, now))
 
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "usage_references" WHERE item_type = $1 AND item_id = $2 AND published = $3`)).
+			WithArgs(enums.UsageItemPartnerPage, pageId, true).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "partner_contents"`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id", "title"}).
 				AddRow(contentId, pageId, contentTitle))		
@@ -442,9 +454,12 @@ func TestCMSRepo_DeletePartnerPage(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "partner_pages"`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))					
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
-		err := cmsPartnerPageRepo.DeletePartnerPage(pageId)
+		err := cmsPartnerPageRepo.DeletePartnerPage(pageId, false)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})	
@@ -459,7 +474,7 @@ func TestCMSRepo_DeletePartnerPage(t *testing.T) {
 
 		mock.ExpectRollback()
 
-		err := cmsPartnerPageRepo.DeletePartnerPage(pageId)
+		err := cmsPartnerPageRepo.DeletePartnerPage(pageId, false)
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})	
@@ -605,6 +620,9 @@ func TestCMSRepo_DeletePartnerContent(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "partner_contents"`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))			
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		err := cmsPartnerPageRepo.DeletePartnerContent(pageId, language, mode)
@@ -752,6 +770,9 @@ func TestCMSRepo_DuplicatePartnerPage(t *testing.T) {
 					AddRow(newContentId, categoryId),
 			)				
 			
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		mock.ExpectCommit()
 
 		partnerPage, err := cmsPartnerPageRepo.DuplicatePartnerPage(pageId)
@@ -844,6 +865,12 @@ func TestCMSRepo_DuplicatePartnerContentToAnotherLanguage(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}).
 				AddRow(newContentId, newCategoryId))						
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		partnerContent, err := cmsPartnerPageRepo.DuplicatePartnerContentToAnotherLanguage(oldContentId, newRevision)
@@ -949,6 +976,12 @@ func TestCMSRepo_RevertPartnerContent(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"partner_content_id", "category_id"}).
 				AddRow(newContentId, newCategoryId))					
 
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO content_relations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectCommit()
 
 		partnerContent, err := cmsPartnerPageRepo.RevertPartnerContent(oldRevisionId, newRevision)
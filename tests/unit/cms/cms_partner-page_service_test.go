@@ -1,8 +1,8 @@
 package tests
 
 import (
-	"strings"
 	"testing"
+	"time"
 
 	"github.com/MadManJJ/cms-api/config"
 	"github.com/MadManJJ/cms-api/dto"
@@ -20,9 +20,10 @@ import (
 type MockCMSPartnerPageRepo struct {
 	createPartnerPage                        func(partnerPage *models.PartnerPage) (*models.PartnerPage, error)
 	findAllPartnerPage                       func(query dto.PartnerPageQuery, sort string, page, limit int, language string) ([]models.PartnerPage, int64, error)
+	findPartnerPagesByCursor                 func(query dto.PartnerPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.PartnerPage, *dto.CursorPage, error)
 	findPartnerPageById                      func(id uuid.UUID) (*models.PartnerPage, error)
 	updatePartnerContent                     func(updatePartnerContent *models.PartnerContent, prevContentId uuid.UUID) (*models.PartnerContent, error)
-	deletePartnerPage                        func(id uuid.UUID) error
+	deletePartnerPage                        func(id uuid.UUID, force bool) error
 	findContentByPartnerPageId               func(pageId uuid.UUID, language string, mode string) (*models.PartnerContent, error)
 	findLatestContentByPageId                func(pageId uuid.UUID, language string) (*models.PartnerContent, error)
 	createContentForPartnerPage              func(partnerContent *models.PartnerContent, lang string, mode string) (*models.PartnerContent, error) // Deprecated
@@ -35,6 +36,9 @@ type MockCMSPartnerPageRepo struct {
 	isUrlDuplicate                           func(url string, pageId uuid.UUID) (bool, error)
 	isUrlAliasDuplicate                      func(urlAlias string, pageId uuid.UUID) (bool, error)
 	getPageIdByContentId                     func(contentId uuid.UUID) (uuid.UUID, error)
+	getContentRefByRevisionId                func(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error)
+	findContentById func(contentId uuid.UUID) (*models.PartnerContent, error)
+	findContentScopes                        func(pageId uuid.UUID) ([]dto.PageContentScope, error)
 	createPartnerContentPreview               func(partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error)
 	updatePartnerContentPreview               func(partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error)	
 	findPartnerContentPreviewById             func(pageId uuid.UUID, language string) (*models.PartnerContent, error)	
@@ -48,6 +52,10 @@ func (m *MockCMSPartnerPageRepo) FindAllPartnerPage(query dto.PartnerPageQuery,
 	return m.findAllPartnerPage(query, sort, page, limit, language)
 }
 
+func (m *MockCMSPartnerPageRepo) FindPartnerPagesByCursor(query dto.PartnerPageQuery, sort string, cursorQuery dto.CursorQuery, language string) ([]models.PartnerPage, *dto.CursorPage, error) {
+	return m.findPartnerPagesByCursor(query, sort, cursorQuery, language)
+}
+
 func (m *MockCMSPartnerPageRepo) FindPartnerPageById(id uuid.UUID) (*models.PartnerPage, error) {
 	return m.findPartnerPageById(id)
 }
@@ -56,8 +64,8 @@ func (m *MockCMSPartnerPageRepo) UpdatePartnerContent(updatePartnerContent *mode
 	return m.updatePartnerContent(updatePartnerContent, prevContentId)
 }
 
-func (m *MockCMSPartnerPageRepo) DeletePartnerPage(id uuid.UUID) error {
-	return m.deletePartnerPage(id)
+func (m *MockCMSPartnerPageRepo) DeletePartnerPage(id uuid.UUID, force bool) error {
+	return m.deletePartnerPage(id, force)
 }
 
 func (m *MockCMSPartnerPageRepo) FindContentByPartnerPageId(pageId uuid.UUID, language string, mode string) (*models.PartnerContent, error) {
@@ -108,6 +116,18 @@ func (m *MockCMSPartnerPageRepo) GetPageIdByContentId(contentId uuid.UUID) (uuid
 	return m.getPageIdByContentId(contentId)
 }
 
+func (m *MockCMSPartnerPageRepo) GetContentRefByRevisionId(revisionId uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	return m.getContentRefByRevisionId(revisionId)
+}
+
+func (m *MockCMSPartnerPageRepo) FindContentScopes(pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageId)
+}
+
+func (m *MockCMSPartnerPageRepo) FindContentById(contentId uuid.UUID) (*models.PartnerContent, error) {
+	return m.findContentById(contentId)
+}
+
 func (m *MockCMSPartnerPageRepo) CreatePartnerContentPreview(partnerContentPreview *models.PartnerContent) (*models.PartnerContent, error) {
 	return m.createPartnerContentPreview(partnerContentPreview)
 }
@@ -444,7 +464,7 @@ func TestCMSService_DeletePartnerPage(t *testing.T) {
 		pageId := uuid.New()
 
 		partnerRepo := &MockCMSPartnerPageRepo{
-			deletePartnerPage: func(id uuid.UUID) error {
+			deletePartnerPage: func(id uuid.UUID, force bool) error {
 				return nil
 			},
 		}
@@ -455,7 +475,7 @@ func TestCMSService_DeletePartnerPage(t *testing.T) {
 	
 		service := services.NewCMSPartnerPageService(partnerRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)	
 
-		err := service.DeletePartnerPage(pageId)
+		err := service.DeletePartnerPage(pageId, false)
 		assert.NoError(t, err)
 	})	
 
@@ -463,7 +483,7 @@ func TestCMSService_DeletePartnerPage(t *testing.T) {
 		pageId := uuid.New()
 
 		partnerRepo := &MockCMSPartnerPageRepo{
-			deletePartnerPage: func(id uuid.UUID) error {
+			deletePartnerPage: func(id uuid.UUID, force bool) error {
 				return errs.ErrInternalServerError
 			},
 		}
@@ -474,7 +494,7 @@ func TestCMSService_DeletePartnerPage(t *testing.T) {
 	
 		service := services.NewCMSPartnerPageService(partnerRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)	
 
-		err := service.DeletePartnerPage(pageId)
+		err := service.DeletePartnerPage(pageId, false)
 		assert.Error(t, err)
 	})		
 }
@@ -862,12 +882,6 @@ func TestCMSService_FindPartnerRevisions(t *testing.T) {
 }
 
 func TestCMSService_PreviewPartnerContent(t *testing.T) {
-	cfg := config.New()
-
-	urls := cfg.App.FrontendURLS
-	parts := strings.Split(urls, ",")
-	appUrl := parts[1]	
-
 	t.Run("successfully preview content: create new content", func(t *testing.T) {
 		pageId := uuid.New()
 
@@ -899,18 +913,11 @@ func TestCMSService_PreviewPartnerContent(t *testing.T) {
 
 		service := services.NewCMSPartnerPageService(partnerRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)	
 
-		expectPreviewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(mockContent.Language),
-			"partner",
-			createdContent.ID,
-		)
-
-		assert.NoError(t, err)
-
-		previewUrl, err := service.PreviewPartnerContent(pageId, mockContent)
+		savedContent, err := service.PreviewPartnerContent(pageId, mockContent)
 		assert.NoError(t, err)
-		assert.Equal(t, expectPreviewUrl, previewUrl)
+		assert.Equal(t, createdContent.ID, savedContent.ID)
+		assert.Equal(t, enums.PageModePreview, mockContent.Mode)
+		assert.WithinDuration(t, time.Now().Add(cfg.Preview.MaxTTL), mockContent.ExpiredAt, time.Minute)
 	})	
 
 	t.Run("successfully preview content: update existing content", func(t *testing.T) {
@@ -951,17 +958,8 @@ func TestCMSService_PreviewPartnerContent(t *testing.T) {
 
 		service := services.NewCMSPartnerPageService(partnerRepo, emailSendingService, emailContentRepo, emailCategoryRepo, cfg)	
 
-		expectPreviewUrl, err := helpers.BuildPreviewURL(
-			appUrl,
-			string(updatedContent.Language),
-			"partner",
-			updatedContent.ID,
-		)
-
-		assert.NoError(t, err)
-
-		previewUrl, err := service.PreviewPartnerContent(pageId, mockContent)
+		savedContent, err := service.PreviewPartnerContent(pageId, mockContent)
 		assert.NoError(t, err)
-		assert.Equal(t, expectPreviewUrl, previewUrl)
+		assert.Equal(t, updatedContent.ID, savedContent.ID)
 	})		
 }
\ No newline at end of file
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockCMSPreviewLinkService struct {
+	mock.Mock
+}
+
+func (m *MockCMSPreviewLinkService) ParsePreviewTTL(raw string) (time.Duration, error) {
+	args := m.Called(raw)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockCMSPreviewLinkService) IssuePreviewLink(request dto.IssuePreviewLinkRequest) (*dto.PreviewLinkResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PreviewLinkResponse), args.Error(1)
+}
+
+func (m *MockCMSPreviewLinkService) FindPreviewLinks(query dto.PreviewLinkQuery, page, limit int) ([]dto.PreviewLinkResponse, int64, error) {
+	args := m.Called(query, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]dto.PreviewLinkResponse), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCMSPreviewLinkService) RevokePreviewLink(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCMSPreviewLinkService) ResolvePreviewToken(token, password string, pageType enums.PageType) (*models.PreviewLink, error) {
+	args := m.Called(token, password, pageType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PreviewLink), args.Error(1)
+}
+
+func (m *MockCMSPreviewLinkService) WithPageAccess(access *dto.PageAccess) services.CMSPreviewLinkServiceInterface {
+	return m
+}
+
+func TestCMSPreviewLinkHandler(t *testing.T) {
+	mockService := &MockCMSPreviewLinkService{}
+	handler := cmsHandler.NewCMSPreviewLinkHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/previews", handler.HandleGetPreviewLinks)
+	app.Delete("/cms/previews/:id", handler.HandleRevokePreviewLink)
+
+	t.Run("GET /cms/previews HandleGetPreviewLinks", func(t *testing.T) {
+		t.Run("successfully get preview links", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			pageId := uuid.New()
+			query := dto.PreviewLinkQuery{PageID: pageId.String(), PageType: enums.PageTypeLanding, IncludeInvalid: true}
+			mockService.On("FindPreviewLinks", query, 1, 10).Return([]dto.PreviewLinkResponse{{PageID: pageId.String()}}, int64(1), nil)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/cms/previews?pageId=%s&pageType=landing&includeInvalid=true", pageId), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get preview links: invalid filter", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindPreviewLinks", mock.Anything, 1, 10).Return(nil, int64(0), errs.ErrInvalidUUIDFormat)
+
+			req := httptest.NewRequest("GET", "/cms/previews?pageId=invalid", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to get preview links: internal server error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindPreviewLinks", mock.Anything, 1, 10).Return(nil, int64(0), errs.ErrInternalServerError)
+
+			req := httptest.NewRequest("GET", "/cms/previews", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	})
+
+	t.Run("DELETE /cms/previews/:id HandleRevokePreviewLink", func(t *testing.T) {
+		id := uuid.New()
+
+		t.Run("successfully revoke preview link", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RevokePreviewLink", id).Return(nil)
+
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/previews/%s", id), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to revoke preview link: invalid id", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest("DELETE", "/cms/previews/1", nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("failed to revoke preview link: not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RevokePreviewLink", id).Return(gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/cms/previews/%s", id), nil)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	})
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCMSRepo_CreatePreviewLink(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsPreviewLinkRepo := repo.NewCMSPreviewLinkRepository(gormDB)
+
+	previewLink := &models.PreviewLink{
+		ID:        uuid.New(),
+		PageType:  enums.PageTypeLanding,
+		PageID:    uuid.New(),
+		ContentID: uuid.New(),
+		Language:  enums.PageLanguageEN,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "preview_links"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(previewLink.ID))
+	mock.ExpectCommit()
+
+	createdPreviewLink, err := cmsPreviewLinkRepo.CreatePreviewLink(previewLink)
+	assert.NoError(t, err)
+	assert.Equal(t, previewLink.ID, createdPreviewLink.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FindPreviewLinks(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsPreviewLinkRepo := repo.NewCMSPreviewLinkRepository(gormDB)
+
+	t.Run("successfully find active preview links", func(t *testing.T) {
+		pageId := uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "preview_links" WHERE page_id = $1 AND (revoked_at IS NULL AND expires_at > $2)`)).
+			WithArgs(pageId.String(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "preview_links" WHERE page_id = $1 AND (revoked_at IS NULL AND expires_at > $2) ORDER BY created_at DESC LIMIT $3`)).
+			WithArgs(pageId.String(), sqlmock.AnyArg(), 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "page_id"}).AddRow(uuid.New(), pageId))
+
+		previewLinks, totalCount, err := cmsPreviewLinkRepo.FindPreviewLinks(dto.PreviewLinkQuery{PageID: pageId.String()}, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), totalCount)
+		assert.Len(t, previewLinks, 1)
+	})
+
+	t.Run("successfully find every preview link of a page type", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "preview_links" WHERE page_type = $1`)).
+			WithArgs(enums.PageTypeFaq).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "preview_links" WHERE page_type = $1 ORDER BY created_at DESC LIMIT $2`)).
+			WithArgs(enums.PageTypeFaq, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		previewLinks, totalCount, err := cmsPreviewLinkRepo.FindPreviewLinks(dto.PreviewLinkQuery{PageType: enums.PageTypeFaq, IncludeInvalid: true}, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), totalCount)
+		assert.Empty(t, previewLinks)
+	})
+
+	t.Run("successfully limit the preview links to the page grants", func(t *testing.T) {
+		english := enums.PageLanguageEN
+		access := &dto.PageAccess{Grants: []models.PageGrant{
+			{PageType: enums.PageTypeLanding, Action: enums.PermissionActionRead, Language: &english},
+		}}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "preview_links" WHERE (`+
+			`(preview_links.page_type = $1 AND preview_links.content_id IN (SELECT landing_contents.id FROM "landing_contents" WHERE ((landing_contents.language = $2)))) OR `+
+			`(preview_links.page_type = $3 AND preview_links.content_id IN (SELECT partner_contents.id FROM "partner_contents" WHERE FALSE)) OR `+
+			`(preview_links.page_type = $4 AND preview_links.content_id IN (SELECT faq_contents.id FROM "faq_contents" WHERE FALSE)))`)).
+			WithArgs(enums.PageTypeLanding, english, enums.PageTypePartner, enums.PageTypeFaq).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "preview_links" WHERE (`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err := cmsPreviewLinkRepo.FindPreviewLinks(dto.PreviewLinkQuery{IncludeInvalid: true, Access: access}, 1, 10)
+		assert.NoError(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_RevokePreviewLink(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsPreviewLinkRepo := repo.NewCMSPreviewLinkRepository(gormDB)
+	id := uuid.New()
+	revokedAt := time.Now()
+
+	t.Run("successfully revoke preview link", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "preview_links" SET "revoked_at"=COALESCE(revoked_at, $1) WHERE id = $2`)).
+			WithArgs(revokedAt, id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := cmsPreviewLinkRepo.RevokePreviewLink(id, revokedAt)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed to revoke preview link: not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "preview_links" SET "revoked_at"=COALESCE(revoked_at, $1) WHERE id = $2`)).
+			WithArgs(revokedAt, id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := cmsPreviewLinkRepo.RevokePreviewLink(id, revokedAt)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,341 @@
+package tests
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockCMSPreviewLinkRepo struct {
+	createPreviewLink   func(previewLink *models.PreviewLink) (*models.PreviewLink, error)
+	findPreviewLinkById func(id uuid.UUID) (*models.PreviewLink, error)
+	findPreviewLinks    func(query dto.PreviewLinkQuery, page, limit int) ([]models.PreviewLink, int64, error)
+	revokePreviewLink   func(id uuid.UUID, revokedAt time.Time) error
+	findContentScopes   func(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+func (m *MockCMSPreviewLinkRepo) CreatePreviewLink(previewLink *models.PreviewLink) (*models.PreviewLink, error) {
+	return m.createPreviewLink(previewLink)
+}
+
+func (m *MockCMSPreviewLinkRepo) FindPreviewLinkById(id uuid.UUID) (*models.PreviewLink, error) {
+	return m.findPreviewLinkById(id)
+}
+
+func (m *MockCMSPreviewLinkRepo) FindPreviewLinks(query dto.PreviewLinkQuery, page, limit int) ([]models.PreviewLink, int64, error) {
+	return m.findPreviewLinks(query, page, limit)
+}
+
+func (m *MockCMSPreviewLinkRepo) RevokePreviewLink(id uuid.UUID, revokedAt time.Time) error {
+	return m.revokePreviewLink(id, revokedAt)
+}
+
+func (m *MockCMSPreviewLinkRepo) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageType, pageId)
+}
+
+func newPreviewLinkConfig() *config.Config {
+	cfg := config.New()
+	cfg.App.FrontendURLS = "https://cms.example.com,https://www.example.com"
+	cfg.Preview.SecretKey = "preview-secret"
+	cfg.Preview.FrontendURL = ""
+	cfg.Preview.DefaultTTL = 2 * time.Hour
+	cfg.Preview.MaxTTL = 24 * time.Hour
+	return cfg
+}
+
+// issueTestPreviewLink issues a link through the service and keeps the stored copy in the returned store
+func issueTestPreviewLink(t *testing.T, password string) (*dto.PreviewLinkResponse, map[uuid.UUID]*models.PreviewLink, *MockCMSPreviewLinkRepo) {
+	store := map[uuid.UUID]*models.PreviewLink{}
+	repo := &MockCMSPreviewLinkRepo{
+		createPreviewLink: func(previewLink *models.PreviewLink) (*models.PreviewLink, error) {
+			store[previewLink.ID] = previewLink
+			return previewLink, nil
+		},
+		findPreviewLinkById: func(id uuid.UUID) (*models.PreviewLink, error) {
+			previewLink, ok := store[id]
+			if !ok {
+				return nil, gorm.ErrRecordNotFound
+			}
+			return previewLink, nil
+		},
+	}
+
+	service := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig())
+	previewLink, err := service.IssuePreviewLink(dto.IssuePreviewLinkRequest{
+		PageType:  enums.PageTypeLanding,
+		PageID:    uuid.New(),
+		ContentID: uuid.New(),
+		Language:  enums.PageLanguageEN,
+		TTL:       time.Hour,
+		Password:  password,
+	})
+	require.NoError(t, err)
+
+	return previewLink, store, repo
+}
+
+func previewTokenOf(t *testing.T, previewURL string) string {
+	parsed, err := url.Parse(previewURL)
+	require.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+func TestCMSService_ParsePreviewTTL(t *testing.T) {
+	service := services.NewCMSPreviewLinkService(&MockCMSPreviewLinkRepo{}, newPreviewLinkConfig())
+
+	ttl, err := service.ParsePreviewTTL("")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, ttl)
+
+	ttl, err = service.ParsePreviewTTL("30m")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, ttl)
+
+	for _, raw := range []string{"forever", "-1h", "0s", "48h"} {
+		_, err = service.ParsePreviewTTL(raw)
+		assert.ErrorIs(t, err, errs.ErrInvalidPreviewTTL, raw)
+	}
+}
+
+func TestCMSService_IssuePreviewLink(t *testing.T) {
+	t.Run("successfully issue preview link", func(t *testing.T) {
+		previewLink, store, _ := issueTestPreviewLink(t, "secret")
+
+		assert.True(t, strings.HasPrefix(previewLink.URL, "https://www.example.com/preview/en/landing?token="))
+		assert.NotEmpty(t, previewTokenOf(t, previewLink.URL))
+		assert.True(t, previewLink.PasswordProtected)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), previewLink.ExpiresAt, time.Minute)
+
+		stored := store[uuid.MustParse(previewLink.ID)]
+		require.NotNil(t, stored)
+		assert.NotEqual(t, "secret", stored.PasswordHash)
+	})
+
+	t.Run("successfully issue preview link: single frontend url", func(t *testing.T) {
+		cfg := newPreviewLinkConfig()
+		cfg.App.FrontendURLS = "https://www.example.com/"
+		repo := &MockCMSPreviewLinkRepo{
+			createPreviewLink: func(previewLink *models.PreviewLink) (*models.PreviewLink, error) {
+				return previewLink, nil
+			},
+		}
+
+		service := services.NewCMSPreviewLinkService(repo, cfg)
+
+		previewLink, err := service.IssuePreviewLink(dto.IssuePreviewLinkRequest{PageType: enums.PageTypeFaq, Language: enums.PageLanguageTH})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(previewLink.URL, "https://www.example.com/preview/th/faq?token="))
+		assert.WithinDuration(t, time.Now().Add(cfg.Preview.DefaultTTL), previewLink.ExpiresAt, time.Minute)
+	})
+
+	t.Run("failed to issue preview link: frontend url not configured", func(t *testing.T) {
+		cfg := newPreviewLinkConfig()
+		cfg.App.FrontendURLS = ""
+		repo := &MockCMSPreviewLinkRepo{
+			createPreviewLink: func(previewLink *models.PreviewLink) (*models.PreviewLink, error) {
+				t.Fatal("preview link should not be stored")
+				return nil, nil
+			},
+		}
+
+		service := services.NewCMSPreviewLinkService(repo, cfg)
+
+		previewLink, err := service.IssuePreviewLink(dto.IssuePreviewLinkRequest{PageType: enums.PageTypePartner})
+		assert.ErrorIs(t, err, errs.ErrPreviewFrontendURLMissing)
+		assert.Nil(t, previewLink)
+	})
+
+	t.Run("failed to issue preview link: ttl over the maximum", func(t *testing.T) {
+		service := services.NewCMSPreviewLinkService(&MockCMSPreviewLinkRepo{}, newPreviewLinkConfig())
+
+		previewLink, err := service.IssuePreviewLink(dto.IssuePreviewLinkRequest{PageType: enums.PageTypeLanding, TTL: 48 * time.Hour})
+		assert.ErrorIs(t, err, errs.ErrInvalidPreviewTTL)
+		assert.Nil(t, previewLink)
+	})
+}
+
+func TestCMSService_ResolvePreviewToken(t *testing.T) {
+	t.Run("successfully resolve preview token", func(t *testing.T) {
+		previewLink, _, repo := issueTestPreviewLink(t, "secret")
+		service := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig())
+
+		resolved, err := service.ResolvePreviewToken(previewTokenOf(t, previewLink.URL), "secret", enums.PageTypeLanding)
+		require.NoError(t, err)
+		assert.Equal(t, previewLink.ContentID, resolved.ContentID.String())
+	})
+
+	t.Run("failed to resolve preview token: password", func(t *testing.T) {
+		previewLink, _, repo := issueTestPreviewLink(t, "secret")
+		service := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig())
+		token := previewTokenOf(t, previewLink.URL)
+
+		_, err := service.ResolvePreviewToken(token, "", enums.PageTypeLanding)
+		assert.ErrorIs(t, err, errs.ErrPreviewPasswordRequired)
+
+		_, err = service.ResolvePreviewToken(token, "wrong", enums.PageTypeLanding)
+		assert.ErrorIs(t, err, errs.ErrInvalidPreviewPassword)
+	})
+
+	t.Run("failed to resolve preview token: locked after too many wrong passwords", func(t *testing.T) {
+		previewLink, store, repo := issueTestPreviewLink(t, "secret")
+		other, otherStore, _ := issueTestPreviewLink(t, "secret")
+		store[uuid.MustParse(other.ID)] = otherStore[uuid.MustParse(other.ID)]
+		cfg := newPreviewLinkConfig()
+		cfg.Preview.MaxPasswordAttempts = 3
+		cfg.Preview.PasswordLockout = time.Hour
+		service := services.NewCMSPreviewLinkService(repo, cfg)
+		token := previewTokenOf(t, previewLink.URL)
+
+		for i := 0; i < 3; i++ {
+			_, err := service.ResolvePreviewToken(token, "wrong", enums.PageTypeLanding)
+			assert.ErrorIs(t, err, errs.ErrInvalidPreviewPassword)
+		}
+
+		// Locked for every request, whatever access it was scoped to, and even with the right password
+		_, err := service.WithPageAccess(nil).ResolvePreviewToken(token, "secret", enums.PageTypeLanding)
+		assert.ErrorIs(t, err, errs.ErrPreviewLinkLocked)
+
+		// Other links keep working
+		resolved, err := service.ResolvePreviewToken(previewTokenOf(t, other.URL), "secret", enums.PageTypeLanding)
+		require.NoError(t, err)
+		assert.Equal(t, other.ContentID, resolved.ContentID.String())
+	})
+
+	t.Run("successfully resolve preview token after a right password resets the wrong ones", func(t *testing.T) {
+		previewLink, _, repo := issueTestPreviewLink(t, "secret")
+		cfg := newPreviewLinkConfig()
+		cfg.Preview.MaxPasswordAttempts = 2
+		service := services.NewCMSPreviewLinkService(repo, cfg)
+		token := previewTokenOf(t, previewLink.URL)
+
+		for i := 0; i < 3; i++ {
+			_, err := service.ResolvePreviewToken(token, "wrong", enums.PageTypeLanding)
+			assert.ErrorIs(t, err, errs.ErrInvalidPreviewPassword)
+			_, err = service.ResolvePreviewToken(token, "secret", enums.PageTypeLanding)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("failed to resolve preview token: invalid token", func(t *testing.T) {
+		previewLink, _, repo := issueTestPreviewLink(t, "")
+		service := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig())
+		token := previewTokenOf(t, previewLink.URL)
+
+		_, err := service.ResolvePreviewToken(token, "", enums.PageTypeFaq)
+		assert.ErrorIs(t, err, errs.ErrInvalidPreviewToken)
+
+		_, err = service.ResolvePreviewToken(token+"x", "", enums.PageTypeLanding)
+		assert.ErrorIs(t, err, errs.ErrInvalidPreviewToken)
+
+		cfg := newPreviewLinkConfig()
+		cfg.Preview.SecretKey = "another-secret"
+		_, err = services.NewCMSPreviewLinkService(repo, cfg).ResolvePreviewToken(token, "", enums.PageTypeLanding)
+		assert.ErrorIs(t, err, errs.ErrInvalidPreviewToken)
+	})
+
+	t.Run("failed to resolve preview token: revoked", func(t *testing.T) {
+		previewLink, store, repo := issueTestPreviewLink(t, "")
+		revokedAt := time.Now()
+		store[uuid.MustParse(previewLink.ID)].RevokedAt = &revokedAt
+		service := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig())
+
+		_, err := service.ResolvePreviewToken(previewTokenOf(t, previewLink.URL), "", enums.PageTypeLanding)
+		assert.ErrorIs(t, err, errs.ErrPreviewLinkRevoked)
+	})
+
+	t.Run("failed to resolve preview token: deleted link", func(t *testing.T) {
+		previewLink, store, repo := issueTestPreviewLink(t, "")
+		delete(store, uuid.MustParse(previewLink.ID))
+		service := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig())
+
+		_, err := service.ResolvePreviewToken(previewTokenOf(t, previewLink.URL), "", enums.PageTypeLanding)
+		assert.ErrorIs(t, err, errs.ErrInvalidPreviewToken)
+	})
+}
+
+func TestCMSService_FindPreviewLinks(t *testing.T) {
+	t.Run("successfully find preview links", func(t *testing.T) {
+		revokedAt := time.Now()
+		active := models.PreviewLink{ID: uuid.New(), PageType: enums.PageTypePartner, Language: enums.PageLanguageEN, ExpiresAt: time.Now().Add(time.Hour)}
+		revoked := models.PreviewLink{ID: uuid.New(), PageType: enums.PageTypePartner, Language: enums.PageLanguageEN, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+		expired := models.PreviewLink{ID: uuid.New(), PageType: enums.PageTypePartner, Language: enums.PageLanguageEN, ExpiresAt: time.Now().Add(-time.Hour)}
+
+		repo := &MockCMSPreviewLinkRepo{
+			findPreviewLinks: func(query dto.PreviewLinkQuery, page, limit int) ([]models.PreviewLink, int64, error) {
+				assert.True(t, query.IncludeInvalid)
+				return []models.PreviewLink{active, revoked, expired}, 3, nil
+			},
+		}
+
+		service := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig())
+
+		previewLinks, totalCount, err := service.FindPreviewLinks(dto.PreviewLinkQuery{PageType: enums.PageTypePartner, IncludeInvalid: true}, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), totalCount)
+		assert.NotEmpty(t, previewLinks[0].URL)
+		assert.Empty(t, previewLinks[1].URL)
+		assert.Empty(t, previewLinks[2].URL)
+	})
+
+	t.Run("failed to find preview links: invalid filters", func(t *testing.T) {
+		service := services.NewCMSPreviewLinkService(&MockCMSPreviewLinkRepo{}, newPreviewLinkConfig())
+
+		_, _, err := service.FindPreviewLinks(dto.PreviewLinkQuery{PageID: "invalid"}, 1, 10)
+		assert.ErrorIs(t, err, errs.ErrInvalidUUIDFormat)
+
+		_, _, err = service.FindPreviewLinks(dto.PreviewLinkQuery{PageType: "unknown"}, 1, 10)
+		assert.ErrorIs(t, err, errs.ErrInvalidPageType)
+	})
+}
+
+func TestCMSService_PreviewLinkPageAccess(t *testing.T) {
+	english := enums.PageLanguageEN
+	// The user may only touch english partner pages
+	access := &dto.PageAccess{Grants: []models.PageGrant{
+		{PageType: enums.PageTypePartner, Action: enums.PermissionActionAll, Language: &english},
+	}}
+	previewLink := &models.PreviewLink{ID: uuid.New(), PageType: enums.PageTypePartner, PageID: uuid.New(), ContentID: uuid.New(), Language: enums.PageLanguageTH}
+
+	t.Run("successfully limit the list to the page grants", func(t *testing.T) {
+		repo := &MockCMSPreviewLinkRepo{
+			findPreviewLinks: func(query dto.PreviewLinkQuery, page, limit int) ([]models.PreviewLink, int64, error) {
+				assert.Equal(t, access, query.Access)
+				return nil, 0, nil
+			},
+		}
+
+		_, _, err := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig()).WithPageAccess(access).FindPreviewLinks(dto.PreviewLinkQuery{}, 1, 10)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed to revoke a link to a content outside the grants: access denied", func(t *testing.T) {
+		repo := &MockCMSPreviewLinkRepo{
+			findPreviewLinkById: func(id uuid.UUID) (*models.PreviewLink, error) { return previewLink, nil },
+			findContentScopes: func(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+				assert.Equal(t, previewLink.PageID, pageId)
+				return []dto.PageContentScope{{ContentID: previewLink.ContentID, Language: enums.PageLanguageTH, Mode: enums.PageModePreview}}, nil
+			},
+			revokePreviewLink: func(id uuid.UUID, revokedAt time.Time) error {
+				t.Fatal("the link must not be revoked")
+				return nil
+			},
+		}
+
+		err := services.NewCMSPreviewLinkService(repo, newPreviewLinkConfig()).WithPageAccess(access).RevokePreviewLink(previewLink.ID)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+}
@@ -0,0 +1,383 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/middleware"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockCMSRoleService struct {
+	mock.Mock
+}
+
+func (m *MockCMSRoleService) FindRoles() ([]models.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockCMSRoleService) AssignUserRole(userId uuid.UUID, roleName string) (*models.User, error) {
+	args := m.Called(userId, roleName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockCMSRoleService) HasPermission(roleName string, resource enums.PermissionResource, action enums.PermissionAction) (bool, error) {
+	args := m.Called(roleName, resource, action)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCMSRoleService) FindPageAccess(roleName string, userId uuid.UUID) (*dto.PageAccess, error) {
+	args := m.Called(roleName, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PageAccess), args.Error(1)
+}
+
+func (m *MockCMSRoleService) FindPageGrants(userId uuid.UUID) ([]models.PageGrant, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PageGrant), args.Error(1)
+}
+
+func (m *MockCMSRoleService) CreatePageGrant(userId uuid.UUID, request dto.CreatePageGrantRequest) (*models.PageGrant, error) {
+	args := m.Called(userId, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PageGrant), args.Error(1)
+}
+
+func (m *MockCMSRoleService) DeletePageGrant(userId, grantId uuid.UUID) error {
+	args := m.Called(userId, grantId)
+	return args.Error(0)
+}
+
+func TestCMSRoleHandler(t *testing.T) {
+	mockService := new(MockCMSRoleService)
+	h := cmsHandler.NewCMSRoleHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/cms/roles", h.HandleGetRoles)
+	app.Put("/cms/users/:userId/role", h.HandleAssignUserRole)
+	app.Get("/cms/users/:userId/page-grants", h.HandleGetPageGrants)
+	app.Post("/cms/users/:userId/page-grants", h.HandleCreatePageGrant)
+	app.Delete("/cms/users/:userId/page-grants/:grantId", h.HandleDeletePageGrant)
+
+	userId := uuid.New()
+
+	t.Run("GET /cms/roles HandleGetRoles", func(t *testing.T) {
+		t.Run("successfully get roles", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindRoles").Return([]models.Role{{Name: "admin"}}, nil)
+
+			resp, _ := app.Test(httptest.NewRequest("GET", "/cms/roles", nil))
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("failed to get roles: internal server error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindRoles").Return(nil, errs.ErrInternalServerError)
+
+			resp, _ := app.Test(httptest.NewRequest("GET", "/cms/roles", nil))
+
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		})
+	})
+
+	t.Run("PUT /cms/users/:userId/role HandleAssignUserRole", func(t *testing.T) {
+		assign := func(id, body string) int {
+			req := httptest.NewRequest("PUT", "/cms/users/"+id+"/role", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+			return resp.StatusCode
+		}
+
+		t.Run("successfully assign role", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("AssignUserRole", userId, "editor").Return(&models.User{ID: userId}, nil)
+
+			assert.Equal(t, fiber.StatusOK, assign(userId.String(), `{"role":"editor"}`))
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to assign role: invalid userId", func(t *testing.T) {
+			assert.Equal(t, fiber.StatusBadRequest, assign("invalid", `{"role":"editor"}`))
+		})
+
+		t.Run("failed to assign role: unknown role", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("AssignUserRole", userId, "owner").Return(nil, errs.ErrInvalidRole)
+
+			assert.Equal(t, fiber.StatusBadRequest, assign(userId.String(), `{"role":"owner"}`))
+		})
+
+		t.Run("failed to assign role: user not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("AssignUserRole", userId, "editor").Return(nil, gorm.ErrRecordNotFound)
+
+			assert.Equal(t, fiber.StatusNotFound, assign(userId.String(), `{"role":"editor"}`))
+		})
+	})
+
+	t.Run("GET /cms/users/:userId/page-grants HandleGetPageGrants", func(t *testing.T) {
+		t.Run("successfully get page grants", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindPageGrants", userId).Return([]models.PageGrant{{UserID: userId, PageType: enums.PageTypeFaq}}, nil)
+
+			resp, _ := app.Test(httptest.NewRequest("GET", "/cms/users/"+userId.String()+"/page-grants", nil))
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("failed to get page grants: user not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindPageGrants", userId).Return(nil, gorm.ErrRecordNotFound)
+
+			resp, _ := app.Test(httptest.NewRequest("GET", "/cms/users/"+userId.String()+"/page-grants", nil))
+
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /cms/users/:userId/page-grants HandleCreatePageGrant", func(t *testing.T) {
+		create := func(body string) int {
+			req := httptest.NewRequest("POST", "/cms/users/"+userId.String()+"/page-grants", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+			return resp.StatusCode
+		}
+		request := dto.CreatePageGrantRequest{PageType: "partner", Language: "en", Action: "*"}
+
+		t.Run("successfully create page grant", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CreatePageGrant", userId, request).Return(&models.PageGrant{UserID: userId}, nil)
+
+			assert.Equal(t, fiber.StatusCreated, create(`{"page_type":"partner","language":"en","action":"*"}`))
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("failed to create page grant: invalid grant", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CreatePageGrant", userId, mock.Anything).Return(nil, errs.ErrInvalidPageGrant)
+
+			assert.Equal(t, fiber.StatusBadRequest, create(`{"page_type":"email","action":"*"}`))
+		})
+	})
+
+	t.Run("DELETE /cms/users/:userId/page-grants/:grantId HandleDeletePageGrant", func(t *testing.T) {
+		grantId := uuid.New()
+
+		t.Run("successfully delete page grant", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("DeletePageGrant", userId, grantId).Return(nil)
+
+			resp, _ := app.Test(httptest.NewRequest("DELETE", "/cms/users/"+userId.String()+"/page-grants/"+grantId.String(), nil))
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("failed to delete page grant: not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("DeletePageGrant", userId, grantId).Return(gorm.ErrRecordNotFound)
+
+			resp, _ := app.Test(httptest.NewRequest("DELETE", "/cms/users/"+userId.String()+"/page-grants/"+grantId.String(), nil))
+
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+	})
+}
+
+func TestPermissionMiddleware(t *testing.T) {
+	const lineKey = "line-secret"
+	const normalKey = "normal-secret"
+
+	mockService := new(MockCMSRoleService)
+	lineUserId := helpers.UUIDFromSub("U1234")
+	editorId := uuid.New()
+	noRoleId := uuid.New()
+	users := map[uuid.UUID]*models.User{
+		lineUserId: {ID: lineUserId, Role: &models.Role{Name: "approver"}},
+		editorId:   {ID: editorId, Role: &models.Role{Name: "editor"}},
+		noRoleId:   {ID: noRoleId},
+	}
+	authRepo := &MockCMSAuthRepo{
+		findUserById: func(id uuid.UUID) (*models.User, error) {
+			user, ok := users[id]
+			if !ok {
+				return nil, gorm.ErrRecordNotFound
+			}
+			return user, nil
+		},
+	}
+
+	ok := func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	}
+	app := fiber.New()
+	pages := app.Group("/cms/landingpages",
+		middleware.CheckAnyTokenMiddleware(lineKey, normalKey, authRepo),
+		middleware.CheckPermissionMiddleware(mockService, enums.PermissionResourcePages))
+	pages.Get("/", ok)
+	pages.Delete("/:pageId", ok)
+	app.Post("/emails/send",
+		middleware.CheckAnyTokenMiddleware(lineKey, normalKey, authRepo),
+		middleware.CheckActionPermissionMiddleware(mockService, enums.PermissionResourceEmails, enums.PermissionActionSend),
+		ok)
+
+	sign := func(key string, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		assert.NoError(t, err)
+		return token
+	}
+	request := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	editorToken := sign(normalKey, jwt.MapClaims{"user_id": editorId.String(), "role": "editor"})
+
+	t.Run("failed without a token: unauthorized", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusUnauthorized, request("GET", "/cms/landingpages", ""))
+		assert.Equal(t, fiber.StatusUnauthorized, request("POST", "/emails/send", ""))
+	})
+
+	t.Run("failed with an invalid token: unauthorized", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusUnauthorized, request("GET", "/cms/landingpages", sign("other-secret", jwt.MapClaims{"role": "admin"})))
+	})
+
+	t.Run("failed with a preview token: unauthorized", func(t *testing.T) {
+		token := sign(normalKey, jwt.MapClaims{"user_id": editorId.String(), "role": "admin", "typ": helpers.PreviewTokenType})
+
+		assert.Equal(t, fiber.StatusUnauthorized, request("GET", "/cms/landingpages", token))
+	})
+
+	t.Run("failed with a user that no longer exists: unauthorized", func(t *testing.T) {
+		token := sign(normalKey, jwt.MapClaims{"user_id": uuid.New().String(), "role": "admin"})
+
+		assert.Equal(t, fiber.StatusUnauthorized, request("GET", "/cms/landingpages", token))
+	})
+
+	t.Run("failed without a role: forbidden", func(t *testing.T) {
+		token := sign(normalKey, jwt.MapClaims{"user_id": noRoleId.String()})
+
+		assert.Equal(t, fiber.StatusForbidden, request("GET", "/cms/landingpages", token))
+	})
+
+	t.Run("failed with a role taken away after login: forbidden", func(t *testing.T) {
+		token := sign(normalKey, jwt.MapClaims{"user_id": noRoleId.String(), "role": "admin"})
+
+		assert.Equal(t, fiber.StatusForbidden, request("GET", "/cms/landingpages", token))
+	})
+
+	t.Run("successfully use the current role of the user instead of the token", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("HasPermission", "editor", enums.PermissionResourcePages, enums.PermissionActionRead).Return(true, nil)
+		token := sign(normalKey, jwt.MapClaims{"user_id": editorId.String(), "role": "admin"})
+
+		assert.Equal(t, fiber.StatusOK, request("GET", "/cms/landingpages", token))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("successfully read with the permission of the method", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("HasPermission", "editor", enums.PermissionResourcePages, enums.PermissionActionRead).Return(true, nil)
+
+		assert.Equal(t, fiber.StatusOK, request("GET", "/cms/landingpages", editorToken))
+	})
+
+	t.Run("failed to delete without the permission: forbidden", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("HasPermission", "editor", enums.PermissionResourcePages, enums.PermissionActionDelete).Return(false, nil)
+
+		assert.Equal(t, fiber.StatusForbidden, request("DELETE", "/cms/landingpages/"+uuid.New().String(), editorToken))
+	})
+
+	t.Run("failed to send email without the send permission: forbidden", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("HasPermission", "editor", enums.PermissionResourceEmails, enums.PermissionActionSend).Return(false, nil)
+
+		assert.Equal(t, fiber.StatusForbidden, request("POST", "/emails/send", editorToken))
+	})
+
+	t.Run("successfully use the role of the LINE user instead of the token", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("HasPermission", "approver", enums.PermissionResourcePages, enums.PermissionActionRead).Return(true, nil)
+		token := sign(lineKey, jwt.MapClaims{"sub": "U1234", "role": "admin"})
+
+		assert.Equal(t, fiber.StatusOK, request("GET", "/cms/landingpages", token))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("failed to check permission: internal server error", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("HasPermission", "editor", enums.PermissionResourcePages, enums.PermissionActionRead).Return(false, errs.ErrInternalServerError)
+
+		assert.Equal(t, fiber.StatusInternalServerError, request("GET", "/cms/landingpages", editorToken))
+	})
+}
+
+func TestPageAccessMiddleware(t *testing.T) {
+	mockService := new(MockCMSRoleService)
+	userId := uuid.New()
+
+	app := fiber.New()
+	app.Get("/cms/faqpages",
+		func(c *fiber.Ctx) error {
+			c.Locals("user", jwt.MapClaims{"user_id": userId.String(), "role": "editor"})
+			return c.Next()
+		},
+		middleware.PageAccessMiddleware(mockService),
+		func(c *fiber.Ctx) error {
+			access := helpers.GetPageAccessFromContext(c)
+			if access == nil || len(access.Grants) != 1 {
+				return c.SendStatus(fiber.StatusTeapot)
+			}
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+	t.Run("successfully leave the page access of the user", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("FindPageAccess", "editor", userId).Return(&dto.PageAccess{Grants: []models.PageGrant{{UserID: userId}}}, nil)
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/cms/faqpages", nil))
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("failed to find page access: internal server error", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("FindPageAccess", "editor", userId).Return(nil, errs.ErrInternalServerError)
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/cms/faqpages", nil))
+
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/helpers"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCMSRepo_FindRoles(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsRoleRepo := repo.NewCMSRoleRepository(gormDB)
+
+	t.Run("successfully find roles with their permissions", func(t *testing.T) {
+		roleId := uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "roles" ORDER BY name`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(roleId, "editor"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "role_permissions" WHERE "role_permissions"."role_id" = $1`)).
+			WithArgs(roleId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "role_id", "resource", "action"}).
+				AddRow(uuid.New(), roleId, "pages", "update"))
+
+		roles, err := cmsRoleRepo.FindRoles()
+		assert.NoError(t, err)
+		assert.Len(t, roles, 1)
+		assert.Equal(t, "editor", roles[0].Name)
+		assert.Len(t, roles[0].Permissions, 1)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_FindRoleByName(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsRoleRepo := repo.NewCMSRoleRepository(gormDB)
+
+	t.Run("failed to find role: not found", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "roles" WHERE name = $1 ORDER BY "roles"."id" LIMIT $2`)).
+			WithArgs("owner", 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		role, err := cmsRoleRepo.FindRoleByName("owner")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, role)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_UpdateUserRole(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsRoleRepo := repo.NewCMSRoleRepository(gormDB)
+	userId := uuid.New()
+	roleId := uuid.New()
+
+	t.Run("successfully update user role", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "role_id"=$1,"updated_at"=$2 WHERE id = $3`)).
+			WithArgs(&roleId, sqlmock.AnyArg(), userId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := cmsRoleRepo.UpdateUserRole(userId, &roleId)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed to update user role: user not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "role_id"=$1,"updated_at"=$2 WHERE id = $3`)).
+			WithArgs(nil, sqlmock.AnyArg(), userId).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := cmsRoleRepo.UpdateUserRole(userId, nil)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
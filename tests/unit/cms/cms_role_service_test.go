@@ -0,0 +1,325 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type MockCMSRoleRepo struct {
+	findRoles              func() ([]models.Role, error)
+	findRoleByName         func(name string) (*models.Role, error)
+	updateUserRole         func(userId uuid.UUID, roleId *uuid.UUID) error
+	findPageGrantsByUserId func(userId uuid.UUID) ([]models.PageGrant, error)
+	createPageGrant        func(grant *models.PageGrant) (*models.PageGrant, error)
+	deletePageGrant        func(userId, grantId uuid.UUID) error
+}
+
+func (m *MockCMSRoleRepo) FindRoles() ([]models.Role, error) {
+	return m.findRoles()
+}
+
+func (m *MockCMSRoleRepo) FindRoleByName(name string) (*models.Role, error) {
+	return m.findRoleByName(name)
+}
+
+func (m *MockCMSRoleRepo) UpdateUserRole(userId uuid.UUID, roleId *uuid.UUID) error {
+	return m.updateUserRole(userId, roleId)
+}
+
+func (m *MockCMSRoleRepo) FindPageGrantsByUserId(userId uuid.UUID) ([]models.PageGrant, error) {
+	return m.findPageGrantsByUserId(userId)
+}
+
+func (m *MockCMSRoleRepo) CreatePageGrant(grant *models.PageGrant) (*models.PageGrant, error) {
+	return m.createPageGrant(grant)
+}
+
+func (m *MockCMSRoleRepo) DeletePageGrant(userId, grantId uuid.UUID) error {
+	return m.deletePageGrant(userId, grantId)
+}
+
+func mockRoles() []models.Role {
+	return []models.Role{
+		{Name: "admin", Permissions: []models.RolePermission{
+			{Resource: enums.PermissionResourceAll, Action: enums.PermissionActionAll},
+		}},
+		{Name: "approver", Permissions: []models.RolePermission{
+			{Resource: enums.PermissionResourcePages, Action: enums.PermissionActionRead},
+			{Resource: enums.PermissionResourcePages, Action: enums.PermissionActionPublish},
+		}},
+	}
+}
+
+func TestCMSRoleService_AssignUserRole(t *testing.T) {
+	cfg := &config.Config{}
+	userId := uuid.New()
+	roleId := uuid.New()
+
+	t.Run("successfully assign role", func(t *testing.T) {
+		var assigned *uuid.UUID
+		repo := &MockCMSRoleRepo{
+			findRoleByName: func(name string) (*models.Role, error) {
+				assert.Equal(t, "editor", name)
+				return &models.Role{ID: roleId, Name: name}, nil
+			},
+			updateUserRole: func(id uuid.UUID, roleId *uuid.UUID) error {
+				assert.Equal(t, userId, id)
+				assigned = roleId
+				return nil
+			},
+		}
+		password := "hashed"
+		authRepo := &MockCMSAuthRepo{
+			findUserById: func(id uuid.UUID) (*models.User, error) {
+				return &models.User{ID: id, Password: &password, RoleID: &roleId}, nil
+			},
+		}
+
+		service := services.NewCMSRoleService(repo, authRepo, cfg)
+
+		user, err := service.AssignUserRole(userId, "editor")
+		assert.NoError(t, err)
+		assert.Equal(t, &roleId, assigned)
+		assert.Equal(t, &roleId, user.RoleID)
+		assert.Nil(t, user.Password)
+	})
+
+	t.Run("successfully remove role", func(t *testing.T) {
+		assigned := &roleId
+		repo := &MockCMSRoleRepo{
+			updateUserRole: func(id uuid.UUID, roleId *uuid.UUID) error {
+				assigned = roleId
+				return nil
+			},
+		}
+		authRepo := &MockCMSAuthRepo{
+			findUserById: func(id uuid.UUID) (*models.User, error) {
+				return &models.User{ID: id}, nil
+			},
+		}
+
+		service := services.NewCMSRoleService(repo, authRepo, cfg)
+
+		_, err := service.AssignUserRole(userId, "")
+		assert.NoError(t, err)
+		assert.Nil(t, assigned)
+	})
+
+	t.Run("failed to assign role: unknown role", func(t *testing.T) {
+		repo := &MockCMSRoleRepo{
+			findRoleByName: func(name string) (*models.Role, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+
+		service := services.NewCMSRoleService(repo, &MockCMSAuthRepo{}, cfg)
+
+		user, err := service.AssignUserRole(userId, "owner")
+		assert.ErrorIs(t, err, errs.ErrInvalidRole)
+		assert.Nil(t, user)
+	})
+
+	t.Run("failed to assign role: user not found", func(t *testing.T) {
+		repo := &MockCMSRoleRepo{
+			findRoleByName: func(name string) (*models.Role, error) {
+				return &models.Role{ID: roleId, Name: name}, nil
+			},
+			updateUserRole: func(id uuid.UUID, roleId *uuid.UUID) error {
+				return gorm.ErrRecordNotFound
+			},
+		}
+
+		service := services.NewCMSRoleService(repo, &MockCMSAuthRepo{}, cfg)
+
+		user, err := service.AssignUserRole(userId, "editor")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, user)
+	})
+}
+
+func TestCMSRoleService_HasPermission(t *testing.T) {
+	t.Run("successfully check permissions with wildcards", func(t *testing.T) {
+		repo := &MockCMSRoleRepo{
+			findRoles: func() ([]models.Role, error) {
+				return mockRoles(), nil
+			},
+		}
+
+		service := services.NewCMSRoleService(repo, &MockCMSAuthRepo{}, &config.Config{Permission: config.PermissionConfig{CacheTTL: time.Minute}})
+
+		cases := []struct {
+			role     string
+			resource enums.PermissionResource
+			action   enums.PermissionAction
+			allowed  bool
+		}{
+			{"admin", enums.PermissionResourceUsers, enums.PermissionActionDelete, true},
+			{"approver", enums.PermissionResourcePages, enums.PermissionActionPublish, true},
+			{"approver", enums.PermissionResourcePages, enums.PermissionActionDelete, false},
+			{"approver", enums.PermissionResourceEmails, enums.PermissionActionRead, false},
+			{"unknown", enums.PermissionResourcePages, enums.PermissionActionRead, false},
+			{"", enums.PermissionResourcePages, enums.PermissionActionRead, false},
+		}
+		for _, c := range cases {
+			allowed, err := service.HasPermission(c.role, c.resource, c.action)
+			assert.NoError(t, err)
+			assert.Equal(t, c.allowed, allowed, "%s %s on %s", c.role, c.action, c.resource)
+		}
+	})
+
+	t.Run("successfully cache roles until the TTL passes", func(t *testing.T) {
+		loads := 0
+		repo := &MockCMSRoleRepo{
+			findRoles: func() ([]models.Role, error) {
+				loads++
+				return mockRoles(), nil
+			},
+		}
+
+		cached := services.NewCMSRoleService(repo, &MockCMSAuthRepo{}, &config.Config{Permission: config.PermissionConfig{CacheTTL: time.Minute}})
+		for i := 0; i < 3; i++ {
+			_, err := cached.HasPermission("admin", enums.PermissionResourcePages, enums.PermissionActionRead)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 1, loads)
+
+		loads = 0
+		uncached := services.NewCMSRoleService(repo, &MockCMSAuthRepo{}, &config.Config{})
+		for i := 0; i < 3; i++ {
+			_, err := uncached.HasPermission("admin", enums.PermissionResourcePages, enums.PermissionActionRead)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 3, loads)
+	})
+
+	t.Run("failed to check permission: internal server error", func(t *testing.T) {
+		repo := &MockCMSRoleRepo{
+			findRoles: func() ([]models.Role, error) {
+				return nil, errs.ErrInternalServerError
+			},
+		}
+
+		service := services.NewCMSRoleService(repo, &MockCMSAuthRepo{}, &config.Config{})
+
+		allowed, err := service.HasPermission("admin", enums.PermissionResourcePages, enums.PermissionActionRead)
+		assert.Error(t, err)
+		assert.False(t, allowed)
+	})
+}
+
+func TestCMSRoleService_FindPageAccess(t *testing.T) {
+	userId := uuid.New()
+	grants := []models.PageGrant{{UserID: userId, PageType: enums.PageTypeFaq, Action: enums.PermissionActionAll}}
+
+	t.Run("successfully find the page grants and the publish permission", func(t *testing.T) {
+		repo := &MockCMSRoleRepo{
+			findRoles: func() ([]models.Role, error) {
+				return mockRoles(), nil
+			},
+			findPageGrantsByUserId: func(id uuid.UUID) ([]models.PageGrant, error) {
+				assert.Equal(t, userId, id)
+				return grants, nil
+			},
+		}
+
+		service := services.NewCMSRoleService(repo, &MockCMSAuthRepo{}, &config.Config{})
+
+		access, err := service.FindPageAccess("approver", userId)
+		assert.NoError(t, err)
+		assert.True(t, access.CanPublish)
+		assert.Equal(t, grants, access.Grants)
+
+		access, err = service.FindPageAccess("", userId)
+		assert.NoError(t, err)
+		assert.False(t, access.CanPublish)
+	})
+
+	t.Run("failed to find page access: internal server error", func(t *testing.T) {
+		repo := &MockCMSRoleRepo{
+			findRoles: func() ([]models.Role, error) {
+				return mockRoles(), nil
+			},
+			findPageGrantsByUserId: func(id uuid.UUID) ([]models.PageGrant, error) {
+				return nil, errs.ErrInternalServerError
+			},
+		}
+
+		service := services.NewCMSRoleService(repo, &MockCMSAuthRepo{}, &config.Config{})
+
+		access, err := service.FindPageAccess("approver", userId)
+		assert.Error(t, err)
+		assert.Nil(t, access)
+	})
+}
+
+func TestCMSRoleService_CreatePageGrant(t *testing.T) {
+	cfg := &config.Config{}
+	userId := uuid.New()
+	categoryId := uuid.New()
+	authRepo := &MockCMSAuthRepo{
+		findUserById: func(id uuid.UUID) (*models.User, error) {
+			return &models.User{ID: id}, nil
+		},
+	}
+
+	t.Run("successfully create page grant", func(t *testing.T) {
+		repo := &MockCMSRoleRepo{
+			createPageGrant: func(grant *models.PageGrant) (*models.PageGrant, error) {
+				return grant, nil
+			},
+		}
+		category := categoryId.String()
+
+		service := services.NewCMSRoleService(repo, authRepo, cfg)
+
+		grant, err := service.CreatePageGrant(userId, dto.CreatePageGrantRequest{PageType: "partner", Language: "EN", CategoryID: &category, Action: "update"})
+		assert.NoError(t, err)
+		assert.Equal(t, userId, grant.UserID)
+		assert.Equal(t, enums.PageTypePartner, grant.PageType)
+		assert.Equal(t, enums.PageLanguageEN, *grant.Language)
+		assert.Equal(t, &categoryId, grant.CategoryID)
+		assert.Equal(t, enums.PermissionActionUpdate, grant.Action)
+	})
+
+	t.Run("failed to create page grant: invalid grant", func(t *testing.T) {
+		service := services.NewCMSRoleService(&MockCMSRoleRepo{}, authRepo, cfg)
+		invalidCategory := "not-a-uuid"
+
+		requests := []dto.CreatePageGrantRequest{
+			{PageType: "email", Action: "read"},
+			{PageType: "faq", Action: "send"},
+			{PageType: "faq", Action: "read", Language: "jp"},
+			{PageType: "faq", Action: "read", CategoryID: &invalidCategory},
+		}
+		for _, request := range requests {
+			grant, err := service.CreatePageGrant(userId, request)
+			assert.ErrorIs(t, err, errs.ErrInvalidPageGrant, "%+v", request)
+			assert.Nil(t, grant)
+		}
+	})
+
+	t.Run("failed to create page grant: user not found", func(t *testing.T) {
+		authRepo := &MockCMSAuthRepo{
+			findUserById: func(id uuid.UUID) (*models.User, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+
+		service := services.NewCMSRoleService(&MockCMSRoleRepo{}, authRepo, cfg)
+
+		grant, err := service.CreatePageGrant(userId, dto.CreatePageGrantRequest{PageType: "faq", Action: "*"})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, grant)
+	})
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCMSUsageService struct {
+	mock.Mock
+}
+
+func (m *MockCMSUsageService) RebuildUsageIndex(ctx context.Context) (*dto.UsageIndexSummary, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UsageIndexSummary), args.Error(1)
+}
+
+func (m *MockCMSUsageService) GetUsages(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error) {
+	args := m.Called(itemType, itemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UsageReference), args.Error(1)
+}
+
+func (m *MockCMSUsageService) GetDeleteImpact(itemType enums.UsageItemType, itemId uuid.UUID) (*dto.UsageImpact, error) {
+	args := m.Called(itemType, itemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UsageImpact), args.Error(1)
+}
+
+func (m *MockCMSUsageService) CheckDelete(itemType enums.UsageItemType, itemId uuid.UUID, force bool) (*dto.UsageImpact, error) {
+	args := m.Called(itemType, itemId, force)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UsageImpact), args.Error(1)
+}
+
+func (m *MockCMSUsageService) WithPageAccess(access *dto.PageAccess) services.CMSUsageServiceInterface {
+	return m
+}
+
+func TestCMSUsageHandler(t *testing.T) {
+	mockService := &MockCMSUsageService{}
+	handler := cmsHandler.NewCMSUsageHandler(mockService)
+
+	app := fiber.New()
+	app.Post("/cms/usages/rebuild", handler.HandleRebuildUsageIndex)
+	app.Get("/cms/usages/:itemType/:itemId", handler.HandleGetUsages)
+	app.Get("/cms/usages/:itemType/:itemId/impact", handler.HandleGetDeleteImpact)
+
+	mediaId := uuid.New()
+	pageId := uuid.New()
+
+	t.Run("GET /cms/usages/:itemType/:itemId HandleGetUsages", func(t *testing.T) {
+		t.Run("successfully get usages", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetUsages", enums.UsageItemMediaFile, mediaId).Return([]models.UsageReference{
+				{ItemType: enums.UsageItemMediaFile, ItemID: mediaId, ReferrerType: enums.UsageReferrerContent, PageID: &pageId, Label: "Summer Sale", Field: "html_input", Published: true},
+			}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/usages/media_file/"+mediaId.String(), nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"label":"Summer Sale"`)
+		})
+
+		t.Run("failed to get usages: invalid item type", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetUsages", enums.UsageItemType("user"), mediaId).Return(nil, errs.ErrInvalidUsageItemType)
+
+			req := httptest.NewRequest("GET", "/cms/usages/user/"+mediaId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("failed to get usages: invalid itemId", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest("GET", "/cms/usages/media_file/abc", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			mockService.AssertNotCalled(t, "GetUsages", mock.Anything, mock.Anything)
+		})
+	})
+
+	t.Run("GET /cms/usages/:itemType/:itemId/impact HandleGetDeleteImpact", func(t *testing.T) {
+		t.Run("successfully get delete impact", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetDeleteImpact", enums.UsageItemLandingPage, pageId).Return(&dto.UsageImpact{
+				ItemType: enums.UsageItemLandingPage, ItemID: pageId, References: 3, PublishedReferences: 1, Blocked: true,
+			}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/usages/landing_page/"+pageId.String()+"/impact", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"blocked":true`)
+		})
+
+		t.Run("failed to get delete impact: database error", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("GetDeleteImpact", enums.UsageItemLandingPage, pageId).Return(nil, errs.ErrInternalServerError)
+
+			req := httptest.NewRequest("GET", "/cms/usages/landing_page/"+pageId.String()+"/impact", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /cms/usages/rebuild HandleRebuildUsageIndex", func(t *testing.T) {
+		t.Run("successfully rebuild usage index", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("RebuildUsageIndex", mock.Anything).Return(&dto.UsageIndexSummary{ReferrersScanned: 4, References: 7}, nil)
+
+			req := httptest.NewRequest("POST", "/cms/usages/rebuild", nil)
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), `"references":7`)
+		})
+	})
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCMSRepo_Usage(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsUsageRepo := repo.NewCMSUsageRepository(gormDB)
+
+	mediaId := uuid.New()
+	contentId := uuid.New()
+	pageId := uuid.New()
+
+	t.Run("successfully replace the usage index", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM usage_references`)).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "usage_references" ("item_type","item_id","referrer_type","referrer_id","page_type","page_id","language","label","field","published","indexed_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING "id"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectCommit()
+
+		err := cmsUsageRepo.ReplaceUsageIndex([]models.UsageReference{{
+			ItemType:     enums.UsageItemMediaFile,
+			ItemID:       mediaId,
+			ReferrerType: enums.UsageReferrerContent,
+			ReferrerID:   contentId,
+			Field:        "html_input",
+			Published:    true,
+			IndexedAt:    time.Now(),
+		}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully clear the usage index", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM usage_references`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := cmsUsageRepo.ReplaceUsageIndex(nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully find the usages of an item, published first", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "usage_references" WHERE item_type = $1 AND item_id = $2 ORDER BY published DESC, referrer_type, label, field`)).
+			WithArgs(enums.UsageItemMediaFile, mediaId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "item_type", "item_id", "referrer_type", "referrer_id", "field", "published"}).
+				AddRow(uuid.New(), enums.UsageItemMediaFile, mediaId, enums.UsageReferrerContent, contentId, "html_input", true))
+
+		references, err := cmsUsageRepo.FindUsages(enums.UsageItemMediaFile, mediaId)
+		assert.NoError(t, err)
+		assert.Len(t, references, 1)
+		assert.True(t, references[0].Published)
+	})
+
+	t.Run("successfully find the current landing contents", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "landing_contents" WHERE mode NOT IN ($1,$2)`)).
+			WithArgs(enums.PageModeHistories, enums.PageModePreview).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		contents, err := cmsUsageRepo.FindCurrentLandingContents(nil)
+		assert.NoError(t, err)
+		assert.Empty(t, contents)
+	})
+
+	t.Run("successfully find the current contents of a page language", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "partner_contents" WHERE mode NOT IN ($1,$2) AND page_id = $3 AND language = $4`)).
+			WithArgs(enums.PageModeHistories, enums.PageModePreview, pageId, enums.PageLanguageEN).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		contents, err := cmsUsageRepo.FindCurrentPartnerContents(&dto.UsagePageScope{PageType: enums.PageTypePartner, PageID: pageId, Language: enums.PageLanguageEN})
+		assert.NoError(t, err)
+		assert.Empty(t, contents)
+	})
+
+	t.Run("successfully find the paths of the current contents", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT $1 AS page_type, page_id, url_alias AS path FROM landing_contents WHERE mode NOT IN ($2,$3)`)).
+			WillReturnRows(sqlmock.NewRows([]string{"page_type", "page_id", "path"}).
+				AddRow(enums.PageTypeLanding, pageId, "summer-sale"))
+
+		paths, err := cmsUsageRepo.FindCurrentPagePaths()
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.UsagePagePath{{PageType: enums.PageTypeLanding, PageID: pageId, Path: "summer-sale"}}, paths)
+	})
+
+	t.Run("successfully replace the usages of a page language", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "usage_references" WHERE (referrer_type IN ($1,$2) AND page_type = $3 AND page_id = $4) AND language = $5`)).
+			WithArgs(enums.UsageReferrerContent, enums.UsageReferrerRelation, enums.PageTypeLanding, pageId, enums.PageLanguageEN).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "usage_references"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectCommit()
+
+		err := cmsUsageRepo.ReplacePageUsages(dto.UsagePageScope{PageType: enums.PageTypeLanding, PageID: pageId, Language: enums.PageLanguageEN}, []models.UsageReference{{
+			ItemType:     enums.UsageItemMediaFile,
+			ItemID:       mediaId,
+			ReferrerType: enums.UsageReferrerContent,
+			ReferrerID:   contentId,
+			PageType:     enums.PageTypeLanding,
+			PageID:       &pageId,
+			Language:     enums.PageLanguageEN,
+			Field:        "html_input",
+			IndexedAt:    time.Now(),
+		}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully clear the usages of a deleted page", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "usage_references" WHERE referrer_type IN ($1,$2) AND page_type = $3 AND page_id = $4`)).
+			WithArgs(enums.UsageReferrerContent, enums.UsageReferrerRelation, enums.PageTypeFaq, pageId).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		err := cmsUsageRepo.ReplacePageUsages(dto.UsagePageScope{PageType: enums.PageTypeFaq, PageID: pageId}, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully delete the usages of a deleted media file", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "usage_references" WHERE item_type = $1 AND item_id = $2`)).
+			WithArgs(enums.UsageItemMediaFile, mediaId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := cmsUsageRepo.DeleteItemUsages(enums.UsageItemMediaFile, mediaId)
+		assert.NoError(t, err)
+	})
+
+	t.Run("successfully move the usages of a replaced media file", func(t *testing.T) {
+		replacementId := uuid.New()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "usage_references" SET "item_id"=$1 WHERE item_type = $2 AND item_id = $3`)).
+			WithArgs(replacementId, enums.UsageItemMediaFile, mediaId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := cmsUsageRepo.MoveItemUsages(enums.UsageItemMediaFile, mediaId, replacementId)
+		assert.NoError(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
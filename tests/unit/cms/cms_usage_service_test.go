@@ -0,0 +1,394 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+)
+
+type MockCMSUsageRepo struct {
+	landingContents   []models.LandingContent
+	partnerContents   []models.PartnerContent
+	faqContents       []models.FaqContent
+	relations         []models.ContentRelation
+	categories        []models.Category
+	emailContents     []models.EmailContent
+	forms             []models.Form
+	mediaFiles        []models.MediaFile
+	pagePaths         []dto.UsagePagePath
+	replaceUsageIndex func(references []models.UsageReference) error
+	replacePageUsages func(scope dto.UsagePageScope, references []models.UsageReference) error
+	deleteItemUsages  func(itemType enums.UsageItemType, itemId uuid.UUID) error
+	moveItemUsages    func(itemType enums.UsageItemType, fromId, toId uuid.UUID) error
+	findUsages        func(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error)
+	findContentScopes func(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error)
+}
+
+func (m *MockCMSUsageRepo) FindCurrentLandingContents(scope *dto.UsagePageScope) ([]models.LandingContent, error) {
+	return m.landingContents, nil
+}
+
+func (m *MockCMSUsageRepo) FindCurrentPartnerContents(scope *dto.UsagePageScope) ([]models.PartnerContent, error) {
+	return m.partnerContents, nil
+}
+
+func (m *MockCMSUsageRepo) FindCurrentFaqContents(scope *dto.UsagePageScope) ([]models.FaqContent, error) {
+	return m.faqContents, nil
+}
+
+func (m *MockCMSUsageRepo) FindCurrentPagePaths() ([]dto.UsagePagePath, error) {
+	return m.pagePaths, nil
+}
+
+func (m *MockCMSUsageRepo) FindContentRelations(scope *dto.UsagePageScope) ([]models.ContentRelation, error) {
+	return m.relations, nil
+}
+
+func (m *MockCMSUsageRepo) FindCategories() ([]models.Category, error) {
+	return m.categories, nil
+}
+
+func (m *MockCMSUsageRepo) FindEmailContents() ([]models.EmailContent, error) {
+	return m.emailContents, nil
+}
+
+func (m *MockCMSUsageRepo) FindForms() ([]models.Form, error) {
+	return m.forms, nil
+}
+
+func (m *MockCMSUsageRepo) FindMediaFiles() ([]models.MediaFile, error) {
+	return m.mediaFiles, nil
+}
+
+func (m *MockCMSUsageRepo) ReplaceUsageIndex(references []models.UsageReference) error {
+	return m.replaceUsageIndex(references)
+}
+
+func (m *MockCMSUsageRepo) ReplacePageUsages(scope dto.UsagePageScope, references []models.UsageReference) error {
+	return m.replacePageUsages(scope, references)
+}
+
+func (m *MockCMSUsageRepo) DeleteItemUsages(itemType enums.UsageItemType, itemId uuid.UUID) error {
+	return m.deleteItemUsages(itemType, itemId)
+}
+
+func (m *MockCMSUsageRepo) MoveItemUsages(itemType enums.UsageItemType, fromId, toId uuid.UUID) error {
+	return m.moveItemUsages(itemType, fromId, toId)
+}
+
+func (m *MockCMSUsageRepo) FindUsages(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error) {
+	return m.findUsages(itemType, itemId)
+}
+
+func (m *MockCMSUsageRepo) FindContentScopes(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+	return m.findContentScopes(pageType, pageId)
+}
+
+func usageKeys(references []models.UsageReference) []string {
+	keys := []string{}
+	for _, reference := range references {
+		published := "draft"
+		if reference.Published {
+			published = "published"
+		}
+		keys = append(keys, string(reference.ItemType)+" "+reference.ItemID.String()+" "+string(reference.ReferrerType)+" "+reference.Field+" "+published)
+	}
+	return keys
+}
+
+func TestCMSUsageService_RebuildUsageIndex(t *testing.T) {
+	cfg := &config.Config{
+		App:   config.AppConfig{WebBaseURL: "https://example.com", APIBaseURL: "https://api.example.com"},
+		Usage: config.UsageConfig{MaxAge: time.Minute},
+	}
+
+	landingPageId := uuid.New()
+	partnerPageId := uuid.New()
+	faqPageId := uuid.New()
+	categoryId := uuid.New()
+	bannerId := uuid.New()
+	logoId := uuid.New()
+	relationId := uuid.New()
+	faqContentId := uuid.New()
+	emailContentId := uuid.New()
+	formId := uuid.New()
+
+	repo := &MockCMSUsageRepo{
+		mediaFiles: []models.MediaFile{
+			{ID: bannerId, DownloadURL: "https://api.example.com/files/banner.png"},
+			{ID: logoId, DownloadURL: "https://api.example.com/files/logo.png"},
+		},
+		landingContents: []models.LandingContent{{
+			ID: uuid.New(), PageID: landingPageId, Language: enums.PageLanguageEN, Title: "Summer Sale",
+			WorkflowStatus: enums.WorkflowPublished, UrlAlias: "summer-sale",
+			HTMLInput:  `<img src="https://api.example.com/files/banner.png"><a href="/en/partners/acme">Acme</a><a href="https://example.com/en/summer-sale">Self</a><a href="https://other.com/files/logo.png">Elsewhere</a>`,
+			Categories: []*models.Category{{ID: categoryId}},
+			Components: []*models.Component{{Props: datatypes.JSON(`{"image":"/files/banner.png"}`)}},
+		}},
+		partnerContents: []models.PartnerContent{{
+			ID: uuid.New(), PageID: partnerPageId, Language: enums.PageLanguageEN, Title: "Acme",
+			WorkflowStatus: enums.WorkflowDraft, URL: "partners/acme",
+			CompanyLogo: "/files/logo.png",
+			MetaTag:     &models.MetaTag{OGImage: "https://api.example.com/files/banner.png"},
+		}},
+		faqContents: []models.FaqContent{{
+			ID: faqContentId, PageID: faqPageId, Language: enums.PageLanguageEN, Title: "How to apply",
+			WorkflowStatus: enums.WorkflowPublished, URL: "faq/apply",
+		}},
+		relations: []models.ContentRelation{
+			{ID: relationId, SourceContentID: faqContentId, SourcePageType: enums.PageTypeFaq, SourcePageID: faqPageId, Language: enums.PageLanguageEN, RelationType: enums.RelationRelatedLink, TargetPageType: enums.PageTypeLanding, TargetPageID: landingPageId},
+		},
+		emailContents: []models.EmailContent{{ID: emailContentId, Label: "welcome", TopImgLink: "https://api.example.com/files/logo.png"}},
+		forms: []models.Form{{ID: formId, Name: "Contact", Sections: []models.FormSection{{
+			Fields: []models.FormField{{Properties: datatypes.JSON(`{"image":"https://api.example.com/files/banner.png"}`)}},
+		}}}},
+	}
+
+	var indexed []models.UsageReference
+	repo.replaceUsageIndex = func(references []models.UsageReference) error {
+		indexed = references
+		return nil
+	}
+	service := services.NewCMSUsageService(repo, cfg)
+
+	t.Run("successfully index the references of every referrer", func(t *testing.T) {
+		summary, err := service.RebuildUsageIndex(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 6, summary.ReferrersScanned)
+		assert.ElementsMatch(t, []string{
+			"category " + categoryId.String() + " content categories published",
+			"media_file " + bannerId.String() + " content html_input published",
+			"partner_page " + partnerPageId.String() + " content html_input published",
+			"media_file " + bannerId.String() + " content components[0] published",
+			"media_file " + logoId.String() + " content company_logo draft",
+			"media_file " + bannerId.String() + " content meta_tag.og_image draft",
+			"landing_page " + landingPageId.String() + " relation related_link published",
+			"media_file " + logoId.String() + " email_content top_img_link published",
+			"media_file " + bannerId.String() + " form sections[0].fields[0].properties published",
+		}, usageKeys(indexed))
+		assert.Equal(t, summary.References, len(indexed))
+
+		for _, reference := range indexed {
+			if reference.ReferrerType == enums.UsageReferrerRelation {
+				assert.Equal(t, "How to apply", reference.Label)
+				assert.Equal(t, relationId, reference.ReferrerID)
+			}
+		}
+	})
+}
+
+func TestCMSUsageService_CheckDelete(t *testing.T) {
+	cfg := &config.Config{Usage: config.UsageConfig{MaxAge: time.Hour}}
+	mediaId := uuid.New()
+	pageId := uuid.New()
+
+	rebuilds := 0
+	repo := &MockCMSUsageRepo{
+		replaceUsageIndex: func(references []models.UsageReference) error {
+			rebuilds++
+			return nil
+		},
+		findUsages: func(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error) {
+			assert.Equal(t, enums.UsageItemMediaFile, itemType)
+			return []models.UsageReference{
+				{ItemType: itemType, ItemID: itemId, ReferrerType: enums.UsageReferrerContent, PageID: &pageId, Field: "html_input", Published: true},
+				{ItemType: itemType, ItemID: itemId, ReferrerType: enums.UsageReferrerContent, PageID: &pageId, Field: "components[0]"},
+			}, nil
+		},
+	}
+	service := services.NewCMSUsageService(repo, cfg)
+
+	t.Run("failed to delete: referenced by published content", func(t *testing.T) {
+		impact, err := service.CheckDelete(enums.UsageItemMediaFile, mediaId, false)
+		assert.ErrorIs(t, err, errs.ErrItemInUse)
+		assert.Equal(t, 2, impact.References)
+		assert.Equal(t, 1, impact.PublishedReferences)
+		assert.True(t, impact.Blocked)
+	})
+
+	t.Run("successfully force the delete of an item in use", func(t *testing.T) {
+		impact, err := service.CheckDelete(enums.UsageItemMediaFile, mediaId, true)
+		assert.NoError(t, err)
+		assert.True(t, impact.Blocked)
+	})
+
+	t.Run("successfully reuse an index younger than the max age", func(t *testing.T) {
+		assert.Equal(t, 1, rebuilds)
+	})
+
+	t.Run("failed to check the delete: invalid item type", func(t *testing.T) {
+		impact, err := service.CheckDelete(enums.UsageItemType("user"), mediaId, false)
+		assert.ErrorIs(t, err, errs.ErrInvalidUsageItemType)
+		assert.Nil(t, impact)
+	})
+}
+
+func TestCMSUsageService_PageAccess(t *testing.T) {
+	cfg := &config.Config{Usage: config.UsageConfig{MaxAge: time.Hour}}
+	mediaId := uuid.New()
+	englishPageId := uuid.New()
+	thaiPageId := uuid.New()
+	english := enums.PageLanguageEN
+
+	// The user may only read english landing pages
+	access := &dto.PageAccess{Grants: []models.PageGrant{
+		{PageType: enums.PageTypeLanding, Action: enums.PermissionActionRead, Language: &english},
+	}}
+	repo := &MockCMSUsageRepo{
+		replaceUsageIndex: func(references []models.UsageReference) error { return nil },
+		findUsages: func(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error) {
+			return []models.UsageReference{
+				{ItemType: itemType, ItemID: itemId, ReferrerType: enums.UsageReferrerContent, ReferrerID: englishPageId, PageType: enums.PageTypeLanding, PageID: &englishPageId, Language: enums.PageLanguageEN, Field: "html_input"},
+				{ItemType: itemType, ItemID: itemId, ReferrerType: enums.UsageReferrerContent, ReferrerID: thaiPageId, PageType: enums.PageTypeLanding, PageID: &thaiPageId, Language: enums.PageLanguageTH, Field: "html_input", Published: true},
+				{ItemType: itemType, ItemID: itemId, ReferrerType: enums.UsageReferrerEmailContent, ReferrerID: uuid.New(), Field: "body"},
+			}, nil
+		},
+		findContentScopes: func(pageType enums.PageType, pageId uuid.UUID) ([]dto.PageContentScope, error) {
+			// The content ids double as page ids to keep the references short
+			if pageId == thaiPageId {
+				return []dto.PageContentScope{{ContentID: thaiPageId, Language: enums.PageLanguageTH, Mode: enums.PageModePublished}}, nil
+			}
+			return []dto.PageContentScope{{ContentID: englishPageId, Language: enums.PageLanguageEN, Mode: enums.PageModePublished}}, nil
+		},
+	}
+	service := services.NewCMSUsageService(repo, cfg)
+
+	t.Run("successfully leave the references of pages outside the grants out", func(t *testing.T) {
+		impact, err := service.WithPageAccess(access).GetDeleteImpact(enums.UsageItemMediaFile, mediaId)
+		assert.NoError(t, err)
+		assert.Len(t, impact.Usages, 2)
+		assert.Equal(t, 3, impact.References)
+		assert.True(t, impact.Blocked)
+	})
+
+	t.Run("failed to get the usages of a page outside the grants: access denied", func(t *testing.T) {
+		_, err := service.WithPageAccess(access).GetUsages(enums.UsageItemLandingPage, thaiPageId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+
+	t.Run("failed to get usages without a page access: access denied", func(t *testing.T) {
+		_, err := service.WithPageAccess(nil).GetUsages(enums.UsageItemLandingPage, englishPageId)
+		assert.ErrorIs(t, err, errs.ErrPageAccessDenied)
+	})
+}
+
+func TestCMSUsageService_HandleDomainEvent(t *testing.T) {
+	cfg := &config.Config{
+		App:   config.AppConfig{WebBaseURL: "https://example.com", APIBaseURL: "https://api.example.com"},
+		Usage: config.UsageConfig{MaxAge: time.Hour},
+	}
+
+	landingPageId := uuid.New()
+	partnerPageId := uuid.New()
+	bannerId := uuid.New()
+
+	rebuilds := 0
+	repo := &MockCMSUsageRepo{
+		mediaFiles: []models.MediaFile{{ID: bannerId, DownloadURL: "https://api.example.com/files/banner.png"}},
+		pagePaths:  []dto.UsagePagePath{{PageType: enums.PageTypePartner, PageID: partnerPageId, Path: "partners/acme"}},
+		landingContents: []models.LandingContent{{
+			ID: uuid.New(), PageID: landingPageId, Language: enums.PageLanguageEN, Title: "Summer Sale",
+			WorkflowStatus: enums.WorkflowPublished, UrlAlias: "summer-sale",
+			HTMLInput: `<img src="/files/banner.png"><a href="/en/partners/acme">Acme</a>`,
+		}},
+		replaceUsageIndex: func(references []models.UsageReference) error {
+			rebuilds++
+			return nil
+		},
+		findUsages: func(itemType enums.UsageItemType, itemId uuid.UUID) ([]models.UsageReference, error) {
+			return nil, nil
+		},
+	}
+	service := services.NewCMSUsageService(repo, cfg)
+
+	contentSaved := &models.OutboxEvent{
+		EventType: enums.DomainEventContentSaved,
+		Payload:   datatypes.JSON(`{"page_type":"landing","page_id":"` + landingPageId.String() + `","language":"en"}`),
+	}
+
+	t.Run("successfully skip an event before the index is built", func(t *testing.T) {
+		repo.replacePageUsages = func(scope dto.UsagePageScope, references []models.UsageReference) error {
+			t.Fatal("the page should not be indexed without an index")
+			return nil
+		}
+
+		err := service.HandleDomainEvent(context.Background(), contentSaved)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, rebuilds)
+	})
+
+	// Builds the index the events update
+	_, err := service.GetUsages(enums.UsageItemMediaFile, bannerId)
+	assert.NoError(t, err)
+
+	t.Run("successfully reindex the saved page language only", func(t *testing.T) {
+		var replacedScope dto.UsagePageScope
+		var replaced []models.UsageReference
+		repo.replacePageUsages = func(scope dto.UsagePageScope, references []models.UsageReference) error {
+			replacedScope = scope
+			replaced = references
+			return nil
+		}
+
+		err := service.HandleDomainEvent(context.Background(), contentSaved)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.UsagePageScope{PageType: enums.PageTypeLanding, PageID: landingPageId, Language: enums.PageLanguageEN}, replacedScope)
+		assert.ElementsMatch(t, []string{
+			"media_file " + bannerId.String() + " content html_input published",
+			"partner_page " + partnerPageId.String() + " content html_input published",
+		}, usageKeys(replaced))
+		assert.Equal(t, 1, rebuilds)
+	})
+
+	t.Run("successfully delete the usages of a deleted media file", func(t *testing.T) {
+		var deletedId uuid.UUID
+		repo.deleteItemUsages = func(itemType enums.UsageItemType, itemId uuid.UUID) error {
+			assert.Equal(t, enums.UsageItemMediaFile, itemType)
+			deletedId = itemId
+			return nil
+		}
+
+		err := service.HandleDomainEvent(context.Background(), &models.OutboxEvent{
+			EventType: enums.DomainEventMediaFileDeleted,
+			Payload:   datatypes.JSON(`{"media_file_id":"` + bannerId.String() + `"}`),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, bannerId, deletedId)
+	})
+
+	t.Run("successfully move the usages of a replaced media file", func(t *testing.T) {
+		replacementId := uuid.New()
+		var movedFrom, movedTo uuid.UUID
+		repo.moveItemUsages = func(itemType enums.UsageItemType, fromId, toId uuid.UUID) error {
+			movedFrom, movedTo = fromId, toId
+			return nil
+		}
+
+		err := service.HandleDomainEvent(context.Background(), &models.OutboxEvent{
+			EventType: enums.DomainEventMediaFileUploaded,
+			Payload:   datatypes.JSON(`{"media_file_id":"` + replacementId.String() + `","replaced":true,"replaced_id":"` + bannerId.String() + `"}`),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, bannerId, movedFrom)
+		assert.Equal(t, replacementId, movedTo)
+	})
+
+	t.Run("failed to handle the event: invalid payload", func(t *testing.T) {
+		err := service.HandleDomainEvent(context.Background(), &models.OutboxEvent{
+			EventType: enums.DomainEventContentSaved,
+			Payload:   datatypes.JSON(`{"page_id":1}`),
+		})
+		assert.Error(t, err)
+	})
+}
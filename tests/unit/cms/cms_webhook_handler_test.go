@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	cmsHandler "github.com/MadManJJ/cms-api/handlers/cms"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockCMSWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockCMSWebhookService) CreateEndpoint(request dto.WebhookEndpointRequest) (*dto.WebhookEndpointSecretResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.WebhookEndpointSecretResponse), args.Error(1)
+}
+
+func (m *MockCMSWebhookService) FindEndpoints() ([]models.WebhookEndpoint, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockCMSWebhookService) FindEndpointByID(id uuid.UUID) (*models.WebhookEndpoint, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockCMSWebhookService) UpdateEndpoint(id uuid.UUID, request dto.WebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	args := m.Called(id, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockCMSWebhookService) RotateEndpointSecret(id uuid.UUID) (*dto.WebhookEndpointSecretResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.WebhookEndpointSecretResponse), args.Error(1)
+}
+
+func (m *MockCMSWebhookService) DeleteEndpoint(id uuid.UUID) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockCMSWebhookService) HandleDomainEvent(ctx context.Context, event *models.OutboxEvent) error {
+	return m.Called(ctx, event).Error(0)
+}
+
+func (m *MockCMSWebhookService) FindDeliveries(query dto.WebhookDeliveryQuery, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	args := m.Called(query, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCMSWebhookService) FindDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockCMSWebhookService) ReplayDelivery(id uuid.UUID) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockCMSWebhookService) DispatchDueDeliveries(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestCMSWebhookHandler(t *testing.T) {
+	mockService := &MockCMSWebhookService{}
+	handler := cmsHandler.NewCMSWebhookHandler(mockService)
+
+	app := fiber.New()
+	app.Post("/cms/webhooks", handler.HandleCreateWebhook)
+	app.Get("/cms/webhooks", handler.HandleGetWebhooks)
+	app.Get("/cms/webhooks/deliveries", handler.HandleGetWebhookDeliveries)
+	app.Get("/cms/webhooks/deliveries/:deliveryId", handler.HandleGetWebhookDelivery)
+	app.Post("/cms/webhooks/deliveries/:deliveryId/replay", handler.HandleReplayWebhookDelivery)
+	app.Get("/cms/webhooks/:webhookId", handler.HandleGetWebhook)
+	app.Put("/cms/webhooks/:webhookId", handler.HandleUpdateWebhook)
+	app.Delete("/cms/webhooks/:webhookId", handler.HandleDeleteWebhook)
+	app.Post("/cms/webhooks/:webhookId/secret", handler.HandleRotateWebhookSecret)
+
+	webhookId := uuid.New()
+	deliveryId := uuid.New()
+	request := dto.WebhookEndpointRequest{
+		Name:       "Search indexer",
+		URL:        "https://search.example.com/hooks/cms",
+		EventTypes: []enums.WebhookEventType{enums.WebhookEventContentPublished},
+	}
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	t.Run("POST /cms/webhooks HandleCreateWebhook", func(t *testing.T) {
+		t.Run("successfully create webhook and return its secret once", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CreateEndpoint", request).Return(&dto.WebhookEndpointSecretResponse{
+				WebhookEndpoint: models.WebhookEndpoint{ID: webhookId, Name: request.Name, URL: request.URL, Secret: "whsec_abc", IsActive: true},
+				Secret:          "whsec_abc",
+			}, nil)
+
+			req := httptest.NewRequest("POST", "/cms/webhooks", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+			respBody, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+			assert.Equal(t, 1, bytes.Count(respBody, []byte(`"secret":"whsec_abc"`)))
+		})
+
+		t.Run("failed to create webhook: invalid endpoint", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("CreateEndpoint", request).Return(nil, errs.ErrInvalidWebhookEndpoint)
+
+			req := httptest.NewRequest("POST", "/cms/webhooks", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("failed to create webhook: invalid body", func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/cms/webhooks", bytes.NewReader([]byte("{")))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/webhooks/:webhookId HandleGetWebhook", func(t *testing.T) {
+		t.Run("successfully get webhook without its secret", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindEndpointByID", webhookId).Return(&models.WebhookEndpoint{ID: webhookId, Name: request.Name, Secret: "whsec_abc"}, nil)
+
+			req := httptest.NewRequest("GET", "/cms/webhooks/"+webhookId.String(), nil)
+			resp, _ := app.Test(req)
+			respBody, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.NotContains(t, string(respBody), "whsec_abc")
+		})
+
+		t.Run("failed to get webhook: not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindEndpointByID", webhookId).Return(nil, gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("GET", "/cms/webhooks/"+webhookId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("failed to get webhook: invalid webhookId", func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/cms/webhooks/abc", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("DELETE /cms/webhooks/:webhookId HandleDeleteWebhook", func(t *testing.T) {
+		t.Run("successfully delete webhook", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("DeleteEndpoint", webhookId).Return(nil)
+
+			req := httptest.NewRequest("DELETE", "/cms/webhooks/"+webhookId.String(), nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /cms/webhooks/deliveries HandleGetWebhookDeliveries", func(t *testing.T) {
+		t.Run("successfully get webhook deliveries", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			query := dto.WebhookDeliveryQuery{EndpointID: webhookId.String(), Status: enums.WebhookDeliveryFailed}
+			mockService.On("FindDeliveries", query, 1, 10).Return([]models.WebhookDelivery{
+				{ID: deliveryId, EndpointID: webhookId, Status: enums.WebhookDeliveryFailed, Attempts: 8},
+			}, int64(1), nil)
+
+			req := httptest.NewRequest("GET", "/cms/webhooks/deliveries?webhookId="+webhookId.String()+"&status=failed", nil)
+			resp, _ := app.Test(req)
+			respBody, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(respBody), `"totalCount":1`)
+		})
+
+		t.Run("failed to get webhook deliveries: invalid status", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("FindDeliveries", mock.Anything, 1, 10).Return(nil, int64(0), errs.ErrInvalidWebhookDeliveryStatus)
+
+			req := httptest.NewRequest("GET", "/cms/webhooks/deliveries?status=lost", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /cms/webhooks/deliveries/:deliveryId/replay HandleReplayWebhookDelivery", func(t *testing.T) {
+		t.Run("successfully queue the delivery again", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("ReplayDelivery", deliveryId).Return(&models.WebhookDelivery{ID: uuid.New(), ReplayOfID: &deliveryId, Status: enums.WebhookDeliveryPending}, nil)
+
+			req := httptest.NewRequest("POST", "/cms/webhooks/deliveries/"+deliveryId.String()+"/replay", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+		})
+
+		t.Run("failed to replay delivery: not found", func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("ReplayDelivery", deliveryId).Return(nil, gorm.ErrRecordNotFound)
+
+			req := httptest.NewRequest("POST", "/cms/webhooks/deliveries/"+deliveryId.String()+"/replay", nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models/enums"
+	repo "github.com/MadManJJ/cms-api/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCMSRepo_FindSubscribedWebhookEndpoints(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsWebhookRepo := repo.NewCMSWebhookRepository(gormDB)
+
+	t.Run("successfully find active endpoints listing the event type", func(t *testing.T) {
+		endpointId := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhook_endpoints" WHERE is_active = $1 AND $2 = ANY(event_types)`)).
+			WithArgs(true, "content.published").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "url"}).AddRow(endpointId, "Search indexer", "https://search.example.com"))
+
+		endpoints, err := cmsWebhookRepo.FindSubscribedEndpoints(enums.WebhookEventContentPublished)
+		assert.NoError(t, err)
+		require.Len(t, endpoints, 1)
+		assert.Equal(t, endpointId, endpoints[0].ID)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_ClaimDueWebhookDeliveries(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsWebhookRepo := repo.NewCMSWebhookRepository(gormDB)
+
+	t.Run("successfully lock, lease and load the due deliveries", func(t *testing.T) {
+		now := time.Now()
+		leaseUntil := now.Add(time.Minute)
+		deliveryId := uuid.New()
+		endpointId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhook_deliveries" WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at ASC LIMIT $3 FOR UPDATE SKIP LOCKED`)).
+			WithArgs(enums.WebhookDeliveryPending, now, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "endpoint_id", "status"}).AddRow(deliveryId, endpointId, enums.WebhookDeliveryPending))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "webhook_deliveries" SET "next_attempt_at"=$1,"updated_at"=$2 WHERE id IN ($3)`)).
+			WithArgs(leaseUntil, sqlmock.AnyArg(), deliveryId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhook_endpoints" WHERE id IN ($1)`)).
+			WithArgs(endpointId).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "url", "is_active"}).AddRow(endpointId, "https://search.example.com", true))
+
+		deliveries, err := cmsWebhookRepo.ClaimDueDeliveries(now, 10, leaseUntil)
+		assert.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.NotNil(t, deliveries[0].Endpoint)
+		assert.Equal(t, endpointId, deliveries[0].Endpoint.ID)
+	})
+
+	t.Run("successfully claim nothing when no delivery is due", func(t *testing.T) {
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE SKIP LOCKED`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+
+		deliveries, err := cmsWebhookRepo.ClaimDueDeliveries(now, 10, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCMSRepo_DeleteWebhookEndpoint(t *testing.T) {
+	gormDB, mock, cleanup := helpers.SetupTestDB(t)
+	defer cleanup()
+
+	cmsWebhookRepo := repo.NewCMSWebhookRepository(gormDB)
+
+	t.Run("failed to delete webhook endpoint: not found", func(t *testing.T) {
+		endpointId := uuid.New()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "webhook_endpoints" WHERE id = $1`)).
+			WithArgs(endpointId).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := cmsWebhookRepo.DeleteEndpoint(endpointId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
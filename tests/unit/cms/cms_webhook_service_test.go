@@ -0,0 +1,482 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MadManJJ/cms-api/config"
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
+	"github.com/MadManJJ/cms-api/helpers"
+	"github.com/MadManJJ/cms-api/models"
+	"github.com/MadManJJ/cms-api/models/enums"
+	"github.com/MadManJJ/cms-api/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+type MockCMSWebhookRepo struct {
+	createEndpoint          func(endpoint *models.WebhookEndpoint) error
+	findEndpoints           func() ([]models.WebhookEndpoint, error)
+	findEndpointByID        func(id uuid.UUID) (*models.WebhookEndpoint, error)
+	updateEndpoint          func(endpoint *models.WebhookEndpoint) error
+	deleteEndpoint          func(id uuid.UUID) error
+	findSubscribedEndpoints func(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error)
+	createDeliveries        func(deliveries []models.WebhookDelivery) error
+	claimDueDeliveries      func(now time.Time, limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error)
+	recordAttempt           func(delivery *models.WebhookDelivery, attempt *models.WebhookDeliveryAttempt) error
+	findDeliveries          func(query dto.WebhookDeliveryQuery, page, limit int) ([]models.WebhookDelivery, int64, error)
+	findDeliveryByID        func(id uuid.UUID) (*models.WebhookDelivery, error)
+}
+
+func (m *MockCMSWebhookRepo) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return m.createEndpoint(endpoint)
+}
+
+func (m *MockCMSWebhookRepo) FindEndpoints() ([]models.WebhookEndpoint, error) {
+	return m.findEndpoints()
+}
+
+func (m *MockCMSWebhookRepo) FindEndpointByID(id uuid.UUID) (*models.WebhookEndpoint, error) {
+	return m.findEndpointByID(id)
+}
+
+func (m *MockCMSWebhookRepo) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return m.updateEndpoint(endpoint)
+}
+
+func (m *MockCMSWebhookRepo) DeleteEndpoint(id uuid.UUID) error {
+	return m.deleteEndpoint(id)
+}
+
+func (m *MockCMSWebhookRepo) FindSubscribedEndpoints(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error) {
+	return m.findSubscribedEndpoints(eventType)
+}
+
+func (m *MockCMSWebhookRepo) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	return m.createDeliveries(deliveries)
+}
+
+func (m *MockCMSWebhookRepo) ClaimDueDeliveries(now time.Time, limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error) {
+	return m.claimDueDeliveries(now, limit, leaseUntil)
+}
+
+func (m *MockCMSWebhookRepo) RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookDeliveryAttempt) error {
+	return m.recordAttempt(delivery, attempt)
+}
+
+func (m *MockCMSWebhookRepo) FindDeliveries(query dto.WebhookDeliveryQuery, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	return m.findDeliveries(query, page, limit)
+}
+
+func (m *MockCMSWebhookRepo) FindDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error) {
+	return m.findDeliveryByID(id)
+}
+
+const webhookTestSecret = "whsec_test"
+
+// newWebhookStandIn answers with the given status and fails the test when a request is not signed with webhookTestSecret
+func newWebhookStandIn(t *testing.T, status int, received *[]*http.Request) *httptest.Server {
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get("X-Webhook-Timestamp"), 10, 64)
+		assert.NoError(t, err)
+		assert.Equal(t, helpers.SignWebhook(webhookTestSecret, timestamp, body), r.Header.Get("X-Webhook-Signature"))
+
+		mu.Lock()
+		*received = append(*received, r)
+		mu.Unlock()
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("answer"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newWebhookConfig() *config.Config {
+	cfg := config.New()
+	cfg.App.WebBaseURL = "https://www.example.com"
+	cfg.Webhook.Timeout = time.Second
+	cfg.Webhook.BatchSize = 10
+	cfg.Webhook.Concurrency = 2
+	cfg.Webhook.MaxAttempts = 3
+	cfg.Webhook.BaseBackoff = time.Minute
+	cfg.Webhook.MaxBackoff = time.Hour
+	cfg.Webhook.UserAgent = "cms-api-webhooks/test"
+	// The stand-in endpoints listen on loopback
+	cfg.Webhook.AllowPrivateHosts = true
+	return cfg
+}
+
+func newDueDelivery(endpoint *models.WebhookEndpoint, attempts int) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:         uuid.New(),
+		EndpointID: endpoint.ID,
+		EventID:    uuid.New(),
+		EventType:  enums.WebhookEventContentPublished,
+		Payload:    datatypes.JSON(`{"type":"content.published"}`),
+		Status:     enums.WebhookDeliveryPending,
+		Attempts:   attempts,
+		Endpoint:   endpoint,
+	}
+}
+
+// dispatchOne claims the given delivery and returns the state it was recorded with
+func dispatchOne(t *testing.T, cfg *config.Config, delivery models.WebhookDelivery) (*models.WebhookDelivery, *models.WebhookDeliveryAttempt) {
+	var recorded *models.WebhookDelivery
+	var attempt *models.WebhookDeliveryAttempt
+	repo := &MockCMSWebhookRepo{
+		claimDueDeliveries: func(now time.Time, limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error) {
+			assert.Equal(t, cfg.Webhook.BatchSize, limit)
+			assert.True(t, leaseUntil.After(now))
+			return []models.WebhookDelivery{delivery}, nil
+		},
+		recordAttempt: func(d *models.WebhookDelivery, a *models.WebhookDeliveryAttempt) error {
+			recorded, attempt = d, a
+			return nil
+		},
+	}
+
+	sent, err := services.NewCMSWebhookService(repo, cfg).DispatchDueDeliveries(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+	require.NotNil(t, recorded)
+	return recorded, attempt
+}
+
+func TestCMSService_DispatchWebhookDeliveries(t *testing.T) {
+	cfg := newWebhookConfig()
+
+	t.Run("successfully deliver a signed event", func(t *testing.T) {
+		var received []*http.Request
+		server := newWebhookStandIn(t, http.StatusNoContent, &received)
+		endpoint := &models.WebhookEndpoint{ID: uuid.New(), URL: server.URL, Secret: webhookTestSecret, IsActive: true}
+		delivery := newDueDelivery(endpoint, 0)
+
+		recorded, attempt := dispatchOne(t, cfg, delivery)
+
+		require.Len(t, received, 1)
+		assert.Equal(t, delivery.EventID.String(), received[0].Header.Get("X-Webhook-Id"))
+		assert.Equal(t, delivery.ID.String(), received[0].Header.Get("X-Webhook-Delivery"))
+		assert.Equal(t, "content.published", received[0].Header.Get("X-Webhook-Event"))
+		assert.Equal(t, "cms-api-webhooks/test", received[0].Header.Get("User-Agent"))
+		assert.Equal(t, enums.WebhookDeliverySucceeded, recorded.Status)
+		assert.Equal(t, 1, recorded.Attempts)
+		assert.NotNil(t, recorded.DeliveredAt)
+		assert.Equal(t, http.StatusNoContent, attempt.StatusCode)
+	})
+
+	t.Run("schedule a retry with backoff when the endpoint fails", func(t *testing.T) {
+		var received []*http.Request
+		server := newWebhookStandIn(t, http.StatusInternalServerError, &received)
+		endpoint := &models.WebhookEndpoint{ID: uuid.New(), URL: server.URL, Secret: webhookTestSecret, IsActive: true}
+
+		recorded, attempt := dispatchOne(t, cfg, newDueDelivery(endpoint, 1))
+
+		assert.Equal(t, enums.WebhookDeliveryPending, recorded.Status)
+		assert.Equal(t, 2, recorded.Attempts)
+		assert.Equal(t, "endpoint answered 500", recorded.LastError)
+		assert.Equal(t, "answer", attempt.ResponseBody)
+		// Second failure waits twice the base backoff
+		assert.WithinDuration(t, time.Now().Add(2*time.Minute), recorded.NextAttemptAt, 5*time.Second)
+	})
+
+	t.Run("mark the delivery failed after the last attempt", func(t *testing.T) {
+		var received []*http.Request
+		server := newWebhookStandIn(t, http.StatusBadGateway, &received)
+		endpoint := &models.WebhookEndpoint{ID: uuid.New(), URL: server.URL, Secret: webhookTestSecret, IsActive: true}
+
+		recorded, _ := dispatchOne(t, cfg, newDueDelivery(endpoint, 2))
+
+		assert.Equal(t, enums.WebhookDeliveryFailed, recorded.Status)
+		assert.Equal(t, 3, recorded.Attempts)
+		assert.Nil(t, recorded.DeliveredAt)
+	})
+
+	t.Run("refuse to send to a loopback address", func(t *testing.T) {
+		var received []*http.Request
+		server := newWebhookStandIn(t, http.StatusOK, &received)
+		endpoint := &models.WebhookEndpoint{ID: uuid.New(), URL: server.URL, Secret: webhookTestSecret, IsActive: true}
+		guarded := newWebhookConfig()
+		guarded.Webhook.AllowPrivateHosts = false
+
+		recorded, attempt := dispatchOne(t, guarded, newDueDelivery(endpoint, 0))
+
+		assert.Empty(t, received)
+		assert.Equal(t, enums.WebhookDeliveryPending, recorded.Status)
+		assert.Contains(t, recorded.LastError, "private")
+		assert.Empty(t, attempt.ResponseBody)
+	})
+
+	t.Run("fail without keeping the body when the endpoint redirects", func(t *testing.T) {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("internal answer"))
+		}))
+		t.Cleanup(target.Close)
+		redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, target.URL, http.StatusFound)
+		}))
+		t.Cleanup(redirecting.Close)
+		endpoint := &models.WebhookEndpoint{ID: uuid.New(), URL: redirecting.URL, Secret: webhookTestSecret, IsActive: true}
+
+		recorded, attempt := dispatchOne(t, cfg, newDueDelivery(endpoint, 0))
+
+		assert.Equal(t, enums.WebhookDeliveryPending, recorded.Status)
+		assert.Contains(t, recorded.LastError, "redirected")
+		assert.Empty(t, attempt.ResponseBody)
+	})
+
+	t.Run("fail without sending when the webhook is disabled", func(t *testing.T) {
+		var received []*http.Request
+		server := newWebhookStandIn(t, http.StatusOK, &received)
+		endpoint := &models.WebhookEndpoint{ID: uuid.New(), URL: server.URL, Secret: webhookTestSecret, IsActive: false}
+
+		recorded, _ := dispatchOne(t, cfg, newDueDelivery(endpoint, 0))
+
+		assert.Empty(t, received)
+		assert.Equal(t, enums.WebhookDeliveryFailed, recorded.Status)
+		assert.Equal(t, "webhook is disabled", recorded.LastError)
+	})
+}
+
+func newDomainEvent(t *testing.T, eventType enums.DomainEventType, payload interface{}) *models.OutboxEvent {
+	event, err := helpers.NewOutboxEvent(eventType, uuid.New(), "", payload)
+	require.NoError(t, err)
+	return event
+}
+
+func TestCMSService_HandleWebhookDomainEvent(t *testing.T) {
+	t.Run("successfully queue a delivery for every subscribed endpoint", func(t *testing.T) {
+		endpoints := []models.WebhookEndpoint{{ID: uuid.New()}, {ID: uuid.New()}}
+		var created []models.WebhookDelivery
+		repo := &MockCMSWebhookRepo{
+			findSubscribedEndpoints: func(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error) {
+				assert.Equal(t, enums.WebhookEventContentPublished, eventType)
+				return endpoints, nil
+			},
+			createDeliveries: func(deliveries []models.WebhookDelivery) error {
+				created = deliveries
+				return nil
+			},
+		}
+		service := services.NewCMSWebhookService(repo, newWebhookConfig())
+
+		pageId := uuid.New()
+		event := newDomainEvent(t, enums.DomainEventContentSaved, dto.ContentEventData{
+			PageType:       enums.PageTypeLanding,
+			PageID:         pageId,
+			Language:       enums.PageLanguageEN,
+			Path:           "summer-sale",
+			WorkflowStatus: enums.WorkflowPublished,
+		})
+		require.NoError(t, service.HandleDomainEvent(context.Background(), event))
+
+		require.Len(t, created, 2)
+		assert.Equal(t, endpoints[0].ID, created[0].EndpointID)
+		assert.Equal(t, endpoints[1].ID, created[1].EndpointID)
+		// The domain event id is the webhook event id, so handling it again adds nothing
+		assert.Equal(t, event.ID, created[0].EventID)
+		assert.Equal(t, event.ID, created[1].EventID)
+		assert.Equal(t, enums.WebhookDeliveryPending, created[0].Status)
+
+		var payload struct {
+			Type enums.WebhookEventType `json:"type"`
+			Data dto.WebhookContentData `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(created[0].Payload, &payload))
+		assert.Equal(t, enums.WebhookEventContentPublished, payload.Type)
+		assert.Equal(t, pageId, payload.Data.PageID)
+		assert.Equal(t, "https://www.example.com/en/summer-sale", payload.Data.URL)
+	})
+
+	t.Run("report an unpublished content as content.unpublished", func(t *testing.T) {
+		var published enums.WebhookEventType
+		repo := &MockCMSWebhookRepo{
+			findSubscribedEndpoints: func(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error) {
+				published = eventType
+				return nil, nil
+			},
+		}
+		service := services.NewCMSWebhookService(repo, newWebhookConfig())
+
+		event := newDomainEvent(t, enums.DomainEventContentSaved, dto.ContentEventData{PageType: enums.PageTypeFaq, WorkflowStatus: enums.WorkflowUnPublished})
+		require.NoError(t, service.HandleDomainEvent(context.Background(), event))
+
+		assert.Equal(t, enums.WebhookEventContentUnpublished, published)
+	})
+
+	t.Run("report a deleted page and a deleted published content as content.deleted", func(t *testing.T) {
+		var published []enums.WebhookEventType
+		repo := &MockCMSWebhookRepo{
+			findSubscribedEndpoints: func(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error) {
+				published = append(published, eventType)
+				return nil, nil
+			},
+		}
+		service := services.NewCMSWebhookService(repo, newWebhookConfig())
+
+		contentId := uuid.New()
+		page := newDomainEvent(t, enums.DomainEventContentDeleted, dto.ContentEventData{PageType: enums.PageTypeLanding, PageID: uuid.New()})
+		content := newDomainEvent(t, enums.DomainEventContentDeleted, dto.ContentEventData{
+			PageType: enums.PageTypeLanding, PageID: uuid.New(), ContentID: &contentId, Mode: enums.PageModePublished,
+		})
+		require.NoError(t, service.HandleDomainEvent(context.Background(), page))
+		require.NoError(t, service.HandleDomainEvent(context.Background(), content))
+
+		assert.Equal(t, []enums.WebhookEventType{enums.WebhookEventContentDeleted, enums.WebhookEventContentDeleted}, published)
+	})
+
+	t.Run("report a form submission as form.submitted", func(t *testing.T) {
+		var created []models.WebhookDelivery
+		repo := &MockCMSWebhookRepo{
+			findSubscribedEndpoints: func(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error) {
+				assert.Equal(t, enums.WebhookEventFormSubmitted, eventType)
+				return []models.WebhookEndpoint{{ID: uuid.New()}}, nil
+			},
+			createDeliveries: func(deliveries []models.WebhookDelivery) error {
+				created = deliveries
+				return nil
+			},
+		}
+		service := services.NewCMSWebhookService(repo, newWebhookConfig())
+
+		submissionId := uuid.New()
+		event := newDomainEvent(t, enums.DomainEventFormSubmissionCreated, dto.FormSubmissionEventData{
+			FormID:        uuid.New(),
+			SubmissionID:  submissionId,
+			SubmittedData: json.RawMessage(`{"name":"Jane"}`),
+		})
+		require.NoError(t, service.HandleDomainEvent(context.Background(), event))
+
+		require.Len(t, created, 1)
+		var payload struct {
+			Data dto.WebhookFormSubmittedData `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(created[0].Payload, &payload))
+		assert.Equal(t, submissionId, payload.Data.SubmissionID)
+		assert.JSONEq(t, `{"name":"Jane"}`, string(payload.Data.SubmittedData))
+	})
+
+	t.Run("do not report a draft or a deleted draft", func(t *testing.T) {
+		repo := &MockCMSWebhookRepo{
+			findSubscribedEndpoints: func(eventType enums.WebhookEventType) ([]models.WebhookEndpoint, error) {
+				t.Fatal("a draft must not be published")
+				return nil, nil
+			},
+		}
+		service := services.NewCMSWebhookService(repo, newWebhookConfig())
+
+		contentId := uuid.New()
+		saved := newDomainEvent(t, enums.DomainEventContentSaved, dto.ContentEventData{PageType: enums.PageTypePartner, WorkflowStatus: enums.WorkflowDraft})
+		deleted := newDomainEvent(t, enums.DomainEventContentDeleted, dto.ContentEventData{
+			PageType: enums.PageTypePartner, ContentID: &contentId, Mode: enums.PageModeDraft,
+		})
+		assert.NoError(t, service.HandleDomainEvent(context.Background(), saved))
+		assert.NoError(t, service.HandleDomainEvent(context.Background(), deleted))
+	})
+}
+
+func TestCMSService_ReplayWebhookDelivery(t *testing.T) {
+	t.Run("successfully queue the same event again", func(t *testing.T) {
+		original := newDueDelivery(&models.WebhookEndpoint{ID: uuid.New()}, 8)
+		original.Status = enums.WebhookDeliveryFailed
+		var created []models.WebhookDelivery
+		repo := &MockCMSWebhookRepo{
+			findDeliveryByID: func(id uuid.UUID) (*models.WebhookDelivery, error) {
+				return &original, nil
+			},
+			createDeliveries: func(deliveries []models.WebhookDelivery) error {
+				created = deliveries
+				return nil
+			},
+		}
+		service := services.NewCMSWebhookService(repo, newWebhookConfig())
+
+		replay, err := service.ReplayDelivery(original.ID)
+
+		assert.NoError(t, err)
+		require.Len(t, created, 1)
+		assert.Equal(t, original.EventID, replay.EventID)
+		assert.Equal(t, original.Payload, replay.Payload)
+		assert.Equal(t, enums.WebhookDeliveryPending, replay.Status)
+		assert.Equal(t, 0, replay.Attempts)
+		assert.Equal(t, &original.ID, replay.ReplayOfID)
+	})
+}
+
+func TestCMSService_CreateWebhookEndpoint(t *testing.T) {
+	t.Run("successfully create an endpoint with a new secret", func(t *testing.T) {
+		var saved *models.WebhookEndpoint
+		repo := &MockCMSWebhookRepo{
+			createEndpoint: func(endpoint *models.WebhookEndpoint) error {
+				saved = endpoint
+				return nil
+			},
+		}
+		service := services.NewCMSWebhookService(repo, newWebhookConfig())
+
+		response, err := service.CreateEndpoint(dto.WebhookEndpointRequest{
+			Name:       "Search indexer",
+			URL:        "https://search.example.com/hooks/cms",
+			EventTypes: []enums.WebhookEventType{enums.WebhookEventContentPublished, enums.WebhookEventContentPublished, enums.WebhookEventContentDeleted},
+		})
+
+		assert.NoError(t, err)
+		require.NotNil(t, saved)
+		assert.True(t, saved.IsActive)
+		assert.Len(t, saved.EventTypes, 2)
+		assert.Regexp(t, `^whsec_[0-9a-f]{48}$`, response.Secret)
+		assert.Equal(t, saved.Secret, response.Secret)
+	})
+
+	t.Run("failed to create an endpoint: invalid url", func(t *testing.T) {
+		service := services.NewCMSWebhookService(&MockCMSWebhookRepo{}, newWebhookConfig())
+
+		_, err := service.CreateEndpoint(dto.WebhookEndpointRequest{
+			Name:       "Search indexer",
+			URL:        "ftp://search.example.com",
+			EventTypes: []enums.WebhookEventType{enums.WebhookEventContentPublished},
+		})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidWebhookEndpoint)
+	})
+
+	t.Run("failed to create an endpoint: private host", func(t *testing.T) {
+		cfg := newWebhookConfig()
+		cfg.Webhook.AllowPrivateHosts = false
+		service := services.NewCMSWebhookService(&MockCMSWebhookRepo{}, cfg)
+
+		for _, endpointURL := range []string{"http://169.254.169.254/latest/meta-data", "http://localhost:8080/hooks", "https://10.0.0.7/hooks", "http://[::1]/hooks"} {
+			_, err := service.CreateEndpoint(dto.WebhookEndpointRequest{
+				Name:       "Search indexer",
+				URL:        endpointURL,
+				EventTypes: []enums.WebhookEventType{enums.WebhookEventContentPublished},
+			})
+
+			assert.ErrorIs(t, err, errs.ErrInvalidWebhookEndpoint, endpointURL)
+		}
+	})
+
+	t.Run("failed to create an endpoint: unknown event type", func(t *testing.T) {
+		service := services.NewCMSWebhookService(&MockCMSWebhookRepo{}, newWebhookConfig())
+
+		_, err := service.CreateEndpoint(dto.WebhookEndpointRequest{
+			Name:       "Search indexer",
+			URL:        "https://search.example.com/hooks/cms",
+			EventTypes: []enums.WebhookEventType{"content.viewed"},
+		})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidWebhookEventType)
+	})
+}
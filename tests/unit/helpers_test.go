@@ -1,12 +1,15 @@
 package tests
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http/httptest"
-	"net/url"
-	"path"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/MadManJJ/cms-api/dto"
+	"github.com/MadManJJ/cms-api/errs"
 	"github.com/MadManJJ/cms-api/helpers"
 	"github.com/MadManJJ/cms-api/models"
 	"github.com/MadManJJ/cms-api/models/enums"
@@ -556,19 +559,347 @@ func TestHelper_GetUserIDFromContext(t *testing.T) {
 }
 
 func TestHelper_BuildPreviewURL(t *testing.T) {
-	baseURL := "https://example.com"
-	language := "en"
-	urlPath := "test-path"
-	id := uuid.New()
+	actualURL, err := helpers.BuildPreviewURL("https://example.com", "en", "landing", "signed.token")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/preview/en/landing?token=signed.token", actualURL)
+}
+
+func TestHelper_PreviewFrontendURL(t *testing.T) {
+	actualURL, err := helpers.PreviewFrontendURL("https://preview.example.com/", "https://cms.example.com,https://www.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://preview.example.com", actualURL)
+
+	actualURL, err = helpers.PreviewFrontendURL("", "https://cms.example.com, https://www.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.example.com", actualURL)
+
+	actualURL, err = helpers.PreviewFrontendURL("", "https://www.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.example.com", actualURL)
+
+	_, err = helpers.PreviewFrontendURL("", " , ")
+	assert.ErrorIs(t, err, errs.ErrPreviewFrontendURLMissing)
+
+	_, err = helpers.PreviewFrontendURL("not a url", "")
+	assert.ErrorIs(t, err, errs.ErrPreviewFrontendURLMissing)
+}
+
+func TestHelper_PreviewToken(t *testing.T) {
+	claims := helpers.PreviewTokenClaims{
+		PageType:  "landing",
+		ContentID: uuid.New().String(),
+		Language:  "en",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	token, err := helpers.SignPreviewToken(claims, "secret")
+	require.NoError(t, err)
+
+	parsedClaims, err := helpers.ParsePreviewToken(token, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, claims.ContentID, parsedClaims.ContentID)
+	assert.Equal(t, claims.ID, parsedClaims.ID)
+	assert.Equal(t, helpers.PreviewTokenType, parsedClaims.Type)
+	assert.True(t, helpers.IsPreviewToken(token))
+
+	loginToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = helpers.ParsePreviewToken(loginToken, "secret")
+	assert.Error(t, err)
+	assert.False(t, helpers.IsPreviewToken(loginToken))
+
+	_, err = helpers.ParsePreviewToken(token, "another-secret")
+	assert.Error(t, err)
+
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	expiredToken, err := helpers.SignPreviewToken(claims, "secret")
+	require.NoError(t, err)
+	_, err = helpers.ParsePreviewToken(expiredToken, "secret")
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	_, err = helpers.SignPreviewToken(claims, "")
+	assert.Error(t, err)
+}
 
-	expectedURL, err := url.Parse(baseURL)
+func TestHelper_BuildContentURL(t *testing.T) {
+	actualURL, err := helpers.BuildContentURL("https://example.com", "th", "/faq/about-us")
 	require.NoError(t, err)
-	expectedURL.Path = path.Join(expectedURL.Path, "preview", language, urlPath)
-	query := expectedURL.Query()
-	query.Set("id", id.String())
-	expectedURL.RawQuery = query.Encode()
+	assert.Equal(t, "https://example.com/th/faq/about-us", actualURL)
+}
+
+func TestHelper_SanitizeMetaTag(t *testing.T) {
+	metaTag := &models.MetaTag{
+		Title:   "<b>Title</b>",
+		OGTitle: "<script>alert(1)</script>OG",
+		CustomMeta: map[string]interface{}{
+			"author":            "<i>John</i>",
+			"<script></script>": "dropped",
+			"weight":            10,
+		},
+	}
+
+	helpers.SanitizeMetaTag(metaTag)
+
+	assert.Equal(t, "Title", metaTag.Title)
+	assert.Equal(t, "OG", metaTag.OGTitle)
+	assert.Equal(t, map[string]interface{}{"author": "John"}, map[string]interface{}(metaTag.CustomMeta))
+}
+
+func TestHelper_SanitizeURL(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com/og.png?w=1200&h=630": "https://example.com/og.png?w=1200&h=630",
+		"  http://example.com/a  ":                "http://example.com/a",
+		"/uploads/cover.jpg":                      "/uploads/cover.jpg",
+		"cover.jpg":                               "cover.jpg",
+		"javascript:alert(1)":                     "",
+		"JavaScript:alert(1)":                     "",
+		"data:image/png;base64,AAAA":              "",
+		"//evil.example.com/og.png":               "",
+		"https:///no-host":                        "",
+		"":                                        "",
+	}
+	for raw, expected := range cases {
+		assert.Equal(t, expected, helpers.SanitizeURL(raw), raw)
+	}
+
+	metaTag := &models.MetaTag{CanonicalURL: "https://example.com/en/a?x=1&y=2", OGImage: "javascript:alert(1)"}
+	helpers.SanitizeMetaTag(metaTag)
+	assert.Equal(t, "https://example.com/en/a?x=1&y=2", metaTag.CanonicalURL)
+	assert.Empty(t, metaTag.OGImage)
+}
+
+func TestHelper_BuildFaqStructuredData(t *testing.T) {
+	pageURL := "https://example.com/en/faq/mock-faq-title"
+
+	t.Run("successfully build faq page from the question and answer of the content", func(t *testing.T) {
+		content := &models.FaqContent{
+			Title:     "Is it free?",
+			Language:  enums.PageLanguageEN,
+			HTMLInput: "<p>Yes, it&#39;s <b>free</b> &amp; open</p>",
+		}
+
+		jsonLD, err := helpers.BuildFaqStructuredData(content, pageURL)
+		require.NoError(t, err)
+
+		var actual map[string]interface{}
+		require.NoError(t, json.Unmarshal(jsonLD, &actual))
+		graph := actual["@graph"].([]interface{})
+		faqPage := graph[0].(map[string]interface{})
+		questions := faqPage["mainEntity"].([]interface{})
+
+		assert.Equal(t, "https://schema.org", actual["@context"])
+		assert.Equal(t, "FAQPage", faqPage["@type"])
+		assert.Len(t, questions, 1)
+		question := questions[0].(map[string]interface{})
+		assert.Equal(t, "Is it free?", question["name"])
+		assert.Equal(t, "Yes, it's free & open", question["acceptedAnswer"].(map[string]interface{})["text"])
+	})
+
+	t.Run("successfully leave out an unanswered question", func(t *testing.T) {
+		content := &models.FaqContent{Title: "Is it free?", HTMLInput: "<p> </p>"}
+
+		jsonLD, err := helpers.BuildFaqStructuredData(content, pageURL)
+		require.NoError(t, err)
+
+		var actual map[string]interface{}
+		require.NoError(t, json.Unmarshal(jsonLD, &actual))
+		faqPage := actual["@graph"].([]interface{})[0].(map[string]interface{})
+		assert.Empty(t, faqPage["mainEntity"])
+	})
+
+	t.Run("successfully use the editor override", func(t *testing.T) {
+		content := helpers.InitializeMockFaqPage().Contents[0]
+		content.MetaTag.StructuredData = []byte(`{"@type": "FAQPage"}`)
 
-	actualURL, err := helpers.BuildPreviewURL(baseURL, language, urlPath, id)
+		jsonLD, err := helpers.BuildFaqStructuredData(content, pageURL)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"@type": "FAQPage"}`, string(jsonLD))
+	})
+}
+
+func TestHelper_BuildPartnerStructuredData(t *testing.T) {
+	content := helpers.InitializeMockPartnerPage().Contents[0]
+
+	jsonLD, err := helpers.BuildPartnerStructuredData(content, "https://example.com/en/partners/mock-partner-title")
 	require.NoError(t, err)
-	assert.Equal(t, expectedURL.String(), actualURL)
-}
\ No newline at end of file
+
+	var actual map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonLD, &actual))
+	graph := actual["@graph"].([]interface{})
+	article := graph[0].(map[string]interface{})
+	organization := graph[1].(map[string]interface{})
+
+	assert.Equal(t, "Article", article["@type"])
+	assert.Equal(t, content.Title, article["headline"])
+	assert.Equal(t, "Organization", organization["@type"])
+	assert.Equal(t, content.CompanyName, organization["name"])
+	assert.Equal(t, content.CompanyLogo, organization["logo"])
+}
+
+func TestHelper_BuildLandingStructuredData(t *testing.T) {
+	content := helpers.InitializeMockLandingPage().Contents[0]
+	content.UrlAlias = "campaigns/mock-landing-title"
+
+	jsonLD, err := helpers.BuildLandingStructuredData(content, "https://example.com/en", "https://example.com/en/campaigns/mock-landing-title")
+	require.NoError(t, err)
+
+	var actual map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonLD, &actual))
+	graph := actual["@graph"].([]interface{})
+	breadcrumbs := graph[1].(map[string]interface{})["itemListElement"].([]interface{})
+
+	assert.Equal(t, "WebPage", graph[0].(map[string]interface{})["@type"])
+	assert.Len(t, breadcrumbs, 3)
+	assert.Equal(t, "campaigns", breadcrumbs[1].(map[string]interface{})["name"])
+	assert.Equal(t, content.Title, breadcrumbs[2].(map[string]interface{})["name"])
+	assert.Equal(t, "https://example.com/en/campaigns/mock-landing-title", breadcrumbs[2].(map[string]interface{})["item"])
+}
+
+func TestHelper_AuditFaqContent(t *testing.T) {
+	auditCodes := func(content *models.FaqContent) []string {
+		codes := []string{}
+		for _, finding := range helpers.AuditFaqContent(content) {
+			codes = append(codes, finding.Code)
+		}
+		return codes
+	}
+
+	t.Run("successfully detect heading problems and missing alt text", func(t *testing.T) {
+		content := helpers.InitializeMockFaqPage().Contents[0]
+		content.Components = nil
+		content.HTMLInput = `<h1>One</h1><h3>Skipped</h3><h1>Two</h1><img src="a.png"><img src="b.png" alt="b">`
+
+		codes := auditCodes(content)
+		assert.Contains(t, codes, "heading_level_skipped")
+		assert.Contains(t, codes, "heading_multiple_h1")
+		assert.Contains(t, codes, "image_alt_missing")
+		assert.Contains(t, codes, "content_too_short")
+	})
+
+	t.Run("successfully detect component images without alt text", func(t *testing.T) {
+		content := helpers.InitializeMockFaqPage().Contents[0]
+		content.Components = []*models.Component{
+			{Props: []byte(`{"items":[{"image":"a.png","alt":"a"},{"image":"b.png"}]}`)},
+		}
+
+		findings := helpers.AuditFaqContent(content)
+		missingAlt := 0
+		for _, finding := range findings {
+			if finding.Code == "image_alt_missing" {
+				missingAlt++
+				assert.Equal(t, "components[0]", finding.Field)
+			}
+		}
+		assert.Equal(t, 1, missingAlt)
+	})
+
+	t.Run("successfully detect invalid url alias", func(t *testing.T) {
+		content := helpers.InitializeMockFaqPage().Contents[0]
+
+		for _, alias := range []string{"Mock-FAQ", "mock faq", "mock--faq", "mock-faq/"} {
+			content.URLAlias = alias
+			assert.Contains(t, auditCodes(content), "url_alias_invalid_format", alias)
+		}
+
+		content.URLAlias = "faq/mock-faq"
+		assert.NotContains(t, auditCodes(content), "url_alias_invalid_format")
+	})
+
+	t.Run("successfully score findings", func(t *testing.T) {
+		content := helpers.InitializeMockFaqPage().Contents[0]
+		content.MetaTag.Title = ""
+
+		findings := helpers.AuditFaqContent(content)
+		assert.True(t, helpers.HasCriticalAuditFinding(findings))
+		// meta_title_missing and content_too_short
+		assert.Equal(t, 65, helpers.ScoreAuditFindings(findings))
+	})
+}
+
+func TestHelper_ExtractHTMLLinks(t *testing.T) {
+	htmlInput := `<p><a href="https://example.com/a">a</a> <a href="/en/b">b</a> <a href="https://example.com/a">again</a></p>
+		<img src="/files/c.png"><iframe src="https://video.example.com/embed"></iframe>
+		<a href="mailto:someone@example.com">mail</a><a href="#top">top</a><a href="tel:123">call</a>`
+
+	links := helpers.ExtractHTMLLinks(htmlInput)
+	assert.Equal(t, []string{"https://example.com/a", "/en/b", "/files/c.png", "https://video.example.com/embed"}, links)
+	assert.Empty(t, helpers.ExtractHTMLLinks(""))
+}
+
+func TestHelper_ExtractComponentLinks(t *testing.T) {
+	props := []byte(`{
+		"title": "Not a link",
+		"button": {"label": "Go", "url": "https://example.com/campaign"},
+		"items": [{"image": "/files/a.png"}, {"text": "<a href=\"/en/faq\">FAQ</a>"}],
+		"separator": "/"
+	}`)
+
+	links := helpers.ExtractComponentLinks(props)
+	assert.ElementsMatch(t, []string{"https://example.com/campaign", "/files/a.png", "/en/faq"}, links)
+	assert.Empty(t, helpers.ExtractComponentLinks(nil))
+}
+
+func TestHelper_BuildCMSEditURL(t *testing.T) {
+	pageId := uuid.New()
+	contentId := uuid.New()
+
+	editURL := helpers.BuildCMSEditURL("https://cms.example.com/", enums.PageTypePartner, pageId, contentId, enums.PageLanguageTH)
+	assert.Equal(t, fmt.Sprintf("https://cms.example.com/partner-pages/%s/content/%s/edit?lang=th", pageId, contentId), editURL)
+
+	editURL = helpers.BuildCMSEditURL("", enums.PageTypeFaq, pageId, contentId, enums.PageLanguageEN)
+	assert.Equal(t, fmt.Sprintf("/faq-pages/%s/content/%s/edit?lang=en", pageId, contentId), editURL)
+}
+
+func TestHelper_BuildICalendar(t *testing.T) {
+	at := time.Date(2025, 7, 20, 9, 30, 0, 0, time.FixedZone("ICT", 7*60*60))
+	events := []dto.CalendarEvent{{
+		UID:            "content-1-publish",
+		Type:           enums.CalendarEventPublish,
+		At:             at,
+		PageType:       enums.PageTypeLanding,
+		Language:       enums.PageLanguageEN,
+		Title:          "Summer sale, up to 50%; " + strings.Repeat("long ", 20),
+		WorkflowStatus: enums.WorkflowSchedule,
+		Author:         "Jane",
+		EditURL:        "https://cms.example.com/landing-pages/1/content/2/edit?lang=en",
+	}}
+
+	calendar := helpers.BuildICalendar("Editorial Calendar", "api.example.com", events, at)
+
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+	assert.Contains(t, calendar, "UID:content-1-publish@api.example.com\r\n")
+	assert.Contains(t, calendar, "DTSTART:20250720T023000Z\r\n")
+	assert.Contains(t, calendar, "DESCRIPTION:Status: Schedule\\nAuthor: Jane\r\n")
+	assert.Contains(t, calendar, "SUMMARY:[PUBLISH] Summer sale\\, up to 50%\\; long")
+
+	for _, line := range strings.Split(calendar, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+	}
+	// Folded lines continue with a space and unfold back to the summary
+	assert.Contains(t, strings.ReplaceAll(calendar, "\r\n ", ""), strings.Repeat("long ", 20))
+}
+
+func TestHelper_RedactJSON(t *testing.T) {
+	t.Run("successfully redact secrets at any depth", func(t *testing.T) {
+		redacted := helpers.RedactJSON([]byte(`{"name":"Hook","secret":"s3cr3t","owner":{"Password_Hash":"x","email":"a@b.c"},"items":[{"api-key":"k","count":12345678901234567890}],"token_hash":null}`))
+
+		var value map[string]interface{}
+		require.NoError(t, json.Unmarshal(redacted, &value))
+		assert.Equal(t, "Hook", value["name"])
+		assert.Equal(t, helpers.RedactedValue, value["secret"])
+		assert.Equal(t, helpers.RedactedValue, value["owner"].(map[string]interface{})["Password_Hash"])
+		assert.Equal(t, "a@b.c", value["owner"].(map[string]interface{})["email"])
+		assert.Equal(t, helpers.RedactedValue, value["items"].([]interface{})[0].(map[string]interface{})["api-key"])
+		assert.Nil(t, value["token_hash"])
+		assert.Contains(t, string(redacted), "12345678901234567890")
+	})
+
+	t.Run("failed to redact: not json", func(t *testing.T) {
+		assert.Nil(t, helpers.RedactJSON([]byte("password=hunter2")))
+		assert.Nil(t, helpers.RedactJSON(nil))
+	})
+}